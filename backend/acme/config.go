@@ -0,0 +1,90 @@
+//backend/acme/config.go
+
+package acme
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ⚙️ Configuração da emissão automática de certificados
+type Config struct {
+	DirectoryURL   string        // diretório ACME (ACME_DIRECTORY_URL; vazio = ACME desligado)
+	Email          string        // contato da conta (ACME_EMAIL)
+	CARoots        string        // PEM extra confiável para falar com o servidor ACME, ex.: Pebble (ACME_CA_ROOTS)
+	DNSProvider    string        // provedor do DNS-01: "", "webhook" ou "challtestsrv" (ACME_DNS_PROVIDER)
+	DNSEndpoint    string        // URL do provedor DNS (ACME_DNS_ENDPOINT)
+	DNSPropagation time.Duration // espera após publicar o TXT (ACME_DNS_PROPAGATION_SECONDS)
+	RenewBefore    time.Duration // renova quando faltar menos que isso (ACME_RENEW_DAYS, padrão 30)
+	CheckInterval  time.Duration // varredura de hostnames e validade (ACME_CHECK_SECONDS, padrão 60)
+}
+
+// ⚙️ Configuração lida das variáveis ACME_*
+func ConfigFromEnv() Config {
+	cfg := Config{
+		DirectoryURL:  strings.TrimSpace(os.Getenv("ACME_DIRECTORY_URL")),
+		Email:         strings.TrimSpace(os.Getenv("ACME_EMAIL")),
+		CARoots:       os.Getenv("ACME_CA_ROOTS"),
+		DNSProvider:   strings.ToLower(strings.TrimSpace(os.Getenv("ACME_DNS_PROVIDER"))),
+		DNSEndpoint:   strings.TrimSpace(os.Getenv("ACME_DNS_ENDPOINT")),
+		RenewBefore:   30 * 24 * time.Hour,
+		CheckInterval: time.Minute,
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("ACME_DNS_PROPAGATION_SECONDS")); err == nil && seconds > 0 {
+		cfg.DNSPropagation = time.Duration(seconds) * time.Second
+	}
+	if days, err := strconv.Atoi(os.Getenv("ACME_RENEW_DAYS")); err == nil && days > 0 {
+		cfg.RenewBefore = time.Duration(days) * 24 * time.Hour
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("ACME_CHECK_SECONDS")); err == nil && seconds > 0 {
+		cfg.CheckInterval = time.Duration(seconds) * time.Second
+	}
+	return cfg
+}
+
+// 🌐 Cliente HTTP que também confia em CARoots
+func (cfg Config) httpClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CARoots != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := os.ReadFile(cfg.CARoots)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler ACME_CA_ROOTS: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("nenhum certificado PEM em %s", cfg.CARoots)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &http.Client{Transport: transport, Timeout: time.Minute}, nil
+}
+
+// 🧾 Provedor DNS configurado (nil = sem DNS-01)
+func (cfg Config) dnsProvider(client *http.Client) (DNSProvider, error) {
+	switch cfg.DNSProvider {
+	case "":
+		return nil, nil
+	case "webhook":
+		if cfg.DNSEndpoint == "" {
+			return nil, fmt.Errorf("ACME_DNS_ENDPOINT obrigatório para o provedor webhook")
+		}
+		return &WebhookProvider{URL: cfg.DNSEndpoint, HTTPClient: client}, nil
+	case "challtestsrv":
+		endpoint := cfg.DNSEndpoint
+		if endpoint == "" {
+			endpoint = "http://localhost:8055"
+		}
+		return &ChallTestSrvProvider{URL: endpoint, HTTPClient: client}, nil
+	default:
+		return nil, fmt.Errorf("ACME_DNS_PROVIDER desconhecido: %s", cfg.DNSProvider)
+	}
+}
@@ -0,0 +1,432 @@
+//backend/acme/manager.go
+
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	xacme "golang.org/x/crypto/acme"
+
+	"virtuscloud/backend/ingress"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/vault"
+)
+
+// ⏳ Espera entre tentativas falhas de um mesmo certificado (dobra até o teto)
+const (
+	minRetryWait = time.Minute
+	maxRetryWait = 6 * time.Hour
+)
+
+// 📜 Certificado carregado em memória, pronto para o handshake
+type loadedCert struct {
+	cert      *tls.Certificate
+	domains   []string
+	notBefore time.Time
+	notAfter  time.Time
+}
+
+type failure struct {
+	until time.Time
+	wait  time.Duration
+}
+
+// 🔐 Emite e renova certificados para os hostnames roteados pelo ingress e os serve por SNI
+type Manager struct {
+	cfg    Config
+	in     *ingress.Ingress
+	client *xacme.Client
+	http01 *HTTP01Solver
+	dns01  *DNS01Solver // nil sem provedor DNS: wildcards ficam indisponíveis
+
+	mu       sync.RWMutex
+	loaded   map[string]*loadedCert // nome do certificado → certificado
+	failures map[string]failure
+	kick     chan struct{}
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultManager *Manager
+)
+
+// 🔐 Manager ativo (nil com o ACME desligado)
+func Default() *Manager {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultManager
+}
+
+// 🚀 Liga o ACME no ingress: carrega os certificados salvos, atende HTTP-01, serve por SNI
+// e emite/renova em segundo plano. Sem DirectoryURL não faz nada.
+func Start(cfg Config, in *ingress.Ingress) (*Manager, error) {
+	if cfg.DirectoryURL == "" {
+		log.Println("🔐 ACME desligado (ACME_DIRECTORY_URL vazio)")
+		return nil, nil
+	}
+	if in == nil {
+		return nil, errors.New("ACME requer o ingress ligado (INGRESS_BASE_DOMAIN)")
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = time.Minute
+	}
+
+	httpClient, err := cfg.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	key, err := accountKey(cfg.DirectoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		cfg:      cfg,
+		in:       in,
+		client:   newClient(cfg.DirectoryURL, httpClient, key),
+		http01:   NewHTTP01Solver(),
+		loaded:   map[string]*loadedCert{},
+		failures: map[string]failure{},
+		kick:     make(chan struct{}, 1),
+	}
+	provider, err := cfg.dnsProvider(httpClient)
+	if err != nil {
+		return nil, err
+	}
+	if provider != nil {
+		m.dns01 = &DNS01Solver{Provider: provider, Propagation: cfg.DNSPropagation}
+	}
+	m.loadStored()
+
+	in.SetChallengeHandler(m.http01)
+	in.SetCertificateSource(m.GetCertificate)
+
+	defaultMu.Lock()
+	defaultManager = m
+	defaultMu.Unlock()
+
+	go m.run()
+	log.Printf("🔐 ACME ligado (%s, DNS-01: %t, %d certificado(s) carregado(s))", cfg.DirectoryURL, m.dns01 != nil, len(m.loaded))
+	return m, nil
+}
+
+// 🔒 Certificado para o SNI do handshake: nome exato ou wildcard do nível acima
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := ingress.CanonicalHost(hello.ServerName)
+	if host == "" {
+		return nil, errors.New("handshake sem SNI")
+	}
+	if lc := m.lookup(host); lc != nil {
+		return lc.cert, nil
+	}
+	if _, routed := m.in.Table().Lookup(host); routed {
+		m.Trigger()
+		return nil, fmt.Errorf("certificado de %s ainda não emitido", host)
+	}
+	return nil, fmt.Errorf("hostname desconhecido: %s", host)
+}
+
+// 📋 Nome e validade do certificado que atende o hostname
+func (m *Manager) CertificateFor(host string) (string, time.Time, bool) {
+	lc := m.lookup(ingress.CanonicalHost(host))
+	if lc == nil {
+		return "", time.Time{}, false
+	}
+	return lc.domains[0], lc.notAfter, true
+}
+
+// ⚡ Antecipa a próxima varredura (ex.: hostname novo sem certificado)
+func (m *Manager) Trigger() {
+	select {
+	case m.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) lookup(host string) *loadedCert {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	if lc, ok := m.loaded[host]; ok && lc.notAfter.After(now) {
+		return lc
+	}
+	if i := strings.Index(host, "."); i > 0 {
+		if lc, ok := m.loaded["*"+host[i:]]; ok && lc.notAfter.After(now) {
+			return lc
+		}
+	}
+	return nil
+}
+
+// 🔁 Varre os hostnames a cada CheckInterval ou quando acionado
+func (m *Manager) run() {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		m.sync()
+		select {
+		case <-ticker.C:
+		case <-m.kick:
+		}
+	}
+}
+
+// 🔄 Emite o que falta, renova o que vence em breve e descarta o que expirou sem uso
+func (m *Manager) sync() {
+	if m.client.KID == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		err := register(ctx, m.client, m.cfg.Email)
+		cancel()
+		if err != nil {
+			log.Println("⚠️ ACME:", err)
+			return
+		}
+		log.Println("🔐 Conta ACME pronta:", m.client.KID)
+	}
+
+	desired := m.desired()
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !m.needsIssue(name) || m.backingOff(name) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		err := m.issue(ctx, name, desired[name])
+		cancel()
+		if err != nil {
+			wait := m.fail(name)
+			log.Printf("⚠️ ACME: erro ao emitir %s (nova tentativa em %s): %v", name, wait, err)
+			continue
+		}
+		m.mu.Lock()
+		delete(m.failures, name)
+		m.mu.Unlock()
+	}
+
+	m.pruneExpired(desired)
+}
+
+// 🗺️ Certificados necessários: nome → domínios. Com DNS-01, <app>.<usuário>.<base>
+// vira o wildcard *.<usuário>.<base>; os demais hostnames usam um certificado cada (HTTP-01).
+func (m *Manager) desired() map[string][]string {
+	base := m.in.Config().BaseDomain
+	out := map[string][]string{}
+	for _, route := range m.in.Table().Routes("") {
+		host := route.Host
+		if m.dns01 != nil && strings.HasSuffix(host, "."+base) {
+			if parent := host[strings.Index(host, ".")+1:]; parent != base {
+				name := "*." + parent
+				out[name] = []string{name}
+				continue
+			}
+		}
+		out[host] = []string{host}
+	}
+	return out
+}
+
+// ⏰ Sem certificado ou dentro da janela de renovação (RenewBefore, limitada a 1/3 da validade)
+func (m *Manager) needsIssue(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	lc, ok := m.loaded[name]
+	if !ok {
+		return true
+	}
+	window := m.cfg.RenewBefore
+	if third := lc.notAfter.Sub(lc.notBefore) / 3; third < window {
+		window = third
+	}
+	return time.Until(lc.notAfter) < window
+}
+
+func (m *Manager) backingOff(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.failures[name]
+	return ok && time.Now().Before(f.until)
+}
+
+func (m *Manager) fail(name string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wait := m.failures[name].wait * 2
+	if wait < minRetryWait {
+		wait = minRetryWait
+	}
+	if wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	m.failures[name] = failure{until: time.Now().Add(wait), wait: wait}
+	return wait
+}
+
+// 🧭 HTTP-01 por padrão; DNS-01 para wildcards e para o domínio da plataforma quando configurado
+func (m *Manager) solverFor(domain string, wildcard bool) Solver {
+	if wildcard {
+		if m.dns01 == nil {
+			return nil
+		}
+		return m.dns01
+	}
+	if base := m.in.Config().BaseDomain; m.dns01 != nil && strings.HasSuffix(domain, "."+base) {
+		return m.dns01
+	}
+	return m.http01
+}
+
+// 📜 Emite, sela e grava o certificado; passa a servi-lo em seguida
+func (m *Manager) issue(ctx context.Context, name string, domains []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar chave do certificado: %w", err)
+	}
+	chain, err := obtain(ctx, m.client, domains, key, m.solverFor)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	bundle := append(append([]byte(nil), chain...), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})...)
+
+	lc, err := parseBundle(bundle)
+	if err != nil {
+		return err
+	}
+	sealed, err := vault.Seal(bundle)
+	if err != nil {
+		return fmt.Errorf("erro ao cifrar certificado: %w", err)
+	}
+	record := &models.Certificate{
+		Name:      name,
+		Kind:      models.CertificateKindCert,
+		Domains:   domains,
+		Issuer:    m.cfg.DirectoryURL,
+		NotBefore: lc.notBefore,
+		NotAfter:  lc.notAfter,
+		Sealed:    sealed,
+		UpdatedAt: time.Now(),
+	}
+	if err := store.CertificateStore.Save(record); err != nil {
+		return fmt.Errorf("erro ao salvar certificado: %w", err)
+	}
+
+	m.mu.Lock()
+	m.loaded[name] = lc
+	m.mu.Unlock()
+	log.Printf("🔐 Certificado de %s emitido (válido até %s)", name, lc.notAfter.Format(time.RFC3339))
+	return nil
+}
+
+// 🗑️ Remove certificados vencidos cujo hostname não é mais roteado
+func (m *Manager) pruneExpired(desired map[string][]string) {
+	now := time.Now()
+	for _, record := range store.CertificateStore.List(models.CertificateKindCert) {
+		if _, wanted := desired[record.Name]; wanted || record.NotAfter.After(now) {
+			continue
+		}
+		if err := store.CertificateStore.Delete(record.Name); err != nil {
+			log.Printf("⚠️ ACME: erro ao remover certificado vencido %s: %v", record.Name, err)
+			continue
+		}
+		m.mu.Lock()
+		delete(m.loaded, record.Name)
+		m.mu.Unlock()
+	}
+}
+
+// 📂 Carrega (decifrando) os certificados já emitidos
+func (m *Manager) loadStored() {
+	for _, record := range store.CertificateStore.List(models.CertificateKindCert) {
+		bundle, err := vault.Open(record.Sealed)
+		if err != nil {
+			log.Printf("⚠️ ACME: certificado %s ilegível: %v", record.Name, err)
+			continue
+		}
+		lc, err := parseBundle(bundle)
+		if err != nil {
+			log.Printf("⚠️ ACME: certificado %s inválido: %v", record.Name, err)
+			continue
+		}
+		m.loaded[record.Name] = lc
+	}
+}
+
+// 🔍 Cadeia PEM + chave → certificado TLS com o leaf já decodificado
+func parseBundle(bundle []byte) (*loadedCert, error) {
+	cert, err := tls.X509KeyPair(bundle, bundle)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao montar certificado: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler certificado: %w", err)
+	}
+	cert.Leaf = leaf
+	domains := leaf.DNSNames
+	if len(domains) == 0 {
+		domains = []string{leaf.Subject.CommonName}
+	}
+	return &loadedCert{cert: &cert, domains: domains, notBefore: leaf.NotBefore, notAfter: leaf.NotAfter}, nil
+}
+
+// 🗝️ Chave da conta ACME deste diretório (gerada e selada no primeiro uso)
+func accountKey(directory string) (*ecdsa.PrivateKey, error) {
+	name := "account:" + directory
+	if record, ok := store.CertificateStore.Get(name); ok {
+		data, err := vault.Open(record.Sealed)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir chave da conta ACME: %w", err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("chave da conta ACME inválida")
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar chave da conta ACME: %w", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := vault.Seal(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao cifrar chave da conta ACME: %w", err)
+	}
+	if err := store.CertificateStore.Save(&models.Certificate{
+		Name:      name,
+		Kind:      models.CertificateKindAccount,
+		Issuer:    directory,
+		Sealed:    sealed,
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("erro ao salvar chave da conta ACME: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,242 @@
+//backend/acme/manager_test.go
+
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/letsencrypt/challtestsrv"
+	"github.com/letsencrypt/pebble/v2/ca"
+	"github.com/letsencrypt/pebble/v2/db"
+	"github.com/letsencrypt/pebble/v2/va"
+	"github.com/letsencrypt/pebble/v2/wfe"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/ingress"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/store"
+)
+
+// 🧪 Stores JSON numa pasta temporária, chave mestra de teste e Pebble sem esperas aleatórias
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "acme-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	os.Setenv("VAULT_MASTER_KEY", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	os.Setenv("PEBBLE_VA_NOSLEEP", "1")
+	persistence.SetBackend(persistence.NewJSONBackend())
+	engine.SetDefault(engine.NewFake())
+	for _, err := range []error{
+		store.UserStore.Load("./database/users.json"),
+		store.LoadAppStoreFromDisk("./database/appstore.json"),
+		store.LoadCertificateStoreFromDisk(store.DefaultCertificateStorePath),
+	} {
+		if err != nil {
+			fmt.Println("erro ao carregar stores:", err)
+			return 1
+		}
+	}
+	return m.Run()
+}
+
+// 🔌 Porta TCP livre no loopback (o Pebble precisa saber a porta antes do ingress subir)
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao reservar porta: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// 🧾 DNS de teste: todo nome resolve para 127.0.0.1, TXT publicados pelo webhook do DNS-01
+func startDNS(t *testing.T) (*challtestsrv.ChallSrv, string) {
+	t.Helper()
+	addr := fmt.Sprintf("127.0.0.1:%d", freePort(t))
+	srv, err := challtestsrv.New(challtestsrv.Config{DNSAddrs: []string{addr}, Log: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("erro ao criar DNS de teste: %v", err)
+	}
+	srv.SetDefaultDNSIPv6("")
+	go srv.Run()
+	t.Cleanup(srv.Shutdown)
+	return srv, addr
+}
+
+// 🪝 Webhook do provedor DNS gravando direto no DNS de teste
+func startDNSWebhook(t *testing.T, dns *challtestsrv.ChallSrv) string {
+	t.Helper()
+	handler := func(present bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var body struct{ FQDN, Value string }
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if present {
+				dns.AddDNSTXTRecord(body.FQDN, body.Value)
+			} else {
+				dns.DeleteDNSTXTRecord(body.FQDN)
+			}
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/present", handler(true))
+	mux.HandleFunc("/cleanup", handler(false))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// 🏛️ Pebble em processo: valida HTTP-01 na porta httpPort e consulta o DNS em dnsAddr.
+// Devolve a URL do diretório e o PEM que torna o HTTPS do Pebble confiável.
+func startPebble(t *testing.T, httpPort int, dnsAddr string) (string, string) {
+	t.Helper()
+	logger := log.New(io.Discard, "", 0)
+	memory := db.NewMemoryStore()
+	authority := ca.New(logger, memory, "", "ecdsa", 0, 1, map[string]ca.Profile{"default": {Description: "padrão"}})
+	validator := va.New(logger, httpPort, 0, false, dnsAddr, memory)
+	frontend := wfe.New(logger, memory, validator, authority, nil, false, false, 1, 1)
+
+	srv := httptest.NewTLSServer(frontend.Handler())
+	t.Cleanup(srv.Close)
+	roots := filepath.Join(t.TempDir(), "pebble.pem")
+	if err := os.WriteFile(roots, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatalf("erro ao gravar raiz do Pebble: %v", err)
+	}
+	return srv.URL + wfe.DirectoryPath, roots
+}
+
+// ⏳ Espera o Manager servir um certificado para host
+func waitCertificate(t *testing.T, m *Manager, host string) *tls.Certificate {
+	t.Helper()
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		if cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: host}); err == nil {
+			return cert
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	t.Fatalf("certificado de %s não emitido a tempo", host)
+	return nil
+}
+
+func TestManagerIssuesWithPebble(t *testing.T) {
+	dns, dnsAddr := startDNS(t)
+	httpPort := freePort(t)
+	directory, roots := startPebble(t, httpPort, dnsAddr)
+
+	in, err := ingress.Start(ingress.Config{Addr: fmt.Sprintf("127.0.0.1:%d", httpPort), BaseDomain: "apps.test", ResyncInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("erro ao subir ingress: %v", err)
+	}
+	m, err := Start(Config{
+		DirectoryURL:  directory,
+		Email:         "ops@example.com",
+		CARoots:       roots,
+		DNSProvider:   "webhook",
+		DNSEndpoint:   startDNSWebhook(t, dns),
+		RenewBefore:   30 * 24 * time.Hour,
+		CheckInterval: time.Hour,
+	}, in)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// 🌐 Hostname da plataforma (wildcard por DNS-01) e domínio personalizado (HTTP-01 pelo ingress)
+	in.Table().SetApp("web", []ingress.Route{
+		{Host: "web.alice.apps.test", AppID: "web", Username: "alice"},
+		{Host: "shop.example.com", AppID: "web", Username: "alice"},
+	})
+	m.Trigger()
+
+	platform := waitCertificate(t, m, "web.alice.apps.test")
+	if got := platform.Leaf.DNSNames; len(got) != 1 || got[0] != "*.alice.apps.test" {
+		t.Fatalf("certificado da plataforma para %v, esperado o wildcard *.alice.apps.test", got)
+	}
+	if other := waitCertificate(t, m, "api.alice.apps.test"); other != platform {
+		t.Fatalf("outra aplicação do mesmo usuário deveria usar o mesmo wildcard")
+	}
+	custom := waitCertificate(t, m, "shop.example.com")
+	if got := custom.Leaf.DNSNames; len(got) != 1 || got[0] != "shop.example.com" {
+		t.Fatalf("certificado do domínio para %v", got)
+	}
+	if len(custom.Certificate) < 2 {
+		t.Fatalf("cadeia sem o intermediário: %d certificado(s)", len(custom.Certificate))
+	}
+	if len(dns.GetDNSTXTRecords("_acme-challenge.alice.apps.test.")) != 0 {
+		t.Fatalf("TXT do desafio continua publicado após a emissão")
+	}
+
+	// 🔐 Certificados selados no store voltam numa nova subida
+	records := store.CertificateStore.List(models.CertificateKindCert)
+	if len(records) != 2 {
+		t.Fatalf("%d certificado(s) salvo(s), esperado 2", len(records))
+	}
+	restarted := &Manager{loaded: map[string]*loadedCert{}}
+	restarted.loadStored()
+	if _, ok := restarted.loaded["*.alice.apps.test"]; !ok {
+		t.Fatalf("wildcard não recarregado do store: %v", restarted.loaded)
+	}
+	if name, _, ok := m.CertificateFor("Shop.Example.com"); !ok || name != "shop.example.com" {
+		t.Fatalf("CertificateFor = %q, %v", name, ok)
+	}
+}
+
+func TestHTTP01SolverServesOnlyPresentedTokens(t *testing.T) {
+	solver := NewHTTP01Solver()
+	if err := solver.Present(context.Background(), "shop.example.com", "token-a", "token-a.thumb"); err != nil {
+		t.Fatalf("Present: %v", err)
+	}
+
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		solver.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, rec.Body.String()
+	}
+	if code, body := get(HTTP01Path + "token-a"); code != http.StatusOK || body != "token-a.thumb" {
+		t.Fatalf("resposta %d %q", code, body)
+	}
+	if code, _ := get(HTTP01Path + "token-b"); code != http.StatusNotFound {
+		t.Fatalf("token desconhecido respondeu %d", code)
+	}
+
+	_ = solver.CleanUp(context.Background(), "shop.example.com", "token-a", "")
+	if code, _ := get(HTTP01Path + "token-a"); code != http.StatusNotFound {
+		t.Fatalf("token removido respondeu %d", code)
+	}
+}
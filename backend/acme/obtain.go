@@ -0,0 +1,148 @@
+//backend/acme/obtain.go
+
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	xacme "golang.org/x/crypto/acme"
+)
+
+// 🧭 Escolhe o solver de cada nome (nil = nome sem solver disponível)
+type SolverFunc func(domain string, wildcard bool) Solver
+
+// 🔐 Cliente ACME (RFC 8555, golang.org/x/crypto/acme) com a chave da conta
+func newClient(directory string, httpClient *http.Client, key crypto.Signer) *xacme.Client {
+	return &xacme.Client{
+		Key:          key,
+		DirectoryURL: directory,
+		HTTPClient:   httpClient,
+		UserAgent:    "virtuscloud",
+	}
+}
+
+// 👤 Cria a conta (ou recupera a existente para a mesma chave) aceitando os termos de serviço
+func register(ctx context.Context, client *xacme.Client, email string) error {
+	account := &xacme.Account{}
+	if email != "" {
+		account.Contact = []string{"mailto:" + email}
+	}
+	_, err := client.Register(ctx, account, xacme.AcceptTOS)
+	if err != nil && !errors.Is(err, xacme.ErrAccountAlreadyExists) {
+		return fmt.Errorf("erro ao registrar conta ACME: %w", err)
+	}
+	return nil
+}
+
+// 📜 Emite um certificado para names: pedido, desafios, CSR assinado por certKey e download da cadeia (PEM)
+func obtain(ctx context.Context, client *xacme.Client, names []string, certKey crypto.Signer, choose SolverFunc) ([]byte, error) {
+	order, err := client.AuthorizeOrder(ctx, xacme.DomainIDs(names...))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar pedido ACME: %w", err)
+	}
+
+	orderURL := order.URI
+
+	for _, authzURL := range order.AuthzURLs {
+		if err := authorize(ctx, client, authzURL, choose); err != nil {
+			return nil, err
+		}
+	}
+	if order, err = client.WaitOrder(ctx, orderURL); err != nil {
+		return nil, fmt.Errorf("erro ao aguardar pedido ACME: %w", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: names[0]},
+		DNSNames: names,
+	}, certKey)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar CSR: %w", err)
+	}
+
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		// 🔁 Servidores que respondem o finalize sem Location (ex.: Pebble): aguarda pelo URL do pedido
+		done, waitErr := client.WaitOrder(ctx, orderURL)
+		if waitErr != nil || done.Status != xacme.StatusValid {
+			return nil, fmt.Errorf("erro ao finalizar pedido ACME: %w", err)
+		}
+		if chain, err = client.FetchCert(ctx, done.CertURL, true); err != nil {
+			return nil, fmt.Errorf("erro ao baixar certificado: %w", err)
+		}
+	}
+	var out []byte
+	for _, der := range chain {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	return out, nil
+}
+
+// ✅ Cumpre o desafio de uma autorização pendente com o solver escolhido
+func authorize(ctx context.Context, client *xacme.Client, authzURL string, choose SolverFunc) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("erro ao ler autorização ACME: %w", err)
+	}
+	if authz.Status == xacme.StatusValid {
+		return nil // autorização ainda válida de um pedido anterior
+	}
+
+	domain := authz.Identifier.Value
+	solver := choose(domain, authz.Wildcard)
+	if solver == nil {
+		return fmt.Errorf("nenhum desafio disponível para %s", domain)
+	}
+	var challenge *xacme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == solver.Type() {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("servidor ACME não oferece %s para %s", solver.Type(), domain)
+	}
+
+	response, err := challengeResponse(client, solver.Type(), challenge.Token)
+	if err != nil {
+		return err
+	}
+	if err := solver.Present(ctx, domain, challenge.Token, response); err != nil {
+		return err
+	}
+	defer func() {
+		if err := solver.CleanUp(context.Background(), domain, challenge.Token, response); err != nil {
+			log.Printf("⚠️ ACME: erro ao limpar desafio %s de %s: %v", solver.Type(), domain, err)
+		}
+	}()
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("erro ao aceitar desafio %s de %s: %w", solver.Type(), domain, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authzURL); err != nil {
+		return fmt.Errorf("autorização de %s recusada: %w", domain, err)
+	}
+	return nil
+}
+
+// 🔑 Resposta publicada pelo solver: keyAuthorization (HTTP-01) ou o valor do TXT (DNS-01)
+func challengeResponse(client *xacme.Client, challengeType, token string) (string, error) {
+	switch challengeType {
+	case "http-01":
+		return client.HTTP01ChallengeResponse(token)
+	case "dns-01":
+		return client.DNS01ChallengeRecord(token)
+	default:
+		return "", fmt.Errorf("desafio %s não suportado", challengeType)
+	}
+}
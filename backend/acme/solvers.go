@@ -0,0 +1,161 @@
+//backend/acme/solvers.go
+
+package acme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🧩 Publica e remove a resposta de um tipo de desafio
+type Solver interface {
+	Type() string // "http-01" ou "dns-01"
+	Present(ctx context.Context, domain, token, response string) error
+	CleanUp(ctx context.Context, domain, token, response string) error
+}
+
+// 📁 Caminho das respostas HTTP-01
+const HTTP01Path = "/.well-known/acme-challenge/"
+
+// 🌐 HTTP-01: responde /.well-known/acme-challenge/<token> na porta 80 do ingress
+type HTTP01Solver struct {
+	mu     sync.RWMutex
+	tokens map[string]string // token → keyAuthorization
+}
+
+func NewHTTP01Solver() *HTTP01Solver {
+	return &HTTP01Solver{tokens: map[string]string{}}
+}
+
+func (s *HTTP01Solver) Type() string { return "http-01" }
+
+func (s *HTTP01Solver) Present(_ context.Context, _, token, response string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token] = response
+	return nil
+}
+
+func (s *HTTP01Solver) CleanUp(_ context.Context, _, token, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, token)
+	return nil
+}
+
+// 📨 Entrega a keyAuthorization do token pedido (404 para tokens desconhecidos)
+func (s *HTTP01Solver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, HTTP01Path)
+	s.mu.RLock()
+	keyAuth, ok := s.tokens[token]
+	s.mu.RUnlock()
+	if !ok || token == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(keyAuth))
+}
+
+// 🗂️ Cria e remove registros TXT no provedor de DNS da zona
+type DNSProvider interface {
+	Present(ctx context.Context, fqdn, value string) error
+	CleanUp(ctx context.Context, fqdn, value string) error
+}
+
+// 🧾 DNS-01: TXT _acme-challenge.<domínio> via DNSProvider (necessário para wildcards)
+type DNS01Solver struct {
+	Provider    DNSProvider
+	Propagation time.Duration // espera após publicar, antes de avisar o servidor
+}
+
+func (s *DNS01Solver) Type() string { return "dns-01" }
+
+func (s *DNS01Solver) Present(ctx context.Context, domain, _, response string) error {
+	if err := s.Provider.Present(ctx, dns01Name(domain), response); err != nil {
+		return fmt.Errorf("erro ao publicar TXT de %s: %w", domain, err)
+	}
+	if s.Propagation > 0 {
+		return sleep(ctx, s.Propagation)
+	}
+	return nil
+}
+
+func (s *DNS01Solver) CleanUp(ctx context.Context, domain, _, response string) error {
+	return s.Provider.CleanUp(ctx, dns01Name(domain), response)
+}
+
+// 🏷️ "*.exemplo.com" → "_acme-challenge.exemplo.com."
+func dns01Name(domain string) string {
+	return "_acme-challenge." + strings.TrimSuffix(strings.TrimPrefix(domain, "*."), ".") + "."
+}
+
+// 🪝 Provedor via webhook: POST <URL>/present e <URL>/cleanup com {"fqdn","value"}
+type WebhookProvider struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func (p *WebhookProvider) Present(ctx context.Context, fqdn, value string) error {
+	return postJSON(ctx, p.HTTPClient, strings.TrimSuffix(p.URL, "/")+"/present", map[string]string{"fqdn": fqdn, "value": value})
+}
+
+func (p *WebhookProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return postJSON(ctx, p.HTTPClient, strings.TrimSuffix(p.URL, "/")+"/cleanup", map[string]string{"fqdn": fqdn, "value": value})
+}
+
+// 🧪 Provedor para o pebble-challtestsrv (API de gestão, ex.: http://localhost:8055)
+type ChallTestSrvProvider struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func (p *ChallTestSrvProvider) Present(ctx context.Context, fqdn, value string) error {
+	return postJSON(ctx, p.HTTPClient, strings.TrimSuffix(p.URL, "/")+"/set-txt", map[string]string{"host": fqdn, "value": value})
+}
+
+func (p *ChallTestSrvProvider) CleanUp(ctx context.Context, fqdn, _ string) error {
+	return postJSON(ctx, p.HTTPClient, strings.TrimSuffix(p.URL, "/")+"/clear-txt", map[string]string{"host": fqdn})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s respondeu %s", url, resp.Status)
+	}
+	return nil
+}
+
+// ⏱️ Espera respeitando o cancelamento do contexto
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,333 @@
+//backend/applogs/collector.go
+
+package applogs
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+)
+
+// ⚙️ Configuração do coletor
+type Config struct {
+	RotateBytes   int64         // tamanho que fecha o arquivo atual (APP_LOGS_ROTATE_MB, padrão 10)
+	SyncInterval  time.Duration // procura aplicações novas/removidas
+	SweepInterval time.Duration // aplica a retenção do plano
+	RetryDelay    time.Duration // espera após falha do stream
+	MinRetainDays int           // piso da retenção (usuário removido ou plano sem valor)
+}
+
+// ⚙️ Padrões com APP_LOGS_ROTATE_MB
+func ConfigFromEnv() Config {
+	cfg := Config{
+		RotateBytes:   10 * 1024 * 1024,
+		SyncInterval:  15 * time.Second,
+		SweepInterval: time.Hour,
+		RetryDelay:    5 * time.Second,
+		MinRetainDays: 1,
+	}
+	if mb, err := strconv.Atoi(os.Getenv("APP_LOGS_ROTATE_MB")); err == nil && mb > 0 {
+		cfg.RotateBytes = int64(mb) * 1024 * 1024
+	}
+	return cfg
+}
+
+// 🧺 Um coletor por aplicação, seguindo o container entre reinícios e recriações
+type collector struct {
+	appID  string
+	cancel context.CancelFunc
+}
+
+var (
+	mu         sync.Mutex
+	collectors = map[string]*collector{}
+	cfg        Config
+)
+
+// 🚀 Inicia a coleta contínua de stdout/stderr de todas as aplicações e a limpeza por retenção
+func Start(c Config) {
+	cfg = c
+	go func() {
+		for {
+			syncCollectors()
+			time.Sleep(cfg.SyncInterval)
+		}
+	}()
+	go func() {
+		for {
+			Sweep()
+			time.Sleep(cfg.SweepInterval)
+		}
+	}()
+	log.Println("🧺 Coletor de logs das aplicações iniciado")
+}
+
+// 🔄 Um coletor para cada aplicação com container; encerra os de aplicações removidas
+func syncCollectors() {
+	apps := map[string]*models.App{}
+	for _, app := range store.AppStore.List() {
+		if app.ContainerName != "" {
+			apps[app.ID] = app
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for id, c := range collectors {
+		if _, ok := apps[id]; !ok {
+			c.cancel()
+			delete(collectors, id)
+		}
+	}
+	for id, app := range apps {
+		if _, ok := collectors[id]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		c := &collector{appID: id, cancel: cancel}
+		collectors[id] = c
+		go c.run(ctx, app)
+	}
+}
+
+func (c *collector) run(ctx context.Context, app *models.App) {
+	defer func() {
+		mu.Lock()
+		if collectors[c.appID] == c {
+			delete(collectors, c.appID)
+		}
+		mu.Unlock()
+	}()
+
+	w, err := openWriter(AppDir(app.Username, app.ID), cfg.RotateBytes)
+	if err != nil {
+		log.Printf("⚠️ Coletor de logs: %v", err)
+		return
+	}
+	defer w.close()
+
+	for {
+		opts := services.LogStreamOptions{Stdout: true, Stderr: true, Follow: true, Tail: -1, After: w.last}
+		err := services.StreamAppLogs(ctx, app, opts, func(line services.LogLine) error {
+			if line.Stream == services.LogStreamSystem || line.Time.IsZero() {
+				return nil // avisos da plataforma não são saída da aplicação
+			}
+			return w.write(line.Time, line.Stream, line.Text)
+		})
+		if ctx.Err() != nil || errors.Is(err, services.ErrLogStreamGone) {
+			return
+		}
+		if err != nil {
+			log.Printf("⚠️ Coletor de logs de %s: %v (nova tentativa em %s)", app.ID, err, cfg.RetryDelay)
+		}
+		select {
+		case <-time.After(cfg.RetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ✍️ Arquivo atual da aplicação, rotacionado por tamanho e por dia (UTC)
+type writer struct {
+	dir         string
+	rotateBytes int64
+	f           *os.File
+	size        int64
+	first       time.Time // primeira linha do arquivo atual
+	last        time.Time // última linha gravada (cursor de retomada)
+}
+
+func openWriter(dir string, rotateBytes int64) (*writer, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de logs %s: %w", dir, err)
+	}
+	w := &writer{dir: dir, rotateBytes: rotateBytes}
+	path := filepath.Join(dir, currentFile)
+	if t, ok := lastLineTime(path); ok {
+		w.last = t
+		w.first, _ = firstLineTime(path)
+	} else if raw, err := os.ReadFile(filepath.Join(dir, cursorFile)); err == nil {
+		w.last, _ = time.Parse(time.RFC3339Nano, string(raw))
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *writer) open() error {
+	f, err := os.OpenFile(filepath.Join(w.dir, currentFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir log atual: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("erro ao abrir log atual: %w", err)
+	}
+	w.f, w.size = f, info.Size()
+	return nil
+}
+
+func (w *writer) write(t time.Time, stream, text string) error {
+	l := lockDir(w.dir)
+	l.Lock()
+	defer l.Unlock()
+
+	line := formatLine(t, stream, text)
+	if w.size > 0 && (w.size+int64(len(line)) > w.rotateBytes || !sameDay(w.first, t)) {
+		if err := w.rotate(); err != nil {
+			log.Printf("⚠️ Coletor de logs: erro ao rotacionar %s: %v", w.dir, err)
+		}
+	}
+	n, err := io.WriteString(w.f, line)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar log: %w", err)
+	}
+	if w.first.IsZero() {
+		w.first = t
+	}
+	w.last = t
+	return nil
+}
+
+func sameDay(a, b time.Time) bool {
+	if a.IsZero() {
+		return true
+	}
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// 🗜️ Compacta o arquivo atual em <início>-<fim>.log.gz e começa outro (chamado com o lock do diretório)
+func (w *writer) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	current := filepath.Join(w.dir, currentFile)
+	first := w.first
+	if first.IsZero() {
+		first = w.last
+	}
+	if err := compressFile(current, filepath.Join(w.dir, archiveName(first, w.last))); err != nil {
+		_ = w.open()
+		return err
+	}
+	_ = os.WriteFile(filepath.Join(w.dir, cursorFile), []byte(w.last.UTC().Format(time.RFC3339Nano)), 0644)
+	if err := os.Remove(current); err != nil {
+		return err
+	}
+	w.first = time.Time{}
+	return w.open()
+}
+
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if cerr := gz.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erro ao compactar log: %w", err)
+	}
+	return os.Rename(tmp, dst)
+}
+
+func (w *writer) close() {
+	l := lockDir(w.dir)
+	l.Lock()
+	defer l.Unlock()
+	w.f.Close()
+}
+
+// 🧹 Remove arquivados mais antigos que a retenção do plano do dono.
+// Logs de aplicações removidas seguem a mesma regra e o diretório some quando esvazia.
+func Sweep() {
+	usersDir := filepath.Join("storage", "users")
+	users, err := os.ReadDir(usersDir)
+	if err != nil {
+		return
+	}
+	for _, u := range users {
+		root := filepath.Join(usersDir, u.Name(), "container-logs")
+		apps, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		cutoff := time.Now().AddDate(0, 0, -retentionDays(u.Name()))
+		for _, a := range apps {
+			sweepApp(filepath.Join(root, a.Name()), a.Name(), cutoff)
+		}
+	}
+}
+
+func retentionDays(username string) int {
+	days := 0
+	if user, _ := store.UserStore.Get(username); user != nil {
+		days = models.Plans[user.Plan].LogRetentionDays
+	}
+	if days < cfg.MinRetainDays {
+		days = cfg.MinRetainDays
+	}
+	return days
+}
+
+func sweepApp(dir, appID string, cutoff time.Time) {
+	l := lockDir(dir)
+	l.Lock()
+	defer l.Unlock()
+
+	files, err := listFiles(dir)
+	if err != nil {
+		return
+	}
+	_, active := store.AppStore.Get(appID)
+	removed := 0
+	for _, f := range files {
+		if f.To.IsZero() || !f.To.Before(cutoff) {
+			continue
+		}
+		if f.Name == currentFile && active {
+			continue // o coletor ainda escreve nele; rotaciona na próxima linha
+		}
+		if err := os.Remove(filepath.Join(dir, f.Name)); err == nil {
+			removed++
+		}
+	}
+	if removed > 0 {
+		log.Printf("🧹 Logs de %s: %d arquivo(s) além da retenção removidos", appID, removed)
+	}
+	if !active && removed == len(files) {
+		_ = os.Remove(filepath.Join(dir, cursorFile))
+		_ = os.Remove(dir)
+	}
+}
@@ -0,0 +1,293 @@
+//backend/applogs/collector_test.go
+
+package applogs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/store"
+)
+
+// 🧪 Os testes rodam numa pasta temporária (storage/ e database/ relativos)
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "applogs-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	persistence.SetBackend(persistence.NewJSONBackend())
+	if err := store.UserStore.Load("./database/users.json"); err != nil {
+		fmt.Println("erro ao carregar usuários:", err)
+		return 1
+	}
+	if err := store.LoadAppStoreFromDisk("./database/appstore.json"); err != nil {
+		fmt.Println("erro ao carregar aplicações:", err)
+		return 1
+	}
+	cfg = ConfigFromEnv()
+	return m.Run()
+}
+
+// 📄 Linhas de um arquivo guardado (descompacta os arquivados)
+func readLogFile(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("erro ao abrir %s: %v", path, err)
+	}
+	defer f.Close()
+	r, err := lineReader(f)
+	if err != nil {
+		t.Fatalf("lineReader: %v", err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, ok := parseLine(scanner.Text())
+		if !ok {
+			t.Fatalf("linha fora do formato: %q", scanner.Text())
+		}
+		lines = append(lines, line.Text)
+	}
+	return lines
+}
+
+// 🗜️ Arquivado com uma linha, cobrindo o intervalo dado
+func writeArchive(t *testing.T, dir string, from, to time.Time) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatalf("erro ao criar pasta: %v", err)
+	}
+	path := filepath.Join(dir, archiveName(from, to))
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("erro ao criar arquivado: %v", err)
+	}
+	gz := gzip.NewWriter(f)
+	_, _ = gz.Write([]byte(formatLine(from, "stdout", "antiga")))
+	gz.Close()
+	f.Close()
+	return filepath.Base(path)
+}
+
+func TestWriterRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	lineSize := int64(len(formatLine(base, "stdout", "linha 0")))
+	w, err := openWriter(dir, 3*lineSize) // cabem 3 linhas por arquivo
+	if err != nil {
+		t.Fatalf("openWriter: %v", err)
+	}
+	for i := 0; i < 7; i++ {
+		if err := w.write(base.Add(time.Duration(i)*time.Second), "stdout", fmt.Sprintf("linha %d", i)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	w.close()
+
+	files, err := listFiles(dir)
+	if err != nil {
+		t.Fatalf("listFiles: %v", err)
+	}
+	if len(files) != 3 || !files[0].Compressed || !files[1].Compressed || files[2].Name != currentFile {
+		t.Fatalf("esperado 2 arquivados + atual, veio %+v", files)
+	}
+	if !files[0].From.Equal(base) || !files[0].To.Equal(base.Add(2*time.Second)) {
+		t.Fatalf("intervalo do primeiro arquivado: %v → %v", files[0].From, files[0].To)
+	}
+	if got := strings.Join(readLogFile(t, filepath.Join(dir, files[1].Name)), ","); got != "linha 3,linha 4,linha 5" {
+		t.Fatalf("segundo arquivado: %q", got)
+	}
+	if got := strings.Join(readLogFile(t, filepath.Join(dir, currentFile)), ","); got != "linha 6" {
+		t.Fatalf("arquivo atual: %q", got)
+	}
+	if !files[2].From.Equal(base.Add(6 * time.Second)) {
+		t.Fatalf("início do atual = %v", files[2].From)
+	}
+}
+
+func TestWriterRotatesByDay(t *testing.T) {
+	dir := t.TempDir()
+	w, err := openWriter(dir, 1024*1024)
+	if err != nil {
+		t.Fatalf("openWriter: %v", err)
+	}
+	night := time.Date(2024, 5, 1, 23, 59, 59, 0, time.UTC)
+	_ = w.write(night, "stdout", "antes da meia-noite")
+	_ = w.write(night.Add(2*time.Second), "stderr", "depois da meia-noite")
+	w.close()
+
+	files, _ := listFiles(dir)
+	if len(files) != 2 || files[0].Name != archiveName(night, night) {
+		t.Fatalf("virada do dia não rotacionou: %+v", files)
+	}
+	if got := readLogFile(t, filepath.Join(dir, currentFile)); len(got) != 1 || got[0] != "depois da meia-noite" {
+		t.Fatalf("arquivo atual: %q", got)
+	}
+}
+
+func TestOpenWriterResumesFromLastLine(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	w, _ := openWriter(dir, 1024*1024)
+	_ = w.write(base, "stdout", "um")
+	_ = w.write(base.Add(time.Second), "stdout", "dois")
+	w.close()
+
+	// Reinício do backend: o cursor vem da última linha do arquivo atual
+	w, err := openWriter(dir, 1024*1024)
+	if err != nil {
+		t.Fatalf("openWriter: %v", err)
+	}
+	if !w.last.Equal(base.Add(time.Second)) || !w.first.Equal(base) {
+		t.Fatalf("cursor = %v (início %v)", w.last, w.first)
+	}
+
+	// Arquivo atual recém-rotacionado (vazio): o cursor vem do arquivo cursor
+	l := lockDir(dir)
+	l.Lock()
+	err = w.rotate()
+	l.Unlock()
+	w.close()
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	w, _ = openWriter(dir, 1024*1024)
+	defer w.close()
+	if !w.last.Equal(base.Add(time.Second)) {
+		t.Fatalf("cursor após rotação = %v", w.last)
+	}
+}
+
+func TestSweepAppliesPlanRetention(t *testing.T) {
+	if err := store.UserStore.Save(&models.User{Username: "keeper", Plan: models.PlanPro}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+	if err := store.AppStore.Save(&models.App{ID: "live1", Username: "keeper", ContainerName: "keeper-live1"}); err != nil {
+		t.Fatalf("erro ao salvar aplicação: %v", err)
+	}
+	now := time.Now().UTC()
+	old := now.AddDate(0, 0, -10) // plano pro guarda 7 dias
+	recent := now.AddDate(0, 0, -2)
+
+	// 🟢 Aplicação ativa: arquivado vencido sai, recente fica, atual nunca é apagado
+	liveDir := AppDir("keeper", "live1")
+	expired := writeArchive(t, liveDir, old, old.Add(time.Hour))
+	kept := writeArchive(t, liveDir, recent, recent.Add(time.Hour))
+	current := filepath.Join(liveDir, currentFile)
+	_ = os.WriteFile(current, []byte(formatLine(old, "stdout", "velha")), 0644)
+	_ = os.Chtimes(current, old, old)
+
+	// 🗑️ Aplicação removida: tudo vencido sai e o diretório some
+	goneDir := AppDir("keeper", "gone1")
+	writeArchive(t, goneDir, old, old.Add(time.Hour))
+	_ = os.WriteFile(filepath.Join(goneDir, currentFile), []byte(formatLine(old, "stdout", "velha")), 0644)
+	_ = os.Chtimes(filepath.Join(goneDir, currentFile), old, old)
+	_ = os.WriteFile(filepath.Join(goneDir, cursorFile), []byte(old.Format(time.RFC3339Nano)), 0644)
+
+	// 👻 Usuário removido: vale o piso de retenção
+	orphanDir := AppDir("ghost", "orphan1")
+	orphanKept := writeArchive(t, orphanDir, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	orphanExpired := writeArchive(t, orphanDir, now.AddDate(0, 0, -3), now.AddDate(0, 0, -3).Add(time.Hour))
+
+	Sweep()
+
+	if _, err := os.Stat(filepath.Join(liveDir, expired)); !os.IsNotExist(err) {
+		t.Fatalf("arquivado além da retenção não foi removido")
+	}
+	if _, err := os.Stat(filepath.Join(liveDir, kept)); err != nil {
+		t.Fatalf("arquivado dentro da retenção removido: %v", err)
+	}
+	if _, err := os.Stat(current); err != nil {
+		t.Fatalf("arquivo atual de aplicação ativa removido: %v", err)
+	}
+	if _, err := os.Stat(goneDir); !os.IsNotExist(err) {
+		t.Fatalf("diretório de aplicação removida continua após vencer")
+	}
+	if _, err := os.Stat(filepath.Join(orphanDir, orphanKept)); err != nil {
+		t.Fatalf("arquivado do último dia removido: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(orphanDir, orphanExpired)); !os.IsNotExist(err) {
+		t.Fatalf("arquivado de usuário removido além do piso não foi removido")
+	}
+}
+
+func TestSearchAcrossFiles(t *testing.T) {
+	dir := AppDir("searcher", "s1")
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	w, err := openWriter(dir, int64(len(formatLine(base, "stdout", "GET /api 200")))*2)
+	if err != nil {
+		t.Fatalf("openWriter: %v", err)
+	}
+	for i, l := range []struct{ stream, text string }{
+		{"stdout", "GET /api 200"}, {"stderr", "erro: timeout"}, {"stdout", "GET /api 500"},
+		{"stderr", "Erro: conexão"}, {"stdout", "GET /ok 200"},
+	} {
+		_ = w.write(base.Add(time.Duration(i)*time.Minute), l.stream, l.text)
+	}
+	w.close()
+
+	texts := func(r *SearchResult) string {
+		var parts []string
+		for _, m := range r.Matches {
+			parts = append(parts, m.Text)
+		}
+		return strings.Join(parts, "|")
+	}
+	cases := []struct {
+		name      string
+		q         Query
+		want      string
+		truncated bool
+	}{
+		{"texto sem diferenciar maiúsculas", Query{Text: "erro"}, "erro: timeout|Erro: conexão", false},
+		{"regex", Query{Text: `GET /api (2|5)00`, Regex: true}, "GET /api 200|GET /api 500", false},
+		{"stream", Query{Stream: "stderr"}, "erro: timeout|Erro: conexão", false},
+		{"intervalo", Query{Since: base.Add(2 * time.Minute), Until: base.Add(3 * time.Minute)}, "GET /api 500|Erro: conexão", false},
+		{"limite fica com as mais recentes", Query{Text: "GET", Limit: 2}, "GET /api 500|GET /ok 200", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := Search("searcher", "s1", tc.q)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if got := texts(r); got != tc.want || r.Truncated != tc.truncated {
+				t.Fatalf("resultado %q (truncado=%v), esperado %q (truncado=%v)", got, r.Truncated, tc.want, tc.truncated)
+			}
+		})
+	}
+
+	if _, err := Search("searcher", "s1", Query{Text: "(", Regex: true}); err == nil {
+		t.Fatalf("regex inválida deveria falhar")
+	}
+	if _, err := Search("searcher", "s1", Query{Stream: "stdin"}); err == nil {
+		t.Fatalf("stream inválido deveria falhar")
+	}
+}
@@ -0,0 +1,388 @@
+//backend/applogs/files.go
+
+package applogs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 📏 Rotação e consulta
+const (
+	currentFile    = "current.log"
+	cursorFile     = "cursor"
+	archiveSuffix  = ".log.gz"
+	archiveStamp   = "20060102T150405.000Z"
+	MaxSearchLimit = 1000
+	maxPatternLen  = 256
+)
+
+// ❗ Erros de consulta (o handler escolhe o status HTTP com errors.Is)
+var (
+	ErrInvalid  = errors.New("consulta de logs inválida")
+	ErrNotFound = errors.New("arquivo de log não encontrado")
+)
+
+var appIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// 🔒 Um mutex por diretório: coletor escrevendo/rotacionando vs. leitores abrindo arquivos
+var (
+	dirMu    sync.Mutex
+	dirLocks = map[string]*sync.Mutex{}
+)
+
+func lockDir(dir string) *sync.Mutex {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	l, ok := dirLocks[dir]
+	if !ok {
+		l = &sync.Mutex{}
+		dirLocks[dir] = l
+	}
+	return l
+}
+
+// 📁 storage/users/<usuário>/container-logs/<app>
+func AppDir(username, appID string) string {
+	return filepath.Join("storage", "users", username, "container-logs", appID)
+}
+
+// 🔍 Indica se há logs guardados da aplicação (inclusive de aplicações já removidas)
+func Exists(username, appID string) bool {
+	if !appIDPattern.MatchString(appID) {
+		return false
+	}
+	info, err := os.Stat(AppDir(username, appID))
+	return err == nil && info.IsDir()
+}
+
+// 🗂️ Arquivo de log guardado
+type File struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	Compressed bool      `json:"compressed"`
+	From       time.Time `json:"from,omitempty"`
+	To         time.Time `json:"to,omitempty"`
+}
+
+// 🗂️ Arquivos da aplicação em ordem cronológica (o atual por último)
+func ListFiles(username, appID string) ([]File, error) {
+	dir := AppDir(username, appID)
+	l := lockDir(dir)
+	l.Lock()
+	defer l.Unlock()
+	return listFiles(dir)
+}
+
+func listFiles(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []File{}, nil
+		}
+		return nil, fmt.Errorf("erro ao listar logs: %w", err)
+	}
+	files := []File{}
+	var current *File
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() {
+			continue
+		}
+		switch {
+		case e.Name() == currentFile:
+			current = &File{Name: currentFile, Size: info.Size(), To: info.ModTime().UTC()}
+		case strings.HasSuffix(e.Name(), archiveSuffix):
+			f := File{Name: e.Name(), Size: info.Size(), Compressed: true}
+			f.From, f.To = archiveRange(e.Name())
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	if current != nil {
+		if first, ok := firstLineTime(filepath.Join(dir, currentFile)); ok {
+			current.From = first
+		}
+		files = append(files, *current)
+	}
+	return files, nil
+}
+
+// 🏷️ <início>-<fim>.log.gz
+func archiveName(from, to time.Time) string {
+	return from.UTC().Format(archiveStamp) + "-" + to.UTC().Format(archiveStamp) + archiveSuffix
+}
+
+func archiveRange(name string) (time.Time, time.Time) {
+	from, to, _ := strings.Cut(strings.TrimSuffix(name, archiveSuffix), "-")
+	f, _ := time.Parse(archiveStamp, from)
+	t, _ := time.Parse(archiveStamp, to)
+	return f, t
+}
+
+// 📖 Abre os arquivos pedidos sob o lock (rotação depois não afeta descritores já abertos)
+func openFiles(dir string, files []File) ([]*os.File, error) {
+	opened := make([]*os.File, 0, len(files))
+	for _, f := range files {
+		fh, err := os.Open(filepath.Join(dir, f.Name))
+		if err != nil {
+			closeAll(opened)
+			return nil, fmt.Errorf("erro ao abrir log %s: %w", f.Name, err)
+		}
+		opened = append(opened, fh)
+	}
+	return opened, nil
+}
+
+func closeAll(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// 📖 Leitor de linhas do arquivo (descompacta os arquivados)
+func lineReader(f *os.File) (io.Reader, error) {
+	if !strings.HasSuffix(f.Name(), archiveSuffix) {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao descompactar %s: %w", filepath.Base(f.Name()), err)
+	}
+	return gz, nil
+}
+
+// 📄 Linha guardada: "<RFC3339Nano> <stdout|stderr> <texto>"
+type Line struct {
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Text   string    `json:"line"`
+}
+
+func formatLine(t time.Time, stream, text string) string {
+	return t.UTC().Format(time.RFC3339Nano) + " " + stream + " " + text + "\n"
+}
+
+func parseLine(raw string) (Line, bool) {
+	stamp, rest, ok := strings.Cut(raw, " ")
+	if !ok {
+		return Line{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return Line{}, false
+	}
+	stream, text, _ := strings.Cut(rest, " ")
+	return Line{Time: t, Stream: stream, Text: text}, true
+}
+
+func firstLineTime(path string) (time.Time, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+	raw, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && raw == "" {
+		return time.Time{}, false
+	}
+	line, ok := parseLine(strings.TrimSuffix(raw, "\n"))
+	return line.Time, ok
+}
+
+// 🕒 Instante da última linha completa do arquivo (lê só o final)
+func lastLineTime(path string) (time.Time, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return time.Time{}, false
+	}
+	const window = 64 * 1024
+	offset := info.Size() - window
+	if offset < 0 {
+		offset = 0
+	}
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return time.Time{}, false
+	}
+	lines := bytes.Split(bytes.TrimRight(buf, "\n"), []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line, ok := parseLine(string(lines[i])); ok {
+			return line.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// 🔎 Critérios da busca
+type Query struct {
+	Text   string // texto (sem diferenciar maiúsculas) ou expressão regular com Regex
+	Regex  bool
+	Stream string    // "", stdout ou stderr
+	Since  time.Time // zero = sem limite
+	Until  time.Time // zero = sem limite
+	Limit  int       // máximo de linhas devolvidas (as mais recentes)
+}
+
+// 🔎 Resultado: linhas em ordem cronológica; Truncated indica que havia mais ocorrências antigas
+type SearchResult struct {
+	Matches   []Line `json:"matches"`
+	Scanned   int    `json:"scanned"`
+	Truncated bool   `json:"truncated"`
+}
+
+// 🔎 Busca textual em todos os arquivos da aplicação
+func Search(username, appID string, q Query) (*SearchResult, error) {
+	match, err := q.matcher()
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit <= 0 || q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+
+	dir := AppDir(username, appID)
+	l := lockDir(dir)
+	l.Lock()
+	files, err := listFiles(dir)
+	if err == nil {
+		files = filterRange(files, q.Since, q.Until)
+	}
+	var opened []*os.File
+	if err == nil {
+		opened, err = openFiles(dir, files)
+	}
+	l.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAll(opened)
+
+	result := &SearchResult{Matches: []Line{}}
+	for _, f := range opened {
+		r, err := lineReader(f)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line, ok := parseLine(scanner.Text())
+			if !ok {
+				continue
+			}
+			result.Scanned++
+			if (!q.Since.IsZero() && line.Time.Before(q.Since)) || (!q.Until.IsZero() && line.Time.After(q.Until)) {
+				continue
+			}
+			if (q.Stream != "" && line.Stream != q.Stream) || !match(line.Text) {
+				continue
+			}
+			if len(result.Matches) == q.Limit {
+				result.Matches = result.Matches[1:]
+				result.Truncated = true
+			}
+			result.Matches = append(result.Matches, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %w", filepath.Base(f.Name()), err)
+		}
+	}
+	return result, nil
+}
+
+func (q Query) matcher() (func(string) bool, error) {
+	if len(q.Text) > maxPatternLen {
+		return nil, fmt.Errorf("%w: texto com mais de %d caracteres", ErrInvalid, maxPatternLen)
+	}
+	if q.Stream != "" && q.Stream != "stdout" && q.Stream != "stderr" {
+		return nil, fmt.Errorf("%w: stream deve ser stdout ou stderr", ErrInvalid)
+	}
+	if q.Regex {
+		re, err := regexp.Compile("(?i)" + q.Text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: expressão regular: %v", ErrInvalid, err)
+		}
+		return re.MatchString, nil
+	}
+	needle := strings.ToLower(q.Text)
+	return func(text string) bool { return strings.Contains(strings.ToLower(text), needle) }, nil
+}
+
+// ⏱️ Descarta arquivos inteiros fora do intervalo (pelo nome dos arquivados / datas do atual)
+func filterRange(files []File, since, until time.Time) []File {
+	kept := files[:0]
+	for _, f := range files {
+		if !since.IsZero() && !f.To.IsZero() && f.To.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !f.From.IsZero() && f.From.After(until) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// 📥 Escreve em w um único arquivo (name) ou, sem name, todos como um gzip só
+// (membros gzip concatenados: os arquivados como estão, o atual compactado na hora)
+func Download(username, appID, name string, w io.Writer) error {
+	dir := AppDir(username, appID)
+	l := lockDir(dir)
+	l.Lock()
+	files, err := listFiles(dir)
+	if err == nil && name != "" {
+		files, err = pickFile(files, name)
+	}
+	var opened []*os.File
+	if err == nil {
+		opened, err = openFiles(dir, files)
+	}
+	l.Unlock()
+	if err != nil {
+		return err
+	}
+	defer closeAll(opened)
+
+	for _, f := range opened {
+		if name != "" || strings.HasSuffix(f.Name(), archiveSuffix) {
+			if _, err := io.Copy(w, f); err != nil {
+				return err
+			}
+			continue
+		}
+		gz := gzip.NewWriter(w)
+		if _, err := io.Copy(gz, f); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pickFile(files []File, name string) ([]File, error) {
+	for _, f := range files {
+		if f.Name == name {
+			return []File{f}, nil
+		}
+	}
+	return nil, ErrNotFound
+}
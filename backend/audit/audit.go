@@ -0,0 +1,169 @@
+//backend/audit/audit.go
+
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// 🎯 Resultado de uma ação auditada
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	OutcomeDenied  Outcome = "denied"
+)
+
+// 🤖 Ator usado quando a ação parte do próprio sistema (tarefas, migrações)
+const SystemActor = "system"
+
+// 📝 Registro imutável de uma ação de usuário ou admin
+type Event struct {
+	ID      string            `json:"id"`
+	Time    time.Time         `json:"time"`
+	Actor   string            `json:"actor"`
+	Role    string            `json:"role,omitempty"`
+	Action  string            `json:"action"`
+	Target  string            `json:"target,omitempty"`
+	IP      string            `json:"ip,omitempty"`
+	Outcome Outcome           `json:"outcome"`
+	Status  int               `json:"status,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// 🔎 Filtros de consulta (campos vazios não filtram)
+type Filter struct {
+	Actor   string
+	Action  string
+	Target  string
+	Outcome Outcome
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// 📏 Limite efetivo da consulta
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultLimit
+	}
+	if f.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return f.Limit
+}
+
+// ✅ Indica se o evento atende ao filtro
+func (f Filter) Match(e Event) bool {
+	if f.Actor != "" && e.Actor != f.Actor {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if f.Target != "" && e.Target != f.Target {
+		return false
+	}
+	if f.Outcome != "" && e.Outcome != f.Outcome {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// 🗄️ Armazenamento append-only: não há atualização nem remoção de eventos
+type Store interface {
+	// Append grava o evento de forma durável antes de retornar
+	Append(e Event) error
+	// Query devolve os eventos mais recentes primeiro
+	Query(f Filter) ([]Event, error)
+	Close() error
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store
+)
+
+// 🔌 Define o armazenamento de auditoria (fecha o anterior, se houver)
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if store != nil {
+		store.Close()
+	}
+	store = s
+}
+
+// 📝 Grava um evento; falhas são logadas e não interrompem a ação auditada
+func Record(e Event) {
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Outcome == "" {
+		e.Outcome = OutcomeSuccess
+	}
+
+	storeMu.RLock()
+	s := store
+	storeMu.RUnlock()
+
+	if s == nil {
+		log.Printf("⚠️ Auditoria não inicializada, evento descartado: %s %s %s", e.Actor, e.Action, e.Target)
+		return
+	}
+	if err := s.Append(e); err != nil {
+		log.Printf("❌ Erro ao gravar auditoria (%s %s %s): %v", e.Actor, e.Action, e.Target, err)
+	}
+}
+
+// 🔎 Consulta o log de auditoria
+func Query(f Filter) ([]Event, error) {
+	storeMu.RLock()
+	s := store
+	storeMu.RUnlock()
+
+	if s == nil {
+		return nil, fmt.Errorf("auditoria não inicializada")
+	}
+	return s.Query(f)
+}
+
+// 🔒 Fecha o armazenamento ativo
+func Close() error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if store == nil {
+		return nil
+	}
+	err := store.Close()
+	store = nil
+	return err
+}
+
+// 🆔 Identificador ordenável por tempo: <unix-nano hex>-<aleatório>
+func newID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%x-%s", time.Now().UnixNano(), hex.EncodeToString(b))
+}
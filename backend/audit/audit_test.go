@@ -0,0 +1,151 @@
+//backend/audit/audit_test.go
+
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// 📄 Log com eventos em ordem de gravação (um por minuto a partir de base)
+func seededStore(t *testing.T, events ...Event) *FileStore {
+	t.Helper()
+	s, err := OpenFileStore(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatalf("OpenFileStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	for i, e := range events {
+		e.ID = newID()
+		e.Time = base.Add(time.Duration(i) * time.Minute)
+		if e.Outcome == "" {
+			e.Outcome = OutcomeSuccess
+		}
+		if err := s.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	return s
+}
+
+func targets(events []Event) string {
+	var out []string
+	for _, e := range events {
+		out = append(out, e.Target)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestFileStoreFilteredQueries(t *testing.T) {
+	s := seededStore(t,
+		Event{Actor: "ana", Action: "app.start", Target: "a1"},
+		Event{Actor: "bia", Action: "app.start", Target: "b1", Outcome: OutcomeDenied},
+		Event{Actor: "ana", Action: "app.delete", Target: "a2", Outcome: OutcomeFailure},
+		Event{Actor: "admin", Action: "user.plan", Target: "ana"},
+		Event{Actor: "ana", Action: "app.start", Target: "a3"},
+	)
+
+	cases := []struct {
+		name   string
+		filter Filter
+		want   string // alvos, mais recentes primeiro
+	}{
+		{"sem filtro", Filter{}, "a3,ana,a2,b1,a1"},
+		{"ator", Filter{Actor: "ana"}, "a3,a2,a1"},
+		{"ação", Filter{Action: "app.start"}, "a3,b1,a1"},
+		{"alvo", Filter{Target: "ana"}, "ana"},
+		{"resultado", Filter{Outcome: OutcomeDenied}, "b1"},
+		{"ator e ação", Filter{Actor: "ana", Action: "app.start"}, "a3,a1"},
+		{"desde", Filter{Since: base.Add(2 * time.Minute)}, "a3,ana,a2"},
+		{"até", Filter{Until: base.Add(time.Minute)}, "b1,a1"},
+		{"intervalo", Filter{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)}, "ana,a2,b1"},
+		{"limite fica com os mais recentes", Filter{Actor: "ana", Limit: 2}, "a3,a2"},
+		{"nada casa", Filter{Actor: "ninguém"}, ""},
+	}
+	for _, tc := range cases {
+		events, err := s.Query(tc.filter)
+		if err != nil {
+			t.Fatalf("%s: Query: %v", tc.name, err)
+		}
+		if got := targets(events); got != tc.want {
+			t.Fatalf("%s: alvos = %q, esperado %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFileStoreSkipsTruncatedLine(t *testing.T) {
+	s := seededStore(t, Event{Actor: "ana", Action: "app.start", Target: "a1"})
+	// ✂️ Queda no meio de uma gravação, seguida de um evento íntegro
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("erro ao abrir log: %v", err)
+	}
+	f.WriteString(`{"id":"x","actor":"ana","act` + "\n")
+	f.Close()
+	if err := s.Append(Event{ID: newID(), Time: base.Add(time.Hour), Actor: "ana", Action: "app.stop", Target: "a1", Outcome: OutcomeSuccess}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	events, err := s.Query(Filter{Actor: "ana"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(events) != 2 || events[0].Action != "app.stop" {
+		t.Fatalf("eventos = %+v", events)
+	}
+}
+
+func TestFilterLimitBounds(t *testing.T) {
+	for limit, want := range map[int]int{0: DefaultLimit, -5: DefaultLimit, 10: 10, MaxLimit + 1: MaxLimit} {
+		if got := (Filter{Limit: limit}).limit(); got != want {
+			t.Fatalf("limit(%d) = %d, esperado %d", limit, got, want)
+		}
+	}
+}
+
+func TestMiddlewareRecordsOutcome(t *testing.T) {
+	s := seededStore(t)
+	SetStore(s)
+	defer SetStore(nil)
+
+	cases := []struct {
+		action  string
+		handler http.HandlerFunc
+		want    Outcome
+		errText string
+	}{
+		{"teste.ok", func(w http.ResponseWriter, r *http.Request) { SetTarget(r, "alvo"); SetDetail(r, "plano", "pro") }, OutcomeSuccess, ""},
+		{"teste.negado", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "sem acesso", http.StatusForbidden) }, OutcomeDenied, "sem acesso"},
+		{"teste.erro", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "quebrou", http.StatusInternalServerError) }, OutcomeFailure, "quebrou"},
+		{"teste.falha200", func(w http.ResponseWriter, r *http.Request) { Fail(r, "parcial") }, OutcomeFailure, "parcial"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/x?id=padrao", nil)
+		Middleware(tc.action, tc.handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		events, err := Query(Filter{Action: tc.action})
+		if err != nil || len(events) != 1 {
+			t.Fatalf("%s: eventos = %v (%v)", tc.action, events, err)
+		}
+		e := events[0]
+		if e.Outcome != tc.want || e.Error != tc.errText || e.Actor != "anonymous" {
+			t.Fatalf("%s: evento = %+v", tc.action, e)
+		}
+	}
+
+	ok, _ := Query(Filter{Action: "teste.ok"})
+	if ok[0].Target != "alvo" || ok[0].Details["plano"] != "pro" {
+		t.Fatalf("alvo/detalhe não gravados: %+v", ok[0])
+	}
+	denied, _ := Query(Filter{Action: "teste.negado"})
+	if denied[0].Target != "padrao" || denied[0].Status != http.StatusForbidden {
+		t.Fatalf("alvo padrão ou status errados: %+v", denied[0])
+	}
+}
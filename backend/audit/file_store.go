@@ -0,0 +1,113 @@
+//backend/audit/file_store.go
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// 📁 Arquivo padrão do log de auditoria (backend JSON)
+const DefaultFilePath = "./database/audit.log"
+
+// 📄 Log de auditoria em JSON Lines, aberto em O_APPEND e com fsync por evento
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// 📂 Abre (ou cria) o arquivo de auditoria
+func OpenFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório da auditoria: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir log de auditoria: %w", err)
+	}
+	return &FileStore{path: path, file: file}, nil
+}
+
+func (s *FileStore) Append(e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("log de auditoria fechado")
+	}
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("erro ao gravar auditoria: %w", err)
+	}
+	return s.file.Sync()
+}
+
+// 🔎 Varre o arquivo e devolve os eventos mais recentes primeiro
+func (s *FileStore) Query(f Filter) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return []Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler log de auditoria: %w", err)
+	}
+	defer file.Close()
+
+	limit := f.limit()
+	var matched []Event
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// Linha truncada por queda durante a escrita: ignora e segue
+			log.Printf("⚠️ Linha %d inválida em %s: %v", lineNo, s.path, err)
+			continue
+		}
+		if !f.Match(e) {
+			continue
+		}
+		matched = append(matched, e)
+		if len(matched) > limit {
+			matched = matched[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler log de auditoria: %w", err)
+	}
+
+	// 🔃 Mais recentes primeiro
+	out := make([]Event, len(matched))
+	for i, e := range matched {
+		out[len(matched)-1-i] = e
+	}
+	return out, nil
+}
+
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,169 @@
+//backend/audit/http.go
+
+package audit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/utils"
+)
+
+type contextKey struct{}
+
+// 🧾 Evento em construção durante a requisição
+type pending struct {
+	mu      sync.Mutex
+	event   Event
+	failure string
+}
+
+// 📦 Grava a ação da rota no log de auditoria após o handler responder.
+// Ator, role e IP vêm da requisição; o alvo padrão é o parâmetro "id" e pode
+// ser ajustado pelo handler com SetTarget. O resultado sai do status HTTP.
+func Middleware(action string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		username, role := middleware.GetUserFromContext(r)
+		p := &pending{event: Event{
+			Actor:  username,
+			Role:   role,
+			Action: action,
+			Target: r.URL.Query().Get("id"),
+			IP:     utils.GetRealIP(r),
+		}}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			p.mu.Lock()
+			e := p.event
+			failure := p.failure
+			p.mu.Unlock()
+
+			e.Status = rec.statusCode()
+			switch {
+			case e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden:
+				e.Outcome = OutcomeDenied
+			case e.Status >= 400:
+				e.Outcome = OutcomeFailure
+			case failure != "":
+				// Handlers que respondem erros com status 200
+				e.Outcome = OutcomeFailure
+				e.Error = failure
+			default:
+				e.Outcome = OutcomeSuccess
+			}
+			if e.Outcome != OutcomeSuccess && e.Error == "" {
+				e.Error = strings.TrimSpace(rec.errBody.String())
+			}
+			if e.Actor == "" {
+				e.Actor = "anonymous"
+			}
+			Record(e)
+		}()
+
+		ctx := context.WithValue(r.Context(), contextKey{}, p)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+	})
+}
+
+func fromRequest(r *http.Request) *pending {
+	p, _ := r.Context().Value(contextKey{}).(*pending)
+	return p
+}
+
+// 🎯 Define o alvo da ação (app, container, usuário...)
+func SetTarget(r *http.Request, target string) {
+	if p := fromRequest(r); p != nil {
+		p.mu.Lock()
+		p.event.Target = target
+		p.mu.Unlock()
+	}
+}
+
+// 👤 Define o ator em rotas públicas (login, logout), onde não há usuário no contexto
+func SetActor(r *http.Request, actor string) {
+	if p := fromRequest(r); p != nil {
+		p.mu.Lock()
+		p.event.Actor = actor
+		p.mu.Unlock()
+	}
+}
+
+// ❌ Marca a ação como falha mesmo quando a resposta sai com status 2xx
+func Fail(r *http.Request, reason string) {
+	if p := fromRequest(r); p != nil {
+		p.mu.Lock()
+		p.failure = reason
+		p.mu.Unlock()
+	}
+}
+
+// 🏷️ Anexa um detalhe ao evento (ex.: plano anterior e novo)
+func SetDetail(r *http.Request, key, value string) {
+	if p := fromRequest(r); p != nil {
+		p.mu.Lock()
+		if p.event.Details == nil {
+			p.event.Details = map[string]string{}
+		}
+		p.event.Details[key] = value
+		p.mu.Unlock()
+	}
+}
+
+// 📏 Máximo de bytes da resposta de erro guardados no evento
+const maxErrorBody = 256
+
+// 🎥 Captura status e o início do corpo das respostas de erro
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	errBody strings.Builder
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.status >= 400 && rec.errBody.Len() < maxErrorBody {
+		remaining := maxErrorBody - rec.errBody.Len()
+		if len(b) < remaining {
+			remaining = len(b)
+		}
+		rec.errBody.Write(b[:remaining])
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// 🚿 Mantém o suporte a streaming dos handlers embrulhados
+func (rec *statusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// 🔓 Dá acesso ao writer original (Hijack de WebSockets via http.ResponseController)
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+func (rec *statusRecorder) statusCode() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
@@ -0,0 +1,577 @@
+//backend/databases/backups.go
+
+package databases
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// ⚙️ Backups agendados: dumps lógicos feitos pelo cliente do próprio motor dentro do container
+type BackupConfig struct {
+	Interval      time.Duration // entre backups agendados de um banco (DATABASES_BACKUP_HOURS, padrão 24)
+	Retention     int           // backups mantidos nos planos com DailyBackups (DATABASES_BACKUP_RETENTION, padrão 7)
+	Timeout       time.Duration // prazo de um dump ou restauração (DATABASES_BACKUP_TIMEOUT_MINUTES, padrão 60)
+	CheckInterval time.Duration // de quanto em quanto tempo procura bancos com backup vencido
+	RetryDelay    time.Duration // espera depois de um backup agendado que falhou
+}
+
+// ⚙️ Configuração a partir do ambiente
+func BackupConfigFromEnv() BackupConfig {
+	cfg := BackupConfig{
+		Interval:      24 * time.Hour,
+		Retention:     7,
+		Timeout:       time.Hour,
+		CheckInterval: 15 * time.Minute,
+		RetryDelay:    time.Hour,
+	}
+	if hours, err := strconv.Atoi(os.Getenv("DATABASES_BACKUP_HOURS")); err == nil && hours > 0 {
+		cfg.Interval = time.Duration(hours) * time.Hour
+	}
+	if keep, err := strconv.Atoi(os.Getenv("DATABASES_BACKUP_RETENTION")); err == nil && keep > 0 {
+		cfg.Retention = keep
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("DATABASES_BACKUP_TIMEOUT_MINUTES")); err == nil && minutes > 0 {
+		cfg.Timeout = time.Duration(minutes) * time.Minute
+	}
+	return cfg
+}
+
+// 🔒 Um backup por banco de cada vez
+var (
+	backupMu  sync.Mutex
+	backingUp = map[string]bool{}
+	backupCfg = BackupConfigFromEnv()
+)
+
+// 📁 storage/users/<usuário>/databases/.backups/<id> (fora dos dados montados no container)
+func BackupDir(db *models.Database) string {
+	return filepath.Join("storage", "users", db.Username, "databases", ".backups", db.ID)
+}
+
+// 📄 Arquivo de um backup
+func BackupPath(db *models.Database, backup *models.DatabaseBackup) string {
+	return filepath.Join(BackupDir(db), backup.File)
+}
+
+// 📄 Cópia do backup de origem guardada para a restauração (sobrevive à retenção da origem)
+func restoreSourcePath(db *models.Database, e *Engine) string {
+	return filepath.Join(BackupDir(db), "restore"+e.dumpExt)
+}
+
+// 📋 Política de retenção do dono: com DailyBackups guarda os últimos N e agenda backups diários,
+// sem ele só o backup manual mais recente fica guardado
+func BackupPolicy(username string) (daily bool, keep int, err error) {
+	daily, err = limits.DailyDatabaseBackups(username)
+	if err != nil {
+		return false, 0, err
+	}
+	if daily {
+		return true, backupCfg.Retention, nil
+	}
+	return false, 1, nil
+}
+
+// 🔍 Backup do banco pelo ID
+func FindBackup(db *models.Database, backupID string) (*models.DatabaseBackup, bool) {
+	for i := range db.Backups {
+		if db.Backups[i].ID == backupID {
+			return &db.Backups[i], true
+		}
+	}
+	return nil, false
+}
+
+// ➕ Registra um backup em andamento (o dump roda em RunBackup)
+func BeginBackup(id, trigger string) (*models.DatabaseBackup, error) {
+	db, ok := store.DatabaseStore.Get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	e, ok := LookupEngine(db.Engine)
+	if !ok {
+		return nil, fmt.Errorf("%w: motor '%s'", ErrInvalid, db.Engine)
+	}
+	if db.Status != models.DatabaseRunning {
+		return nil, fmt.Errorf("%w: %s precisa estar ligado para o backup (status %s)", ErrBusy, db.Name, db.Status)
+	}
+
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	if backingUp[id] {
+		return nil, fmt.Errorf("%w: já existe um backup de %s em andamento", ErrBusy, db.Name)
+	}
+
+	suffix := make([]byte, 2)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("erro ao gerar ID do backup: %w", err)
+	}
+	now := time.Now()
+	stamp := now.UTC().Format("20060102-150405")
+	backup := models.DatabaseBackup{
+		ID:        "bk_" + stamp + "-" + hex.EncodeToString(suffix),
+		Trigger:   trigger,
+		Status:    models.BackupRunning,
+		File:      db.Name + "-" + stamp + "-" + hex.EncodeToString(suffix) + e.dumpExt,
+		StartedAt: now,
+	}
+	if _, err := store.DatabaseStore.Update(id, func(stored *models.Database) {
+		stored.Backups = append([]models.DatabaseBackup{backup}, stored.Backups...)
+	}); err != nil {
+		return nil, err
+	}
+	backingUp[id] = true
+	return &backup, nil
+}
+
+// 💾 Faz o dump registrado por BeginBackup, confere o arquivo e aplica a retenção
+func RunBackup(id, backupID string) error {
+	defer func() {
+		backupMu.Lock()
+		delete(backingUp, id)
+		backupMu.Unlock()
+	}()
+
+	db, ok := store.DatabaseStore.Get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	backup, ok := FindBackup(db, backupID)
+	if !ok {
+		return fmt.Errorf("%w: backup %s", ErrNotFound, backupID)
+	}
+
+	size, sum, objects, err := dump(db, BackupPath(db, backup))
+	now := time.Now()
+	if _, uerr := store.DatabaseStore.Update(id, func(stored *models.Database) {
+		stored.Backups = withBackup(stored.Backups, backupID, func(b *models.DatabaseBackup) {
+			b.CompletedAt = &now
+			if err != nil {
+				b.Status = models.BackupFailed
+				b.Error = err.Error()
+				return
+			}
+			b.Status = models.BackupReady
+			b.SizeBytes = size
+			b.SHA256 = sum
+			b.Objects = objects
+		})
+	}); uerr != nil {
+		return uerr
+	}
+	if err != nil {
+		log.Printf("❌ Backup %s do banco %s falhou: %v", backupID, db.ID, err)
+		return err
+	}
+	log.Printf("💾 Backup %s do banco %s pronto (%s, %d objetos)", backupID, db.ID, FormatBytes(size), objects)
+	pruneBackups(id)
+	return nil
+}
+
+// 💾 Roda o dump dentro do container e grava em path (via .part, para nunca sobrar arquivo pela metade)
+func dump(db *models.Database, path string) (int64, string, int, error) {
+	e, ok := LookupEngine(db.Engine)
+	if !ok {
+		return 0, "", 0, fmt.Errorf("%w: motor '%s'", ErrInvalid, db.Engine)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backupCfg.Timeout)
+	defer cancel()
+
+	// 🔢 Esquema contado antes do dump (muda pouco); quem conta pelo arquivo conta depois, no que foi gravado
+	objects := 0
+	if e.countDump == nil {
+		n, err := countObjects(ctx, db, e)
+		if err != nil {
+			return 0, "", 0, err
+		}
+		objects = n
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, "", 0, fmt.Errorf("erro ao criar diretório de backups: %w", err)
+	}
+	part := path + ".part"
+	defer os.Remove(part)
+
+	out, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("erro ao criar arquivo de backup: %w", err)
+	}
+	var stderr bytes.Buffer
+	stdout := io.Writer(out)
+	if e.dumpFile != "" {
+		stdout = &stderr
+	}
+	code, err := engine.Default().Exec(ctx, db.ContainerName, e.dumpCmd, stdout, &stderr)
+	if cerr := out.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("erro ao executar dump: %w", err)
+	}
+	if code != 0 {
+		return 0, "", 0, fmt.Errorf("dump saiu com código %d: %s", code, lastLine(stderr.String()))
+	}
+	if e.dumpFile != "" {
+		// 📦 O cliente gravou no diretório de dados (montado do host): traz para a pasta de backups
+		written := filepath.Join(HostPath(db), filepath.Base(e.dumpFile))
+		err := copyFile(written, part, 0o600)
+		os.Remove(written)
+		if err != nil {
+			return 0, "", 0, fmt.Errorf("erro ao copiar dump: %w", err)
+		}
+	}
+
+	size, sum, err := hashFile(part)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	if size == 0 {
+		return 0, "", 0, fmt.Errorf("dump vazio")
+	}
+	if e.countDump != nil {
+		if objects, err = e.countDump(part); err != nil {
+			return 0, "", 0, err
+		}
+	}
+	if err := os.Rename(part, path); err != nil {
+		return 0, "", 0, fmt.Errorf("erro ao salvar backup: %w", err)
+	}
+	return size, sum, objects, nil
+}
+
+// 🧹 Retenção: os N backups prontos mais recentes; uma falha só fica se for mais nova que todos eles
+func pruneBackups(id string) {
+	db, ok := store.DatabaseStore.Get(id)
+	if !ok {
+		return
+	}
+	_, keep, err := BackupPolicy(db.Username)
+	if err != nil {
+		return
+	}
+
+	var removed []models.DatabaseBackup
+	updated, err := store.DatabaseStore.Update(id, func(stored *models.Database) {
+		removed = nil
+		var kept []models.DatabaseBackup
+		ready := 0
+		failedKept := false
+		for _, b := range stored.Backups {
+			switch {
+			case b.Status == models.BackupRunning:
+				kept = append(kept, b)
+			case b.Status == models.BackupReady && ready < keep:
+				ready++
+				kept = append(kept, b)
+			case b.Status == models.BackupFailed && ready == 0 && !failedKept:
+				failedKept = true
+				kept = append(kept, b)
+			default:
+				removed = append(removed, b)
+			}
+		}
+		stored.Backups = kept
+	})
+	if err != nil {
+		log.Printf("⚠️ Erro ao aplicar retenção dos backups de %s: %v", id, err)
+		return
+	}
+	for i := range removed {
+		if err := os.Remove(BackupPath(updated, &removed[i])); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️ Erro ao apagar backup %s: %v", removed[i].ID, err)
+		}
+	}
+}
+
+// 📥 Abre um backup pronto para download, conferindo o checksum gravado no dump
+func OpenBackup(db *models.Database, backupID string) (*os.File, *models.DatabaseBackup, error) {
+	backup, ok := FindBackup(db, backupID)
+	if !ok || backup.Status != models.BackupReady {
+		return nil, nil, fmt.Errorf("%w: backup %s", ErrNotFound, backupID)
+	}
+	f, err := os.Open(BackupPath(db, backup))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao abrir backup: %w", err)
+	}
+	return f, backup, nil
+}
+
+// 🔐 Confere tamanho e SHA-256 do arquivo
+func VerifyBackupFile(db *models.Database, backup *models.DatabaseBackup) error {
+	size, sum, err := hashFile(BackupPath(db, backup))
+	if err != nil {
+		return err
+	}
+	if size != backup.SizeBytes || sum != backup.SHA256 {
+		return fmt.Errorf("%w: backup %s corrompido (checksum não confere)", ErrInvalid, backup.ID)
+	}
+	return nil
+}
+
+// ♻️ Novo banco com o mesmo motor e versão da origem, marcado para receber o backup quando ficar pronto
+func CreateRestored(source *models.Database, backupID, name string, sizeGB int) (*models.Database, error) {
+	backup, ok := FindBackup(source, backupID)
+	if !ok || backup.Status != models.BackupReady {
+		return nil, fmt.Errorf("%w: backup %s", ErrNotFound, backupID)
+	}
+	e, ok := LookupEngine(source.Engine)
+	if !ok {
+		return nil, fmt.Errorf("%w: motor '%s'", ErrInvalid, source.Engine)
+	}
+	if err := VerifyBackupFile(source, backup); err != nil {
+		return nil, err
+	}
+	if sizeGB <= 0 {
+		sizeGB = source.SizeGB
+	}
+
+	db, err := Create(source.Username, name, source.Engine, source.Version, sizeGB)
+	if err != nil {
+		return nil, err
+	}
+	staged := restoreSourcePath(db, e)
+	if err := os.MkdirAll(filepath.Dir(staged), 0o700); err == nil {
+		err = copyFile(BackupPath(source, backup), staged, 0o600)
+	}
+	if err != nil {
+		_ = Remove(db.ID)
+		return nil, fmt.Errorf("erro ao preparar restauração: %w", err)
+	}
+	return store.DatabaseStore.Update(db.ID, func(stored *models.Database) {
+		stored.RestoredFrom = &models.DatabaseRestore{
+			DatabaseID: source.ID,
+			BackupID:   backup.ID,
+			SourceDB:   source.DBName,
+			Objects:    backup.Objects,
+		}
+	})
+}
+
+// ⏳ Restauração ainda não feita?
+func PendingRestore(db *models.Database) bool {
+	return db.RestoredFrom != nil && db.RestoredFrom.VerifiedAt == nil
+}
+
+// 🌱 Motores que restauram na subida (Redis): coloca o arquivo no diretório de dados antes do primeiro start
+func SeedRestore(db *models.Database) error {
+	e, ok := LookupEngine(db.Engine)
+	if !ok || !PendingRestore(db) || e.seedFile == "" {
+		return nil
+	}
+	if err := copyFile(restoreSourcePath(db, e), filepath.Join(HostPath(db), e.seedFile), 0o644); err != nil {
+		return fmt.Errorf("erro ao preparar restauração: %w", err)
+	}
+	return nil
+}
+
+// ♻️ Carrega o backup no banco já pronto e verifica: a contagem de tabelas/coleções precisa bater com a do dump
+func Restore(db *models.Database) (*models.Database, error) {
+	e, ok := LookupEngine(db.Engine)
+	if !ok {
+		return nil, fmt.Errorf("%w: motor '%s'", ErrInvalid, db.Engine)
+	}
+	if !PendingRestore(db) {
+		return db, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backupCfg.Timeout)
+	defer cancel()
+
+	file := ""
+	if e.seedFile == "" {
+		// 📦 O arquivo entra pelo diretório de dados (montado do host) e sai logo depois
+		name := ".vc-restore" + e.dumpExt
+		hostFile := filepath.Join(HostPath(db), name)
+		if err := copyFile(restoreSourcePath(db, e), hostFile, 0o644); err != nil {
+			return nil, fmt.Errorf("erro ao preparar restauração: %w", err)
+		}
+		defer os.Remove(hostFile)
+		file = e.DataDir + "/" + name
+	}
+	var out bytes.Buffer
+	code, err := engine.Default().Exec(ctx, db.ContainerName, e.restoreCmd(file, db.RestoredFrom.SourceDB), &out, &out)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar restauração: %w", err)
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("restauração saiu com código %d: %s", code, lastLine(out.String()))
+	}
+
+	restored, err := countObjects(ctx, db, e)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar restauração: %w", err)
+	}
+	expected := db.RestoredFrom.Objects
+	if (e.countExact && restored != expected) || (!e.countExact && restored < expected) {
+		return nil, fmt.Errorf("verificação da restauração falhou: backup com %d objetos, banco restaurado com %d", expected, restored)
+	}
+
+	now := time.Now()
+	updated, err := store.DatabaseStore.Update(db.ID, func(stored *models.Database) {
+		if stored.RestoredFrom != nil {
+			stored.RestoredFrom.Restored = restored
+			stored.RestoredFrom.VerifiedAt = &now
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(restoreSourcePath(db, e))
+	log.Printf("♻️ Banco %s restaurado do backup %s e verificado (%d objetos)", db.ID, db.RestoredFrom.BackupID, restored)
+	return updated, nil
+}
+
+// ⏱️ Agenda os backups diários dos planos com DailyBackups
+func StartBackups(cfg BackupConfig) {
+	backupCfg = cfg
+	recoverBackups()
+	go func() {
+		for {
+			for _, db := range store.DatabaseStore.List() {
+				if !backupDue(db) {
+					continue
+				}
+				backup, err := BeginBackup(db.ID, models.BackupScheduled)
+				if err != nil {
+					continue
+				}
+				_ = RunBackup(db.ID, backup.ID)
+			}
+			time.Sleep(cfg.CheckInterval)
+		}
+	}()
+	log.Printf("💾 Backups de bancos a cada %s (retenção de %d nos planos com backup diário)", cfg.Interval, cfg.Retention)
+}
+
+// 📅 Banco ligado, plano com DailyBackups e backup pronto mais recente (agendado ou manual) vencido;
+// um agendado que falhou espera RetryDelay antes da nova tentativa
+func backupDue(db *models.Database) bool {
+	if db.Status != models.DatabaseRunning {
+		return false
+	}
+	if daily, _, err := BackupPolicy(db.Username); err != nil || !daily {
+		return false
+	}
+	for _, b := range db.Backups {
+		switch b.Status {
+		case models.BackupRunning:
+			return false
+		case models.BackupFailed:
+			if b.Trigger == models.BackupScheduled && time.Since(b.StartedAt) < backupCfg.RetryDelay {
+				return false
+			}
+		case models.BackupReady:
+			return time.Since(b.StartedAt) >= backupCfg.Interval
+		}
+	}
+	return true
+}
+
+// 🧯 Backups que estavam rodando quando o backend parou viram falha
+func recoverBackups() {
+	for _, db := range store.DatabaseStore.List() {
+		for _, b := range db.Backups {
+			if b.Status != models.BackupRunning {
+				continue
+			}
+			os.Remove(BackupPath(db, &b) + ".part")
+			if _, err := store.DatabaseStore.Update(db.ID, func(stored *models.Database) {
+				stored.Backups = withBackup(stored.Backups, b.ID, func(stale *models.DatabaseBackup) {
+					stale.Status = models.BackupFailed
+					stale.Error = "interrompido pela reinicialização do backend"
+				})
+			}); err != nil {
+				log.Printf("⚠️ Erro ao marcar backup %s como interrompido: %v", b.ID, err)
+			}
+		}
+	}
+}
+
+// 🔢 Tabelas, coleções ou chaves do banco (última linha numérica da saída)
+func countObjects(ctx context.Context, db *models.Database, e *Engine) (int, error) {
+	var stdout, stderr bytes.Buffer
+	code, err := engine.Default().Exec(ctx, db.ContainerName, e.countCmd, &stdout, &stderr)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao contar objetos do banco: %w", err)
+	}
+	if code != 0 {
+		return 0, fmt.Errorf("contagem saiu com código %d: %s", code, lastLine(stderr.String()))
+	}
+	text := lastLine(stdout.String())
+	if text == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("contagem inesperada: %q", text)
+	}
+	return n, nil
+}
+
+// ✏️ Aplica fn no backup com o ID
+func withBackup(backups []models.DatabaseBackup, id string, fn func(*models.DatabaseBackup)) []models.DatabaseBackup {
+	for i := range backups {
+		if backups[i].ID == id {
+			fn(&backups[i])
+		}
+	}
+	return backups
+}
+
+// 🔐 Tamanho e SHA-256 do arquivo
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("erro ao abrir backup: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("erro ao ler backup: %w", err)
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// 📋 Copia src para dst com o modo informado
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(dst, mode)
+}
+
+// 🔤 Última linha não vazia (mensagens de erro e contagens)
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,454 @@
+//backend/databases/databases.go
+
+package databases
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/vault"
+)
+
+// 🏷️ Labels dos containers de banco (fora do label "username", que é das aplicações)
+const (
+	DatabaseLabel = "virtuscloud.database"
+	UserLabel     = "virtuscloud.user"
+)
+
+const gb = int64(1024 * 1024 * 1024)
+
+// ❗ Erros de validação (o handler escolhe o status HTTP com errors.Is)
+var (
+	ErrInvalid    = errors.New("banco inválido")
+	ErrExists     = errors.New("banco já existe")
+	ErrNotAllowed = errors.New("banco não permitido pelo plano")
+	ErrNotFound   = errors.New("banco não encontrado")
+	ErrBusy       = errors.New("banco ocupado")
+)
+
+// 🔤 Também vira nome de DNS na rede do usuário
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,30}[a-z0-9]$`)
+
+// 🔒 Serializa criações para a contagem respeitar o plano
+var createMu sync.Mutex
+
+// 📁 storage/users/<usuário>/databases/<nome> (criado junto com as pastas base do usuário)
+func HostPath(db *models.Database) string {
+	return filepath.Join("storage", "users", db.Username, "databases", db.Name)
+}
+
+// ➕ Registra o banco (estado "creating") com credenciais geradas; o container sobe depois
+func Create(username, name, engineName, version string, sizeGB int) (*models.Database, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: nome '%s' (use de 2 a 32 letras minúsculas, números e -, começando por letra)", ErrInvalid, name)
+	}
+	e, ok := LookupEngine(strings.ToLower(strings.TrimSpace(engineName)))
+	if !ok {
+		return nil, fmt.Errorf("%w: motor '%s' (use postgres, mysql, mongodb ou redis)", ErrInvalid, engineName)
+	}
+	version, ok = e.version(strings.TrimSpace(version))
+	if !ok {
+		return nil, fmt.Errorf("%w: versão não suportada de %s (disponíveis: %s)", ErrInvalid, e.Name, strings.Join(e.Versions, ", "))
+	}
+	if sizeGB <= 0 {
+		return nil, fmt.Errorf("%w: tamanho deve ser de pelo menos 1 GB", ErrInvalid)
+	}
+
+	createMu.Lock()
+	defer createMu.Unlock()
+
+	existing := store.DatabaseStore.ListByUser(username)
+	for _, db := range existing {
+		if db.Name == name {
+			return nil, fmt.Errorf("%w: %s", ErrExists, name)
+		}
+	}
+	if err := limits.CanCreateDatabase(username, len(existing), sizeGB); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAllowed, err)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	password, err := newPassword()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := vault.Seal([]byte(password))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao cifrar senha do banco: %w", err)
+	}
+
+	db := &models.Database{
+		ID:             id,
+		Username:       username,
+		Name:           name,
+		Engine:         e.Name,
+		Version:        version,
+		Image:          e.Image(version),
+		ContainerName:  "vc" + id,
+		Port:           e.Port,
+		SealedPassword: sealed,
+		SizeGB:         sizeGB,
+		Status:         models.DatabaseCreating,
+		CreatedAt:      time.Now(),
+	}
+	if e.UsesDatabase {
+		db.DBName = strings.ReplaceAll(name, "-", "_")
+		db.User = db.DBName
+	}
+
+	dir := HostPath(db)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%w: já existem dados em %s", ErrExists, dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do banco: %w", err)
+	}
+	// 🔓 Cada imagem roda com um UID próprio (postgres 70/999, mysql 999, redis 999...)
+	if err := os.Chmod(dir, 0o777); err != nil {
+		return nil, fmt.Errorf("erro ao ajustar permissões do banco: %w", err)
+	}
+	if err := store.DatabaseStore.Create(db); err != nil {
+		return nil, err
+	}
+	log.Printf("🗄️ Banco %s (%s %s) registrado para %s", db.Name, db.Engine, db.Version, username)
+	return db, nil
+}
+
+// 📏 Altera o tamanho contratado (não pode ficar abaixo do que os dados já ocupam)
+func Resize(id string, sizeGB int) (*models.Database, error) {
+	current, ok := store.DatabaseStore.Get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if sizeGB <= 0 {
+		return nil, fmt.Errorf("%w: tamanho deve ser de pelo menos 1 GB", ErrInvalid)
+	}
+	if int64(sizeGB)*gb < current.UsedBytes {
+		return nil, fmt.Errorf("%w: o banco já usa %s, mais que %d GB", ErrInvalid, FormatBytes(current.UsedBytes), sizeGB)
+	}
+	if err := limits.CanSizeDatabase(current.Username, sizeGB); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAllowed, err)
+	}
+	return store.DatabaseStore.Update(id, func(db *models.Database) {
+		db.SizeGB = sizeGB
+	})
+}
+
+// 🗑️ Apaga os dados, os backups e o registro (o container já deve ter sido removido)
+func Remove(id string) error {
+	db, ok := store.DatabaseStore.Get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	if err := os.RemoveAll(HostPath(db)); err != nil {
+		return fmt.Errorf("erro ao apagar dados do banco: %w", err)
+	}
+	if err := os.RemoveAll(BackupDir(db)); err != nil {
+		return fmt.Errorf("erro ao apagar backups do banco: %w", err)
+	}
+	return store.DatabaseStore.Delete(id)
+}
+
+// 🔓 Senha em claro
+func Password(db *models.Database) (string, error) {
+	plain, err := vault.Open(db.SealedPassword)
+	if err != nil {
+		return "", fmt.Errorf("erro ao decifrar senha do banco %s: %w", db.Name, err)
+	}
+	return string(plain), nil
+}
+
+// 👁️ Banco como a API exibe (sem a senha)
+type View struct {
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Engine       string                  `json:"engine"`
+	Version      string                  `json:"version"`
+	Status       string                  `json:"status"`
+	LastError    string                  `json:"lastError,omitempty"`
+	Host         string                  `json:"host"`
+	Port         int                     `json:"port"`
+	Database     string                  `json:"database,omitempty"`
+	User         string                  `json:"user,omitempty"`
+	SizeGB       int                     `json:"sizeGB"`
+	UsedBytes    int64                   `json:"usedBytes"`
+	Usage        string                  `json:"usage"`
+	MeasuredAt   *time.Time              `json:"measuredAt,omitempty"`
+	Links        []models.DatabaseLink   `json:"links"`
+	Backups      []models.DatabaseBackup `json:"backups"`
+	RestoredFrom *models.DatabaseRestore `json:"restoredFrom,omitempty"`
+	CreatedAt    time.Time               `json:"createdAt"`
+}
+
+// 👁️ Visão pública do banco
+func ViewOf(db *models.Database) View {
+	links := db.Links
+	if links == nil {
+		links = []models.DatabaseLink{}
+	}
+	backups := db.Backups
+	if backups == nil {
+		backups = []models.DatabaseBackup{}
+	}
+	return View{
+		ID:           db.ID,
+		Name:         db.Name,
+		Engine:       db.Engine,
+		Version:      db.Version,
+		Status:       db.Status,
+		LastError:    db.LastError,
+		Host:         db.Name,
+		Port:         db.Port,
+		Database:     db.DBName,
+		User:         db.User,
+		SizeGB:       db.SizeGB,
+		UsedBytes:    db.UsedBytes,
+		Usage:        FormatBytes(db.UsedBytes),
+		MeasuredAt:   db.MeasuredAt,
+		Links:        links,
+		Backups:      backups,
+		RestoredFrom: db.RestoredFrom,
+		CreatedAt:    db.CreatedAt,
+	}
+}
+
+// 🔌 Dados de conexão, válidos dentro da rede do usuário (host = nome do banco)
+type Connection struct {
+	Engine   string `json:"engine"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Database string `json:"database,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password"`
+	URL      string `json:"url"`
+}
+
+// 🔌 Conexão do banco com a senha decifrada
+func ConnectionOf(db *models.Database) (*Connection, error) {
+	e, ok := LookupEngine(db.Engine)
+	if !ok {
+		return nil, fmt.Errorf("%w: motor '%s'", ErrInvalid, db.Engine)
+	}
+	password, err := Password(db)
+	if err != nil {
+		return nil, err
+	}
+	return &Connection{
+		Engine:   db.Engine,
+		Host:     db.Name,
+		Port:     db.Port,
+		Database: db.DBName,
+		User:     db.User,
+		Password: password,
+		URL:      e.connectionURL(db, password),
+	}, nil
+}
+
+// 💉 Variável de conexão para o ambiente de uma aplicação
+type EnvVar struct {
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// 💉 <PREFIXO>_URL, _HOST, _PORT, _USER, _PASSWORD e _NAME (URL e senha como segredos)
+func Environment(db *models.Database, prefix string) ([]EnvVar, error) {
+	conn, err := ConnectionOf(db)
+	if err != nil {
+		return nil, err
+	}
+	vars := []EnvVar{
+		{Key: prefix + "_URL", Value: conn.URL, Secret: true},
+		{Key: prefix + "_HOST", Value: conn.Host},
+		{Key: prefix + "_PORT", Value: strconv.Itoa(conn.Port)},
+		{Key: prefix + "_PASSWORD", Value: conn.Password, Secret: true},
+	}
+	if conn.User != "" {
+		vars = append(vars, EnvVar{Key: prefix + "_USER", Value: conn.User})
+	}
+	if conn.Database != "" {
+		vars = append(vars, EnvVar{Key: prefix + "_NAME", Value: conn.Database})
+	}
+	return vars, nil
+}
+
+// 🧩 Especificação do container: dados montados do host, credenciais no ambiente e RAM do plano
+func Spec(db *models.Database, network string) (engine.Spec, error) {
+	e, ok := LookupEngine(db.Engine)
+	if !ok {
+		return engine.Spec{}, fmt.Errorf("%w: motor '%s'", ErrInvalid, db.Engine)
+	}
+	password, err := Password(db)
+	if err != nil {
+		return engine.Spec{}, err
+	}
+	_, _, memoryMB, err := limits.DatabaseQuota(db.Username)
+	if err != nil {
+		return engine.Spec{}, err
+	}
+	resources, err := limits.UserContainerResources(db.Username)
+	if err != nil {
+		return engine.Spec{}, err
+	}
+	resources.MemoryMB = memoryMB
+	if resources.CPUs > 1 {
+		resources.CPUs = 1
+	}
+	host, err := filepath.Abs(HostPath(db))
+	if err != nil {
+		return engine.Spec{}, fmt.Errorf("erro ao resolver diretório do banco: %w", err)
+	}
+
+	spec := engine.Spec{
+		Name:           db.ContainerName,
+		Image:          db.Image,
+		Env:            e.containerEnv(db, password),
+		Labels:         map[string]string{DatabaseLabel: db.ID, UserLabel: db.Username},
+		Binds:          []string{host + ":" + e.DataDir},
+		Resources:      resources,
+		RestartPolicy:  "unless-stopped", // 🛡️ bancos não passam pelo reconciliador das aplicações
+		NetworkMode:    network,
+		NetworkAliases: []string{db.Name},
+	}
+	if e.containerCmd != nil {
+		spec.Cmd = e.containerCmd(memoryMB)
+	}
+	return spec, nil
+}
+
+// 🩺 O banco aceita conexões? (roda o cliente do próprio motor dentro do container)
+func Ready(ctx context.Context, db *models.Database) error {
+	e, ok := LookupEngine(db.Engine)
+	if !ok {
+		return fmt.Errorf("%w: motor '%s'", ErrInvalid, db.Engine)
+	}
+	var out bytes.Buffer
+	code, err := engine.Default().Exec(ctx, db.ContainerName, e.readyCmd, &out, &out)
+	if err != nil {
+		return err
+	}
+	if code != 0 || (e.readyOutput != "" && !strings.Contains(out.String(), e.readyOutput)) {
+		return fmt.Errorf("banco ainda não responde (código %d): %s", code, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+// 📏 Bytes ocupados pelos dados (links simbólicos não são seguidos)
+func Measure(db *models.Database) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(HostPath(db), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+				return nil // arquivos do motor com dono e modo próprios: conta o que der para ler
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// 🔄 Mede todos os bancos; devolve os que passaram do tamanho contratado agora
+func Refresh() []string {
+	var over []string
+	for _, db := range store.DatabaseStore.List() {
+		used, err := Measure(db)
+		if err != nil {
+			log.Printf("⚠️ Erro ao medir banco %s: %v", db.ID, err)
+			continue
+		}
+		now := time.Now()
+		updated, err := store.DatabaseStore.Update(db.ID, func(stored *models.Database) {
+			stored.UsedBytes = used
+			stored.MeasuredAt = &now
+		})
+		if err != nil {
+			log.Printf("⚠️ Erro ao salvar uso do banco %s: %v", db.ID, err)
+			continue
+		}
+		if updated.Status != models.DatabaseOverQuota && used > int64(updated.SizeGB)*gb {
+			log.Printf("🚫 Banco %s acima do tamanho contratado (%s de %d GB)", db.ID, FormatBytes(used), updated.SizeGB)
+			over = append(over, db.ID)
+		}
+	}
+	return over
+}
+
+// ⏱️ Mede os bancos periodicamente; onOverQuota recebe os que passaram do tamanho
+func StartMonitor(interval time.Duration, onOverQuota func(id string)) {
+	go func() {
+		for {
+			for _, id := range Refresh() {
+				onOverQuota(id)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// ⏱️ Intervalo de medição (DATABASES_SCAN_SECONDS, padrão 5 minutos)
+func MonitorIntervalFromEnv() time.Duration {
+	if seconds, err := strconv.Atoi(os.Getenv("DATABASES_SCAN_SECONDS")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 5 * time.Minute
+}
+
+// 🔤 Bytes em unidade legível (ex.: 1.5 GB)
+func FormatBytes(n int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(n)
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// 🆔 db_<10 hex>
+func newID() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar ID do banco: %w", err)
+	}
+	return "db_" + hex.EncodeToString(b), nil
+}
+
+// 🔑 32 caracteres hex: entra em URLs sem escape
+func newPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar senha do banco: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
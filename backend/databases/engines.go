@@ -0,0 +1,209 @@
+//backend/databases/engines.go
+
+package databases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"virtuscloud/backend/models"
+)
+
+// 🧰 Como rodar cada motor: imagem oficial, porta, onde ficam os dados e como conferir que está pronto
+type Engine struct {
+	Name          string   `json:"name"`
+	Repo          string   `json:"-"`
+	TagSuffix     string   `json:"-"`        // ex.: "-alpine"
+	Versions      []string `json:"versions"` // a primeira é a padrão
+	Port          int      `json:"port"`
+	DataDir       string   `json:"-"`
+	DefaultPrefix string   `json:"defaultPrefix"` // prefixo das variáveis injetadas nas aplicações
+	UsesDatabase  bool     `json:"-"`             // tem banco e usuário nomeados (Redis não)
+	readyOutput   string   // texto esperado na saída do comando de prontidão (vazio = basta sair com 0)
+	containerEnv  func(db *models.Database, password string) []string
+	containerCmd  func(memoryMB int) []string
+	readyCmd      []string
+	connectionURL func(db *models.Database, password string) string
+
+	// 💾 Backup lógico e restauração (arquivos dentro do container ficam em DataDir, montado do host)
+	dumpExt    string                               // extensão do arquivo de backup
+	dumpCmd    []string                             // escreve o dump na saída padrão, ou em dumpFile
+	dumpFile   string                               // caminho no container quando o cliente não escreve na saída (Redis)
+	seedFile   string                               // restaura colocando o arquivo em DataDir antes do primeiro start (Redis)
+	restoreCmd func(file, sourceDB string) []string // carrega o arquivo (caminho no container) no banco já pronto
+	countCmd   []string                             // conta tabelas, coleções ou chaves (verificação da restauração)
+	countExact bool                                 // a contagem precisa bater (esquema); sem ela, o banco restaurado precisa ter ao menos a do backup
+	countDump  func(path string) (int, error)       // conta a partir do próprio arquivo de dump (Redis: o dataset muda até o snapshot)
+}
+
+// 🗂️ Motores suportados
+var engines = map[string]*Engine{
+	models.DatabasePostgres: {
+		Name:          models.DatabasePostgres,
+		Repo:          "postgres",
+		TagSuffix:     "-alpine",
+		Versions:      []string{"16", "17", "15", "14"},
+		Port:          5432,
+		DataDir:       "/var/lib/postgresql/data",
+		DefaultPrefix: "DATABASE",
+		UsesDatabase:  true,
+		containerEnv: func(db *models.Database, password string) []string {
+			return []string{"POSTGRES_USER=" + db.User, "POSTGRES_PASSWORD=" + password, "POSTGRES_DB=" + db.DBName}
+		},
+		// -h 127.0.0.1: o servidor temporário da inicialização só escuta no socket local
+		readyCmd: []string{"sh", "-c", `pg_isready -q -h 127.0.0.1 -U "$POSTGRES_USER" -d "$POSTGRES_DB"`},
+		connectionURL: func(db *models.Database, password string) string {
+			return serverURL("postgres", db, password) + "/" + db.DBName
+		},
+		dumpExt: ".dump",
+		dumpCmd: []string{"sh", "-c", `PGPASSWORD="$POSTGRES_PASSWORD" pg_dump -Fc -h 127.0.0.1 -U "$POSTGRES_USER" "$POSTGRES_DB"`},
+		restoreCmd: func(file, _ string) []string {
+			return []string{"sh", "-c", `PGPASSWORD="$POSTGRES_PASSWORD" pg_restore --no-owner --no-acl --exit-on-error -h 127.0.0.1 -U "$POSTGRES_USER" -d "$POSTGRES_DB" "$1"`, "sh", file}
+		},
+		countCmd:   []string{"sh", "-c", `PGPASSWORD="$POSTGRES_PASSWORD" psql -tA -h 127.0.0.1 -U "$POSTGRES_USER" -d "$POSTGRES_DB" -c "select count(*) from information_schema.tables where table_schema not in ('pg_catalog', 'information_schema')"`},
+		countExact: true,
+	},
+	models.DatabaseMySQL: {
+		Name:          models.DatabaseMySQL,
+		Repo:          "mysql",
+		Versions:      []string{"8.4", "8.0"},
+		Port:          3306,
+		DataDir:       "/var/lib/mysql",
+		DefaultPrefix: "DATABASE",
+		UsesDatabase:  true,
+		containerEnv: func(db *models.Database, password string) []string {
+			// 🔒 root recebe uma senha aleatória que ninguém guarda: o usuário trabalha com a conta dele
+			return []string{"MYSQL_RANDOM_ROOT_PASSWORD=yes", "MYSQL_USER=" + db.User, "MYSQL_PASSWORD=" + password, "MYSQL_DATABASE=" + db.DBName}
+		},
+		readyCmd: []string{"sh", "-c", `MYSQL_PWD="$MYSQL_PASSWORD" mysqladmin ping --silent -h 127.0.0.1 -u "$MYSQL_USER"`},
+		connectionURL: func(db *models.Database, password string) string {
+			return serverURL("mysql", db, password) + "/" + db.DBName
+		},
+		dumpExt: ".sql",
+		// --no-tablespaces: o usuário do banco não tem o privilégio PROCESS
+		dumpCmd: []string{"sh", "-c", `MYSQL_PWD="$MYSQL_PASSWORD" mysqldump -h 127.0.0.1 -u "$MYSQL_USER" --single-transaction --routines --triggers --no-tablespaces "$MYSQL_DATABASE"`},
+		restoreCmd: func(file, _ string) []string {
+			return []string{"sh", "-c", `MYSQL_PWD="$MYSQL_PASSWORD" mysql -h 127.0.0.1 -u "$MYSQL_USER" "$MYSQL_DATABASE" < "$1"`, "sh", file}
+		},
+		countCmd:   []string{"sh", "-c", `MYSQL_PWD="$MYSQL_PASSWORD" mysql -N -h 127.0.0.1 -u "$MYSQL_USER" -e "select count(*) from information_schema.tables where table_schema = '$MYSQL_DATABASE'"`},
+		countExact: true,
+	},
+	models.DatabaseMongo: {
+		Name:          models.DatabaseMongo,
+		Repo:          "mongo",
+		Versions:      []string{"7.0", "8.0", "6.0"},
+		Port:          27017,
+		DataDir:       "/data/db",
+		DefaultPrefix: "MONGODB",
+		UsesDatabase:  true,
+		containerEnv: func(db *models.Database, password string) []string {
+			return []string{"MONGO_INITDB_ROOT_USERNAME=" + db.User, "MONGO_INITDB_ROOT_PASSWORD=" + password, "MONGO_INITDB_DATABASE=" + db.DBName}
+		},
+		// 🧠 O cache do WiredTiger calcula o padrão pela RAM do host; aqui fica em ~40% do limite do container
+		containerCmd: func(memoryMB int) []string {
+			cacheGB := float64(memoryMB) * 0.4 / 1024
+			if cacheGB < 0.25 {
+				cacheGB = 0.25
+			}
+			return []string{"mongod", "--wiredTigerCacheSizeGB", strconv.FormatFloat(cacheGB, 'f', 2, 64)}
+		},
+		readyCmd:    []string{"sh", "-c", `mongosh --quiet --host 127.0.0.1 -u "$MONGO_INITDB_ROOT_USERNAME" -p "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin --eval 'db.runCommand({ping: 1}).ok'`},
+		readyOutput: "1",
+		connectionURL: func(db *models.Database, password string) string {
+			return serverURL("mongodb", db, password) + "/" + db.DBName + "?authSource=admin"
+		},
+		dumpExt: ".archive.gz",
+		dumpCmd: []string{"sh", "-c", `mongodump --quiet --host 127.0.0.1 -u "$MONGO_INITDB_ROOT_USERNAME" -p "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin --db "$MONGO_INITDB_DATABASE" --archive --gzip`},
+		// 🔀 O banco restaurado tem outro nome: os namespaces da origem são renomeados
+		restoreCmd: func(file, sourceDB string) []string {
+			return []string{"sh", "-c", `mongorestore --quiet --host 127.0.0.1 -u "$MONGO_INITDB_ROOT_USERNAME" -p "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin --archive="$1" --gzip --nsFrom "$2.*" --nsTo "$MONGO_INITDB_DATABASE.*"`, "sh", file, sourceDB}
+		},
+		countCmd:   []string{"sh", "-c", `mongosh --quiet --host 127.0.0.1 -u "$MONGO_INITDB_ROOT_USERNAME" -p "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin "$MONGO_INITDB_DATABASE" --eval 'db.getCollectionNames().length'`},
+		countExact: true,
+	},
+	models.DatabaseRedis: {
+		Name:          models.DatabaseRedis,
+		Repo:          "redis",
+		TagSuffix:     "-alpine",
+		Versions:      []string{"7.4", "7.2", "6.2"},
+		Port:          6379,
+		DataDir:       "/data",
+		DefaultPrefix: "REDIS",
+		containerEnv: func(db *models.Database, password string) []string {
+			return []string{"REDIS_PASSWORD=" + password}
+		},
+		// 💾 AOF + RDB em /data; maxmemory abaixo do limite do container para o kernel não matar o processo.
+		// A senha vem do ambiente, como nos outros motores: fica fora do ps e do log do processo, mas o Env aparece
+		// no docker inspect (quem tem a API do Docker já tem os dados). Com um dump.rdb e nenhum AOF
+		// (banco restaurado) o servidor sobe sem AOF para carregar o RDB; a restauração liga o AOF depois.
+		containerCmd: func(memoryMB int) []string {
+			maxMemory := memoryMB * 3 / 4
+			if maxMemory < 32 {
+				maxMemory = 32
+			}
+			return []string{"sh", "-c", fmt.Sprintf(`aof=yes; if [ -f /data/dump.rdb ] && [ ! -e /data/appendonlydir ] && [ ! -e /data/appendonly.aof ]; then aof=no; fi; exec redis-server --requirepass "$REDIS_PASSWORD" --appendonly "$aof" --dir /data --maxmemory %dmb --maxmemory-policy noeviction`, maxMemory)}
+		},
+		readyCmd:    []string{"sh", "-c", `redis-cli -h 127.0.0.1 -a "$REDIS_PASSWORD" --no-auth-warning ping`},
+		readyOutput: "PONG",
+		connectionURL: func(db *models.Database, password string) string {
+			return serverURL("redis", db, password) + "/0"
+		},
+		dumpExt:  ".rdb",
+		dumpCmd:  []string{"sh", "-c", `redis-cli -h 127.0.0.1 -a "$REDIS_PASSWORD" --no-auth-warning --rdb /data/.vc-backup.rdb`},
+		dumpFile: "/data/.vc-backup.rdb",
+		seedFile: "dump.rdb",
+		// 💾 O RDB já foi carregado na subida: liga o AOF (reescrito a partir dos dados em memória)
+		restoreCmd: func(_, _ string) []string {
+			return []string{"sh", "-c", `redis-cli -h 127.0.0.1 -a "$REDIS_PASSWORD" --no-auth-warning config set appendonly yes`}
+		},
+		countCmd:  []string{"sh", "-c", `redis-cli -h 127.0.0.1 -a "$REDIS_PASSWORD" --no-auth-warning dbsize`},
+		countDump: rdbPersistentKeys,
+	},
+}
+
+// 🔍 Motor pelo nome (aceita "mongo" e "pg" como apelidos)
+func LookupEngine(name string) (*Engine, bool) {
+	switch name {
+	case "mongo":
+		name = models.DatabaseMongo
+	case "pg", "postgresql":
+		name = models.DatabasePostgres
+	}
+	e, ok := engines[name]
+	return e, ok
+}
+
+// 📋 Motores suportados, em ordem fixa
+func Engines() []*Engine {
+	return []*Engine{
+		engines[models.DatabasePostgres],
+		engines[models.DatabaseMySQL],
+		engines[models.DatabaseMongo],
+		engines[models.DatabaseRedis],
+	}
+}
+
+// 🖼️ Imagem oficial da versão (ex.: postgres:16-alpine)
+func (e *Engine) Image(version string) string {
+	return e.Repo + ":" + version + e.TagSuffix
+}
+
+// ✅ Versão suportada (vazia = padrão)
+func (e *Engine) version(version string) (string, bool) {
+	if version == "" {
+		return e.Versions[0], true
+	}
+	for _, v := range e.Versions {
+		if v == version {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// 🔗 <esquema>://usuário:senha@host:porta (Redis usa só a senha)
+func serverURL(scheme string, db *models.Database, password string) string {
+	u := url.URL{Scheme: scheme, Host: db.Name + ":" + strconv.Itoa(db.Port), User: url.UserPassword(db.User, password)}
+	return u.String()
+}
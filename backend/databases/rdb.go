@@ -0,0 +1,165 @@
+//backend/databases/rdb.go
+
+package databases
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// 🧾 Opcodes do formato RDB que aparecem antes das chaves do banco 0
+const (
+	rdbOpSlotInfo  = 0xF4
+	rdbOpFunction2 = 0xF5
+	rdbOpAux       = 0xFA
+	rdbOpResizeDB  = 0xFB
+	rdbOpSelectDB  = 0xFE
+	rdbOpEOF       = 0xFF
+)
+
+// 🔢 Chaves sem expiração do banco 0 no RDB, lidas do cabeçalho RESIZEDB que o Redis grava antes das chaves.
+// É o mínimo que a restauração precisa encontrar: chaves com TTL podem vencer antes do banco novo subir.
+func rdbPersistentKeys(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao abrir dump: %w", err)
+	}
+	defer f.Close()
+	n, err := readRDBPersistentKeys(bufio.NewReader(f))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler contagem do dump: %w", err)
+	}
+	return n, nil
+}
+
+func readRDBPersistentKeys(r *bufio.Reader) (int, error) {
+	header := make([]byte, 9)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+	if string(header[:5]) != "REDIS" {
+		return 0, errors.New("arquivo não é um RDB")
+	}
+
+	for {
+		op, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case rdbOpAux:
+			if err := skipRDBStrings(r, 2); err != nil {
+				return 0, err
+			}
+		case rdbOpFunction2:
+			if err := skipRDBStrings(r, 1); err != nil {
+				return 0, err
+			}
+		case rdbOpSlotInfo:
+			for i := 0; i < 3; i++ {
+				if _, _, err := readRDBLength(r); err != nil {
+					return 0, err
+				}
+			}
+		case rdbOpSelectDB:
+			db, _, err := readRDBLength(r)
+			if err != nil {
+				return 0, err
+			}
+			// 🗂️ Os bancos vêm em ordem e os vazios não aparecem: o primeiro não sendo o 0, o 0 está vazio
+			if db != 0 {
+				return 0, nil
+			}
+			next, err := r.ReadByte()
+			if err != nil {
+				return 0, err
+			}
+			if next != rdbOpResizeDB {
+				return 0, errors.New("RDB sem RESIZEDB no banco 0")
+			}
+			keys, _, err := readRDBLength(r)
+			if err != nil {
+				return 0, err
+			}
+			expires, _, err := readRDBLength(r)
+			if err != nil {
+				return 0, err
+			}
+			if expires > keys {
+				return 0, errors.New("RESIZEDB inconsistente")
+			}
+			return int(keys - expires), nil
+		case rdbOpEOF:
+			return 0, nil
+		default:
+			return 0, fmt.Errorf("opcode RDB 0x%X não suportado antes das chaves", op)
+		}
+	}
+}
+
+// 📏 Comprimento no formato RDB; encoded indica um formato especial de string (inteiro ou LZF)
+func readRDBLength(r *bufio.Reader) (n uint64, encoded bool, err error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch b >> 6 {
+	case 0:
+		return uint64(b & 0x3F), false, nil
+	case 1:
+		next, err := r.ReadByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(b&0x3F)<<8 | uint64(next), false, nil
+	case 2:
+		switch b {
+		case 0x80:
+			var v uint32
+			err := binary.Read(r, binary.BigEndian, &v)
+			return uint64(v), false, err
+		case 0x81:
+			var v uint64
+			err := binary.Read(r, binary.BigEndian, &v)
+			return v, false, err
+		}
+		return 0, false, fmt.Errorf("comprimento RDB 0x%X inválido", b)
+	default:
+		return uint64(b & 0x3F), true, nil
+	}
+}
+
+// ⏭️ Pula strings do RDB (texto, inteiros codificados ou LZF)
+func skipRDBStrings(r *bufio.Reader, count int) error {
+	for i := 0; i < count; i++ {
+		n, encoded, err := readRDBLength(r)
+		if err != nil {
+			return err
+		}
+		if encoded {
+			switch n {
+			case 0, 1, 2: // inteiro de 8, 16 ou 32 bits
+				n = 1 << n
+			case 3: // LZF: tamanho comprimido, tamanho original, dados
+				compressed, _, err := readRDBLength(r)
+				if err != nil {
+					return err
+				}
+				if _, _, err := readRDBLength(r); err != nil {
+					return err
+				}
+				n = compressed
+			default:
+				return fmt.Errorf("string RDB com codificação %d desconhecida", n)
+			}
+		}
+		if _, err := r.Discard(int(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+//backend/databases/rdb_test.go
+
+package databases
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 🧾 Cabeçalho como o que o Redis 7 grava: versão e campos auxiliares (texto, inteiros e LZF)
+func rdbHeader() []byte {
+	var b bytes.Buffer
+	b.WriteString("REDIS0011")
+	aux := func(key string, value []byte) {
+		b.WriteByte(rdbOpAux)
+		b.WriteByte(byte(len(key)))
+		b.WriteString(key)
+		b.Write(value)
+	}
+	aux("redis-ver", append([]byte{5}, "7.2.4"...))
+	aux("redis-bits", []byte{0xC0, 64})
+	aux("ctime", []byte{0xC2, 0x10, 0x20, 0x30, 0x40})
+	aux("used-mem", []byte{0xC1, 0x00, 0x10})
+	aux("lzf", []byte{0xC3, 3, 10, 'a', 'b', 'c'})
+	aux("long", append([]byte{0x40 | 1, 0x2C}, strings.Repeat("x", 300)...))
+	return b.Bytes()
+}
+
+func countRDB(t *testing.T, data []byte) (int, error) {
+	t.Helper()
+	return readRDBPersistentKeys(bufio.NewReader(bytes.NewReader(data)))
+}
+
+func TestRDBPersistentKeysFromResizeDB(t *testing.T) {
+	// 🔢 Banco 0 com 1200 chaves (comprimento de 14 bits), 200 delas com TTL; o resto do arquivo não é lido
+	data := append(rdbHeader(), rdbOpSelectDB, 0, rdbOpResizeDB, 0x40|0x04, 0xB0, 0x80, 0, 0, 0, 200, 0x00, 0x01, 'k')
+	n, err := countRDB(t, data)
+	if err != nil || n != 1000 {
+		t.Fatalf("contagem = %d, %v; esperado 1000", n, err)
+	}
+}
+
+func TestRDBPersistentKeysEmptyDatabase(t *testing.T) {
+	for name, tail := range map[string][]byte{
+		"sem bancos":     {rdbOpEOF},
+		"só o banco 3":   {rdbOpSelectDB, 3, rdbOpResizeDB, 5, 0},
+		"com função lua": {rdbOpFunction2, 2, 'f', 'n', rdbOpEOF},
+	} {
+		n, err := countRDB(t, append(rdbHeader(), tail...))
+		if err != nil || n != 0 {
+			t.Fatalf("%s: contagem = %d, %v; esperado 0", name, n, err)
+		}
+	}
+}
+
+func TestRDBPersistentKeysRejectsInvalidFiles(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"não é rdb":     []byte("PGDMP0000 qualquer coisa"),
+		"truncado":      rdbHeader()[:20],
+		"sem resizedb":  append(rdbHeader(), rdbOpSelectDB, 0, 0x00, 0x01, 'k'),
+		"módulo":        append(rdbHeader(), 0xF7, 1, 2, 3),
+		"expira demais": append(rdbHeader(), rdbOpSelectDB, 0, rdbOpResizeDB, 1, 2),
+	} {
+		if _, err := countRDB(t, data); err == nil {
+			t.Fatalf("%s: arquivo aceito", name)
+		}
+	}
+}
+
+func TestRDBPersistentKeysReadsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.rdb")
+	data := append(rdbHeader(), rdbOpSelectDB, 0, rdbOpResizeDB, 3, 1, rdbOpEOF)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("erro ao gravar dump: %v", err)
+	}
+	if n, err := rdbPersistentKeys(path); err != nil || n != 2 {
+		t.Fatalf("contagem = %d, %v; esperado 2", n, err)
+	}
+	if _, err := rdbPersistentKeys(path + ".nada"); err == nil {
+		t.Fatalf("arquivo ausente aceito")
+	}
+}
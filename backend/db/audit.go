@@ -0,0 +1,125 @@
+//backend/db/audit.go
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/audit"
+)
+
+// 🛡️ Log de auditoria na tabela audit_log (append-only por gatilho)
+type auditStore struct {
+	conn    *sql.DB
+	dialect Dialect
+}
+
+// 🛡️ Armazenamento de auditoria no mesmo banco do backend
+func (b *Backend) AuditStore() audit.Store {
+	return &auditStore{conn: b.conn, dialect: b.dialect}
+}
+
+func (s *auditStore) Append(e audit.Event) error {
+	details := []byte("{}")
+	if len(e.Details) > 0 {
+		data, err := json.Marshal(e.Details)
+		if err != nil {
+			return err
+		}
+		details = data
+	}
+
+	placeholders := make([]string, 11)
+	for i := range placeholders {
+		placeholders[i] = s.dialect.Placeholder(i + 1)
+	}
+	query := fmt.Sprintf(`INSERT INTO audit_log (id, time, actor, role, action, target, ip, outcome, status, error, details) VALUES (%s)`,
+		strings.Join(placeholders, ", "))
+
+	_, err := s.conn.Exec(query,
+		e.ID, e.Time.UnixNano(), e.Actor, e.Role, e.Action, e.Target, e.IP,
+		string(e.Outcome), e.Status, e.Error, string(details))
+	if err != nil {
+		return fmt.Errorf("erro ao gravar auditoria: %w", err)
+	}
+	return nil
+}
+
+// 🔎 Eventos filtrados, mais recentes primeiro
+func (s *auditStore) Query(f audit.Filter) ([]audit.Event, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(cond, s.dialect.Placeholder(len(args))))
+	}
+
+	if f.Actor != "" {
+		add("actor = %s", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = %s", f.Action)
+	}
+	if f.Target != "" {
+		add("target = %s", f.Target)
+	}
+	if f.Outcome != "" {
+		add("outcome = %s", string(f.Outcome))
+	}
+	if !f.Since.IsZero() {
+		add("time >= %s", f.Since.UnixNano())
+	}
+	if !f.Until.IsZero() {
+		add("time <= %s", f.Until.UnixNano())
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = audit.DefaultLimit
+	}
+	if limit > audit.MaxLimit {
+		limit = audit.MaxLimit
+	}
+
+	query := `SELECT id, time, actor, role, action, target, ip, outcome, status, error, details FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY seq DESC LIMIT %d", limit)
+
+	rows, err := s.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar auditoria: %w", err)
+	}
+	defer rows.Close()
+
+	events := []audit.Event{}
+	for rows.Next() {
+		var e audit.Event
+		var nanos int64
+		var outcome string
+		var details []byte
+		if err := rows.Scan(&e.ID, &nanos, &e.Actor, &e.Role, &e.Action, &e.Target, &e.IP,
+			&outcome, &e.Status, &e.Error, &details); err != nil {
+			return nil, fmt.Errorf("erro ao ler auditoria: %w", err)
+		}
+		e.Time = time.Unix(0, nanos).UTC()
+		e.Outcome = audit.Outcome(outcome)
+		if len(details) > 0 && string(details) != "{}" {
+			if err := json.Unmarshal(details, &e.Details); err != nil {
+				return nil, fmt.Errorf("detalhes inválidos no evento %s: %w", e.ID, err)
+			}
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// A conexão pertence ao Backend e é fechada por ele
+func (s *auditStore) Close() error {
+	return nil
+}
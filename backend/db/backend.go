@@ -0,0 +1,94 @@
+//backend/db/backend.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Banco SQLite padrão quando DATABASE_URL não é informado
+const DefaultSQLitePath = "./database/virtus.db"
+
+// 🛢️ Backend SQL (SQLite ou PostgreSQL) com esquema migrado no boot
+type Backend struct {
+	conn    *sql.DB
+	dialect Dialect
+}
+
+// 🔌 Conecta ao banco e aplica as migrações pendentes
+func Open(dialect Dialect, dsn string) (*Backend, error) {
+	conn, err := sql.Open(dialect.Driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir banco %s: %w", dialect.Name, err)
+	}
+	if dialect.Name == SQLite.Name {
+		// SQLite aceita um único escritor por vez
+		conn.SetMaxOpenConns(1)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro ao conectar ao banco %s: %w", dialect.Name, err)
+	}
+	if err := Migrate(conn, dialect); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Backend{conn: conn, dialect: dialect}, nil
+}
+
+func (b *Backend) Name() string {
+	return b.dialect.Name
+}
+
+// 📂 Abre a coleção na tabela correspondente (o caminho JSON é ignorado)
+func (b *Backend) Open(name, _ string) (persistence.Collection, error) {
+	spec, ok := tables[name]
+	if !ok {
+		return nil, fmt.Errorf("coleção desconhecida: %s", name)
+	}
+	return &sqlCollection{conn: b.conn, dialect: b.dialect, spec: spec}, nil
+}
+
+// 🛢️ Conexão subjacente
+func (b *Backend) DB() *sql.DB {
+	return b.conn
+}
+
+func (b *Backend) Close() error {
+	return b.conn.Close()
+}
+
+// ⚙️ Seleciona o backend pelas variáveis de ambiente:
+// STORAGE_BACKEND=json (padrão) | sqlite | postgres e DATABASE_URL (DSN ou arquivo SQLite)
+func BackendFromEnv() (persistence.Backend, error) {
+	kind := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_BACKEND")))
+	if kind == "" || kind == "json" {
+		return persistence.NewJSONBackend(), nil
+	}
+
+	dialect, err := DialectByName(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		if dialect.Name != SQLite.Name {
+			return nil, fmt.Errorf("DATABASE_URL é obrigatório para o backend %s", dialect.Name)
+		}
+		dsn = DefaultSQLitePath
+	}
+
+	backend, err := Open(dialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🛢️ Backend de persistência: %s", dialect.Name)
+	return backend, nil
+}
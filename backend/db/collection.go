@@ -0,0 +1,125 @@
+//backend/db/collection.go
+
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🗃️ Coleção persistida em uma tabela SQL
+type sqlCollection struct {
+	conn    *sql.DB
+	dialect Dialect
+	spec    tableSpec
+
+	mu          sync.Mutex
+	fingerprint string
+}
+
+// 📋 Todos os registros da tabela (chave → JSON completo)
+func (c *sqlCollection) Records() (map[string]json.RawMessage, error) {
+	query := fmt.Sprintf(`SELECT %s, data FROM %s`, c.spec.Key, c.spec.Table)
+	rows, err := c.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar %s: %w", c.spec.Table, err)
+	}
+	defer rows.Close()
+
+	records := map[string]json.RawMessage{}
+	for rows.Next() {
+		var key string
+		var data []byte
+		if err := rows.Scan(&key, &data); err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %w", c.spec.Table, err)
+		}
+		records[key] = json.RawMessage(append([]byte(nil), data...))
+	}
+	return records, rows.Err()
+}
+
+// 💾 Upsert do registro, preenchendo as colunas tipadas
+func (c *sqlCollection) Put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return fmt.Errorf("registro inválido para %s: %w", c.spec.Table, err)
+	}
+
+	names := []string{c.spec.Key}
+	args := []interface{}{key}
+	for _, col := range c.spec.Columns {
+		names = append(names, col.Name)
+		args = append(args, col.value(fields))
+	}
+	names = append(names, "data", "updated_at")
+	args = append(args, string(data), time.Now().UnixNano())
+
+	placeholders := make([]string, len(names))
+	updates := make([]string, 0, len(names)-1)
+	for i, name := range names {
+		placeholders[i] = c.dialect.Placeholder(i + 1)
+		if i > 0 {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", name, name))
+		}
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+		c.spec.Table,
+		strings.Join(names, ", "),
+		strings.Join(placeholders, ", "),
+		c.spec.Key,
+		strings.Join(updates, ", "),
+	)
+	if _, err := c.conn.Exec(query, args...); err != nil {
+		return fmt.Errorf("erro ao gravar em %s: %w", c.spec.Table, err)
+	}
+	return nil
+}
+
+// 🗑️ Remove o registro da chave
+func (c *sqlCollection) Delete(key string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = %s`, c.spec.Table, c.spec.Key, c.dialect.Placeholder(1))
+	if _, err := c.conn.Exec(query, key); err != nil {
+		return fmt.Errorf("erro ao remover de %s: %w", c.spec.Table, err)
+	}
+	return nil
+}
+
+// 🔄 Detecta alterações externas pela contagem + última atualização
+func (c *sqlCollection) Reload() (bool, error) {
+	var count, last int64
+	query := fmt.Sprintf(`SELECT COUNT(*), COALESCE(MAX(updated_at), 0) FROM %s`, c.spec.Table)
+	if err := c.conn.QueryRow(query).Scan(&count, &last); err != nil {
+		return false, fmt.Errorf("erro ao consultar %s: %w", c.spec.Table, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fingerprint := fmt.Sprintf("%d:%d", count, last)
+	changed := fingerprint != c.fingerprint
+	c.fingerprint = fingerprint
+	return changed, nil
+}
+
+// Cada gravação já é confirmada no banco
+func (c *sqlCollection) Flush() error {
+	return nil
+}
+
+// A conexão pertence ao Backend
+func (c *sqlCollection) Close() error {
+	return nil
+}
@@ -0,0 +1,35 @@
+//backend/db/dialect.go
+
+package db
+
+import "fmt"
+
+// 🗣️ Diferenças de SQL entre os bancos suportados
+type Dialect struct {
+	Name   string // nome do dialeto (pasta de migrações)
+	Driver string // driver registrado em database/sql
+}
+
+var (
+	Postgres = Dialect{Name: "postgres", Driver: "postgres"}
+	SQLite   = Dialect{Name: "sqlite", Driver: "sqlite3"}
+)
+
+// 🔍 Resolve o dialeto pelo nome configurado
+func DialectByName(name string) (Dialect, error) {
+	switch name {
+	case "postgres", "postgresql":
+		return Postgres, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	}
+	return Dialect{}, fmt.Errorf("banco de dados não suportado: %s", name)
+}
+
+// 🔢 Marcador do n-ésimo parâmetro (1-based)
+func (d Dialect) Placeholder(n int) string {
+	if d.Name == Postgres.Name {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
@@ -0,0 +1,9 @@
+//backend/db/drivers.go
+
+package db
+
+// 🔌 Drivers database/sql dos dialetos suportados
+import (
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,7 @@
+-- backend/db/init.sql
+-- O esquema é versionado em db/migrations/<dialeto>/NNNN_*.sql e aplicado
+-- automaticamente no boot (STORAGE_BACKEND=sqlite|postgres) ou pelo importador:
+--
+--   go run ./tools/importer -driver postgres -dsn "$DATABASE_URL"
+--
+-- As versões aplicadas ficam registradas em schema_migrations.
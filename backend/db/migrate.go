@@ -0,0 +1,101 @@
+//backend/db/migrate.go
+
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 📜 Migrações versionadas por dialeto (NNNN_descricao.sql)
+//
+//go:embed migrations
+var migrationsFS embed.FS
+
+// 📋 Lista as migrações do dialeto em ordem de versão
+func migrationFiles(dialect Dialect) ([]string, error) {
+	dir := path.Join("migrations", dialect.Name)
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar migrações de %s: %w", dialect.Name, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// 🚚 Aplica as migrações pendentes, cada uma em sua própria transação
+func Migrate(conn *sql.DB, dialect Dialect) error {
+	_, err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    TEXT PRIMARY KEY,
+    applied_at TEXT NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("erro ao criar schema_migrations: %w", err)
+	}
+
+	applied := map[string]bool{}
+	rows, err := conn.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("erro ao ler schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+
+	files, err := migrationFiles(dialect)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		version := strings.TrimSuffix(name, ".sql")
+		if applied[version] {
+			continue
+		}
+
+		script, err := migrationsFS.ReadFile(path.Join("migrations", dialect.Name, name))
+		if err != nil {
+			return fmt.Errorf("erro ao ler migração %s: %w", name, err)
+		}
+
+		tx, err := conn.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(string(script)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("erro ao aplicar migração %s: %w", name, err)
+		}
+		insert := fmt.Sprintf(`INSERT INTO schema_migrations (version, applied_at) VALUES (%s, %s)`,
+			dialect.Placeholder(1), dialect.Placeholder(2))
+		if _, err := tx.Exec(insert, version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("erro ao registrar migração %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("erro ao confirmar migração %s: %w", name, err)
+		}
+		log.Printf("🚚 Migração aplicada (%s): %s", dialect.Name, version)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+-- 0003_domains.sql (PostgreSQL)
+-- Domínios personalizados das aplicações e o estado da verificação de posse.
+
+CREATE TABLE IF NOT EXISTS domains (
+    hostname   TEXT PRIMARY KEY,
+    app_id     TEXT NOT NULL DEFAULT '',
+    username   TEXT NOT NULL DEFAULT '',
+    status     TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ,
+    data       JSONB NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS domains_app_id_idx ON domains (app_id);
+CREATE INDEX IF NOT EXISTS domains_username_idx ON domains (username);
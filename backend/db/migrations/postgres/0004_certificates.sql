@@ -0,0 +1,13 @@
+-- 0004_certificates.sql (PostgreSQL)
+-- Certificados TLS emitidos via ACME e a conta ACME; chaves privadas ficam seladas em data.
+
+CREATE TABLE IF NOT EXISTS certificates (
+    name       TEXT PRIMARY KEY,
+    kind       TEXT NOT NULL DEFAULT '',
+    issuer     TEXT NOT NULL DEFAULT '',
+    not_after  TIMESTAMPTZ,
+    data       JSONB NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS certificates_not_after_idx ON certificates (not_after);
@@ -0,0 +1,11 @@
+-- 0005_app_env.sql (PostgreSQL)
+-- Variáveis de ambiente por aplicação; segredos ficam selados com a chave mestra em data.
+
+CREATE TABLE IF NOT EXISTS app_env (
+    app_id     TEXT PRIMARY KEY,
+    username   TEXT NOT NULL DEFAULT '',
+    data       JSONB NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS app_env_username_idx ON app_env (username);
@@ -0,0 +1,14 @@
+-- 0008_deployments.sql (PostgreSQL)
+-- Deploys das aplicações (fase, situação e duração); as entradas ficam no log de cada deploy.
+
+CREATE TABLE IF NOT EXISTS deployments (
+    id         TEXT PRIMARY KEY,
+    app_id     TEXT NOT NULL DEFAULT '',
+    username   TEXT NOT NULL DEFAULT '',
+    status     TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ,
+    data       JSONB NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS deployments_app_id_idx ON deployments (app_id);
@@ -0,0 +1,16 @@
+-- 0009_databases.sql (PostgreSQL)
+-- Bancos gerenciados (containers na rede privada do usuário); a senha fica cifrada no registro.
+
+CREATE TABLE IF NOT EXISTS databases (
+    id         TEXT PRIMARY KEY,
+    username   TEXT NOT NULL DEFAULT '',
+    name       TEXT NOT NULL DEFAULT '',
+    engine     TEXT NOT NULL DEFAULT '',
+    status     TEXT NOT NULL DEFAULT '',
+    size_gb    INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    data       JSONB NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS databases_username_idx ON databases (username);
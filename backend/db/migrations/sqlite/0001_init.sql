@@ -0,0 +1,50 @@
+-- 0001_init.sql (SQLite)
+-- Esquema inicial: usuários, aplicações e sessões.
+-- As colunas tipadas espelham os campos consultados; "data" guarda o registro completo.
+
+CREATE TABLE IF NOT EXISTS users (
+    username      TEXT PRIMARY KEY,
+    email         TEXT NOT NULL DEFAULT '',
+    name          TEXT NOT NULL DEFAULT '',
+    role          TEXT NOT NULL DEFAULT 'user',
+    plan          TEXT NOT NULL DEFAULT '',
+    status        TEXT NOT NULL DEFAULT '',
+    active        INTEGER NOT NULL DEFAULT 0,
+    can_deploy    INTEGER NOT NULL DEFAULT 0,
+    last_activity TEXT NOT NULL DEFAULT '',
+    created_at    TEXT,
+    data          TEXT NOT NULL,
+    updated_at    INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS users_email_idx ON users (lower(email));
+
+CREATE TABLE IF NOT EXISTS apps (
+    id             TEXT PRIMARY KEY,
+    username       TEXT NOT NULL DEFAULT '',
+    name           TEXT NOT NULL DEFAULT '',
+    container_name TEXT NOT NULL DEFAULT '',
+    status         TEXT NOT NULL DEFAULT '',
+    runtime        TEXT NOT NULL DEFAULT '',
+    plan           TEXT NOT NULL DEFAULT '',
+    port           INTEGER NOT NULL DEFAULT 0,
+    start_time     TEXT,
+    data           TEXT NOT NULL,
+    updated_at     INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS apps_username_idx ON apps (username);
+CREATE INDEX IF NOT EXISTS apps_container_name_idx ON apps (container_name);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    username   TEXT PRIMARY KEY,
+    email      TEXT NOT NULL DEFAULT '',
+    role       TEXT NOT NULL DEFAULT '',
+    plan       TEXT NOT NULL DEFAULT '',
+    token      TEXT NOT NULL DEFAULT '',
+    last_seen  TEXT NOT NULL DEFAULT '',
+    data       TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_token_idx ON sessions (token);
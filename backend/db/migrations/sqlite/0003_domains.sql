@@ -0,0 +1,15 @@
+-- 0003_domains.sql (SQLite)
+-- Domínios personalizados das aplicações e o estado da verificação de posse.
+
+CREATE TABLE IF NOT EXISTS domains (
+    hostname   TEXT PRIMARY KEY,
+    app_id     TEXT NOT NULL DEFAULT '',
+    username   TEXT NOT NULL DEFAULT '',
+    status     TEXT NOT NULL DEFAULT '',
+    created_at TEXT,
+    data       TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS domains_app_id_idx ON domains (app_id);
+CREATE INDEX IF NOT EXISTS domains_username_idx ON domains (username);
@@ -0,0 +1,13 @@
+-- 0004_certificates.sql (SQLite)
+-- Certificados TLS emitidos via ACME e a conta ACME; chaves privadas ficam seladas em data.
+
+CREATE TABLE IF NOT EXISTS certificates (
+    name       TEXT PRIMARY KEY,
+    kind       TEXT NOT NULL DEFAULT '',
+    issuer     TEXT NOT NULL DEFAULT '',
+    not_after  TEXT,
+    data       TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS certificates_not_after_idx ON certificates (not_after);
@@ -0,0 +1,11 @@
+-- 0005_app_env.sql (SQLite)
+-- Variáveis de ambiente por aplicação; segredos ficam selados com a chave mestra em data.
+
+CREATE TABLE IF NOT EXISTS app_env (
+    app_id     TEXT PRIMARY KEY,
+    username   TEXT NOT NULL DEFAULT '',
+    data       TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS app_env_username_idx ON app_env (username);
@@ -0,0 +1,15 @@
+-- 0006_volumes.sql (SQLite)
+-- Volumes persistentes por aplicação, com a cota reservada do plano e o uso medido.
+
+CREATE TABLE IF NOT EXISTS volumes (
+    id         TEXT PRIMARY KEY,
+    app_id     TEXT NOT NULL DEFAULT '',
+    username   TEXT NOT NULL DEFAULT '',
+    size_gb    INTEGER NOT NULL DEFAULT 0,
+    used_bytes INTEGER NOT NULL DEFAULT 0,
+    data       TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS volumes_app_id_idx ON volumes (app_id);
+CREATE INDEX IF NOT EXISTS volumes_username_idx ON volumes (username);
@@ -0,0 +1,14 @@
+-- 0007_crash_reports.sql (SQLite)
+-- Relatórios de crash das aplicações (código de saída, OOM e últimas linhas de log), retidos por aplicação.
+
+CREATE TABLE IF NOT EXISTS crash_reports (
+    id         TEXT PRIMARY KEY,
+    app_id     TEXT NOT NULL DEFAULT '',
+    username   TEXT NOT NULL DEFAULT '',
+    exit_code  INTEGER NOT NULL DEFAULT 0,
+    crashed_at TEXT,
+    data       TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS crash_reports_app_id_idx ON crash_reports (app_id);
@@ -0,0 +1,16 @@
+-- 0009_databases.sql (SQLite)
+-- Bancos gerenciados (containers na rede privada do usuário); a senha fica cifrada no registro.
+
+CREATE TABLE IF NOT EXISTS databases (
+    id         TEXT PRIMARY KEY,
+    username   TEXT NOT NULL DEFAULT '',
+    name       TEXT NOT NULL DEFAULT '',
+    engine     TEXT NOT NULL DEFAULT '',
+    status     TEXT NOT NULL DEFAULT '',
+    size_gb    INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT,
+    data       TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS databases_username_idx ON databases (username);
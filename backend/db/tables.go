@@ -0,0 +1,173 @@
+//backend/db/tables.go
+
+package db
+
+import (
+	"encoding/json"
+	"strings"
+
+	"virtuscloud/backend/persistence"
+)
+
+// 🧱 Tipo de coluna extraída do registro JSON
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindBool
+	kindInt
+	kindTime
+)
+
+// 📐 Coluna tipada: nome no banco + campo JSON de origem
+type column struct {
+	Name  string
+	Field string
+	Kind  columnKind
+}
+
+// 📐 Mapeamento de uma coleção para sua tabela
+type tableSpec struct {
+	Table   string
+	Key     string
+	Columns []column
+}
+
+// 🗂️ Tabelas de cada coleção (ver migrations/*/0001_init.sql)
+var tables = map[string]tableSpec{
+	persistence.CollectionUsers: {
+		Table: "users",
+		Key:   "username",
+		Columns: []column{
+			{Name: "email", Field: "email", Kind: kindText},
+			{Name: "name", Field: "name", Kind: kindText},
+			{Name: "role", Field: "role", Kind: kindText},
+			{Name: "plan", Field: "plan", Kind: kindText},
+			{Name: "status", Field: "status", Kind: kindText},
+			{Name: "active", Field: "active", Kind: kindBool},
+			{Name: "can_deploy", Field: "canDeploy", Kind: kindBool},
+			{Name: "last_activity", Field: "last_activity", Kind: kindText},
+			{Name: "created_at", Field: "created_at", Kind: kindTime},
+		},
+	},
+	persistence.CollectionApps: {
+		Table: "apps",
+		Key:   "id",
+		Columns: []column{
+			{Name: "username", Field: "username", Kind: kindText},
+			{Name: "name", Field: "name", Kind: kindText},
+			{Name: "container_name", Field: "container_name", Kind: kindText},
+			{Name: "status", Field: "status", Kind: kindText},
+			{Name: "runtime", Field: "runtime", Kind: kindText},
+			{Name: "plan", Field: "plan", Kind: kindText},
+			{Name: "port", Field: "port", Kind: kindInt},
+			{Name: "start_time", Field: "start_time", Kind: kindTime},
+		},
+	},
+	persistence.CollectionSessions: {
+		Table: "sessions",
+		Key:   "username",
+		Columns: []column{
+			{Name: "email", Field: "email", Kind: kindText},
+			{Name: "role", Field: "role", Kind: kindText},
+			{Name: "plan", Field: "plan", Kind: kindText},
+			{Name: "token", Field: "token", Kind: kindText},
+			{Name: "last_seen", Field: "lastSeen", Kind: kindText},
+		},
+	},
+	persistence.CollectionDomains: {
+		Table: "domains",
+		Key:   "hostname",
+		Columns: []column{
+			{Name: "app_id", Field: "appId", Kind: kindText},
+			{Name: "username", Field: "username", Kind: kindText},
+			{Name: "status", Field: "status", Kind: kindText},
+			{Name: "created_at", Field: "createdAt", Kind: kindTime},
+		},
+	},
+	persistence.CollectionCertificates: {
+		Table: "certificates",
+		Key:   "name",
+		Columns: []column{
+			{Name: "kind", Field: "kind", Kind: kindText},
+			{Name: "issuer", Field: "issuer", Kind: kindText},
+			{Name: "not_after", Field: "notAfter", Kind: kindTime},
+		},
+	},
+	persistence.CollectionAppEnv: {
+		Table: "app_env",
+		Key:   "app_id",
+		Columns: []column{
+			{Name: "username", Field: "username", Kind: kindText},
+		},
+	},
+	persistence.CollectionVolumes: {
+		Table: "volumes",
+		Key:   "id",
+		Columns: []column{
+			{Name: "app_id", Field: "appId", Kind: kindText},
+			{Name: "username", Field: "username", Kind: kindText},
+			{Name: "size_gb", Field: "sizeGB", Kind: kindInt},
+			{Name: "used_bytes", Field: "usedBytes", Kind: kindInt},
+		},
+	},
+	persistence.CollectionCrashReports: {
+		Table: "crash_reports",
+		Key:   "id",
+		Columns: []column{
+			{Name: "app_id", Field: "appId", Kind: kindText},
+			{Name: "username", Field: "username", Kind: kindText},
+			{Name: "exit_code", Field: "exitCode", Kind: kindInt},
+			{Name: "crashed_at", Field: "crashedAt", Kind: kindTime},
+		},
+	},
+	persistence.CollectionDeployments: {
+		Table: "deployments",
+		Key:   "id",
+		Columns: []column{
+			{Name: "app_id", Field: "appId", Kind: kindText},
+			{Name: "username", Field: "username", Kind: kindText},
+			{Name: "status", Field: "status", Kind: kindText},
+			{Name: "started_at", Field: "startedAt", Kind: kindTime},
+		},
+	},
+	persistence.CollectionDatabases: {
+		Table: "databases",
+		Key:   "id",
+		Columns: []column{
+			{Name: "username", Field: "username", Kind: kindText},
+			{Name: "name", Field: "name", Kind: kindText},
+			{Name: "engine", Field: "engine", Kind: kindText},
+			{Name: "status", Field: "status", Kind: kindText},
+			{Name: "size_gb", Field: "sizeGB", Kind: kindInt},
+			{Name: "created_at", Field: "createdAt", Kind: kindTime},
+		},
+	},
+}
+
+// 🔄 Converte o campo do registro para o valor da coluna
+func (c column) value(fields map[string]interface{}) interface{} {
+	raw := fields[c.Field]
+
+	switch c.Kind {
+	case kindBool:
+		b, _ := raw.(bool)
+		return b
+	case kindInt:
+		if n, ok := raw.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return i
+			}
+		}
+		return int64(0)
+	case kindTime:
+		s, _ := raw.(string)
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return s
+	default:
+		s, _ := raw.(string)
+		return s
+	}
+}
@@ -0,0 +1,316 @@
+//backend/docker/client.go
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🔌 Socket padrão do Docker Engine
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// ⏱️ Prazo padrão das chamadas curtas (inspect, start, stop, list...)
+const DefaultTimeout = 30 * time.Second
+
+// 🔨 Builds transmitem o contexto e baixam camadas: precisam de mais tempo
+const BuildTimeout = 10 * time.Minute
+
+// 🔢 Maior versão da Engine API que este cliente conhece
+const maxAPIVersion = "1.43"
+
+// 🐳 Cliente da Docker Engine API (HTTP sobre unix socket ou TCP)
+type Client struct {
+	http    *http.Client
+	baseURL string
+
+	mu         sync.Mutex
+	apiVersion string
+}
+
+// 🏗️ Cria um cliente para o host informado (unix:///caminho ou tcp://host:porta)
+func NewClient(host string) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("DOCKER_HOST inválido '%s': %w", host, err)
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        16,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	baseURL := ""
+
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		// O host da URL é ignorado pelo dialer do socket
+		baseURL = "http://docker"
+	case "tcp", "http":
+		baseURL = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("esquema de DOCKER_HOST não suportado: %s", u.Scheme)
+	}
+
+	return &Client{
+		// Sem timeout global: streams (logs, eventos, build) usam o contexto
+		http:    &http.Client{Transport: transport},
+		baseURL: baseURL,
+	}, nil
+}
+
+// ⚙️ Cliente configurado por DOCKER_HOST (padrão: /var/run/docker.sock)
+func NewClientFromEnv() (*Client, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = DefaultHost
+	}
+	return NewClient(host)
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultClient *Client
+)
+
+// 🐳 Cliente compartilhado pelo backend
+func Default() *Client {
+	defaultOnce.Do(func() {
+		client, err := NewClientFromEnv()
+		if err != nil {
+			// DOCKER_HOST malformado: cai no socket padrão e deixa as chamadas falharem
+			client, _ = NewClient(DefaultHost)
+		}
+		defaultClient = client
+	})
+	return defaultClient
+}
+
+// ⏱️ Contexto com o prazo padrão das chamadas curtas
+func Timeout() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), DefaultTimeout)
+}
+
+// ❌ Erro devolvido pela Engine API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("docker: %s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// 🔍 Container ou imagem inexistente
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ⚠️ Conflito de nome ou de estado (ex.: container já existe)
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// 🏓 Verifica se o daemon responde
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.raw(ctx, http.MethodGet, "/_ping", nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// 🏷️ Versão do daemon
+func (c *Client) Version(ctx context.Context) (*Version, error) {
+	var v Version
+	if err := c.getJSON(ctx, "/version", nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// 🤝 Negocia a versão da API com o daemon (menor entre a dele e a nossa)
+func (c *Client) version(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.apiVersion != "" {
+		return c.apiVersion, nil
+	}
+
+	resp, err := c.raw(ctx, http.MethodGet, "/_ping", nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	version := maxAPIVersion
+	if server := resp.Header.Get("Api-Version"); server != "" && versionLess(server, version) {
+		version = server
+	}
+	c.apiVersion = version
+	return version, nil
+}
+
+// 🔢 Compara versões "1.41" < "1.43"
+func versionLess(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			return na < nb
+		}
+	}
+	return len(pa) < len(pb)
+}
+
+// 📡 Requisição sem prefixo de versão (usada no ping)
+func (c *Client) raw(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker indisponível: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
+
+// 📡 Requisição versionada; o chamador fecha o corpo da resposta
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.raw(ctx, method, "/v"+version+path, query, body, contentType)
+}
+
+// 🔀 POST que troca o protocolo da conexão (Upgrade: tcp) e devolve o stream bruto nos dois sentidos
+func (c *Client) upgrade(ctx context.Context, path string, in interface{}) (io.ReadWriteCloser, error) {
+	version, err := c.version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v"+version+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker indisponível: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if resp.StatusCode != http.StatusSwitchingProtocols || !ok {
+		resp.Body.Close()
+		return nil, fmt.Errorf("docker: upgrade da conexão recusado (HTTP %d)", resp.StatusCode)
+	}
+	return conn, nil
+}
+
+// 📥 GET com resposta JSON
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	resp, err := c.do(ctx, http.MethodGet, path, query, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// 📤 POST com corpo JSON opcional; decodifica a resposta em out quando informado
+func (c *Client) postJSON(ctx context.Context, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// 🧾 Converte a resposta de erro da API ({"message": "..."}) em APIError
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var payload struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &payload) == nil && payload.Message != "" {
+		message = payload.Message
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// 🔎 Codifica filtros no formato esperado pela API ({"label":["a=b"]})
+func encodeFilters(filters map[string][]string) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(filters)
+	return string(data)
+}
@@ -0,0 +1,202 @@
+//backend/docker/containers.go
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 📋 Lista containers (todos com opts.All, senão apenas os em execução)
+func (c *Client) ContainerList(ctx context.Context, opts ListOptions) ([]Container, error) {
+	query := url.Values{}
+	if opts.All {
+		query.Set("all", "1")
+	}
+	if f := encodeFilters(opts.Filters); f != "" {
+		query.Set("filters", f)
+	}
+
+	var containers []Container
+	if err := c.getJSON(ctx, "/containers/json", query, &containers); err != nil {
+		return nil, fmt.Errorf("erro ao listar containers: %w", err)
+	}
+	return containers, nil
+}
+
+// 🔍 Detalhes do container por nome ou ID
+func (c *Client) ContainerInspect(ctx context.Context, name string) (*ContainerJSON, error) {
+	var info ContainerJSON
+	if err := c.getJSON(ctx, "/containers/"+url.PathEscape(name)+"/json", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// 🔍 Indica se existe um container com exatamente esse nome ou ID
+func (c *Client) ContainerExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.ContainerInspect(ctx, name)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// 🆕 Cria o container (sem iniciá-lo)
+func (c *Client) ContainerCreate(ctx context.Context, name string, config *Config, hostConfig *HostConfig, networking *NetworkingConfig) (*CreateResponse, error) {
+	body := struct {
+		*Config
+		HostConfig       *HostConfig       `json:"HostConfig,omitempty"`
+		NetworkingConfig *NetworkingConfig `json:"NetworkingConfig,omitempty"`
+	}{Config: config, HostConfig: hostConfig, NetworkingConfig: networking}
+
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+
+	var created CreateResponse
+	if err := c.postJSON(ctx, "/containers/create", query, body, &created); err != nil {
+		return nil, fmt.Errorf("erro ao criar container %s: %w", name, err)
+	}
+	return &created, nil
+}
+
+// ▶️ Inicia o container (já em execução = sucesso, a API responde 304)
+func (c *Client) ContainerStart(ctx context.Context, name string) error {
+	return c.postJSON(ctx, "/containers/"+url.PathEscape(name)+"/start", nil, nil, nil)
+}
+
+// ⏹️ Para o container, aguardando até timeout antes do SIGKILL (parado = sucesso)
+func (c *Client) ContainerStop(ctx context.Context, name string, timeout time.Duration) error {
+	query := url.Values{"t": {strconv.Itoa(int(timeout.Seconds()))}}
+	return c.postJSON(ctx, "/containers/"+url.PathEscape(name)+"/stop", query, nil, nil)
+}
+
+// 🔁 Reinicia o container
+func (c *Client) ContainerRestart(ctx context.Context, name string, timeout time.Duration) error {
+	query := url.Values{"t": {strconv.Itoa(int(timeout.Seconds()))}}
+	return c.postJSON(ctx, "/containers/"+url.PathEscape(name)+"/restart", query, nil, nil)
+}
+
+// 🧹 Opções de remoção
+type RemoveOptions struct {
+	Force         bool // remove mesmo em execução (docker rm -f)
+	RemoveVolumes bool // remove volumes anônimos
+}
+
+// 🧹 Remove o container
+func (c *Client) ContainerRemove(ctx context.Context, name string, opts RemoveOptions) error {
+	query := url.Values{}
+	if opts.Force {
+		query.Set("force", "1")
+	}
+	if opts.RemoveVolumes {
+		query.Set("v", "1")
+	}
+	resp, err := c.do(ctx, http.MethodDelete, "/containers/"+url.PathEscape(name), query, nil, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ✏️ Atualiza limites de recursos e política de reinício
+func (c *Client) ContainerUpdate(ctx context.Context, name string, update UpdateConfig) error {
+	return c.postJSON(ctx, "/containers/"+url.PathEscape(name)+"/update", nil, update, nil)
+}
+
+// 📄 Opções de leitura de logs
+type LogsOptions struct {
+	Stdout     bool
+	Stderr     bool
+	Follow     bool
+	Timestamps bool
+	Tail       string    // "all" ou número de linhas
+	Since      time.Time // zero = desde o início
+}
+
+// 📄 Stream bruto de logs; use DemuxLogs quando o container não tem TTY
+func (c *Client) ContainerLogs(ctx context.Context, name string, opts LogsOptions) (io.ReadCloser, error) {
+	query := url.Values{}
+	if opts.Stdout {
+		query.Set("stdout", "1")
+	}
+	if opts.Stderr {
+		query.Set("stderr", "1")
+	}
+	if opts.Follow {
+		query.Set("follow", "1")
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "1")
+	}
+	if opts.Tail != "" {
+		query.Set("tail", opts.Tail)
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", strconv.FormatInt(opts.Since.Unix(), 10))
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(name)+"/logs", query, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// 📄 Logs completos (stdout + stderr) como texto, como o `docker logs`
+func (c *Client) ContainerLogsText(ctx context.Context, name string) (string, error) {
+	info, err := c.ContainerInspect(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	stream, err := c.ContainerLogs(ctx, name, LogsOptions{Stdout: true, Stderr: true})
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var out bytes.Buffer
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(&out, stream)
+	} else {
+		err = DemuxLogs(&out, &out, stream)
+	}
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler logs de %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// 📊 Amostra única de métricas (a API aguarda duas leituras para calcular CPU)
+func (c *Client) ContainerStats(ctx context.Context, name string) (*Stats, error) {
+	query := url.Values{"stream": {"0"}}
+	var stats Stats
+	if err := c.getJSON(ctx, "/containers/"+url.PathEscape(name)+"/stats", query, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// 📦 Copia um caminho do container (equivalente a `docker cp container:src destDir`)
+func (c *Client) CopyFromContainer(ctx context.Context, name, srcPath, destDir string) error {
+	query := url.Values{"path": {srcPath}}
+	resp, err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(name)+"/archive", query, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return extractTar(resp.Body, destDir)
+}
@@ -0,0 +1,51 @@
+//backend/docker/events.go
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// 📡 Assina o stream de eventos do daemon até ctx ser cancelado.
+// O canal de erros recebe no máximo um valor quando o stream termina.
+func (c *Client) Events(ctx context.Context, filters map[string][]string) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		query := url.Values{}
+		if f := encodeFilters(filters); f != "" {
+			query.Set("filters", f)
+		}
+		resp, err := c.do(ctx, http.MethodGet, "/events", query, nil, "")
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var e Event
+			if err := decoder.Decode(&e); err != nil {
+				if ctx.Err() == nil {
+					errs <- err
+				}
+				return
+			}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
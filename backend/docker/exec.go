@@ -0,0 +1,149 @@
+//backend/docker/exec.go
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ⚙️ Configuração de /containers/{id}/exec
+type ExecConfig struct {
+	Cmd          []string `json:"Cmd"`
+	Env          []string `json:"Env,omitempty"`
+	WorkingDir   string   `json:"WorkingDir,omitempty"`
+	User         string   `json:"User,omitempty"`
+	Tty          bool     `json:"Tty"`
+	AttachStdin  bool     `json:"AttachStdin"`
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+}
+
+// 🔍 Resposta de /exec/{id}/json
+type ExecInspect struct {
+	ID       string `json:"ID"`
+	Running  bool   `json:"Running"`
+	ExitCode int    `json:"ExitCode"`
+	Pid      int    `json:"Pid"`
+}
+
+// 🆕 Cria uma instância de exec no container e devolve o ID
+func (c *Client) ExecCreate(ctx context.Context, name string, config ExecConfig) (string, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := c.postJSON(ctx, "/containers/"+url.PathEscape(name)+"/exec", nil, config, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// ▶️ Inicia o exec e devolve o stream bruto da saída (multiplexado quando sem TTY; use DemuxLogs)
+func (c *Client) ExecStart(ctx context.Context, id string, tty bool) (io.ReadCloser, error) {
+	data, err := json.Marshal(map[string]bool{"Detach": false, "Tty": tty})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/exec/"+url.PathEscape(id)+"/start", nil, bytes.NewReader(data), "application/json")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// 🖥️ Inicia o exec criado com AttachStdin e devolve a conexão bidirecional: leitura = saída
+// (multiplexada quando sem TTY; use DemuxLogs), escrita = entrada. A conexão sobrevive ao fim de ctx; feche-a ao terminar.
+func (c *Client) ExecAttach(ctx context.Context, id string, tty bool) (io.ReadWriteCloser, error) {
+	return c.upgrade(ctx, "/exec/"+url.PathEscape(id)+"/start", map[string]bool{"Detach": false, "Tty": tty})
+}
+
+// 📐 Ajusta o tamanho do TTY do exec
+func (c *Client) ExecResize(ctx context.Context, id string, rows, cols int) error {
+	query := url.Values{}
+	query.Set("h", strconv.Itoa(rows))
+	query.Set("w", strconv.Itoa(cols))
+	return c.postJSON(ctx, "/exec/"+url.PathEscape(id)+"/resize", query, nil, nil)
+}
+
+// 🔍 Estado do exec (ExitCode só vale depois que Running vira false)
+func (c *Client) ExecInspect(ctx context.Context, id string) (*ExecInspect, error) {
+	var info ExecInspect
+	if err := c.getJSON(ctx, "/exec/"+url.PathEscape(id)+"/json", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// 🧪 Executa o comando até o fim, separando stdout/stderr, e devolve o código de saída
+func (c *Client) ExecRun(ctx context.Context, name string, cmd []string, stdout, stderr io.Writer) (int, error) {
+	id, err := c.ExecCreate(ctx, name, ExecConfig{Cmd: cmd, AttachStdout: true, AttachStderr: true})
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar exec em %s: %w", name, err)
+	}
+
+	stream, err := c.ExecStart(ctx, id, false)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar exec em %s: %w", name, err)
+	}
+	err = DemuxLogs(stdout, stderr, stream)
+	stream.Close()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler saída do exec em %s: %w", name, err)
+	}
+
+	info, err := c.ExecInspect(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar exec em %s: %w", name, err)
+	}
+	return info.ExitCode, nil
+}
+
+// 🔀 Executa o comando sem TTY com a entrada em fluxo (rsync, tar), separando stdout/stderr, e devolve o código de saída.
+// A entrada é repassada até o processo sair; cancelar ctx derruba a conexão.
+func (c *Client) ExecStream(ctx context.Context, name string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	id, err := c.ExecCreate(ctx, name, ExecConfig{Cmd: cmd, AttachStdin: true, AttachStdout: true, AttachStderr: true})
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar exec em %s: %w", name, err)
+	}
+
+	conn, err := c.ExecAttach(ctx, id, false)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar exec em %s: %w", name, err)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	go func() {
+		_, _ = io.Copy(conn, stdin) // termina quando a conexão fecha
+	}()
+	err = DemuxLogs(stdout, stderr, conn)
+	conn.Close()
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler saída do exec em %s: %w", name, err)
+	}
+
+	// A saída pode terminar um instante antes de a Engine registrar o fim do processo
+	for {
+		info, err := c.ExecInspect(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("erro ao consultar exec em %s: %w", name, err)
+		}
+		if !info.Running {
+			return info.ExitCode, nil
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,143 @@
+//backend/docker/images.go
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 🖼️ Lista imagens locais
+func (c *Client) ImageList(ctx context.Context) ([]Image, error) {
+	var images []Image
+	if err := c.getJSON(ctx, "/images/json", nil, &images); err != nil {
+		return nil, fmt.Errorf("erro ao listar imagens: %w", err)
+	}
+	return images, nil
+}
+
+// 🔍 Indica se a imagem (nome[:tag] ou ID) existe localmente
+func (c *Client) ImageExists(ctx context.Context, ref string) (bool, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/images/"+ref+"/json", nil, nil, "")
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
+// 🔍 Detalhes da imagem (nome[:tag] ou ID)
+func (c *Client) ImageInspect(ctx context.Context, ref string) (*Image, error) {
+	var image Image
+	if err := c.getJSON(ctx, "/images/"+ref+"/json", nil, &image); err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// 🧹 Remove a imagem (force = docker rmi -f)
+func (c *Client) ImageRemove(ctx context.Context, ref string, force bool) error {
+	query := url.Values{}
+	if force {
+		query.Set("force", "1")
+	}
+	resp, err := c.do(ctx, http.MethodDelete, "/images/"+ref, query, nil, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// 📨 Mensagem do stream de /build
+type buildMessage struct {
+	Stream      string `json:"stream"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	ErrorDetail struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// 🔨 Constrói a imagem a partir do diretório (Dockerfile na raiz) e devolve a saída do build
+func (c *Client) ImageBuild(ctx context.Context, contextDir, tag string) (string, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(tarDirectory(contextDir, pw))
+	}()
+	defer pr.Close()
+
+	query := url.Values{
+		"t":       {tag},
+		"rm":      {"1"},
+		"forcerm": {"1"},
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/build", query, pr, "application/x-tar")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var output strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg buildMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return output.String(), fmt.Errorf("erro ao ler saída do build: %w", err)
+		}
+
+		output.WriteString(msg.Stream)
+		if msg.Status != "" {
+			output.WriteString(msg.Status + "\n")
+		}
+		if msg.Error != "" {
+			message := msg.ErrorDetail.Message
+			if message == "" {
+				message = msg.Error
+			}
+			return output.String(), fmt.Errorf("build falhou: %s", message)
+		}
+	}
+	return output.String(), nil
+}
+
+// ⬇️ Baixa a imagem do registro (nome[:tag]; sem tag = latest) e espera o fim do download
+func (c *Client) ImagePull(ctx context.Context, ref string) error {
+	name, tag := ref, "latest"
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		name, tag = ref[:i], ref[i+1:]
+	}
+	query := url.Values{"fromImage": {name}, "tag": {tag}}
+	resp, err := c.do(ctx, http.MethodPost, "/images/create", query, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg buildMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("erro ao ler progresso do download de %s: %w", ref, err)
+		}
+		if msg.Error != "" {
+			message := msg.ErrorDetail.Message
+			if message == "" {
+				message = msg.Error
+			}
+			return fmt.Errorf("download de %s falhou: %s", ref, message)
+		}
+	}
+}
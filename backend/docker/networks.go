@@ -0,0 +1,116 @@
+//backend/docker/networks.go
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// 🕸️ Item de /networks e resposta de /networks/{id}
+type Network struct {
+	ID       string                     `json:"Id"`
+	Name     string                     `json:"Name"`
+	Driver   string                     `json:"Driver"`
+	Internal bool                       `json:"Internal"`
+	Labels   map[string]string          `json:"Labels"`
+	Options  map[string]string          `json:"Options"`
+	Members  map[string]NetworkResource `json:"Containers"` // ID do container → endpoint
+}
+
+// 🔌 Endpoint de um container na rede (visto pela rede)
+type NetworkResource struct {
+	Name        string `json:"Name"`
+	IPv4Address string `json:"IPv4Address"` // "172.18.0.2/16"
+}
+
+// 🆕 Corpo de /networks/create
+type NetworkCreate struct {
+	Name           string            `json:"Name"`
+	Driver         string            `json:"Driver,omitempty"`
+	Internal       bool              `json:"Internal,omitempty"`
+	Labels         map[string]string `json:"Labels,omitempty"`
+	Options        map[string]string `json:"Options,omitempty"`
+	CheckDuplicate bool              `json:"CheckDuplicate,omitempty"`
+}
+
+// 🔌 Configuração do container em uma rede (visto pelo container)
+type EndpointSettings struct {
+	Aliases   []string `json:"Aliases,omitempty"`
+	NetworkID string   `json:"NetworkID,omitempty"`
+	IPAddress string   `json:"IPAddress,omitempty"`
+}
+
+// 🔗 Redes do container na criação (rede → endpoint), além de HostConfig.NetworkMode
+type NetworkingConfig struct {
+	EndpointsConfig map[string]EndpointSettings `json:"EndpointsConfig,omitempty"`
+}
+
+// 🌐 Parte de rede do inspect de container
+type NetworkSettings struct {
+	Networks map[string]EndpointSettings `json:"Networks"`
+}
+
+// 📋 Lista redes (filtros como {"label": {"virtuscloud.user"}})
+func (c *Client) NetworkList(ctx context.Context, filters map[string][]string) ([]Network, error) {
+	query := url.Values{}
+	if f := encodeFilters(filters); f != "" {
+		query.Set("filters", f)
+	}
+
+	var networks []Network
+	if err := c.getJSON(ctx, "/networks", query, &networks); err != nil {
+		return nil, fmt.Errorf("erro ao listar redes: %w", err)
+	}
+	return networks, nil
+}
+
+// 🔍 Detalhes da rede por nome ou ID
+func (c *Client) NetworkInspect(ctx context.Context, name string) (*Network, error) {
+	var network Network
+	if err := c.getJSON(ctx, "/networks/"+url.PathEscape(name), nil, &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+// 🆕 Cria a rede e devolve o ID
+func (c *Client) NetworkCreate(ctx context.Context, spec NetworkCreate) (string, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := c.postJSON(ctx, "/networks/create", nil, spec, &created); err != nil {
+		return "", fmt.Errorf("erro ao criar rede %s: %w", spec.Name, err)
+	}
+	return created.ID, nil
+}
+
+// 🗑️ Remove a rede (falha com containers ainda conectados)
+func (c *Client) NetworkRemove(ctx context.Context, name string) error {
+	resp, err := c.do(ctx, http.MethodDelete, "/networks/"+url.PathEscape(name), nil, nil, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// 🔗 Conecta o container à rede com os aliases de DNS informados
+func (c *Client) NetworkConnect(ctx context.Context, network, container string, aliases []string) error {
+	body := struct {
+		Container      string           `json:"Container"`
+		EndpointConfig EndpointSettings `json:"EndpointConfig"`
+	}{Container: container, EndpointConfig: EndpointSettings{Aliases: aliases}}
+	return c.postJSON(ctx, "/networks/"+url.PathEscape(network)+"/connect", nil, body, nil)
+}
+
+// ✂️ Desconecta o container da rede
+func (c *Client) NetworkDisconnect(ctx context.Context, network, container string, force bool) error {
+	body := struct {
+		Container string `json:"Container"`
+		Force     bool   `json:"Force,omitempty"`
+	}{Container: container, Force: force}
+	return c.postJSON(ctx, "/networks/"+url.PathEscape(network)+"/disconnect", nil, body, nil)
+}
@@ -0,0 +1,64 @@
+//backend/docker/stdcopy.go
+
+package docker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// 🔀 Identificadores de stream no formato multiplexado da API
+const (
+	StreamStdin  = 0
+	StreamStdout = 1
+	StreamStderr = 2
+)
+
+// 🔀 Separa o stream multiplexado de logs/attach (containers sem TTY).
+// Cada quadro tem cabeçalho de 8 bytes: [stream, 0, 0, 0, tamanho uint32 big-endian].
+func DemuxLogs(stdout, stderr io.Writer, src io.Reader) error {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(src, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var dst io.Writer
+		switch header[0] {
+		case StreamStdin, StreamStdout:
+			dst = stdout
+		case StreamStderr:
+			dst = stderr
+		default:
+			return fmt.Errorf("stream multiplexado inválido: %d", header[0])
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(dst, src, size); err != nil {
+			return err
+		}
+	}
+}
+
+// 📨 Quadro de log já separado
+type Frame struct {
+	Stream  int
+	Payload []byte
+}
+
+// 📨 Lê o próximo quadro do stream multiplexado (io.EOF ao final)
+func ReadFrame(src io.Reader) (*Frame, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[4:]))
+	if _, err := io.ReadFull(src, payload); err != nil {
+		return nil, err
+	}
+	return &Frame{Stream: int(header[0]), Payload: payload}, nil
+}
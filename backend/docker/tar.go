@@ -0,0 +1,192 @@
+//backend/docker/tar.go
+
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 📦 Empacota o diretório de build em tar, respeitando o .dockerignore
+func tarDirectory(dir string, w io.Writer) error {
+	ignore, err := readDockerignore(dir)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Dockerfile e .dockerignore sempre vão para o daemon
+		if rel != "Dockerfile" && rel != ".dockerignore" && ignore.matches(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(tw, file)
+			file.Close()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao empacotar contexto de build: %w", err)
+	}
+	return tw.Close()
+}
+
+// 🙈 Padrões do .dockerignore (com suporte a negação "!")
+type ignorePatterns []string
+
+func readDockerignore(dir string) (ignorePatterns, error) {
+	file, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns ignorePatterns
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		negate := strings.HasPrefix(line, "!")
+		line = strings.TrimPrefix(line, "!")
+		line = strings.Trim(filepath.ToSlash(filepath.Clean(line)), "/")
+		if negate {
+			line = "!" + line
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// 🔍 O último padrão que casa decide; um diretório ignorado ignora seu conteúdo
+func (p ignorePatterns) matches(rel string) bool {
+	ignored := false
+	for _, pattern := range p {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if matchPathOrParent(pattern, rel) {
+			ignored = !negate
+		}
+	}
+	return ignored
+}
+
+func matchPathOrParent(pattern, rel string) bool {
+	for candidate := rel; candidate != "."; candidate = filepath.ToSlash(filepath.Dir(candidate)) {
+		if pattern == "**" {
+			return true
+		}
+		if strings.HasPrefix(pattern, "**/") {
+			// **/x casa x em qualquer profundidade
+			if ok, _ := filepath.Match(strings.TrimPrefix(pattern, "**/"), filepath.Base(candidate)); ok {
+				return true
+			}
+		}
+		if ok, _ := filepath.Match(pattern, candidate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// 📂 Extrai um tar em destDir, recusando caminhos que escapem do destino.
+// Links simbólicos e físicos são ignorados (backups guardam apenas arquivos).
+func extractTar(r io.Reader, destDir string) error {
+	root, err := filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler arquivo tar: %w", err)
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(header.Name))
+		if target != root && !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			return fmt.Errorf("caminho inválido no tar: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0777)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tr); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			log.Printf("⚠️ Link ignorado ao extrair: %s", header.Name)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+//backend/docker/types.go
+
+package docker
+
+import (
+	"strings"
+	"time"
+)
+
+// 🏷️ Resposta de /version
+type Version struct {
+	Version       string `json:"Version"`
+	APIVersion    string `json:"ApiVersion"`
+	MinAPIVersion string `json:"MinAPIVersion"`
+	Os            string `json:"Os"`
+	Arch          string `json:"Arch"`
+}
+
+// 📋 Item de /containers/json
+type Container struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
+	Command string            `json:"Command"`
+	Created int64             `json:"Created"`
+	State   string            `json:"State"`  // running, exited, created, paused, restarting, dead
+	Status  string            `json:"Status"` // texto humano: "Up 5 minutes", "Exited (0) ..."
+	Labels  map[string]string `json:"Labels"`
+}
+
+// 🏷️ Nome do container sem a barra inicial
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// 🔎 Opções de listagem de containers
+type ListOptions struct {
+	All     bool
+	Filters map[string][]string // ex.: {"label": {"username"}}
+}
+
+// 🔍 Resposta de /containers/{id}/json
+type ContainerJSON struct {
+	ID           string          `json:"Id"`
+	Name         string          `json:"Name"`
+	Image        string          `json:"Image"` // ID da imagem
+	Created      time.Time       `json:"Created"`
+	RestartCount int             `json:"RestartCount"`
+	State        *ContainerState `json:"State"`
+	Config       *Config         `json:"Config"`
+	HostConfig   *HostConfig     `json:"HostConfig"`
+
+	NetworkSettings *NetworkSettings `json:"NetworkSettings"`
+}
+
+// 🚦 Estado do container
+type ContainerState struct {
+	Status     string    `json:"Status"`
+	Running    bool      `json:"Running"`
+	Paused     bool      `json:"Paused"`
+	Restarting bool      `json:"Restarting"`
+	OOMKilled  bool      `json:"OOMKilled"`
+	Dead       bool      `json:"Dead"`
+	Pid        int       `json:"Pid"`
+	ExitCode   int       `json:"ExitCode"`
+	Error      string    `json:"Error"`
+	StartedAt  time.Time `json:"StartedAt"`
+	FinishedAt time.Time `json:"FinishedAt"`
+}
+
+// ⚙️ Configuração do container (parte independente do host)
+type Config struct {
+	Hostname     string              `json:"Hostname,omitempty"`
+	User         string              `json:"User,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	Entrypoint   []string            `json:"Entrypoint,omitempty"`
+	Image        string              `json:"Image,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Tty          bool                `json:"Tty,omitempty"`
+	OpenStdin    bool                `json:"OpenStdin,omitempty"`
+}
+
+// 🔁 Política de reinício
+type RestartPolicy struct {
+	Name              string `json:"Name"` // no, always, unless-stopped, on-failure
+	MaximumRetryCount int    `json:"MaximumRetryCount,omitempty"`
+}
+
+// 🔌 Mapeamento de porta do host
+type PortBinding struct {
+	HostIP   string `json:"HostIp,omitempty"`
+	HostPort string `json:"HostPort,omitempty"`
+}
+
+// 📏 Limites de recursos (usados na criação e em /update)
+type Resources struct {
+	Memory      int64    `json:"Memory,omitempty"`     // bytes
+	MemorySwap  int64    `json:"MemorySwap,omitempty"` // bytes (memória + swap)
+	NanoCPUs    int64    `json:"NanoCpus,omitempty"`
+	CPUShares   int64    `json:"CpuShares,omitempty"`
+	PidsLimit   int64    `json:"PidsLimit,omitempty"`
+	BlkioWeight uint16   `json:"BlkioWeight,omitempty"` // 10–1000
+	Ulimits     []Ulimit `json:"Ulimits,omitempty"`     // só na criação: /update não altera ulimits
+}
+
+// 🔢 Limite rlimit do processo (ex.: nofile)
+type Ulimit struct {
+	Name string `json:"Name"`
+	Soft int64  `json:"Soft"`
+	Hard int64  `json:"Hard"`
+}
+
+// 🖥️ Configuração dependente do host
+type HostConfig struct {
+	Resources
+	Binds         []string                 `json:"Binds,omitempty"`
+	PortBindings  map[string][]PortBinding `json:"PortBindings,omitempty"`
+	RestartPolicy RestartPolicy            `json:"RestartPolicy"`
+	NetworkMode   string                   `json:"NetworkMode,omitempty"`
+	AutoRemove    bool                     `json:"AutoRemove,omitempty"`
+}
+
+// ✏️ Corpo de /containers/{id}/update
+type UpdateConfig struct {
+	Resources
+	RestartPolicy *RestartPolicy `json:"RestartPolicy,omitempty"`
+}
+
+// 🆕 Resposta de /containers/create
+type CreateResponse struct {
+	ID       string   `json:"Id"`
+	Warnings []string `json:"Warnings"`
+}
+
+// 🖼️ Item de /images/json
+type Image struct {
+	ID       string   `json:"Id"`
+	RepoTags []string `json:"RepoTags"`
+	Created  int64    `json:"Created"`
+	Size     int64    `json:"Size"`
+}
+
+// 📦 Repositórios da imagem (sem a tag)
+func (i Image) Repositories() []string {
+	repos := make([]string, 0, len(i.RepoTags))
+	for _, tag := range i.RepoTags {
+		if idx := strings.LastIndex(tag, ":"); idx > strings.LastIndex(tag, "/") {
+			tag = tag[:idx]
+		}
+		repos = append(repos, tag)
+	}
+	return repos
+}
+
+// 📡 Mensagem de /events
+type Event struct {
+	Type     string     `json:"Type"`
+	Action   string     `json:"Action"`
+	Actor    EventActor `json:"Actor"`
+	Time     int64      `json:"time"`
+	TimeNano int64      `json:"timeNano"`
+}
+
+type EventActor struct {
+	ID         string            `json:"ID"`
+	Attributes map[string]string `json:"Attributes"`
+}
+
+// 📊 Resposta de /containers/{id}/stats (stream=false)
+type Stats struct {
+	Name        string      `json:"name"`
+	ID          string      `json:"id"`
+	Read        time.Time   `json:"read"`
+	CPUStats    CPUStats    `json:"cpu_stats"`
+	PreCPUStats CPUStats    `json:"precpu_stats"`
+	MemoryStats MemoryStats `json:"memory_stats"`
+	PidsStats   struct {
+		Current uint64 `json:"current"`
+		Limit   uint64 `json:"limit"`
+	} `json:"pids_stats"`
+}
+
+type CPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint32 `json:"online_cpus"`
+}
+
+type MemoryStats struct {
+	Usage uint64            `json:"usage"`
+	Limit uint64            `json:"limit"`
+	Stats map[string]uint64 `json:"stats"`
+}
+
+// ⚡ Percentual de CPU como o `docker stats` (100% = um núcleo inteiro)
+func (s *Stats) CPUPercent() float64 {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	cpus := float64(s.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// 🧠 Memória em uso descontando o page cache, como o `docker stats`
+func (s *Stats) MemoryUsage() uint64 {
+	usage := s.MemoryStats.Usage
+	// cgroup v2 usa inactive_file; cgroup v1 usa total_inactive_file
+	for _, key := range []string{"inactive_file", "total_inactive_file"} {
+		if cache, ok := s.MemoryStats.Stats[key]; ok {
+			if cache < usage {
+				return usage - cache
+			}
+			return usage
+		}
+	}
+	return usage
+}
+
+// 🔢 Bytes → MB
+func BytesToMB(b uint64) float64 {
+	return float64(b) / (1024 * 1024)
+}
+
+// 🔢 MB → bytes
+func MBToBytes(mb int) int64 {
+	return int64(mb) * 1024 * 1024
+}
@@ -0,0 +1,318 @@
+//backend/domains/domains.go
+
+package domains
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/ingress"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🏷️ Registro TXT do desafio: _virtuscloud-challenge.<hostname> = "virtuscloud-verify=<token>"
+const (
+	ChallengeLabel  = "_virtuscloud-challenge"
+	ChallengePrefix = "virtuscloud-verify="
+)
+
+// ⏳ Prazo para o desafio pendente ser cumprido antes de virar "failed"
+const PendingTTL = 7 * 24 * time.Hour
+
+// ❗ Erros de validação (o handler escolhe o status HTTP com errors.Is)
+var (
+	ErrInvalidHostname = errors.New("hostname inválido")
+	ErrHostnameTaken   = errors.New("domínio já cadastrado")
+	ErrNotAllowed      = errors.New("domínio não permitido pelo plano")
+	ErrNotFound        = errors.New("domínio não encontrado")
+)
+
+// 📝 Instrução do registro DNS que o usuário deve criar
+type Challenge struct {
+	Type  string `json:"type"` // TXT ou CNAME
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// 🔒 Serializa cadastros para a contagem por plano e a unicidade valerem juntas
+var addMu sync.Mutex
+
+// 🧹 Domínios saem junto com a aplicação
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		for _, domain := range store.DomainStore.ListByApp(app.ID) {
+			if err := store.DomainStore.Delete(domain.Hostname); err != nil {
+				log.Printf("⚠️ Erro ao remover domínio %s da aplicação %s: %v", domain.Hostname, app.ID, err)
+				continue
+			}
+			log.Printf("🌍 Domínio %s removido junto com a aplicação %s", domain.Hostname, app.ID)
+		}
+	})
+}
+
+// ➕ Cadastra o hostname para a aplicação e devolve o desafio a ser publicado no DNS
+func Add(username string, app *models.App, hostname, method string) (*models.Domain, Challenge, error) {
+	hostname, err := NormalizeHostname(hostname)
+	if err != nil {
+		return nil, Challenge{}, err
+	}
+	if method == "" {
+		method = models.DomainMethodTXT
+	}
+	if method != models.DomainMethodTXT && method != models.DomainMethodCNAME {
+		return nil, Challenge{}, fmt.Errorf("método de verificação '%s' inválido (use txt ou cname)", method)
+	}
+	if method == models.DomainMethodCNAME && ingress.Default() == nil {
+		return nil, Challenge{}, errors.New("verificação por CNAME indisponível com o ingress desligado")
+	}
+
+	addMu.Lock()
+	defer addMu.Unlock()
+
+	if _, taken := store.DomainStore.Get(hostname); taken {
+		return nil, Challenge{}, fmt.Errorf("%w: %s", ErrHostnameTaken, hostname)
+	}
+	active := 0
+	for _, d := range store.DomainStore.ListByUser(username) {
+		if d.Status != models.DomainFailed {
+			active++
+		}
+	}
+	if err := limits.CanAddCustomDomain(username, active); err != nil {
+		return nil, Challenge{}, fmt.Errorf("%w: %v", ErrNotAllowed, err)
+	}
+
+	domain := &models.Domain{
+		Hostname:  hostname,
+		AppID:     app.ID,
+		Username:  username,
+		Method:    method,
+		Token:     newToken(),
+		Status:    models.DomainPending,
+		CreatedAt: time.Now(),
+	}
+	if err := store.DomainStore.Create(domain); err != nil {
+		return nil, Challenge{}, fmt.Errorf("erro ao salvar domínio: %w", err)
+	}
+	log.Printf("🌍 Domínio %s cadastrado para %s (%s, desafio %s)", hostname, app.ID, username, method)
+	return domain, ChallengeFor(domain, app), nil
+}
+
+// 📝 Registro DNS que comprova a posse do domínio
+func ChallengeFor(domain *models.Domain, app *models.App) Challenge {
+	if domain.Method == models.DomainMethodCNAME {
+		return Challenge{Type: "CNAME", Name: domain.Hostname, Value: platformHost(app)}
+	}
+	return Challenge{
+		Type:  "TXT",
+		Name:  ChallengeLabel + "." + domain.Hostname,
+		Value: ChallengePrefix + domain.Token,
+	}
+}
+
+// 🔍 Consulta o DNS e atualiza o estado do domínio; verificado passa a ser roteado pelo ingress
+func Verify(ctx context.Context, hostname string) (*models.Domain, error) {
+	domain, ok := store.DomainStore.Get(hostname)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	app, ok := store.AppStore.Get(domain.AppID)
+	if !ok {
+		return nil, fmt.Errorf("aplicação %s do domínio não encontrada", domain.AppID)
+	}
+
+	checkErr := check(ctx, domain, app)
+	now := time.Now()
+	updated, err := store.DomainStore.Update(domain.Hostname, func(d *models.Domain) {
+		d.LastCheck = &now
+		if checkErr != nil {
+			d.LastError = checkErr.Error()
+			return
+		}
+		d.LastError = ""
+		if d.Status != models.DomainVerified {
+			d.Status = models.DomainVerified
+			d.VerifiedAt = &now
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar domínio: %w", err)
+	}
+
+	if checkErr == nil && domain.Status != models.DomainVerified {
+		log.Printf("✅ Domínio %s verificado para %s", updated.Hostname, updated.AppID)
+		refreshRoutes(updated.AppID)
+	}
+	return updated, nil
+}
+
+// 🗑️ Remove o domínio e tira a rota do ingress
+func Remove(hostname string) error {
+	domain, ok := store.DomainStore.Get(hostname)
+	if !ok {
+		return ErrNotFound
+	}
+	if err := store.DomainStore.Delete(domain.Hostname); err != nil {
+		return fmt.Errorf("erro ao remover domínio: %w", err)
+	}
+	refreshRoutes(domain.AppID)
+	log.Printf("🗑️ Domínio %s removido de %s", domain.Hostname, domain.AppID)
+	return nil
+}
+
+// 🌐 Domínios verificados da aplicação (HostProvider do ingress)
+func HostsForApp(app *models.App) []string {
+	var hosts []string
+	for _, domain := range store.DomainStore.ListByApp(app.ID) {
+		if domain.Status == models.DomainVerified {
+			hosts = append(hosts, domain.Hostname)
+		}
+	}
+	return hosts
+}
+
+// 🔁 Verifica periodicamente os domínios pendentes e expira os que passaram do prazo
+func StartVerifier(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			verifyPending()
+		}
+	}()
+}
+
+func verifyPending() {
+	for _, domain := range store.DomainStore.List() {
+		if domain.Status != models.DomainPending {
+			continue
+		}
+		if time.Since(domain.CreatedAt) > PendingTTL {
+			if _, err := store.DomainStore.Update(domain.Hostname, func(d *models.Domain) {
+				d.Status = models.DomainFailed
+				d.LastError = "desafio não encontrado dentro do prazo"
+			}); err != nil {
+				log.Printf("⚠️ Erro ao expirar domínio %s: %v", domain.Hostname, err)
+			}
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if _, err := Verify(ctx, domain.Hostname); err != nil {
+			log.Printf("⚠️ Erro ao verificar domínio %s: %v", domain.Hostname, err)
+		}
+		cancel()
+	}
+}
+
+// 🔎 Confere o registro do desafio no resolvedor configurado
+func check(ctx context.Context, domain *models.Domain, app *models.App) error {
+	r := currentResolver()
+
+	if domain.Method == models.DomainMethodCNAME {
+		expected := platformHost(app)
+		if expected == "" {
+			return errors.New("ingress desligado: não há destino para o CNAME")
+		}
+		cname, err := r.LookupCNAME(ctx, domain.Hostname)
+		if err != nil {
+			return fmt.Errorf("erro ao consultar CNAME de %s: %w", domain.Hostname, err)
+		}
+		if ingress.CanonicalHost(cname) != expected {
+			return fmt.Errorf("CNAME de %s aponta para %s, esperado %s", domain.Hostname, ingress.CanonicalHost(cname), expected)
+		}
+		return nil
+	}
+
+	name := ChallengeLabel + "." + domain.Hostname
+	records, err := r.LookupTXT(ctx, name)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar TXT de %s: %w", name, err)
+	}
+	want := ChallengePrefix + domain.Token
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("TXT %s não contém o token de verificação", name)
+}
+
+// 🔤 Hostname em minúsculas e validado: nome DNS com ao menos dois rótulos, fora do domínio da plataforma
+func NormalizeHostname(hostname string) (string, error) {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+	if hostname == "" || len(hostname) > 253 {
+		return "", ErrInvalidHostname
+	}
+	if net.ParseIP(hostname) != nil {
+		return "", fmt.Errorf("%w: use um nome, não um IP", ErrInvalidHostname)
+	}
+
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("%w: %s", ErrInvalidHostname, hostname)
+	}
+	for _, label := range labels {
+		if !validLabel(label) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidHostname, hostname)
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidHostname, hostname)
+	}
+
+	if in := ingress.Default(); in != nil {
+		base := in.Config().BaseDomain
+		if hostname == base || strings.HasSuffix(hostname, "."+base) {
+			return "", fmt.Errorf("%w: subdomínios de %s são da plataforma", ErrInvalidHostname, base)
+		}
+	}
+	return hostname, nil
+}
+
+func validLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// 🎯 Endereço da aplicação na plataforma (<id>.<usuário>.<base>); vazio com o ingress desligado
+func platformHost(app *models.App) string {
+	in := ingress.Default()
+	if in == nil {
+		return ""
+	}
+	user, id := ingress.UserLabel(app.Username), ingress.DNSLabel(app.ID)
+	if user == "" || id == "" {
+		return ""
+	}
+	return id + "." + user + "." + in.Config().BaseDomain
+}
+
+func refreshRoutes(appID string) {
+	if in := ingress.Default(); in != nil {
+		in.Refresh(appID)
+	}
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,290 @@
+//backend/domains/domains_test.go
+
+package domains
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/ingress"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/store"
+)
+
+// 🧪 Stores JSON numa pasta temporária, runtime em memória e ingress local (para o CNAME)
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "domains-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	persistence.SetBackend(persistence.NewJSONBackend())
+	engine.SetDefault(engine.NewFake())
+	for _, err := range []error{
+		store.UserStore.Load("./database/users.json"),
+		store.LoadAppStoreFromDisk("./database/appstore.json"),
+		store.LoadDomainStoreFromDisk(store.DefaultDomainStorePath),
+	} {
+		if err != nil {
+			fmt.Println("erro ao carregar stores:", err)
+			return 1
+		}
+	}
+	if _, err := ingress.Start(ingress.Config{Addr: "127.0.0.1:0", BaseDomain: "apps.test", ResyncInterval: time.Hour}); err != nil {
+		fmt.Println("erro ao subir ingress:", err)
+		return 1
+	}
+	return m.Run()
+}
+
+// 🎭 Resolvedor de mentira: respostas por nome, o resto é NXDOMAIN
+type fakeResolver struct {
+	mu    sync.Mutex
+	txt   map[string][]string
+	cname map[string]string
+}
+
+func newFakeResolver(t *testing.T) *fakeResolver {
+	r := &fakeResolver{txt: map[string][]string{}, cname: map[string]string{}}
+	SetResolver(r)
+	t.Cleanup(func() { SetResolver(net.DefaultResolver) })
+	return r
+}
+
+func (r *fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if records, ok := r.txt[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if target, ok := r.cname[host]; ok {
+		return target, nil
+	}
+	return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r *fakeResolver) setTXT(name string, records ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txt[name] = records
+}
+
+func (r *fakeResolver) setCNAME(host, target string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cname[host] = target
+}
+
+func newTestApp(t *testing.T, username, appID string) *models.App {
+	t.Helper()
+	if err := store.UserStore.Save(&models.User{Username: username, Plan: models.PlanPro}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+	app := &models.App{ID: appID, Username: username, ContainerName: username + "-" + appID}
+	if err := store.AppStore.Save(app); err != nil {
+		t.Fatalf("erro ao salvar aplicação: %v", err)
+	}
+	return app
+}
+
+func addDomain(t *testing.T, app *models.App, hostname, method string) (*models.Domain, Challenge) {
+	t.Helper()
+	domain, challenge, err := Add(app.Username, app, hostname, method)
+	if err != nil {
+		t.Fatalf("Add(%s): %v", hostname, err)
+	}
+	return domain, challenge
+}
+
+func verify(t *testing.T, hostname string) *models.Domain {
+	t.Helper()
+	domain, err := Verify(context.Background(), hostname)
+	if err != nil {
+		t.Fatalf("Verify(%s): %v", hostname, err)
+	}
+	return domain
+}
+
+func hasHost(app *models.App, hostname string) bool {
+	for _, host := range HostsForApp(app) {
+		if host == hostname {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVerifyTXTChallenge(t *testing.T) {
+	r := newFakeResolver(t)
+	app := newTestApp(t, "txtuser", "txt1")
+	domain, challenge := addDomain(t, app, "WWW.Example.com.", "")
+
+	if domain.Hostname != "www.example.com" || challenge.Type != "TXT" || challenge.Name != ChallengeLabel+".www.example.com" {
+		t.Fatalf("desafio inesperado: %+v (%s)", challenge, domain.Hostname)
+	}
+	if hasHost(app, domain.Hostname) {
+		t.Fatalf("domínio pendente não deveria ser roteado")
+	}
+
+	r.setTXT(challenge.Name, "outro-registro", " "+challenge.Value+" ")
+	verified := verify(t, domain.Hostname)
+	if verified.Status != models.DomainVerified || verified.VerifiedAt == nil || verified.LastError != "" {
+		t.Fatalf("domínio não verificado: %+v", verified)
+	}
+	if !hasHost(app, domain.Hostname) {
+		t.Fatalf("domínio verificado fora dos hostnames da aplicação")
+	}
+}
+
+func TestVerifyTXTWithWrongToken(t *testing.T) {
+	r := newFakeResolver(t)
+	app := newTestApp(t, "wrongtoken", "wt1")
+	domain, challenge := addDomain(t, app, "wrong.example.com", models.DomainMethodTXT)
+
+	r.setTXT(challenge.Name, ChallengePrefix+"token-de-outra-pessoa")
+	checked := verify(t, domain.Hostname)
+	if checked.Status != models.DomainPending || checked.LastCheck == nil || !strings.Contains(checked.LastError, "não contém o token") {
+		t.Fatalf("domínio deveria continuar pendente com o erro do TXT: %+v", checked)
+	}
+}
+
+func TestVerifyTXTMissingRecord(t *testing.T) {
+	newFakeResolver(t)
+	app := newTestApp(t, "notxt", "nt1")
+	domain, _ := addDomain(t, app, "missing.example.com", models.DomainMethodTXT)
+
+	checked := verify(t, domain.Hostname)
+	if checked.Status != models.DomainPending || !strings.Contains(checked.LastError, "erro ao consultar TXT") {
+		t.Fatalf("domínio deveria continuar pendente com erro de consulta: %+v", checked)
+	}
+}
+
+func TestVerifyCNAMEChallenge(t *testing.T) {
+	r := newFakeResolver(t)
+	app := newTestApp(t, "John.Doe", "cn1")
+	domain, challenge := addDomain(t, app, "app.example.org", models.DomainMethodCNAME)
+
+	want := "cn1." + ingress.UserLabel("John.Doe") + ".apps.test"
+	if challenge.Type != "CNAME" || challenge.Name != "app.example.org" || challenge.Value != want {
+		t.Fatalf("desafio inesperado: %+v, esperado CNAME para %s", challenge, want)
+	}
+
+	r.setCNAME("app.example.org", "somewhere.else.test.")
+	if checked := verify(t, domain.Hostname); checked.Status != models.DomainPending || !strings.Contains(checked.LastError, "aponta para somewhere.else.test") {
+		t.Fatalf("CNAME errado deveria manter o domínio pendente: %+v", checked)
+	}
+
+	// 🔤 O resolvedor devolve o nome absoluto (com ponto final) e pode variar as maiúsculas
+	r.setCNAME("app.example.org", strings.ToUpper(want)+".")
+	if verified := verify(t, domain.Hostname); verified.Status != models.DomainVerified {
+		t.Fatalf("CNAME correto não verificou o domínio: %+v", verified)
+	}
+}
+
+func TestVerifyKeepsVerifiedDomainVerified(t *testing.T) {
+	r := newFakeResolver(t)
+	app := newTestApp(t, "stable", "st1")
+	domain, challenge := addDomain(t, app, "stable.example.com", models.DomainMethodTXT)
+	r.setTXT(challenge.Name, challenge.Value)
+	first := verify(t, domain.Hostname)
+
+	// 🧹 O registro pode sair do DNS depois da verificação: o erro fica anotado, o status não volta
+	r.setTXT(challenge.Name)
+	again := verify(t, domain.Hostname)
+	if again.Status != models.DomainVerified || !again.VerifiedAt.Equal(*first.VerifiedAt) || again.LastError == "" {
+		t.Fatalf("domínio verificado alterado pela nova consulta: %+v", again)
+	}
+}
+
+func TestAddRejectsTakenHostname(t *testing.T) {
+	newFakeResolver(t)
+	first := newTestApp(t, "first", "f1")
+	second := newTestApp(t, "second", "s1")
+	addDomain(t, first, "taken.example.com", models.DomainMethodTXT)
+
+	if _, _, err := Add(second.Username, second, "Taken.Example.com", models.DomainMethodTXT); !errors.Is(err, ErrHostnameTaken) {
+		t.Fatalf("erro = %v, esperado ErrHostnameTaken", err)
+	}
+}
+
+func TestVerifyPendingExpiresOldChallenges(t *testing.T) {
+	r := newFakeResolver(t)
+	app := newTestApp(t, "expire", "ex1")
+	old, oldChallenge := addDomain(t, app, "old.example.com", models.DomainMethodTXT)
+	fresh, freshChallenge := addDomain(t, app, "fresh.example.com", models.DomainMethodTXT)
+	if _, err := store.DomainStore.Update(old.Hostname, func(d *models.Domain) {
+		d.CreatedAt = time.Now().Add(-PendingTTL - time.Hour)
+	}); err != nil {
+		t.Fatalf("erro ao envelhecer domínio: %v", err)
+	}
+	r.setTXT(oldChallenge.Name, oldChallenge.Value)
+	r.setTXT(freshChallenge.Name, freshChallenge.Value)
+
+	verifyPending()
+
+	if d, _ := store.DomainStore.Get(old.Hostname); d.Status != models.DomainFailed {
+		t.Fatalf("desafio vencido = %s, esperado failed", d.Status)
+	}
+	if d, _ := store.DomainStore.Get(fresh.Hostname); d.Status != models.DomainVerified {
+		t.Fatalf("desafio no prazo = %s, esperado verified", d.Status)
+	}
+}
+
+func TestResolverFromEnvQueriesConfiguredServer(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao escutar UDP: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("DOMAINS_DNS_SERVER", conn.LocalAddr().String())
+
+	queried := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 512)
+		if _, _, err := conn.ReadFrom(buf); err == nil {
+			queried <- struct{}{}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_, _ = ResolverFromEnv().LookupTXT(ctx, ChallengeLabel+".example.com")
+
+	select {
+	case <-queried:
+	case <-time.After(time.Second):
+		t.Fatalf("consulta não chegou ao servidor de DOMAINS_DNS_SERVER")
+	}
+}
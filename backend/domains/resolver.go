@@ -0,0 +1,62 @@
+//backend/domains/resolver.go
+
+package domains
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 🔎 Consultas DNS usadas na verificação de posse (net.Resolver já satisfaz a interface)
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+var (
+	resolverMu sync.RWMutex
+	resolver   Resolver = net.DefaultResolver
+)
+
+// 🔌 Troca o resolvedor das próximas verificações (ex.: DNS local em testes)
+func SetResolver(r Resolver) {
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+	resolver = r
+}
+
+func currentResolver() Resolver {
+	resolverMu.RLock()
+	defer resolverMu.RUnlock()
+	return resolver
+}
+
+// 🔎 Resolvedor definido por DOMAINS_DNS_SERVER (host:porta); vazio = resolvedor do sistema
+func ResolverFromEnv() Resolver {
+	server := os.Getenv("DOMAINS_DNS_SERVER")
+	if server == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: 5 * time.Second}
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// ⏱️ Intervalo da verificação automática dos pendentes (DOMAINS_VERIFY_SECONDS, padrão 300)
+func VerifyIntervalFromEnv() time.Duration {
+	if seconds, err := strconv.Atoi(os.Getenv("DOMAINS_VERIFY_SECONDS")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 5 * time.Minute
+}
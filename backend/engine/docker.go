@@ -0,0 +1,481 @@
+//backend/engine/docker.go
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/docker"
+)
+
+// 🐳 Runtime sobre a Docker Engine API
+type DockerRuntime struct {
+	client *docker.Client
+	name   string
+}
+
+// 🏗️ Runtime Docker sobre o cliente informado (nil = docker.Default())
+func NewDocker(client *docker.Client) *DockerRuntime {
+	if client == nil {
+		client = docker.Default()
+	}
+	return &DockerRuntime{client: client, name: "docker"}
+}
+
+// ⚙️ Runtime Docker configurado por DOCKER_HOST
+func NewDockerFromEnv() (*DockerRuntime, error) {
+	client, err := docker.NewClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewDocker(client), nil
+}
+
+// 🐳 Cliente da Engine API por trás do runtime
+func (d *DockerRuntime) Client() *docker.Client {
+	return d.client
+}
+
+func (d *DockerRuntime) Name() string {
+	return d.name
+}
+
+func (d *DockerRuntime) Ping(ctx context.Context) error {
+	return wrapDockerError(d.client.Ping(ctx))
+}
+
+func (d *DockerRuntime) Build(ctx context.Context, contextDir, tag string) (string, error) {
+	out, err := d.client.ImageBuild(ctx, contextDir, tag)
+	return out, wrapDockerError(err)
+}
+
+func (d *DockerRuntime) Pull(ctx context.Context, ref string) error {
+	return wrapDockerError(d.client.ImagePull(ctx, ref))
+}
+
+func (d *DockerRuntime) Images(ctx context.Context) ([]string, error) {
+	images, err := d.client.ImageList(ctx)
+	if err != nil {
+		return nil, wrapDockerError(err)
+	}
+	var repos []string
+	for _, image := range images {
+		repos = append(repos, image.Repositories()...)
+	}
+	return repos, nil
+}
+
+func (d *DockerRuntime) ImageExists(ctx context.Context, ref string) (bool, error) {
+	exists, err := d.client.ImageExists(ctx, ref)
+	return exists, wrapDockerError(err)
+}
+
+func (d *DockerRuntime) ImageID(ctx context.Context, ref string) (string, error) {
+	image, err := d.client.ImageInspect(ctx, ref)
+	if err != nil {
+		return "", wrapDockerError(err)
+	}
+	return image.ID, nil
+}
+
+func (d *DockerRuntime) ImageRemove(ctx context.Context, ref string) error {
+	return wrapDockerError(d.client.ImageRemove(ctx, ref, true))
+}
+
+func (d *DockerRuntime) Create(ctx context.Context, spec Spec) (string, error) {
+	config := &docker.Config{
+		Image:      spec.Image,
+		Cmd:        spec.Cmd,
+		WorkingDir: spec.WorkingDir,
+		Env:        spec.Env,
+		Labels:     spec.Labels,
+	}
+	restart := spec.RestartPolicy
+	if restart == "" {
+		restart = "no" // 🛡️ reinício controlado pelo backend
+	}
+	hostConfig := &docker.HostConfig{
+		Resources:     dockerResources(spec.Resources),
+		Binds:         spec.Binds,
+		RestartPolicy: docker.RestartPolicy{Name: restart},
+		NetworkMode:   spec.NetworkMode,
+	}
+
+	var networking *docker.NetworkingConfig
+	if len(spec.NetworkAliases) > 0 && spec.NetworkMode != "" {
+		networking = &docker.NetworkingConfig{EndpointsConfig: map[string]docker.EndpointSettings{
+			spec.NetworkMode: {Aliases: spec.NetworkAliases},
+		}}
+	}
+
+	created, err := d.client.ContainerCreate(ctx, spec.Name, config, hostConfig, networking)
+	if err != nil {
+		return "", wrapDockerError(err)
+	}
+	return created.ID, nil
+}
+
+func (d *DockerRuntime) Start(ctx context.Context, name string) error {
+	return wrapDockerError(d.client.ContainerStart(ctx, name))
+}
+
+func (d *DockerRuntime) Stop(ctx context.Context, name string, timeout time.Duration) error {
+	return wrapDockerError(d.client.ContainerStop(ctx, name, timeout))
+}
+
+func (d *DockerRuntime) Restart(ctx context.Context, name string, timeout time.Duration) error {
+	return wrapDockerError(d.client.ContainerRestart(ctx, name, timeout))
+}
+
+func (d *DockerRuntime) Remove(ctx context.Context, name string, force bool) error {
+	return wrapDockerError(d.client.ContainerRemove(ctx, name, docker.RemoveOptions{Force: force}))
+}
+
+func (d *DockerRuntime) Update(ctx context.Context, name string, update Update) error {
+	var body docker.UpdateConfig
+	if update.Resources != nil {
+		body.Resources = dockerResources(*update.Resources)
+		body.Ulimits = nil // a Engine API não altera ulimits em /update
+	}
+	if update.RestartPolicy != "" {
+		body.RestartPolicy = &docker.RestartPolicy{Name: update.RestartPolicy}
+	}
+	return wrapDockerError(d.client.ContainerUpdate(ctx, name, body))
+}
+
+func (d *DockerRuntime) Inspect(ctx context.Context, name string) (*Info, error) {
+	raw, err := d.client.ContainerInspect(ctx, name)
+	if err != nil {
+		return nil, wrapDockerError(err)
+	}
+
+	info := &Info{
+		ID:    raw.ID,
+		Name:  strings.TrimPrefix(raw.Name, "/"),
+		Image: raw.Image,
+	}
+	if s := raw.State; s != nil {
+		info.State = s.Status
+		info.Running = s.Running
+		info.Paused = s.Paused
+		info.ExitCode = s.ExitCode
+		info.OOMKilled = s.OOMKilled
+		info.Error = s.Error
+		info.StartedAt = s.StartedAt
+		info.FinishedAt = s.FinishedAt
+	}
+	if c := raw.Config; c != nil {
+		info.Labels = c.Labels
+		info.Env = c.Env
+		info.Tty = c.Tty
+		info.ExposedPorts = exposedTCPPorts(c.ExposedPorts)
+	}
+	if n := raw.NetworkSettings; n != nil && len(n.Networks) > 0 {
+		info.Networks = make(map[string]string, len(n.Networks))
+		for network, endpoint := range n.Networks {
+			info.Networks[network] = endpoint.IPAddress
+		}
+	}
+	if h := raw.HostConfig; h != nil {
+		info.Resources = Resources{
+			MemoryMB:    int(h.Memory / (1024 * 1024)),
+			CPUs:        float64(h.NanoCPUs) / 1e9,
+			PidsLimit:   h.PidsLimit,
+			BlkioWeight: h.BlkioWeight,
+		}
+		for _, u := range h.Ulimits {
+			if u.Name == "nofile" && u.Soft > 0 {
+				info.Resources.NoFile = uint64(u.Soft)
+			}
+		}
+	}
+	info.RestartCount = raw.RestartCount
+	info.Spec = Spec{
+		Name:      info.Name,
+		Env:       info.Env,
+		Labels:    info.Labels,
+		Resources: info.Resources,
+	}
+	if c := raw.Config; c != nil {
+		info.Spec.Image = c.Image
+		info.Spec.Cmd = c.Cmd
+		info.Spec.WorkingDir = c.WorkingDir
+	}
+	if h := raw.HostConfig; h != nil {
+		info.Spec.Binds = h.Binds
+		info.Spec.RestartPolicy = h.RestartPolicy.Name
+		info.Spec.NetworkMode = h.NetworkMode
+	}
+	return info, nil
+}
+
+func (d *DockerRuntime) List(ctx context.Context, opts ListOptions) ([]Summary, error) {
+	var filters map[string][]string
+	for key, value := range opts.Labels {
+		if filters == nil {
+			filters = map[string][]string{}
+		}
+		if value != "" {
+			key += "=" + value
+		}
+		filters["label"] = append(filters["label"], key)
+	}
+
+	containers, err := d.client.ContainerList(ctx, docker.ListOptions{All: opts.All, Filters: filters})
+	if err != nil {
+		return nil, wrapDockerError(err)
+	}
+
+	summaries := make([]Summary, 0, len(containers))
+	for _, c := range containers {
+		summaries = append(summaries, Summary{
+			ID:      c.ID,
+			Name:    c.Name(),
+			Image:   c.Image,
+			State:   c.State,
+			Status:  c.Status,
+			Labels:  c.Labels,
+			Created: time.Unix(c.Created, 0),
+		})
+	}
+	return summaries, nil
+}
+
+func (d *DockerRuntime) Stats(ctx context.Context, name string) (*Stats, error) {
+	raw, err := d.client.ContainerStats(ctx, name)
+	if err != nil {
+		return nil, wrapDockerError(err)
+	}
+	return &Stats{
+		CPUPercent:    raw.CPUPercent(),
+		MemoryUsageMB: docker.BytesToMB(raw.MemoryUsage()),
+		MemoryLimitMB: docker.BytesToMB(raw.MemoryStats.Limit),
+	}, nil
+}
+
+func (d *DockerRuntime) Logs(ctx context.Context, name string, opts LogsOptions, stdout, stderr io.Writer) error {
+	info, err := d.client.ContainerInspect(ctx, name)
+	if err != nil {
+		return wrapDockerError(err)
+	}
+
+	stream, err := d.client.ContainerLogs(ctx, name, docker.LogsOptions{
+		Stdout:     opts.Stdout,
+		Stderr:     opts.Stderr,
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+		Tail:       opts.Tail,
+		Since:      opts.Since,
+	})
+	if err != nil {
+		return wrapDockerError(err)
+	}
+	defer stream.Close()
+
+	// Com TTY o daemon não multiplexa: tudo chega como stdout
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(stdout, stream)
+	} else {
+		err = docker.DemuxLogs(stdout, stderr, stream)
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil // follow encerrado pelo chamador
+	}
+	return err
+}
+
+func (d *DockerRuntime) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	raw, rawErrs := d.client.Events(ctx, map[string][]string{"type": {"container"}})
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		for e := range raw {
+			ev := Event{
+				Action:     e.Action,
+				ID:         e.Actor.ID,
+				Name:       e.Actor.Attributes["name"],
+				Attributes: e.Actor.Attributes,
+			}
+			if e.TimeNano != 0 {
+				ev.Time = time.Unix(0, e.TimeNano)
+			} else {
+				ev.Time = time.Unix(e.Time, 0)
+			}
+			// "exec_start: sh -c ..." → "exec_start"
+			if i := strings.Index(ev.Action, ":"); i > 0 {
+				ev.Action = ev.Action[:i]
+			}
+
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := <-rawErrs; err != nil {
+			errs <- wrapDockerError(err)
+		}
+	}()
+
+	return events, errs
+}
+
+func (d *DockerRuntime) CopyFrom(ctx context.Context, name, srcPath, destDir string) error {
+	return wrapDockerError(d.client.CopyFromContainer(ctx, name, srcPath, destDir))
+}
+
+func (d *DockerRuntime) Exec(ctx context.Context, name string, cmd []string, stdout, stderr io.Writer) (int, error) {
+	code, err := d.client.ExecRun(ctx, name, cmd, stdout, stderr)
+	return code, wrapDockerError(err)
+}
+
+func (d *DockerRuntime) ExecStream(ctx context.Context, name string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	code, err := d.client.ExecStream(ctx, name, cmd, stdin, stdout, stderr)
+	return code, wrapDockerError(err)
+}
+
+func (d *DockerRuntime) ExecTTY(ctx context.Context, name string, opts TTYOptions) (TTY, error) {
+	id, err := d.client.ExecCreate(ctx, name, docker.ExecConfig{
+		Cmd:          opts.Cmd,
+		Env:          opts.Env,
+		User:         opts.User,
+		WorkingDir:   opts.WorkingDir,
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, wrapDockerError(err)
+	}
+	conn, err := d.client.ExecAttach(ctx, id, true)
+	if err != nil {
+		return nil, wrapDockerError(err)
+	}
+	t := &dockerTTY{ReadWriteCloser: conn, client: d.client, id: id}
+	if opts.Rows > 0 && opts.Cols > 0 {
+		// O TTY só existe depois do start: o tamanho inicial vai logo em seguida
+		_ = t.Resize(ctx, opts.Rows, opts.Cols)
+	}
+	return t, nil
+}
+
+// 🖥️ Exec interativo sobre a conexão com upgrade da Engine API
+type dockerTTY struct {
+	io.ReadWriteCloser
+	client *docker.Client
+	id     string
+}
+
+func (t *dockerTTY) Resize(ctx context.Context, rows, cols int) error {
+	return wrapDockerError(t.client.ExecResize(ctx, t.id, rows, cols))
+}
+
+func (t *dockerTTY) ExitCode(ctx context.Context) (int, error) {
+	for {
+		info, err := t.client.ExecInspect(ctx, t.id)
+		if err != nil {
+			return 0, wrapDockerError(err)
+		}
+		// A saída pode terminar um instante antes de a Engine registrar o fim do processo
+		if !info.Running {
+			return info.ExitCode, nil
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+func (d *DockerRuntime) EnsureNetwork(ctx context.Context, name string, labels, options map[string]string) error {
+	_, err := d.client.NetworkInspect(ctx, name)
+	if err == nil {
+		return nil
+	}
+	if !docker.IsNotFound(err) {
+		return wrapDockerError(err)
+	}
+	_, err = d.client.NetworkCreate(ctx, docker.NetworkCreate{
+		Name:           name,
+		Driver:         "bridge",
+		Labels:         labels,
+		Options:        options,
+		CheckDuplicate: true,
+	})
+	if docker.IsConflict(err) {
+		return nil // criada em paralelo
+	}
+	return wrapDockerError(err)
+}
+
+func (d *DockerRuntime) RemoveNetwork(ctx context.Context, name string) error {
+	return wrapDockerError(d.client.NetworkRemove(ctx, name))
+}
+
+func (d *DockerRuntime) Connect(ctx context.Context, network, container string, aliases []string) error {
+	raw, err := d.client.ContainerInspect(ctx, container)
+	if err != nil {
+		return wrapDockerError(err)
+	}
+	if raw.NetworkSettings != nil {
+		if _, ok := raw.NetworkSettings.Networks[network]; ok {
+			return nil
+		}
+	}
+	return wrapDockerError(d.client.NetworkConnect(ctx, network, container, aliases))
+}
+
+func (d *DockerRuntime) Disconnect(ctx context.Context, network, container string) error {
+	return wrapDockerError(d.client.NetworkDisconnect(ctx, network, container, true))
+}
+
+// 🔌 {"8080/tcp": {}} → [8080], em ordem crescente
+func exposedTCPPorts(exposed map[string]struct{}) []int {
+	var ports []int
+	for spec := range exposed {
+		port, proto, _ := strings.Cut(spec, "/")
+		if proto != "" && proto != "tcp" {
+			continue
+		}
+		if n, err := strconv.Atoi(port); err == nil {
+			ports = append(ports, n)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// 📏 Limites neutros → HostConfig da Engine API
+func dockerResources(r Resources) docker.Resources {
+	res := docker.Resources{
+		Memory:      docker.MBToBytes(r.MemoryMB),
+		MemorySwap:  docker.MBToBytes(r.MemoryMB),
+		NanoCPUs:    int64(r.CPUs * 1e9),
+		PidsLimit:   r.PidsLimit,
+		BlkioWeight: r.BlkioWeight,
+	}
+	if r.NoFile > 0 {
+		res.Ulimits = []docker.Ulimit{{Name: "nofile", Soft: int64(r.NoFile), Hard: int64(r.NoFile)}}
+	}
+	return res
+}
+
+// ❓ Traduz 404 da API para ErrNotFound, preservando a mensagem original
+func wrapDockerError(err error) error {
+	if err != nil && docker.IsNotFound(err) {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,1056 @@
+//backend/engine/fake.go
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🧪 Runtime em memória, determinístico, para testes sem daemon.
+// IDs são sequenciais e o relógio é lógico (avança um segundo por operação),
+// então duas execuções com as mesmas chamadas produzem o mesmo estado.
+type FakeRuntime struct {
+	mu         sync.Mutex
+	tick       int64
+	clock      func() time.Time
+	images     map[string]string // repositório (sem tag) → ID da imagem
+	imagePorts map[string][]int  // repositório → portas do EXPOSE
+	containers map[string]*fakeContainer
+	networks   map[string]*fakeNetwork
+	buildErrs  map[string]error
+	pingErr    error
+	execFns    map[string]FakeExecFunc   // container → comando simulado
+	ttyFns     map[string]FakeTTYFunc    // container → processo interativo simulado
+	streamFns  map[string]FakeStreamFunc // container ou imagem → comando com entrada em fluxo simulado
+	subs       map[int]chan Event
+	nextSub    int
+	changed    chan struct{} // fechado e trocado a cada mutação (acorda Logs com Follow)
+}
+
+type fakeContainer struct {
+	info    Info
+	spec    Spec
+	created time.Time
+	logs    []fakeLogLine
+	stats   Stats
+}
+
+type fakeNetwork struct {
+	id      string
+	index   int // define a sub-rede: bridge = 172.17.0.0/16, demais = 10.<index>.0.0/16
+	labels  map[string]string
+	options map[string]string
+	members map[string]string // nome do container → IP
+	aliases map[string][]string
+	nextIP  int
+}
+
+type fakeLogLine struct {
+	time   time.Time
+	stderr bool
+	text   string
+}
+
+// 🧪 Comando simulado pelo fake: escreve a saída e devolve o código de saída
+type FakeExecFunc func(cmd []string, stdout, stderr io.Writer) int
+
+// 🖥️ Processo interativo simulado: lê o teclado de stdin até EOF e devolve o código de saída
+type FakeTTYFunc func(cmd []string, stdin io.Reader, stdout io.Writer) int
+
+// 🔀 Comando com entrada em fluxo simulado (ExecStream): lê stdin, escreve as saídas e devolve o código de saída
+type FakeStreamFunc func(cmd []string, stdin io.Reader, stdout, stderr io.Writer) int
+
+// ⏰ Início do relógio lógico
+var fakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// 🏗️ Runtime fake vazio
+func NewFake() *FakeRuntime {
+	return &FakeRuntime{
+		images:     map[string]string{},
+		imagePorts: map[string][]int{},
+		containers: map[string]*fakeContainer{},
+		networks: map[string]*fakeNetwork{
+			"bridge": {id: fmt.Sprintf("%064x", 0), members: map[string]string{}, aliases: map[string][]string{}},
+		},
+		buildErrs: map[string]error{},
+		execFns:   map[string]FakeExecFunc{},
+		ttyFns:    map[string]FakeTTYFunc{},
+		streamFns: map[string]FakeStreamFunc{},
+		subs:      map[int]chan Event{},
+		changed:   make(chan struct{}),
+	}
+}
+
+// ⏰ Substitui o relógio lógico (ex.: time.Now em testes de integração)
+func (f *FakeRuntime) SetClock(clock func() time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clock = clock
+}
+
+// 🩺 Faz o Ping falhar (nil volta ao normal)
+func (f *FakeRuntime) SetPingError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pingErr = err
+}
+
+// 💥 Faz o próximo build da tag falhar (nil remove a falha)
+func (f *FakeRuntime) FailBuild(tag string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.buildErrs, imageRepo(tag))
+		return
+	}
+	f.buildErrs[imageRepo(tag)] = err
+}
+
+// 🖼️ Registra uma imagem como se tivesse sido baixada
+func (f *FakeRuntime) AddImage(ref string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.images[imageRepo(ref)] = f.newID("sha256:")
+}
+
+// 🔌 Define as portas do EXPOSE de uma imagem já registrada
+func (f *FakeRuntime) ExposePorts(ref string, ports ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.imagePorts[imageRepo(ref)] = append([]int(nil), ports...)
+}
+
+// 📝 Acrescenta uma linha aos logs do container
+func (f *FakeRuntime) WriteLog(name string, stderr bool, line string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	c.logs = append(c.logs, fakeLogLine{time: f.now(), stderr: stderr, text: strings.TrimSuffix(line, "\n")})
+	f.notify()
+	return nil
+}
+
+// 📊 Define a próxima amostra de métricas do container
+func (f *FakeRuntime) SetStats(name string, stats Stats) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	c.stats = stats
+	return nil
+}
+
+// 💥 Simula a morte do processo principal (exit code e OOM), emitindo oom/die
+func (f *FakeRuntime) Crash(name string, exitCode int, oomKilled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	if !c.info.Running {
+		return fmt.Errorf("container %s não está em execução", name)
+	}
+	if oomKilled {
+		f.emit(c, "oom", nil)
+	}
+	f.halt(c, exitCode, oomKilled)
+	return nil
+}
+
+// 🧪 Define o resultado dos Exec no container (nil = sucesso sem saída)
+func (f *FakeRuntime) SetExec(name string, fn FakeExecFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fn == nil {
+		delete(f.execFns, name)
+		return
+	}
+	f.execFns[name] = fn
+}
+
+// 🖥️ Define o processo dos ExecTTY no container (nil = eco da entrada até EOF)
+func (f *FakeRuntime) SetTTY(name string, fn FakeTTYFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fn == nil {
+		delete(f.ttyFns, name)
+		return
+	}
+	f.ttyFns[name] = fn
+}
+
+// 🔀 Define o comando dos ExecStream no container; name também pode ser a imagem
+// (containers auxiliares têm nome gerado). nil = repassa ao comando de SetExec
+func (f *FakeRuntime) SetStream(name string, fn FakeStreamFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fn == nil {
+		delete(f.streamFns, name)
+		return
+	}
+	f.streamFns[name] = fn
+}
+
+// ⚙️ Opções com que a rede foi criada (nil se ela não existe)
+func (f *FakeRuntime) NetworkOptions(name string) map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	network, ok := f.networks[name]
+	if !ok {
+		return nil
+	}
+	return copyLabels(network.options)
+}
+
+// 🔤 IPs que o DNS da rede devolveria para o nome (container ou alias), como o DNS embutido do Docker
+func (f *FakeRuntime) Resolve(networkName, name string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	network, ok := f.networks[networkName]
+	if !ok || networkName == "bridge" {
+		return nil // a bridge padrão não tem DNS
+	}
+	var ips []string
+	for container, ip := range network.members {
+		match := container == name
+		for _, alias := range network.aliases[container] {
+			match = match || alias == name
+		}
+		if match {
+			ips = append(ips, ip)
+		}
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+func (f *FakeRuntime) Name() string {
+	return "fake"
+}
+
+func (f *FakeRuntime) Ping(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pingErr
+}
+
+func (f *FakeRuntime) Build(ctx context.Context, contextDir, tag string) (string, error) {
+	if _, err := os.Stat(filepath.Join(contextDir, "Dockerfile")); err != nil {
+		return "", fmt.Errorf("build falhou: Dockerfile não encontrado em %s", contextDir)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo := imageRepo(tag)
+	if err, ok := f.buildErrs[repo]; ok {
+		return "Step 1/1 : FROM scratch\n", fmt.Errorf("build falhou: %w", err)
+	}
+	id := f.newID("sha256:")
+	f.images[repo] = id
+	f.imagePorts[repo] = dockerfileExposedPorts(filepath.Join(contextDir, "Dockerfile"))
+	return fmt.Sprintf("Step 1/1 : FROM scratch\nSuccessfully built %s\nSuccessfully tagged %s\n", shortID(id), tag), nil
+}
+
+func (f *FakeRuntime) Pull(ctx context.Context, ref string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.images[imageRepo(ref)]; !ok {
+		f.images[imageRepo(ref)] = f.newID("sha256:")
+	}
+	return nil
+}
+
+func (f *FakeRuntime) Images(ctx context.Context) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repos := make([]string, 0, len(f.images))
+	for repo := range f.images {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+func (f *FakeRuntime) ImageExists(ctx context.Context, ref string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.findImage(ref)
+	return ok, nil
+}
+
+func (f *FakeRuntime) ImageID(ctx context.Context, ref string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo, ok := f.findImage(ref)
+	if !ok {
+		return "", fmt.Errorf("%w: imagem %s", ErrNotFound, ref)
+	}
+	return f.images[repo], nil
+}
+
+func (f *FakeRuntime) ImageRemove(ctx context.Context, ref string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo, ok := f.findImage(ref)
+	if !ok {
+		return fmt.Errorf("%w: imagem %s", ErrNotFound, ref)
+	}
+	delete(f.images, repo)
+	return nil
+}
+
+func (f *FakeRuntime) Create(ctx context.Context, spec Spec) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if spec.Name == "" {
+		spec.Name = "fake_" + strconv.FormatInt(f.tick+1, 10)
+	}
+	if _, exists := f.containers[spec.Name]; exists {
+		return "", fmt.Errorf("nome de container já em uso: %s", spec.Name)
+	}
+	imageID, ok := f.images[imageRepo(spec.Image)]
+	if !ok {
+		return "", fmt.Errorf("%w: imagem %s", ErrNotFound, spec.Image)
+	}
+	if spec.RestartPolicy == "" {
+		spec.RestartPolicy = "no"
+	}
+	networkName := spec.NetworkMode
+	switch networkName {
+	case "", "default":
+		networkName = "bridge"
+	case "none", "host":
+		networkName = ""
+	}
+	var network *fakeNetwork
+	if networkName != "" {
+		if network = f.networks[networkName]; network == nil {
+			return "", fmt.Errorf("%w: rede %s", ErrNotFound, networkName)
+		}
+	}
+
+	c := &fakeContainer{
+		spec:    spec,
+		created: f.now(),
+		info: Info{
+			ID:        f.newID(""),
+			Name:      spec.Name,
+			Image:     imageID,
+			State:     "created",
+			Labels:    copyLabels(spec.Labels),
+			Env:       append([]string(nil), spec.Env...),
+			Resources: spec.Resources,
+		},
+	}
+	c.info.ExposedPorts = append([]int(nil), f.imagePorts[imageRepo(spec.Image)]...)
+	c.stats.MemoryLimitMB = float64(spec.Resources.MemoryMB)
+	f.containers[spec.Name] = c
+	if network != nil {
+		f.join(network, spec.Name, spec.NetworkAliases)
+	}
+	f.emit(c, "create", nil)
+	return c.info.ID, nil
+}
+
+func (f *FakeRuntime) Start(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	f.start(c)
+	return nil
+}
+
+func (f *FakeRuntime) Stop(ctx context.Context, name string, timeout time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	if c.info.Running {
+		f.emit(c, "kill", map[string]string{"signal": "15"})
+		f.halt(c, 0, false)
+		f.emit(c, "stop", nil)
+	}
+	return nil
+}
+
+func (f *FakeRuntime) Restart(ctx context.Context, name string, timeout time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	if c.info.Running {
+		f.halt(c, 0, false)
+		f.emit(c, "stop", nil)
+	}
+	f.start(c)
+	f.emit(c, "restart", nil)
+	return nil
+}
+
+func (f *FakeRuntime) Remove(ctx context.Context, name string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	if c.info.Running {
+		if !force {
+			return fmt.Errorf("container %s em execução: pare-o antes ou use force", name)
+		}
+		f.emit(c, "kill", map[string]string{"signal": "9"})
+		f.halt(c, 137, false)
+	}
+	delete(f.containers, c.info.Name)
+	for _, network := range f.networks {
+		delete(network.members, c.info.Name)
+		delete(network.aliases, c.info.Name)
+	}
+	f.emit(c, "destroy", nil)
+	return nil
+}
+
+func (f *FakeRuntime) Update(ctx context.Context, name string, update Update) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return err
+	}
+	if update.Resources != nil {
+		// Como no Docker: ulimits ficam os da criação
+		resources := *update.Resources
+		resources.NoFile = c.spec.Resources.NoFile
+		c.spec.Resources = resources
+		c.info.Resources = resources
+		c.stats.MemoryLimitMB = float64(resources.MemoryMB)
+	}
+	if update.RestartPolicy != "" {
+		c.spec.RestartPolicy = update.RestartPolicy
+	}
+	f.emit(c, "update", nil)
+	return nil
+}
+
+func (f *FakeRuntime) Inspect(ctx context.Context, name string) (*Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	info := c.info
+	info.Labels = copyLabels(c.info.Labels)
+	info.Env = append([]string(nil), c.info.Env...)
+	info.Spec = c.spec
+	info.Spec.Cmd = append([]string(nil), c.spec.Cmd...)
+	info.Spec.Env = append([]string(nil), c.spec.Env...)
+	info.Spec.Labels = copyLabels(c.spec.Labels)
+	info.Spec.Binds = append([]string(nil), c.spec.Binds...)
+	info.Spec.NetworkAliases = append([]string(nil), c.spec.NetworkAliases...)
+	info.ExposedPorts = append([]int(nil), c.info.ExposedPorts...)
+	for name, network := range f.networks {
+		ip, ok := network.members[c.info.Name]
+		if !ok {
+			continue
+		}
+		if info.Networks == nil {
+			info.Networks = map[string]string{}
+		}
+		if !c.info.Running {
+			ip = "" // como no Docker: sem IP enquanto parado
+		}
+		info.Networks[name] = ip
+	}
+	return &info, nil
+}
+
+func (f *FakeRuntime) List(ctx context.Context, opts ListOptions) ([]Summary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := make([]string, 0, len(f.containers))
+	for name := range f.containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var summaries []Summary
+	for _, name := range names {
+		c := f.containers[name]
+		if !opts.All && !c.info.Running {
+			continue
+		}
+		if !matchLabels(c.info.Labels, opts.Labels) {
+			continue
+		}
+		summaries = append(summaries, Summary{
+			ID:      c.info.ID,
+			Name:    c.info.Name,
+			Image:   c.spec.Image,
+			State:   c.info.State,
+			Status:  f.statusText(c),
+			Labels:  copyLabels(c.info.Labels),
+			Created: c.created,
+		})
+	}
+	return summaries, nil
+}
+
+func (f *FakeRuntime) Stats(ctx context.Context, name string) (*Stats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	stats := c.stats
+	if !c.info.Running {
+		stats.CPUPercent, stats.MemoryUsageMB = 0, 0
+	}
+	return &stats, nil
+}
+
+func (f *FakeRuntime) Logs(ctx context.Context, name string, opts LogsOptions, stdout, stderr io.Writer) error {
+	f.mu.Lock()
+	c, err := f.lookup(name)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	lines := selectLogLines(c.logs, opts)
+	sent := len(c.logs)
+	f.mu.Unlock()
+
+	for {
+		for _, line := range lines {
+			dst := stdout
+			if line.stderr {
+				dst = stderr
+			}
+			text := line.text + "\n"
+			if opts.Timestamps {
+				text = line.time.UTC().Format(time.RFC3339Nano) + " " + text
+			}
+			if _, err := io.WriteString(dst, text); err != nil {
+				return err
+			}
+		}
+		if !opts.Follow {
+			return nil
+		}
+
+		// 🔁 Follow: espera novas linhas até o container parar ou ctx terminar
+		f.mu.Lock()
+		changed := f.changed
+		current, ok := f.containers[c.info.Name]
+		if !ok || current != c || (!c.info.Running && sent == len(c.logs)) {
+			f.mu.Unlock()
+			return nil
+		}
+		if sent < len(c.logs) {
+			lines = filterLogStreams(c.logs[sent:], opts)
+			sent = len(c.logs)
+			f.mu.Unlock()
+			continue
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-changed:
+			lines = nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (f *FakeRuntime) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	sub := make(chan Event, 256)
+	errs := make(chan error, 1)
+
+	f.mu.Lock()
+	id := f.nextSub
+	f.nextSub++
+	f.subs[id] = sub
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		delete(f.subs, id)
+		close(sub)
+		close(errs)
+		f.mu.Unlock()
+	}()
+	return sub, errs
+}
+
+func (f *FakeRuntime) CopyFrom(ctx context.Context, name, srcPath, destDir string) error {
+	f.mu.Lock()
+	c, err := f.lookup(name)
+	var binds []string
+	if err == nil {
+		binds = append(binds, c.spec.Binds...)
+	}
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Só caminhos montados a partir do host têm conteúdo real no fake
+	srcPath = filepath.ToSlash(filepath.Clean(srcPath))
+	for _, bind := range binds {
+		hostPath, target := splitBind(bind)
+		if srcPath != target && !strings.HasPrefix(srcPath, target+"/") {
+			continue
+		}
+		source := filepath.Join(hostPath, filepath.FromSlash(strings.TrimPrefix(srcPath, target)))
+		return copyTree(source, filepath.Join(destDir, filepath.Base(srcPath)))
+	}
+	return fmt.Errorf("%w: caminho %s em %s", ErrNotFound, srcPath, name)
+}
+
+func (f *FakeRuntime) Exec(ctx context.Context, name string, cmd []string, stdout, stderr io.Writer) (int, error) {
+	f.mu.Lock()
+	c, err := f.lookup(name)
+	if err != nil {
+		f.mu.Unlock()
+		return 0, err
+	}
+	if !c.info.Running {
+		f.mu.Unlock()
+		return 0, fmt.Errorf("container %s não está em execução", name)
+	}
+	fn := f.execFns[c.info.Name]
+	f.mu.Unlock()
+
+	if fn == nil {
+		return 0, nil
+	}
+	return fn(cmd, stdout, stderr), nil
+}
+
+func (f *FakeRuntime) ExecStream(ctx context.Context, name string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	f.mu.Lock()
+	c, err := f.lookup(name)
+	if err != nil {
+		f.mu.Unlock()
+		return 0, err
+	}
+	if !c.info.Running {
+		f.mu.Unlock()
+		return 0, fmt.Errorf("container %s não está em execução", name)
+	}
+	fn, ok := f.streamFns[c.info.Name]
+	if !ok {
+		fn = f.streamFns[c.spec.Image]
+	}
+	execFn := f.execFns[c.info.Name]
+	f.mu.Unlock()
+
+	switch {
+	case fn != nil:
+		return fn(cmd, stdin, stdout, stderr), nil
+	case execFn != nil:
+		return execFn(cmd, stdout, stderr), nil
+	}
+	return 0, nil
+}
+
+func (f *FakeRuntime) ExecTTY(ctx context.Context, name string, opts TTYOptions) (TTY, error) {
+	f.mu.Lock()
+	c, err := f.lookup(name)
+	if err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+	if !c.info.Running {
+		f.mu.Unlock()
+		return nil, fmt.Errorf("container %s não está em execução", name)
+	}
+	fn := f.ttyFns[c.info.Name]
+	f.mu.Unlock()
+
+	if fn == nil {
+		fn = func(cmd []string, stdin io.Reader, stdout io.Writer) int {
+			_, _ = io.Copy(stdout, stdin)
+			return 0
+		}
+	}
+	t := &fakeTTY{done: make(chan struct{})}
+	t.inR, t.inW = io.Pipe()
+	t.outR, t.outW = io.Pipe()
+	go func() {
+		t.code = fn(opts.Cmd, t.inR, t.outW)
+		t.inR.Close()
+		t.outW.Close()
+		close(t.done)
+	}()
+	return t, nil
+}
+
+// 🖥️ Sessão do fake sobre pipes em memória
+type fakeTTY struct {
+	inR  *io.PipeReader
+	inW  *io.PipeWriter
+	outR *io.PipeReader
+	outW *io.PipeWriter
+	done chan struct{}
+	code int
+}
+
+func (t *fakeTTY) Read(p []byte) (int, error)  { return t.outR.Read(p) }
+func (t *fakeTTY) Write(p []byte) (int, error) { return t.inW.Write(p) }
+
+func (t *fakeTTY) Close() error {
+	t.inW.Close()
+	t.outR.Close()
+	return nil
+}
+
+func (t *fakeTTY) Resize(ctx context.Context, rows, cols int) error {
+	return nil
+}
+
+func (t *fakeTTY) ExitCode(ctx context.Context) (int, error) {
+	select {
+	case <-t.done:
+		return t.code, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (f *FakeRuntime) EnsureNetwork(ctx context.Context, name string, labels, options map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.networks[name]; ok {
+		return nil
+	}
+	f.networks[name] = &fakeNetwork{
+		id:      f.newID(""),
+		index:   len(f.networks),
+		labels:  copyLabels(labels),
+		options: copyLabels(options),
+		members: map[string]string{},
+		aliases: map[string][]string{},
+	}
+	return nil
+}
+
+func (f *FakeRuntime) RemoveNetwork(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	network, ok := f.networks[name]
+	if !ok || name == "bridge" {
+		return fmt.Errorf("%w: rede %s", ErrNotFound, name)
+	}
+	if len(network.members) > 0 {
+		return fmt.Errorf("rede %s ainda tem %d container(s) conectado(s)", name, len(network.members))
+	}
+	delete(f.networks, name)
+	return nil
+}
+
+func (f *FakeRuntime) Connect(ctx context.Context, networkName, container string, aliases []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(container)
+	if err != nil {
+		return err
+	}
+	network, ok := f.networks[networkName]
+	if !ok {
+		return fmt.Errorf("%w: rede %s", ErrNotFound, networkName)
+	}
+	if _, connected := network.members[c.info.Name]; !connected {
+		f.join(network, c.info.Name, aliases)
+	}
+	return nil
+}
+
+func (f *FakeRuntime) Disconnect(ctx context.Context, networkName, container string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(container)
+	if err != nil {
+		return err
+	}
+	network, ok := f.networks[networkName]
+	if !ok {
+		return fmt.Errorf("%w: rede %s", ErrNotFound, networkName)
+	}
+	delete(network.members, c.info.Name)
+	delete(network.aliases, c.info.Name)
+	return nil
+}
+
+// ── internos (chamados com f.mu travado) ──
+
+func (f *FakeRuntime) join(network *fakeNetwork, container string, aliases []string) {
+	network.nextIP++
+	if network.index == 0 {
+		network.members[container] = fmt.Sprintf("172.17.0.%d", network.nextIP+1)
+	} else {
+		network.members[container] = fmt.Sprintf("10.%d.0.%d", network.index, network.nextIP+1)
+	}
+	network.aliases[container] = append([]string(nil), aliases...)
+}
+
+func (f *FakeRuntime) now() time.Time {
+	if f.clock != nil {
+		return f.clock()
+	}
+	f.tick++
+	return fakeEpoch.Add(time.Duration(f.tick) * time.Second)
+}
+
+func (f *FakeRuntime) newID(prefix string) string {
+	f.tick++
+	return prefix + fmt.Sprintf("%064x", f.tick)
+}
+
+func (f *FakeRuntime) lookup(name string) (*fakeContainer, error) {
+	if c, ok := f.containers[name]; ok {
+		return c, nil
+	}
+	for _, c := range f.containers {
+		if name != "" && strings.HasPrefix(c.info.ID, name) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: container %s", ErrNotFound, name)
+}
+
+func (f *FakeRuntime) findImage(ref string) (string, bool) {
+	if _, ok := f.images[imageRepo(ref)]; ok {
+		return imageRepo(ref), true
+	}
+	for repo, id := range f.images {
+		if ref == id || (len(ref) >= 12 && strings.HasPrefix(strings.TrimPrefix(id, "sha256:"), strings.TrimPrefix(ref, "sha256:"))) {
+			return repo, true
+		}
+	}
+	return "", false
+}
+
+func (f *FakeRuntime) start(c *fakeContainer) {
+	if c.info.Running {
+		return
+	}
+	c.info.Running = true
+	c.info.State = "running"
+	c.info.ExitCode = 0
+	c.info.OOMKilled = false
+	c.info.StartedAt = f.now()
+	c.info.FinishedAt = time.Time{}
+	f.emit(c, "start", nil)
+}
+
+func (f *FakeRuntime) halt(c *fakeContainer, exitCode int, oomKilled bool) {
+	c.info.Running = false
+	c.info.State = "exited"
+	c.info.ExitCode = exitCode
+	c.info.OOMKilled = oomKilled
+	c.info.FinishedAt = f.now()
+	f.emit(c, "die", map[string]string{"exitCode": strconv.Itoa(exitCode)})
+}
+
+func (f *FakeRuntime) statusText(c *fakeContainer) string {
+	switch c.info.State {
+	case "running":
+		return "Up"
+	case "exited":
+		return fmt.Sprintf("Exited (%d)", c.info.ExitCode)
+	default:
+		return "Created"
+	}
+}
+
+func (f *FakeRuntime) emit(c *fakeContainer, action string, extra map[string]string) {
+	attrs := copyLabels(c.info.Labels)
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	attrs["name"] = c.info.Name
+	attrs["image"] = c.spec.Image
+	for k, v := range extra {
+		attrs[k] = v
+	}
+
+	ev := Event{Time: f.now(), Action: action, ID: c.info.ID, Name: c.info.Name, Attributes: attrs}
+	ids := make([]int, 0, len(f.subs))
+	for id := range f.subs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		select {
+		case f.subs[id] <- ev:
+		default: // assinante lento perde o evento, como num stream real desconectado
+		}
+	}
+	f.notify()
+}
+
+func (f *FakeRuntime) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// ── utilitários ──
+
+// 🔌 Portas das instruções EXPOSE do Dockerfile (só TCP)
+func dockerfileExposedPorts(path string) []int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var ports []int
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "EXPOSE") {
+			continue
+		}
+		for _, spec := range fields[1:] {
+			port, proto, _ := strings.Cut(spec, "/")
+			if proto != "" && proto != "tcp" {
+				continue
+			}
+			if n, err := strconv.Atoi(port); err == nil {
+				ports = append(ports, n)
+			}
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// 🏷️ "nome:tag" → "nome" (mantém portas de registry como host:5000/nome)
+func imageRepo(ref string) string {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+	return ref
+}
+
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
+
+func matchLabels(labels, want map[string]string) bool {
+	for key, value := range want {
+		got, ok := labels[key]
+		if !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	return true
+}
+
+func selectLogLines(lines []fakeLogLine, opts LogsOptions) []fakeLogLine {
+	var selected []fakeLogLine
+	for _, line := range filterLogStreams(lines, opts) {
+		if !opts.Since.IsZero() && line.time.Before(opts.Since) {
+			continue
+		}
+		selected = append(selected, line)
+	}
+	if opts.Tail != "" && opts.Tail != "all" {
+		if n, err := strconv.Atoi(opts.Tail); err == nil && n >= 0 && n < len(selected) {
+			selected = selected[len(selected)-n:]
+		}
+	}
+	return selected
+}
+
+func filterLogStreams(lines []fakeLogLine, opts LogsOptions) []fakeLogLine {
+	var out []fakeLogLine
+	for _, line := range lines {
+		if (line.stderr && opts.Stderr) || (!line.stderr && opts.Stdout) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// 📎 "origem:destino[:opções]" → origem, destino
+func splitBind(bind string) (string, string) {
+	parts := strings.Split(bind, ":")
+	// Caminhos Windows (C:\...) têm ":" na origem
+	if len(parts) >= 3 && len(parts[0]) == 1 {
+		parts = append([]string{parts[0] + ":" + parts[1]}, parts[2:]...)
+	}
+	if len(parts) < 2 {
+		return bind, bind
+	}
+	return parts[0], filepath.ToSlash(filepath.Clean(parts[1]))
+}
+
+// 📂 Copia arquivos e diretórios de src para dst (links são ignorados, como no extractTar)
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, info.Mode().Perm())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+//backend/engine/podman.go
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"virtuscloud/backend/docker"
+)
+
+// 🦭 Runtime sobre o Podman rootless, pela API compatível com Docker do `podman system service`.
+// O ciclo de vida é o mesmo do DockerRuntime; muda a descoberta do socket e a
+// forma como o Podman nomeia imagens construídas localmente.
+type PodmanRuntime struct {
+	*DockerRuntime
+}
+
+// 🏗️ Runtime Podman no host informado (unix:///caminho ou tcp://host:porta)
+func NewPodman(host string) (*PodmanRuntime, error) {
+	client, err := docker.NewClient(host)
+	if err != nil {
+		return nil, err
+	}
+	return &PodmanRuntime{DockerRuntime: &DockerRuntime{client: client, name: "podman"}}, nil
+}
+
+// ⚙️ Runtime Podman configurado por CONTAINER_HOST (padrão: socket rootless do usuário atual)
+func NewPodmanFromEnv() (*PodmanRuntime, error) {
+	host := os.Getenv("CONTAINER_HOST")
+	if host == "" {
+		host = "unix://" + rootlessSocket()
+	}
+	return NewPodman(host)
+}
+
+// 🔌 $XDG_RUNTIME_DIR/podman/podman.sock, ou /run/user/<uid>/podman/podman.sock
+func rootlessSocket() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	return filepath.Join(dir, "podman", "podman.sock")
+}
+
+// 🖼️ O Podman registra builds locais como localhost/<nome>; devolve o nome curto, como o Docker
+func (p *PodmanRuntime) Images(ctx context.Context) ([]string, error) {
+	repos, err := p.DockerRuntime.Images(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, repo := range repos {
+		repos[i] = strings.TrimPrefix(repo, "localhost/")
+	}
+	return repos, nil
+}
@@ -0,0 +1,260 @@
+//backend/engine/runtime.go
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ⏱️ Prazo padrão das chamadas curtas (inspect, start, stop, list...)
+const DefaultTimeout = 30 * time.Second
+
+// 🔨 Builds transmitem o contexto e baixam camadas: precisam de mais tempo
+const BuildTimeout = 10 * time.Minute
+
+// ❓ Container ou imagem inexistente (comparar com errors.Is ou IsNotFound)
+var ErrNotFound = errors.New("recurso não encontrado no runtime")
+
+// 🔍 Indica se o erro significa container/imagem inexistente
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// 🧱 Ciclo de vida de containers, independente do motor (Docker, Podman, fake)
+type ContainerRuntime interface {
+	// 🏷️ Nome do runtime ("docker", "podman", "fake")
+	Name() string
+	// 🩺 Verifica se o daemon responde
+	Ping(ctx context.Context) error
+
+	// 🔨 Constrói a imagem tag a partir do diretório (Dockerfile na raiz) e devolve a saída do build
+	Build(ctx context.Context, contextDir, tag string) (string, error)
+	// 🖼️ Repositórios (sem tag) das imagens locais
+	Images(ctx context.Context) ([]string, error)
+	ImageExists(ctx context.Context, ref string) (bool, error)
+	// 🆔 ID (sha256:...) da imagem; ErrNotFound se não existir
+	ImageID(ctx context.Context, ref string) (string, error)
+	ImageRemove(ctx context.Context, ref string) error
+	// ⬇️ Baixa a imagem do registro (como `docker pull`)
+	Pull(ctx context.Context, ref string) error
+
+	// 🆕 Cria o container (sem iniciá-lo) e devolve o ID
+	Create(ctx context.Context, spec Spec) (string, error)
+	Start(ctx context.Context, name string) error
+	Stop(ctx context.Context, name string, timeout time.Duration) error
+	Restart(ctx context.Context, name string, timeout time.Duration) error
+	Remove(ctx context.Context, name string, force bool) error
+	Update(ctx context.Context, name string, update Update) error
+
+	Inspect(ctx context.Context, name string) (*Info, error)
+	List(ctx context.Context, opts ListOptions) ([]Summary, error)
+	Stats(ctx context.Context, name string) (*Stats, error)
+	// 📄 Escreve os logs já separados em stdout/stderr; com Follow bloqueia até ctx terminar
+	Logs(ctx context.Context, name string, opts LogsOptions, stdout, stderr io.Writer) error
+	// 📡 Eventos de containers até ctx ser cancelado (o canal de erros recebe no máximo um valor)
+	Events(ctx context.Context) (<-chan Event, <-chan error)
+	// 📦 Copia srcPath do container para destDir (como `docker cp container:src destDir`)
+	CopyFrom(ctx context.Context, name, srcPath, destDir string) error
+	// 🧪 Executa cmd dentro do container em execução até o fim e devolve o código de saída
+	Exec(ctx context.Context, name string, cmd []string, stdout, stderr io.Writer) (int, error)
+	// 🔀 Como Exec, sem TTY e com stdin em fluxo até o processo sair (rsync, tar)
+	ExecStream(ctx context.Context, name string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	// 🖥️ Abre um processo interativo com TTY no container em execução (terminal web)
+	ExecTTY(ctx context.Context, name string, opts TTYOptions) (TTY, error)
+
+	// 🕸️ Cria a rede bridge se ainda não existir (já existente = sucesso, as opções ficam as da criação)
+	EnsureNetwork(ctx context.Context, name string, labels, options map[string]string) error
+	RemoveNetwork(ctx context.Context, name string) error
+	// 🔗 Conecta o container à rede com aliases de DNS (já conectado = sucesso)
+	Connect(ctx context.Context, network, container string, aliases []string) error
+	Disconnect(ctx context.Context, network, container string) error
+}
+
+// 📏 Limites de recursos aplicados ao container (zero = sem limite)
+type Resources struct {
+	MemoryMB    int     // swap fica igual à memória (sem swap extra)
+	CPUs        float64 // núcleos, como --cpus (0.5 = meio núcleo)
+	PidsLimit   int64   // processos/threads simultâneos
+	NoFile      uint64  // ulimit nofile (soft = hard); só vale na criação do container
+	BlkioWeight uint16  // peso relativo de I/O de disco (10–1000)
+}
+
+// 🆕 Especificação de criação
+type Spec struct {
+	Name          string
+	Image         string
+	Cmd           []string
+	WorkingDir    string
+	Env           []string
+	Labels        map[string]string
+	Binds         []string // "origem:destino[:opções]"
+	Resources     Resources
+	RestartPolicy string // "no" (padrão), "always", "unless-stopped", "on-failure"
+	NetworkMode   string
+	// 🔤 Nomes de DNS do container na rede NetworkMode (só redes criadas pelo usuário têm DNS)
+	NetworkAliases []string
+}
+
+// ✏️ Alterações em container existente (campos nulos/vazios ficam como estão).
+// Resources.NoFile é ignorado: ulimits só mudam recriando o container.
+type Update struct {
+	Resources     *Resources
+	RestartPolicy string
+}
+
+// 🔎 Opções de listagem
+type ListOptions struct {
+	All    bool              // inclui parados
+	Labels map[string]string // valor vazio = apenas presença do label
+}
+
+// 📋 Item da listagem
+type Summary struct {
+	ID      string
+	Name    string
+	Image   string
+	State   string // running, exited, created, paused, restarting, dead
+	Status  string // texto humano: "Up 5 minutes", "Exited (0) ..."
+	Labels  map[string]string
+	Created time.Time
+}
+
+// 🔍 Detalhes do container
+type Info struct {
+	ID           string
+	Name         string
+	Image        string // ID da imagem
+	State        string
+	Running      bool
+	Paused       bool
+	ExitCode     int
+	OOMKilled    bool
+	Error        string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	RestartCount int
+	Labels       map[string]string
+	Env          []string
+	Tty          bool
+	Resources    Resources
+	ExposedPorts []int             // portas TCP declaradas na imagem (EXPOSE)
+	Networks     map[string]string // rede → IP do container nela
+	Spec         Spec              // parâmetros de criação atuais (base para recriar o container)
+}
+
+// 🖥️ Processo interativo a abrir com ExecTTY
+type TTYOptions struct {
+	Cmd        []string
+	Env        []string
+	User       string
+	WorkingDir string
+	Rows, Cols int // tamanho inicial (zero = padrão do runtime)
+}
+
+// 🖥️ Sessão interativa: Read = saída do terminal, Write = teclado; Close encerra a conexão
+// (o processo recebe EOF/SIGHUP). A saída termina quando o processo sai.
+type TTY interface {
+	io.ReadWriteCloser
+	Resize(ctx context.Context, rows, cols int) error
+	// 🏁 Código de saída do processo (consultar depois que a saída terminou)
+	ExitCode(ctx context.Context) (int, error)
+}
+
+// 📊 Amostra de métricas
+type Stats struct {
+	CPUPercent    float64 // relativo a um núcleo (100% = 1 núcleo inteiro), como no `docker stats`
+	MemoryUsageMB float64
+	MemoryLimitMB float64
+}
+
+// 📄 Opções de leitura de logs
+type LogsOptions struct {
+	Stdout     bool
+	Stderr     bool
+	Follow     bool
+	Timestamps bool
+	Tail       string    // "all" ou número de linhas
+	Since      time.Time // zero = desde o início
+}
+
+// 📡 Evento de container (start, stop, die, oom, destroy...)
+type Event struct {
+	Time       time.Time
+	Action     string
+	ID         string
+	Name       string
+	Attributes map[string]string // inclui exitCode em "die"
+}
+
+// 🔍 Indica se existe um container com exatamente esse nome ou ID
+func Exists(ctx context.Context, rt ContainerRuntime, name string) (bool, error) {
+	_, err := rt.Inspect(ctx, name)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ⏱️ Contexto com o prazo padrão das chamadas curtas
+func Timeout() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), DefaultTimeout)
+}
+
+// ⚙️ Runtime configurado por CONTAINER_RUNTIME (docker | podman | fake; padrão docker)
+func FromEnv() (ContainerRuntime, error) {
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv("CONTAINER_RUNTIME"))); name {
+	case "", "docker":
+		return NewDockerFromEnv()
+	case "podman":
+		return NewPodmanFromEnv()
+	case "fake":
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("CONTAINER_RUNTIME desconhecido: %s", name)
+	}
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultRuntime ContainerRuntime
+)
+
+// 🔧 Define o runtime usado pelo backend (main ou testes com NewFake)
+func SetDefault(rt ContainerRuntime) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRuntime = rt
+}
+
+// 🧱 Runtime compartilhado; sem SetDefault, é escolhido por CONTAINER_RUNTIME no primeiro uso
+func Default() ContainerRuntime {
+	defaultMu.RLock()
+	rt := defaultRuntime
+	defaultMu.RUnlock()
+	if rt != nil {
+		return rt
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultRuntime == nil {
+		var err error
+		if defaultRuntime, err = FromEnv(); err != nil {
+			// Configuração inválida: cai no Docker padrão e deixa as chamadas falharem
+			log.Println("⚠️ Runtime de containers inválido, usando Docker:", err)
+			defaultRuntime = NewDocker(nil)
+		}
+	}
+	return defaultRuntime
+}
@@ -0,0 +1,171 @@
+//backend/files/files.go
+
+package files
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🗂️ Gerenciador de arquivos das aplicações. Duas raízes:
+//   - "source": o código da aplicação, guardado no snapshot .zip que o rebuild usa
+//     (a pasta extraída some depois do deploy; alterar o snapshot e reconstruir é o que persiste)
+//   - "volume:<nome>": um volume persistente, editado direto no diretório do host
+
+// 🏷️ Raízes
+const (
+	RootSource   = "source"
+	volumePrefix = "volume:"
+)
+
+// 🏷️ Tipos de entrada
+const (
+	TypeFile    = "file"
+	TypeDir     = "dir"
+	TypeSymlink = "symlink"
+)
+
+// ❗ Erros (o handler escolhe o status HTTP com errors.Is)
+var (
+	ErrInvalid    = errors.New("caminho ou operação inválida")
+	ErrNotFound   = errors.New("arquivo não encontrado")
+	ErrExists     = errors.New("arquivo já existe")
+	ErrTooLarge   = errors.New("arquivo maior que o limite do plano")
+	ErrNotAllowed = errors.New("operação não permitida")
+)
+
+// 📄 Entrada de diretório
+type Entry struct {
+	Name    string     `json:"name"`
+	Path    string     `json:"path"`
+	Type    string     `json:"type"`
+	Size    int64      `json:"size"`
+	Mode    string     `json:"mode,omitempty"`
+	ModTime *time.Time `json:"modTime,omitempty"`
+}
+
+// 🗂️ Operações sobre uma raiz. Caminhos são relativos à raiz, com "/" ("" = a própria raiz).
+type Root interface {
+	Name() string
+	List(dir string) ([]Entry, error)
+	Stat(p string) (*Entry, error)
+	// 📖 Conteúdo do arquivo; o chamador fecha
+	Open(p string) (io.ReadCloser, *Entry, error)
+	// ✍️ Cria ou substitui o arquivo com até max bytes de r
+	Write(p string, r io.Reader, max int64) (*Entry, error)
+	Mkdir(p string) error
+	Rename(from, to string) error
+	// 🗑️ Remove o arquivo ou o diretório inteiro
+	Delete(p string) error
+}
+
+// 🗂️ Raiz disponível para a aplicação
+type RootInfo struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"` // source ou volume
+	MountPath string `json:"mountPath"`
+	Available bool   `json:"available"`
+	Apply     string `json:"apply"` // o que faz a alteração valer: rebuild ou restart
+}
+
+// 📁 storage/users/<usuário>/<plano>/snapshots/<app>.zip (o mesmo usado por RebuildApp)
+func snapshotPath(app *models.App) string {
+	return filepath.Join("storage", "users", app.Username, app.Plan, "snapshots", app.ID+".zip")
+}
+
+// 🗂️ Raízes da aplicação: o código e cada volume
+func Roots(app *models.App) []RootInfo {
+	_, err := os.Stat(snapshotPath(app))
+	roots := []RootInfo{{Name: RootSource, Kind: "source", MountPath: "/app", Available: err == nil, Apply: "rebuild"}}
+	for _, v := range store.VolumeStore.ListByApp(app.ID) {
+		roots = append(roots, RootInfo{Name: volumePrefix + v.Name, Kind: "volume", MountPath: v.MountPath, Available: true, Apply: "restart"})
+	}
+	return roots
+}
+
+// 🔓 Abre a raiz pedida da aplicação
+func Open(app *models.App, root string) (Root, error) {
+	if root == "" || root == RootSource {
+		p := snapshotPath(app)
+		if _, err := os.Stat(p); err != nil {
+			return nil, fmt.Errorf("%w: a aplicação não tem snapshot do código (gere um backup para habilitar a edição)", ErrNotFound)
+		}
+		return &sourceRoot{path: p}, nil
+	}
+	name, ok := strings.CutPrefix(root, volumePrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: raiz '%s' (use source ou volume:<nome>)", ErrInvalid, root)
+	}
+	volume, found := store.VolumeStore.Get(app.ID + "/" + name)
+	if !found {
+		return nil, fmt.Errorf("%w: volume %s", ErrNotFound, name)
+	}
+	return &volumeRoot{volume: volume}, nil
+}
+
+// 📏 Maior arquivo que o usuário pode abrir ou gravar pelo gerenciador
+func MaxFileBytes(username string) (int64, error) {
+	mb, err := limits.FileManagerMaxMB(username)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNotAllowed, err)
+	}
+	return int64(mb) * 1024 * 1024, nil
+}
+
+// 🧭 Normaliza o caminho relativo à raiz; ".." e caracteres de controle são recusados (não apenas limpos)
+func CleanPath(p string) (string, error) {
+	p = strings.ReplaceAll(p, "\\", "/")
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: '..' não é permitido", ErrInvalid)
+		}
+	}
+	if strings.ContainsAny(p, "\x00\r\n") {
+		return "", fmt.Errorf("%w: caracteres de controle no caminho", ErrInvalid)
+	}
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if len(p) > 1024 {
+		return "", fmt.Errorf("%w: caminho longo demais", ErrInvalid)
+	}
+	return p, nil
+}
+
+// 🔁 Verifica se p é o próprio dir ou está dentro dele
+func within(p, dir string) bool {
+	return dir == "" || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// ✂️ Lê até max bytes; passar do limite é ErrTooLarge
+func limitedReader(r io.Reader, max int64) io.Reader {
+	return &limitReader{r: r, left: max}
+}
+
+type limitReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
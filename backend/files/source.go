@@ -0,0 +1,397 @@
+//backend/files/source.go
+
+package files
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🔒 Um escritor por snapshot: cada alteração regrava o .zip inteiro
+var (
+	snapshotLocksMu sync.Mutex
+	snapshotLocks   = map[string]*sync.Mutex{}
+)
+
+func snapshotLock(p string) *sync.Mutex {
+	snapshotLocksMu.Lock()
+	defer snapshotLocksMu.Unlock()
+	mu, ok := snapshotLocks[p]
+	if !ok {
+		mu = &sync.Mutex{}
+		snapshotLocks[p] = mu
+	}
+	return mu
+}
+
+// 📦 Código da aplicação dentro do snapshot .zip
+type sourceRoot struct {
+	path string
+}
+
+// 📄 Entrada do zip com o caminho já normalizado
+type zipEntry struct {
+	name string // sem "/" final
+	dir  bool
+	file *zip.File
+}
+
+func (s *sourceRoot) Name() string { return RootSource }
+
+// 📋 Entradas válidas do zip (nomes que não passam em CleanPath são ignorados, como na extração)
+func readEntries(zr *zip.Reader) []zipEntry {
+	entries := make([]zipEntry, 0, len(zr.File))
+	for _, f := range zr.File {
+		name, err := CleanPath(f.Name)
+		if err != nil || name == "" || strings.HasPrefix(f.Name, "/") {
+			continue
+		}
+		entries = append(entries, zipEntry{name: name, dir: strings.HasSuffix(f.Name, "/") || f.FileInfo().IsDir(), file: f})
+	}
+	return entries
+}
+
+func (s *sourceRoot) open() (*zip.ReadCloser, []zipEntry, error) {
+	zr, err := zip.OpenReader(s.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao abrir snapshot: %w", err)
+	}
+	return zr, readEntries(&zr.Reader), nil
+}
+
+func fileEntry(e zipEntry) Entry {
+	modified := e.file.Modified
+	entry := Entry{Name: path.Base(e.name), Path: e.name, Type: TypeFile, ModTime: &modified}
+	mode := e.file.Mode()
+	switch {
+	case e.dir:
+		entry.Type = TypeDir
+	case mode&os.ModeSymlink != 0:
+		entry.Type = TypeSymlink
+	default:
+		entry.Size = int64(e.file.UncompressedSize64)
+	}
+	entry.Mode = mode.Perm().String()
+	return entry
+}
+
+// 🔍 Procura p entre as entradas; diretórios podem existir só implicitamente (prefixo de outros caminhos)
+func lookup(entries []zipEntry, p string) (*Entry, bool) {
+	if p == "" {
+		return &Entry{Name: "", Path: "", Type: TypeDir}, true
+	}
+	implicit := false
+	for _, e := range entries {
+		if e.name == p {
+			entry := fileEntry(e)
+			return &entry, true
+		}
+		if strings.HasPrefix(e.name, p+"/") {
+			implicit = true
+		}
+	}
+	if implicit {
+		return &Entry{Name: path.Base(p), Path: p, Type: TypeDir}, true
+	}
+	return nil, false
+}
+
+func (s *sourceRoot) List(dir string) ([]Entry, error) {
+	zr, entries, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	if e, ok := lookup(entries, dir); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, dir)
+	} else if e.Type != TypeDir {
+		return nil, fmt.Errorf("%w: %s não é um diretório", ErrInvalid, dir)
+	}
+
+	children := map[string]Entry{}
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	for _, e := range entries {
+		rest, ok := strings.CutPrefix(e.name, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		if child, _, nested := strings.Cut(rest, "/"); nested {
+			if _, seen := children[child]; !seen {
+				children[child] = Entry{Name: child, Path: prefix + child, Type: TypeDir}
+			}
+		} else {
+			children[child] = fileEntry(e)
+		}
+	}
+	return sortEntries(children), nil
+}
+
+// 🔤 Diretórios primeiro, depois por nome
+func sortEntries(m map[string]Entry) []Entry {
+	list := make([]Entry, 0, len(m))
+	for _, e := range m {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Type == TypeDir) != (list[j].Type == TypeDir) {
+			return list[i].Type == TypeDir
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+func (s *sourceRoot) Stat(p string) (*Entry, error) {
+	zr, entries, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	e, ok := lookup(entries, p)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, p)
+	}
+	return e, nil
+}
+
+func (s *sourceRoot) Open(p string) (io.ReadCloser, *Entry, error) {
+	zr, entries, err := s.open()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		if e.name != p {
+			continue
+		}
+		entry := fileEntry(e)
+		if entry.Type != TypeFile {
+			zr.Close()
+			return nil, nil, fmt.Errorf("%w: %s não é um arquivo", ErrInvalid, p)
+		}
+		rc, err := e.file.Open()
+		if err != nil {
+			zr.Close()
+			return nil, nil, fmt.Errorf("erro ao ler %s do snapshot: %w", p, err)
+		}
+		return &zipFileReader{ReadCloser: rc, zr: zr}, &entry, nil
+	}
+	zr.Close()
+	return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, p)
+}
+
+// 📖 Fecha o arquivo e o snapshot juntos
+type zipFileReader struct {
+	io.ReadCloser
+	zr *zip.ReadCloser
+}
+
+func (z *zipFileReader) Close() error {
+	err := z.ReadCloser.Close()
+	if cerr := z.zr.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// 🔁 Regrava o snapshot: keep decide o destino de cada entrada existente ("" = descartar), extra grava as novas.
+// O novo .zip é escrito ao lado e só então substitui o antigo, então um rebuild concorrente nunca lê um arquivo pela metade.
+func (s *sourceRoot) rewrite(check func(entries []zipEntry) error, keep func(name string) string, extra func(w *zip.Writer) error) error {
+	mu := snapshotLock(s.path)
+	mu.Lock()
+	defer mu.Unlock()
+
+	zr, entries, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	if err := check(entries); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".files-*.zip")
+	if err != nil {
+		return fmt.Errorf("erro ao criar snapshot temporário: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := zip.NewWriter(tmp)
+	for _, e := range entries {
+		name := keep(e.name)
+		if name == "" {
+			continue
+		}
+		if name == e.name {
+			err = zw.Copy(e.file)
+		} else {
+			err = copyRenamed(zw, e, name)
+		}
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("erro ao copiar %s no snapshot: %w", e.name, err)
+		}
+	}
+	if err := extra(zw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao finalizar snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao finalizar snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("erro ao substituir snapshot: %w", err)
+	}
+	return nil
+}
+
+// ✏️ Copia a entrada com outro nome sem descomprimir
+func copyRenamed(zw *zip.Writer, e zipEntry, name string) error {
+	header := e.file.FileHeader
+	header.Name = name
+	if e.dir {
+		header.Name += "/"
+	}
+	raw, err := e.file.OpenRaw()
+	if err != nil {
+		return err
+	}
+	w, err := zw.CreateRaw(&header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, raw)
+	return err
+}
+
+// 🧱 O pai de p não pode ser um arquivo
+func checkParent(entries []zipEntry, p string) error {
+	for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+		for _, e := range entries {
+			if e.name == dir && !e.dir {
+				return fmt.Errorf("%w: %s é um arquivo", ErrInvalid, dir)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *sourceRoot) Write(p string, r io.Reader, max int64) (*Entry, error) {
+	if p == "" {
+		return nil, fmt.Errorf("%w: caminho vazio", ErrInvalid)
+	}
+	mode := os.FileMode(0o644)
+	var written int64
+	now := time.Now()
+
+	err := s.rewrite(func(entries []zipEntry) error {
+		if e, ok := lookup(entries, p); ok {
+			if e.Type != TypeFile {
+				return fmt.Errorf("%w: %s não é um arquivo", ErrInvalid, p)
+			}
+			for _, z := range entries {
+				if z.name == p {
+					mode = z.file.Mode().Perm()
+				}
+			}
+		}
+		return checkParent(entries, p)
+	}, func(name string) string {
+		if name == p {
+			return ""
+		}
+		return name
+	}, func(zw *zip.Writer) error {
+		header := &zip.FileHeader{Name: p, Method: zip.Deflate, Modified: now}
+		header.SetMode(mode)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("erro ao gravar %s no snapshot: %w", p, err)
+		}
+		written, err = io.Copy(w, limitedReader(r, max))
+		if err != nil {
+			if errors.Is(err, ErrTooLarge) {
+				return err
+			}
+			return fmt.Errorf("erro ao gravar %s no snapshot: %w", p, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{Name: path.Base(p), Path: p, Type: TypeFile, Size: written, Mode: mode.String(), ModTime: &now}, nil
+}
+
+func (s *sourceRoot) Mkdir(p string) error {
+	if p == "" {
+		return fmt.Errorf("%w: caminho vazio", ErrInvalid)
+	}
+	return s.rewrite(func(entries []zipEntry) error {
+		if _, ok := lookup(entries, p); ok {
+			return fmt.Errorf("%w: %s", ErrExists, p)
+		}
+		return checkParent(entries, p)
+	}, func(name string) string { return name }, func(zw *zip.Writer) error {
+		header := &zip.FileHeader{Name: p + "/", Modified: time.Now()}
+		header.SetMode(os.ModeDir | 0o755)
+		_, err := zw.CreateHeader(header)
+		return err
+	})
+}
+
+func (s *sourceRoot) Rename(from, to string) error {
+	if from == "" || to == "" {
+		return fmt.Errorf("%w: não é possível renomear a raiz", ErrInvalid)
+	}
+	if within(to, from) {
+		return fmt.Errorf("%w: destino dentro da origem", ErrInvalid)
+	}
+	return s.rewrite(func(entries []zipEntry) error {
+		if _, ok := lookup(entries, from); !ok {
+			return fmt.Errorf("%w: %s", ErrNotFound, from)
+		}
+		if _, ok := lookup(entries, to); ok {
+			return fmt.Errorf("%w: %s", ErrExists, to)
+		}
+		return checkParent(entries, to)
+	}, func(name string) string {
+		if within(name, from) {
+			return to + strings.TrimPrefix(name, from)
+		}
+		return name
+	}, func(*zip.Writer) error { return nil })
+}
+
+func (s *sourceRoot) Delete(p string) error {
+	if p == "" {
+		return fmt.Errorf("%w: não é possível apagar a raiz", ErrInvalid)
+	}
+	return s.rewrite(func(entries []zipEntry) error {
+		if _, ok := lookup(entries, p); !ok {
+			return fmt.Errorf("%w: %s", ErrNotFound, p)
+		}
+		return nil
+	}, func(name string) string {
+		if within(name, p) {
+			return ""
+		}
+		return name
+	}, func(*zip.Writer) error { return nil })
+}
@@ -0,0 +1,201 @@
+//backend/files/source_test.go
+
+package files
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 📦 Snapshot com index.js, src/app.js e src/lib/util.js (este executável)
+func newTestSnapshot(t *testing.T) *sourceRoot {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "app.zip")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatalf("erro ao criar snapshot: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	for _, file := range []struct {
+		name string
+		mode os.FileMode
+	}{{"index.js", 0o644}, {"src/app.js", 0o644}, {"src/lib/util.js", 0o755}} {
+		header := &zip.FileHeader{Name: file.name, Method: zip.Deflate}
+		header.SetMode(file.mode)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("erro ao escrever snapshot: %v", err)
+		}
+		_, _ = w.Write([]byte("// " + file.name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("erro ao fechar snapshot: %v", err)
+	}
+	f.Close()
+	return &sourceRoot{path: p}
+}
+
+func names(entries []Entry) string {
+	var parts []string
+	for _, e := range entries {
+		parts = append(parts, e.Name+":"+e.Type)
+	}
+	return strings.Join(parts, ",")
+}
+
+func readSource(t *testing.T, s *sourceRoot, p string) string {
+	t.Helper()
+	rc, _, err := s.Open(p)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", p, err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	return string(data)
+}
+
+func TestSourceRootBrowse(t *testing.T) {
+	s := newTestSnapshot(t)
+
+	root, err := s.List("")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	// Diretórios implícitos (sem entrada própria no zip) aparecem, e antes dos arquivos
+	if got := names(root); got != "src:dir,index.js:file" {
+		t.Fatalf("raiz = %q", got)
+	}
+	if got, _ := s.List("src"); names(got) != "lib:dir,app.js:file" {
+		t.Fatalf("src = %q", names(got))
+	}
+	if _, err := s.List("index.js"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("List de arquivo: %v", err)
+	}
+	if _, err := s.Stat("nada.js"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Stat inexistente: %v", err)
+	}
+	if got := readSource(t, s, "src/lib/util.js"); got != "// src/lib/util.js" {
+		t.Fatalf("conteúdo = %q", got)
+	}
+	if _, _, err := s.Open("src"); err == nil {
+		t.Fatalf("Open de diretório deveria falhar")
+	}
+}
+
+func TestSourceRootWrite(t *testing.T) {
+	s := newTestSnapshot(t)
+
+	if _, err := s.Write("src/lib/util.js", strings.NewReader("novo"), 1024); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := readSource(t, s, "src/lib/util.js"); got != "novo" {
+		t.Fatalf("conteúdo após sobrescrever = %q", got)
+	}
+	e, _ := s.Stat("src/lib/util.js")
+	if e.Mode != os.FileMode(0o755).String() {
+		t.Fatalf("sobrescrever perdeu a permissão: %s", e.Mode)
+	}
+
+	if _, err := s.Write("docs/README.md", strings.NewReader("# oi"), 1024); err != nil {
+		t.Fatalf("Write em diretório novo: %v", err)
+	}
+	if got, _ := s.List("docs"); names(got) != "README.md:file" {
+		t.Fatalf("docs = %q", names(got))
+	}
+
+	// ❌ Falhas não tocam no snapshot
+	before, _ := os.ReadFile(s.path)
+	if _, err := s.Write("grande.bin", strings.NewReader(strings.Repeat("x", 2048)), 1024); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("arquivo acima do limite: %v", err)
+	}
+	if _, err := s.Write("index.js/dentro.js", strings.NewReader("x"), 1024); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("arquivo dentro de arquivo: %v", err)
+	}
+	if _, err := s.Write("src", strings.NewReader("x"), 1024); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("sobrescrever diretório: %v", err)
+	}
+	after, _ := os.ReadFile(s.path)
+	if string(before) != string(after) {
+		t.Fatalf("snapshot alterado por escrita recusada")
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(s.path), ".files-*.zip")); len(leftovers) != 0 {
+		t.Fatalf("snapshots temporários ficaram para trás: %v", leftovers)
+	}
+}
+
+func TestSourceRootMkdirRenameDelete(t *testing.T) {
+	s := newTestSnapshot(t)
+
+	if err := s.Mkdir("public"); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	if err := s.Mkdir("src/lib"); !errors.Is(err, ErrExists) {
+		t.Fatalf("Mkdir existente: %v", err)
+	}
+	if got, _ := s.List("public"); len(got) != 0 {
+		t.Fatalf("diretório novo não está vazio: %q", names(got))
+	}
+
+	// ✏️ Renomear diretório leva o conteúdo junto
+	if err := s.Rename("src", "lib"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if got := readSource(t, s, "lib/lib/util.js"); got != "// src/lib/util.js" {
+		t.Fatalf("conteúdo após renomear = %q", got)
+	}
+	if _, err := s.Stat("src"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("origem continua após renomear: %v", err)
+	}
+	if err := s.Rename("lib", "lib/dentro"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("renomear para dentro de si: %v", err)
+	}
+	if err := s.Rename("index.js", "public"); !errors.Is(err, ErrExists) {
+		t.Fatalf("renomear sobre existente: %v", err)
+	}
+
+	if err := s.Delete("lib"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, _ := s.List(""); names(got) != "public:dir,index.js:file" {
+		t.Fatalf("raiz após apagar = %q", names(got))
+	}
+	if err := s.Delete(""); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("apagar a raiz: %v", err)
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	cases := []struct {
+		in, want string
+		err      bool
+	}{
+		{"", "", false},
+		{"/", "", false},
+		{"src/app.js", "src/app.js", false},
+		{"/src//lib/./util.js", "src/lib/util.js", false},
+		{`src\lib\util.js`, "src/lib/util.js", false},
+		{"../etc/passwd", "", true},
+		{"src/../../etc", "", true},
+		{`src\..\x`, "", true},
+		{"a\x00b", "", true},
+		{"a\nb", "", true},
+		{strings.Repeat("a/", 600), "", true},
+	}
+	for _, tc := range cases {
+		got, err := CleanPath(tc.in)
+		if tc.err {
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("CleanPath(%q) deveria falhar, veio %q, %v", tc.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Fatalf("CleanPath(%q) = %q, %v; esperado %q", tc.in, got, err, tc.want)
+		}
+	}
+}
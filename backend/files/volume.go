@@ -0,0 +1,294 @@
+//backend/files/volume.go
+
+package files
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/volumes"
+)
+
+// 💽 Diretório de um volume persistente no host
+type volumeRoot struct {
+	volume *models.Volume
+}
+
+func (v *volumeRoot) Name() string { return volumePrefix + v.volume.Name }
+
+// 🔒 Abre o diretório do volume como os.Root. O container grava no volume e pode trocar qualquer
+// componente por um link simbólico a qualquer momento; por isso nada aqui resolve o caminho como texto:
+// cada operação caminha a partir do descritor da raiz (openat sem seguir links para fora dela).
+// Links que ficam dentro do volume continuam valendo; apagar ou renomear um link atua só sobre o link.
+func (v *volumeRoot) open() (*os.Root, error) {
+	root, err := os.OpenRoot(volumes.HostPath(v.volume))
+	if err != nil {
+		return nil, fmt.Errorf("%w: diretório do volume", ErrNotFound)
+	}
+	return root, nil
+}
+
+// 🧭 Caminho relativo à raiz no formato do os.Root ("" = a própria raiz)
+func rootPath(p string) string {
+	if p == "" {
+		return "."
+	}
+	return filepath.FromSlash(p)
+}
+
+// ❗ Traduz o erro do os.Root: inexistente vira ErrNotFound, saída da raiz (por link ou "..") vira ErrNotAllowed
+func rootError(p string, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: %s", ErrNotFound, p)
+	case strings.Contains(err.Error(), "escapes from parent"):
+		return fmt.Errorf("%w: %s aponta para fora do volume", ErrNotAllowed, p)
+	default:
+		return fmt.Errorf("erro ao acessar %s: %w", p, err)
+	}
+}
+
+func volumeEntry(p string, info fs.FileInfo) Entry {
+	modified := info.ModTime()
+	entry := Entry{Name: path.Base(p), Path: p, Type: TypeFile, Mode: info.Mode().Perm().String(), ModTime: &modified}
+	switch {
+	case info.IsDir():
+		entry.Type = TypeDir
+	case info.Mode()&os.ModeSymlink != 0:
+		entry.Type = TypeSymlink
+	default:
+		entry.Size = info.Size()
+	}
+	return entry
+}
+
+func (v *volumeRoot) List(dir string) ([]Entry, error) {
+	root, err := v.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	d, err := root.Open(rootPath(dir))
+	if err != nil {
+		return nil, rootError(dir, err)
+	}
+	defer d.Close()
+	items, err := d.ReadDir(-1)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s não é um diretório", ErrInvalid, dir)
+	}
+	children := map[string]Entry{}
+	for _, item := range items {
+		p := path.Join(dir, item.Name())
+		info, err := root.Lstat(rootPath(p)) // pelo descritor da raiz, não pelo caminho no host
+		if err != nil {
+			continue
+		}
+		children[item.Name()] = volumeEntry(p, info)
+	}
+	return sortEntries(children), nil
+}
+
+func (v *volumeRoot) Stat(p string) (*Entry, error) {
+	root, err := v.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	info, err := root.Lstat(rootPath(p))
+	if err != nil {
+		return nil, rootError(p, err)
+	}
+	entry := volumeEntry(p, info)
+	return &entry, nil
+}
+
+func (v *volumeRoot) Open(p string) (io.ReadCloser, *Entry, error) {
+	root, err := v.open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer root.Close()
+	f, err := root.Open(rootPath(p))
+	if err != nil {
+		return nil, nil, rootError(p, err)
+	}
+	info, err := f.Stat() // o arquivo já aberto: trocar o caminho depois não muda o que é lido
+	if err != nil || !info.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, fmt.Errorf("%w: %s não é um arquivo", ErrInvalid, p)
+	}
+	entry := volumeEntry(p, info)
+	return f, &entry, nil
+}
+
+// 📏 Volume acima da cota está montado somente leitura: o gerenciador também não grava
+func (v *volumeRoot) checkQuota(adding int64) error {
+	if v.volume.OverQuota {
+		return fmt.Errorf("%w: volume %s acima da cota (libere espaço antes de gravar)", ErrNotAllowed, v.volume.Name)
+	}
+	used, err := volumes.Measure(v.volume)
+	if err != nil {
+		return fmt.Errorf("erro ao medir volume %s: %w", v.volume.Name, err)
+	}
+	if used+adding > int64(v.volume.SizeGB)*1024*1024*1024 {
+		return fmt.Errorf("%w: a gravação ultrapassa a cota de %d GB do volume %s", ErrNotAllowed, v.volume.SizeGB, v.volume.Name)
+	}
+	return nil
+}
+
+// 🧱 O diretório pai precisa existir
+func parentDir(root *os.Root, p string) (string, error) {
+	parent := path.Dir(p)
+	if parent == "." {
+		parent = ""
+	}
+	if info, err := root.Stat(rootPath(parent)); err != nil || !info.IsDir() {
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", rootError(parent, err)
+		}
+		return "", fmt.Errorf("%w: diretório pai de %s", ErrNotFound, p)
+	}
+	return parent, nil
+}
+
+func (v *volumeRoot) Write(p string, r io.Reader, max int64) (*Entry, error) {
+	if p == "" {
+		return nil, fmt.Errorf("%w: caminho vazio", ErrInvalid)
+	}
+	root, err := v.open()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	dir, err := parentDir(root, p)
+	if err != nil {
+		return nil, err
+	}
+	var previous int64
+	mode := os.FileMode(0o666) // o processo do container roda com outro UID
+	if info, err := root.Lstat(rootPath(p)); err == nil {
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%w: %s não é um arquivo", ErrInvalid, p)
+		}
+		previous, mode = info.Size(), info.Mode().Perm()
+	}
+	if err := v.checkQuota(0); err != nil {
+		return nil, err
+	}
+
+	// Grava ao lado e renomeia: quem lê pelo container nunca vê o arquivo pela metade
+	tmpName := path.Join(dir, ".files-"+randomSuffix())
+	tmp, err := root.OpenFile(rootPath(tmpName), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar arquivo temporário: %w", rootError(tmpName, err))
+	}
+	defer root.Remove(rootPath(tmpName))
+	written, err := io.Copy(tmp, limitedReader(r, max))
+	if err == nil {
+		err = tmp.Chmod(mode) // no descritor: não segue um link posto no lugar do temporário
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao gravar %s: %w", p, err)
+	}
+	if err := v.checkQuota(written - previous); err != nil {
+		return nil, err
+	}
+	if err := root.Rename(rootPath(tmpName), rootPath(p)); err != nil {
+		return nil, fmt.Errorf("erro ao gravar %s: %w", p, rootError(p, err))
+	}
+	return v.Stat(p)
+}
+
+func (v *volumeRoot) Mkdir(p string) error {
+	if p == "" {
+		return fmt.Errorf("%w: caminho vazio", ErrInvalid)
+	}
+	root, err := v.open()
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	if _, err := parentDir(root, p); err != nil {
+		return err
+	}
+	if err := root.Mkdir(rootPath(p), 0o777); errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrExists, p)
+	} else if err != nil {
+		return fmt.Errorf("erro ao criar diretório %s: %w", p, rootError(p, err))
+	}
+	d, err := root.Open(rootPath(p))
+	if err != nil {
+		return rootError(p, err)
+	}
+	defer d.Close()
+	return d.Chmod(0o777)
+}
+
+func (v *volumeRoot) Rename(from, to string) error {
+	if from == "" || to == "" {
+		return fmt.Errorf("%w: não é possível renomear a raiz", ErrInvalid)
+	}
+	if within(to, from) {
+		return fmt.Errorf("%w: destino dentro da origem", ErrInvalid)
+	}
+	root, err := v.open()
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	if _, err := root.Lstat(rootPath(from)); err != nil {
+		return rootError(from, err)
+	}
+	if _, err := parentDir(root, to); err != nil {
+		return err
+	}
+	if _, err := root.Lstat(rootPath(to)); err == nil {
+		return fmt.Errorf("%w: %s", ErrExists, to)
+	}
+	if err := root.Rename(rootPath(from), rootPath(to)); err != nil {
+		return fmt.Errorf("erro ao renomear %s: %w", from, rootError(from, err))
+	}
+	return nil
+}
+
+// 🗑️ Links simbólicos são removidos sem tocar no destino
+func (v *volumeRoot) Delete(p string) error {
+	if p == "" {
+		return fmt.Errorf("%w: não é possível apagar a raiz", ErrInvalid)
+	}
+	root, err := v.open()
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	if _, err := root.Lstat(rootPath(p)); err != nil {
+		return rootError(p, err)
+	}
+	if err := root.RemoveAll(rootPath(p)); err != nil {
+		return fmt.Errorf("erro ao apagar %s: %w", p, rootError(p, err))
+	}
+	return nil
+}
+
+// 🎲 Sufixo aleatório do arquivo temporário
+func randomSuffix() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
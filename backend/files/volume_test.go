@@ -0,0 +1,235 @@
+//backend/files/volume_test.go
+
+package files
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/volumes"
+)
+
+// 🧪 storage/ relativo numa pasta temporária
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "files-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	return m.Run()
+}
+
+// 💽 Volume vazio de 1 GB e uma pasta "do host" fora dele com um arquivo secreto
+func newTestVolume(t *testing.T, appID string) (*volumeRoot, string, string) {
+	t.Helper()
+	volume := &models.Volume{Username: "alice", AppID: appID, Name: "data", SizeGB: 1}
+	host := volumes.HostPath(volume)
+	if err := os.MkdirAll(host, 0o777); err != nil {
+		t.Fatalf("erro ao criar volume: %v", err)
+	}
+	outside, err := filepath.Abs(filepath.Join("outside", appID))
+	if err != nil {
+		t.Fatalf("erro ao montar caminho: %v", err)
+	}
+	if err := os.MkdirAll(outside, 0o700); err != nil {
+		t.Fatalf("erro ao criar pasta externa: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("segredo"), 0o600); err != nil {
+		t.Fatalf("erro ao gravar segredo: %v", err)
+	}
+	return &volumeRoot{volume: volume}, host, outside
+}
+
+func readAll(t *testing.T, v *volumeRoot, p string) string {
+	t.Helper()
+	rc, _, err := v.Open(p)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", p, err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	return string(data)
+}
+
+func TestVolumeRootOperations(t *testing.T) {
+	v, _, _ := newTestVolume(t, "ops")
+
+	if err := v.Mkdir("conf"); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	if err := v.Mkdir("conf"); !errors.Is(err, ErrExists) {
+		t.Fatalf("Mkdir repetido = %v, esperado ErrExists", err)
+	}
+	entry, err := v.Write("conf/app.yml", strings.NewReader("porta: 8080\n"), 1024)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if entry.Size != 12 || entry.Type != TypeFile || entry.Mode != "-rw-rw-rw-" {
+		t.Fatalf("entrada gravada inesperada: %+v", entry)
+	}
+	if _, err := v.Write("missing/app.yml", strings.NewReader("x"), 1024); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Write sem pai = %v, esperado ErrNotFound", err)
+	}
+	if _, err := v.Write("conf/big.bin", strings.NewReader(strings.Repeat("x", 20)), 10); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Write acima do limite = %v, esperado ErrTooLarge", err)
+	}
+
+	if err := v.Rename("conf/app.yml", "app.yml"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if got := readAll(t, v, "app.yml"); got != "porta: 8080\n" {
+		t.Fatalf("conteúdo após renomear = %q", got)
+	}
+	entries, err := v.List("")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("List = %+v, esperado conf/ e app.yml (sem temporários)", entries)
+	}
+
+	if err := v.Delete("conf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := v.Stat("conf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Stat após apagar = %v, esperado ErrNotFound", err)
+	}
+	if err := v.Delete(""); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("apagar a raiz = %v, esperado ErrInvalid", err)
+	}
+}
+
+func TestVolumeRootFollowsLinksInsideVolume(t *testing.T) {
+	v, host, _ := newTestVolume(t, "inside")
+	if err := v.Mkdir("real"); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	if _, err := v.Write("real/a.txt", strings.NewReader("a"), 10); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := os.Symlink("real", filepath.Join(host, "alias")); err != nil {
+		t.Fatalf("erro ao criar link: %v", err)
+	}
+
+	if got := readAll(t, v, "alias/a.txt"); got != "a" {
+		t.Fatalf("conteúdo pelo link = %q", got)
+	}
+	if entry, err := v.Stat("alias"); err != nil || entry.Type != TypeSymlink {
+		t.Fatalf("Stat(alias) = %+v, %v, esperado symlink", entry, err)
+	}
+}
+
+func TestVolumeRootRefusesLinksOutOfVolume(t *testing.T) {
+	v, host, outside := newTestVolume(t, "escape")
+	// 🔗 Links que o container pode criar: absoluto para o host e relativo subindo além da raiz
+	if err := os.Symlink(outside, filepath.Join(host, "abs")); err != nil {
+		t.Fatalf("erro ao criar link: %v", err)
+	}
+	absHost, _ := filepath.Abs(host)
+	rel, _ := filepath.Rel(absHost, outside)
+	if err := os.Symlink(rel, filepath.Join(host, "rel")); err != nil {
+		t.Fatalf("erro ao criar link: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(rel, "secret.txt"), filepath.Join(host, "secret.txt")); err != nil {
+		t.Fatalf("erro ao criar link: %v", err)
+	}
+
+	for _, p := range []string{"abs/secret.txt", "rel/secret.txt", "secret.txt"} {
+		if _, _, err := v.Open(p); err == nil {
+			t.Fatalf("Open(%s) leu fora do volume", p)
+		}
+		if _, err := v.Write(p, strings.NewReader("invadido"), 100); err == nil {
+			t.Fatalf("Write(%s) gravou fora do volume", p)
+		}
+	}
+	if _, _, err := v.Open("rel/secret.txt"); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("Open por link relativo = %v, esperado ErrNotAllowed", err)
+	}
+	if _, err := v.List("abs"); err == nil {
+		t.Fatalf("List listou pasta fora do volume")
+	}
+	if err := v.Mkdir("rel/novo"); err == nil {
+		t.Fatalf("Mkdir criou pasta fora do volume")
+	}
+	if err := v.Rename("rel/secret.txt", "roubado.txt"); err == nil {
+		t.Fatalf("Rename trouxe arquivo de fora do volume")
+	}
+
+	// 🗑️ Apagar o link remove só o link
+	if err := v.Delete("abs"); err != nil {
+		t.Fatalf("Delete(abs): %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(outside, "secret.txt")); err != nil || string(data) != "segredo" {
+		t.Fatalf("arquivo externo alterado: %q, %v", data, err)
+	}
+	if items, _ := os.ReadDir(outside); len(items) != 1 {
+		t.Fatalf("arquivos criados fora do volume: %v", items)
+	}
+}
+
+// 🏁 O container troca uma pasta por um link para fora enquanto o gerenciador grava nela
+func TestVolumeRootWriteRacesWithSymlinkSwap(t *testing.T) {
+	v, host, outside := newTestVolume(t, "race")
+	dir := filepath.Join(host, "dir")
+	if err := os.Mkdir(dir, 0o777); err != nil {
+		t.Fatalf("erro ao criar pasta: %v", err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		parked := filepath.Join(host, "parked")
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_ = os.Rename(dir, parked)
+			_ = os.Symlink(outside, dir)
+			_ = os.Remove(dir)
+			_ = os.Rename(parked, dir)
+		}
+	}()
+
+	for i := 0; i < 300; i++ {
+		_, _ = v.Write("dir/file.txt", strings.NewReader("x"), 10)
+		if rc, _, err := v.Open("dir/secret.txt"); err == nil {
+			rc.Close()
+			close(stop)
+			wg.Wait()
+			t.Fatalf("leitura escapou do volume na iteração %d", i)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	items, _ := os.ReadDir(outside)
+	if len(items) != 1 {
+		t.Fatalf("gravação escapou do volume: %v", items)
+	}
+}
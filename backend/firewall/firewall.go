@@ -0,0 +1,158 @@
+//backend/firewall/firewall.go
+
+// 🧱 Regras de iptables que completam o isolamento das redes dos usuários.
+//
+// O Docker já separa redes bridge diferentes, mas não impede que um container abra conexões para o
+// próprio host (IP do gateway da bridge, API do Docker em porta TCP, o backend rodando no host) nem para
+// o container do backend quando o ingress entra nas redes dos usuários. Com NETWORK_FIREWALL=iptables:
+//
+//   - no namespace de rede do backend (o host, ou o container com NET_ADMIN), tráfego novo vindo das
+//     bridges dos usuários (interfaces vcu*) é descartado na chain INPUT; respostas continuam passando;
+//   - cada IP que o container do backend recebe numa rede de usuário só aceita respostas às conexões
+//     que o próprio ingress abriu (GuardSelf); sem a regra, o ingress não entra na rede.
+//
+// Portas publicadas (DNAT) passam pela chain FORWARD do host e ficam fora daqui: valem como acesso pela
+// internet. Serviços internos (API do Docker, métricas) não devem ser publicados fora do loopback.
+package firewall
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// 🏷️ Prefixo das interfaces bridge das redes de usuário (casado com "vcu+" no iptables)
+const BridgePrefix = "vcu"
+
+// ⚙️ Modos de NETWORK_FIREWALL
+const (
+	ModeOff      = "off"
+	ModeIPTables = "iptables"
+)
+
+var (
+	mu      sync.Mutex
+	mode    = ModeOff
+	guarded = map[string]bool{} // IPs do backend protegidos por GuardSelf
+
+	// 🔧 Executa o iptables (trocado nos testes)
+	run = func(args ...string) error {
+		out, err := exec.Command("iptables", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("iptables %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+)
+
+// 🔤 Nome da interface bridge da rede (até 15 caracteres, estável entre subidas)
+func BridgeName(network string) string {
+	sum := sha256.Sum256([]byte(network))
+	return BridgePrefix + hex.EncodeToString(sum[:])[:12]
+}
+
+// 🚀 Lê NETWORK_FIREWALL e instala as regras das bridges dos usuários
+func StartFromEnv() error {
+	return Start(os.Getenv("NETWORK_FIREWALL"))
+}
+
+// 🚀 Instala as regras no modo pedido ("" ou "off" = nada é instalado e o risco fica no log)
+func Start(m string) error {
+	m = strings.ToLower(strings.TrimSpace(m))
+	if m == "" {
+		m = ModeOff
+	}
+	if m != ModeOff && m != ModeIPTables {
+		return fmt.Errorf("NETWORK_FIREWALL '%s' inválido (use iptables ou off)", m)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	mode = m
+	guarded = map[string]bool{}
+	if mode == ModeOff {
+		log.Println("⚠️ Firewall das redes de usuário desligado (NETWORK_FIREWALL): aplicações alcançam o host e o backend")
+		for _, rule := range HostRecommendations() {
+			log.Println("   iptables " + strings.Join(rule, " "))
+		}
+		return nil
+	}
+
+	// -I na posição 1: a ordem final é ACCEPT das respostas e depois o DROP
+	for _, rule := range [][]string{
+		{"INPUT", "-i", BridgePrefix + "+", "-j", "DROP"},
+		{"INPUT", "-i", BridgePrefix + "+", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"},
+	} {
+		if err := insert(rule); err != nil {
+			return err
+		}
+	}
+	log.Println("🧱 Firewall das redes de usuário ativo: conexões novas das bridges vcu* para o host são descartadas")
+	return nil
+}
+
+// 🔍 Firewall ligado?
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return mode == ModeIPTables
+}
+
+// 🛡️ IP do container do backend numa rede de usuário: só respostas às conexões do ingress entram.
+// Com o firewall desligado não faz nada; com ele ligado, erro significa que o IP ficaria exposto.
+func GuardSelf(ip string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if mode != ModeIPTables || guarded[ip] {
+		return nil
+	}
+	if ip == "" {
+		return fmt.Errorf("IP do backend na rede desconhecido")
+	}
+	if err := insert(selfRule(ip)); err != nil {
+		return err
+	}
+	guarded[ip] = true
+	return nil
+}
+
+// 🔓 Remove a proteção do IP (o backend saiu da rede)
+func ReleaseSelf(ip string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !guarded[ip] {
+		return
+	}
+	delete(guarded, ip)
+	if err := run(append([]string{"-D"}, selfRule(ip)...)...); err != nil {
+		log.Printf("⚠️ Erro ao remover regra do firewall para %s: %v", ip, err)
+	}
+}
+
+// 📋 Regra que o operador instala no host quando o backend roda em container (fora do alcance dele)
+func HostRecommendations() [][]string {
+	return [][]string{
+		{"-I", "INPUT", "-i", BridgePrefix + "+", "-m", "conntrack", "--ctstate", "NEW", "-j", "DROP"},
+	}
+}
+
+func selfRule(ip string) []string {
+	return []string{"INPUT", "-d", ip, "-m", "conntrack", "--ctstate", "NEW", "-j", "DROP"}
+}
+
+// ➕ Insere no topo da chain se a regra ainda não existe (chamado com mu travado)
+func insert(rule []string) error {
+	if run(append([]string{"-C"}, rule...)...) == nil {
+		return nil
+	}
+	args := append([]string{"-I", rule[0], "1"}, rule[1:]...)
+	if err := run(args...); err != nil {
+		return fmt.Errorf("erro ao instalar regra do firewall: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+//backend/firewall/firewall_test.go
+
+package firewall
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 🧪 iptables de mentira: grava as chamadas e mantém as regras inseridas
+type fakeIPTables struct {
+	calls []string
+	rules map[string]bool
+	fail  bool
+}
+
+func useFake(t *testing.T) *fakeIPTables {
+	t.Helper()
+	f := &fakeIPTables{rules: map[string]bool{}}
+	old := run
+	run = func(args ...string) error {
+		f.calls = append(f.calls, strings.Join(args, " "))
+		rule := strings.Join(args[1:], " ")
+		switch args[0] {
+		case "-C":
+			if !f.rules[rule] {
+				return errors.New("regra não existe")
+			}
+		case "-I":
+			if f.fail {
+				return errors.New("permissão negada")
+			}
+			// "-I INPUT 1 ..." guarda a regra sem a posição
+			f.rules[args[1]+" "+strings.Join(args[3:], " ")] = true
+		case "-D":
+			delete(f.rules, rule)
+		}
+		return nil
+	}
+	t.Cleanup(func() {
+		run = old
+		_ = Start(ModeOff)
+	})
+	return f
+}
+
+func (f *fakeIPTables) inserts() []string {
+	var out []string
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, "-I ") {
+			out = append(out, call)
+		}
+	}
+	return out
+}
+
+func TestStartIPTablesInsertsRulesOnce(t *testing.T) {
+	f := useFake(t)
+	if err := Start("iptables"); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	want := []string{
+		"-I INPUT 1 -i vcu+ -j DROP",
+		"-I INPUT 1 -i vcu+ -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+	}
+	if got := f.inserts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("regras inseridas = %q, esperado %q", got, want)
+	}
+	if !Enabled() {
+		t.Fatalf("firewall deveria estar ligado")
+	}
+
+	// 🔁 Reiniciar o backend não duplica as regras
+	if err := Start("iptables"); err != nil {
+		t.Fatalf("Start de novo: %v", err)
+	}
+	if got := f.inserts(); len(got) != len(want) {
+		t.Fatalf("regras duplicadas: %q", got)
+	}
+}
+
+func TestStartOffInstallsNothing(t *testing.T) {
+	f := useFake(t)
+	if err := Start(""); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if Enabled() || len(f.calls) != 0 {
+		t.Fatalf("modo off chamou o iptables: %q", f.calls)
+	}
+	if err := GuardSelf("10.1.0.5"); err != nil || len(f.calls) != 0 {
+		t.Fatalf("GuardSelf com firewall desligado: %v %q", err, f.calls)
+	}
+}
+
+func TestStartRejectsUnknownMode(t *testing.T) {
+	useFake(t)
+	if err := Start("nftables"); err == nil {
+		t.Fatalf("modo desconhecido aceito")
+	}
+}
+
+func TestGuardSelfAndRelease(t *testing.T) {
+	f := useFake(t)
+	if err := Start(ModeIPTables); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := GuardSelf("10.1.0.5"); err != nil {
+		t.Fatalf("GuardSelf: %v", err)
+	}
+	rule := "INPUT -d 10.1.0.5 -m conntrack --ctstate NEW -j DROP"
+	if !f.rules[rule] {
+		t.Fatalf("regra do backend ausente: %v", f.rules)
+	}
+
+	ReleaseSelf("10.1.0.5")
+	if f.rules[rule] {
+		t.Fatalf("regra do backend continuou depois de ReleaseSelf")
+	}
+	if err := GuardSelf(""); err == nil {
+		t.Fatalf("IP vazio aceito")
+	}
+}
+
+func TestGuardSelfFailsWhenRuleCannotBeInstalled(t *testing.T) {
+	f := useFake(t)
+	if err := Start(ModeIPTables); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	f.fail = true
+	if err := GuardSelf("10.2.0.7"); err == nil {
+		t.Fatalf("GuardSelf sem regra instalada não falhou")
+	}
+	f.fail = false
+	if err := GuardSelf("10.2.0.7"); err != nil {
+		t.Fatalf("GuardSelf depois da falha: %v", err)
+	}
+}
+
+func TestBridgeNameFitsInterfaceLimit(t *testing.T) {
+	name := BridgeName("vc-net-um-usuario-com-nome-bem-comprido")
+	if len(name) > 15 || !strings.HasPrefix(name, BridgePrefix) {
+		t.Fatalf("nome de bridge inválido: %q", name)
+	}
+	if name != BridgeName("vc-net-um-usuario-com-nome-bem-comprido") || name == BridgeName("vc-net-outro") {
+		t.Fatalf("nome de bridge instável ou repetido: %q", name)
+	}
+}
@@ -22,7 +22,7 @@ func DebugUsersHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(store.UserStore)
+	json.NewEncoder(w).Encode(store.UserStore.Snapshot())
 }
 
 // 🧪 Retorna os dados do contexto do usuário logado (debug individual)
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 
+	"virtuscloud/backend/audit"
 	"virtuscloud/backend/limits"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/models"
@@ -26,12 +27,15 @@ func AssignPlanHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Erro ao decodificar requisição JSON", http.StatusBadRequest)
 		return
 	}
+	audit.SetTarget(r, req.Username)
+	audit.SetDetail(r, "to", string(req.Plan))
 
-	user := store.UserStore[req.Username]
-	if user == nil {
+	current, _ := store.UserStore.Get(req.Username)
+	if current == nil {
 		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
 		return
 	}
+	audit.SetDetail(r, "from", string(current.Plan))
 
 	planDetails, ok := models.Plans[req.Plan]
 	if !ok {
@@ -39,7 +43,12 @@ func AssignPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user.Plan = req.Plan
+	if _, err := store.UserStore.Update(req.Username, func(user *models.User) {
+		user.Plan = req.Plan
+	}); err != nil {
+		http.Error(w, "Erro ao salvar plano do usuário", http.StatusInternalServerError)
+		return
+	}
 
 	log.Printf(
 		"Plano atribuído: %s → Usuário: %s | Memória: %dMB | Projetos: %d-%d",
@@ -52,12 +61,18 @@ func AssignPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "Plano '%s' atribuído ao usuário '%s' com sucesso.", req.Plan, req.Username)
+
+	// 📂 RAM, CPU, PIDs e I/O são reaplicados nos containers existentes; o ulimit nofile não muda com o container criado
+	if previous := models.Plans[current.Plan]; previous.NoFileLimit != planDetails.NoFileLimit {
+		fmt.Fprintf(w, " O limite de arquivos abertos (nofile %d → %d) só vale para cada aplicação depois de recriar o container (rebuild).",
+			previous.NoFileLimit, planDetails.NoFileLimit)
+	}
 }
 
 // GET /api/user/status
 func GetUserStatusHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r)
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
 		return
@@ -66,19 +81,22 @@ func GetUserStatusHandler(w http.ResponseWriter, r *http.Request) {
 	plan := models.Plans[user.Plan]
 
 	appCount := limits.CountUserContainers(user.Username)
-	ramUsed := limits.SumUserRAM(user.Username) // float32
-	cpuUsed := limits.SumUserCPU(user.Username) // float32
+	ramUsed := limits.SumUserRAM(user.Username) // float32 — só exibição: uso atual
+	cpuUsed := limits.SumUserCPU(user.Username) // float32 — só exibição: uso atual
 
 	perAppLimit := plan.PerAppMB // ✅ agora vem do plano
 	totalMB := plan.MemoryMB
 
-	// 🔧 Corrige tipos: converte totalMB para float32
-	ramAvailable := float32(totalMB) - ramUsed
-	if ramAvailable < 0 {
-		ramAvailable = 0
+	// 📒 Disponível = o que ainda não foi reservado para aplicações (paradas também ocupam a fatia)
+	ramAllocated, ramAvailable := 0, 0
+	cpuAllocated := float32(0)
+	if ledger, err := limits.UserLedger(user.Username); err == nil {
+		ramAllocated, ramAvailable = ledger.AllocatedRAMMB, ledger.FreeRAMMB()
+		cpuAllocated = ledger.AllocatedCPUs
 	}
 
-	canDeploy := appCount < plan.MaxProjects
+	// 🚦 Mesma regra do deploy (ledger do plano, não o uso atual)
+	canDeploy := limits.CheckDeployAdmission(user.Username) == nil
 
 	utils.WriteJSON(w, map[string]interface{}{
 		"username":       user.Username,
@@ -86,7 +104,9 @@ func GetUserStatusHandler(w http.ResponseWriter, r *http.Request) {
 		"canDeploy":      canDeploy,
 		"ramUsedMB":      ramUsed,
 		"ramAvailableMB": ramAvailable,
+		"ramAllocatedMB": ramAllocated,
 		"cpuUsedPct":     cpuUsed,
+		"cpuAllocated":   cpuAllocated,
 		"memoryMB":       perAppLimit, // ✅ limite por aplicação (256 MB)
 		"totalMB":        totalMB,     // limite global do plano
 		"maxProjects":    plan.MaxProjects,
@@ -404,7 +424,7 @@ func GetUserDetailsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
 		return
@@ -434,7 +454,7 @@ func GetUserDetailsHandler(w http.ResponseWriter, r *http.Request) {
 func GetUserPlanHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r) // ✅ usa contexto preenchido pelo AuthMiddleware
 
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
 		return
@@ -0,0 +1,334 @@
+//backend/health/monitor.go
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/ingress"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 🔄 Varredura que liga/desliga os probes conforme as aplicações do AppStore
+const syncInterval = 15 * time.Second
+
+// 🧵 Probes em execução de uma aplicação
+type worker struct {
+	cancel context.CancelFunc
+	config string // configuração serializada (mudou = reinicia os probes)
+}
+
+var (
+	workersMu sync.Mutex
+	workers   = map[string]*worker{}
+)
+
+// 🧹 Probes param junto com a aplicação
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		stop(app.ID)
+	})
+}
+
+// 🚀 Acompanha as aplicações com health check em segundo plano
+func StartMonitor() {
+	go func() {
+		for {
+			syncWorkers()
+			time.Sleep(syncInterval)
+		}
+	}()
+}
+
+// ⚙️ Substitui os probes da aplicação (nil remove) e reinicia a verificação do zero
+func Configure(app *models.App, cfg *models.HealthCheck) (*models.App, error) {
+	if cfg != nil && cfg.Liveness == nil && cfg.Readiness == nil {
+		cfg = nil
+	}
+	if err := Normalize(cfg); err != nil {
+		return nil, err
+	}
+
+	updated, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		a.HealthCheck = cfg.Clone()
+		a.Health = nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar health check: %w", err)
+	}
+	reload(updated)
+	refreshIngress(app.ID)
+	return updated, nil
+}
+
+func syncWorkers() {
+	active := map[string]bool{}
+	for _, app := range store.AppStore.List() {
+		if app.HealthCheck != nil {
+			active[app.ID] = true
+			reload(app)
+		}
+	}
+
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	for id, w := range workers {
+		if !active[id] {
+			w.cancel()
+			delete(workers, id)
+		}
+	}
+}
+
+// 🔁 Garante um worker com a configuração atual da aplicação
+func reload(app *models.App) {
+	if app.HealthCheck == nil {
+		stop(app.ID)
+		return
+	}
+	data, _ := json.Marshal(app.HealthCheck)
+
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	if w, ok := workers[app.ID]; ok {
+		if w.config == string(data) {
+			return
+		}
+		w.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	workers[app.ID] = &worker{cancel: cancel, config: string(data)}
+	if p := app.HealthCheck.Liveness; p != nil {
+		go runLoop(ctx, app.ID, true, p.Clone())
+	}
+	if p := app.HealthCheck.Readiness; p != nil {
+		go runLoop(ctx, app.ID, false, p.Clone())
+	}
+}
+
+func stop(appID string) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	if w, ok := workers[appID]; ok {
+		w.cancel()
+		delete(workers, appID)
+	}
+}
+
+// ⏱️ Executa o probe a cada intervalo até ctx ser cancelado
+func runLoop(ctx context.Context, appID string, liveness bool, p *models.Probe) {
+	ticker := time.NewTicker(time.Duration(p.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	var startedAt time.Time
+	for {
+		check(ctx, appID, liveness, p, &startedAt)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// 🩺 Uma rodada do probe: atualiza o resultado na aplicação e reage às transições
+func check(ctx context.Context, appID string, liveness bool, p *models.Probe, startedAt *time.Time) {
+	app, ok := store.AppStore.Get(appID)
+	if !ok {
+		return
+	}
+
+	inspectCtx, cancel := engine.Timeout()
+	info, err := engine.Default().Inspect(inspectCtx, containerOf(app))
+	cancel()
+	if err != nil || !info.Running {
+		// Parado (pelo usuário ou por crash): sem veredito até voltar a rodar
+		record(app, liveness, func(r *models.ProbeResult) {
+			*r = models.ProbeResult{Status: models.ProbeUnknown, Message: "container parado"}
+		})
+		return
+	}
+
+	fresh := !info.StartedAt.Equal(*startedAt)
+	*startedAt = info.StartedAt
+	inStartPeriod := time.Since(info.StartedAt) < time.Duration(p.StartPeriodSeconds)*time.Second
+
+	message, probeErr := runProbe(ctx, app, info, p)
+	if ctx.Err() != nil {
+		return // configuração trocada no meio da rodada
+	}
+
+	now := time.Now()
+	var became models.ProbeState
+	record(app, liveness, func(r *models.ProbeResult) {
+		if fresh {
+			*r = models.ProbeResult{Status: models.ProbeUnknown}
+		}
+		previous := r.Status
+		r.LastCheck = &now
+
+		switch {
+		case probeErr == nil:
+			r.Message = message
+			r.LastSuccess = &now
+			r.ConsecutiveSuccesses++
+			r.ConsecutiveFailures = 0
+			if r.ConsecutiveSuccesses >= p.SuccessThreshold {
+				r.Status = models.ProbePassing
+			}
+		case inStartPeriod:
+			// Falhas no período inicial não contam (a aplicação ainda está subindo)
+			r.Message = probeErr.Error()
+			r.ConsecutiveSuccesses = 0
+			r.Status = models.ProbeStarting
+		default:
+			r.Message = probeErr.Error()
+			r.LastFailure = &now
+			r.ConsecutiveFailures++
+			r.ConsecutiveSuccesses = 0
+			if r.ConsecutiveFailures >= p.FailureThreshold {
+				r.Status = models.ProbeFailing
+			}
+		}
+		if r.Status == "" {
+			r.Status = models.ProbeUnknown
+		}
+		if r.Status != previous {
+			became = r.Status
+		}
+	})
+
+	if became == "" {
+		return
+	}
+	kind := "Readiness"
+	if liveness {
+		kind = "Liveness"
+	}
+	services.Log(app.ID, app.Username, app.Plan, fmt.Sprintf("🩺 %s: %s (%s)", kind, became, resultMessage(appID, liveness)))
+	if liveness && became == models.ProbeFailing {
+		onLivenessFailure(app)
+	}
+}
+
+// 💾 Altera o resultado do probe; transições de estado são gravadas no disco, o resto fica em memória
+func record(app *models.App, liveness bool, fn func(r *models.ProbeResult)) {
+	var changed, readyChanged bool
+	mutate := func(a *models.App) {
+		if a.Health == nil {
+			a.Health = &models.Health{}
+		}
+		slot := &a.Health.Readiness
+		if liveness {
+			slot = &a.Health.Liveness
+		}
+		if *slot == nil {
+			*slot = &models.ProbeResult{Status: models.ProbeUnknown}
+		}
+		before := (*slot).Status
+		fn(*slot)
+		changed = (*slot).Status != before
+
+		ready := readyOf(a)
+		readyChanged = ready != a.Health.Ready
+		a.Health.Ready = ready
+	}
+
+	// Ensaio em cópia: só grava no disco quando algo relevante muda
+	probe := app.Clone()
+	mutate(probe)
+	if changed || readyChanged {
+		if _, err := store.AppStore.Update(app.ID, mutate); err != nil {
+			log.Printf("⚠️ Health: erro ao gravar resultado de %s: %v", app.ID, err)
+			return
+		}
+	} else {
+		store.AppStore.UpdateVolatile(app.ID, mutate)
+	}
+	if readyChanged {
+		refreshIngress(app.ID)
+	}
+}
+
+// 🚦 Pronta = readiness passando (sem readiness, vale o liveness)
+func readyOf(a *models.App) bool {
+	r := a.Health.Liveness
+	if a.HealthCheck != nil && a.HealthCheck.Readiness != nil {
+		r = a.Health.Readiness
+	}
+	return r != nil && r.Status == models.ProbePassing
+}
+
+// 🔁 Liveness falhou: reinicia se o plano permitir
+func onLivenessFailure(app *models.App) {
+	user, _ := store.UserStore.Get(app.Username)
+	if user == nil || !models.Plans[user.Plan].AutoRestart {
+		services.Log(app.ID, app.Username, app.Plan, "⚠️ Liveness falhando, mas o plano não inclui reinício automático")
+		return
+	}
+
+	log.Printf("🔁 Health: liveness de %s falhou, reiniciando", app.ID)
+	restartErr := services.RestartApp(app.ID, app.Username)
+	if restartErr != nil {
+		log.Printf("❌ Health: falha ao reiniciar %s: %v", app.ID, restartErr)
+	}
+
+	// Liveness volta a "unknown": se o reinício falhou, novas falhas disparam outra tentativa
+	now := time.Now()
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		if a.Health == nil {
+			a.Health = &models.Health{}
+		}
+		result := &models.ProbeResult{Status: models.ProbeUnknown, Message: "reiniciada pelo liveness"}
+		if restartErr != nil {
+			result.Message = "falha ao reiniciar: " + restartErr.Error()
+		} else {
+			a.Health.Restarts++
+			a.Health.LastRestart = &now
+		}
+		a.Health.Liveness = result
+		a.Health.Ready = readyOf(a)
+	}); err != nil {
+		log.Printf("⚠️ Health: erro ao registrar reinício de %s: %v", app.ID, err)
+	}
+}
+
+func resultMessage(appID string, liveness bool) string {
+	app, ok := store.AppStore.Get(appID)
+	if !ok || app.Health == nil {
+		return ""
+	}
+	r := app.Health.Readiness
+	if liveness {
+		r = app.Health.Liveness
+	}
+	if r == nil {
+		return ""
+	}
+	return r.Message
+}
+
+// 🚪 Rotas do ingress seguem a prontidão
+func refreshIngress(appID string) {
+	if in := ingress.Default(); in != nil {
+		in.Refresh(appID)
+	}
+}
+
+// 🐳 Nome do container da aplicação
+func containerOf(app *models.App) string {
+	if app.ContainerName != "" {
+		return app.ContainerName
+	}
+	return utils.GetContainerName(app.Username, app.ID)
+}
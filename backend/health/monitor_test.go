@@ -0,0 +1,306 @@
+//backend/health/monitor_test.go
+
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/store"
+)
+
+// 🧪 Os testes rodam numa pasta temporária (storage/ e database/ relativos) com o runtime em memória
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "health-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	persistence.SetBackend(persistence.NewJSONBackend())
+	rt := engine.NewFake()
+	rt.SetClock(time.Now) // o monitor compara StartedAt com o relógio real (período inicial)
+	engine.SetDefault(rt)
+	if err := store.UserStore.Load("./database/users.json"); err != nil {
+		fmt.Println("erro ao carregar usuários:", err)
+		return 1
+	}
+	if err := store.LoadAppStoreFromDisk("./database/appstore.json"); err != nil {
+		fmt.Println("erro ao carregar aplicações:", err)
+		return 1
+	}
+	return m.Run()
+}
+
+// 🩺 Resultado do probe exec controlado pelo teste (0 = saudável)
+type execResult struct{ code atomic.Int32 }
+
+func (r *execResult) set(code int) { r.code.Store(int32(code)) }
+
+// 🚀 Aplicação com container rodando no fake e probes exec respondendo conforme o resultado dado
+func newProbedApp(t *testing.T, id string, plan models.PlanType, hc *models.HealthCheck) (*models.App, *execResult) {
+	t.Helper()
+	username := "user-" + id
+	if err := store.UserStore.Save(&models.User{Username: username, Plan: plan}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+	app := &models.App{ID: id, Username: username, Plan: string(plan), Status: models.StatusRunning, ContainerName: username + "-" + id}
+	if err := store.AppStore.Save(app); err != nil {
+		t.Fatalf("erro ao salvar aplicação: %v", err)
+	}
+
+	rt := engine.Default().(*engine.FakeRuntime)
+	rt.AddImage("img-" + id)
+	ctx := context.Background()
+	if _, err := rt.Create(ctx, engine.Spec{Name: app.ContainerName, Image: "img-" + id}); err != nil {
+		t.Fatalf("erro ao criar container: %v", err)
+	}
+	if err := rt.Start(ctx, app.ContainerName); err != nil {
+		t.Fatalf("erro ao iniciar container: %v", err)
+	}
+	result := &execResult{}
+	rt.SetExec(app.ContainerName, func(cmd []string, stdout, stderr io.Writer) int {
+		code := int(result.code.Load())
+		if code != 0 {
+			fmt.Fprintln(stderr, "falhou")
+		} else {
+			fmt.Fprintln(stdout, "ok")
+		}
+		return code
+	})
+
+	if err := Normalize(hc); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	updated, err := store.AppStore.Update(id, func(a *models.App) { a.HealthCheck = hc })
+	if err != nil {
+		t.Fatalf("erro ao gravar health check: %v", err)
+	}
+	return updated, result
+}
+
+func execProbe(success, failure, startPeriod int) *models.Probe {
+	return &models.Probe{
+		Type:               models.ProbeExec,
+		Command:            []string{"true"},
+		SuccessThreshold:   success,
+		FailureThreshold:   failure,
+		StartPeriodSeconds: startPeriod,
+	}
+}
+
+func healthOf(t *testing.T, id string) *models.Health {
+	t.Helper()
+	app, ok := store.AppStore.Get(id)
+	if !ok || app.Health == nil {
+		t.Fatalf("aplicação %s sem saúde registrada", id)
+	}
+	return app.Health
+}
+
+func startedAt(t *testing.T, name string) time.Time {
+	t.Helper()
+	info, err := engine.Default().Inspect(context.Background(), name)
+	if err != nil {
+		t.Fatalf("erro ao inspecionar %s: %v", name, err)
+	}
+	return info.StartedAt
+}
+
+func TestNormalizeDefaultsAndLimits(t *testing.T) {
+	cfg := &models.HealthCheck{Liveness: &models.Probe{Type: models.ProbeHTTP}, Readiness: &models.Probe{Type: models.ProbeTCP, Path: "/x", IntervalSeconds: 2}}
+	if err := Normalize(cfg); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	l, r := cfg.Liveness, cfg.Readiness
+	if l.Path != "/" || l.IntervalSeconds != DefaultIntervalSeconds || l.TimeoutSeconds != DefaultTimeoutSeconds ||
+		l.SuccessThreshold != DefaultSuccessThreshold || l.FailureThreshold != DefaultFailureThreshold {
+		t.Fatalf("padrões do liveness não aplicados: %+v", l)
+	}
+	if r.Path != "" || r.TimeoutSeconds != 2 {
+		t.Fatalf("readiness tcp: path=%q timeout=%d, esperado vazio e limitado ao intervalo", r.Path, r.TimeoutSeconds)
+	}
+
+	invalid := []struct {
+		name  string
+		probe models.Probe
+	}{
+		{"tipo desconhecido", models.Probe{Type: "ping"}},
+		{"path sem barra", models.Probe{Type: models.ProbeHTTP, Path: "saude"}},
+		{"exec sem comando", models.Probe{Type: models.ProbeExec}},
+		{"porta fora do intervalo", models.Probe{Type: models.ProbeTCP, Port: 70000}},
+		{"intervalo curto", models.Probe{Type: models.ProbeTCP, IntervalSeconds: 1}},
+		{"timeout maior que o intervalo", models.Probe{Type: models.ProbeTCP, IntervalSeconds: 5, TimeoutSeconds: 6}},
+		{"limiar alto", models.Probe{Type: models.ProbeTCP, FailureThreshold: 11}},
+		{"período inicial negativo", models.Probe{Type: models.ProbeTCP, StartPeriodSeconds: -1}},
+	}
+	for _, tc := range invalid {
+		p := tc.probe
+		if err := Normalize(&models.HealthCheck{Liveness: &p}); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: erro = %v, esperado ErrInvalid", tc.name, err)
+		}
+	}
+}
+
+func TestLivenessFailureRestartsContainer(t *testing.T) {
+	app, result := newProbedApp(t, "live1", models.PlanPro, &models.HealthCheck{Liveness: execProbe(1, 2, 0)})
+	probe := app.HealthCheck.Liveness
+	var seen time.Time
+
+	check(context.Background(), app.ID, true, probe, &seen)
+	if h := healthOf(t, app.ID); h.Liveness.Status != models.ProbePassing || !h.Ready {
+		t.Fatalf("liveness=%s pronta=%v, esperado passing e pronta", h.Liveness.Status, h.Ready)
+	}
+	before := startedAt(t, app.ContainerName)
+
+	result.set(1)
+	check(context.Background(), app.ID, true, probe, &seen)
+	if h := healthOf(t, app.ID); h.Liveness.Status != models.ProbePassing || h.Liveness.ConsecutiveFailures != 1 {
+		t.Fatalf("uma falha abaixo do limiar: status=%s falhas=%d", h.Liveness.Status, h.Liveness.ConsecutiveFailures)
+	}
+	time.Sleep(2 * time.Millisecond)
+	check(context.Background(), app.ID, true, probe, &seen)
+
+	h := healthOf(t, app.ID)
+	if h.Restarts != 1 || h.LastRestart == nil {
+		t.Fatalf("reinícios = %d, esperado 1", h.Restarts)
+	}
+	if h.Liveness.Status != models.ProbeUnknown || h.Ready {
+		t.Fatalf("após o reinício: liveness=%s pronta=%v, esperado unknown e não pronta", h.Liveness.Status, h.Ready)
+	}
+	if !startedAt(t, app.ContainerName).After(before) {
+		t.Fatalf("container não foi reiniciado")
+	}
+}
+
+func TestLivenessFailureWithoutAutoRestart(t *testing.T) {
+	app, result := newProbedApp(t, "live2", models.PlanBasic, &models.HealthCheck{Liveness: execProbe(1, 1, 0)})
+	before := startedAt(t, app.ContainerName)
+	var seen time.Time
+
+	result.set(1)
+	check(context.Background(), app.ID, true, app.HealthCheck.Liveness, &seen)
+
+	h := healthOf(t, app.ID)
+	if h.Liveness.Status != models.ProbeFailing || h.Restarts != 0 {
+		t.Fatalf("liveness=%s reinícios=%d, esperado failing sem reinício", h.Liveness.Status, h.Restarts)
+	}
+	if h.Liveness.Message != "código de saída 1: falhou" {
+		t.Fatalf("mensagem = %q", h.Liveness.Message)
+	}
+	if !startedAt(t, app.ContainerName).Equal(before) {
+		t.Fatalf("plano sem reinício automático reiniciou o container")
+	}
+}
+
+func TestReadinessThresholdsDriveReady(t *testing.T) {
+	app, result := newProbedApp(t, "ready1", models.PlanPro, &models.HealthCheck{Readiness: execProbe(2, 1, 0)})
+	probe := app.HealthCheck.Readiness
+	var seen time.Time
+
+	check(context.Background(), app.ID, false, probe, &seen)
+	if h := healthOf(t, app.ID); h.Ready || h.Readiness.Status != models.ProbeUnknown {
+		t.Fatalf("um sucesso abaixo do limiar: status=%s pronta=%v", h.Readiness.Status, h.Ready)
+	}
+	if app, _ := store.AppStore.Get(app.ID); !app.AwaitingReadiness() {
+		t.Fatalf("aplicação deveria aguardar readiness")
+	}
+
+	check(context.Background(), app.ID, false, probe, &seen)
+	if h := healthOf(t, app.ID); !h.Ready || h.Readiness.Status != models.ProbePassing {
+		t.Fatalf("dois sucessos: status=%s pronta=%v, esperado passing e pronta", h.Readiness.Status, h.Ready)
+	}
+
+	result.set(1)
+	check(context.Background(), app.ID, false, probe, &seen)
+	if h := healthOf(t, app.ID); h.Ready || h.Readiness.Status != models.ProbeFailing {
+		t.Fatalf("falha: status=%s pronta=%v, esperado failing e não pronta", h.Readiness.Status, h.Ready)
+	}
+}
+
+func TestStartPeriodIgnoresFailures(t *testing.T) {
+	app, result := newProbedApp(t, "boot1", models.PlanPro, &models.HealthCheck{Liveness: execProbe(1, 1, 300)})
+	var seen time.Time
+
+	result.set(1)
+	check(context.Background(), app.ID, true, app.HealthCheck.Liveness, &seen)
+	h := healthOf(t, app.ID)
+	if h.Liveness.Status != models.ProbeStarting || h.Liveness.ConsecutiveFailures != 0 || h.Restarts != 0 {
+		t.Fatalf("falha no período inicial: status=%s falhas=%d reinícios=%d", h.Liveness.Status, h.Liveness.ConsecutiveFailures, h.Restarts)
+	}
+}
+
+func TestStoppedContainerHasNoVerdict(t *testing.T) {
+	app, _ := newProbedApp(t, "stop1", models.PlanPro, &models.HealthCheck{Liveness: execProbe(1, 1, 0)})
+	var seen time.Time
+
+	check(context.Background(), app.ID, true, app.HealthCheck.Liveness, &seen)
+	if err := engine.Default().Stop(context.Background(), app.ContainerName, time.Second); err != nil {
+		t.Fatalf("erro ao parar container: %v", err)
+	}
+	check(context.Background(), app.ID, true, app.HealthCheck.Liveness, &seen)
+
+	h := healthOf(t, app.ID)
+	if h.Liveness.Status != models.ProbeUnknown || h.Liveness.Message != "container parado" || h.Ready {
+		t.Fatalf("container parado: status=%s mensagem=%q pronta=%v", h.Liveness.Status, h.Liveness.Message, h.Ready)
+	}
+}
+
+func TestConfigureReplacesAndRemovesProbes(t *testing.T) {
+	app, _ := newProbedApp(t, "conf1", models.PlanPro, nil)
+
+	if _, err := Configure(app, &models.HealthCheck{Liveness: &models.Probe{Type: "ping"}}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("configuração inválida aceita: %v", err)
+	}
+
+	updated, err := Configure(app, &models.HealthCheck{Liveness: execProbe(0, 0, 0)})
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if updated.HealthCheck == nil || updated.HealthCheck.Liveness.FailureThreshold != DefaultFailureThreshold {
+		t.Fatalf("health check não gravado com os padrões: %+v", updated.HealthCheck)
+	}
+	workersMu.Lock()
+	_, running := workers[app.ID]
+	workersMu.Unlock()
+	if !running {
+		t.Fatalf("probes não iniciados após Configure")
+	}
+
+	// 🧹 Configuração vazia remove os probes
+	updated, err = Configure(app, &models.HealthCheck{})
+	if err != nil {
+		t.Fatalf("Configure vazio: %v", err)
+	}
+	workersMu.Lock()
+	_, running = workers[app.ID]
+	workersMu.Unlock()
+	if updated.HealthCheck != nil || running {
+		t.Fatalf("probes continuam após remover a configuração")
+	}
+}
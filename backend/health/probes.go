@@ -0,0 +1,243 @@
+//backend/health/probes.go
+
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/utils"
+)
+
+// ⏱️ Padrões aplicados a campos zerados
+const (
+	DefaultIntervalSeconds  = 10
+	DefaultTimeoutSeconds   = 3
+	DefaultSuccessThreshold = 1
+	DefaultFailureThreshold = 3
+)
+
+// 📏 Limites aceitos na configuração
+const (
+	minIntervalSeconds = 2
+	maxIntervalSeconds = 3600
+	maxTimeoutSeconds  = 60
+	maxThreshold       = 10
+	maxStartPeriod     = 3600
+	maxExecArgs        = 32
+	maxOutputBytes     = 512 // saída do exec/HTTP guardada na mensagem do resultado
+)
+
+// ❗ Configuração inválida (o handler responde 400 com errors.Is)
+var ErrInvalid = errors.New("health check inválido")
+
+// ✅ Valida a configuração e preenche os padrões
+func Normalize(cfg *models.HealthCheck) error {
+	if cfg == nil {
+		return nil
+	}
+	if err := normalizeProbe("liveness", cfg.Liveness); err != nil {
+		return err
+	}
+	return normalizeProbe("readiness", cfg.Readiness)
+}
+
+func normalizeProbe(kind string, p *models.Probe) error {
+	if p == nil {
+		return nil
+	}
+
+	switch p.Type {
+	case models.ProbeHTTP:
+		if p.Path == "" {
+			p.Path = "/"
+		}
+		if !strings.HasPrefix(p.Path, "/") || strings.ContainsAny(p.Path, " \r\n") {
+			return fmt.Errorf("%w: %s.path deve começar com / e não conter espaços", ErrInvalid, kind)
+		}
+		p.Command = nil
+	case models.ProbeTCP:
+		p.Path, p.Command = "", nil
+	case models.ProbeExec:
+		if len(p.Command) == 0 || strings.TrimSpace(p.Command[0]) == "" {
+			return fmt.Errorf("%w: %s.command é obrigatório para probes exec", ErrInvalid, kind)
+		}
+		if len(p.Command) > maxExecArgs {
+			return fmt.Errorf("%w: %s.command aceita até %d argumentos", ErrInvalid, kind, maxExecArgs)
+		}
+		p.Path, p.Port = "", 0
+	default:
+		return fmt.Errorf("%w: %s.type deve ser http, tcp ou exec", ErrInvalid, kind)
+	}
+	if p.Port < 0 || p.Port > 65535 {
+		return fmt.Errorf("%w: %s.port fora do intervalo", ErrInvalid, kind)
+	}
+
+	if p.IntervalSeconds == 0 {
+		p.IntervalSeconds = DefaultIntervalSeconds
+	}
+	if p.TimeoutSeconds == 0 {
+		p.TimeoutSeconds = DefaultTimeoutSeconds
+		if p.IntervalSeconds > 0 && p.IntervalSeconds < p.TimeoutSeconds {
+			p.TimeoutSeconds = p.IntervalSeconds
+		}
+	}
+	if p.SuccessThreshold == 0 {
+		p.SuccessThreshold = DefaultSuccessThreshold
+	}
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = DefaultFailureThreshold
+	}
+
+	switch {
+	case p.IntervalSeconds < minIntervalSeconds || p.IntervalSeconds > maxIntervalSeconds:
+		return fmt.Errorf("%w: %s.intervalSeconds deve ficar entre %d e %d", ErrInvalid, kind, minIntervalSeconds, maxIntervalSeconds)
+	case p.TimeoutSeconds < 1 || p.TimeoutSeconds > maxTimeoutSeconds || p.TimeoutSeconds > p.IntervalSeconds:
+		return fmt.Errorf("%w: %s.timeoutSeconds deve ficar entre 1 e %d, sem passar do intervalo", ErrInvalid, kind, maxTimeoutSeconds)
+	case p.SuccessThreshold < 1 || p.SuccessThreshold > maxThreshold:
+		return fmt.Errorf("%w: %s.successThreshold deve ficar entre 1 e %d", ErrInvalid, kind, maxThreshold)
+	case p.FailureThreshold < 1 || p.FailureThreshold > maxThreshold:
+		return fmt.Errorf("%w: %s.failureThreshold deve ficar entre 1 e %d", ErrInvalid, kind, maxThreshold)
+	case p.StartPeriodSeconds < 0 || p.StartPeriodSeconds > maxStartPeriod:
+		return fmt.Errorf("%w: %s.startPeriodSeconds deve ficar entre 0 e %d", ErrInvalid, kind, maxStartPeriod)
+	}
+	return nil
+}
+
+// 🩺 Executa o probe uma vez contra o container em execução (nil = saudável)
+func runProbe(ctx context.Context, app *models.App, info *engine.Info, p *models.Probe) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	switch p.Type {
+	case models.ProbeExec:
+		return probeExec(ctx, info.Name, p.Command)
+	case models.ProbeTCP:
+		addr, err := probeAddress(app, info, p)
+		if err != nil {
+			return "", err
+		}
+		return probeTCP(ctx, addr)
+	default:
+		addr, err := probeAddress(app, info, p)
+		if err != nil {
+			return "", err
+		}
+		return probeHTTP(ctx, "http://"+addr+p.Path)
+	}
+}
+
+// 🌐 GET sem seguir redirecionamentos: 2xx e 3xx contam como sucesso
+func probeHTTP(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "virtuscloud-health/1")
+
+	resp, err := probeClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	status := fmt.Sprintf("HTTP %d", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return "", errors.New(status)
+	}
+	return status, nil
+}
+
+var probeClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// 🔌 Conexão TCP aceita = saudável
+func probeTCP(ctx context.Context, addr string) (string, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	conn.Close()
+	return "conexão aceita em " + addr, nil
+}
+
+// 🧪 Comando no container: código 0 = saudável
+func probeExec(ctx context.Context, container string, cmd []string) (string, error) {
+	out := &cappedBuffer{max: maxOutputBytes}
+	code, err := engine.Default().Exec(ctx, container, cmd, out, out)
+	if err != nil {
+		return "", err
+	}
+	output := strings.TrimSpace(out.String())
+	if code != 0 {
+		if output == "" {
+			return "", fmt.Errorf("código de saída %d", code)
+		}
+		return "", fmt.Errorf("código de saída %d: %s", code, output)
+	}
+	return output, nil
+}
+
+// 🎯 IP:porta do container na rede do usuário (ou na primeira rede com IP)
+func probeAddress(app *models.App, info *engine.Info, p *models.Probe) (string, error) {
+	ip := info.Networks[utils.GetUserNetworkName(app.Username)]
+	if ip == "" {
+		names := make([]string, 0, len(info.Networks))
+		for name := range info.Networks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if info.Networks[name] != "" {
+				ip = info.Networks[name]
+				break
+			}
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("container %s sem IP", info.Name)
+	}
+
+	port := p.Port
+	if port == 0 {
+		port = app.Port
+	}
+	if port == 0 && len(info.ExposedPorts) > 0 {
+		port = info.ExposedPorts[0]
+	}
+	if port == 0 {
+		return "", errors.New("porta desconhecida: informe port no probe")
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(port)), nil
+}
+
+// ✂️ Buffer que guarda só os primeiros max bytes (o resto é descartado sem erro)
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
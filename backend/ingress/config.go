@@ -0,0 +1,46 @@
+//backend/ingress/config.go
+
+package ingress
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ⚙️ Configuração do ingress (proxy reverso das aplicações)
+type Config struct {
+	Addr           string        // endereço de escuta (INGRESS_ADDR, padrão :8000)
+	TLSAddr        string        // escuta HTTPS com certificado por SNI (INGRESS_TLS_ADDR; vazio = sem TLS)
+	BaseDomain     string        // <app>.<usuário>.<BaseDomain> (INGRESS_BASE_DOMAIN; vazio = ingress desligado)
+	DefaultPort    int           // porta da aplicação sem EXPOSE nem Port definido (INGRESS_DEFAULT_PORT, padrão 8080)
+	SelfContainer  string        // container do próprio backend, conectado às redes dos usuários (INGRESS_CONTAINER)
+	TrustForwarded bool          // mantém X-Forwarded-* recebidos (atrás do nginx) (INGRESS_TRUST_FORWARDED)
+	ResyncInterval time.Duration // varredura completa além dos eventos (INGRESS_RESYNC_SECONDS, padrão 30)
+}
+
+// ⚙️ Configuração lida das variáveis INGRESS_*
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Addr:           os.Getenv("INGRESS_ADDR"),
+		TLSAddr:        os.Getenv("INGRESS_TLS_ADDR"),
+		BaseDomain:     strings.Trim(strings.ToLower(os.Getenv("INGRESS_BASE_DOMAIN")), "."),
+		DefaultPort:    8080,
+		SelfContainer:  os.Getenv("INGRESS_CONTAINER"),
+		ResyncInterval: 30 * time.Second,
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = ":8000"
+	}
+	if port, err := strconv.Atoi(os.Getenv("INGRESS_DEFAULT_PORT")); err == nil && port > 0 {
+		cfg.DefaultPort = port
+	}
+	if trust, err := strconv.ParseBool(os.Getenv("INGRESS_TRUST_FORWARDED")); err == nil {
+		cfg.TrustForwarded = trust
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("INGRESS_RESYNC_SECONDS")); err == nil && seconds > 0 {
+		cfg.ResyncInterval = time.Duration(seconds) * time.Second
+	}
+	return cfg
+}
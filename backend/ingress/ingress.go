@@ -0,0 +1,423 @@
+//backend/ingress/ingress.go
+
+package ingress
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/firewall"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 🌐 Hostnames extras de uma aplicação (ex.: domínios personalizados verificados)
+type HostProvider func(app *models.App) []string
+
+// 🔒 Certificado do hostname pedido no handshake (SNI)
+type CertificateSource func(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+
+// 📁 Caminho dos desafios HTTP-01 do ACME, atendidos antes do roteamento
+const challengePath = "/.well-known/acme-challenge/"
+
+// 🚪 Proxy reverso que leva <app>.<usuário>.<domínio-base> ao container da aplicação
+type Ingress struct {
+	cfg   Config
+	table *Table
+	proxy *httputil.ReverseProxy
+
+	mu        sync.Mutex
+	providers []HostProvider
+	attached  map[string]string // redes às quais o container do backend já foi conectado → IP dele lá
+	certs     CertificateSource
+	challenge http.Handler
+}
+
+// 🏗️ Ingress com tabela vazia (Start ou Resync preenchem as rotas)
+func New(cfg Config) *Ingress {
+	if cfg.DefaultPort <= 0 {
+		cfg.DefaultPort = 8080
+	}
+	in := &Ingress{cfg: cfg, table: NewTable(), attached: map[string]string{}}
+	in.proxy = in.newReverseProxy()
+	return in
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultIngress *Ingress
+)
+
+// 🚪 Ingress ativo (nil enquanto desligado)
+func Default() *Ingress {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultIngress
+}
+
+// 🚀 Sobe o ingress: carrega as rotas, acompanha os eventos do runtime e escuta em cfg.Addr.
+// Sem BaseDomain o ingress fica desligado e Start não faz nada.
+func Start(cfg Config) (*Ingress, error) {
+	if cfg.BaseDomain == "" {
+		log.Println("🚪 Ingress desligado (INGRESS_BASE_DOMAIN vazio)")
+		return nil, nil
+	}
+
+	in := New(cfg)
+	listener, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao escutar em %s: %w", cfg.Addr, err)
+	}
+
+	defaultMu.Lock()
+	defaultIngress = in
+	defaultMu.Unlock()
+
+	in.Resync()
+	go in.Watch(context.Background())
+
+	server := &http.Server{Handler: in, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Println("❌ Ingress encerrado:", err)
+		}
+	}()
+	log.Printf("🚪 Ingress escutando em %s para *.%s", cfg.Addr, cfg.BaseDomain)
+
+	// 🔒 HTTPS: o certificado de cada hostname vem do CertificateSource (SNI)
+	if cfg.TLSAddr != "" {
+		tlsListener, err := tls.Listen("tcp", cfg.TLSAddr, &tls.Config{
+			GetCertificate: in.getCertificate,
+			MinVersion:     tls.VersionTLS12,
+			NextProtos:     []string{"h2", "http/1.1"},
+		})
+		if err != nil {
+			return in, fmt.Errorf("erro ao escutar em %s: %w", cfg.TLSAddr, err)
+		}
+		tlsServer := &http.Server{Handler: in, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := tlsServer.Serve(tlsListener); err != nil && err != http.ErrServerClosed {
+				log.Println("❌ Ingress HTTPS encerrado:", err)
+			}
+		}()
+		log.Printf("🔒 Ingress HTTPS escutando em %s", cfg.TLSAddr)
+	}
+	return in, nil
+}
+
+// 🔒 Define a fonte dos certificados servidos por SNI
+func (in *Ingress) SetCertificateSource(source CertificateSource) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.certs = source
+}
+
+// 🧩 Define quem responde /.well-known/acme-challenge/ (em qualquer hostname)
+func (in *Ingress) SetChallengeHandler(handler http.Handler) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.challenge = handler
+}
+
+func (in *Ingress) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	in.mu.Lock()
+	source := in.certs
+	in.mu.Unlock()
+	if source == nil {
+		return nil, errors.New("nenhum certificado configurado no ingress")
+	}
+	return source(hello)
+}
+
+func (in *Ingress) challengeHandler() http.Handler {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.challenge
+}
+
+// 🧩 Registra uma fonte de hostnames extras (vale para as próximas atualizações de rota)
+func (in *Ingress) AddHostProvider(provider HostProvider) {
+	in.mu.Lock()
+	in.providers = append(in.providers, provider)
+	in.mu.Unlock()
+	in.Resync()
+}
+
+// 📒 Tabela de rotas atual
+func (in *Ingress) Table() *Table {
+	return in.table
+}
+
+// ⚙️ Configuração em uso
+func (in *Ingress) Config() Config {
+	return in.cfg
+}
+
+// 🌐 Hostnames da aplicação: <id>.<usuário>.<base>, <nome>.<usuário>.<base> e os dos providers
+func (in *Ingress) AppHosts(app *models.App) []string {
+	user := UserLabel(app.Username)
+	if user == "" {
+		return nil
+	}
+	seen := map[string]bool{}
+	var hosts []string
+	add := func(host string) {
+		host = CanonicalHost(host)
+		if host != "" && !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+
+	if id := DNSLabel(app.ID); id != "" {
+		add(id + "." + user + "." + in.cfg.BaseDomain)
+	}
+	if name := DNSLabel(app.Name); name != "" {
+		add(name + "." + user + "." + in.cfg.BaseDomain)
+	}
+
+	in.mu.Lock()
+	providers := append([]HostProvider(nil), in.providers...)
+	in.mu.Unlock()
+	for _, provider := range providers {
+		for _, host := range provider(app) {
+			add(host)
+		}
+	}
+	return hosts
+}
+
+// 🔄 Recalcula as rotas de uma aplicação (container iniciado, parado, removido ou renomeado)
+func (in *Ingress) Refresh(appID string) {
+	app, ok := store.AppStore.Get(appID)
+	if !ok {
+		in.table.RemoveApp(appID)
+		return
+	}
+
+	target, err := in.resolveTarget(app)
+	if err != nil {
+		if engine.IsNotFound(err) {
+			in.table.RemoveApp(appID)
+			return
+		}
+		log.Printf("⚠️ Ingress: erro ao resolver %s: %v", appID, err)
+		return
+	}
+
+	containerName := containerOf(app)
+	var routes []Route
+	for _, host := range in.AppHosts(app) {
+		routes = append(routes, Route{
+			Host:      host,
+			AppID:     app.ID,
+			Username:  app.Username,
+			Container: containerName,
+			Target:    target,
+			Unready:   app.AwaitingReadiness(),
+		})
+	}
+	for _, host := range in.table.SetApp(app.ID, routes) {
+		log.Printf("⚠️ Ingress: hostname %s já pertence a outra aplicação (ignorado para %s)", host, app.ID)
+	}
+}
+
+// 🔄 Recalcula todas as rotas a partir do AppStore
+func (in *Ingress) Resync() {
+	known := map[string]bool{}
+	for _, app := range store.AppStore.List() {
+		known[app.ID] = true
+		in.Refresh(app.ID)
+	}
+	for _, id := range in.table.AppIDs() {
+		if !known[id] {
+			in.table.RemoveApp(id)
+		}
+	}
+}
+
+// 📡 Atualiza rotas a cada evento de container e faz varreduras periódicas até ctx terminar
+func (in *Ingress) Watch(ctx context.Context) {
+	ticker := time.NewTicker(in.cfg.ResyncInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		events, errs := engine.Default().Events(ctx)
+	stream:
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					break stream
+				}
+				in.handleEvent(ev)
+			case <-ticker.C:
+				in.Resync()
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// 🔌 Stream encerrado (daemon reiniciado?): reconecta em seguida
+		if err := <-errs; err != nil {
+			log.Println("⚠️ Ingress: stream de eventos interrompido:", err)
+		}
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+		in.Resync()
+	}
+}
+
+func (in *Ingress) handleEvent(ev engine.Event) {
+	switch ev.Action {
+	case "start", "restart", "die", "stop", "kill", "destroy", "rename", "unpause", "pause":
+	default:
+		return
+	}
+	if app, ok := store.AppStore.FindByContainer(ev.Name); ok {
+		in.Refresh(app.ID)
+		return
+	}
+	// Container sem aplicação (ex.: removido junto com ela): limpa a rota pelo nome
+	for _, route := range in.table.Routes("") {
+		if route.Container == ev.Name {
+			in.table.RemoveApp(route.AppID)
+		}
+	}
+}
+
+// 🎯 http://IP:porta do container; vazio se parado
+func (in *Ingress) resolveTarget(app *models.App) (string, error) {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	rt := engine.Default()
+	info, err := rt.Inspect(ctx, containerOf(app))
+	if err != nil {
+		return "", err
+	}
+	if !info.Running {
+		return "", nil
+	}
+
+	network := utils.GetUserNetworkName(app.Username)
+	ip := info.Networks[network]
+	if ip != "" {
+		if err := in.attachSelf(ctx, network); err != nil {
+			return "", err
+		}
+	} else {
+		// Containers criados antes das redes por usuário continuam na bridge padrão
+		names := make([]string, 0, len(info.Networks))
+		for name := range info.Networks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if info.Networks[name] != "" {
+				ip = info.Networks[name]
+				break
+			}
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("container %s sem IP", info.Name)
+	}
+
+	port := in.appPort(app, info)
+	return "http://" + net.JoinHostPort(ip, strconv.Itoa(port)), nil
+}
+
+// 🔌 Porta da aplicação: App.Port, senão o primeiro EXPOSE, senão DefaultPort (gravada em App.Port)
+func (in *Ingress) appPort(app *models.App, info *engine.Info) int {
+	if app.Port > 0 {
+		return app.Port
+	}
+	port := in.cfg.DefaultPort
+	if len(info.ExposedPorts) > 0 {
+		port = info.ExposedPorts[0]
+	}
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		if a.Port == 0 {
+			a.Port = port
+		}
+	}); err != nil {
+		log.Printf("⚠️ Ingress: erro ao gravar porta de %s: %v", app.ID, err)
+	}
+	return port
+}
+
+// 🔗 Backend em container: entra na rede do usuário para alcançar as aplicações.
+// Com o firewall ligado, o IP do backend nessa rede só aceita respostas; sem a regra ele sai da rede
+// (a rota fica indisponível em vez de expor o backend às aplicações).
+func (in *Ingress) attachSelf(ctx context.Context, network string) error {
+	if in.cfg.SelfContainer == "" {
+		return nil
+	}
+	in.mu.Lock()
+	_, done := in.attached[network]
+	in.mu.Unlock()
+	if done {
+		return nil
+	}
+
+	rt := engine.Default()
+	if err := rt.Connect(ctx, network, in.cfg.SelfContainer, nil); err != nil {
+		log.Printf("⚠️ Ingress: erro ao conectar %s à rede %s: %v", in.cfg.SelfContainer, network, err)
+		return nil
+	}
+	self, err := rt.Inspect(ctx, in.cfg.SelfContainer)
+	if err != nil {
+		return fmt.Errorf("erro ao inspecionar %s: %w", in.cfg.SelfContainer, err)
+	}
+	ip := self.Networks[network]
+	if err := firewall.GuardSelf(ip); err != nil {
+		_ = rt.Disconnect(ctx, network, in.cfg.SelfContainer)
+		return fmt.Errorf("rede %s sem a proteção do backend: %w", network, err)
+	}
+	in.mu.Lock()
+	in.attached[network] = ip
+	in.mu.Unlock()
+	return nil
+}
+
+// 🔌 Sai da rede de um usuário que vai ser removida (a rede não some com membros conectados)
+func (in *Ingress) DetachNetwork(ctx context.Context, network string) {
+	in.mu.Lock()
+	ip, attached := in.attached[network]
+	delete(in.attached, network)
+	in.mu.Unlock()
+	if in.cfg.SelfContainer == "" {
+		return
+	}
+
+	if err := engine.Default().Disconnect(ctx, network, in.cfg.SelfContainer); err != nil && !engine.IsNotFound(err) {
+		log.Printf("⚠️ Ingress: erro ao desconectar %s da rede %s: %v", in.cfg.SelfContainer, network, err)
+	}
+	if attached {
+		firewall.ReleaseSelf(ip)
+	}
+}
+
+// 🐳 Nome do container da aplicação
+func containerOf(app *models.App) string {
+	if app.ContainerName != "" {
+		return app.ContainerName
+	}
+	return utils.GetContainerName(app.Username, app.ID)
+}
@@ -0,0 +1,106 @@
+//backend/ingress/proxy.go
+
+package ingress
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type targetKey struct{}
+
+// 🚦 Encaminha a requisição para a aplicação dona do hostname
+func (in *Ingress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, challengePath) {
+		if handler := in.challengeHandler(); handler != nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	route, ok := in.table.Lookup(r.Host)
+	if !ok {
+		http.Error(w, "Aplicação não encontrada", http.StatusNotFound)
+		return
+	}
+	if route.Target == "" {
+		w.Header().Set("Retry-After", "10")
+		http.Error(w, "Aplicação parada", http.StatusServiceUnavailable)
+		return
+	}
+	if route.Unready {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "Aplicação ainda não está pronta", http.StatusServiceUnavailable)
+		return
+	}
+	target, err := url.Parse(route.Target)
+	if err != nil {
+		http.Error(w, "Rota inválida", http.StatusBadGateway)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), targetKey{}, target)
+	in.proxy.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// 🔁 Proxy reverso com suporte a WebSocket (Upgrade) e streaming (SSE)
+func (in *Ingress) newReverseProxy() *httputil.ReverseProxy {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	transport.MaxIdleConnsPerHost = 32
+
+	return &httputil.ReverseProxy{
+		Rewrite:       in.rewrite,
+		Transport:     transport,
+		FlushInterval: -1, // repassa cada escrita (SSE, respostas em streaming)
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("⚠️ Ingress: %s%s → %v", r.Host, r.URL.Path, err)
+			http.Error(w, "Aplicação indisponível", http.StatusBadGateway)
+		},
+	}
+}
+
+// 🧭 Reescreve a requisição para o container, preservando Host e preenchendo X-Forwarded-*
+func (in *Ingress) rewrite(pr *httputil.ProxyRequest) {
+	target := pr.In.Context().Value(targetKey{}).(*url.URL)
+	pr.SetURL(target)
+	pr.Out.Host = pr.In.Host // a aplicação vê o hostname público
+
+	// Atrás do nginx: mantém a cadeia recebida em vez de recomeçar do proxy
+	if in.cfg.TrustForwarded {
+		if prior := pr.In.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+			pr.Out.Header["X-Forwarded-For"] = append([]string(nil), prior...)
+		}
+	}
+	pr.SetXForwarded()
+
+	proto, port := "http", "80"
+	if pr.In.TLS != nil {
+		proto, port = "https", "443"
+	}
+	if _, p, err := net.SplitHostPort(pr.In.Host); err == nil {
+		port = p
+	}
+	if in.cfg.TrustForwarded {
+		if v := pr.In.Header.Get("X-Forwarded-Proto"); v != "" {
+			proto = v
+			port = map[string]string{"https": "443", "http": "80"}[v]
+		}
+		if v := pr.In.Header.Get("X-Forwarded-Host"); v != "" {
+			pr.Out.Header.Set("X-Forwarded-Host", v)
+		}
+		if v := pr.In.Header.Get("X-Forwarded-Port"); v != "" {
+			port = v
+		}
+	}
+	pr.Out.Header.Set("X-Forwarded-Proto", proto)
+	if port != "" {
+		pr.Out.Header.Set("X-Forwarded-Port", port)
+	}
+}
@@ -0,0 +1,159 @@
+//backend/ingress/table.go
+
+package ingress
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 🧭 Rota de um hostname para o container da aplicação
+type Route struct {
+	Host      string `json:"host"`
+	AppID     string `json:"appId"`
+	Username  string `json:"username"`
+	Container string `json:"container"`
+	Target    string `json:"target"`            // http://IP:porta; vazio = aplicação parada
+	Unready   bool   `json:"unready,omitempty"` // readiness configurado e ainda sem passar
+}
+
+// 📒 Tabela de rotas com lock interno (hostname → rota)
+type Table struct {
+	mu     sync.RWMutex
+	routes map[string]*Route
+	byApp  map[string][]string // ID da aplicação → hostnames
+}
+
+func NewTable() *Table {
+	return &Table{routes: map[string]*Route{}, byApp: map[string][]string{}}
+}
+
+// 🔍 Rota do hostname (sem porta, sem diferenciar maiúsculas)
+func (t *Table) Lookup(host string) (Route, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	route, ok := t.routes[CanonicalHost(host)]
+	if !ok {
+		return Route{}, false
+	}
+	return *route, true
+}
+
+// ✏️ Substitui as rotas da aplicação. Hostnames já usados por outra aplicação ficam com a dona atual
+// e são devolvidos em conflicts.
+func (t *Table) SetApp(appID string, routes []Route) (conflicts []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.removeLocked(appID)
+	var hosts []string
+	for _, route := range routes {
+		route.Host = CanonicalHost(route.Host)
+		if current, taken := t.routes[route.Host]; taken && current.AppID != appID {
+			conflicts = append(conflicts, route.Host)
+			continue
+		}
+		r := route
+		t.routes[r.Host] = &r
+		hosts = append(hosts, r.Host)
+	}
+	if len(hosts) > 0 {
+		t.byApp[appID] = hosts
+	}
+	return conflicts
+}
+
+// 🗑️ Remove todas as rotas da aplicação
+func (t *Table) RemoveApp(appID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeLocked(appID)
+}
+
+func (t *Table) removeLocked(appID string) {
+	for _, host := range t.byApp[appID] {
+		if route, ok := t.routes[host]; ok && route.AppID == appID {
+			delete(t.routes, host)
+		}
+	}
+	delete(t.byApp, appID)
+}
+
+// 🆔 IDs das aplicações com rota
+func (t *Table) AppIDs() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ids := make([]string, 0, len(t.byApp))
+	for id := range t.byApp {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// 📋 Cópia das rotas (filtradas por usuário quando username != ""), ordenadas por hostname
+func (t *Table) Routes(username string) []Route {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	out := make([]Route, 0, len(t.routes))
+	for _, route := range t.routes {
+		if username == "" || strings.EqualFold(route.Username, username) {
+			out = append(out, *route)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
+// 🔤 "App.Exemplo.com:443" → "app.exemplo.com"
+func CanonicalHost(host string) string {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// 🏷️ Rótulo DNS a partir de um nome livre ("Meu Bot_1" → "meu-bot-1"); vazio se nada sobrar
+func DNSLabel(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	label := strings.TrimSuffix(b.String(), "-")
+	if len(label) > 63 {
+		label = strings.TrimSuffix(label[:63], "-")
+	}
+	return label
+}
+
+// 🏷️ Rótulo do usuário nos hostnames gerados. Username que já é um rótulo DNS fica igual; os demais
+// ("John.Doe", "john_doe") viram rótulo + "--" + hash do username original. DNSLabel nunca gera "--",
+// então os dois grupos não se cruzam e usernames diferentes nunca disputam o mesmo hostname.
+func UserLabel(username string) string {
+	label := DNSLabel(username)
+	if label == username {
+		return label
+	}
+	sum := sha256.Sum256([]byte(username))
+	suffix := hex.EncodeToString(sum[:])[:10]
+	if max := 63 - len(suffix) - 2; len(label) > max {
+		label = strings.TrimSuffix(label[:max], "-")
+	}
+	if label == "" {
+		return "u--" + suffix
+	}
+	return label + "--" + suffix
+}
@@ -0,0 +1,73 @@
+//backend/ingress/table_test.go
+
+package ingress
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserLabelKeepsValidUsernames(t *testing.T) {
+	for _, username := range []string{"alice", "bob-2", "a1b2c3"} {
+		if got := UserLabel(username); got != username {
+			t.Fatalf("UserLabel(%q) = %q, esperado o próprio username", username, got)
+		}
+	}
+}
+
+func TestUserLabelSeparatesNormalizedUsernames(t *testing.T) {
+	// 🔤 Todos normalizam para "john-doe" com DNSLabel
+	usernames := []string{"john-doe", "John-Doe", "john.doe", "john_doe", "john..doe", "JOHN DOE"}
+	seen := map[string]string{}
+	for _, username := range usernames {
+		label := UserLabel(username)
+		if other, dup := seen[label]; dup {
+			t.Fatalf("%q e %q caem no mesmo rótulo %q", other, username, label)
+		}
+		seen[label] = username
+		if label != DNSLabel(label) && !strings.Contains(label, "--") {
+			t.Fatalf("rótulo inválido para %q: %q", username, label)
+		}
+		if UserLabel(username) != label {
+			t.Fatalf("rótulo de %q não é estável", username)
+		}
+	}
+}
+
+func TestUserLabelTruncatesLongUsernames(t *testing.T) {
+	long := strings.Repeat("a", 63)
+	a, b := UserLabel(long+"x"), UserLabel(long+"y")
+	if a == b {
+		t.Fatalf("usernames longos diferentes no mesmo rótulo %q", a)
+	}
+	for _, label := range []string{a, b, UserLabel("..."), UserLabel("Ünïcode")} {
+		if len(label) == 0 || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			t.Fatalf("rótulo fora do padrão DNS: %q", label)
+		}
+	}
+}
+
+func TestTableKeepsHostWithCurrentOwner(t *testing.T) {
+	table := NewTable()
+	if conflicts := table.SetApp("a1", []Route{{Host: "Web.Alice.Example.com:443", AppID: "a1", Username: "alice"}}); len(conflicts) != 0 {
+		t.Fatalf("conflitos inesperados: %v", conflicts)
+	}
+	conflicts := table.SetApp("b1", []Route{
+		{Host: "web.alice.example.com", AppID: "b1", Username: "bob"},
+		{Host: "b1.bob.example.com", AppID: "b1", Username: "bob"},
+	})
+	if len(conflicts) != 1 || conflicts[0] != "web.alice.example.com" {
+		t.Fatalf("conflitos = %v, esperado o hostname da alice", conflicts)
+	}
+	if route, _ := table.Lookup("web.alice.example.com"); route.AppID != "a1" {
+		t.Fatalf("hostname mudou de dono: %+v", route)
+	}
+	if _, ok := table.Lookup("b1.bob.example.com"); !ok {
+		t.Fatalf("hostname livre não registrado")
+	}
+
+	table.RemoveApp("a1")
+	if _, ok := table.Lookup("web.alice.example.com"); ok {
+		t.Fatalf("rota continua após remover a aplicação")
+	}
+}
@@ -0,0 +1,315 @@
+//backend/limits/allocation.go
+
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🔒 Serializa reservas e redimensionamentos (evita dois deploys simultâneos dividirem a mesma sobra)
+var allocationMu sync.Mutex
+
+// 📐 Fatia de uma aplicação no ledger
+type AppAllocation struct {
+	AppID  string  `json:"id"`
+	Name   string  `json:"name"`
+	Status string  `json:"status"`
+	RAMMB  int     `json:"ramMB"`
+	CPUs   float32 `json:"cpus"`
+}
+
+// 📒 Ledger de alocação do usuário: tudo o que está reservado, rodando ou não
+type Ledger struct {
+	Username       string          `json:"username"`
+	Plan           models.PlanType `json:"plan"`
+	TotalRAMMB     int             `json:"totalRamMB"`
+	TotalCPUs      float32         `json:"totalCpus"`
+	AllocatedRAMMB int             `json:"allocatedRamMB"`
+	AllocatedCPUs  float32         `json:"allocatedCpus"`
+	MinAppRAMMB    int             `json:"minAppRamMB"`
+	MinAppCPUs     float32         `json:"minAppCpus"`
+	Apps           []AppAllocation `json:"apps"`
+}
+
+// 💾 RAM ainda não reservada (MB)
+func (l *Ledger) FreeRAMMB() int {
+	if free := l.TotalRAMMB - l.AllocatedRAMMB; free > 0 {
+		return free
+	}
+	return 0
+}
+
+// 🧠 CPU ainda não reservada (vCPU)
+func (l *Ledger) FreeCPUs() float32 {
+	if free := roundCPUs(l.TotalCPUs - l.AllocatedCPUs); free > 0 {
+		return free
+	}
+	return 0
+}
+
+// 📒 Monta o ledger do usuário a partir do AppStore
+func UserLedger(username string) (*Ledger, error) {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return nil, fmt.Errorf("usuário não encontrado: %s", username)
+	}
+	plan, ok := models.Plans[user.Plan]
+	if !ok {
+		return nil, fmt.Errorf("plano inválido: %s", user.Plan)
+	}
+	return buildLedger(username, plan, ""), nil
+}
+
+// 📒 Ledger do plano, ignorando a aplicação exceptID (usado ao redimensioná-la)
+func buildLedger(username string, plan models.Plan, exceptID string) *Ledger {
+	ledger := &Ledger{
+		Username:    username,
+		Plan:        plan.Name,
+		TotalRAMMB:  plan.MemoryMB,
+		TotalCPUs:   plan.CPUvCores,
+		MinAppRAMMB: plan.PerAppMB,
+		MinAppCPUs:  models.MinAppCPUs,
+		Apps:        []AppAllocation{},
+	}
+	for _, app := range store.AppStore.ListByUser(username) {
+		if app.ID == exceptID {
+			continue
+		}
+		ramMB, cpus := appAllocation(app, plan)
+		ledger.AllocatedRAMMB += ramMB
+		ledger.AllocatedCPUs += cpus
+		ledger.Apps = append(ledger.Apps, AppAllocation{
+			AppID:  app.ID,
+			Name:   app.Name,
+			Status: string(app.Status),
+			RAMMB:  ramMB,
+			CPUs:   cpus,
+		})
+	}
+	ledger.AllocatedCPUs = roundCPUs(ledger.AllocatedCPUs)
+	return ledger
+}
+
+// 📐 Alocação efetiva da aplicação (apps sem alocação gravada ocupam a fatia padrão do plano)
+func appAllocation(app *models.App, plan models.Plan) (int, float32) {
+	defaultRAM, defaultCPUs := plan.DefaultAppAllocation()
+	ramMB, cpus := app.AllocatedRAMMB, app.AllocatedCPUs
+	if ramMB <= 0 {
+		ramMB = defaultRAM
+	}
+	if cpus <= 0 {
+		cpus = defaultCPUs
+	}
+	return ramMB, cpus
+}
+
+// 🧾 Reserva a fatia padrão do plano para uma nova aplicação e a grava no AppStore
+func AllocateNewApp(app *models.App) error {
+	allocationMu.Lock()
+	defer allocationMu.Unlock()
+
+	user, _ := store.UserStore.Get(app.Username)
+	if user == nil {
+		return fmt.Errorf("usuário não encontrado: %s", app.Username)
+	}
+	plan, ok := models.Plans[user.Plan]
+	if !ok {
+		return fmt.Errorf("plano inválido: %s", user.Plan)
+	}
+
+	ledger := buildLedger(app.Username, plan, app.ID)
+	ramMB, cpus := plan.DefaultAppAllocation()
+	if ledger.FreeRAMMB() < ramMB {
+		return fmt.Errorf("RAM insuficiente: %dMB livres de %dMB (mínimo %dMB por aplicação)",
+			ledger.FreeRAMMB(), ledger.TotalRAMMB, ramMB)
+	}
+	if free := ledger.FreeCPUs(); cpus > free {
+		if free < models.MinAppCPUs {
+			return fmt.Errorf("CPU insuficiente: %.2f vCPU livres (mínimo %.2f por aplicação)", free, models.MinAppCPUs)
+		}
+		cpus = free
+	}
+
+	app.AllocatedRAMMB = ramMB
+	app.AllocatedCPUs = cpus
+	if err := store.AppStore.Save(app); err != nil {
+		return fmt.Errorf("erro ao salvar aplicação: %w", err)
+	}
+
+	log.Printf("📐 Alocação de %s/%s: %dMB | %.2f vCPU (livre: %dMB | %.2f vCPU)",
+		app.Username, app.ID, ramMB, cpus, ledger.FreeRAMMB()-ramMB, ledger.FreeCPUs()-cpus)
+	return nil
+}
+
+// 🧮 Grava a fatia padrão do plano nas aplicações sem alocação registrada. Vale para qualquer backend:
+// o JSON já passa pela migração 3 de apps, mas registros antigos em SQLite/PostgreSQL chegam aqui sem ela.
+func BackfillAllocations() error {
+	allocationMu.Lock()
+	defer allocationMu.Unlock()
+
+	var errs []error
+	filled := 0
+	for _, app := range store.AppStore.List() {
+		if app.AllocatedRAMMB > 0 {
+			continue
+		}
+		user, _ := store.UserStore.Get(app.Username)
+		if user == nil {
+			continue
+		}
+		plan, ok := models.Plans[user.Plan]
+		if !ok {
+			continue
+		}
+		ramMB, cpus := appAllocation(app, plan)
+		if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+			a.AllocatedRAMMB = ramMB
+			a.AllocatedCPUs = cpus
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", app.ID, err))
+			continue
+		}
+		filled++
+	}
+	if filled > 0 {
+		log.Printf("🧮 Alocação padrão gravada em %d aplicações sem fatia registrada", filled)
+	}
+	return errors.Join(errs...)
+}
+
+// ✏️ Altera a fatia de uma aplicação, mantendo a soma do usuário dentro do plano
+func ResizeAllocation(username, appID string, ramMB int, cpus float32) (*models.App, error) {
+	allocationMu.Lock()
+	defer allocationMu.Unlock()
+
+	app, _ := store.AppStore.Get(appID)
+	if app == nil || app.Username != username {
+		return nil, fmt.Errorf("aplicação não encontrada")
+	}
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return nil, fmt.Errorf("usuário não encontrado: %s", username)
+	}
+	plan, ok := models.Plans[user.Plan]
+	if !ok {
+		return nil, fmt.Errorf("plano inválido: %s", user.Plan)
+	}
+
+	cpus = roundCPUs(cpus)
+	if ramMB < plan.PerAppMB {
+		return nil, fmt.Errorf("RAM mínima por aplicação: %dMB", plan.PerAppMB)
+	}
+	if cpus < models.MinAppCPUs {
+		return nil, fmt.Errorf("CPU mínima por aplicação: %.2f vCPU", models.MinAppCPUs)
+	}
+
+	others := buildLedger(username, plan, appID)
+	if ramMB > others.FreeRAMMB() {
+		return nil, fmt.Errorf("RAM indisponível: máximo de %dMB para esta aplicação", others.FreeRAMMB())
+	}
+	if cpus > others.FreeCPUs() {
+		return nil, fmt.Errorf("CPU indisponível: máximo de %.2f vCPU para esta aplicação", others.FreeCPUs())
+	}
+
+	return store.AppStore.Update(appID, func(a *models.App) {
+		a.AllocatedRAMMB = ramMB
+		a.AllocatedCPUs = cpus
+	})
+}
+
+// 📉 Encaixa as alocações no plano atual (ex.: após downgrade), reduzindo todas na mesma proporção.
+// Upgrades não mudam nada: a sobra fica livre para novos deploys ou redimensionamentos.
+func FitAllocationsToPlan(username string) error {
+	allocationMu.Lock()
+	defer allocationMu.Unlock()
+
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return fmt.Errorf("usuário não encontrado: %s", username)
+	}
+	plan, ok := models.Plans[user.Plan]
+	if !ok {
+		return fmt.Errorf("plano inválido: %s", user.Plan)
+	}
+
+	ledger := buildLedger(username, plan, "")
+	ramScale, cpuScale := 1.0, 1.0
+	if ledger.AllocatedRAMMB > ledger.TotalRAMMB && ledger.AllocatedRAMMB > 0 {
+		ramScale = float64(ledger.TotalRAMMB) / float64(ledger.AllocatedRAMMB)
+	}
+	if ledger.AllocatedCPUs > ledger.TotalCPUs && ledger.AllocatedCPUs > 0 {
+		cpuScale = float64(ledger.TotalCPUs) / float64(ledger.AllocatedCPUs)
+	}
+	if ramScale == 1 && cpuScale == 1 {
+		return nil
+	}
+
+	var totalRAM int
+	var totalCPUs float32
+	for _, a := range ledger.Apps {
+		ramMB, cpus := a.RAMMB, a.CPUs
+		if ramScale < 1 {
+			ramMB = int(math.Floor(float64(a.RAMMB) * ramScale))
+			if ramMB < plan.PerAppMB {
+				ramMB = plan.PerAppMB
+			}
+		}
+		if cpuScale < 1 {
+			cpus = float32(math.Floor(float64(a.CPUs)*cpuScale*100) / 100)
+			if cpus < models.MinAppCPUs {
+				cpus = models.MinAppCPUs
+			}
+		}
+		totalRAM += ramMB
+		totalCPUs += cpus
+
+		if _, err := store.AppStore.Update(a.AppID, func(app *models.App) {
+			app.AllocatedRAMMB = ramMB
+			app.AllocatedCPUs = cpus
+		}); err != nil {
+			return fmt.Errorf("erro ao ajustar alocação de %s: %w", a.AppID, err)
+		}
+	}
+
+	log.Printf("📉 Alocações de %s ajustadas ao plano %s: %dMB/%dMB | %.2f/%.2f vCPU",
+		username, plan.Name, totalRAM, plan.MemoryMB, totalCPUs, plan.CPUvCores)
+	if totalRAM > plan.MemoryMB || roundCPUs(totalCPUs) > plan.CPUvCores {
+		log.Printf("⚠️ %s tem mais aplicações do que o plano %s comporta no mínimo; novos deploys ficam bloqueados", username, plan.Name)
+	}
+	return nil
+}
+
+// ⚙️ Limites do container da aplicação: fatia alocada + PIDs, nofile e I/O do plano.
+// ref é o ID da aplicação ou o nome do container; sem app cadastrada vale a fatia padrão.
+func AppContainerResources(username, ref string) (engine.Resources, error) {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return engine.Resources{}, fmt.Errorf("usuário não encontrado: %s", username)
+	}
+	plan, ok := models.Plans[user.Plan]
+	if !ok {
+		return engine.Resources{}, fmt.Errorf("plano inválido: %s", user.Plan)
+	}
+
+	resources := ContainerResources(plan)
+	ramMB, cpus := plan.DefaultAppAllocation()
+	if app, found := store.AppStore.FindByContainer(ref); found && app.Username == username {
+		ramMB, cpus = appAllocation(app, plan)
+	}
+	resources.MemoryMB = ramMB
+	resources.CPUs = float64(cpus)
+	return resources, nil
+}
+
+// 🔢 Arredonda vCPU em centésimos (evita resíduos de float32 nas somas)
+func roundCPUs(v float32) float32 {
+	return float32(math.Round(float64(v)*100) / 100)
+}
@@ -0,0 +1,135 @@
+//backend/limits/allocation_test.go
+
+package limits
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/store"
+)
+
+// 🧪 Stores JSON numa pasta temporária e runtime em memória
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "limits-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	persistence.SetBackend(persistence.NewJSONBackend())
+	engine.SetDefault(engine.NewFake())
+	for _, err := range []error{
+		store.UserStore.Load("./database/users.json"),
+		store.LoadAppStoreFromDisk("./database/appstore.json"),
+	} {
+		if err != nil {
+			fmt.Println("erro ao carregar stores:", err)
+			return 1
+		}
+	}
+	return m.Run()
+}
+
+func saveUser(t *testing.T, username string, plan models.PlanType) {
+	t.Helper()
+	if err := store.UserStore.Save(&models.User{Username: username, Plan: plan}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+}
+
+func saveApp(t *testing.T, app *models.App) {
+	t.Helper()
+	if err := store.AppStore.Save(app); err != nil {
+		t.Fatalf("erro ao salvar aplicação: %v", err)
+	}
+}
+
+func TestBackfillAllocationsFillsOnlyMissing(t *testing.T) {
+	saveUser(t, "backfill", models.PlanPro)
+	saveApp(t, &models.App{ID: "bf-old", Username: "backfill", ContainerName: "backfill-bf-old"})
+	saveApp(t, &models.App{ID: "bf-sized", Username: "backfill", ContainerName: "backfill-bf-sized", AllocatedRAMMB: 1024, AllocatedCPUs: 1})
+
+	if err := BackfillAllocations(); err != nil {
+		t.Fatalf("BackfillAllocations: %v", err)
+	}
+
+	ramMB, cpus := models.Plans[models.PlanPro].DefaultAppAllocation()
+	old, _ := store.AppStore.Get("bf-old")
+	if old.AllocatedRAMMB != ramMB || old.AllocatedCPUs != cpus {
+		t.Fatalf("alocação gravada = %dMB/%.2f, esperado %dMB/%.2f", old.AllocatedRAMMB, old.AllocatedCPUs, ramMB, cpus)
+	}
+	sized, _ := store.AppStore.Get("bf-sized")
+	if sized.AllocatedRAMMB != 1024 || sized.AllocatedCPUs != 1 {
+		t.Fatalf("alocação existente alterada: %dMB/%.2f", sized.AllocatedRAMMB, sized.AllocatedCPUs)
+	}
+}
+
+func TestCheckDeployAdmissionUsesLedger(t *testing.T) {
+	saveUser(t, "ledger", models.PlanTest)
+	if err := CheckDeployAdmission("ledger"); err != nil {
+		t.Fatalf("plano vazio deveria admitir deploy: %v", err)
+	}
+
+	// 💤 Aplicação parada e sem container: não usa RAM agora, mas a fatia continua reservada
+	saveApp(t, &models.App{ID: "ledger-1", Username: "ledger", ContainerName: "ledger-ledger-1", Status: models.StatusStopped})
+	if err := CheckDeployAdmission("ledger"); err == nil {
+		t.Fatalf("fatia reservada deveria bloquear novo deploy")
+	}
+}
+
+func TestResizeAllocationStaysWithinPlan(t *testing.T) {
+	saveUser(t, "resize", models.PlanBasic)
+	plan := models.Plans[models.PlanBasic]
+	saveApp(t, &models.App{ID: "rs-1", Username: "resize", AllocatedRAMMB: plan.PerAppMB, AllocatedCPUs: models.MinAppCPUs})
+	saveApp(t, &models.App{ID: "rs-2", Username: "resize", AllocatedRAMMB: plan.PerAppMB, AllocatedCPUs: models.MinAppCPUs})
+
+	if _, err := ResizeAllocation("resize", "rs-1", plan.MemoryMB, models.MinAppCPUs); err == nil {
+		t.Fatalf("redimensionar acima da sobra deveria falhar")
+	}
+	app, err := ResizeAllocation("resize", "rs-1", plan.MemoryMB-plan.PerAppMB, models.MinAppCPUs)
+	if err != nil {
+		t.Fatalf("ResizeAllocation: %v", err)
+	}
+	if app.AllocatedRAMMB != plan.MemoryMB-plan.PerAppMB {
+		t.Fatalf("RAM = %dMB, esperado %dMB", app.AllocatedRAMMB, plan.MemoryMB-plan.PerAppMB)
+	}
+	if _, err := ResizeAllocation("other", "rs-1", plan.PerAppMB, models.MinAppCPUs); err == nil {
+		t.Fatalf("redimensionar aplicação de outro usuário deveria falhar")
+	}
+}
+
+func TestAppContainerResourcesUsesAllocation(t *testing.T) {
+	saveUser(t, "slice", models.PlanPro)
+	saveApp(t, &models.App{ID: "sl-1", Username: "slice", ContainerName: "slice-sl-1", AllocatedRAMMB: 768, AllocatedCPUs: 0.75})
+
+	for _, ref := range []string{"sl-1", "slice-sl-1"} {
+		resources, err := AppContainerResources("slice", ref)
+		if err != nil {
+			t.Fatalf("AppContainerResources(%s): %v", ref, err)
+		}
+		if resources.MemoryMB != 768 || resources.CPUs != float64(float32(0.75)) {
+			t.Fatalf("%s: %dMB/%.2f, esperado a fatia 768MB/0.75", ref, resources.MemoryMB, resources.CPUs)
+		}
+	}
+}
@@ -6,26 +6,27 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"virtuscloud/backend/engine"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/store"
 )
 
 // 📦 Conta quantos containers ativos pertencem ao usuário
 func CountUserContainers(username string) int {
-	cmd := exec.Command("docker", "ps", "-a", "--format", "{{.Names}}")
-	output, err := cmd.Output()
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	containers, err := engine.Default().List(ctx, engine.ListOptions{All: true})
 	if err != nil {
 		log.Printf("⚠️ Erro ao listar aplicações: %v", err)
 		return 0
 	}
 
-	lines := strings.Split(string(output), "\n")
 	count := 0
-	for _, name := range lines {
-		if strings.HasPrefix(name, username+"-") {
+	for _, c := range containers {
+		if strings.HasPrefix(c.Name, username+"-") {
 			count++
 		}
 	}
@@ -63,28 +64,37 @@ func CountUserApps(username string, plan string) int {
 
 // 🚦 Verifica se o usuário pode fazer novo deploy
 func IsUserEligibleForDeploy(username string, planName string) error {
-	user := store.UserStore[username]
+	err := CheckDeployAdmission(username)
+	log.Printf("[Deploy Check] Usuário: %s | Plano: %s | Resultado: %v\n", username, planName, err)
+	return err
+}
+
+// 🚦 Regra única de admissão de deploy (o deploy e as telas de status usam a mesma): vagas do plano
+// e RAM reservada no ledger (apps paradas continuam ocupando a fatia), nunca o uso atual
+func CheckDeployAdmission(username string) error {
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		return fmt.Errorf("usuário não encontrado")
 	}
 
 	plan := models.Plans[user.Plan]
 	appCount := CountUserContainers(username)
-	ramUsed := SumUserRAM(username) // já em MB
 
-	log.Printf("[Deploy Check] Usuário: %s | Plano: %s | Apps: %d | RAM: %.2fMB\n",
-		username, plan.Name, appCount, ramUsed)
+	ledger, err := UserLedger(username)
+	if err != nil {
+		return err
+	}
 
 	// 🚫 Limite de aplicações
 	if appCount >= plan.MaxProjects {
 		return fmt.Errorf("limite de %d aplicações atingido para o plano '%s'", plan.MaxProjects, plan.Name)
 	}
 
-	// 🔧 Corrige cálculo de RAM disponível
-	totalMB := float32(plan.MemoryMB) // MemoryMB já está em MB
-
-	if totalMB-ramUsed < float32(plan.PerAppMB) {
-		return fmt.Errorf("RAM insuficiente (mínimo %dMB por aplicação)", plan.PerAppMB)
+	if ledger.FreeRAMMB() < plan.PerAppMB {
+		return fmt.Errorf("RAM insuficiente (mínimo %dMB por aplicação, %dMB livres no plano)", plan.PerAppMB, ledger.FreeRAMMB())
+	}
+	if ledger.FreeCPUs() < models.MinAppCPUs {
+		return fmt.Errorf("CPU insuficiente (mínimo %.2f vCPU por aplicação, %.2f livres no plano)", models.MinAppCPUs, ledger.FreeCPUs())
 	}
 
 	return nil
@@ -7,7 +7,7 @@ import "virtuscloud/backend/store"
 // SumUserCPU soma o uso de CPU (%) de todas as aplicações de um usuário
 func SumUserCPU(username string) float32 {
 	var total float32
-	for _, app := range store.AppStore {
+	for _, app := range store.AppStore.ListByUser(username) {
 		if app.Username == username {
 			total += app.CPUUsage
 		}
@@ -0,0 +1,57 @@
+//backend/limits/databases.go
+
+package limits
+
+import (
+	"fmt"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🗄️ Bancos gerenciados do plano: quantidade, maior tamanho e RAM de cada container
+func DatabaseQuota(username string) (maxDatabases, maxGB, memoryMB int, err error) {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return 0, 0, 0, fmt.Errorf("usuário não encontrado")
+	}
+	plan := models.Plans[user.Plan]
+	return plan.MaxDatabases, plan.DatabaseMaxGB, plan.DatabaseMemoryMB, nil
+}
+
+// 🗄️ Verifica se o usuário pode ter mais um banco de sizeGB (current = bancos que ele já tem)
+func CanCreateDatabase(username string, current, sizeGB int) error {
+	maxDatabases, _, _, err := DatabaseQuota(username)
+	if err != nil {
+		return err
+	}
+	user, _ := store.UserStore.Get(username)
+	if maxDatabases <= 0 {
+		return fmt.Errorf("o plano '%s' não inclui bancos gerenciados", user.Plan)
+	}
+	if current >= maxDatabases {
+		return fmt.Errorf("limite de %d bancos gerenciados atingido para o plano '%s'", maxDatabases, user.Plan)
+	}
+	return CanSizeDatabase(username, sizeGB)
+}
+
+// 📏 Verifica se o plano permite um banco de sizeGB
+func CanSizeDatabase(username string, sizeGB int) error {
+	_, maxGB, _, err := DatabaseQuota(username)
+	if err != nil {
+		return err
+	}
+	if sizeGB > maxGB {
+		user, _ := store.UserStore.Get(username)
+		return fmt.Errorf("o plano '%s' permite bancos de até %d GB", user.Plan, maxGB)
+	}
+	return nil
+}
+
+// 💾 Backups diários automáticos do plano (sem eles só o backup manual mais recente é mantido)
+func DailyDatabaseBackups(username string) (bool, error) {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return false, fmt.Errorf("usuário não encontrado")
+	}
+	return models.Plans[user.Plan].DailyBackups, nil
+}
@@ -0,0 +1,26 @@
+//backend/limits/domains.go
+
+package limits
+
+import (
+	"fmt"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🌍 Verifica se o usuário pode cadastrar mais um domínio personalizado
+func CanAddCustomDomain(username string, currentDomainCount int) error {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return fmt.Errorf("usuário não encontrado")
+	}
+	if !HasFeature(username, "custom-domain") {
+		return fmt.Errorf("o plano '%s' não inclui domínios personalizados", user.Plan)
+	}
+
+	plan := models.Plans[user.Plan]
+	if currentDomainCount >= plan.MaxCustomDomains {
+		return fmt.Errorf("limite de %d domínios personalizados atingido para o plano '%s'", plan.MaxCustomDomains, plan.Name)
+	}
+	return nil
+}
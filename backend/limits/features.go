@@ -9,7 +9,7 @@ import (
 
 // 🔐 Verifica se o usuário tem acesso a uma funcionalidade específica
 func HasFeature(username string, feature string) bool {
-	user := store.UserStore[username] // ✅ corrigido para usar username
+	user, _ := store.UserStore.Get(username) // ✅ corrigido para usar username
 	if user == nil {
 		return false
 	}
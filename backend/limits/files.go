@@ -0,0 +1,23 @@
+//backend/limits/files.go
+
+package limits
+
+import (
+	"fmt"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🗂️ Maior arquivo (em MB) que o usuário pode abrir ou gravar pelo gerenciador de arquivos
+func FileManagerMaxMB(username string) (int, error) {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return 0, fmt.Errorf("usuário não encontrado")
+	}
+
+	plan := models.Plans[user.Plan]
+	if plan.FileManagerMaxMB <= 0 {
+		return 0, fmt.Errorf("o plano '%s' não inclui gerenciador de arquivos", user.Plan)
+	}
+	return plan.FileManagerMaxMB, nil
+}
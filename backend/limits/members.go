@@ -10,7 +10,7 @@ import (
 
 // 👥 Verifica se o usuário pode adicionar mais membros ao projeto
 func CanAddMember(username string, currentMemberCount int) error {
-	user := store.UserStore[username] // ✅ corrigido para usar username
+	user, _ := store.UserStore.Get(username) // ✅ corrigido para usar username
 	if user == nil {
 		return fmt.Errorf("usuário não encontrado")
 	}
@@ -7,7 +7,7 @@ import "virtuscloud/backend/store"
 // 💾 Soma a RAM utilizada por todas as aplicações do usuário (em MB)
 func SumUserRAM(username string) float32 {
 	var total float32
-	for _, app := range store.AppStore {
+	for _, app := range store.AppStore.ListByUser(username) {
 		if app.Username == username {
 			total += app.RAMUsage // ✅ já está em MB
 		}
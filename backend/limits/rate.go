@@ -9,7 +9,7 @@ import (
 
 // 📈 Verifica se o usuário excedeu o limite diário de requisições
 func IsRateLimitExceeded(username string, currentCount int) bool {
-	user := store.UserStore[username] // ✅ corrigido para usar username
+	user, _ := store.UserStore.Get(username) // ✅ corrigido para usar username
 	if user == nil {
 		return true
 	}
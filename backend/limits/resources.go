@@ -0,0 +1,34 @@
+//backend/limits/resources.go
+
+package limits
+
+import (
+	"fmt"
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// ⚙️ Limites de container derivados do plano (RAM, CPU, PIDs, nofile e peso de I/O)
+func ContainerResources(plan models.Plan) engine.Resources {
+	return engine.Resources{
+		MemoryMB:    plan.MemoryMB,
+		CPUs:        float64(plan.CPUvCores),
+		PidsLimit:   plan.PidsLimit,
+		NoFile:      plan.NoFileLimit,
+		BlkioWeight: plan.BlkioWeight,
+	}
+}
+
+// ⚙️ Limites de container do plano atual do usuário
+func UserContainerResources(username string) (engine.Resources, error) {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return engine.Resources{}, fmt.Errorf("usuário não encontrado: %s", username)
+	}
+	plan, ok := models.Plans[user.Plan]
+	if !ok {
+		return engine.Resources{}, fmt.Errorf("plano inválido: %s", user.Plan)
+	}
+	return ContainerResources(plan), nil
+}
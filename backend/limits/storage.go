@@ -10,7 +10,7 @@ import (
 
 // 🧮 Verifica se o usuário pode fazer upload de blob
 func CanUploadBlob(username string, currentUsageGB int) error {
-	user := store.UserStore[username] // ✅ corrigido para usar username
+	user, _ := store.UserStore.Get(username) // ✅ corrigido para usar username
 	if user == nil {
 		return fmt.Errorf("usuário não encontrado")
 	}
@@ -0,0 +1,26 @@
+//backend/limits/terminal.go
+
+package limits
+
+import (
+	"fmt"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🖥️ Verifica se o usuário pode abrir mais um terminal web (active = sessões abertas agora)
+func CanOpenTerminal(username string, active int) error {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return fmt.Errorf("usuário não encontrado")
+	}
+
+	plan := models.Plans[user.Plan]
+	if plan.TerminalSessions <= 0 {
+		return fmt.Errorf("o plano '%s' não inclui terminal web", user.Plan)
+	}
+	if active >= plan.TerminalSessions {
+		return fmt.Errorf("limite de %d terminais simultâneos atingido para o plano '%s'", plan.TerminalSessions, plan.Name)
+	}
+	return nil
+}
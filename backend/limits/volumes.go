@@ -0,0 +1,35 @@
+//backend/limits/volumes.go
+
+package limits
+
+import (
+	"fmt"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 💽 Armazenamento do plano: incluído sem custo (BlobFreeGBMax) e teto total (BlobLimitGB)
+func VolumeQuota(username string) (freeGB, limitGB int, err error) {
+	user, _ := store.UserStore.Get(username)
+	if user == nil {
+		return 0, 0, fmt.Errorf("usuário não encontrado")
+	}
+	plan := models.Plans[user.Plan]
+	return plan.BlobFreeGBMax, plan.BlobLimitGB, nil
+}
+
+// 💽 Verifica se o usuário pode reservar mais requestedGB em volumes (reservedGB = cotas já reservadas)
+func CanReserveVolume(username string, reservedGB, requestedGB int) error {
+	_, limitGB, err := VolumeQuota(username)
+	if err != nil {
+		return err
+	}
+	user, _ := store.UserStore.Get(username)
+	if limitGB <= 0 {
+		return fmt.Errorf("o plano '%s' não inclui volumes persistentes", user.Plan)
+	}
+	if reservedGB+requestedGB > limitGB {
+		return fmt.Errorf("limite de armazenamento excedido para o plano '%s': %d de %d GB já reservados", user.Plan, reservedGB, limitGB)
+	}
+	return nil
+}
@@ -3,15 +3,32 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"time"
-	"virtuscloud/backend/handlers"   // ✅ novo import para debug
-	"virtuscloud/backend/middleware" // 🔐 autenticação e controle de acesso
-	"virtuscloud/backend/routes"     // 🚦 definição das rotas da API
-	"virtuscloud/backend/services"   // 🧠 lógica de negócio e integração
-	"virtuscloud/backend/store"      // 🗃️ persistência de usuários e sessões
-	"virtuscloud/backend/tools"      // 🐳 watchdog e monitoramento de containers
+	"virtuscloud/backend/acme"        // 🔐 certificados TLS automáticos (ACME)
+	"virtuscloud/backend/applogs"     // 🧺 logs dos containers guardados em disco
+	"virtuscloud/backend/audit"       // 🛡️ log de auditoria append-only
+	"virtuscloud/backend/databases"   // 🗄️ bancos gerenciados em containers
+	"virtuscloud/backend/db"          // 🛢️ backend SQL e migrações
+	"virtuscloud/backend/domains"     // 🌍 domínios personalizados com verificação DNS
+	"virtuscloud/backend/engine"      // 🧱 runtime de containers (Docker, Podman ou fake)
+	"virtuscloud/backend/firewall"    // 🧱 iptables das redes de usuário
+	"virtuscloud/backend/handlers"    // ✅ novo import para debug
+	"virtuscloud/backend/health"      // 🩺 probes de liveness/readiness
+	"virtuscloud/backend/ingress"     // 🚪 proxy reverso das aplicações
+	"virtuscloud/backend/limits"      // 📐 limites e alocações do plano
+	"virtuscloud/backend/middleware"  // 🔐 autenticação e controle de acesso
+	"virtuscloud/backend/models"      // 📦 modelos e sessões
+	"virtuscloud/backend/persistence" // 💾 backends de persistência plugáveis
+	"virtuscloud/backend/reconciler"  // 🎯 converge containers para o estado desejado
+	"virtuscloud/backend/routes"      // 🚦 definição das rotas da API
+	"virtuscloud/backend/services"    // 🧠 lógica de negócio e integração
+	"virtuscloud/backend/sftp"        // 📂 SFTP com as chaves SSH do perfil
+	"virtuscloud/backend/store"       // 🗃️ persistência de usuários e sessões
+	"virtuscloud/backend/vault"       // 🗝️ segredos cifrados com a chave mestra
+	"virtuscloud/backend/volumes"     // 💽 volumes persistentes com cota do plano
 )
 
 func main() {
@@ -22,10 +39,54 @@ func main() {
 		}
 	}()
 
+	// 🛢️ Seleciona o backend de persistência (JSON em dev, SQLite/PostgreSQL via STORAGE_BACKEND)
+	backend, err := db.BackendFromEnv()
+	if err != nil {
+		log.Fatal("❌ Erro ao inicializar persistência:", err)
+	}
+	persistence.SetBackend(backend)
+	defer backend.Close()
+
+	// 🛡️ Log de auditoria: tabela audit_log no backend SQL ou database/audit.log
+	if sqlBackend, ok := backend.(*db.Backend); ok {
+		audit.SetStore(sqlBackend.AuditStore())
+	} else {
+		auditStore, err := audit.OpenFileStore(audit.DefaultFilePath)
+		if err != nil {
+			log.Fatal("❌ Erro ao abrir log de auditoria: ", err)
+		}
+		audit.SetStore(auditStore)
+	}
+	defer audit.Close()
+
+	// 🧱 Runtime de containers escolhido por CONTAINER_RUNTIME (docker, podman ou fake)
+	containerRuntime, err := engine.FromEnv()
+	if err != nil {
+		log.Fatal("❌ Erro ao inicializar runtime de containers: ", err)
+	}
+	engine.SetDefault(containerRuntime)
+	log.Println("🧱 Runtime de containers:", containerRuntime.Name())
+
+	// 🧱 Firewall das redes de usuário (NETWORK_FIREWALL=iptables): aplicações não abrem conexões para o host
+	if err := firewall.StartFromEnv(); err != nil {
+		log.Fatal("❌ Erro ao iniciar firewall das redes: ", err)
+	}
+
+	// 🗝️ Chave mestra dos segredos (VAULT_MASTER_KEY ou VAULT_KEY_FILE fora das pastas de dados)
+	if err := vault.Check(); err != nil {
+		log.Fatal("❌ Chave mestra indisponível: ", err)
+	}
+
 	// 🗃️ Carrega clientes salvos do arquivo JSON
 	if err := store.LoadUsersFromFile(middleware.ClientsFilePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de clientes incompatível: ", err)
+		}
 		log.Println("⚠️ Erro ao carregar clientes:", err)
 	}
+	if err := models.OpenSessions(); errors.Is(err, persistence.ErrSchemaTooNew) {
+		log.Fatal("❌ Arquivo de sessões incompatível: ", err)
+	}
 	services.LoadUsersFromFile()
 
 	// 👤 Garante que o usuário admin esteja presente
@@ -46,12 +107,82 @@ func main() {
 
 	// 🧠 Carrega aplicações salvas do disco
 	if err := store.LoadAppStoreFromDisk("./database/appstore.json"); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ AppStore incompatível: ", err)
+		}
 		log.Println("⚠️ Erro ao carregar AppStore:", err)
 	} else {
 		log.Println("✅ AppStore restaurado com sucesso!")
 		services.SyncAppStoreWithDocker()             // 🔄 sincroniza containers Docker reais com AppStore
 		services.CleanAppStoreFromMissingContainers() // 🧹 remove apps cujo container foi apagado
+		if err := limits.BackfillAllocations(); err != nil {
+			log.Println("⚠️ Erro ao gravar alocações padrão:", err)
+		}
+	}
+
+	// 🌍 Carrega os domínios personalizados das aplicações
+	if err := store.LoadDomainStoreFromDisk(store.DefaultDomainStorePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de domínios incompatível: ", err)
+		}
+		log.Println("⚠️ Erro ao carregar domínios:", err)
+	}
+	defer store.DomainStore.Close()
+
+	// 🔐 Certificados TLS emitidos via ACME (chaves seladas pelo vault)
+	if err := store.LoadCertificateStoreFromDisk(store.DefaultCertificateStorePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de certificados incompatível: ", err)
+		}
+		log.Println("⚠️ Erro ao carregar certificados:", err)
+	}
+	defer store.CertificateStore.Close()
+
+	// 🔑 Variáveis de ambiente e segredos das aplicações
+	if err := store.LoadAppEnvStoreFromDisk(store.DefaultAppEnvStorePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de variáveis de ambiente incompatível: ", err)
+		}
+		log.Println("⚠️ Erro ao carregar variáveis de ambiente:", err)
+	}
+	defer store.AppEnvStore.Close()
+
+	// 💽 Volumes persistentes das aplicações
+	if err := store.LoadVolumeStoreFromDisk(store.DefaultVolumeStorePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de volumes incompatível: ", err)
+		}
+		log.Println("⚠️ Erro ao carregar volumes:", err)
+	}
+	defer store.VolumeStore.Close()
+
+	// 🗄️ Bancos gerenciados (PostgreSQL, MySQL, MongoDB e Redis em containers)
+	if err := store.LoadDatabaseStoreFromDisk(store.DefaultDatabaseStorePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de bancos gerenciados incompatível: ", err)
+		}
+		log.Println("⚠️ Erro ao carregar bancos gerenciados:", err)
+	}
+	defer store.DatabaseStore.Close()
+
+	// 💥 Relatórios de crash das aplicações
+	if err := store.LoadCrashReportStoreFromDisk(store.DefaultCrashReportStorePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de relatórios de crash incompatível: ", err)
+		}
+		log.Println("⚠️ Erro ao carregar relatórios de crash:", err)
+	}
+	defer store.CrashReportStore.Close()
+
+	// 🚀 Carrega histórico de deploys (deploys "em andamento" de uma execução anterior viram interrompidos)
+	if err := store.LoadDeploymentStoreFromDisk(store.DefaultDeploymentStorePath); err != nil {
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			log.Fatal("❌ Arquivo de deploys incompatível: ", err)
+		}
+		log.Println("⚠️ Erro ao carregar histórico de deploys:", err)
 	}
+	defer store.DeploymentStore.Close()
+	services.MarkInterruptedDeployments()
 
 	// 🔄 Inicia sincronização automática de planos entre users.json e sessions.json
 	routes.StartSessionSync()
@@ -68,7 +199,7 @@ func main() {
 	// 📩 Envio e verificação de código
 	PublicRoute("/send-code", routes.SendCodeHandler)
 	PublicRoute("/resend-code", routes.SendCodeHandler)
-	PublicRoute("/api/verify", routes.VerifyCodeHandler)
+	AuditedPublicRoute("/api/verify", "auth.login", routes.VerifyCodeHandler)
 
 	// 🔎 Verificação de disponibilidade de usuário (signup)
 	PublicRoute("/api/check-user", routes.CheckUserAvailabilityHandler) // ✅ nova rota para verificação de duplicação
@@ -77,17 +208,20 @@ func main() {
 	PublicRoute("/api/signin", routes.SendCodeHandler) // ✅ nova rota de login direto
 
 	// 🔓 Logout manual
-	PublicRoute("/api/logout", routes.LogoutHandler) // ✅ nova rota de logout
+	AuditedPublicRoute("/api/logout", "auth.logout", routes.LogoutHandler) // ✅ nova rota de logout
 
 	// ✅ Verificação de sessão persistente (token + sessão)
 	ProtectedRoute("/api/verify-token", routes.VerifyTokenHandler) // ✅ essencial para login persistente
 
 	// 🔐 ROTAS PROTEGIDAS POR JWT
-	ProtectedRoute("/api/containers/create", routes.CreateContainerHandler)
-	ProtectedRoute("/api/containers/dev-create", routes.CreateContainerHandler)
+	AuditedRoute("/api/containers/create", "container.create", routes.CreateContainerHandler)
+	AuditedRoute("/api/containers/dev-create", "container.create", routes.CreateContainerHandler)
 	ProtectedRoute("/api/containers/list", routes.ListContainersHandler)
-	ProtectedRoute("/api/containers/delete", routes.DeleteContainerHandler)
-	ProtectedRoute("/api/profile/update", routes.UpdateProfileHandler)
+	AuditedRoute("/api/containers/delete", "container.delete", routes.DeleteContainerHandler)
+	AuditedRoute("/api/profile/update", "profile.update", routes.UpdateProfileHandler)
+	ProtectedRoute("/api/profile/ssh-keys", routes.ListSSHKeysHandler)
+	AuditedRoute("/api/profile/ssh-keys/add", "profile.ssh-key.add", routes.AddSSHKeyHandler)
+	AuditedRoute("/api/profile/ssh-keys/delete", "profile.ssh-key.delete", routes.DeleteSSHKeyHandler)
 
 	// 📊 Métricas e eventos técnicos
 	ProtectedRoute("/api/metrics", routes.MetricsHandler)
@@ -97,15 +231,73 @@ func main() {
 	ProtectedRoute("/api/plans/details", routes.PlansDetailsHandler)
 	ProtectedWithAccess("/api/admin/clients", "admin", routes.AdminUsersHandler)
 	ProtectedWithAccess("/api/admin/export-apps", "dev", routes.AdminExportAppsHandler)
+	ProtectedWithAccess("/api/admin/plan-migrations", "admin", routes.AdminPlanMigrationsHandler)
+	AuditedWithAccess("/api/admin/promote", "staff", "admin.user.promote", routes.AdminPromoteUserHandler)
+
+	// 🛡️ Log de auditoria: próprias ações e visão completa (admin)
+	ProtectedRoute("/api/audit", routes.UserAuditHandler)
+	ProtectedWithAccess("/api/admin/audit", "admin", routes.AdminAuditHandler)
 
 	// 📱 Aplicações do usuário
-	ProtectedRoute("/api/app/start", routes.StartAppHandler)
-	ProtectedRoute("/api/app/stop", routes.StopAppHandler)
-	ProtectedRoute("/api/app/restart", routes.RestartAppHandler)
-	ProtectedRoute("/api/app/rebuild", routes.RebuildAppHandler)
-	ProtectedRoute("/api/app/backup", routes.BackupAppHandler)
-	ProtectedRoute("/api/app/delete", routes.DeleteAppHandler)
-	ProtectedRoute("/api/app/update-name", routes.UpdateAppNameHandler)
+	AuditedRoute("/api/app/start", "app.start", routes.StartAppHandler)
+	AuditedRoute("/api/app/stop", "app.stop", routes.StopAppHandler)
+	AuditedRoute("/api/app/restart", "app.restart", routes.RestartAppHandler)
+	AuditedRoute("/api/app/rebuild", "app.rebuild", routes.RebuildAppHandler)
+	AuditedRoute("/api/app/backup", "app.backup", routes.BackupAppHandler)
+	AuditedRoute("/api/app/delete", "app.delete", routes.DeleteAppHandler)
+	AuditedRoute("/api/app/update-name", "app.rename", routes.UpdateAppNameHandler)
+	AuditedRoute("/api/app/resources", "app.resize", routes.ResizeAppHandler)
+	ProtectedRoute("/api/app/allocations", routes.AppAllocationsHandler)
+	ProtectedRoute("/api/app/routes", routes.AppRoutesHandler)
+	ProtectedRoute("/api/app/env", routes.ListAppEnvHandler)
+	AuditedRoute("/api/app/env/set", "app.env.set", routes.SetAppEnvHandler)
+	AuditedRoute("/api/app/env/delete", "app.env.delete", routes.DeleteAppEnvHandler)
+	ProtectedRoute("/api/app/volumes", routes.ListAppVolumesHandler)
+	AuditedRoute("/api/app/volumes/create", "volume.create", routes.CreateVolumeHandler)
+	AuditedRoute("/api/app/volumes/update", "volume.update", routes.UpdateVolumeHandler)
+	AuditedRoute("/api/app/volumes/delete", "volume.delete", routes.DeleteVolumeHandler)
+	ProtectedRoute("/api/app/health", routes.GetAppHealthHandler)
+	AuditedRoute("/api/app/health/set", "app.health.set", routes.SetAppHealthHandler)
+	ProtectedRoute("/api/app/crashes", routes.ListAppCrashesHandler)
+	ProtectedRoute("/api/app/logs/stream", routes.StreamAppLogsHandler)
+	ProtectedRoute("/api/app/logs/files", routes.ListAppLogFilesHandler)
+	ProtectedRoute("/api/app/logs/search", routes.SearchAppLogsHandler)
+	ProtectedRoute("/api/app/logs/download", routes.DownloadAppLogsHandler)
+	ProtectedRoute("/api/app/deployments", routes.ListAppDeploymentsHandler)
+	ProtectedRoute("/api/app/deployments/logs", routes.GetDeploymentLogHandler)
+	ProtectedRoute("/api/app/deployments/stream", routes.StreamDeploymentLogHandler)
+	AuditedRoute("/api/app/terminal", "app.terminal", routes.AppTerminalHandler)
+	ProtectedRoute("/api/app/terminal/sessions", routes.ListTerminalSessionsHandler)
+	ProtectedRoute("/api/app/files/roots", routes.ListFileRootsHandler)
+	ProtectedRoute("/api/app/files", routes.ListFilesHandler)
+	ProtectedRoute("/api/app/files/read", routes.ReadFileHandler)
+	AuditedRoute("/api/app/files/write", "app.files.write", routes.WriteFileHandler)
+	AuditedRoute("/api/app/files/upload", "app.files.upload", routes.UploadFilesHandler)
+	AuditedRoute("/api/app/files/mkdir", "app.files.mkdir", routes.MkdirFileHandler)
+	AuditedRoute("/api/app/files/rename", "app.files.rename", routes.RenameFileHandler)
+	AuditedRoute("/api/app/files/delete", "app.files.delete", routes.DeleteFileHandler)
+	ProtectedRoute("/api/volumes", routes.ListUserVolumesHandler)
+
+	// 🗄️ Bancos gerenciados na rede privada do usuário
+	ProtectedRoute("/api/databases", routes.ListDatabasesHandler)
+	ProtectedRoute("/api/databases/connection", routes.DatabaseConnectionHandler)
+	AuditedRoute("/api/databases/create", "database.create", routes.CreateDatabaseHandler)
+	AuditedRoute("/api/databases/resize", "database.resize", routes.ResizeDatabaseHandler)
+	AuditedRoute("/api/databases/start", "database.start", routes.StartDatabaseHandler)
+	AuditedRoute("/api/databases/stop", "database.stop", routes.StopDatabaseHandler)
+	AuditedRoute("/api/databases/delete", "database.delete", routes.DeleteDatabaseHandler)
+	AuditedRoute("/api/databases/link", "database.link", routes.LinkDatabaseHandler)
+	AuditedRoute("/api/databases/unlink", "database.unlink", routes.UnlinkDatabaseHandler)
+	ProtectedRoute("/api/databases/backups", routes.ListDatabaseBackupsHandler)
+	AuditedRoute("/api/databases/backups/create", "database.backup", routes.CreateDatabaseBackupHandler)
+	AuditedRoute("/api/databases/backups/download", "database.backup.download", routes.DownloadDatabaseBackupHandler)
+	AuditedRoute("/api/databases/restore", "database.restore", routes.RestoreDatabaseHandler)
+
+	// 🌍 Domínios personalizados (recurso custom-domain do plano)
+	ProtectedRoute("/api/domains", routes.ListDomainsHandler)
+	AuditedRoute("/api/domains/add", "domain.add", routes.AddDomainHandler)
+	AuditedRoute("/api/domains/verify", "domain.verify", routes.VerifyDomainHandler)
+	AuditedRoute("/api/domains/delete", "domain.delete", routes.DeleteDomainHandler)
 	ProtectedRoute("/api/app/list", routes.ListUserAppsHandler)
 	ProtectedRoute("/api/app/status", routes.ListAppsByStatusHandler) // ✅ nova rota para dashboard
 	ProtectedRoute("/api/app/metrics", routes.AppMetricsHandler)
@@ -123,11 +315,11 @@ func main() {
 	//ProtectedRoute("/api/deploy/entrypoints/{appID}", routes.EntryPointListHandler)
 
 	// 📤 Teste de upload — agora protegido para testes autenticados
-	ProtectedRoute("/api/upload", routes.UploadHandler)
-	ProtectedRoute("/api/test/upload", routes.UploadHandler)
+	AuditedRoute("/api/upload", "app.deploy", routes.UploadHandler)
+	AuditedRoute("/api/test/upload", "app.deploy", routes.UploadHandler)
 
 	// 🐳 Teste de criação de container local via CLI — agora protegido
-	ProtectedRoute("/api/docker", routes.DockerHandler)
+	AuditedRoute("/api/docker", "container.test-create", routes.DockerHandler)
 
 	// 🔐 Versão protegida futura — comentada por enquanto
 	// http.HandleFunc("/api/docker", middleware.AuthMiddleware(routes.DockerHandler))
@@ -152,8 +344,51 @@ func main() {
 	// 🐳 Lista containers reais do Docker por usuário autenticado
 	ProtectedRoute("/api/user/containers", routes.ListUserContainersHandler) // ✅ nova rota para containers reais
 
-	// 🐶 Inicia o watchdog para monitorar e reiniciar containers automaticamente
-	go tools.StartWatchdog()
+	// 🎯 Reconciliador: mantém cada aplicação no estado desejado (RECONCILE_WORKERS, RECONCILE_RESYNC_SECONDS)
+	reconciler.Start(reconciler.ConfigFromEnv())
+
+	// 🚧 Aplicações antigas saem da bridge padrão e ficam só na rede privada do dono
+	go services.IsolateAppNetworks()
+
+	// 🚪 Ingress: <app>.<usuário>.<INGRESS_BASE_DOMAIN> → container da aplicação
+	in, err := ingress.Start(ingress.ConfigFromEnv())
+	if err != nil {
+		log.Println("❌ Erro ao iniciar ingress:", err)
+	}
+	if in != nil {
+		in.AddHostProvider(domains.HostsForApp)         // domínios verificados também levam à aplicação
+		services.OnUserNetworkRelease(in.DetachNetwork) // rede do usuário removida: o ingress sai antes
+	}
+
+	// 🔐 ACME: emite e renova certificados dos hostnames do ingress (ACME_DIRECTORY_URL, INGRESS_TLS_ADDR)
+	if _, err := acme.Start(acme.ConfigFromEnv(), in); err != nil {
+		log.Println("❌ Erro ao iniciar ACME:", err)
+	}
+
+	// 🌍 Verificação DNS dos domínios pendentes (DOMAINS_DNS_SERVER, DOMAINS_VERIFY_SECONDS)
+	domains.SetResolver(domains.ResolverFromEnv())
+	domains.StartVerifier(domains.VerifyIntervalFromEnv())
+
+	// 💽 Mede o uso dos volumes e remonta somente leitura o que passar da cota
+	volumes.StartMonitor(volumes.MonitorIntervalFromEnv(), services.RemountAppVolumes)
+
+	// 🗄️ Retoma bancos interrompidos e para os que passarem do tamanho contratado (DATABASES_SCAN_SECONDS)
+	go services.ResumeDatabases()
+	databases.StartMonitor(databases.MonitorIntervalFromEnv(), services.SuspendDatabase)
+
+	// 💾 Dumps diários dos bancos nos planos com DailyBackups (DATABASES_BACKUP_HOURS, DATABASES_BACKUP_RETENTION)
+	databases.StartBackups(databases.BackupConfigFromEnv())
+
+	// 🩺 Probes de liveness/readiness das aplicações
+	health.StartMonitor()
+
+	// 🧺 Coleta stdout/stderr das aplicações em arquivos rotacionados (retenção pelo plano)
+	applogs.Start(applogs.ConfigFromEnv())
+
+	// 📂 SFTP nas aplicações e volumes do usuário, com as chaves do perfil (SFTP_ADDR, SFTP_HOST_KEY)
+	if err := sftp.Start(sftp.ConfigFromEnv()); err != nil {
+		log.Println("❌ Erro ao iniciar SFTP:", err)
+	}
 
 	// 🔄 Inicia sincronização periódica do AppStore com Docker
 
@@ -219,6 +454,20 @@ func ProtectedWithAccess(path string, required string, handler http.HandlerFunc)
 	http.Handle(path, middleware.AuthMiddleware(middleware.RequireAccess(required, handler)))
 }
 
+// 🛡️ Variantes que gravam cada chamada no log de auditoria
+func AuditedPublicRoute(path, action string, handler http.HandlerFunc) {
+	http.Handle(path, audit.Middleware(action, handler))
+}
+
+func AuditedRoute(path, action string, handler http.HandlerFunc) {
+	http.Handle(path, middleware.AuthMiddleware(audit.Middleware(action, handler)))
+}
+
+// A auditoria fica por fora do RequireAccess para registrar também as tentativas negadas
+func AuditedWithAccess(path, required, action string, handler http.HandlerFunc) {
+	http.Handle(path, middleware.AuthMiddleware(audit.Middleware(action, middleware.RequireAccess(required, handler))))
+}
+
 //virtuscloud/backend/main.go
 
 //package main
@@ -0,0 +1,32 @@
+//backend/models/app_env.go
+
+package models
+
+import "time"
+
+// 🔑 Variável de ambiente de uma aplicação
+type EnvVar struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value,omitempty"`  // variáveis comuns (texto puro)
+	Secret    bool      `json:"secret"`           // segredo: valor só existe selado
+	Sealed    string    `json:"sealed,omitempty"` // segredo cifrado com a chave mestra (vault.Seal)
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// 🧾 Variáveis e segredos injetados no container de uma aplicação
+type AppEnv struct {
+	AppID     string    `json:"appId"`
+	Username  string    `json:"username"`
+	Vars      []EnvVar  `json:"vars"` // ordenadas por chave
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// 📋 Cópia independente do ambiente
+func (e *AppEnv) Clone() *AppEnv {
+	if e == nil {
+		return nil
+	}
+	c := *e
+	c.Vars = append([]EnvVar(nil), e.Vars...)
+	return &c
+}
@@ -34,6 +34,10 @@ type App struct {
 	CPULimit   float32 `json:"cpuLimit"`   // limite do plano em vCPU
 	CPUPercent float32 `json:"cpuPercent"` // percentual relativo ao plano
 
+	// 📐 Fatia reservada do plano (a soma das aplicações do usuário não passa dos totais do plano)
+	AllocatedRAMMB int     `json:"allocatedRamMB"` // limite de memória do container em MB
+	AllocatedCPUs  float32 `json:"allocatedCpus"`  // limite de vCPU do container
+
 	Alert string `json:"alert"`
 
 	// Deploy dinâmico
@@ -43,6 +47,30 @@ type App struct {
 
 	ContainerName string `json:"container_name,omitempty"`
 	MissingCount  int    `json:"missing_count,omitempty"`
+
+	// 🎯 Estado desejado (o reconciliador converge o container para ele)
+	DesiredStatus AppStatus  `json:"desiredStatus,omitempty"` // running | stopped (vazio = deduzido de Status)
+	ImageID       string     `json:"imageId,omitempty"`       // imagem do último deploy (container em outra = recriado)
+	CrashLoop     *CrashLoop `json:"crashLoop,omitempty"`     // reinícios após crash com backoff
+
+	// 🩺 Probes configurados e último resultado observado
+	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
+	Health      *Health      `json:"health,omitempty"`
+}
+
+// 📋 Cópia independente da aplicação (slices incluídos)
+func (a *App) Clone() *App {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	if a.Logs != nil {
+		c.Logs = append([]string(nil), a.Logs...)
+	}
+	c.CrashLoop = a.CrashLoop.Clone()
+	c.HealthCheck = a.HealthCheck.Clone()
+	c.Health = a.Health.Clone()
+	return &c
 }
 
 //backend/models/apps.go
@@ -0,0 +1,33 @@
+//backend/models/certificates.go
+
+package models
+
+import "time"
+
+// 🏷️ Tipos de registro da coleção de certificados
+const (
+	CertificateKindCert    = "certificate" // certificado TLS emitido via ACME
+	CertificateKindAccount = "account"     // chave da conta ACME
+)
+
+// 🔒 Certificado (ou conta ACME) com o material privado cifrado pelo vault
+type Certificate struct {
+	Name      string    `json:"name"` // nome principal ("*.alice.apps.exemplo.com", "www.exemplo.com", "account:<diretório>")
+	Kind      string    `json:"kind"`
+	Domains   []string  `json:"domains,omitempty"`
+	Issuer    string    `json:"issuer"` // URL do diretório ACME
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	Sealed    string    `json:"sealed"` // PEM (cadeia + chave privada) selado com vault.Seal
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// 📋 Cópia independente do certificado
+func (c *Certificate) Clone() *Certificate {
+	if c == nil {
+		return nil
+	}
+	copy := *c
+	copy.Domains = append([]string(nil), c.Domains...)
+	return &copy
+}
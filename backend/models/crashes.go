@@ -0,0 +1,62 @@
+//backend/models/crashes.go
+
+package models
+
+import "time"
+
+// 💥 Aplicação reiniciando em sequência (backoff do reconciliador em andamento)
+const StatusCrashLoop AppStatus = "crashloop"
+
+// 💥 Crashes seguidos a partir dos quais a aplicação é considerada em crash loop
+const CrashLoopThreshold = 3
+
+// 🔁 Controle de reinícios após crash (zerado quando a aplicação fica estável ou o usuário age)
+type CrashLoop struct {
+	Crashes        int        `json:"crashes"`        // crashes seguidos sem ficar estável
+	BackoffSeconds int        `json:"backoffSeconds"` // espera atual antes de religar
+	LastCrash      *time.Time `json:"lastCrash,omitempty"`
+	NextRestart    *time.Time `json:"nextRestart,omitempty"`
+}
+
+// 💥 Relatório de um crash: como o container saiu e o que ele escreveu por último
+type CrashReport struct {
+	ID        string    `json:"id"` // "<appId>/<unix nanos do crash>"
+	AppID     string    `json:"appId"`
+	Username  string    `json:"username"`
+	ExitCode  int       `json:"exitCode"`
+	OOMKilled bool      `json:"oomKilled"`
+	Error     string    `json:"error,omitempty"` // erro do runtime, se houver
+	Crashes   int       `json:"crashes"`         // posição na sequência de crashes
+	StartedAt time.Time `json:"startedAt"`
+	CrashedAt time.Time `json:"crashedAt"`
+	LogLines  []string  `json:"logLines"`
+}
+
+// 📋 Cópia independente do controle de reinícios
+func (c *CrashLoop) Clone() *CrashLoop {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	return &cp
+}
+
+// 📋 Cópia independente do relatório
+func (r *CrashReport) Clone() *CrashReport {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	if r.LogLines != nil {
+		c.LogLines = append([]string(nil), r.LogLines...)
+	}
+	return &c
+}
+
+// 🚦 Estado exibido: crash loop tem precedência sobre o Status observado
+func (a *App) State() AppStatus {
+	if a.CrashLoop != nil && a.CrashLoop.Crashes >= CrashLoopThreshold {
+		return StatusCrashLoop
+	}
+	return a.Status
+}
@@ -0,0 +1,114 @@
+//backend/models/databases.go
+
+package models
+
+import "time"
+
+// 🗄️ Motores de banco gerenciado
+const (
+	DatabasePostgres = "postgres"
+	DatabaseMySQL    = "mysql"
+	DatabaseMongo    = "mongodb"
+	DatabaseRedis    = "redis"
+)
+
+// 🚦 Estados de um banco gerenciado
+const (
+	DatabaseCreating  = "creating"   // baixando a imagem / subindo o container
+	DatabaseRunning   = "running"    // aceitando conexões
+	DatabaseStopped   = "stopped"    // parado pelo usuário
+	DatabaseOverQuota = "over-quota" // parado: os dados passaram do tamanho contratado
+	DatabaseFailed    = "failed"     // não subiu (detalhe em LastError)
+)
+
+// 💾 Estados de um backup (dump lógico)
+const (
+	BackupRunning = "running"
+	BackupReady   = "ready"
+	BackupFailed  = "failed"
+)
+
+// 💾 Origem de um backup
+const (
+	BackupScheduled = "scheduled" // diário, nos planos com DailyBackups
+	BackupManual    = "manual"    // pedido pelo usuário
+)
+
+// 💾 Dump lógico do banco guardado fora do diretório de dados
+type DatabaseBackup struct {
+	ID          string     `json:"id"`
+	Trigger     string     `json:"trigger"`
+	Status      string     `json:"status"`
+	File        string     `json:"file"` // nome dentro do diretório de backups do banco
+	SizeBytes   int64      `json:"sizeBytes"`
+	SHA256      string     `json:"sha256,omitempty"`
+	Objects     int        `json:"objects"` // tabelas, coleções ou chaves no momento do dump
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// ♻️ Banco criado a partir do backup de outro (a restauração roda quando o container fica pronto)
+type DatabaseRestore struct {
+	DatabaseID string     `json:"databaseId"`
+	BackupID   string     `json:"backupId"`
+	SourceDB   string     `json:"sourceDb,omitempty"` // nome do banco de origem (MongoDB renomeia os namespaces)
+	Objects    int        `json:"objects"`            // esperado, conforme o backup
+	Restored   int        `json:"restored"`           // encontrado depois da restauração
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+}
+
+// 🔗 Aplicação que recebe a conexão do banco como variáveis de ambiente
+type DatabaseLink struct {
+	AppID    string    `json:"appId"`
+	Prefix   string    `json:"prefix"` // ex.: DATABASE → DATABASE_URL, DATABASE_HOST...
+	Keys     []string  `json:"keys"`   // variáveis gravadas no ambiente da aplicação
+	LinkedAt time.Time `json:"linkedAt"`
+}
+
+// 🗄️ Banco gerenciado: um container do usuário na rede privada dele
+type Database struct {
+	ID             string           `json:"id"`
+	Username       string           `json:"username"`
+	Name           string           `json:"name"` // também o nome de DNS na rede do usuário
+	Engine         string           `json:"engine"`
+	Version        string           `json:"version"`
+	Image          string           `json:"image"`
+	ContainerName  string           `json:"containerName"`
+	Port           int              `json:"port"`
+	DBName         string           `json:"dbName"`
+	User           string           `json:"user"`
+	SealedPassword string           `json:"sealedPassword"` // senha cifrada com a chave mestra (vault.Seal)
+	SizeGB         int              `json:"sizeGB"`         // tamanho contratado (limite do plano)
+	UsedBytes      int64            `json:"usedBytes"`      // última medição dos dados
+	MeasuredAt     *time.Time       `json:"measuredAt,omitempty"`
+	Status         string           `json:"status"`
+	LastError      string           `json:"lastError,omitempty"`
+	Links          []DatabaseLink   `json:"links,omitempty"`
+	Backups        []DatabaseBackup `json:"backups,omitempty"` // mais recente primeiro
+	RestoredFrom   *DatabaseRestore `json:"restoredFrom,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+}
+
+// 📋 Cópia independente do banco
+func (d *Database) Clone() *Database {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	if d.Links != nil {
+		c.Links = make([]DatabaseLink, len(d.Links))
+		for i, link := range d.Links {
+			link.Keys = append([]string(nil), link.Keys...)
+			c.Links[i] = link
+		}
+	}
+	if d.Backups != nil {
+		c.Backups = append([]DatabaseBackup(nil), d.Backups...)
+	}
+	if d.RestoredFrom != nil {
+		restore := *d.RestoredFrom
+		c.RestoredFrom = &restore
+	}
+	return &c
+}
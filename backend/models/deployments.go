@@ -0,0 +1,80 @@
+//backend/models/deployments.go
+
+package models
+
+import "time"
+
+// 🚀 Situação de um deploy
+type DeploymentStatus string
+
+const (
+	DeploymentRunning     DeploymentStatus = "running"
+	DeploymentSucceeded   DeploymentStatus = "succeeded"
+	DeploymentFailed      DeploymentStatus = "failed"
+	DeploymentInterrupted DeploymentStatus = "interrupted" // backend reiniciou no meio do deploy
+)
+
+// 🎚️ Nível de uma entrada do log de deploy
+type DeployLevel string
+
+const (
+	DeployDebug DeployLevel = "debug"
+	DeployInfo  DeployLevel = "info"
+	DeployWarn  DeployLevel = "warn"
+	DeployError DeployLevel = "error"
+)
+
+// 🔢 Ordem dos níveis (filtro por nível mínimo)
+func (l DeployLevel) Rank() int {
+	switch l {
+	case DeployDebug:
+		return 0
+	case DeployWarn:
+		return 2
+	case DeployError:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// 🚀 Um deploy da aplicação; as entradas ficam no log do próprio deploy
+type Deployment struct {
+	ID         string           `json:"id"`
+	AppID      string           `json:"appId"`
+	Username   string           `json:"username"`
+	Kind       string           `json:"kind"` // origem do código: zip | folder | rebuild
+	Status     DeploymentStatus `json:"status"`
+	Phase      string           `json:"phase"` // fase atual (ou a última, se terminou)
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt *time.Time       `json:"finishedAt,omitempty"`
+	DurationMs int64            `json:"durationMs,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	Entries    int              `json:"entries"`
+	Warnings   int              `json:"warnings"` // entradas warn ou error
+}
+
+// 📝 Entrada estruturada do log de deploy
+type DeployLogEntry struct {
+	Seq        int         `json:"seq"`
+	Time       time.Time   `json:"time"`
+	Level      DeployLevel `json:"level"`
+	Phase      string      `json:"phase"`
+	Step       string      `json:"step,omitempty"`
+	Message    string      `json:"message"`
+	DurationMs int64       `json:"durationMs,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// 📋 Cópia independente do deploy
+func (d *Deployment) Clone() *Deployment {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	if d.FinishedAt != nil {
+		t := *d.FinishedAt
+		c.FinishedAt = &t
+	}
+	return &c
+}
@@ -0,0 +1,41 @@
+//backend/models/domains.go
+
+package models
+
+import "time"
+
+// 🌍 Estados da verificação de posse de um domínio personalizado
+const (
+	DomainPending  = "pending"  // aguardando o registro DNS do desafio
+	DomainVerified = "verified" // posse confirmada: o ingress roteia o hostname
+	DomainFailed   = "failed"   // desafio expirado sem verificação
+)
+
+// 🧩 Métodos de desafio aceitos
+const (
+	DomainMethodTXT   = "txt"   // TXT em _virtuscloud-challenge.<hostname>
+	DomainMethodCNAME = "cname" // CNAME do hostname para o endereço da aplicação na plataforma
+)
+
+// 🌍 Domínio personalizado apontado para uma aplicação
+type Domain struct {
+	Hostname   string     `json:"hostname"`
+	AppID      string     `json:"appId"`
+	Username   string     `json:"username"`
+	Method     string     `json:"method"`
+	Token      string     `json:"token"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+	LastCheck  *time.Time `json:"lastCheck,omitempty"`
+	LastError  string     `json:"lastError,omitempty"`
+}
+
+// 📋 Cópia independente do domínio
+func (d *Domain) Clone() *Domain {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	return &c
+}
@@ -0,0 +1,111 @@
+//backend/models/health.go
+
+package models
+
+import "time"
+
+// 🩺 Tipos de probe
+type ProbeType string
+
+const (
+	ProbeHTTP ProbeType = "http" // GET em http://container:porta/caminho (2xx/3xx = saudável)
+	ProbeTCP  ProbeType = "tcp"  // conexão TCP na porta
+	ProbeExec ProbeType = "exec" // comando dentro do container (código 0 = saudável)
+)
+
+// 🩺 Estado de um probe
+type ProbeState string
+
+const (
+	ProbeUnknown  ProbeState = "unknown"  // ainda sem veredito (container parado ou limiares não atingidos)
+	ProbeStarting ProbeState = "starting" // dentro do período inicial: falhas não contam
+	ProbePassing  ProbeState = "passing"
+	ProbeFailing  ProbeState = "failing"
+)
+
+// ⚙️ Configuração de um probe (zeros recebem os padrões na validação)
+type Probe struct {
+	Type               ProbeType `json:"type"`
+	Path               string    `json:"path,omitempty"`    // http
+	Port               int       `json:"port,omitempty"`    // http/tcp; 0 = porta da aplicação
+	Command            []string  `json:"command,omitempty"` // exec
+	IntervalSeconds    int       `json:"intervalSeconds"`
+	TimeoutSeconds     int       `json:"timeoutSeconds"`
+	SuccessThreshold   int       `json:"successThreshold"`
+	FailureThreshold   int       `json:"failureThreshold"`
+	StartPeriodSeconds int       `json:"startPeriodSeconds"`
+}
+
+// 🩺 Probes configurados na aplicação (nil = sem verificação)
+type HealthCheck struct {
+	Liveness  *Probe `json:"liveness,omitempty"`  // falhando → reinício (plano com AutoRestart)
+	Readiness *Probe `json:"readiness,omitempty"` // falhando → ingress responde 503
+}
+
+// 📋 Último resultado de um probe
+type ProbeResult struct {
+	Status               ProbeState `json:"status"`
+	Message              string     `json:"message,omitempty"`
+	LastCheck            *time.Time `json:"lastCheck,omitempty"`
+	LastSuccess          *time.Time `json:"lastSuccess,omitempty"`
+	LastFailure          *time.Time `json:"lastFailure,omitempty"`
+	ConsecutiveSuccesses int        `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  int        `json:"consecutiveFailures"`
+}
+
+// 📋 Saúde observada da aplicação
+type Health struct {
+	Ready       bool         `json:"ready"`
+	Liveness    *ProbeResult `json:"liveness,omitempty"`
+	Readiness   *ProbeResult `json:"readiness,omitempty"`
+	Restarts    int          `json:"restarts"` // reinícios causados pelo liveness
+	LastRestart *time.Time   `json:"lastRestart,omitempty"`
+}
+
+// 📋 Cópia independente da configuração
+func (h *HealthCheck) Clone() *HealthCheck {
+	if h == nil {
+		return nil
+	}
+	return &HealthCheck{Liveness: h.Liveness.Clone(), Readiness: h.Readiness.Clone()}
+}
+
+// 📋 Cópia independente do probe
+func (p *Probe) Clone() *Probe {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	if p.Command != nil {
+		c.Command = append([]string(nil), p.Command...)
+	}
+	return &c
+}
+
+// 📋 Cópia independente da saúde observada
+func (h *Health) Clone() *Health {
+	if h == nil {
+		return nil
+	}
+	c := *h
+	c.Liveness = h.Liveness.Clone()
+	c.Readiness = h.Readiness.Clone()
+	return &c
+}
+
+// 📋 Cópia independente do resultado
+func (r *ProbeResult) Clone() *ProbeResult {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
+}
+
+// 🚦 Aplicação com readiness configurado que ainda não passou (o ingress não encaminha tráfego)
+func (a *App) AwaitingReadiness() bool {
+	if a.HealthCheck == nil || a.HealthCheck.Readiness == nil {
+		return false
+	}
+	return a.Health == nil || !a.Health.Ready
+}
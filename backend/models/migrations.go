@@ -0,0 +1,128 @@
+//backend/models/migrations.go
+
+package models
+
+import (
+	"fmt"
+	"strconv"
+
+	"virtuscloud/backend/persistence"
+)
+
+// 🧬 Migrações de esquema dos arquivos JSON, na ordem em que os campos mudaram.
+// Nunca altere uma migração publicada: acrescente a próxima versão.
+func init() {
+	persistence.RegisterMigrations(persistence.CollectionApps,
+		persistence.Migration{
+			Version:     1,
+			Description: "renomeia as chaves capitalizadas do formato antigo",
+			Up:          migrateAppLegacyKeys,
+		},
+		persistence.Migration{
+			Version:     2,
+			Description: "preenche ID e container_name ausentes",
+			Up:          migrateAppContainerName,
+		},
+		persistence.Migration{
+			Version:     3,
+			Description: "atribui a alocação padrão do plano às aplicações existentes",
+			Up:          migrateAppAllocation,
+		},
+	)
+
+	persistence.RegisterMigrations(persistence.CollectionUsers,
+		persistence.Migration{
+			Version:     1,
+			Description: "remove o ID numérico e preenche username, role e plan",
+			Up:          migrateUserIdentity,
+		},
+		persistence.Migration{
+			Version:     2,
+			Description: "preenche canDeploy a partir do plano",
+			Up:          migrateUserCanDeploy,
+		},
+	)
+}
+
+// 🔤 Chaves do App antes da padronização em minúsculas
+var legacyAppKeys = map[string]string{
+	"Username":      "username",
+	"Name":          "name",
+	"Path":          "path",
+	"PID":           "pid",
+	"Status":        "status",
+	"Logs":          "logs",
+	"Port":          "port",
+	"StartTime":     "start_time",
+	"RAM":           "ram",
+	"Alert":         "alert",
+	"ContainerName": "container_name",
+}
+
+func migrateAppLegacyKeys(_ string, record map[string]interface{}) error {
+	for oldKey, newKey := range legacyAppKeys {
+		value, ok := record[oldKey]
+		if !ok {
+			continue
+		}
+		if _, exists := record[newKey]; !exists {
+			record[newKey] = value
+		}
+		delete(record, oldKey)
+	}
+	return nil
+}
+
+func migrateAppContainerName(key string, record map[string]interface{}) error {
+	id, _ := record["ID"].(string)
+	if id == "" {
+		id = key
+		record["ID"] = id
+	}
+
+	// 🐳 Containers sempre foram nomeados como <username>-<ID>
+	containerName, _ := record["container_name"].(string)
+	username, _ := record["username"].(string)
+	if containerName == "" && username != "" {
+		record["container_name"] = fmt.Sprintf("%s-%s", username, id)
+	}
+	return nil
+}
+
+func migrateAppAllocation(_ string, record map[string]interface{}) error {
+	if ram, err := strconv.Atoi(fmt.Sprint(record["allocatedRamMB"])); err == nil && ram > 0 {
+		return nil
+	}
+	plan, ok := Plans[PlanType(fmt.Sprint(record["plan"]))]
+	if !ok {
+		return nil // plano desconhecido: o ledger usa a alocação padrão do plano do usuário
+	}
+	ramMB, cpus := plan.DefaultAppAllocation()
+	record["allocatedRamMB"] = ramMB
+	record["allocatedCpus"] = cpus
+	return nil
+}
+
+func migrateUserIdentity(key string, record map[string]interface{}) error {
+	delete(record, "id")
+
+	if username, _ := record["username"].(string); username == "" {
+		record["username"] = key
+	}
+	if role, _ := record["role"].(string); role == "" {
+		record["role"] = "user"
+	}
+	if plan, _ := record["plan"].(string); plan == "" {
+		record["plan"] = string(PlanNothing)
+	}
+	return nil
+}
+
+func migrateUserCanDeploy(_ string, record map[string]interface{}) error {
+	if _, ok := record["canDeploy"]; ok {
+		return nil
+	}
+	plan, _ := record["plan"].(string)
+	record["canDeploy"] = Plans[PlanType(plan)].MaxProjects > 0
+	return nil
+}
@@ -0,0 +1,224 @@
+//backend/models/migrations_test.go
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"virtuscloud/backend/persistence"
+)
+
+// 🧬 Roda MigrateFile sobre o conteúdo dado e devolve os registros migrados e o backup criado
+func migrateFixture(t *testing.T, collection, content, journal string) (map[string]map[string]interface{}, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), collection+".json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("erro ao gravar arquivo: %v", err)
+	}
+	if journal != "" {
+		if err := os.WriteFile(path+".journal", []byte(journal), 0644); err != nil {
+			t.Fatalf("erro ao gravar journal: %v", err)
+		}
+	}
+
+	if err := persistence.MigrateFile(collection, path); err != nil {
+		t.Fatalf("MigrateFile: %v", err)
+	}
+
+	var migrated struct {
+		SchemaVersion int                               `json:"schema_version"`
+		Records       map[string]map[string]interface{} `json:"records"`
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("erro ao ler arquivo migrado: %v", err)
+	}
+	if err := json.Unmarshal(data, &migrated); err != nil {
+		t.Fatalf("arquivo migrado inválido: %v\n%s", err, data)
+	}
+	if want := persistence.SchemaVersion(collection); migrated.SchemaVersion != want {
+		t.Fatalf("schema_version = %d, esperado %d", migrated.SchemaVersion, want)
+	}
+	if _, err := os.Stat(path + ".journal"); !os.IsNotExist(err) {
+		t.Fatalf("journal antigo não foi incorporado ao snapshot")
+	}
+
+	backups, _ := filepath.Glob(path + ".v*.bak")
+	if len(backups) > 1 {
+		t.Fatalf("backups demais: %v", backups)
+	}
+	backup := ""
+	if len(backups) == 1 {
+		backup = backups[0]
+		saved, _ := os.ReadFile(backup)
+		if string(saved) != content {
+			t.Fatalf("backup %s não guarda o arquivo original", backup)
+		}
+		if journal != "" {
+			if saved, _ := os.ReadFile(backup + ".journal"); string(saved) != journal {
+				t.Fatalf("backup do journal ausente ou diferente")
+			}
+		}
+	}
+	return migrated.Records, backup
+}
+
+type migrationCase struct {
+	name    string
+	content string
+	journal string
+	from    int // versão do arquivo original (-1 = já atual, sem backup)
+	want    map[string]map[string]interface{}
+}
+
+func runMigrationCases(t *testing.T, collection string, cases []migrationCase) {
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			records, backup := migrateFixture(t, collection, tc.content, tc.journal)
+			if tc.from < 0 {
+				if backup != "" {
+					t.Fatalf("arquivo atual não deveria gerar backup (%s)", backup)
+				}
+			} else if !regexp.MustCompile(`\.json\.v` + strconv.Itoa(tc.from) + `-\d{8}-\d{6}\.bak$`).MatchString(backup) {
+				t.Fatalf("backup = %q, esperado <arquivo>.v%d-<data>.bak", backup, tc.from)
+			}
+			for key, fields := range tc.want {
+				for field, value := range fields {
+					got, ok := records[key][field]
+					if value == nil {
+						if ok {
+							t.Fatalf("%s.%s = %v, esperado ausente", key, field, got)
+						}
+						continue
+					}
+					if !reflect.DeepEqual(got, value) {
+						t.Fatalf("%s.%s = %#v, esperado %#v", key, field, got, value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateAppsFile(t *testing.T) {
+	runMigrationCases(t, persistence.CollectionApps, []migrationCase{
+		{
+			name:    "v0 com chaves capitalizadas",
+			content: `{"web":{"Username":"ana","Name":"Site","Port":3000,"Status":"running"}}`,
+			from:    0,
+			want: map[string]map[string]interface{}{"web": {
+				"ID": "web", "username": "ana", "name": "Site", "port": float64(3000), "status": "running",
+				"container_name": "ana-web", "Username": nil, "Name": nil, "Port": nil,
+			}},
+		},
+		{
+			name:    "v0 com chave nova e antiga (a nova vence)",
+			content: `{"api":{"Username":"velho","username":"bia","ContainerName":"bia-api-2"}}`,
+			from:    0,
+			want: map[string]map[string]interface{}{"api": {
+				"username": "bia", "container_name": "bia-api-2", "Username": nil, "ContainerName": nil,
+			}},
+		},
+		{
+			name:    "v0 com journal pendente",
+			content: `{"web":{"Username":"ana"}}`,
+			journal: `{"seq":1,"op":"put","key":"bot","data":{"Username":"caio","Name":"Bot"}}` + "\n",
+			from:    0,
+			want: map[string]map[string]interface{}{
+				"web": {"container_name": "ana-web"},
+				"bot": {"ID": "bot", "username": "caio", "name": "Bot", "container_name": "caio-bot"},
+			},
+		},
+		{
+			name:    "v1 sem ID e container_name",
+			content: `{"schema_version":1,"records":{"jobs":{"username":"dani"}}}`,
+			from:    1,
+			want:    map[string]map[string]interface{}{"jobs": {"ID": "jobs", "container_name": "dani-jobs"}},
+		},
+		{
+			name:    "v2 sem alocação recebe a fatia padrão do plano",
+			content: `{"schema_version":2,"records":{"web":{"ID":"web","plan":"test"},"api":{"ID":"api","plan":"pro","allocatedRamMB":512,"allocatedCpus":1},"old":{"ID":"old","plan":"gold"}}}`,
+			from:    2,
+			want: map[string]map[string]interface{}{
+				"web": {"allocatedRamMB": float64(256), "allocatedCpus": 0.5},
+				"api": {"allocatedRamMB": float64(512), "allocatedCpus": float64(1)},
+				"old": {"allocatedRamMB": nil, "allocatedCpus": nil},
+			},
+		},
+		{
+			name:    "versão atual fica como está",
+			content: `{"schema_version":3,"records":{"web":{"ID":"web","username":"ana","plan":"pro"}}}`,
+			from:    -1,
+			want:    map[string]map[string]interface{}{"web": {"container_name": nil, "allocatedRamMB": nil}},
+		},
+	})
+}
+
+func TestMigrateUsersFile(t *testing.T) {
+	runMigrationCases(t, persistence.CollectionUsers, []migrationCase{
+		{
+			name:    "v0 com ID numérico e campos vazios",
+			content: `{"ana":{"id":7,"email":"ana@example.com"}}`,
+			from:    0,
+			want: map[string]map[string]interface{}{"ana": {
+				"id": nil, "username": "ana", "role": "user", "plan": string(PlanNothing), "canDeploy": false,
+			}},
+		},
+		{
+			name:    "v0 com plano pago",
+			content: `{"bia":{"username":"bia","role":"admin","plan":"pro"}}`,
+			from:    0,
+			want:    map[string]map[string]interface{}{"bia": {"role": "admin", "plan": "pro", "canDeploy": true}},
+		},
+		{
+			name:    "v1 sem canDeploy",
+			content: `{"schema_version":1,"records":{"caio":{"username":"caio","plan":"basic"},"dani":{"username":"dani","plan":"basic","canDeploy":false}}}`,
+			from:    1,
+			want: map[string]map[string]interface{}{
+				"caio": {"canDeploy": true},
+				"dani": {"canDeploy": false},
+			},
+		},
+		{
+			name:    "versão atual fica como está",
+			content: `{"schema_version":2,"records":{"eva":{"username":"eva","plan":"pro"}}}`,
+			from:    -1,
+			want:    map[string]map[string]interface{}{"eva": {"canDeploy": nil}},
+		},
+	})
+}
+
+func TestMigrateFileRefusesNewerSchema(t *testing.T) {
+	for _, collection := range []string{persistence.CollectionApps, persistence.CollectionUsers} {
+		path := filepath.Join(t.TempDir(), collection+".json")
+		content := `{"schema_version":99,"records":{"x":{"campo_do_futuro":true}}}`
+		journal := `{"seq":1,"op":"delete","key":"x"}` + "\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("erro ao gravar arquivo: %v", err)
+		}
+		if err := os.WriteFile(path+".journal", []byte(journal), 0644); err != nil {
+			t.Fatalf("erro ao gravar journal: %v", err)
+		}
+
+		err := persistence.MigrateFile(collection, path)
+		if !errors.Is(err, persistence.ErrSchemaTooNew) {
+			t.Fatalf("%s: MigrateFile = %v, esperado ErrSchemaTooNew", collection, err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Fatalf("%s: arquivo alterado: %s", collection, data)
+		}
+		if data, _ := os.ReadFile(path + ".journal"); string(data) != journal {
+			t.Fatalf("%s: journal alterado", collection)
+		}
+		if backups, _ := filepath.Glob(path + ".v*"); len(backups) > 0 {
+			t.Fatalf("%s: backup criado para arquivo recusado: %v", collection, backups)
+		}
+	}
+}
@@ -35,6 +35,13 @@ type Plan struct {
 	DailyBackups        bool
 	DailySnapshots      bool
 	CustomDomain        bool
+	MaxCustomDomains    int // domínios personalizados por usuário (0 = sem domínios)
+	LogRetentionDays    int // dias de logs dos containers guardados em disco
+	TerminalSessions    int // terminais web simultâneos por usuário (0 = sem terminal)
+	FileManagerMaxMB    int // maior arquivo aberto ou gravado pelo gerenciador de arquivos (0 = sem gerenciador)
+	MaxDatabases        int // bancos gerenciados por usuário (0 = sem bancos)
+	DatabaseMaxGB       int // maior tamanho contratado de um banco gerenciado
+	DatabaseMemoryMB    int // RAM de cada container de banco
 	EmailNotifs         bool
 	MetricsAccess       bool
 	ShieldEnabled       bool
@@ -49,6 +56,26 @@ type Plan struct {
 
 	// ✅ Novo campo para limite mínimo por aplicação (em MB)
 	PerAppMB int
+
+	// ⚙️ Limites aplicados a cada container de aplicação
+	PidsLimit   int64  // processos/threads simultâneos
+	NoFileLimit uint64 // ulimit nofile (soft = hard)
+	BlkioWeight uint16 // peso relativo de I/O de disco (10–1000)
+}
+
+// 📐 Menor fatia de CPU que uma aplicação pode receber (em vCPU)
+const MinAppCPUs float32 = 0.1
+
+// 📐 Alocação padrão de uma nova aplicação: PerAppMB de RAM e CPU proporcional a essa fatia
+func (p Plan) DefaultAppAllocation() (int, float32) {
+	if p.MemoryMB <= 0 {
+		return p.PerAppMB, 0
+	}
+	cpus := p.CPUvCores * float32(p.PerAppMB) / float32(p.MemoryMB)
+	if cpus > p.CPUvCores {
+		cpus = p.CPUvCores
+	}
+	return p.PerAppMB, cpus
 }
 
 var Plans = map[PlanType]Plan{
@@ -71,6 +98,13 @@ var Plans = map[PlanType]Plan{
 		DailyBackups:        false,
 		DailySnapshots:      false,
 		CustomDomain:        false,
+		MaxCustomDomains:    0,
+		LogRetentionDays:    1,
+		TerminalSessions:    0,
+		FileManagerMaxMB:    0,
+		MaxDatabases:        0,
+		DatabaseMaxGB:       0,
+		DatabaseMemoryMB:    0,
 		EmailNotifs:         false,
 		MetricsAccess:       false,
 		ShieldEnabled:       false,
@@ -83,6 +117,9 @@ var Plans = map[PlanType]Plan{
 		ProjectManager:      false,
 		ExclusiveSupport:    false,
 		PerAppMB:            256, // apenas adicionado
+		PidsLimit:           0,
+		NoFileLimit:         0,
+		BlkioWeight:         0,
 	},
 	PlanTest: {
 		Name:                PlanTest,
@@ -103,6 +140,13 @@ var Plans = map[PlanType]Plan{
 		DailyBackups:        false,
 		DailySnapshots:      false,
 		CustomDomain:        false,
+		MaxCustomDomains:    0,
+		LogRetentionDays:    1,
+		TerminalSessions:    1,
+		FileManagerMaxMB:    1,
+		MaxDatabases:        1,
+		DatabaseMaxGB:       1,
+		DatabaseMemoryMB:    256,
 		EmailNotifs:         false,
 		MetricsAccess:       false,
 		ShieldEnabled:       false,
@@ -115,6 +159,9 @@ var Plans = map[PlanType]Plan{
 		ProjectManager:      false,
 		ExclusiveSupport:    false,
 		PerAppMB:            256, // apenas adicionado
+		PidsLimit:           128,
+		NoFileLimit:         1024,
+		BlkioWeight:         100,
 	},
 	PlanBasic: {
 		Name:                PlanBasic,
@@ -135,6 +182,13 @@ var Plans = map[PlanType]Plan{
 		DailyBackups:        false,
 		DailySnapshots:      false,
 		CustomDomain:        false,
+		MaxCustomDomains:    0,
+		LogRetentionDays:    3,
+		TerminalSessions:    1,
+		FileManagerMaxMB:    5,
+		MaxDatabases:        2,
+		DatabaseMaxGB:       5,
+		DatabaseMemoryMB:    512,
 		EmailNotifs:         true,
 		MetricsAccess:       true,
 		ShieldEnabled:       false,
@@ -147,6 +201,9 @@ var Plans = map[PlanType]Plan{
 		ProjectManager:      false,
 		ExclusiveSupport:    false,
 		PerAppMB:            256, // apenas adicionado
+		PidsLimit:           256,
+		NoFileLimit:         4096,
+		BlkioWeight:         300,
 	},
 	PlanPro: {
 		Name:                PlanPro,
@@ -167,6 +224,13 @@ var Plans = map[PlanType]Plan{
 		DailyBackups:        true,
 		DailySnapshots:      false,
 		CustomDomain:        true,
+		MaxCustomDomains:    3,
+		LogRetentionDays:    7,
+		TerminalSessions:    2,
+		FileManagerMaxMB:    20,
+		MaxDatabases:        5,
+		DatabaseMaxGB:       20,
+		DatabaseMemoryMB:    1024,
 		EmailNotifs:         true,
 		MetricsAccess:       true,
 		ShieldEnabled:       true,
@@ -179,6 +243,9 @@ var Plans = map[PlanType]Plan{
 		ProjectManager:      false,
 		ExclusiveSupport:    false,
 		PerAppMB:            256, // apenas adicionado
+		PidsLimit:           512,
+		NoFileLimit:         8192,
+		BlkioWeight:         500,
 	},
 	PlanPremium: {
 		Name:                PlanPremium,
@@ -199,6 +266,13 @@ var Plans = map[PlanType]Plan{
 		DailyBackups:        true,
 		DailySnapshots:      true,
 		CustomDomain:        true,
+		MaxCustomDomains:    10,
+		LogRetentionDays:    14,
+		TerminalSessions:    3,
+		FileManagerMaxMB:    50,
+		MaxDatabases:        10,
+		DatabaseMaxGB:       50,
+		DatabaseMemoryMB:    2048,
 		EmailNotifs:         true,
 		MetricsAccess:       true,
 		ShieldEnabled:       true,
@@ -211,6 +285,9 @@ var Plans = map[PlanType]Plan{
 		ProjectManager:      true,
 		ExclusiveSupport:    true,
 		PerAppMB:            256, // apenas adicionado
+		PidsLimit:           1024,
+		NoFileLimit:         16384,
+		BlkioWeight:         700,
 	},
 	PlanEnterprise: {
 		Name:                PlanEnterprise,
@@ -231,6 +308,13 @@ var Plans = map[PlanType]Plan{
 		DailyBackups:        true,
 		DailySnapshots:      true,
 		CustomDomain:        true,
+		MaxCustomDomains:    50,
+		LogRetentionDays:    30,
+		TerminalSessions:    5,
+		FileManagerMaxMB:    100,
+		MaxDatabases:        25,
+		DatabaseMaxGB:       200,
+		DatabaseMemoryMB:    4096,
 		EmailNotifs:         true,
 		MetricsAccess:       true,
 		ShieldEnabled:       true,
@@ -243,6 +327,9 @@ var Plans = map[PlanType]Plan{
 		ProjectManager:      true,
 		ExclusiveSupport:    true,
 		PerAppMB:            256, // apenas adicionado
+		PidsLimit:           2048,
+		NoFileLimit:         65536,
+		BlkioWeight:         1000,
 	},
 }
 
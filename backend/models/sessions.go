@@ -5,8 +5,18 @@ package models
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"reflect"
+	"sync"
 	"time"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho do arquivo de sessões
+const SessionsFilePath = "./database/sessions.json"
+
+var (
+	sessionsMu         sync.Mutex
+	sessionsCollection persistence.Collection
 )
 
 func NowISO() string {
@@ -23,60 +33,122 @@ type SessionData struct {
 	Token    string `json:"token"`
 }
 
-func SaveSessions(sessions map[string]SessionData) error {
-	file, err := os.Create("./database/sessions.json")
-	if err != nil {
-		return fmt.Errorf("erro ao abrir arquivo de sessões: %w", err)
+// 📂 Abre a coleção de sessões no backend ativo sob demanda
+func openSessionsLocked() error {
+	if sessionsCollection != nil {
+		return nil
 	}
-	defer file.Close()
-
-	if err := json.NewEncoder(file).Encode(sessions); err != nil {
-		return fmt.Errorf("erro ao salvar sessões: %w", err)
+	collection, err := persistence.Open(persistence.CollectionSessions, SessionsFilePath)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir sessões: %w", err)
 	}
+	sessionsCollection = collection
 	return nil
 }
 
-func LoadSessions() map[string]SessionData {
-	file, err := os.Open("./database/sessions.json")
+// 📂 Abre as sessões no boot para validar a versão do arquivo
+func OpenSessions() error {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	return openSessionsLocked()
+}
+
+// 📖 Lê todas as sessões persistidas (sem expiração)
+func readSessionsLocked() map[string]SessionData {
+	sessions := map[string]SessionData{}
+	if err := openSessionsLocked(); err != nil {
+		return sessions
+	}
+	records, err := sessionsCollection.Records()
 	if err != nil {
-		return map[string]SessionData{}
+		return sessions
+	}
+	for username, raw := range records {
+		var session SessionData
+		if err := json.Unmarshal(raw, &session); err == nil {
+			sessions[username] = session
+		}
+	}
+	return sessions
+}
+
+// 💾 Grava apenas as diferenças entre o estado persistido e o novo mapa
+func writeSessionsLocked(sessions map[string]SessionData) error {
+	if err := openSessionsLocked(); err != nil {
+		return err
 	}
-	defer file.Close()
+	current := readSessionsLocked()
 
-	sessions := map[string]SessionData{}
-	_ = json.NewDecoder(file).Decode(&sessions)
+	for username := range current {
+		if _, ok := sessions[username]; !ok {
+			if err := sessionsCollection.Delete(username); err != nil {
+				return fmt.Errorf("erro ao salvar sessões: %w", err)
+			}
+		}
+	}
+	for username, session := range sessions {
+		if old, ok := current[username]; ok && reflect.DeepEqual(old, session) {
+			continue
+		}
+		if err := sessionsCollection.Put(username, session); err != nil {
+			return fmt.Errorf("erro ao salvar sessões: %w", err)
+		}
+	}
+	return nil
+}
 
-	// ⏳ Expiração automática (24h)
-	expired := map[string]bool{}
+// ⏳ Remove sessões sem atividade há mais de 24h
+func expireSessions(sessions map[string]SessionData) bool {
+	expired := false
 	for username, session := range sessions {
 		lastSeen, err := time.Parse(time.RFC3339, session.LastSeen)
 		if err != nil || time.Since(lastSeen) > 24*time.Hour {
-			expired[username] = true
+			delete(sessions, username)
+			expired = true
 		}
 	}
+	return expired
+}
 
-	for username := range expired {
-		delete(sessions, username)
-	}
+func SaveSessions(sessions map[string]SessionData) error {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	return writeSessionsLocked(sessions)
+}
+
+func LoadSessions() map[string]SessionData {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	sessions := readSessionsLocked()
 
-	if len(expired) > 0 {
-		_ = SaveSessions(sessions)
+	// ⏳ Expiração automática (24h)
+	if expireSessions(sessions) {
+		_ = writeSessionsLocked(sessions)
 	}
 
 	return sessions
 }
 
+// 🔁 Leitura-modificação-escrita atômica das sessões
+func UpdateSessions(fn func(sessions map[string]SessionData)) error {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	sessions := readSessionsLocked()
+	expireSessions(sessions)
+	fn(sessions)
+	return writeSessionsLocked(sessions)
+}
+
 func DeleteSessionByEmail(email string) error {
-	file, err := os.Open("./database/sessions.json")
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
 
-	var sessions map[string]SessionData
-	if err := json.NewDecoder(file).Decode(&sessions); err != nil {
+	if err := openSessionsLocked(); err != nil {
 		return err
 	}
+	sessions := readSessionsLocked()
 
 	// Remove a sessão com base no email
 	for username, session := range sessions {
@@ -87,11 +159,7 @@ func DeleteSessionByEmail(email string) error {
 	}
 
 	// Salva de volta
-	data, err := json.MarshalIndent(sessions, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile("./database/sessions.json", data, 0644)
+	return writeSessionsLocked(sessions)
 }
 
 func GetSessionByToken(tokenStr string) (*SessionData, bool) {
@@ -104,31 +172,33 @@ func GetSessionByToken(tokenStr string) (*SessionData, bool) {
 	return nil, false
 }
 func UpdateSessionActivity(username string) error {
-	sessions := LoadSessions()
-
-	session, ok := sessions[username]
-	if !ok {
+	found := false
+	err := UpdateSessions(func(sessions map[string]SessionData) {
+		session, ok := sessions[username]
+		if !ok {
+			return
+		}
+		found = true
+		session.LastSeen = NowISO()
+		sessions[username] = session
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
 		return fmt.Errorf("sessão não encontrada para %s", username)
 	}
-
-	session.LastSeen = NowISO()
-	sessions[username] = session
-
-	return SaveSessions(sessions)
+	return nil
 }
 func DeleteSession(username string) error {
-	sessions := LoadSessions()
-	delete(sessions, username)
-	return SaveSessions(sessions)
+	return UpdateSessions(func(sessions map[string]SessionData) {
+		delete(sessions, username)
+	})
 }
 func LoadAllSessions() map[string]SessionData {
-	sessions := map[string]SessionData{}
-	file, err := os.Open("./database/sessions.json")
-	if err == nil {
-		_ = json.NewDecoder(file).Decode(&sessions)
-		file.Close()
-	}
-	return sessions
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	return readSessionsLocked()
 }
 func GetSessionByTokenFromUsername(username string) (*SessionData, bool) {
 	sessions := LoadSessions()
@@ -18,6 +18,30 @@ type User struct {
 	Active       bool      `json:"active"`                 // Indica se o usuário está ativo
 	CanDeploy    bool      `json:"canDeploy"`              // ✅ Permissão para realizar deploys
 	CreatedAt    time.Time `json:"created_at"`             // Data de criação do usuário
+	SSHKeys      []SSHKey  `json:"sshKeys,omitempty"`      // 🔑 Chaves públicas aceitas no SFTP
+}
+
+// 🔑 Chave pública SSH registrada no perfil (acesso SFTP)
+type SSHKey struct {
+	Name        string    `json:"name"`
+	PublicKey   string    `json:"publicKey"`   // Linha authorized_keys sem comentário
+	Fingerprint string    `json:"fingerprint"` // SHA256:... como no ssh-keygen -l
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// 📋 Cópia independente do usuário (slices incluídos)
+func (u *User) Clone() *User {
+	if u == nil {
+		return nil
+	}
+	c := *u
+	if u.Containers != nil {
+		c.Containers = append([]string(nil), u.Containers...)
+	}
+	if u.SSHKeys != nil {
+		c.SSHKeys = append([]SSHKey(nil), u.SSHKeys...)
+	}
+	return &c
 }
 
 //package models
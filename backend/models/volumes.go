@@ -0,0 +1,28 @@
+//backend/models/volumes.go
+
+package models
+
+import "time"
+
+// 💽 Volume persistente de uma aplicação (diretório no host montado no container)
+type Volume struct {
+	ID         string     `json:"id"` // "<appId>/<nome>"
+	AppID      string     `json:"appId"`
+	Username   string     `json:"username"`
+	Name       string     `json:"name"`
+	MountPath  string     `json:"mountPath"`
+	SizeGB     int        `json:"sizeGB"`    // cota reservada do armazenamento do plano
+	UsedBytes  int64      `json:"usedBytes"` // última medição
+	OverQuota  bool       `json:"overQuota"` // acima da cota: montado somente leitura até liberar espaço
+	MeasuredAt *time.Time `json:"measuredAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// 📋 Cópia independente do volume
+func (v *Volume) Clone() *Volume {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,142 @@
+//backend/persistence/atomic_test.go
+
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 🧹 Temporários que sobraram ao lado do arquivo
+func leftovers(t *testing.T, path string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("erro ao listar diretório: %v", err)
+	}
+	var out []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "."+filepath.Base(path)+".tmp-") {
+			out = append(out, e.Name())
+		}
+	}
+	return out
+}
+
+func TestWriteFileAtomicReplacesContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db", "users.json")
+	if err := WriteFileAtomic(path, []byte("velho"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("novo"), 0640); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "novo" {
+		t.Fatalf("conteúdo = %q (%v), esperado novo", data, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Fatalf("permissão = %v, esperado 0640", info.Mode().Perm())
+	}
+	if left := leftovers(t, path); len(left) > 0 {
+		t.Fatalf("temporários sobrando: %v", left)
+	}
+}
+
+func TestWriteFileAtomicKeepsTargetOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	// 📁 Destino é um diretório com conteúdo: o rename falha
+	path := filepath.Join(dir, "apps.json")
+	if err := os.MkdirAll(filepath.Join(path, "dentro"), 0755); err != nil {
+		t.Fatalf("erro ao criar diretório: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("{}"), 0644); err == nil {
+		t.Fatalf("WriteFileAtomic por cima de diretório deveria falhar")
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		t.Fatalf("destino alterado pela escrita que falhou")
+	}
+	if left := leftovers(t, path); len(left) > 0 {
+		t.Fatalf("temporários sobrando: %v", left)
+	}
+}
+
+func TestOpenTableRemovesInterruptedTempFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apps.json")
+	if err := WriteFileAtomic(path, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	// ✂️ Escrita interrompida antes do rename
+	stale := filepath.Join(filepath.Dir(path), ".apps.json.tmp-123")
+	if err := os.WriteFile(stale, []byte(`{"a":`), 0644); err != nil {
+		t.Fatalf("erro ao criar temporário: %v", err)
+	}
+
+	table, err := OpenTable("teste", path)
+	if err != nil {
+		t.Fatalf("OpenTable: %v", err)
+	}
+	defer table.Close()
+	if string(table.Records()["a"]) != "1" {
+		t.Fatalf("snapshot não lido: %v", table.Records())
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("temporário interrompido não foi removido")
+	}
+}
+
+// 🏁 Rode com -race: escritas concorrentes com checkpoints no meio
+func TestTableConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apps.json")
+	table, err := OpenTable("teste", path)
+	if err != nil {
+		t.Fatalf("OpenTable: %v", err)
+	}
+	table.CheckpointEvery = 16
+
+	const workers, writes = 8, 40
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				key := fmt.Sprintf("w%d-%d", w, i)
+				if err := table.Put(key, i); err != nil {
+					t.Errorf("Put: %v", err)
+					return
+				}
+				if i%4 == 0 {
+					if err := table.Delete(key); err != nil {
+						t.Errorf("Delete: %v", err)
+						return
+					}
+				}
+				_ = table.Records()
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := table.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := OpenTable("teste", path)
+	if err != nil {
+		t.Fatalf("OpenTable: %v", err)
+	}
+	defer reopened.Close()
+	records := reopened.Records()
+	if want := workers * writes * 3 / 4; len(records) != want {
+		t.Fatalf("%d registros no disco, esperado %d", len(records), want)
+	}
+	var value int
+	if err := json.Unmarshal(records["w3-7"], &value); err != nil || value != 7 {
+		t.Fatalf("registro w3-7 = %s", records["w3-7"])
+	}
+}
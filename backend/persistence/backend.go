@@ -0,0 +1,71 @@
+//backend/persistence/backend.go
+
+package persistence
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// 🗂️ Nomes das coleções persistidas
+const (
+	CollectionUsers        = "users"
+	CollectionApps         = "apps"
+	CollectionSessions     = "sessions"
+	CollectionDomains      = "domains"
+	CollectionCertificates = "certificates"
+	CollectionAppEnv       = "app_env"
+	CollectionVolumes      = "volumes"
+	CollectionCrashReports = "crash_reports"
+	CollectionDeployments  = "deployments"
+	CollectionDatabases    = "databases"
+)
+
+// 🧩 Conjunto de registros chaveados (usuários, apps, sessões)
+type Collection interface {
+	// Records devolve uma cópia de todos os registros (chave → JSON)
+	Records() (map[string]json.RawMessage, error)
+	// Put insere ou substitui o registro da chave
+	Put(key string, value interface{}) error
+	// Delete remove o registro da chave (ausente = no-op)
+	Delete(key string) error
+	// Reload indica se a origem mudou fora do processo desde a última leitura
+	Reload() (bool, error)
+	// Flush consolida as alterações pendentes
+	Flush() error
+	// Close faz o flush final e libera os recursos
+	Close() error
+}
+
+// 🔌 Backend de persistência plugável (arquivos JSON, SQL...)
+type Backend interface {
+	Name() string
+	// Open abre a coleção; path é o arquivo legado usado pelo backend JSON
+	// e pode ser ignorado por backends que não trabalham com arquivos.
+	Open(name, path string) (Collection, error)
+	Close() error
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend = NewJSONBackend()
+)
+
+// 🔌 Define o backend usado pelas próximas aberturas de coleção
+func SetBackend(b Backend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backend = b
+}
+
+// 🔍 Backend ativo
+func CurrentBackend() Backend {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backend
+}
+
+// 📂 Abre a coleção no backend ativo
+func Open(name, path string) (Collection, error) {
+	return CurrentBackend().Open(name, path)
+}
@@ -0,0 +1,145 @@
+//backend/persistence/journal.go
+
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	OpPut    = "put"
+	OpDelete = "delete"
+)
+
+// 📝 Entrada do write-ahead journal (uma linha JSON por operação)
+type Entry struct {
+	Seq  uint64          `json:"seq"`
+	Op   string          `json:"op"`
+	Key  string          `json:"key"`
+	Data json.RawMessage `json:"data,omitempty"`
+	Time time.Time       `json:"time"`
+}
+
+// 📒 Journal append-only com fsync a cada entrada
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	seq  uint64
+	size int
+}
+
+// 📂 Abre (ou cria) o journal no caminho informado
+func OpenJournal(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do journal: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir journal: %w", err)
+	}
+	return &Journal{path: path, file: f}, nil
+}
+
+// 🔁 Reaplica as entradas gravadas. Uma última linha incompleta (crash durante
+// o append) é descartada e o arquivo é truncado no último registro válido.
+func (j *Journal) Replay(apply func(Entry) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(j.file)
+	var offset int64
+	j.size = 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// linha sem '\n' final → escrita interrompida
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler journal: %w", err)
+		}
+
+		var entry Entry
+		if jsonErr := json.Unmarshal(bytes.TrimSpace(line), &entry); jsonErr != nil {
+			break
+		}
+		if err := apply(entry); err != nil {
+			return err
+		}
+		offset += int64(len(line))
+		j.size++
+		if entry.Seq > j.seq {
+			j.seq = entry.Seq
+		}
+	}
+
+	if err := j.file.Truncate(offset); err != nil {
+		return fmt.Errorf("erro ao truncar journal: %w", err)
+	}
+	_, err := j.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+// ➕ Grava uma entrada e força o fsync antes de retornar
+func (j *Journal) Append(op, key string, data json.RawMessage) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	line, err := json.Marshal(Entry{Seq: j.seq, Op: op, Key: key, Data: data, Time: time.Now()})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err := j.file.Write(line); err != nil {
+		return fmt.Errorf("erro ao escrever no journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("erro ao sincronizar journal: %w", err)
+	}
+	j.size++
+	return nil
+}
+
+// 🧹 Esvazia o journal depois de um checkpoint bem-sucedido
+func (j *Journal) Truncate() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("erro ao truncar journal: %w", err)
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	j.size = 0
+	return j.file.Sync()
+}
+
+// 📏 Quantidade de entradas pendentes desde o último checkpoint
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.size
+}
+
+// 🔒 Fecha o arquivo do journal
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
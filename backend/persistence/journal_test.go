@@ -0,0 +1,188 @@
+//backend/persistence/journal_test.go
+
+package persistence
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 📒 Journal com as entradas dadas, já fechado (como depois de um crash)
+func writeJournal(t *testing.T, path string, keys ...string) {
+	t.Helper()
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	for _, key := range keys {
+		if err := j.Append(OpPut, key, json.RawMessage(`"`+key+`"`)); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func appendRaw(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("erro ao abrir journal: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatalf("erro ao escrever no journal: %v", err)
+	}
+}
+
+// 🔁 Reabre o journal e devolve as chaves reaplicadas
+func replayKeys(t *testing.T, path string) (*Journal, []string) {
+	t.Helper()
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	var keys []string
+	if err := j.Replay(func(e Entry) error {
+		keys = append(keys, e.Key)
+		return nil
+	}); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	return j, keys
+}
+
+func TestReplayDropsTornLastEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apps.json.journal")
+	writeJournal(t, path, "a", "b", "c")
+	intact, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("erro ao ler journal: %v", err)
+	}
+	// ✂️ Crash no meio do append: a última linha ficou sem '\n'
+	appendRaw(t, path, `{"seq":4,"op":"put","key":"d","da`)
+
+	j, keys := replayKeys(t, path)
+	if strings.Join(keys, ",") != "a,b,c" || j.Len() != 3 {
+		t.Fatalf("reaplicadas %v (len %d), esperado a,b,c", keys, j.Len())
+	}
+	if info, _ := os.Stat(path); info.Size() != intact.Size() {
+		t.Fatalf("journal com %d bytes, esperado truncado em %d", info.Size(), intact.Size())
+	}
+
+	// ➕ A próxima entrada continua a sequência e fica legível
+	if err := j.Append(OpPut, "e", json.RawMessage(`"e"`)); err != nil {
+		t.Fatalf("Append depois do replay: %v", err)
+	}
+	j.Close()
+	j, keys = replayKeys(t, path)
+	defer j.Close()
+	if strings.Join(keys, ",") != "a,b,c,e" {
+		t.Fatalf("reaplicadas %v, esperado a,b,c,e", keys)
+	}
+	if j.seq != 4 {
+		t.Fatalf("seq = %d, esperado 4", j.seq)
+	}
+}
+
+func TestReplayStopsAtPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json.journal")
+	writeJournal(t, path, "a", "b")
+	// 🧱 Bloco zerado pelo sistema de arquivos depois do crash, terminado em '\n'
+	appendRaw(t, path, "\x00\x00\x00\n")
+
+	j, keys := replayKeys(t, path)
+	defer j.Close()
+	if strings.Join(keys, ",") != "a,b" {
+		t.Fatalf("reaplicadas %v, esperado a,b", keys)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.ContainsRune(string(data), 0) {
+		t.Fatalf("linha parcial continuou no journal")
+	}
+}
+
+func TestTableRecoversFromJournalAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apps.json")
+	table, err := OpenTable("teste", path)
+	if err != nil {
+		t.Fatalf("OpenTable: %v", err)
+	}
+	table.CheckpointEvery = 0
+	for _, key := range []string{"a", "b", "c"} {
+		if err := table.Put(key, map[string]string{"id": key}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if err := table.Delete("b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// 💥 Sem Close: o snapshot nunca foi gravado, só o journal
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("snapshot gravado antes do checkpoint")
+	}
+
+	recovered, err := OpenTable("teste", path)
+	if err != nil {
+		t.Fatalf("OpenTable depois do crash: %v", err)
+	}
+	defer recovered.Close()
+	records := recovered.Records()
+	if len(records) != 2 || records["a"] == nil || records["c"] == nil {
+		t.Fatalf("registros recuperados: %v", records)
+	}
+	// ♻️ A recuperação já consolidou o snapshot e esvaziou o journal
+	if recovered.journal.Len() != 0 {
+		t.Fatalf("journal com %d entradas depois da recuperação", recovered.journal.Len())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot não gravado na recuperação: %v", err)
+	}
+}
+
+func TestTruncateAfterCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	table, err := OpenTable("teste", path)
+	if err != nil {
+		t.Fatalf("OpenTable: %v", err)
+	}
+	table.CheckpointEvery = 3
+	for _, key := range []string{"a", "b"} {
+		if err := table.Put(key, key); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if table.journal.Len() != 2 {
+		t.Fatalf("journal com %d entradas, esperado 2", table.journal.Len())
+	}
+
+	// 📸 A terceira entrada dispara o checkpoint: snapshot completo e journal vazio
+	if err := table.Put("c", "c"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if info, err := os.Stat(path + ".journal"); err != nil || info.Size() != 0 || table.journal.Len() != 0 {
+		t.Fatalf("journal não foi esvaziado no checkpoint: %v", err)
+	}
+	var snapshot snapshotFile
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &snapshot); err != nil || len(snapshot.Records) != 3 {
+		t.Fatalf("snapshot = %s (%v)", data, err)
+	}
+
+	// ➕ O que vem depois do checkpoint vai só para o journal e sobrevive ao crash
+	if err := table.Put("d", "d"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	recovered, err := OpenTable("teste", path)
+	if err != nil {
+		t.Fatalf("OpenTable depois do crash: %v", err)
+	}
+	defer recovered.Close()
+	if records := recovered.Records(); len(records) != 4 || string(records["d"]) != `"d"` {
+		t.Fatalf("registros recuperados: %v", records)
+	}
+}
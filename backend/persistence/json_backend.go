@@ -0,0 +1,62 @@
+//backend/persistence/json_backend.go
+
+package persistence
+
+import "encoding/json"
+
+// 📄 Backend de desenvolvimento: um arquivo JSON por coleção + journal
+type JSONBackend struct{}
+
+func NewJSONBackend() *JSONBackend {
+	return &JSONBackend{}
+}
+
+func (b *JSONBackend) Name() string {
+	return "json"
+}
+
+// 📂 Abre a tabela JSON no caminho informado, migrando arquivos antigos
+func (b *JSONBackend) Open(name, path string) (Collection, error) {
+	if err := MigrateFile(name, path); err != nil {
+		return nil, err
+	}
+	table, err := OpenTable(name, path)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonCollection{table: table}, nil
+}
+
+// Cada coleção é fechada pelo seu repositório
+func (b *JSONBackend) Close() error {
+	return nil
+}
+
+// 🔗 Adapta a Table ao contrato de Collection
+type jsonCollection struct {
+	table *Table
+}
+
+func (c *jsonCollection) Records() (map[string]json.RawMessage, error) {
+	return c.table.Records(), nil
+}
+
+func (c *jsonCollection) Put(key string, value interface{}) error {
+	return c.table.Put(key, value)
+}
+
+func (c *jsonCollection) Delete(key string) error {
+	return c.table.Delete(key)
+}
+
+func (c *jsonCollection) Reload() (bool, error) {
+	return c.table.ReloadIfChanged()
+}
+
+func (c *jsonCollection) Flush() error {
+	return c.table.Checkpoint()
+}
+
+func (c *jsonCollection) Close() error {
+	return c.table.Close()
+}
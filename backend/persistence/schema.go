@@ -0,0 +1,155 @@
+//backend/persistence/schema.go
+
+package persistence
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 🚫 O arquivo foi gravado por um binário mais novo que este
+var ErrSchemaTooNew = errors.New("versão de esquema mais nova que a suportada por este binário")
+
+// 🧬 Migração de esquema: leva cada registro da versão Version-1 para Version
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(key string, record map[string]interface{}) error
+}
+
+var (
+	schemaMu sync.RWMutex
+	schemas  = map[string][]Migration{}
+)
+
+// 📝 Registra as migrações de uma coleção (versões contíguas a partir de 1)
+func RegisterMigrations(collection string, migrations ...Migration) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	all := append(schemas[collection], migrations...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	for i, m := range all {
+		if m.Version != i+1 {
+			panic(fmt.Sprintf("migrações de %s fora de ordem: esperado v%d, recebido v%d", collection, i+1, m.Version))
+		}
+	}
+	schemas[collection] = all
+}
+
+// 🔢 Versão de esquema que este binário grava para a coleção
+func SchemaVersion(collection string) int {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	return len(schemas[collection])
+}
+
+// 🧬 Aplica em memória as migrações from+1..atual aos registros
+func MigrateRecords(collection string, from int, records map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	schemaMu.RLock()
+	migrations := schemas[collection]
+	schemaMu.RUnlock()
+
+	if from > len(migrations) {
+		return nil, fmt.Errorf("%w: %s está na versão %d, este binário entende até a %d",
+			ErrSchemaTooNew, collection, from, len(migrations))
+	}
+	if from == len(migrations) {
+		return records, nil
+	}
+
+	out := make(map[string]json.RawMessage, len(records))
+	for key, raw := range records {
+		record := map[string]interface{}{}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("registro '%s' de %s inválido: %w", key, collection, err)
+		}
+
+		for _, m := range migrations[from:] {
+			if err := m.Up(key, record); err != nil {
+				return nil, fmt.Errorf("migração v%d de %s falhou no registro '%s': %w", m.Version, collection, key, err)
+			}
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = data
+	}
+	return out, nil
+}
+
+// 🧬 Atualiza o arquivo JSON da coleção para a versão atual.
+// O original (snapshot + journal) é copiado para <arquivo>.v<N>-<data>.bak antes
+// da reescrita; arquivos de versão mais nova são recusados com ErrSchemaTooNew.
+func MigrateFile(collection, path string) error {
+	latest := SchemaVersion(collection)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	version, _, err := decodeSnapshot(path, data)
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("%w: %s está na versão %d, este binário entende até a %d",
+			ErrSchemaTooNew, path, version, latest)
+	}
+	if version == latest {
+		return nil
+	}
+
+	// 📖 Snapshot + journal pendente (ambos no formato da versão antiga)
+	_, records, err := ReadTableFile(path)
+	if err != nil {
+		return err
+	}
+
+	// 💾 Backup do estado pré-migração
+	backup := fmt.Sprintf("%s.v%d-%s.bak", path, version, time.Now().Format("20060102-150405"))
+	if err := WriteFileAtomic(backup, data, 0644); err != nil {
+		return fmt.Errorf("erro ao criar backup de %s: %w", path, err)
+	}
+	journalPath := path + ".journal"
+	if journal, err := os.ReadFile(journalPath); err == nil && len(journal) > 0 {
+		if err := WriteFileAtomic(backup+".journal", journal, 0644); err != nil {
+			return fmt.Errorf("erro ao criar backup do journal de %s: %w", path, err)
+		}
+	}
+
+	migrated, err := MigrateRecords(collection, version, records)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := encodeSnapshot(latest, migrated)
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(path, encoded, 0644); err != nil {
+		return err
+	}
+	// O journal antigo já está incorporado ao snapshot migrado
+	if err := os.Remove(journalPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao limpar journal de %s: %w", path, err)
+	}
+
+	log.Printf("🧬 %s migrado da versão %d para %d (backup em %s)", path, version, latest, backup)
+	return nil
+}
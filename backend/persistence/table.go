@@ -0,0 +1,298 @@
+//backend/persistence/table.go
+
+package persistence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// 📦 Checkpoint automático após esta quantidade de entradas no journal
+const DefaultCheckpointEvery = 64
+
+// 🗃️ Tabela persistida: snapshot JSON (map chave → registro) + write-ahead journal.
+// Toda alteração é gravada primeiro no journal (com fsync); o snapshot é
+// reescrito atomicamente nos checkpoints e o journal é então esvaziado.
+type Table struct {
+	mu              sync.Mutex
+	collection      string
+	version         int
+	path            string
+	journal         *Journal
+	records         map[string]json.RawMessage
+	modTime         time.Time
+	CheckpointEvery int
+}
+
+// 🏷️ Formato do snapshot em disco: cabeçalho de versão + registros
+type snapshotFile struct {
+	SchemaVersion int                        `json:"schema_version"`
+	Records       map[string]json.RawMessage `json:"records"`
+}
+
+// 📂 Abre a tabela da coleção, recuperando snapshot + journal de um crash anterior
+func OpenTable(collection, path string) (*Table, error) {
+	cleanupTempFiles(path)
+
+	t := &Table{
+		collection:      collection,
+		version:         SchemaVersion(collection),
+		path:            path,
+		records:         map[string]json.RawMessage{},
+		CheckpointEvery: DefaultCheckpointEvery,
+	}
+
+	if err := t.readSnapshot(); err != nil {
+		return nil, err
+	}
+
+	journal, err := OpenJournal(path + ".journal")
+	if err != nil {
+		return nil, err
+	}
+	t.journal = journal
+
+	replayed := 0
+	err = journal.Replay(func(e Entry) error {
+		t.apply(e)
+		replayed++
+		return nil
+	})
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	// ♻️ Consolida o que foi recuperado do journal em um snapshot novo
+	if replayed > 0 {
+		log.Printf("♻️ %d operações recuperadas do journal de %s", replayed, path)
+		if err := t.checkpointLocked(); err != nil {
+			journal.Close()
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// 📖 Lê o snapshot atual do disco (arquivo ausente = tabela vazia)
+func (t *Table) readSnapshot() error {
+	info, err := os.Stat(t.path)
+	if os.IsNotExist(err) {
+		t.records = map[string]json.RawMessage{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return err
+	}
+
+	version, records, err := decodeSnapshot(t.path, data)
+	if err != nil {
+		return err
+	}
+	// 🧬 Arquivo antigo editado por fora: migra em memória (o disco é
+	// atualizado no próximo checkpoint)
+	if version != t.version {
+		if records, err = MigrateRecords(t.collection, version, records); err != nil {
+			return err
+		}
+	}
+
+	t.records = records
+	t.modTime = info.ModTime()
+	return nil
+}
+
+// 📖 Decodifica o snapshot; arquivos sem cabeçalho (mapa plano) são a versão 0
+func decodeSnapshot(path string, data []byte) (int, map[string]json.RawMessage, error) {
+	top := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &top); err != nil {
+			return 0, nil, fmt.Errorf("snapshot %s corrompido: %w", path, err)
+		}
+	}
+
+	rawVersion, hasVersion := top["schema_version"]
+	rawRecords, hasRecords := top["records"]
+	if hasVersion && hasRecords {
+		var header snapshotFile
+		if err := json.Unmarshal(rawVersion, &header.SchemaVersion); err == nil {
+			if err := json.Unmarshal(rawRecords, &header.Records); err != nil {
+				return 0, nil, fmt.Errorf("snapshot %s corrompido: %w", path, err)
+			}
+			if header.Records == nil {
+				header.Records = map[string]json.RawMessage{}
+			}
+			return header.SchemaVersion, header.Records, nil
+		}
+	}
+
+	if top == nil {
+		top = map[string]json.RawMessage{}
+	}
+	return 0, top, nil
+}
+
+// 📝 Serializa o snapshot com o cabeçalho de versão
+func encodeSnapshot(version int, records map[string]json.RawMessage) ([]byte, error) {
+	return json.MarshalIndent(snapshotFile{SchemaVersion: version, Records: records}, "", "  ")
+}
+
+// 📖 Lê snapshot + journal sem alterar nenhum dos arquivos (importação, inspeção).
+// Retorna também a versão de esquema em que os registros estão.
+func ReadTableFile(path string) (int, map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, nil, err
+	}
+	version, records, err := decodeSnapshot(path, data)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	journal, err := os.ReadFile(path + ".journal")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return version, records, nil
+		}
+		return 0, nil, err
+	}
+	t := &Table{records: records}
+	for _, line := range bytes.SplitAfter(journal, []byte("\n")) {
+		if !bytes.HasSuffix(line, []byte("\n")) {
+			break // escrita interrompida
+		}
+		var entry Entry
+		if err := json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil {
+			break
+		}
+		t.apply(entry)
+	}
+	return version, t.records, nil
+}
+
+// 🔁 Aplica uma entrada do journal aos registros em memória
+func (t *Table) apply(e Entry) {
+	switch e.Op {
+	case OpPut:
+		t.records[e.Key] = e.Data
+	case OpDelete:
+		delete(t.records, e.Key)
+	}
+}
+
+// 📋 Cópia dos registros brutos atuais
+func (t *Table) Records() map[string]json.RawMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]json.RawMessage, len(t.records))
+	for k, v := range t.records {
+		out[k] = v
+	}
+	return out
+}
+
+// 💾 Insere ou substitui um registro
+func (t *Table) Put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.journal.Append(OpPut, key, data); err != nil {
+		return err
+	}
+	t.records[key] = data
+	return t.maybeCheckpointLocked()
+}
+
+// 🗑️ Remove um registro
+func (t *Table) Delete(key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.records[key]; !ok {
+		return nil
+	}
+	if err := t.journal.Append(OpDelete, key, nil); err != nil {
+		return err
+	}
+	delete(t.records, key)
+	return t.maybeCheckpointLocked()
+}
+
+func (t *Table) maybeCheckpointLocked() error {
+	if t.CheckpointEvery > 0 && t.journal.Len() >= t.CheckpointEvery {
+		return t.checkpointLocked()
+	}
+	return nil
+}
+
+// 📸 Grava o snapshot completo e esvazia o journal
+func (t *Table) Checkpoint() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.checkpointLocked()
+}
+
+func (t *Table) checkpointLocked() error {
+	data, err := encodeSnapshot(t.version, t.records)
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(t.path, data, 0644); err != nil {
+		return err
+	}
+	if info, err := os.Stat(t.path); err == nil {
+		t.modTime = info.ModTime()
+	}
+	return t.journal.Truncate()
+}
+
+// 🔄 Recarrega o snapshot se o arquivo foi editado fora do processo,
+// reaplicando por cima as operações ainda pendentes no journal
+func (t *Table) ReloadIfChanged() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, err := os.Stat(t.path)
+	if err != nil || info.ModTime().Equal(t.modTime) {
+		return false, nil
+	}
+
+	previous := t.records
+	if err := t.readSnapshot(); err != nil {
+		t.records = previous
+		return false, err
+	}
+	err = t.journal.Replay(func(e Entry) error {
+		t.apply(e)
+		return nil
+	})
+	return true, err
+}
+
+// 🔒 Faz o checkpoint final e fecha o journal
+func (t *Table) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.checkpointLocked(); err != nil {
+		return err
+	}
+	return t.journal.Close()
+}
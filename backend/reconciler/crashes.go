@@ -0,0 +1,135 @@
+//backend/reconciler/crashes.go
+
+package reconciler
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+)
+
+// 📄 Linhas finais do log guardadas em cada relatório de crash
+const crashLogLines = 50
+
+// 🧹 Relatórios saem junto com a aplicação
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		if err := store.CrashReportStore.DeleteByApp(app.ID); err != nil {
+			log.Printf("⚠️ Erro ao remover relatórios de crash da aplicação %s: %v", app.ID, err)
+		}
+	})
+}
+
+// 💥 Registra a saída do container (uma vez por FinishedAt) e calcula quando religar.
+// Crashes seguidos dobram a espera até MaxRestartDelay; execução estável zera a contagem.
+func (r *Reconciler) noteCrash(app *models.App, info *engine.Info) *models.CrashLoop {
+	previous := app.CrashLoop
+	if info.FinishedAt.IsZero() {
+		return previous // criado e nunca iniciado: não é crash
+	}
+	if previous != nil && previous.LastCrash != nil && !info.FinishedAt.After(*previous.LastCrash) {
+		return previous // crash já registrado
+	}
+
+	crashes := 1
+	if previous != nil && info.FinishedAt.Sub(info.StartedAt) < r.cfg.StableAfter {
+		crashes = previous.Crashes + 1
+	}
+	backoff := r.backoff(crashes)
+	finished := info.FinishedAt
+	next := finished.Add(backoff)
+	loop := &models.CrashLoop{
+		Crashes:        crashes,
+		BackoffSeconds: int(backoff / time.Second),
+		LastCrash:      &finished,
+		NextRestart:    &next,
+	}
+	// 🔻 Fora do ar até o reinício: status parado (o usuário pode religar na hora, zerando a espera)
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		a.Status = models.StatusStopped
+		a.CrashLoop = loop.Clone()
+	}); err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao registrar crash de %s: %v", app.ID, err)
+	}
+
+	report := &models.CrashReport{
+		ID:        fmt.Sprintf("%s/%d", app.ID, finished.UnixNano()),
+		AppID:     app.ID,
+		Username:  app.Username,
+		ExitCode:  info.ExitCode,
+		OOMKilled: info.OOMKilled,
+		Error:     info.Error,
+		Crashes:   crashes,
+		StartedAt: info.StartedAt,
+		CrashedAt: finished,
+		LogLines:  lastLogLines(app),
+	}
+	if err := store.CrashReportStore.Add(report); err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao salvar relatório de crash de %s: %v", app.ID, err)
+	}
+
+	reason := fmt.Sprintf("código %d", info.ExitCode)
+	if info.OOMKilled {
+		reason += ", sem memória (OOM)"
+	}
+	services.Log(app.ID, app.Username, app.Plan, fmt.Sprintf("💥 Aplicação saiu (%s); religando em %s (crash %d seguido)", reason, backoff, crashes))
+	if crashes == models.CrashLoopThreshold {
+		services.Log(app.ID, app.Username, app.Plan, "🔁 Crash loop detectado: reinícios seguem com espera crescente até a aplicação ficar estável")
+	}
+	return loop
+}
+
+// ⏳ Espera antes do reinício N: RestartDelay, 2×, 4×... limitada a MaxRestartDelay
+func (r *Reconciler) backoff(crashes int) time.Duration {
+	delay := r.cfg.RestartDelay
+	for i := 1; i < crashes && delay < r.cfg.MaxRestartDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.cfg.MaxRestartDelay {
+		delay = r.cfg.MaxRestartDelay
+	}
+	return delay
+}
+
+// ✅ Rodando há StableAfter: a sequência de crashes termina
+func (r *Reconciler) checkStable(app *models.App, info *engine.Info) {
+	if app.CrashLoop == nil {
+		return
+	}
+	if remaining := r.cfg.StableAfter - time.Since(info.StartedAt); remaining > 0 {
+		r.queue.AddAfter(app.ID, remaining)
+		return
+	}
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) { a.CrashLoop = nil }); err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao zerar crashes de %s: %v", app.ID, err)
+		return
+	}
+	if app.CrashLoop.Crashes >= models.CrashLoopThreshold {
+		services.Log(app.ID, app.Username, app.Plan, "✅ Aplicação estável novamente: crash loop encerrado")
+	}
+}
+
+// 📄 Últimas linhas do log do container (segredos mascarados)
+func lastLogLines(app *models.App) []string {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	var out bytes.Buffer
+	opts := engine.LogsOptions{Stdout: true, Stderr: true, Timestamps: true, Tail: strconv.Itoa(crashLogLines)}
+	if err := engine.Default().Logs(ctx, app.ContainerName, opts, &out, &out); err != nil {
+		return []string{"(logs indisponíveis: " + err.Error() + ")"}
+	}
+	text := strings.TrimRight(services.MaskSecrets(app.ID, out.String()), "\n")
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,74 @@
+//backend/reconciler/queue.go
+
+package reconciler
+
+import (
+	"sync"
+	"time"
+)
+
+// 📬 Fila de aplicações a reconciliar, sem duplicatas: uma aplicação enfileirada várias vezes
+// é processada uma vez, e nunca por dois workers ao mesmo tempo (pedidos durante o processamento
+// voltam para a fila quando o worker termina).
+type queue struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	items      []string
+	queued     map[string]bool
+	processing map[string]bool
+	dirty      map[string]bool
+}
+
+func newQueue() *queue {
+	q := &queue{queued: map[string]bool{}, processing: map[string]bool{}, dirty: map[string]bool{}}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// ➕ Enfileira a aplicação (ignorado se já estiver na fila)
+func (q *queue) Add(appID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.queued[appID] {
+		return
+	}
+	if q.processing[appID] {
+		q.dirty[appID] = true
+		return
+	}
+	q.queued[appID] = true
+	q.items = append(q.items, appID)
+	q.cond.Signal()
+}
+
+// ⏳ Enfileira depois do atraso (ex.: aguardar o intervalo entre reinícios)
+func (q *queue) AddAfter(appID string, delay time.Duration) {
+	time.AfterFunc(delay, func() { q.Add(appID) })
+}
+
+// 📥 Próxima aplicação (bloqueia até haver uma)
+func (q *queue) Get() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 {
+		q.cond.Wait()
+	}
+	appID := q.items[0]
+	q.items = q.items[1:]
+	delete(q.queued, appID)
+	q.processing[appID] = true
+	return appID
+}
+
+// ✅ Fim do processamento; pedidos recebidos nesse meio-tempo voltam para a fila
+func (q *queue) Done(appID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.processing, appID)
+	if q.dirty[appID] {
+		delete(q.dirty, appID)
+		q.queued[appID] = true
+		q.items = append(q.items, appID)
+		q.cond.Signal()
+	}
+}
@@ -0,0 +1,342 @@
+//backend/reconciler/reconciler.go
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// ⚙️ Configuração do reconciliador
+type Config struct {
+	Workers         int           // reconciliações simultâneas (RECONCILE_WORKERS, padrão 4)
+	ResyncInterval  time.Duration // varredura completa além dos eventos (RECONCILE_RESYNC_SECONDS, padrão 30)
+	RestartDelay    time.Duration // espera após o primeiro crash; dobra a cada crash seguido (RECONCILE_RESTART_DELAY_SECONDS, padrão 10)
+	MaxRestartDelay time.Duration // teto da espera entre reinícios (RECONCILE_MAX_RESTART_DELAY_SECONDS, padrão 300)
+	StableAfter     time.Duration // tempo rodando que zera a contagem de crashes (RECONCILE_STABLE_SECONDS, padrão 600)
+}
+
+// ⚙️ Configuração lida das variáveis RECONCILE_*
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Workers:         4,
+		ResyncInterval:  30 * time.Second,
+		RestartDelay:    10 * time.Second,
+		MaxRestartDelay: 5 * time.Minute,
+		StableAfter:     10 * time.Minute,
+	}
+	if n, err := strconv.Atoi(os.Getenv("RECONCILE_WORKERS")); err == nil && n > 0 {
+		cfg.Workers = n
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("RECONCILE_RESYNC_SECONDS")); err == nil && seconds > 0 {
+		cfg.ResyncInterval = time.Duration(seconds) * time.Second
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("RECONCILE_RESTART_DELAY_SECONDS")); err == nil && seconds >= 0 {
+		cfg.RestartDelay = time.Duration(seconds) * time.Second
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("RECONCILE_MAX_RESTART_DELAY_SECONDS")); err == nil && seconds >= 0 {
+		cfg.MaxRestartDelay = time.Duration(seconds) * time.Second
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("RECONCILE_STABLE_SECONDS")); err == nil && seconds > 0 {
+		cfg.StableAfter = time.Duration(seconds) * time.Second
+	}
+	return cfg
+}
+
+// 🎯 Converge cada aplicação do AppStore para o estado desejado (rodando/parada, imagem do último deploy)
+type Reconciler struct {
+	cfg   Config
+	queue *queue
+
+	mu     sync.Mutex
+	warned map[string]string // aplicação → último aviso registrado (evita repetir a cada varredura)
+}
+
+var (
+	defaultMu         sync.RWMutex
+	defaultReconciler *Reconciler
+)
+
+// 🎯 Reconciliador ativo (nil antes de Start)
+func Default() *Reconciler {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultReconciler
+}
+
+// 🚀 Sobe os workers, acompanha os eventos do runtime e faz varreduras periódicas
+func Start(cfg Config) *Reconciler {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxRestartDelay < cfg.RestartDelay {
+		cfg.MaxRestartDelay = cfg.RestartDelay
+	}
+	r := &Reconciler{cfg: cfg, queue: newQueue(), warned: map[string]string{}}
+	for i := 0; i < cfg.Workers; i++ {
+		go r.worker()
+	}
+	go r.watch(context.Background())
+
+	defaultMu.Lock()
+	defaultReconciler = r
+	defaultMu.Unlock()
+	log.Printf("🎯 Reconciliador iniciado (%d workers, varredura a cada %s)", cfg.Workers, cfg.ResyncInterval)
+	return r
+}
+
+// ➕ Pede a reconciliação da aplicação
+func (r *Reconciler) Enqueue(appID string) {
+	r.queue.Add(appID)
+}
+
+// 🔄 Enfileira todas as aplicações
+func (r *Reconciler) Resync() {
+	for _, app := range store.AppStore.List() {
+		r.queue.Add(app.ID)
+	}
+}
+
+func (r *Reconciler) worker() {
+	for {
+		appID := r.queue.Get()
+		r.reconcile(appID)
+		r.queue.Done(appID)
+	}
+}
+
+// 📡 Eventos de container disparam a reconciliação da aplicação; a varredura cobre eventos perdidos
+func (r *Reconciler) watch(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ResyncInterval)
+	defer ticker.Stop()
+
+	r.Resync()
+	for ctx.Err() == nil {
+		events, errs := engine.Default().Events(ctx)
+	stream:
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					break stream
+				}
+				r.handleEvent(ev)
+			case <-ticker.C:
+				r.Resync()
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// 🔌 Stream encerrado (daemon reiniciado?): reconecta e revisa tudo
+		if err := <-errs; err != nil {
+			log.Println("⚠️ Reconciliador: stream de eventos interrompido:", err)
+		}
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+		r.Resync()
+	}
+}
+
+func (r *Reconciler) handleEvent(ev engine.Event) {
+	switch ev.Action {
+	case "start", "die", "stop", "kill", "oom", "destroy":
+	default:
+		return
+	}
+	if app, ok := store.AppStore.FindByContainer(ev.Name); ok {
+		r.queue.Add(app.ID)
+	}
+}
+
+// 🎯 Compara o desejado com o observado e age uma vez; o que falhar volta na próxima varredura
+func (r *Reconciler) reconcile(appID string) {
+	app, ok := store.AppStore.Get(appID)
+	if !ok {
+		r.clearWarning(appID) // aplicação removida
+		return
+	}
+	if app.ContainerName == "" {
+		return
+	}
+	desired := desiredStatus(app)
+	rt := engine.Default()
+
+	ctx, cancel := engine.Timeout()
+	info, err := rt.Inspect(ctx, app.ContainerName)
+	cancel()
+	if engine.IsNotFound(err) {
+		r.recreateMissing(app, desired)
+		return
+	}
+	if err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao inspecionar %s: %v", app.ContainerName, err)
+		return
+	}
+
+	// 🆔 Imagem: aplicações anteriores ao registro adotam a do container; outra imagem = deploy novo
+	if app.ImageID == "" && info.Image != "" {
+		if _, err := store.AppStore.Update(app.ID, func(a *models.App) { a.ImageID = info.Image }); err != nil {
+			log.Printf("⚠️ Reconciliador: erro ao registrar imagem de %s: %v", app.ID, err)
+		}
+	} else if app.ImageID != "" && info.Image != app.ImageID {
+		r.recreateOutdated(app, desired)
+		return
+	}
+
+	switch {
+	case desired == models.StatusRunning && !info.Running:
+		if loop := r.noteCrash(app, info); loop != nil && loop.NextRestart != nil {
+			if wait := time.Until(*loop.NextRestart); wait > 0 {
+				r.queue.AddAfter(app.ID, wait)
+				return
+			}
+		}
+		r.start(app, info)
+	case desired == models.StatusRunning:
+		r.clearWarning(app.ID)
+		r.checkStable(app, info)
+	case desired == models.StatusStopped && info.Running:
+		r.stop(app)
+	default:
+		r.clearWarning(app.ID)
+	}
+}
+
+// ▶️ Container parado que deveria estar rodando (crash, reinício do host, kill externo)
+func (r *Reconciler) start(app *models.App, info *engine.Info) {
+	recreated, err := services.SyncContainerSpec(app, true)
+	if err == nil && !recreated {
+		ctx, cancel := engine.Timeout()
+		err = engine.Default().Start(ctx, app.ContainerName)
+		cancel()
+	}
+	if err != nil {
+		r.warn(app, fmt.Sprintf("❌ Reconciliador: falha ao iniciar a aplicação: %v", err))
+		return
+	}
+	r.clearWarning(app.ID)
+	setStatus(app.ID, models.StatusRunning)
+	services.Log(app.ID, app.Username, app.Plan, fmt.Sprintf("🎯 Aplicação religada pelo reconciliador (saiu com código %d)", info.ExitCode))
+}
+
+// ⏸️ Container rodando que o usuário deixou parado
+func (r *Reconciler) stop(app *models.App) {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+	if err := rt.Update(ctx, app.ContainerName, engine.Update{RestartPolicy: "no"}); err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao desativar reinício de %s: %v", app.ContainerName, err)
+	}
+	if err := rt.Stop(ctx, app.ContainerName, 10*time.Second); err != nil {
+		r.warn(app, fmt.Sprintf("❌ Reconciliador: falha ao parar a aplicação: %v", err))
+		return
+	}
+	r.clearWarning(app.ID)
+	setStatus(app.ID, models.StatusStopped)
+	services.Log(app.ID, app.Username, app.Plan, "🎯 Aplicação parada pelo reconciliador (estado desejado: parada)")
+}
+
+// 🆕 Container removido por fora: recria a partir da imagem do último deploy
+func (r *Reconciler) recreateMissing(app *models.App, desired models.AppStatus) {
+	if desired != models.StatusRunning {
+		return // parada e sem container: nada a fazer até o próximo start/rebuild
+	}
+	if app.ImageID == "" {
+		r.warn(app, "⚠️ Reconciliador: container ausente e deploy sem imagem registrada; faça um rebuild")
+		return
+	}
+
+	imageName := utils.GetContainerName(app.Username, app.ID)
+	ctx, cancel := engine.Timeout()
+	exists, err := engine.Default().ImageExists(ctx, imageName)
+	cancel()
+	if err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao verificar imagem %s: %v", imageName, err)
+		return
+	}
+	if !exists {
+		r.warn(app, fmt.Sprintf("⚠️ Reconciliador: container e imagem %s ausentes; faça um rebuild", imageName))
+		return
+	}
+
+	if _, err := services.CreateContainer(app.ID, imageName, "", "", nil, app.Username); err != nil {
+		r.warn(app, fmt.Sprintf("❌ Reconciliador: falha ao recriar container: %v", err))
+		return
+	}
+	r.clearWarning(app.ID)
+	setStatus(app.ID, models.StatusRunning)
+	services.Log(app.ID, app.Username, app.Plan, "🎯 Container ausente recriado pelo reconciliador")
+}
+
+// 🆕 Container em imagem diferente da do último deploy: recria mantendo o estado desejado
+func (r *Reconciler) recreateOutdated(app *models.App, desired models.AppStatus) {
+	if err := services.RecreateAppContainer(app, desired == models.StatusRunning); err != nil {
+		r.warn(app, fmt.Sprintf("❌ Reconciliador: falha ao atualizar a imagem do container: %v", err))
+		return
+	}
+	r.clearWarning(app.ID)
+	setStatus(app.ID, desired)
+	services.Log(app.ID, app.Username, app.Plan, "🎯 Container recriado pelo reconciliador com a imagem do último deploy")
+}
+
+// 🎯 Estado desejado; aplicações anteriores ao campo adotam o Status gravado
+func desiredStatus(app *models.App) models.AppStatus {
+	if app.DesiredStatus != "" {
+		return app.DesiredStatus
+	}
+	desired := models.StatusRunning
+	if app.Status == models.StatusStopped {
+		desired = models.StatusStopped
+	}
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		if a.DesiredStatus == "" {
+			a.DesiredStatus = desired
+		}
+	}); err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao gravar estado desejado de %s: %v", app.ID, err)
+	}
+	return desired
+}
+
+func setStatus(appID string, status models.AppStatus) {
+	if _, err := store.AppStore.Update(appID, func(a *models.App) {
+		a.Status = status
+		if status == models.StatusRunning {
+			a.StartTime = time.Now()
+		}
+	}); err != nil {
+		log.Printf("⚠️ Reconciliador: erro ao gravar status de %s: %v", appID, err)
+	}
+}
+
+// 📝 Registra o problema no log da aplicação uma vez (repete só se a mensagem mudar)
+func (r *Reconciler) warn(app *models.App, message string) {
+	r.mu.Lock()
+	repeated := r.warned[app.ID] == message
+	r.warned[app.ID] = message
+	r.mu.Unlock()
+	if !repeated {
+		services.Log(app.ID, app.Username, app.Plan, message)
+	}
+}
+
+func (r *Reconciler) clearWarning(appID string) {
+	r.mu.Lock()
+	delete(r.warned, appID)
+	r.mu.Unlock()
+}
@@ -0,0 +1,275 @@
+//backend/reconciler/reconciler_test.go
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/store"
+)
+
+// 🧪 Pasta temporária (storage/ e database/ relativos) e runtime em memória com relógio real
+var rt *engine.FakeRuntime
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "reconciler-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	persistence.SetBackend(persistence.NewJSONBackend())
+	rt = engine.NewFake()
+	rt.SetClock(time.Now)
+	engine.SetDefault(rt)
+	loaders := []error{
+		store.UserStore.Load("./database/users.json"),
+		store.LoadAppStoreFromDisk("./database/appstore.json"),
+		store.LoadAppEnvStoreFromDisk(store.DefaultAppEnvStorePath),
+		store.LoadVolumeStoreFromDisk(store.DefaultVolumeStorePath),
+		store.LoadDatabaseStoreFromDisk(store.DefaultDatabaseStorePath),
+		store.LoadCrashReportStoreFromDisk(store.DefaultCrashReportStorePath),
+	}
+	for _, err := range loaders {
+		if err != nil {
+			fmt.Println("erro ao carregar stores:", err)
+			return 1
+		}
+	}
+	return m.Run()
+}
+
+// 🎯 Reconciliador sem workers: cada teste chama reconcile e observa o resultado
+func newTestReconciler(restartDelay time.Duration) *Reconciler {
+	return &Reconciler{
+		cfg: Config{
+			Workers:         1,
+			ResyncInterval:  time.Hour,
+			RestartDelay:    restartDelay,
+			MaxRestartDelay: time.Hour,
+			StableAfter:     10 * time.Minute,
+		},
+		queue:  newQueue(),
+		warned: map[string]string{},
+	}
+}
+
+// 🐳 Aplicação rodando com o container na imagem do último deploy
+func newRunningApp(t *testing.T, username, appID string) *models.App {
+	t.Helper()
+	ctx := context.Background()
+	if err := store.UserStore.Save(&models.User{Username: username, Plan: models.PlanPro}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+	name := username + "-" + appID
+	rt.AddImage(name)
+	imageID, _ := rt.ImageID(ctx, name)
+	network := "vc-net-" + username
+	if err := rt.EnsureNetwork(ctx, network, nil, nil); err != nil {
+		t.Fatalf("erro ao criar rede: %v", err)
+	}
+	if _, err := rt.Create(ctx, engine.Spec{Name: name, Image: name, NetworkMode: network}); err != nil {
+		t.Fatalf("erro ao criar container: %v", err)
+	}
+	if err := rt.Start(ctx, name); err != nil {
+		t.Fatalf("erro ao iniciar container: %v", err)
+	}
+	app := &models.App{
+		ID:            appID,
+		Username:      username,
+		Plan:          string(models.PlanPro),
+		ContainerName: name,
+		Status:        models.StatusRunning,
+		DesiredStatus: models.StatusRunning,
+		ImageID:       imageID,
+	}
+	if err := store.AppStore.Save(app); err != nil {
+		t.Fatalf("erro ao salvar aplicação: %v", err)
+	}
+	return app
+}
+
+func inspect(t *testing.T, name string) *engine.Info {
+	t.Helper()
+	info, err := rt.Inspect(context.Background(), name)
+	if err != nil {
+		t.Fatalf("erro ao inspecionar %s: %v", name, err)
+	}
+	return info
+}
+
+func getApp(t *testing.T, appID string) *models.App {
+	t.Helper()
+	app, ok := store.AppStore.Get(appID)
+	if !ok {
+		t.Fatalf("aplicação %s não encontrada", appID)
+	}
+	return app
+}
+
+func TestReconcileRestartsCrashedApp(t *testing.T) {
+	app := newRunningApp(t, "crash", "c1")
+	r := newTestReconciler(0)
+
+	if err := rt.Crash(app.ContainerName, 137, true); err != nil {
+		t.Fatalf("Crash: %v", err)
+	}
+	r.reconcile(app.ID)
+
+	if !inspect(t, app.ContainerName).Running {
+		t.Fatalf("container não foi religado")
+	}
+	stored := getApp(t, app.ID)
+	if stored.Status != models.StatusRunning {
+		t.Fatalf("status = %s, esperado running", stored.Status)
+	}
+	if stored.CrashLoop == nil || stored.CrashLoop.Crashes != 1 {
+		t.Fatalf("crash não registrado: %+v", stored.CrashLoop)
+	}
+	reports := store.CrashReportStore.ListByApp(app.ID)
+	if len(reports) != 1 || !reports[0].OOMKilled || reports[0].ExitCode != 137 {
+		t.Fatalf("relatório de crash inesperado: %+v", reports)
+	}
+}
+
+func TestReconcileWaitsBackoffBeforeRestart(t *testing.T) {
+	app := newRunningApp(t, "backoff", "b1")
+	r := newTestReconciler(time.Hour)
+
+	if err := rt.Crash(app.ContainerName, 1, false); err != nil {
+		t.Fatalf("Crash: %v", err)
+	}
+	r.reconcile(app.ID)
+
+	if inspect(t, app.ContainerName).Running {
+		t.Fatalf("container religado antes da espera")
+	}
+	stored := getApp(t, app.ID)
+	if stored.Status != models.StatusStopped {
+		t.Fatalf("status = %s, esperado stopped durante a espera", stored.Status)
+	}
+	if stored.CrashLoop == nil || stored.CrashLoop.NextRestart == nil || !stored.CrashLoop.NextRestart.After(time.Now()) {
+		t.Fatalf("próximo reinício não agendado: %+v", stored.CrashLoop)
+	}
+
+	// 🔁 O mesmo crash visto de novo não conta duas vezes
+	r.reconcile(app.ID)
+	if again := getApp(t, app.ID); again.CrashLoop.Crashes != 1 {
+		t.Fatalf("crash contado %d vezes", again.CrashLoop.Crashes)
+	}
+}
+
+func TestReconcileKeepsStoppedAppStopped(t *testing.T) {
+	app := newRunningApp(t, "stopper", "s1")
+	r := newTestReconciler(0)
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) { a.DesiredStatus = models.StatusStopped }); err != nil {
+		t.Fatalf("erro ao gravar estado desejado: %v", err)
+	}
+
+	r.reconcile(app.ID) // container ligado por fora
+
+	if inspect(t, app.ContainerName).Running {
+		t.Fatalf("container continua rodando com estado desejado parado")
+	}
+	if stored := getApp(t, app.ID); stored.Status != models.StatusStopped {
+		t.Fatalf("status = %s, esperado stopped", stored.Status)
+	}
+}
+
+func TestReconcileRecreatesMissingContainer(t *testing.T) {
+	app := newRunningApp(t, "missing", "m1")
+	r := newTestReconciler(0)
+	if err := rt.Remove(context.Background(), app.ContainerName, true); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	r.reconcile(app.ID)
+
+	info := inspect(t, app.ContainerName)
+	if !info.Running || info.Image != app.ImageID {
+		t.Fatalf("container recriado errado: running=%v imagem=%s", info.Running, info.Image)
+	}
+}
+
+func TestReconcileLeavesStoppedMissingContainer(t *testing.T) {
+	app := newRunningApp(t, "gone", "g1")
+	r := newTestReconciler(0)
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) { a.DesiredStatus = models.StatusStopped }); err != nil {
+		t.Fatalf("erro ao gravar estado desejado: %v", err)
+	}
+	if err := rt.Remove(context.Background(), app.ContainerName, true); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	r.reconcile(app.ID)
+
+	if _, err := rt.Inspect(context.Background(), app.ContainerName); !engine.IsNotFound(err) {
+		t.Fatalf("container parado e removido não deveria voltar: %v", err)
+	}
+}
+
+func TestReconcileRecreatesOutdatedImage(t *testing.T) {
+	app := newRunningApp(t, "outdated", "o1")
+	r := newTestReconciler(0)
+	before := inspect(t, app.ContainerName)
+
+	rt.AddImage(app.ContainerName) // deploy novo da mesma tag
+	imageID, _ := rt.ImageID(context.Background(), app.ContainerName)
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) { a.ImageID = imageID }); err != nil {
+		t.Fatalf("erro ao registrar imagem: %v", err)
+	}
+
+	r.reconcile(app.ID)
+
+	after := inspect(t, app.ContainerName)
+	if after.ID == before.ID || after.Image != imageID {
+		t.Fatalf("container não foi recriado na imagem nova: %s → %s", before.Image, after.Image)
+	}
+	if !after.Running {
+		t.Fatalf("container recriado parado")
+	}
+}
+
+func TestReconcileAdoptsImageOfLegacyApp(t *testing.T) {
+	app := newRunningApp(t, "legacy", "l1")
+	r := newTestReconciler(0)
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		a.ImageID = ""
+		a.DesiredStatus = ""
+	}); err != nil {
+		t.Fatalf("erro ao limpar aplicação: %v", err)
+	}
+
+	r.reconcile(app.ID)
+
+	stored := getApp(t, app.ID)
+	if stored.ImageID != inspect(t, app.ContainerName).Image {
+		t.Fatalf("imagem não adotada: %q", stored.ImageID)
+	}
+	if stored.DesiredStatus != models.StatusRunning {
+		t.Fatalf("estado desejado = %q, esperado running", stored.DesiredStatus)
+	}
+}
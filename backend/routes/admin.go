@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"virtuscloud/backend/audit"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/services"
@@ -109,6 +110,9 @@ func AdminPromoteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	audit.SetTarget(r, req.Email)
+	audit.SetDetail(r, "role", req.Role)
+
 	if req.Email == "" || req.Role == "" {
 		http.Error(w, "email e cargo são obrigatórios", http.StatusBadRequest)
 		return
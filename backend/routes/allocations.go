@@ -0,0 +1,86 @@
+//backend/routes/allocations.go
+
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/utils"
+)
+
+// 📒 GET /api/app/allocations — fatias de RAM/CPU de cada aplicação e sobra do plano
+func AppAllocationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	ledger, err := limits.UserLedger(username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"ledger":    ledger,
+		"freeRamMB": ledger.FreeRAMMB(),
+		"freeCpus":  ledger.FreeCPUs(),
+	})
+}
+
+// 📐 POST /api/app/resources — redimensiona a fatia de RAM/CPU de uma aplicação
+func ResizeAppHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID    string  `json:"id"`
+		RAMMB int     `json:"ramMB"`
+		CPUs  float32 `json:"cpus"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "ramMB", fmt.Sprint(payload.RAMMB))
+	audit.SetDetail(r, "cpus", fmt.Sprintf("%.2f", payload.CPUs))
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(payload.ID)
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	updated, err := limits.ResizeAllocation(username, app.ID, payload.RAMMB, payload.CPUs)
+	if err != nil {
+		utils.WriteJSONStatus(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// ⚙️ Container existente recebe o novo limite na hora (sem reiniciar)
+	applied := true
+	if err := services.ApplyAppAllocation(updated); err != nil {
+		log.Println("⚠️ Fatia salva, mas não aplicada ao container:", err)
+		applied = false
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"message":        "Recursos da aplicação atualizados com sucesso!",
+		"id":             updated.ID,
+		"allocatedRamMB": updated.AllocatedRAMMB,
+		"allocatedCpus":  updated.AllocatedCPUs,
+		"applied":        applied,
+	})
+}
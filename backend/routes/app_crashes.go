@@ -0,0 +1,34 @@
+//backend/routes/app_crashes.go
+
+package routes
+
+import (
+	"net/http"
+
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 💥 GET /api/app/crashes?id= — relatórios de crash (mais recente primeiro) e estado do crash loop
+func ListAppCrashesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(r.URL.Query().Get("id"))
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"id":        app.ID,
+		"state":     app.State(),
+		"crashLoop": app.CrashLoop,
+		"reports":   store.CrashReportStore.ListByApp(app.ID),
+	})
+}
@@ -0,0 +1,145 @@
+//backend/routes/app_env.go
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/utils"
+)
+
+// 🔑 GET /api/app/env?id= — variáveis da aplicação (segredos sempre mascarados)
+func ListAppEnvHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(r.URL.Query().Get("id"))
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"id":   app.ID,
+		"vars": services.ListAppEnv(app.ID),
+	})
+}
+
+// 💾 POST /api/app/env/set — cria ou substitui variáveis {id, vars:[{key,value,secret}], restart}
+func SetAppEnvHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID      string              `json:"id"`
+		Vars    []services.EnvInput `json:"vars"`
+		Restart bool                `json:"restart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	keys := make([]string, 0, len(payload.Vars))
+	for _, v := range payload.Vars {
+		keys = append(keys, v.Key) // 🙈 só as chaves vão para a auditoria, nunca os valores
+	}
+	audit.SetDetail(r, "keys", strings.Join(keys, ","))
+
+	app, ok := ownedEnvApp(w, r, payload.ID)
+	if !ok {
+		return
+	}
+	if err := services.SetAppEnv(app, payload.Vars); err != nil {
+		utils.WriteJSONStatus(w, envErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeEnvChange(w, r, app, payload.Restart, "Variáveis salvas com sucesso!")
+}
+
+// 🗑️ POST /api/app/env/delete — remove variáveis {id, keys:[...], restart}
+func DeleteAppEnvHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID      string   `json:"id"`
+		Keys    []string `json:"keys"`
+		Restart bool     `json:"restart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "keys", strings.Join(payload.Keys, ","))
+
+	app, ok := ownedEnvApp(w, r, payload.ID)
+	if !ok {
+		return
+	}
+	removed, err := services.DeleteAppEnv(app, payload.Keys)
+	if err != nil {
+		utils.WriteJSONStatus(w, envErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	if len(removed) == 0 {
+		utils.WriteJSONStatus(w, http.StatusNotFound, map[string]string{"error": "Nenhuma das variáveis informadas existe"})
+		return
+	}
+	writeEnvChange(w, r, app, payload.Restart, "Variáveis removidas com sucesso!")
+}
+
+// 🔐 Confere se a aplicação pertence ao usuário autenticado
+func ownedEnvApp(w http.ResponseWriter, r *http.Request, id string) (*models.App, bool) {
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(id)
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return nil, false
+	}
+	return app, true
+}
+
+// 🔁 Aplica agora (restart=true) ou deixa para o próximo start/rebuild, e devolve a lista mascarada
+func writeEnvChange(w http.ResponseWriter, r *http.Request, app *models.App, restart bool, message string) {
+	if restart {
+		if err := services.ApplyAppSpecChange(app); err != nil {
+			audit.Fail(r, err.Error())
+			utils.WriteJSONStatus(w, http.StatusInternalServerError, map[string]interface{}{
+				"error": "Variáveis salvas, mas a aplicação não foi recriada: " + err.Error(),
+				"vars":  services.ListAppEnv(app.ID),
+			})
+			return
+		}
+	} else {
+		message += " Elas valem a partir do próximo start, restart ou rebuild."
+	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"message": message,
+		"applied": restart,
+		"vars":    services.ListAppEnv(app.ID),
+	})
+}
+
+// 🚦 Erros de validação viram 400; o resto é falha interna
+func envErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvalidEnv) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
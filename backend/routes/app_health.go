@@ -0,0 +1,88 @@
+//backend/routes/app_health.go
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/health"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/utils"
+)
+
+// 🩺 GET /api/app/health?id= — probes configurados e último resultado
+func GetAppHealthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(r.URL.Query().Get("id"))
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"id":          app.ID,
+		"healthCheck": app.HealthCheck,
+		"health":      app.Health,
+	})
+}
+
+// ⚙️ POST /api/app/health/set — substitui os probes {id, liveness, readiness}; ambos nulos removem
+func SetAppHealthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID        string        `json:"id"`
+		Liveness  *models.Probe `json:"liveness"`
+		Readiness *models.Probe `json:"readiness"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	if payload.Liveness != nil {
+		audit.SetDetail(r, "liveness", string(payload.Liveness.Type))
+	}
+	if payload.Readiness != nil {
+		audit.SetDetail(r, "readiness", string(payload.Readiness.Type))
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(payload.ID)
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	updated, err := health.Configure(app, &models.HealthCheck{Liveness: payload.Liveness, Readiness: payload.Readiness})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, health.ErrInvalid) {
+			status = http.StatusBadRequest
+		}
+		utils.WriteJSONStatus(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+
+	message := "Health check salvo com sucesso!"
+	if updated.HealthCheck == nil {
+		message = "Health check removido."
+	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"message":     message,
+		"healthCheck": updated.HealthCheck,
+	})
+}
@@ -0,0 +1,142 @@
+//backend/routes/app_log_archive.go
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"virtuscloud/backend/applogs"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/utils"
+)
+
+// 🔐 Aplicação dona dos logs guardados: pelo container (aplicação ativa) ou pelo ID (aplicação já removida,
+// com logs ainda dentro da retenção). O diretório fica sob o usuário autenticado, então não há como ler de outro.
+func logArchiveApp(r *http.Request) (username, appID string, ok bool) {
+	username, _ = middleware.GetUserFromContext(r)
+	id := r.URL.Query().Get("id")
+	if app := services.GetAppByContainerName(id); app != nil {
+		return username, app.ID, app.Username == username
+	}
+	return username, id, applogs.Exists(username, id)
+}
+
+// 🗂️ GET /api/app/logs/files?id= — arquivos de log guardados (arquivados compactados e o atual)
+func ListAppLogFilesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	username, appID, ok := logArchiveApp(r)
+	if !ok {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	files, err := applogs.ListFiles(username, appID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{"id": appID, "files": files})
+}
+
+// 🔎 GET /api/app/logs/search?id=&q=&regex=1&stream=&since=&until=&limit= — busca nos logs guardados
+func SearchAppLogsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	username, appID, ok := logArchiveApp(r)
+	if !ok {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	q := r.URL.Query()
+	query := applogs.Query{
+		Text:   q.Get("q"),
+		Regex:  q.Get("regex") == "1" || q.Get("regex") == "true",
+		Stream: q.Get("stream"),
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit inválido", http.StatusBadRequest)
+			return
+		}
+		query.Limit = n
+	}
+	for param, dst := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if value := q.Get(param); value != "" {
+			t, err := parseLogSince(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s inválido: use RFC3339, segundos Unix ou duração (ex.: 10m)", param), http.StatusBadRequest)
+				return
+			}
+			*dst = t
+		}
+	}
+
+	result, err := applogs.Search(username, appID, query)
+	switch {
+	case errors.Is(err, applogs.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSON(w, result)
+}
+
+// 📥 GET /api/app/logs/download?id=&file= — um arquivo guardado ou, sem file, tudo em um .log.gz
+func DownloadAppLogsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	username, appID, ok := logArchiveApp(r)
+	if !ok {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	name := r.URL.Query().Get("file")
+	files, err := applogs.ListFiles(username, appID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	filename := appID + "-logs.log.gz"
+	contentType := "application/gzip"
+	if name != "" {
+		found := false
+		for _, f := range files {
+			if f.Name == name {
+				found = true
+				if !f.Compressed {
+					contentType = "text/plain; charset=utf-8"
+				}
+			}
+		}
+		if !found {
+			http.Error(w, applogs.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		filename = appID + "-" + name
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := applogs.Download(username, appID, name, w); err != nil {
+		// Cabeçalhos já enviados: só resta registrar e interromper a resposta
+		log.Printf("⚠️ Erro ao enviar logs de %s: %v", appID, err)
+	}
+}
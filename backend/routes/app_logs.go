@@ -0,0 +1,221 @@
+//backend/routes/app_logs.go
+
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/services"
+)
+
+// ⏱️ Streaming de logs: cliente que não consome por sseWriteTimeout é desconectado (retoma com Last-Event-ID)
+const (
+	sseWriteTimeout = 10 * time.Second
+	sseHeartbeat    = 15 * time.Second
+	sseRetryMillis  = 3000
+)
+
+// 📡 GET /api/app/logs/stream?id=&follow=1&tail=100&since=&stdout=1&stderr=1&timestamps=1 — logs em Server-Sent Events.
+// Cada linha chega como evento stdout/stderr (id = instante da linha); avisos da plataforma como evento system.
+func StreamAppLogsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(r.URL.Query().Get("id"))
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	opts, timestamps, err := parseLogStreamQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !opts.Follow {
+		ctx, cancel := engine.Timeout()
+		_, err := engine.Default().Inspect(ctx, app.ContainerName)
+		cancel()
+		if engine.IsNotFound(err) {
+			http.Error(w, "Container da aplicação não encontrado", http.StatusNotFound)
+			return
+		}
+	}
+
+	sse, ok := startSSE(w)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	go sse.keepAlive(ctx)
+
+	err = services.StreamAppLogs(ctx, app, opts, func(line services.LogLine) error {
+		return sse.line(line, timestamps)
+	})
+	switch {
+	case ctx.Err() != nil:
+		// cliente desconectou
+	case err == nil:
+		_ = sse.event("end", "", map[string]string{"reason": "fim dos logs"})
+	case errors.Is(err, services.ErrLogStreamGone):
+		_ = sse.event("end", "", map[string]string{"reason": err.Error()})
+	default:
+		_ = sse.event("error", "", map[string]string{"error": err.Error()})
+	}
+}
+
+// 🔎 Lê follow, tail, since, stdout, stderr e timestamps; retomada via Last-Event-ID (ou ?lastEventId=)
+func parseLogStreamQuery(r *http.Request) (services.LogStreamOptions, bool, error) {
+	q := r.URL.Query()
+	opts := services.LogStreamOptions{
+		Stdout: q.Get("stdout") != "0" && q.Get("stdout") != "false",
+		Stderr: q.Get("stderr") != "0" && q.Get("stderr") != "false",
+		Follow: q.Get("follow") == "1" || q.Get("follow") == "true",
+		Tail:   services.DefaultLogTail,
+	}
+	if !opts.Stdout && !opts.Stderr {
+		return opts, false, errors.New("stdout e stderr desativados: nada para transmitir")
+	}
+	timestamps := q.Get("timestamps") == "1" || q.Get("timestamps") == "true"
+
+	switch tail := q.Get("tail"); tail {
+	case "":
+	case "all":
+		opts.Tail = -1
+	default:
+		n, err := strconv.Atoi(tail)
+		if err != nil || n < 0 {
+			return opts, false, errors.New("tail inválido: use um número ou all")
+		}
+		if n > services.MaxLogTail {
+			n = services.MaxLogTail
+		}
+		opts.Tail = n
+	}
+
+	if since := q.Get("since"); since != "" {
+		t, err := parseLogSince(since)
+		if err != nil {
+			return opts, false, err
+		}
+		opts.Since = t
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("lastEventId")
+	}
+	if lastID != "" {
+		t, err := time.Parse(time.RFC3339Nano, lastID)
+		if err != nil {
+			return opts, false, errors.New("Last-Event-ID inválido")
+		}
+		opts.After = t
+		opts.Tail = -1 // retomada: tudo depois do último evento recebido
+	}
+	return opts, timestamps, nil
+}
+
+// 🕒 since aceita RFC3339, segundos Unix ou duração relativa (ex.: 10m, 2h)
+func parseLogSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, errors.New("since inválido: use RFC3339, segundos Unix ou duração (ex.: 10m)")
+}
+
+// 📡 Abre a resposta como text/event-stream (false = streaming indisponível, erro já respondido)
+func startSSE(w http.ResponseWriter) (*sseWriter, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming não suportado", http.StatusInternalServerError)
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // proxies não devem segurar o stream
+	w.WriteHeader(http.StatusOK)
+
+	sse := &sseWriter{w: w, flusher: flusher, rc: http.NewResponseController(w)}
+	return sse, sse.write(fmt.Sprintf("retry: %d\n\n", sseRetryMillis)) == nil
+}
+
+// 💓 Comentário periódico: mantém proxies abertos e detecta cliente que sumiu
+func (s *sseWriter) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(sseHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.write(": ping\n\n") != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// 🚿 Escrita serializada de eventos SSE com prazo por escrita
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	rc      *http.ResponseController
+}
+
+func (s *sseWriter) write(chunk string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := fmt.Fprint(s.w, chunk); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *sseWriter) event(name, id string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	chunk := "event: " + name + "\n"
+	if id != "" {
+		chunk += "id: " + id + "\n"
+	}
+	return s.write(chunk + "data: " + string(payload) + "\n\n")
+}
+
+func (s *sseWriter) line(line services.LogLine, timestamps bool) error {
+	data := map[string]string{"line": line.Text}
+	id := ""
+	if !line.Time.IsZero() {
+		id = line.Time.UTC().Format(time.RFC3339Nano)
+		if timestamps {
+			data["time"] = id
+		}
+	}
+	return s.event(line.Stream, id, data)
+}
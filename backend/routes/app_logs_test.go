@@ -0,0 +1,105 @@
+//backend/routes/app_logs_test.go
+
+package routes
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"virtuscloud/backend/services"
+)
+
+func TestParseLogStreamQueryDefaults(t *testing.T) {
+	opts, timestamps, err := parseLogStreamQuery(httptest.NewRequest("GET", "/logs", nil))
+	if err != nil {
+		t.Fatalf("parseLogStreamQuery: %v", err)
+	}
+	if !opts.Stdout || !opts.Stderr || opts.Follow || timestamps {
+		t.Fatalf("padrões inesperados: %+v timestamps=%v", opts, timestamps)
+	}
+	if opts.Tail != services.DefaultLogTail || !opts.Since.IsZero() || !opts.After.IsZero() {
+		t.Fatalf("padrões inesperados: %+v", opts)
+	}
+}
+
+func TestParseLogStreamQuery(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   string
+		tail    int
+		wantErr bool
+	}{
+		{"tail numérico", "tail=20", 20, false},
+		{"tail all", "tail=all", -1, false},
+		{"tail acima do máximo", "tail=" + strconv.Itoa(services.MaxLogTail+1), services.MaxLogTail, false},
+		{"tail negativo", "tail=-5", 0, true},
+		{"tail inválido", "tail=muitas", 0, true},
+		{"since RFC3339", "since=2024-05-01T10:00:00Z", services.DefaultLogTail, false},
+		{"since Unix", "since=1714557600", services.DefaultLogTail, false},
+		{"since relativo", "since=10m", services.DefaultLogTail, false},
+		{"since inválido", "since=ontem", 0, true},
+		{"sem stdout nem stderr", "stdout=0&stderr=false", 0, true},
+		{"só stderr", "stdout=0&follow=1", services.DefaultLogTail, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, _, err := parseLogStreamQuery(httptest.NewRequest("GET", "/logs?"+tc.query, nil))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("esperado erro para %q, veio %+v", tc.query, opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLogStreamQuery(%q): %v", tc.query, err)
+			}
+			if opts.Tail != tc.tail {
+				t.Fatalf("tail = %d, esperado %d", opts.Tail, tc.tail)
+			}
+		})
+	}
+}
+
+func TestParseLogStreamQuerySince(t *testing.T) {
+	opts, _, _ := parseLogStreamQuery(httptest.NewRequest("GET", "/logs?since=1714557600", nil))
+	if !opts.Since.Equal(time.Unix(1714557600, 0)) {
+		t.Fatalf("since Unix = %v", opts.Since)
+	}
+	opts, _, _ = parseLogStreamQuery(httptest.NewRequest("GET", "/logs?since=1h", nil))
+	if d := time.Since(opts.Since); d < time.Hour || d > time.Hour+time.Minute {
+		t.Fatalf("since relativo = %v atrás, esperado 1h", d)
+	}
+}
+
+func TestParseLogStreamQueryLastEventID(t *testing.T) {
+	last := "2024-05-01T10:20:30.123456789Z"
+	want, _ := time.Parse(time.RFC3339Nano, last)
+
+	// Reconexão do EventSource: o cabeçalho manda e a retomada ignora o tail pedido
+	r := httptest.NewRequest("GET", "/logs?tail=10&lastEventId=2000-01-01T00:00:00Z", nil)
+	r.Header.Set("Last-Event-ID", last)
+	opts, _, err := parseLogStreamQuery(r)
+	if err != nil {
+		t.Fatalf("parseLogStreamQuery: %v", err)
+	}
+	if !opts.After.Equal(want) || opts.Tail != -1 {
+		t.Fatalf("retomada pelo cabeçalho: after=%v tail=%d", opts.After, opts.Tail)
+	}
+
+	// Sem cabeçalho (clientes que não conseguem defini-lo) vale o parâmetro da query
+	opts, _, err = parseLogStreamQuery(httptest.NewRequest("GET", "/logs?lastEventId="+last, nil))
+	if err != nil {
+		t.Fatalf("parseLogStreamQuery: %v", err)
+	}
+	if !opts.After.Equal(want) || opts.Tail != -1 {
+		t.Fatalf("retomada pela query: after=%v tail=%d", opts.After, opts.Tail)
+	}
+
+	r = httptest.NewRequest("GET", "/logs", nil)
+	r.Header.Set("Last-Event-ID", "42")
+	if _, _, err := parseLogStreamQuery(r); err == nil {
+		t.Fatalf("Last-Event-ID inválido deveria falhar")
+	}
+}
@@ -9,12 +9,14 @@ import (
 	"net/http"
 	"strings"
 
+	"virtuscloud/backend/audit"
 	"virtuscloud/backend/limits"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/services"
 	"virtuscloud/backend/store"
 	"virtuscloud/backend/utils"
+	"virtuscloud/backend/volumes"
 )
 
 // 🚀 Inicia uma aplicação
@@ -97,7 +99,7 @@ func RebuildAppHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("📡 RebuildAppHandler foi chamado com id =", rawID)
 
 	cleanID := services.CleanAppID(rawID)
-	app := store.AppStore[cleanID]
+	app, _ := store.AppStore.Get(cleanID)
 	if app == nil || app.Username != username {
 		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
 		return
@@ -181,16 +183,18 @@ func UpdateAppNameHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "JSON inválido", http.StatusBadRequest)
 		return
 	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "name", payload.NewName)
 
-	app := store.AppStore[payload.ID]
-	if app == nil {
+	_, err := store.AppStore.Update(payload.ID, func(app *models.App) {
+		app.Name = payload.NewName
+		app.Logs = append(app.Logs, "Nome da aplicação alterado para "+payload.NewName)
+	})
+	if err != nil {
 		http.Error(w, "Aplicação não encontrada", http.StatusNotFound)
 		return
 	}
 
-	app.Name = payload.NewName
-	app.Logs = append(app.Logs, "Nome da aplicação alterado para "+payload.NewName)
-
 	utils.WriteJSON(w, map[string]string{"message": "Nome atualizado com sucesso!"})
 }
 
@@ -222,7 +226,7 @@ func AppMetricsHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r)
 	var metrics []map[string]interface{}
 
-	for _, app := range store.AppStore {
+	for _, app := range store.AppStore.List() {
 		if app.Username != username {
 			continue
 		}
@@ -254,14 +258,21 @@ func AppOverviewHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	uptime := utils.CalculateUptime(app.StartTime)
+	volumeUsed, volumeQuotaGB := volumes.AppUsage(app.ID)
 	overview := map[string]interface{}{
-		"id":       app.ID,
-		"name":     app.ContainerName,
-		"uptime":   uptime,
-		"ramUsage": fmt.Sprintf("%.2f MB", app.RAMUsage),
-		"status":   app.Status,
-		"logs":     app.Logs,
-		"alert":    app.Alert,
+		"id":            app.ID,
+		"name":          app.ContainerName,
+		"uptime":        uptime,
+		"ramUsage":      fmt.Sprintf("%.2f MB", app.RAMUsage),
+		"volumeUsage":   volumes.FormatBytes(volumeUsed),
+		"volumeQuotaGB": volumeQuotaGB,
+		"status":        app.Status,
+		"state":         app.State(),
+		"logs":          app.Logs,
+		"alert":         app.Alert,
+		"healthCheck":   app.HealthCheck,
+		"health":        app.Health,
+		"crashLoop":     app.CrashLoop,
 	}
 
 	utils.WriteJSON(w, overview)
@@ -277,7 +288,7 @@ func AppHistoryHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r)
 	appID := r.URL.Query().Get("id")
 
-	app := store.AppStore[appID]
+	app, _ := store.AppStore.Get(appID)
 	if app == nil || app.Username != username {
 		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusNotFound)
 		return
@@ -321,7 +332,7 @@ func ClassifyAppUsageHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r)
 	appID := r.URL.Query().Get("id")
 
-	app := store.AppStore[appID]
+	app, _ := store.AppStore.Get(appID)
 	if app == nil || app.Username != username {
 		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusNotFound)
 		return
@@ -348,7 +359,7 @@ func ClassifyAppUsageHandler(w http.ResponseWriter, r *http.Request) {
 func UserStatusHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r) // ✅ substituído
 
-	user := store.UserStore[username] // ✅ substituído
+	user, _ := store.UserStore.Get(username) // ✅ substituído
 	if user == nil {
 		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
 		return
@@ -365,7 +376,7 @@ func UserStatusHandler(w http.ResponseWriter, r *http.Request) {
 // GET /api/user/status
 func GetUserStatusHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r)
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
 		return
@@ -376,18 +387,27 @@ func GetUserStatusHandler(w http.ResponseWriter, r *http.Request) {
 	// ✅ Usa username e plano corretamente
 	appCount := limits.CountUserContainers(user.Username)
 	//appCount := limits.CountUserApps(user.Username, string(user.Plan))
-	ramUsed := limits.SumUserRAM(user.Username)
+	ramUsed := limits.SumUserRAM(user.Username) // só exibição: uso atual dos containers
 
-	canDeploy := float32(plan.MemoryMB)-ramUsed >= 256 && appCount < plan.MaxProjects
+	// 📒 Reserva no ledger do plano (é o que conta para admissão)
+	ramAllocated, ramFree := 0, 0
+	if ledger, err := limits.UserLedger(user.Username); err == nil {
+		ramAllocated, ramFree = ledger.AllocatedRAMMB, ledger.FreeRAMMB()
+	}
+
+	// 🚦 Mesma regra do deploy
+	canDeploy := limits.CheckDeployAdmission(user.Username) == nil
 
 	utils.WriteJSON(w, map[string]interface{}{
-		"username":    user.Username,
-		"plan":        plan.Name,
-		"canDeploy":   canDeploy,
-		"ramUsedMB":   ramUsed,
-		"memoryMB":    plan.MemoryMB,
-		"maxProjects": plan.MaxProjects,
-		"currentApps": appCount,
+		"username":       user.Username,
+		"plan":           plan.Name,
+		"canDeploy":      canDeploy,
+		"ramUsedMB":      ramUsed,
+		"ramAllocatedMB": ramAllocated,
+		"ramFreeMB":      ramFree,
+		"memoryMB":       plan.MemoryMB,
+		"maxProjects":    plan.MaxProjects,
+		"currentApps":    appCount,
 	})
 }
 
@@ -400,7 +420,7 @@ func GetAppInfoHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id := r.URL.Query().Get("id")
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
 		return
@@ -414,12 +434,14 @@ func ListAppsByStatusHandler(w http.ResponseWriter, r *http.Request) {
 	username, _ := middleware.GetUserFromContext(r)
 
 	// 🧠 Apps registrados
-	var active, stopped, backups []*models.App
-	for _, app := range store.AppStore {
+	var active, stopped, crashing, backups []*models.App
+	for _, app := range store.AppStore.List() {
 		if app.Username != username {
 			continue
 		}
-		switch app.Status {
+		switch app.State() {
+		case models.StatusCrashLoop:
+			crashing = append(crashing, app)
 		case models.StatusRunning:
 			active = append(active, app)
 		case models.StatusStopped:
@@ -435,7 +457,7 @@ func ListAppsByStatusHandler(w http.ResponseWriter, r *http.Request) {
 		if app.Username != username {
 			continue
 		}
-		if _, exists := store.AppStore[app.ID]; exists {
+		if _, exists := store.AppStore.Get(app.ID); exists {
 			continue // já listado
 		}
 		switch app.Status {
@@ -449,9 +471,10 @@ func ListAppsByStatusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	utils.WriteJSON(w, map[string]interface{}{
-		"active":  active,
-		"stopped": stopped,
-		"backups": backups,
+		"active":    active,
+		"stopped":   stopped,
+		"crashloop": crashing,
+		"backups":   backups,
 	})
 }
 
@@ -535,7 +558,7 @@ func ListAppsByStatusHandler(w http.ResponseWriter, r *http.Request) {
 //	name := r.URL.Query().Get("name")
 //
 //	var apps []*models.App
-//	for _, app := range store.AppStore {
+//	for _, app := range store.AppStore.List() {
 //		if app.Username != username {
 //			continue
 //		}
@@ -731,7 +754,7 @@ func ListAppsByStatusHandler(w http.ResponseWriter, r *http.Request) {
 //	username, _ := middleware.GetUserFromContext(r)
 //
 //	var active, stopped, backups []*models.App
-//	for _, app := range store.AppStore {
+//	for _, app := range store.AppStore.List() {
 //		if app.Username != username {
 //			continue
 //		}
@@ -1033,7 +1056,7 @@ func ListAppsByStatusHandler(w http.ResponseWriter, r *http.Request) {
 //	name := r.URL.Query().Get("name")
 //
 //	var apps []*models.App
-//	for _, app := range store.AppStore {
+//	for _, app := range store.AppStore.List() {
 //		if app.UserID != userID {
 //			continue
 //		}
@@ -1056,7 +1079,7 @@ func ListAppsByStatusHandler(w http.ResponseWriter, r *http.Request) {
 //	userID := utils.GetBearerToken(r)
 //	var metrics []map[string]interface{}
 //
-//	for _, app := range store.AppStore {
+//	for _, app := range store.AppStore.List() {
 //		if app.UserID != userID {
 //			continue
 //		}
@@ -0,0 +1,102 @@
+//backend/routes/audit.go
+
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/utils"
+)
+
+// 🔎 Monta o filtro a partir da query: action, target, outcome, since, until (RFC3339) e limit
+func auditFilterFromQuery(r *http.Request) (audit.Filter, error) {
+	q := r.URL.Query()
+	f := audit.Filter{
+		Action:  q.Get("action"),
+		Target:  q.Get("target"),
+		Outcome: audit.Outcome(q.Get("outcome")),
+	}
+
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, fmt.Errorf("parâmetro 'since' inválido (use RFC3339)")
+		}
+		f.Since = t
+	}
+	if v := q.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, fmt.Errorf("parâmetro 'until' inválido (use RFC3339)")
+		}
+		f.Until = t
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("parâmetro 'limit' inválido")
+		}
+		f.Limit = n
+	}
+	return f, nil
+}
+
+// 🛡️ Ações do próprio usuário autenticado
+func UserAuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	if username == "" {
+		http.Error(w, "usuário não identificado", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := auditFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Actor = username // 🔒 nunca expõe ações de terceiros
+
+	events, err := audit.Query(filter)
+	if err != nil {
+		http.Error(w, "erro ao consultar auditoria: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{"events": events})
+}
+
+// 🛡️ Log de auditoria completo, com filtro opcional por ator (admin only)
+func AdminAuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !middleware.HasMinimumAccess(r, "admin") {
+		http.Error(w, "acesso não autorizado", http.StatusForbidden)
+		return
+	}
+
+	filter, err := auditFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Actor = r.URL.Query().Get("actor")
+
+	events, err := audit.Query(filter)
+	if err != nil {
+		http.Error(w, "erro ao consultar auditoria: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{"events": events})
+}
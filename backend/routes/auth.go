@@ -7,9 +7,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
+	"virtuscloud/backend/audit"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/services"
@@ -29,17 +29,21 @@ type VerifyRequest struct {
 func VerifyCodeHandler(w http.ResponseWriter, r *http.Request) {
 	var req VerifyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" || req.Code == "" {
+		audit.Fail(r, "dados inválidos")
 		utils.WriteJSON(w, map[string]string{
 			"error": "Dados inválidos. Verifique e tente novamente.",
 		})
 		return
 	}
+	audit.SetActor(r, req.Email)
+	audit.SetTarget(r, req.Email)
 
 	log.Printf("🔍 Verificando código: Email=%s, Code=%s, Username=%s", req.Email, req.Code, req.Username)
 
 	// ✅ Verifica se o código é válido
 	if !services.IsValidToken(req.Email, req.Code) {
 		log.Printf("❌ Código inválido ou expirado para %s", req.Email)
+		audit.Fail(r, "código inválido ou expirado")
 		utils.WriteJSON(w, map[string]string{
 			"error": "Código inválido ou expirado",
 		})
@@ -53,6 +57,7 @@ func VerifyCodeHandler(w http.ResponseWriter, r *http.Request) {
 		// 🔐 Login: busca usuário pelo e-mail
 		user = services.FindUserByEmail(req.Email)
 		if user == nil {
+			audit.Fail(r, "usuário não encontrado")
 			utils.WriteJSON(w, map[string]string{
 				"error": "Usuário não encontrado",
 			})
@@ -63,12 +68,14 @@ func VerifyCodeHandler(w http.ResponseWriter, r *http.Request) {
 		// 🆕 Cadastro: autentica com username
 		user, err = services.AuthenticateUserWithToken(req.Email, req.Code, req.Username)
 		if err != nil {
+			audit.Fail(r, err.Error())
 			utils.WriteJSON(w, map[string]string{
 				"error": err.Error(),
 			})
 			return
 		}
 	}
+	audit.SetActor(r, user.Username)
 
 	// 📩 Envia e-mail de confirmação de login com localização dinâmica
 	go func() {
@@ -88,6 +95,7 @@ func VerifyCodeHandler(w http.ResponseWriter, r *http.Request) {
 	//token, err := utils.GenerateJWT(user.Username, user.Role, user.Email, string(user.Plan))
 	//token, err := utils.GenerateJWT(user.Username, user.Role, string(user.Plan))
 	if err != nil {
+		audit.Fail(r, "erro ao gerar token de acesso")
 		utils.WriteJSON(w, map[string]string{
 			"error": "Erro ao gerar token de acesso.",
 		})
@@ -114,36 +122,22 @@ func VerifyCodeHandler(w http.ResponseWriter, r *http.Request) {
 	//	Token:    token,
 	//}
 
-	sessions := map[string]models.SessionData{}
-	file, err := os.Open("./database/sessions.json")
-	if err == nil {
-		_ = json.NewDecoder(file).Decode(&sessions)
-		file.Close()
-	}
-
-	// ✅ Salva apenas a sessão atual por username
-	sessions[user.Username] = session
-	// 🧹 Limpa todas as sessões anteriores
-	//sessions = map[string]models.SessionData{}
-
-	// ✅ Salva apenas a sessão atual por token
-	//sessions[token] = session
+	err = models.UpdateSessions(func(sessions map[string]models.SessionData) {
+		// ✅ Salva apenas a sessão atual por username
+		sessions[user.Username] = session
+		// 🧹 Limpa todas as sessões anteriores
+		//sessions = map[string]models.SessionData{}
 
-	file, err = os.Create("./database/sessions.json")
+		// ✅ Salva apenas a sessão atual por token
+		//sessions[token] = session
+	})
 	if err != nil {
+		audit.Fail(r, "erro ao salvar sessão")
 		utils.WriteJSON(w, map[string]string{
 			"error": "Erro ao salvar sessão.",
 		})
 		return
 	}
-	defer file.Close()
-
-	if err := json.NewEncoder(file).Encode(sessions); err != nil {
-		utils.WriteJSON(w, map[string]string{
-			"error": "Erro ao escrever sessão.",
-		})
-		return
-	}
 
 	// 🍪 Define cookie de autenticação
 	http.SetCookie(w, &http.Cookie{
@@ -215,22 +209,7 @@ func VerifyTokenHandler(w http.ResponseWriter, r *http.Request) {
 	role, _ := claims["role"].(string)
 
 	// 🔄 Carrega sessões
-	sessionFile, err := os.Open("./database/sessions.json")
-	if err != nil {
-		utils.WriteJSON(w, map[string]string{
-			"error": "Erro ao abrir sessões",
-		})
-		return
-	}
-	defer sessionFile.Close()
-
-	sessions := map[string]models.SessionData{}
-	if err := json.NewDecoder(sessionFile).Decode(&sessions); err != nil {
-		utils.WriteJSON(w, map[string]string{
-			"error": "Sessões inválidas",
-		})
-		return
-	}
+	sessions := models.LoadAllSessions()
 
 	session, ok := sessions[username]
 	if !ok || session.Role != role || session.Token != tokenStr {
@@ -241,24 +220,7 @@ func VerifyTokenHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 🧠 Carrega plano atualizado do usuário
-	userFile, err := os.Open("./database/users.json")
-	if err != nil {
-		utils.WriteJSON(w, map[string]string{
-			"error": "Erro ao abrir usuários",
-		})
-		return
-	}
-	defer userFile.Close()
-
-	users := map[string]models.User{}
-	if err := json.NewDecoder(userFile).Decode(&users); err != nil {
-		utils.WriteJSON(w, map[string]string{
-			"error": "Usuários inválidos",
-		})
-		return
-	}
-
-	user, ok := users[username]
+	user, ok := store.UserStore.Get(username)
 	if !ok {
 		utils.WriteJSON(w, map[string]string{
 			"error": "Usuário não encontrado",
@@ -269,14 +231,14 @@ func VerifyTokenHandler(w http.ResponseWriter, r *http.Request) {
 	// 🔁 Sincroniza plano da sessão com plano do usuário
 	if session.Plan != string(user.Plan) {
 		session.Plan = string(user.Plan)
-		sessions[username] = session
 
 		// 💾 Salva sessões atualizadas
-		sessionFileWrite, err := os.Create("./database/sessions.json")
-		if err == nil {
-			json.NewEncoder(sessionFileWrite).Encode(sessions)
-			sessionFileWrite.Close()
-		}
+		_ = models.UpdateSessions(func(sessions map[string]models.SessionData) {
+			if current, ok := sessions[username]; ok {
+				current.Plan = session.Plan
+				sessions[username] = current
+			}
+		})
 	}
 
 	// ✅ Retorna dados da sessão válida
@@ -310,26 +272,27 @@ func PingSessionHandler(w http.ResponseWriter, r *http.Request) {
 
 	username, _ := claims["username"].(string)
 
-	// 🔄 Carrega sessões
-	sessionFile, err := os.Open("./database/sessions.json")
-	if err != nil {
-		utils.WriteJSON(w, map[string]string{
-			"error": "Erro ao abrir sessões",
-		})
-		return
-	}
-	defer sessionFile.Close()
+	// 🔄 Carrega sessões e atualiza lastSeen de forma atômica
+	matched := false
+	err = models.UpdateSessions(func(sessions map[string]models.SessionData) {
+		session, ok := sessions[username]
+		if !ok || session.Token != tokenStr {
+			return
+		}
+		matched = true
 
-	sessions := map[string]models.SessionData{}
-	if err := json.NewDecoder(sessionFile).Decode(&sessions); err != nil {
+		// 🕒 Atualiza lastSeen
+		session.LastSeen = time.Now().Format(time.RFC3339)
+		sessions[username] = session
+	})
+	if err != nil {
 		utils.WriteJSON(w, map[string]string{
-			"error": "Sessões inválidas",
+			"error": "Erro ao salvar sessões",
 		})
 		return
 	}
 
-	session, ok := sessions[username]
-	if !ok || session.Token != tokenStr {
+	if !matched {
 		models.DeleteSessionByEmail(claims["email"].(string))
 		utils.WriteJSON(w, map[string]string{
 			"error": "Sessão não corresponde ao token",
@@ -337,17 +300,6 @@ func PingSessionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 🕒 Atualiza lastSeen
-	session.LastSeen = time.Now().Format(time.RFC3339)
-	sessions[username] = session
-
-	// 💾 Salva sessões atualizadas
-	sessionFileWrite, err := os.Create("./database/sessions.json")
-	if err == nil {
-		json.NewEncoder(sessionFileWrite).Encode(sessions)
-		sessionFileWrite.Close()
-	}
-
 	utils.WriteJSON(w, map[string]string{
 		"message": "Sessão validada e atualizada",
 	})
@@ -356,64 +308,30 @@ func PingSessionHandler(w http.ResponseWriter, r *http.Request) {
 // 🔄 Sincroniza plano da sessão com users.json e move apps para o novo diretório se necessário
 func syncSessionsWithUsers() {
 	// 🧾 Carrega usuários
-	userFile, err := os.Open("./database/users.json")
-	if err != nil {
-		fmt.Println("Erro ao abrir users.json:", err)
-		return
-	}
-	defer userFile.Close()
-
-	users := map[string]models.User{}
-	if err := json.NewDecoder(userFile).Decode(&users); err != nil {
-		fmt.Println("Erro ao decodificar users.json:", err)
-		return
-	}
-
-	// 🧾 Carrega sessões
-	sessionFile, err := os.Open("./database/sessions.json")
-	if err != nil {
-		fmt.Println("Erro ao abrir sessions.json:", err)
-		return
-	}
-	defer sessionFile.Close()
-
-	sessions := map[string]models.SessionData{}
-	if err := json.NewDecoder(sessionFile).Decode(&sessions); err != nil {
-		fmt.Println("Erro ao decodificar sessions.json:", err)
-		return
-	}
+	users := store.UserStore.Snapshot()
 
 	// 🔄 Atualiza plano e migra apps
-	updated := false
-	for username, session := range sessions {
-		user, ok := users[username]
-		if ok && session.Plan != string(user.Plan) {
-			fmt.Printf("Atualizando plano de %s: %s → %s\n", username, session.Plan, user.Plan)
-
-			// 📦 Move apps para o novo plano
-			err := services.MigrateAllUserAppsToNewPlan(username, user.Plan)
-			if err != nil {
-				fmt.Printf("Erro ao migrar apps de %s: %v\n", username, err)
+	err := models.UpdateSessions(func(sessions map[string]models.SessionData) {
+		for username, session := range sessions {
+			user, ok := users[username]
+			if ok && session.Plan != string(user.Plan) {
+				fmt.Printf("Atualizando plano de %s: %s → %s\n", username, session.Plan, user.Plan)
+
+				// 📦 Move apps para o novo plano
+				err := services.MigrateAllUserAppsToNewPlan(username, user.Plan)
+				if err != nil {
+					fmt.Printf("Erro ao migrar apps de %s: %v\n", username, err)
+				}
+
+				session.Plan = string(user.Plan)
+				sessions[username] = session
 			}
-
-			session.Plan = string(user.Plan)
-			sessions[username] = session
-			updated = true
 		}
-	}
+	})
 
 	// 💾 Salva sessões atualizadas
-	if updated {
-		sessionFileWrite, err := os.Create("./database/sessions.json")
-		if err != nil {
-			fmt.Println("Erro ao salvar sessions.json:", err)
-			return
-		}
-		defer sessionFileWrite.Close()
-
-		if err := json.NewEncoder(sessionFileWrite).Encode(sessions); err != nil {
-			fmt.Println("Erro ao escrever sessions.json:", err)
-		}
+	if err != nil {
+		fmt.Println("Erro ao salvar sessions.json:", err)
 	}
 }
 
@@ -441,10 +359,16 @@ func LogoutHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	email, _ := claims["email"].(string)
+	if username, _ := claims["username"].(string); username != "" {
+		audit.SetActor(r, username)
+	} else {
+		audit.SetActor(r, email)
+	}
 
 	// 🔓 Remove sessão do arquivo
 	if err := models.DeleteSessionByEmail(email); err != nil {
 		log.Printf("❌ Erro ao remover sessão: %v", err)
+		audit.Fail(r, "erro ao remover sessão")
 		utils.WriteJSON(w, map[string]string{
 			"message": "Erro ao remover sessão",
 		})
@@ -5,18 +5,16 @@ package routes
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
-	"os/exec"
 	"regexp"
-	"strings"
 	"time"
 
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
 	"virtuscloud/backend/middleware"
-	"virtuscloud/backend/models"
 	"virtuscloud/backend/services"
-	"virtuscloud/backend/store"
 	"virtuscloud/backend/utils"
 )
 
@@ -47,9 +45,8 @@ func isValidContainerName(name string) bool {
 
 // 🔍 Verifica se Docker está disponível
 func isDockerAvailable(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "docker", "version")
-	if out, err := cmd.CombinedOutput(); err != nil {
-		log.Println("Docker indisponível:", err, string(out))
+	if err := engine.Default().Ping(ctx); err != nil {
+		log.Println("Docker indisponível:", err)
 		return err
 	}
 	return nil
@@ -68,6 +65,8 @@ func CreateContainerHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "JSON inválido", http.StatusBadRequest)
 		return
 	}
+	audit.SetTarget(r, req.Name)
+	audit.SetDetail(r, "image", req.Image)
 
 	if !isValidContainerName(req.Name) || req.Image == "" {
 		http.Error(w, "Campos inválidos", http.StatusBadRequest)
@@ -92,36 +91,51 @@ func CreateContainerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 🔍 Recupera plano do usuário para aplicar limite de memória
-	user := store.UserStore[req.Username]
-	if user == nil {
+	// 🔍 Recupera a fatia alocada à aplicação para aplicar limite de memória e CPU
+	resources, err := limits.AppContainerResources(req.Username, req.Name)
+	if err != nil {
 		http.Error(w, "Usuário não encontrado", http.StatusUnauthorized)
 		return
 	}
-	plan := models.Plans[user.Plan]
 
-	log.Printf("Criando container: %s com imagem: %s | Limite de memória: %dMB", req.Name, req.Image, plan.MemoryMB)
+	network, err := services.EnsureUserNetwork(req.Username)
+	if err != nil {
+		http.Error(w, "Erro ao preparar rede do usuário: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Criando container: %s com imagem: %s | Limite de memória: %dMB | CPU: %.2f vCPU", req.Name, req.Image, resources.MemoryMB, resources.CPUs)
 
 	// 🐳 Criação do container com múltiplos labels e limite de memória
-	cmd := exec.CommandContext(ctx, "docker", "run",
-		"-d",
-		"--name", req.Name,
-		"--label", "username="+req.Username,
-		"--label", "user="+req.Username,
-		"--label", "name="+req.Name,
-		"--memory", fmt.Sprintf("%dM", plan.MemoryMB),
-		"--memory-swap", fmt.Sprintf("%dM", plan.MemoryMB),
-		req.Image,
-	)
-
-	out, err := cmd.CombinedOutput()
+	spec := engine.Spec{
+		Name:  req.Name,
+		Image: req.Image,
+		Labels: map[string]string{
+			"username": req.Username,
+			"user":     req.Username,
+			"name":     req.Name,
+		},
+		Resources:   resources,
+		NetworkMode: network,
+	}
+	if app := services.GetAppByContainerName(req.Name); app != nil {
+		if err := services.ApplyAppSpec(&spec, app.ID); err != nil {
+			log.Println("Erro ao preparar variáveis e volumes:", err)
+			http.Error(w, "Erro ao preparar variáveis e volumes da aplicação", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	containerID, err := engine.Default().Create(ctx, spec)
+	if err == nil {
+		err = engine.Default().Start(ctx, req.Name)
+	}
 	if err != nil {
-		log.Println("Erro ao criar aplicação:", err, string(out))
-		http.Error(w, "Erro ao criar aplicação: "+string(out), http.StatusInternalServerError)
+		log.Println("Erro ao criar aplicação:", err)
+		http.Error(w, "Erro ao criar aplicação: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	containerID := strings.TrimSpace(string(out))
 	log.Println("Aplicação criada com sucesso. ID:", containerID)
 
 	utils.WriteJSON(w, map[string]string{
@@ -419,23 +433,22 @@ func ListContainersHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "docker", "ps", "--format", "{{.ID}}:{{.Names}}")
-	out, err := cmd.CombinedOutput()
+	list, err := engine.Default().List(ctx, engine.ListOptions{})
 	if err != nil {
-		http.Error(w, "Erro ao listar aplicações: "+string(out), http.StatusInternalServerError)
+		http.Error(w, "Erro ao listar aplicações: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 	containers := []ContainerInfo{}
-	for _, line := range lines {
-		parts := strings.Split(line, ":")
-		if len(parts) == 2 {
-			containers = append(containers, ContainerInfo{
-				ID:   parts[0],
-				Name: parts[1],
-			})
+	for _, c := range list {
+		id := c.ID
+		if len(id) > 12 {
+			id = id[:12] // 🔎 ID curto, como no `docker ps`
 		}
+		containers = append(containers, ContainerInfo{
+			ID:   id,
+			Name: c.Name,
+		})
 	}
 
 	utils.WriteJSON(w, map[string]interface{}{
@@ -455,6 +468,7 @@ func DeleteContainerHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "JSON inválido", http.StatusBadRequest)
 		return
 	}
+	audit.SetTarget(r, req.Name)
 
 	if !isValidContainerName(req.Name) {
 		http.Error(w, "Nome inválido", http.StatusBadRequest)
@@ -464,16 +478,14 @@ func DeleteContainerHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "docker", "rm", "-f", req.Name)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		http.Error(w, "Erro ao remover aplicação: "+string(out), http.StatusInternalServerError)
+	if err := engine.Default().Remove(ctx, req.Name, true); err != nil {
+		http.Error(w, "Erro ao remover aplicação: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	utils.WriteJSON(w, map[string]string{
 		"message":  "Aplicação removida com sucesso!",
-		"output":   strings.TrimSpace(string(out)),
+		"output":   req.Name, // `docker rm` ecoava o nome removido
 		"deleted":  req.Name,
 		"datetime": time.Now().Format(time.RFC3339),
 	})
@@ -4,47 +4,457 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
-	"os/exec"
+	"strconv"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/databases"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
 )
 
-type CreateDatabaseRequest struct {
-	Type     string `json:"type"`     // "postgres", "mongo", "redis"
-	DBName   string `json:"dbName"`   // nome do banco
-	Username string `json:"username"` // nome do usuário
+// 🗄️ GET /api/databases — bancos do usuário, motores disponíveis e o que o plano permite
+func ListDatabasesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	maxDatabases, maxGB, memoryMB, err := limits.DatabaseQuota(username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	views := []databases.View{}
+	for _, db := range store.DatabaseStore.ListByUser(username) {
+		if db.Username == username {
+			views = append(views, databases.ViewOf(db))
+		}
+	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"databases": views,
+		"engines":   databases.Engines(),
+		"plan": map[string]int{
+			"maxDatabases": maxDatabases,
+			"maxSizeGB":    maxGB,
+			"memoryMB":     memoryMB,
+		},
+	})
 }
 
+// ➕ POST /api/databases/create — {name, engine, version?, sizeGB}; responde 202 e o banco sobe em segundo plano
 func CreateDatabaseHandler(w http.ResponseWriter, r *http.Request) {
-	var req CreateDatabaseRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Erro ao decodificar JSON", http.StatusBadRequest)
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
-	fullName := fmt.Sprintf("%s_%s", req.Username, req.DBName)
+	var payload struct {
+		Name    string `json:"name"`
+		Engine  string `json:"engine"`
+		Version string `json:"version"`
+		SizeGB  int    `json:"sizeGB"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetDetail(r, "database", payload.Name)
+	audit.SetDetail(r, "engine", payload.Engine)
 
-	switch req.Type {
-	case "postgres":
-		cmd := exec.Command("createdb", fullName)
-		if err := cmd.Run(); err != nil {
-			http.Error(w, "Erro ao criar banco PostgreSQL", http.StatusInternalServerError)
-			return
-		}
-	case "mongo":
-		cmd := exec.Command("mongo", fullName, "--eval", "db.createCollection('init')")
-		if err := cmd.Run(); err != nil {
-			http.Error(w, "Erro ao criar banco MongoDB", http.StatusInternalServerError)
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.CreateDatabase(username, payload.Name, payload.Engine, payload.Version, payload.SizeGB)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	audit.SetTarget(r, db.ID)
+	utils.WriteJSONStatus(w, http.StatusAccepted, map[string]interface{}{
+		"message":  "Banco registrado: o container está subindo (acompanhe o status em /api/databases)",
+		"database": databases.ViewOf(db),
+	})
+}
+
+// 🔌 GET /api/databases/connection?id= — host, porta, usuário, senha e URL (válidos na rede privada do usuário)
+func DatabaseConnectionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.OwnedDatabase(username, r.URL.Query().Get("id"))
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	conn, err := databases.ConnectionOf(db)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"id":         db.ID,
+		"status":     db.Status,
+		"connection": conn,
+	})
+}
+
+// 📏 POST /api/databases/resize — {id, sizeGB}
+func ResizeDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID     string `json:"id"`
+		SizeGB int    `json:"sizeGB"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+
+	username, _ := middleware.GetUserFromContext(r)
+	if _, err := services.OwnedDatabase(username, payload.ID); err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	db, err := databases.Resize(payload.ID, payload.SizeGB)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"message":  "Tamanho do banco atualizado!",
+		"database": databases.ViewOf(db),
+	})
+}
+
+// ▶️ POST /api/databases/start — {id}
+func StartDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	changeDatabaseState(w, r, services.StartDatabase, http.StatusAccepted, "Banco ligando (acompanhe o status em /api/databases)")
+}
+
+// ⏹️ POST /api/databases/stop — {id}
+func StopDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	changeDatabaseState(w, r, services.StopDatabase, http.StatusOK, "Banco parado!")
+}
+
+// 🗑️ POST /api/databases/delete — {id, confirm}; confirm deve repetir o nome (os dados são apagados)
+func DeleteDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID      string `json:"id"`
+		Confirm string `json:"confirm"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.OwnedDatabase(username, payload.ID)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	audit.SetDetail(r, "database", db.Name)
+	if payload.Confirm != db.Name {
+		utils.WriteJSONStatus(w, http.StatusBadRequest, map[string]string{"error": "Confirme repetindo o nome do banco em 'confirm': os dados serão apagados"})
+		return
+	}
+	unlinked, err := services.DeleteDatabase(username, payload.ID)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	if unlinked == nil {
+		unlinked = []string{}
+	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"message":  "Banco removido com sucesso! As aplicações ligadas perdem as variáveis no próximo start, restart ou rebuild.",
+		"unlinked": unlinked,
+	})
+}
+
+// 🔗 POST /api/databases/link — {id, app, prefix?, restart}; injeta <PREFIXO>_URL, _HOST... no ambiente da aplicação
+func LinkDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID      string `json:"id"`
+		App     string `json:"app"`
+		Prefix  string `json:"prefix"`
+		Restart bool   `json:"restart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "app", payload.App)
+	audit.SetDetail(r, "prefix", payload.Prefix)
+
+	app, ok := ownedVolumeApp(w, r, payload.App)
+	if !ok {
+		return
+	}
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.LinkDatabase(username, payload.ID, app, payload.Prefix)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	writeDatabaseLinkChange(w, r, app, payload.Restart, "Banco ligado à aplicação!", db)
+}
+
+// ✂️ POST /api/databases/unlink — {id, app, restart}
+func UnlinkDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID      string `json:"id"`
+		App     string `json:"app"`
+		Restart bool   `json:"restart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "app", payload.App)
+
+	app, ok := ownedVolumeApp(w, r, payload.App)
+	if !ok {
+		return
+	}
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.UnlinkDatabase(username, payload.ID, app)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	writeDatabaseLinkChange(w, r, app, payload.Restart, "Banco desligado da aplicação!", db)
+}
+
+// 💾 GET /api/databases/backups?id= — backups do banco e a política do plano
+func ListDatabaseBackupsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.OwnedDatabase(username, r.URL.Query().Get("id"))
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	daily, keep, err := databases.BackupPolicy(username)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	backups := db.Backups
+	if backups == nil {
+		backups = []models.DatabaseBackup{}
+	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"id":      db.ID,
+		"backups": backups,
+		"policy": map[string]interface{}{
+			"daily": daily,
+			"keep":  keep,
+		},
+	})
+}
+
+// 💾 POST /api/databases/backups/create — {id}; responde 202 e o dump roda em segundo plano
+func CreateDatabaseBackupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+
+	username, _ := middleware.GetUserFromContext(r)
+	backup, err := services.BackupDatabase(username, payload.ID)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	audit.SetDetail(r, "backup", backup.ID)
+	utils.WriteJSONStatus(w, http.StatusAccepted, map[string]interface{}{
+		"message": "Backup iniciado (acompanhe o status em /api/databases/backups)",
+		"backup":  backup,
+	})
+}
+
+// 📥 GET /api/databases/backups/download?id=&backup= — arquivo do dump (formato nativo do motor)
+func DownloadDatabaseBackupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	backupID := r.URL.Query().Get("backup")
+	audit.SetTarget(r, id)
+	audit.SetDetail(r, "backup", backupID)
+
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.OwnedDatabase(username, id)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	f, backup, err := databases.OpenBackup(db, backupID)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", backup.File))
+	w.Header().Set("Content-Length", strconv.FormatInt(backup.SizeBytes, 10))
+	w.Header().Set("X-Checksum-SHA256", backup.SHA256)
+	if _, err := io.Copy(w, io.LimitReader(f, backup.SizeBytes)); err != nil {
+		// Cabeçalhos já enviados: só resta registrar e interromper a resposta
+		log.Printf("⚠️ Erro ao enviar backup %s: %v", backup.ID, err)
+	}
+}
+
+// ♻️ POST /api/databases/restore — {id, backup, name, sizeGB?}; cria um banco novo com o backup e verifica a restauração
+func RestoreDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID     string `json:"id"`
+		Backup string `json:"backup"`
+		Name   string `json:"name"`
+		SizeGB int    `json:"sizeGB"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "backup", payload.Backup)
+	audit.SetDetail(r, "database", payload.Name)
+
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := services.RestoreDatabase(username, payload.ID, payload.Backup, payload.Name, payload.SizeGB)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	utils.WriteJSONStatus(w, http.StatusAccepted, map[string]interface{}{
+		"message":  "Restauração iniciada: o banco novo fica \"running\" depois que o backup for carregado e verificado",
+		"database": databases.ViewOf(db),
+	})
+}
+
+// 🚦 Liga/para o banco pelo ID do corpo
+func changeDatabaseState(w http.ResponseWriter, r *http.Request, change func(username, id string) (*models.Database, error), status int, message string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+
+	username, _ := middleware.GetUserFromContext(r)
+	db, err := change(username, payload.ID)
+	if err != nil {
+		writeDatabaseError(w, err)
+		return
+	}
+	utils.WriteJSONStatus(w, status, map[string]interface{}{
+		"message":  message,
+		"database": databases.ViewOf(db),
+	})
+}
+
+// 🔁 Recria a aplicação agora (restart=true) ou deixa as variáveis para o próximo start, restart ou rebuild
+func writeDatabaseLinkChange(w http.ResponseWriter, r *http.Request, app *models.App, restart bool, message string, db *models.Database) {
+	if restart {
+		if err := services.ApplyAppSpecChange(app); err != nil {
+			audit.Fail(r, err.Error())
+			utils.WriteJSONStatus(w, http.StatusInternalServerError, map[string]interface{}{
+				"error":    "Ligação salva, mas a aplicação não foi recriada: " + err.Error(),
+				"database": databases.ViewOf(db),
+			})
 			return
 		}
-	case "redis":
-		// Redis não cria bancos nomeados, apenas prefixos
-		fmt.Println("Redis namespace reservado para", fullName)
-	default:
-		http.Error(w, "Tipo de banco inválido", http.StatusBadRequest)
-		return
+	} else {
+		message += " As variáveis valem a partir do próximo start, restart ou rebuild."
 	}
+	utils.WriteJSON(w, map[string]interface{}{
+		"message":  message,
+		"applied":  restart,
+		"database": databases.ViewOf(db),
+	})
+}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("Banco criado com sucesso"))
+// ❗ Responde o erro com o status adequado
+func writeDatabaseError(w http.ResponseWriter, err error) {
+	utils.WriteJSONStatus(w, databaseErrorStatus(err), map[string]string{"error": err.Error()})
+}
+
+// 🚦 Erros de validação/plano viram 4xx; o resto é falha interna
+func databaseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, databases.ErrInvalid), errors.Is(err, services.ErrInvalidEnv):
+		return http.StatusBadRequest
+	case errors.Is(err, databases.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, databases.ErrExists), errors.Is(err, databases.ErrBusy):
+		return http.StatusConflict
+	case errors.Is(err, databases.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
 }
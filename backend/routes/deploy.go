@@ -38,7 +38,7 @@ func DeployHandler(w http.ResponseWriter, r *http.Request) {
 	customID := r.URL.Query().Get("customID")
 
 	username, _ := middleware.GetUserFromContext(r)
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		w.WriteHeader(http.StatusUnauthorized)
 		utils.WriteJSON(w, map[string]interface{}{
@@ -81,9 +81,11 @@ func DeployHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fileSize := header.Size
-	ramTotalMB := models.Plans[user.Plan].MemoryMB
-	ramUsed := limits.SumUserRAM(username)
-	ramAvailable := float32(ramTotalMB) - ramUsed
+	// 📒 Sobra de RAM ainda não reservada para outras aplicações
+	var ramAvailable float32
+	if ledger, err := limits.UserLedger(username); err == nil {
+		ramAvailable = float32(ledger.FreeRAMMB())
+	}
 
 	maxUploadBytes := int64(ramAvailable * 200 * 1024 * 1024)
 	if fileSize > maxUploadBytes {
@@ -210,7 +212,7 @@ func ValidateDeployHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	username, _ := middleware.GetUserFromContext(r)
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		http.Error(w, "usuário não encontrado", http.StatusUnauthorized)
 		return
@@ -0,0 +1,180 @@
+//backend/routes/deployments.go
+
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 🚀 GET /api/app/deployments?id= — deploys da aplicação (container ou ID), do mais recente ao mais antigo
+func ListAppDeploymentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	appID := r.URL.Query().Get("id")
+	if app := services.GetAppByContainerName(appID); app != nil {
+		if app.Username != username {
+			http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+			return
+		}
+		appID = app.ID
+	}
+
+	// Sem container (primeiro deploy falhou ou aplicação removida) vale o ID; só os deploys do próprio usuário
+	deployments := []*models.Deployment{}
+	for _, d := range store.DeploymentStore.ListByApp(appID) {
+		if d.Username == username {
+			deployments = append(deployments, d)
+		}
+	}
+	utils.WriteJSON(w, map[string]interface{}{"id": appID, "deployments": deployments})
+}
+
+// 🔐 Deploy pedido em ?deploy=, se pertencer ao usuário autenticado
+func ownedDeployment(r *http.Request) (*models.Deployment, bool) {
+	username, _ := middleware.GetUserFromContext(r)
+	d, ok := store.DeploymentStore.Get(r.URL.Query().Get("deploy"))
+	if !ok || d.Username != username {
+		return nil, false
+	}
+	return d, true
+}
+
+// 🔎 level (debug|info|warn|error), phase e after (seq)
+func parseDeployLogFilter(r *http.Request) (services.DeployLogFilter, error) {
+	q := r.URL.Query()
+	filter := services.DeployLogFilter{Phase: q.Get("phase")}
+	switch level := models.DeployLevel(q.Get("level")); level {
+	case "":
+	case models.DeployDebug, models.DeployInfo, models.DeployWarn, models.DeployError:
+		filter.MinLevel = level
+	default:
+		return filter, errors.New("level inválido: use debug, info, warn ou error")
+	}
+	if after := q.Get("after"); after != "" {
+		n, err := strconv.Atoi(after)
+		if err != nil || n < 0 {
+			return filter, errors.New("after inválido")
+		}
+		filter.AfterSeq = n
+	}
+	return filter, nil
+}
+
+// 📜 GET /api/app/deployments/logs?deploy=&level=&phase=&after= — log estruturado de um deploy
+func GetDeploymentLogHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	d, ok := ownedDeployment(r)
+	if !ok {
+		http.Error(w, services.ErrDeploymentNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	filter, err := parseDeployLogFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := services.ReadDeployLog(d, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{"deployment": d, "entries": entries})
+}
+
+// 📡 GET /api/app/deployments/stream?deploy=&level=&phase= — log do deploy em Server-Sent Events.
+// Entradas chegam como evento entry (id = seq); ao terminar o deploy, um evento end com o resultado.
+func StreamDeploymentLogHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	d, ok := ownedDeployment(r)
+	if !ok {
+		http.Error(w, services.ErrDeploymentNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	filter, err := parseDeployLogFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	if lastID != "" {
+		n, err := strconv.Atoi(lastID)
+		if err != nil || n < 0 {
+			http.Error(w, "Last-Event-ID inválido", http.StatusBadRequest)
+			return
+		}
+		filter.AfterSeq = n
+	}
+
+	sse, ok := startSSE(w)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	go sse.keepAlive(ctx)
+
+	send := func(e models.DeployLogEntry) error {
+		filter.AfterSeq = e.Seq
+		return sse.event("entry", strconv.Itoa(e.Seq), e)
+	}
+	for {
+		history, live, cancel, err := services.SubscribeDeployLog(d, filter)
+		if err != nil {
+			_ = sse.event("error", "", map[string]string{"error": err.Error()})
+			return
+		}
+		for _, e := range history {
+			if send(e) != nil {
+				cancel()
+				return
+			}
+		}
+		if live == nil {
+			break // deploy já terminou: o histórico é o log completo
+		}
+		for open := true; open; {
+			select {
+			case e, more := <-live:
+				if open = more; more && send(e) != nil {
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				cancel()
+				return
+			}
+		}
+		// Canal fechado: deploy terminou ou este leitor ficou para trás; reassina a partir do último seq
+		cancel()
+	}
+
+	if latest, ok := store.DeploymentStore.Get(d.ID); ok {
+		d = latest
+	}
+	_ = sse.event("end", "", map[string]interface{}{
+		"status":     d.Status,
+		"error":      d.Error,
+		"durationMs": d.DurationMs,
+	})
+}
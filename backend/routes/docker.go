@@ -6,9 +6,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os/exec"
 	"time"
 
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/services"
 	"virtuscloud/backend/store"
@@ -19,7 +20,10 @@ import (
 func waitForDocker(timeout time.Duration, interval time.Duration) bool {
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
-		if err := exec.Command("docker", "info").Run(); err == nil {
+		ctx, cancel := engine.Timeout()
+		err := engine.Default().Ping(ctx)
+		cancel()
+		if err == nil {
 			log.Println("✅ Docker ativo")
 			return true
 		}
@@ -30,6 +34,42 @@ func waitForDocker(timeout time.Duration, interval time.Duration) bool {
 	return false
 }
 
+// 🐳 Cria e inicia o container da aplicação (equivalente ao antigo `docker run -d`)
+func runAppContainer(containerName, imageName, username, appPath, workdir string, command []string) error {
+	resources, err := limits.AppContainerResources(username, containerName)
+	if err != nil {
+		return err
+	}
+	network, err := services.EnsureUserNetwork(username)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	spec := engine.Spec{
+		Name:          containerName,
+		Image:         imageName,
+		Cmd:           command,
+		WorkingDir:    workdir,
+		Labels:        map[string]string{"username": username},
+		Binds:         []string{appPath + ":/app"},
+		Resources:     resources,
+		RestartPolicy: "no", // 🛡️ reinício controlado pelo backend
+		NetworkMode:   network,
+	}
+	if app := services.GetAppByContainerName(containerName); app != nil {
+		if err := services.ApplyAppSpec(&spec, app.ID); err != nil {
+			return err
+		}
+	}
+	if _, err := engine.Default().Create(ctx, spec); err != nil {
+		return err
+	}
+	return engine.Default().Start(ctx, containerName)
+}
+
 // 🐳 Endpoint para criar container Docker local (CLI ou API interna)
 func DockerHandler(w http.ResponseWriter, r *http.Request) {
 	// 🔓 Libera CORS
@@ -40,7 +80,7 @@ func DockerHandler(w http.ResponseWriter, r *http.Request) {
 	useAPI := r.URL.Query().Get("mode") == "api"
 
 	// 🧪 App de teste
-	app := services.AppStore["test-local"]
+	app, _ := store.AppStore.Get("test-local")
 	if app == nil {
 		http.Error(w, "Aplicação 'test-local' não encontrada", http.StatusNotFound)
 		return
@@ -54,7 +94,7 @@ func DockerHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 🔍 Busca usuário na store
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
 		return
@@ -70,11 +110,12 @@ func DockerHandler(w http.ResponseWriter, r *http.Request) {
 
 	// 🔄 Atualiza app
 	app.ContainerName = containerName
-	services.AppStore[app.ID] = app
 	store.SaveApp(app)
 
 	// 🧹 Remove container antigo se existir
-	_ = exec.Command("docker", "rm", "-f", containerName).Run()
+	ctx, cancel := engine.Timeout()
+	_ = engine.Default().Remove(ctx, containerName, true)
+	cancel()
 
 	// 🧠 Comando por runtime
 	command := services.GetRuntimeCommand(app.Runtime, app.Entry)
@@ -100,18 +141,8 @@ func DockerHandler(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 		} else {
-			// 🛠️ CLI local
-			cmd := exec.Command("docker", "run",
-				"-d",
-				"--restart=no", // 🛡️ reinício automático
-				"--name", containerName,
-				"--label", fmt.Sprintf("username=%s", user.Username),
-				"-v", app.Path+":/app",
-				"-w", workdir,
-				imageName,
-			)
-			cmd.Args = append(cmd.Args, command...)
-			err = cmd.Run()
+			// 🛠️ Engine API local
+			err = runAppContainer(containerName, imageName, user.Username, app.Path, workdir, command)
 			if err == nil {
 				result = &services.ContainerResult{
 					ID:        containerName,
@@ -150,17 +181,7 @@ func DockerHandler(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 		} else {
-			cmd := exec.Command("docker", "run",
-				"-d",
-				"--restart=no",
-				"--name", containerName,
-				"--label", fmt.Sprintf("username=%s", user.Username),
-				"-v", app.Path+":/app",
-				"-w", workdir,
-				imageName,
-			)
-			cmd.Args = append(cmd.Args, command...)
-			err = cmd.Run()
+			err = runAppContainer(containerName, imageName, user.Username, app.Path, workdir, command)
 			if err == nil {
 				result = &services.ContainerResult{
 					ID:        containerName,
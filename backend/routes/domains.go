@@ -0,0 +1,171 @@
+//backend/routes/domains.go
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/domains"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 🌍 Domínio com o registro DNS esperado (instrução para o painel)
+type domainView struct {
+	*models.Domain
+	Challenge domains.Challenge `json:"challenge"`
+}
+
+// 🌍 GET /api/domains — domínios personalizados do usuário (?id= filtra uma aplicação)
+func ListDomainsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	appID := r.URL.Query().Get("id")
+	if appID != "" {
+		app := services.GetAppByContainerName(appID)
+		if app == nil || app.Username != username {
+			http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+			return
+		}
+		appID = app.ID
+	}
+
+	views := []domainView{}
+	for _, domain := range store.DomainStore.ListByUser(username) {
+		if appID != "" && domain.AppID != appID {
+			continue
+		}
+		app, _ := store.AppStore.Get(domain.AppID)
+		if app == nil {
+			app = &models.App{ID: domain.AppID, Username: domain.Username}
+		}
+		views = append(views, domainView{Domain: domain, Challenge: domains.ChallengeFor(domain, app)})
+	}
+	utils.WriteJSON(w, map[string]interface{}{"domains": views})
+}
+
+// ➕ POST /api/domains/add — aponta um hostname para a aplicação e devolve o desafio DNS
+func AddDomainHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID       string `json:"id"`
+		Hostname string `json:"hostname"`
+		Method   string `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "hostname", payload.Hostname)
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(payload.ID)
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	domain, challenge, err := domains.Add(username, app, payload.Hostname, strings.ToLower(payload.Method))
+	if err != nil {
+		utils.WriteJSONStatus(w, domainErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+
+	utils.WriteJSONStatus(w, http.StatusCreated, map[string]interface{}{
+		"message":   "Domínio cadastrado! Crie o registro DNS abaixo e solicite a verificação.",
+		"domain":    domain,
+		"challenge": challenge,
+	})
+}
+
+// ✅ POST /api/domains/verify — consulta o DNS agora e ativa a rota se o desafio conferir
+func VerifyDomainHandler(w http.ResponseWriter, r *http.Request) {
+	domain, ok := ownedDomain(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := domains.Verify(r.Context(), domain.Hostname)
+	if err != nil {
+		utils.WriteJSONStatus(w, domainErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	if updated.Status != models.DomainVerified {
+		audit.Fail(r, updated.LastError)
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"verified": updated.Status == models.DomainVerified,
+		"domain":   updated,
+	})
+}
+
+// 🗑️ POST /api/domains/delete — remove o domínio e a rota
+func DeleteDomainHandler(w http.ResponseWriter, r *http.Request) {
+	domain, ok := ownedDomain(w, r)
+	if !ok {
+		return
+	}
+
+	if err := domains.Remove(domain.Hostname); err != nil {
+		utils.WriteJSONStatus(w, domainErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	utils.WriteJSON(w, map[string]string{"message": "Domínio removido com sucesso!"})
+}
+
+// 🔐 Lê {hostname} do corpo e confere se o domínio pertence ao usuário
+func ownedDomain(w http.ResponseWriter, r *http.Request) (*models.Domain, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	var payload struct {
+		Hostname string `json:"hostname"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return nil, false
+	}
+	audit.SetDetail(r, "hostname", payload.Hostname)
+
+	username, _ := middleware.GetUserFromContext(r)
+	domain, ok := store.DomainStore.Get(strings.TrimSuffix(strings.TrimSpace(payload.Hostname), "."))
+	if !ok || domain.Username != username {
+		http.Error(w, "Domínio não encontrado ou não pertence ao usuário", http.StatusForbidden)
+		return nil, false
+	}
+	audit.SetTarget(r, domain.AppID)
+	return domain, true
+}
+
+// 🚦 Status HTTP para os erros do subsistema de domínios
+func domainErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domains.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, domains.ErrHostnameTaken):
+		return http.StatusConflict
+	case errors.Is(err, domains.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,347 @@
+//backend/routes/files.go
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/files"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/utils"
+)
+
+// 📤 Arquivos por upload multipart
+const maxUploadFiles = 100
+
+// 🗂️ GET /api/app/files/roots?id= — raízes editáveis: o código (snapshot) e os volumes
+func ListFileRootsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, ok := ownedVolumeApp(w, r, r.URL.Query().Get("id"))
+	if !ok {
+		return
+	}
+	maxBytes, err := files.MaxFileBytes(app.Username)
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{"id": app.ID, "roots": files.Roots(app), "maxFileBytes": maxBytes})
+}
+
+// 📋 GET /api/app/files?id=&root=&path= — conteúdo do diretório
+func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	_, root, p, ok := openFileRoot(w, r)
+	if !ok {
+		return
+	}
+	entries, err := root.List(p)
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{"root": root.Name(), "path": p, "entries": entries})
+}
+
+// 📖 GET /api/app/files/read?id=&root=&path=&download=1 — conteúdo do arquivo (até o limite do plano)
+func ReadFileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, root, p, ok := openFileRoot(w, r)
+	if !ok {
+		return
+	}
+	maxBytes, err := files.MaxFileBytes(app.Username)
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	rc, entry, err := root.Open(p)
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	defer rc.Close()
+	if entry.Size > maxBytes {
+		utils.WriteJSONStatus(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("%v: %s tem %d bytes (limite %d)", files.ErrTooLarge, p, entry.Size, maxBytes),
+		})
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(p))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(entry.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if entry.ModTime != nil {
+		w.Header().Set("Last-Modified", entry.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if r.URL.Query().Get("download") == "1" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": entry.Name}))
+	}
+	_, _ = io.Copy(w, io.LimitReader(rc, entry.Size))
+}
+
+// ✍️ POST /api/app/files/write?id=&root=&path=&apply= — corpo da requisição vira o conteúdo do arquivo
+func WriteFileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, root, p, apply, maxBytes, ok := openFileMutation(w, r)
+	if !ok {
+		return
+	}
+	if r.ContentLength > maxBytes {
+		utils.WriteJSONStatus(w, http.StatusRequestEntityTooLarge, map[string]string{"error": files.ErrTooLarge.Error()})
+		return
+	}
+	entry, err := root.Write(p, r.Body, maxBytes)
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	audit.SetDetail(r, "bytes", strconv.FormatInt(entry.Size, 10))
+	writeFilesChange(w, r, app, apply, http.StatusOK, "Arquivo salvo com sucesso!", map[string]interface{}{"entry": entry})
+}
+
+// 📤 POST /api/app/files/upload?id=&root=&path=<diretório>&apply= — multipart, um ou mais campos "file"
+func UploadFilesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, root, dir, apply, maxBytes, ok := openFileMutation(w, r)
+	if !ok {
+		return
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Envie os arquivos como multipart/form-data", http.StatusBadRequest)
+		return
+	}
+
+	uploaded := []*files.Entry{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, "Multipart inválido", http.StatusBadRequest)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		if len(uploaded) >= maxUploadFiles {
+			part.Close()
+			utils.WriteJSONStatus(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Máximo de %d arquivos por envio", maxUploadFiles)})
+			return
+		}
+		name, err := files.CleanPath(path.Base(part.FileName()))
+		if err == nil && name == "" {
+			err = fmt.Errorf("%w: nome de arquivo vazio", files.ErrInvalid)
+		}
+		if err != nil {
+			part.Close()
+			utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]interface{}{"error": err.Error(), "uploaded": uploaded})
+			return
+		}
+		entry, err := root.Write(path.Join(dir, name), part, maxBytes)
+		part.Close()
+		if err != nil {
+			utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]interface{}{"error": err.Error(), "uploaded": uploaded})
+			return
+		}
+		uploaded = append(uploaded, entry)
+	}
+	if len(uploaded) == 0 {
+		http.Error(w, "Nenhum arquivo enviado no campo 'file'", http.StatusBadRequest)
+		return
+	}
+	audit.SetDetail(r, "files", strconv.Itoa(len(uploaded)))
+	writeFilesChange(w, r, app, apply, http.StatusCreated, "Arquivos enviados com sucesso!", map[string]interface{}{"entries": uploaded})
+}
+
+// 📁 POST /api/app/files/mkdir?id=&root=&path=
+func MkdirFileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, root, p, apply, _, ok := openFileMutation(w, r)
+	if !ok {
+		return
+	}
+	if err := root.Mkdir(p); err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeFilesChange(w, r, app, apply, http.StatusCreated, "Diretório criado com sucesso!", nil)
+}
+
+// ✏️ POST /api/app/files/rename?id=&root=&path=&to=&apply=
+func RenameFileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, root, p, apply, _, ok := openFileMutation(w, r)
+	if !ok {
+		return
+	}
+	to, err := files.CleanPath(r.URL.Query().Get("to"))
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	audit.SetDetail(r, "to", to)
+	if err := root.Rename(p, to); err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeFilesChange(w, r, app, apply, http.StatusOK, "Arquivo renomeado com sucesso!", nil)
+}
+
+// 🗑️ POST /api/app/files/delete?id=&root=&path=&apply= — diretórios são apagados com todo o conteúdo
+func DeleteFileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, root, p, apply, _, ok := openFileMutation(w, r)
+	if !ok {
+		return
+	}
+	if err := root.Delete(p); err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeFilesChange(w, r, app, apply, http.StatusOK, "Arquivo removido com sucesso!", nil)
+}
+
+// 🔐 Confere a aplicação, abre a raiz e normaliza o caminho (?id=&root=&path=)
+func openFileRoot(w http.ResponseWriter, r *http.Request) (*models.App, files.Root, string, bool) {
+	q := r.URL.Query()
+	app, ok := ownedVolumeApp(w, r, q.Get("id"))
+	if !ok {
+		return nil, nil, "", false
+	}
+	if _, err := files.MaxFileBytes(app.Username); err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return nil, nil, "", false
+	}
+	p, err := files.CleanPath(q.Get("path"))
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return nil, nil, "", false
+	}
+	root, err := files.Open(app, q.Get("root"))
+	if err != nil {
+		utils.WriteJSONStatus(w, filesErrorStatus(err), map[string]string{"error": err.Error()})
+		return nil, nil, "", false
+	}
+	return app, root, p, true
+}
+
+// ✍️ openFileRoot para alterações: registra a auditoria e valida apply (rebuild no código, restart ou rebuild nos volumes)
+func openFileMutation(w http.ResponseWriter, r *http.Request) (*models.App, files.Root, string, string, int64, bool) {
+	q := r.URL.Query()
+	audit.SetTarget(r, q.Get("id"))
+	audit.SetDetail(r, "root", q.Get("root"))
+	audit.SetDetail(r, "path", q.Get("path"))
+
+	app, root, p, ok := openFileRoot(w, r)
+	if !ok {
+		return nil, nil, "", "", 0, false
+	}
+	apply := q.Get("apply")
+	switch {
+	case apply == "" || apply == "rebuild":
+	case apply == "restart" && root.Name() != files.RootSource:
+	case apply == "restart":
+		utils.WriteJSONStatus(w, http.StatusBadRequest, map[string]string{"error": "Alterações no código só valem após rebuild: use apply=rebuild"})
+		return nil, nil, "", "", 0, false
+	default:
+		utils.WriteJSONStatus(w, http.StatusBadRequest, map[string]string{"error": "apply inválido: use rebuild ou restart"})
+		return nil, nil, "", "", 0, false
+	}
+	maxBytes, _ := files.MaxFileBytes(app.Username)
+	return app, root, p, apply, maxBytes, true
+}
+
+// 🔁 Aplica agora (rebuild/restart) ou deixa para a próxima vez que a aplicação for reconstruída ou reiniciada
+func writeFilesChange(w http.ResponseWriter, r *http.Request, app *models.App, apply string, status int, message string, extra map[string]interface{}) {
+	response := map[string]interface{}{"message": message, "applied": apply != ""}
+	for k, v := range extra {
+		response[k] = v
+	}
+
+	var err error
+	switch apply {
+	case "rebuild":
+		err = services.RebuildApp(app.ID, app.Username)
+	case "restart":
+		err = services.RestartApp(app.ID, app.Username)
+	default:
+		response["message"] = message + " A alteração vale a partir do próximo rebuild (código) ou restart (volumes)."
+	}
+	if err != nil {
+		audit.Fail(r, err.Error())
+		response["error"] = "Arquivo salvo, mas a aplicação não foi atualizada: " + err.Error()
+		response["applied"] = false
+		utils.WriteJSONStatus(w, http.StatusInternalServerError, response)
+		return
+	}
+	if apply != "" {
+		audit.SetDetail(r, "apply", apply)
+	}
+	utils.WriteJSONStatus(w, status, response)
+}
+
+// 🚦 Erros de validação/plano viram 4xx; o resto é falha interna
+func filesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, files.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, files.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, files.ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, files.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, files.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,58 @@
+//backend/routes/ingress.go
+
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"virtuscloud/backend/acme"
+	"virtuscloud/backend/ingress"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/utils"
+)
+
+// 🚪 GET /api/app/routes — hostnames públicos das aplicações do usuário (?id= filtra uma aplicação)
+func AppRoutesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	in := ingress.Default()
+	if in == nil {
+		utils.WriteJSON(w, map[string]interface{}{"enabled": false, "routes": []ingress.Route{}})
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	appID := r.URL.Query().Get("id")
+	routes := []ingress.Route{}
+	for _, route := range in.Table().Routes(username) {
+		if appID == "" || route.AppID == appID || route.Container == appID {
+			routes = append(routes, route)
+		}
+	}
+
+	// 🔐 Certificado que atende cada hostname (apenas com o ACME ligado)
+	type certificateInfo struct {
+		Name     string    `json:"name"`
+		NotAfter time.Time `json:"notAfter"`
+	}
+	certificates := map[string]certificateInfo{}
+	if manager := acme.Default(); manager != nil {
+		for _, route := range routes {
+			if name, notAfter, ok := manager.CertificateFor(route.Host); ok {
+				certificates[route.Host] = certificateInfo{Name: name, NotAfter: notAfter}
+			}
+		}
+	}
+
+	utils.WriteJSON(w, map[string]interface{}{
+		"enabled":      true,
+		"baseDomain":   in.Config().BaseDomain,
+		"routes":       routes,
+		"tls":          acme.Default() != nil,
+		"certificates": certificates,
+	})
+}
@@ -5,6 +5,7 @@ package routes
 import (
 	"encoding/json"
 	"net/http"
+	"virtuscloud/backend/audit"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/services"
 	"virtuscloud/backend/utils"
@@ -21,11 +22,14 @@ func UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	audit.SetTarget(r, email)
+
 	var req ProfileUpdate
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
 		http.Error(w, "Nome inválido", http.StatusBadRequest)
 		return
 	}
+	audit.SetDetail(r, "name", req.Name)
 
 	user, err := services.UpdateUserNameByEmail(email, req.Name)
 	if err != nil {
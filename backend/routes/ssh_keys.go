@@ -0,0 +1,108 @@
+//backend/routes/ssh_keys.go
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/sftp"
+	"virtuscloud/backend/utils"
+)
+
+// 🔑 GET /api/profile/ssh-keys — chaves do usuário e os dados de conexão do SFTP
+func ListSSHKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	keys, err := services.ListSSHKeys(username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	utils.WriteJSON(w, map[string]interface{}{"keys": keys, "sftp": sftp.Info(username)})
+}
+
+// ➕ POST /api/profile/ssh-keys/add — {name?, publicKey} com a linha do arquivo .pub
+func AddSSHKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		Name      string `json:"name"`
+		PublicKey string `json:"publicKey"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	username, _ := middleware.GetUserFromContext(r)
+	audit.SetTarget(r, username)
+
+	key, err := services.AddSSHKey(username, payload.Name, payload.PublicKey)
+	if err != nil {
+		utils.WriteJSONStatus(w, sshKeyErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	audit.SetDetail(r, "name", key.Name)
+	audit.SetDetail(r, "fingerprint", key.Fingerprint)
+	utils.WriteJSONStatus(w, http.StatusCreated, map[string]interface{}{
+		"message": "Chave SSH registrada com sucesso!",
+		"key":     key,
+	})
+}
+
+// 🗑️ POST /api/profile/ssh-keys/delete — {fingerprint}; sessões SFTP já abertas com a chave continuam até fechar
+func DeleteSSHKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		Fingerprint string `json:"fingerprint"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	username, _ := middleware.GetUserFromContext(r)
+	audit.SetTarget(r, username)
+	audit.SetDetail(r, "fingerprint", payload.Fingerprint)
+
+	key, err := services.RemoveSSHKey(username, payload.Fingerprint)
+	if err != nil {
+		utils.WriteJSONStatus(w, sshKeyErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	audit.SetDetail(r, "name", key.Name)
+	utils.WriteJSON(w, map[string]interface{}{
+		"message": "Chave SSH removida com sucesso!",
+		"key":     key,
+	})
+}
+
+// 🚦 Erros de validação viram 4xx; o resto é falha interna
+func sshKeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSSHKeyInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrSSHKeyExists):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrSSHKeyLimit):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrSSHKeyNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
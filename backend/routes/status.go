@@ -6,7 +6,7 @@ import (
 	"net/http"
 	"strings"
 
-	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
 )
 
 // Handler para verificar o status de uma aplicação
@@ -22,7 +22,7 @@ func StatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	app := services.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil {
 		respondError(w, http.StatusNotFound, "Aplicação não encontrada")
 		return
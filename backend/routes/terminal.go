@@ -0,0 +1,290 @@
+//backend/routes/terminal.go
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/utils"
+	"virtuscloud/backend/websocket"
+)
+
+// ⏱️ Terminal: ping a cada terminalPing; cliente que não responde em 2×terminalPing cai
+const (
+	terminalPing        = 30 * time.Second
+	terminalMaxMessage  = 64 * 1024 // colagens grandes chegam em uma mensagem só
+	terminalOutputChunk = 32 * 1024
+	terminalMaxSize     = 1000
+)
+
+// 📨 Mensagens de texto do cliente (binárias são repassadas direto ao teclado)
+type terminalControl struct {
+	Type string `json:"type"` // input, resize, ping
+	Data string `json:"data,omitempty"`
+	Rows int    `json:"rows,omitempty"`
+	Cols int    `json:"cols,omitempty"`
+}
+
+// 🏁 Resumo da sessão gravado na auditoria
+type terminalResult struct {
+	reason   string // exit, client, idle, error
+	exitCode int
+	hasExit  bool
+	bytesIn  int64
+	bytesOut int64
+	err      error
+}
+
+// 🖥️ GET /api/app/terminal?id=&rows=&cols= (WebSocket) — shell interativo no container da aplicação.
+// Saída do terminal em mensagens binárias; eventos ready/exit/pong em JSON de texto.
+// Entrada: mensagens binárias (teclado) ou JSON {"type":"input","data"} / {"type":"resize","rows","cols"} / {"type":"ping"}.
+func AppTerminalHandler(w http.ResponseWriter, r *http.Request) {
+	wsOpts := websocket.Options{
+		MaxMessageBytes: terminalMaxMessage,
+		ReadTimeout:     2 * terminalPing,
+		AllowedOrigins:  terminalOrigins(),
+	}
+	// Handshake inválido é recusado antes de abrir o exec no container
+	if status, message := websocket.Validate(r, wsOpts); status != 0 {
+		if status == http.StatusUpgradeRequired {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+		}
+		http.Error(w, message, status)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(r.URL.Query().Get("id"))
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return
+	}
+
+	rows, errRows := parseTerminalSize(r.URL.Query().Get("rows"))
+	cols, errCols := parseTerminalSize(r.URL.Query().Get("cols"))
+	if errRows != nil || errCols != nil {
+		http.Error(w, fmt.Sprintf("rows e cols devem estar entre 1 e %d", terminalMaxSize), http.StatusBadRequest)
+		return
+	}
+
+	term, err := services.OpenTerminal(app, utils.GetRealIP(r), rows, cols)
+	switch {
+	case errors.Is(err, services.ErrTerminalNotAllowed):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, services.ErrTerminalNotRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer term.Close()
+	audit.SetTarget(r, app.ContainerName)
+	audit.SetDetail(r, "session", term.Session.ID)
+
+	conn, err := websocket.Upgrade(w, r, wsOpts)
+	if err != nil {
+		return
+	}
+
+	result := runTerminal(conn, term, services.TerminalIdleTimeout())
+	audit.SetDetail(r, "durationMs", strconv.FormatInt(time.Since(term.Session.StartedAt).Milliseconds(), 10))
+	audit.SetDetail(r, "reason", result.reason)
+	audit.SetDetail(r, "bytesIn", strconv.FormatInt(result.bytesIn, 10))
+	audit.SetDetail(r, "bytesOut", strconv.FormatInt(result.bytesOut, 10))
+	if result.hasExit {
+		audit.SetDetail(r, "exitCode", strconv.Itoa(result.exitCode))
+	}
+	if result.err != nil {
+		audit.Fail(r, result.err.Error())
+	}
+}
+
+// 📋 GET /api/app/terminal/sessions — terminais abertos do usuário
+func ListTerminalSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	username, _ := middleware.GetUserFromContext(r)
+	utils.WriteJSON(w, map[string]interface{}{
+		"sessions":           services.ListTerminals(username),
+		"idleTimeoutSeconds": int(services.TerminalIdleTimeout().Seconds()),
+	})
+}
+
+func parseTerminalSize(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > terminalMaxSize {
+		return 0, errors.New("tamanho inválido")
+	}
+	return n, nil
+}
+
+// 🛡️ Origens do painel liberadas além do próprio host (TERMINAL_ALLOWED_ORIGINS, separadas por vírgula)
+func terminalOrigins() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("TERMINAL_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// 🔁 Liga o WebSocket ao TTY até o shell sair, o cliente fechar ou a sessão ficar ociosa
+func runTerminal(conn *websocket.Conn, term *services.Terminal, idle time.Duration) terminalResult {
+	result := terminalResult{}
+	sendJSON := func(v interface{}) error {
+		payload, _ := json.Marshal(v)
+		return conn.WriteMessage(websocket.TextMessage, payload)
+	}
+	if err := sendJSON(map[string]interface{}{
+		"type":               "ready",
+		"session":            term.Session.ID,
+		"idleTimeoutSeconds": int(idle.Seconds()),
+	}); err != nil {
+		conn.Close(websocket.CloseInternalError, "")
+		return terminalResult{reason: "error", err: err}
+	}
+
+	// 📤 Saída do shell → cliente
+	outputDone := make(chan error, 1)
+	outBytes := make(chan int64, 1)
+	go func() {
+		var total int64
+		buf := make([]byte, terminalOutputChunk)
+		var err error
+		for {
+			n, rerr := term.Read(buf)
+			if n > 0 {
+				total += int64(n)
+				if werr := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); werr != nil {
+					err = werr
+					break
+				}
+			}
+			if rerr != nil {
+				break // fim do shell (EOF) ou sessão fechada
+			}
+		}
+		outBytes <- total
+		outputDone <- err
+	}()
+
+	// ⌨️ Cliente → teclado do shell; cada mensagem conta como atividade
+	inputDone := make(chan error, 1)
+	activity := make(chan struct{}, 1)
+	inBytes := make(chan int64, 1)
+	go func() {
+		var total int64
+		err := func() error {
+			for {
+				messageType, data, err := conn.ReadMessage()
+				if err != nil {
+					return err
+				}
+				select {
+				case activity <- struct{}{}:
+				default:
+				}
+				if messageType == websocket.BinaryMessage {
+					total += int64(len(data))
+					if _, err := term.Write(data); err != nil {
+						return err
+					}
+					continue
+				}
+				var msg terminalControl
+				if json.Unmarshal(data, &msg) != nil {
+					_ = sendJSON(map[string]string{"type": "error", "error": "mensagem inválida"})
+					continue
+				}
+				switch msg.Type {
+				case "input":
+					total += int64(len(msg.Data))
+					if _, err := term.Write([]byte(msg.Data)); err != nil {
+						return err
+					}
+				case "resize":
+					if msg.Rows < 1 || msg.Cols < 1 || msg.Rows > terminalMaxSize || msg.Cols > terminalMaxSize {
+						_ = sendJSON(map[string]string{"type": "error", "error": "tamanho inválido"})
+						continue
+					}
+					_ = term.Resize(msg.Rows, msg.Cols)
+				case "ping":
+					_ = sendJSON(map[string]string{"type": "pong"})
+				default:
+					_ = sendJSON(map[string]string{"type": "error", "error": "tipo de mensagem desconhecido"})
+				}
+			}
+		}()
+		inBytes <- total
+		inputDone <- err
+	}()
+
+	ping := time.NewTicker(terminalPing)
+	defer ping.Stop()
+	idleTimer := time.NewTimer(idle)
+	defer idleTimer.Stop()
+	lastInput := time.Now()
+
+	for result.reason == "" {
+		select {
+		case err := <-outputDone:
+			if err != nil {
+				result.reason = "client" // cliente parou de ler: tratado como desconexão
+				break
+			}
+			result.reason = "exit"
+			if code, err := term.ExitCode(); err == nil {
+				result.exitCode, result.hasExit = code, true
+			}
+			exit := map[string]interface{}{"type": "exit", "reason": "exit"}
+			if result.hasExit {
+				exit["code"] = result.exitCode
+			}
+			_ = sendJSON(exit)
+			conn.Close(websocket.CloseNormal, "sessão encerrada")
+		case err := <-inputDone:
+			result.reason = "client"
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) && !errors.Is(err, websocket.ErrClosed) {
+				conn.Close(websocket.CloseGoingAway, "")
+			}
+		case <-activity:
+			lastInput = time.Now()
+		case <-idleTimer.C:
+			if quiet := time.Since(lastInput); quiet < idle {
+				idleTimer.Reset(idle - quiet)
+				break
+			}
+			result.reason = "idle"
+			_ = sendJSON(map[string]interface{}{"type": "exit", "reason": "idle"})
+			conn.Close(websocket.ClosePolicyViolation, "inatividade")
+		case <-ping.C:
+			_ = conn.Ping()
+		}
+	}
+
+	// Fecha os dois lados e espera as goroutines contarem o tráfego
+	term.Close()
+	conn.Close(websocket.CloseGoingAway, "")
+	result.bytesOut = <-outBytes
+	result.bytesIn = <-inBytes
+	return result
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"virtuscloud/backend/audit"
 	"virtuscloud/backend/limits"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/services"
@@ -39,7 +40,7 @@ func UploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	username, _ := middleware.GetUserFromContext(r)
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
 		w.WriteHeader(http.StatusUnauthorized)
 		utils.WriteJSON(w, map[string]interface{}{
@@ -100,6 +101,8 @@ func UploadHandler(w http.ResponseWriter, r *http.Request) {
 	if appID == "" {
 		appID = fmt.Sprintf("%d", services.GenerateID())
 	}
+	audit.SetTarget(r, appID)
+	audit.SetDetail(r, "plan", plan)
 
 	// 📦 Copia o arquivo para snapshots antes do deploy
 	snapshotPath := filepath.Join(snapshotDir, appID+".zip")
@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"virtuscloud/backend/audit"
 	"virtuscloud/backend/handlers"
 	auth "virtuscloud/backend/middleware" // ✅ Corrigido: import do AuthMiddleware
 	"virtuscloud/backend/models"
@@ -28,8 +29,8 @@ func UserRoutes() http.Handler {
 		r.Use(auth.AuthMiddleware) // ✅ Corrigido: uso do middleware correto
 
 		// 🔧 Rotas relacionadas a usuários autenticados
-		r.Post("/assign-plan", handlers.AssignPlanHandler) // 📝 Atribui plano ao usuário
-		r.Get("/plan", handlers.GetUserPlanHandler)        // ✅ Retorna plano atual do usuário
+		r.Method(http.MethodPost, "/assign-plan", audit.Middleware("user.plan.assign", http.HandlerFunc(handlers.AssignPlanHandler))) // 📝 Atribui plano ao usuário
+		r.Get("/plan", handlers.GetUserPlanHandler)                                                                                   // ✅ Retorna plano atual do usuário
 		//r.Get("/api/user/plan-migrations", handlers.GetUserPlanMigrationsHandler)
 		// 📌 Futuras rotas protegidas que você pode adicionar:
 		// r.Get("/api/user/details", handlers.GetUserDetailsHandler)
@@ -41,11 +42,9 @@ func UserRoutes() http.Handler {
 
 // 🔍 Lista todos os usuários salvos no sistema (admin only)
 func ListUsersHandler(w http.ResponseWriter, r *http.Request) {
-	users := []*models.User{}
-
-	// 🧠 Converte o map para slice
-	for _, user := range store.UserStore {
-		users = append(users, user)
+	users := store.UserStore.List()
+	if users == nil {
+		users = []*models.User{}
 	}
 
 	// 📤 Retorna como JSON
@@ -0,0 +1,212 @@
+//backend/routes/volumes.go
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/middleware"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+	"virtuscloud/backend/volumes"
+)
+
+// 💽 GET /api/volumes — volumes do usuário e o armazenamento frente ao plano
+func ListUserVolumesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username, _ := middleware.GetUserFromContext(r)
+	summary, err := volumes.Usage(username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	list := store.VolumeStore.ListByUser(username)
+	if list == nil {
+		list = []*models.Volume{}
+	}
+	utils.WriteJSON(w, map[string]interface{}{"volumes": list, "storage": summary})
+}
+
+// 📱 GET /api/app/volumes?id= — volumes da aplicação com o uso medido
+func ListAppVolumesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app, ok := ownedVolumeApp(w, r, r.URL.Query().Get("id"))
+	if !ok {
+		return
+	}
+	list := store.VolumeStore.ListByApp(app.ID)
+	if list == nil {
+		list = []*models.Volume{}
+	}
+	used, reserved := volumes.AppUsage(app.ID)
+	utils.WriteJSON(w, map[string]interface{}{
+		"id":         app.ID,
+		"volumes":    list,
+		"usedBytes":  used,
+		"usage":      volumes.FormatBytes(used),
+		"reservedGB": reserved,
+	})
+}
+
+// ➕ POST /api/app/volumes/create — {id, name, mountPath, sizeGB, restart}
+func CreateVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		MountPath string `json:"mountPath"`
+		SizeGB    int    `json:"sizeGB"`
+		Restart   bool   `json:"restart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "volume", payload.Name)
+	audit.SetDetail(r, "mountPath", payload.MountPath)
+
+	app, ok := ownedVolumeApp(w, r, payload.ID)
+	if !ok {
+		return
+	}
+	volume, err := volumes.Create(app, payload.Name, payload.MountPath, payload.SizeGB)
+	if err != nil {
+		utils.WriteJSONStatus(w, volumeErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeVolumeChange(w, r, app, payload.Restart, http.StatusCreated, "Volume criado com sucesso!", volume)
+}
+
+// ✏️ POST /api/app/volumes/update — {id, name, sizeGB?, mountPath?, restart}
+func UpdateVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		MountPath string `json:"mountPath"`
+		SizeGB    int    `json:"sizeGB"`
+		Restart   bool   `json:"restart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "volume", payload.Name)
+
+	app, ok := ownedVolumeApp(w, r, payload.ID)
+	if !ok {
+		return
+	}
+	volume, err := volumes.Update(app.ID+"/"+payload.Name, payload.SizeGB, payload.MountPath)
+	if err != nil {
+		utils.WriteJSONStatus(w, volumeErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeVolumeChange(w, r, app, payload.Restart, http.StatusOK, "Volume atualizado com sucesso!", volume)
+}
+
+// 🗑️ POST /api/app/volumes/delete — {id, name, confirm, restart}; confirm deve repetir o nome (os dados são apagados)
+func DeleteVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Confirm string `json:"confirm"`
+		Restart bool   `json:"restart"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	audit.SetTarget(r, payload.ID)
+	audit.SetDetail(r, "volume", payload.Name)
+
+	app, ok := ownedVolumeApp(w, r, payload.ID)
+	if !ok {
+		return
+	}
+	if payload.Confirm != payload.Name {
+		utils.WriteJSONStatus(w, http.StatusBadRequest, map[string]string{"error": "Confirme repetindo o nome do volume em 'confirm': os dados serão apagados"})
+		return
+	}
+	if err := volumes.Remove(app.ID + "/" + payload.Name); err != nil {
+		utils.WriteJSONStatus(w, volumeErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeVolumeChange(w, r, app, payload.Restart, http.StatusOK, "Volume removido com sucesso!", nil)
+}
+
+// 🔐 Confere se a aplicação pertence ao usuário autenticado
+func ownedVolumeApp(w http.ResponseWriter, r *http.Request, id string) (*models.App, bool) {
+	username, _ := middleware.GetUserFromContext(r)
+	app := services.GetAppByContainerName(id)
+	if app == nil || app.Username != username {
+		http.Error(w, "Aplicação não encontrada ou não pertence ao usuário", http.StatusForbidden)
+		return nil, false
+	}
+	return app, true
+}
+
+// 🔁 Remonta agora (restart=true) ou deixa para o próximo start, restart ou rebuild
+func writeVolumeChange(w http.ResponseWriter, r *http.Request, app *models.App, restart bool, status int, message string, volume *models.Volume) {
+	if restart {
+		if err := services.ApplyAppSpecChange(app); err != nil {
+			audit.Fail(r, err.Error())
+			utils.WriteJSONStatus(w, http.StatusInternalServerError, map[string]interface{}{
+				"error":  "Volume salvo, mas a aplicação não foi recriada: " + err.Error(),
+				"volume": volume,
+			})
+			return
+		}
+	} else {
+		message += " A montagem vale a partir do próximo start, restart ou rebuild."
+	}
+	utils.WriteJSONStatus(w, status, map[string]interface{}{
+		"message": message,
+		"applied": restart,
+		"volume":  volume,
+	})
+}
+
+// 🚦 Erros de validação/plano viram 4xx; o resto é falha interna
+func volumeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, volumes.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, volumes.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, volumes.ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, volumes.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,310 @@
+//backend/services/app_env.go
+
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/vault"
+)
+
+// 🏷️ Labels do container: chaves injetadas pela plataforma e impressão digital do ambiente aplicado
+const (
+	EnvKeysLabel = "virtuscloud.env-keys"
+	EnvHashLabel = "virtuscloud.env-hash"
+)
+
+// 📏 Limites por aplicação
+const (
+	MaxEnvVars       = 100
+	MaxEnvValueBytes = 32 * 1024
+)
+
+// 🙈 Valor exibido no lugar de segredos (respostas da API e logs)
+const MaskedValue = "********"
+
+// 🙈 Segredos mais curtos que isso não são procurados nos logs (mascarariam texto comum)
+const minMaskedSecretLen = 4
+
+// ❗ Erro de validação (o handler responde 400 com errors.Is)
+var ErrInvalidEnv = errors.New("variável de ambiente inválida")
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 🔒 Chaves definidas pela própria plataforma
+var reservedEnvKeys = map[string]bool{"PORT": true, "HOSTNAME": true}
+
+// ✏️ Variável enviada pelo usuário
+type EnvInput struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
+}
+
+// 👁️ Variável como a API exibe (segredos sempre mascarados)
+type EnvView struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Secret    bool      `json:"secret"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// 🙈 Segredos em claro por aplicação, só para mascarar logs (preenchido sob demanda)
+var (
+	maskMu    sync.Mutex
+	maskCache = map[string][]string{}
+)
+
+// 🧹 Variáveis saem junto com a aplicação
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		if err := store.AppEnvStore.Delete(app.ID); err != nil {
+			log.Printf("⚠️ Erro ao remover variáveis da aplicação %s: %v", app.ID, err)
+		}
+		forgetSecrets(app.ID)
+	})
+}
+
+// 📋 Variáveis da aplicação com os segredos mascarados
+func ListAppEnv(appID string) []EnvView {
+	views := []EnvView{}
+	env, ok := store.AppEnvStore.Get(appID)
+	if !ok {
+		return views
+	}
+	for _, v := range env.Vars {
+		view := EnvView{Key: v.Key, Value: v.Value, Secret: v.Secret, UpdatedAt: v.UpdatedAt}
+		if v.Secret {
+			view.Value = MaskedValue
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// 💾 Cria ou substitui variáveis (segredos são selados com a chave mestra antes de persistir)
+func SetAppEnv(app *models.App, inputs []EnvInput) error {
+	if len(inputs) == 0 {
+		return fmt.Errorf("%w: nenhuma variável informada", ErrInvalidEnv)
+	}
+
+	now := time.Now()
+	incoming := map[string]models.EnvVar{}
+	for _, in := range inputs {
+		key := strings.TrimSpace(in.Key)
+		if err := validateEnvKey(key); err != nil {
+			return err
+		}
+		if len(in.Value) > MaxEnvValueBytes {
+			return fmt.Errorf("%w: valor de %s excede %d bytes", ErrInvalidEnv, key, MaxEnvValueBytes)
+		}
+		if strings.ContainsRune(in.Value, 0) {
+			return fmt.Errorf("%w: valor de %s contém byte nulo", ErrInvalidEnv, key)
+		}
+
+		v := models.EnvVar{Key: key, Secret: in.Secret, UpdatedAt: now}
+		if in.Secret {
+			sealed, err := vault.Seal([]byte(in.Value))
+			if err != nil {
+				return fmt.Errorf("erro ao cifrar segredo %s: %w", key, err)
+			}
+			v.Sealed = sealed
+		} else {
+			v.Value = in.Value
+		}
+		incoming[key] = v
+	}
+
+	_, err := store.AppEnvStore.Update(app.ID, app.Username, func(env *models.AppEnv) error {
+		merged := map[string]models.EnvVar{}
+		for _, v := range env.Vars {
+			merged[v.Key] = v
+		}
+		for key, v := range incoming {
+			merged[key] = v
+		}
+		if len(merged) > MaxEnvVars {
+			return fmt.Errorf("%w: limite de %d variáveis por aplicação", ErrInvalidEnv, MaxEnvVars)
+		}
+		env.Username = app.Username
+		env.Vars = sortedEnvVars(merged)
+		return nil
+	})
+	forgetSecrets(app.ID)
+	return err
+}
+
+// 🗑️ Remove variáveis pelas chaves e devolve as que existiam
+func DeleteAppEnv(app *models.App, keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: nenhuma chave informada", ErrInvalidEnv)
+	}
+	drop := map[string]bool{}
+	for _, key := range keys {
+		drop[strings.TrimSpace(key)] = true
+	}
+
+	var removed []string
+	_, err := store.AppEnvStore.Update(app.ID, app.Username, func(env *models.AppEnv) error {
+		kept := env.Vars[:0]
+		for _, v := range env.Vars {
+			if drop[v.Key] {
+				removed = append(removed, v.Key)
+				continue
+			}
+			kept = append(kept, v)
+		}
+		env.Vars = kept
+		return nil
+	})
+	forgetSecrets(app.ID)
+	return removed, err
+}
+
+func validateEnvKey(key string) error {
+	if !envKeyPattern.MatchString(key) || len(key) > 128 {
+		return fmt.Errorf("%w: chave '%s' (use letras, números e _, sem começar por número)", ErrInvalidEnv, key)
+	}
+	if reservedEnvKeys[strings.ToUpper(key)] {
+		return fmt.Errorf("%w: %s é definida pela plataforma", ErrInvalidEnv, key)
+	}
+	return nil
+}
+
+func sortedEnvVars(vars map[string]models.EnvVar) []models.EnvVar {
+	out := make([]models.EnvVar, 0, len(vars))
+	for _, v := range vars {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// 🔓 Lista KEY=VALUE com os segredos decifrados, pronta para o container
+func AppEnvironment(appID string) ([]string, error) {
+	env, ok := store.AppEnvStore.Get(appID)
+	if !ok {
+		return nil, nil
+	}
+	out := make([]string, 0, len(env.Vars))
+	for _, v := range env.Vars {
+		value := v.Value
+		if v.Secret {
+			plain, err := vault.Open(v.Sealed)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao decifrar segredo %s: %w", v.Key, err)
+			}
+			value = string(plain)
+		}
+		out = append(out, v.Key+"="+value)
+	}
+	return out, nil
+}
+
+// 🧬 Impressão digital do ambiente salvo (sobre os valores selados; nunca sobre segredos em claro)
+func appEnvHash(appID string) string {
+	env, ok := store.AppEnvStore.Get(appID)
+	if !ok || len(env.Vars) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, v := range env.Vars {
+		fmt.Fprintf(h, "%s\x00%t\x00%s\x00%s\n", v.Key, v.Secret, v.Value, v.Sealed)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// 💉 Injeta as variáveis da aplicação na especificação do container.
+// Chaves injetadas antes (label EnvKeysLabel) são retiradas, então serve também para recriar.
+func ApplyAppEnv(spec *engine.Spec, appID string) error {
+	vars, err := AppEnvironment(appID)
+	if err != nil {
+		return err
+	}
+
+	managed := map[string]bool{}
+	if spec.Labels != nil {
+		for _, key := range strings.Split(spec.Labels[EnvKeysLabel], ",") {
+			if key != "" {
+				managed[key] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(vars))
+	for _, kv := range vars {
+		key := kv[:strings.IndexByte(kv, '=')]
+		managed[key] = true
+		keys = append(keys, key)
+	}
+
+	kept := make([]string, 0, len(spec.Env)+len(vars))
+	for _, kv := range spec.Env {
+		if key, _, _ := strings.Cut(kv, "="); !managed[key] {
+			kept = append(kept, kv)
+		}
+	}
+	spec.Env = append(kept, vars...)
+
+	labels := make(map[string]string, len(spec.Labels)+2)
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	labels[EnvKeysLabel] = strings.Join(keys, ",")
+	labels[EnvHashLabel] = appEnvHash(appID)
+	spec.Labels = labels
+	return nil
+}
+
+// 🙈 Substitui os segredos da aplicação presentes no texto por MaskedValue
+func MaskSecrets(appID, text string) string {
+	if appID == "" || text == "" {
+		return text
+	}
+	for _, secret := range secretsOf(appID) {
+		text = strings.ReplaceAll(text, secret, MaskedValue)
+	}
+	return text
+}
+
+// 🔓 Segredos em claro da aplicação, do maior para o menor (cache até a próxima alteração)
+func secretsOf(appID string) []string {
+	maskMu.Lock()
+	defer maskMu.Unlock()
+
+	if secrets, ok := maskCache[appID]; ok {
+		return secrets
+	}
+	secrets := []string{}
+	if env, ok := store.AppEnvStore.Get(appID); ok {
+		for _, v := range env.Vars {
+			if !v.Secret {
+				continue
+			}
+			plain, err := vault.Open(v.Sealed)
+			if err != nil || len(plain) < minMaskedSecretLen {
+				continue
+			}
+			secrets = append(secrets, string(plain))
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	maskCache[appID] = secrets
+	return secrets
+}
+
+func forgetSecrets(appID string) {
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	delete(maskCache, appID)
+}
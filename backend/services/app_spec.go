@@ -0,0 +1,148 @@
+//backend/services/app_spec.go
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/volumes"
+)
+
+// 🏷️ Label do container com os binds de volumes aplicados pela plataforma
+const VolumesLabel = "virtuscloud.volumes"
+
+// 🧩 Completa a especificação com o que a aplicação configurou (variáveis, segredos e volumes)
+// e com a rede privada do dono, onde ela responde pelo nome
+func ApplyAppSpec(spec *engine.Spec, appID string) error {
+	if err := ApplyAppEnv(spec, appID); err != nil {
+		return err
+	}
+	ApplyAppVolumes(spec, appID)
+	if app, ok := store.AppStore.Get(appID); ok {
+		return ApplyAppNetwork(spec, app)
+	}
+	return nil
+}
+
+// 💽 Monta os volumes da aplicação; binds aplicados antes (label VolumesLabel) são trocados pelos atuais
+func ApplyAppVolumes(spec *engine.Spec, appID string) {
+	previous := map[string]bool{}
+	if spec.Labels != nil {
+		for _, bind := range strings.Split(spec.Labels[VolumesLabel], ",") {
+			previous[bind] = true
+		}
+	}
+	binds := volumes.Binds(appID)
+
+	kept := make([]string, 0, len(spec.Binds)+len(binds))
+	for _, bind := range spec.Binds {
+		if !previous[bind] {
+			kept = append(kept, bind)
+		}
+	}
+	spec.Binds = append(kept, binds...)
+
+	labels := make(map[string]string, len(spec.Labels)+1)
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	labels[VolumesLabel] = strings.Join(binds, ",")
+	spec.Labels = labels
+}
+
+// 🔍 O container já reflete variáveis e volumes atuais?
+func specUpToDate(labels map[string]string, appID string) bool {
+	return labels[EnvHashLabel] == appEnvHash(appID) &&
+		labels[VolumesLabel] == strings.Join(volumes.Binds(appID), ",")
+}
+
+// 🔄 Recria o container quando variáveis ou volumes mudaram (só valem na criação).
+// start = iniciar o container recriado; devolve true se houve recriação.
+func SyncContainerSpec(app *models.App, start bool) (bool, error) {
+	if app.ContainerName == "" {
+		return false, nil
+	}
+	rt := engine.Default()
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	info, err := rt.Inspect(ctx, app.ContainerName)
+	if err != nil {
+		if engine.IsNotFound(err) {
+			return false, nil // sem container: o próximo deploy/rebuild já nasce com a configuração atual
+		}
+		return false, fmt.Errorf("erro ao inspecionar container: %w", err)
+	}
+	if specUpToDate(info.Labels, app.ID) {
+		return false, IsolateAppContainer(app)
+	}
+
+	if err := recreateContainer(ctx, app, info.Spec, start); err != nil {
+		return true, err
+	}
+	Log(app.ID, app.Username, app.Plan, "🔄 Container recriado com variáveis e volumes atualizados")
+	return true, nil
+}
+
+// 🆕 Recria o container a partir da especificação atual (imagem pela tag, variáveis e volumes de agora)
+func RecreateAppContainer(app *models.App, start bool) error {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	info, err := engine.Default().Inspect(ctx, app.ContainerName)
+	if err != nil {
+		return fmt.Errorf("erro ao inspecionar container: %w", err)
+	}
+	return recreateContainer(ctx, app, info.Spec, start)
+}
+
+func recreateContainer(ctx context.Context, app *models.App, spec engine.Spec, start bool) error {
+	rt := engine.Default()
+	if err := ApplyAppSpec(&spec, app.ID); err != nil {
+		return err
+	}
+	if err := rt.Remove(ctx, app.ContainerName, true); err != nil && !engine.IsNotFound(err) {
+		return fmt.Errorf("erro ao remover container para aplicar configuração: %w", err)
+	}
+	if _, err := rt.Create(ctx, spec); err != nil {
+		return fmt.Errorf("erro ao recriar container com nova configuração: %w", err)
+	}
+	if start {
+		if err := rt.Start(ctx, app.ContainerName); err != nil {
+			return fmt.Errorf("erro ao iniciar container recriado: %w", err)
+		}
+	}
+	return nil
+}
+
+// 🔁 Aplica a configuração alterada agora: recria o container mantendo o estado (rodando continua rodando)
+func ApplyAppSpecChange(app *models.App) error {
+	running := false
+	if app.ContainerName != "" {
+		ctx, cancel := engine.Timeout()
+		info, err := engine.Default().Inspect(ctx, app.ContainerName)
+		cancel()
+		running = err == nil && info.Running
+	}
+	_, err := SyncContainerSpec(app, running)
+	return err
+}
+
+// 💽 Remonta os volumes depois que a medição mudou o estado de cota (somente leitura ↔ leitura e escrita)
+func RemountAppVolumes(appID string) {
+	app, ok := store.AppStore.Get(appID)
+	if !ok {
+		return
+	}
+	for _, volume := range store.VolumeStore.ListByApp(appID) {
+		if volume.OverQuota {
+			Log(app.ID, app.Username, app.Plan, fmt.Sprintf("🚫 Volume %s acima da cota de %d GB: montado somente leitura até liberar espaço ou aumentar a cota", volume.Name, volume.SizeGB))
+		}
+	}
+	if err := ApplyAppSpecChange(app); err != nil {
+		Log(app.ID, app.Username, app.Plan, fmt.Sprintf("⚠️ Erro ao remontar volumes: %v", err))
+	}
+}
@@ -4,28 +4,28 @@ package services
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
+	"virtuscloud/backend/engine"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/store"
 	"virtuscloud/backend/utils"
+	"virtuscloud/backend/volumes"
 )
 
 // 🔍 Busca aplicação pelo nome real do container
 func GetAppByContainerName(name string) *models.App {
 	log.Println("🔍 Buscando container:", name)
 
-	for _, app := range store.AppStore {
-		log.Printf("🔍 Comparando: app.ID=%s, app.ContainerName=%s", app.ID, app.ContainerName)
-		if app.ContainerName == name || app.ID == name {
-			log.Println("✅ Localizado:", app.ID)
-			return app
-		}
+	if app, ok := store.AppStore.FindByContainer(name); ok {
+		log.Println("✅ Localizado:", app.ID)
+		return app
 	}
 
 	log.Println("❌ Container não encontrado")
@@ -58,7 +58,7 @@ func GetAppByContainerName(name string) *models.App {
 
 // ▶️ Inicia a aplicação
 func StartApp(id, username string) error {
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		return fmt.Errorf("aplicação não encontrada ou não pertence ao usuário")
 	}
@@ -68,11 +68,22 @@ func StartApp(id, username string) error {
 	}
 
 	log.Println("▶️ Iniciando aplicação:", app.ContainerName)
-	cmd := exec.Command("docker", "start", app.ContainerName)
-	if err := cmd.Run(); err != nil {
-		log.Println("❌ Erro ao iniciar aplicação:", err)
+	setDesiredStatus(app, models.StatusRunning)
+
+	// 🔄 Variáveis ou volumes alterados desde a criação exigem recriar o container (que já sai iniciado)
+	recreated, err := SyncContainerSpec(app, true)
+	if err != nil {
+		log.Println("❌ Erro ao aplicar variáveis e volumes:", err)
 		return fmt.Errorf("erro ao iniciar aplicação: %w", err)
 	}
+	if !recreated {
+		ctx, cancel := engine.Timeout()
+		defer cancel()
+		if err := engine.Default().Start(ctx, app.ContainerName); err != nil {
+			log.Println("❌ Erro ao iniciar aplicação:", err)
+			return fmt.Errorf("erro ao iniciar aplicação: %w", err)
+		}
+	}
 
 	app.Status = models.StatusRunning
 	app.Logs = append(app.Logs, "Aplicação iniciada!")
@@ -83,30 +94,31 @@ func StartApp(id, username string) error {
 
 // ⏸️ Para a aplicação
 func StopApp(id, username string) error {
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		return fmt.Errorf("aplicação não encontrada ou não pertence ao usuário")
 	}
 
 	log.Println("📡 StopApp chamado para ID:", id)
+
+	// 🎯 Gravado antes de parar: o reconciliador não religa o que o usuário desligou
+	setDesiredStatus(app, models.StatusStopped)
 	log.Println("⏸️ Atualizando política de restart para 'no'")
 
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+
 	// Desativa reinício automático
-	updateCmd := exec.Command("docker", "update", "--restart=no", app.ContainerName)
-	updateOut, updateErr := updateCmd.CombinedOutput()
-	if updateErr != nil {
-		log.Println("❌ Erro ao atualizar política de restart:", updateErr)
-		log.Println("🪵 Saída do comando:", string(updateOut))
-		return fmt.Errorf("erro ao atualizar política de restart: %w", updateErr)
+	if err := rt.Update(ctx, app.ContainerName, engine.Update{RestartPolicy: "no"}); err != nil {
+		log.Println("❌ Erro ao atualizar política de restart:", err)
+		return fmt.Errorf("erro ao atualizar política de restart: %w", err)
 	}
 
 	log.Println("⏸️ Parando aplicação:", app.ContainerName)
-	stopCmd := exec.Command("docker", "stop", app.ContainerName)
-	stopOut, stopErr := stopCmd.CombinedOutput()
-	if stopErr != nil {
-		log.Println("❌ Erro ao parar aplicação:", stopErr)
-		log.Println("🪵 Saída do comando:", string(stopOut))
-		return fmt.Errorf("erro ao parar aplicação: %w", stopErr)
+	if err := rt.Stop(ctx, app.ContainerName, 10*time.Second); err != nil {
+		log.Println("❌ Erro ao parar aplicação:", err)
+		return fmt.Errorf("erro ao parar aplicação: %w", err)
 	}
 
 	app.Status = models.StatusStopped
@@ -118,17 +130,28 @@ func StopApp(id, username string) error {
 
 // 🔁 Reinicia a aplicação
 func RestartApp(id, username string) error {
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		return fmt.Errorf("aplicação não encontrada ou não pertence ao usuário")
 	}
 
 	log.Println("🔁 Reiniciando aplicação:", app.ContainerName)
-	cmd := exec.Command("docker", "restart", app.ContainerName)
-	if err := cmd.Run(); err != nil {
-		log.Println("❌ Erro ao reiniciar aplicação:", err)
+	setDesiredStatus(app, models.StatusRunning)
+
+	// 🔄 Recriar com variáveis e volumes atuais já equivale ao reinício
+	recreated, err := SyncContainerSpec(app, true)
+	if err != nil {
+		log.Println("❌ Erro ao aplicar variáveis e volumes:", err)
 		return fmt.Errorf("erro ao reiniciar aplicação: %w", err)
 	}
+	if !recreated {
+		ctx, cancel := engine.Timeout()
+		defer cancel()
+		if err := engine.Default().Restart(ctx, app.ContainerName, 10*time.Second); err != nil {
+			log.Println("❌ Erro ao reiniciar aplicação:", err)
+			return fmt.Errorf("erro ao reiniciar aplicação: %w", err)
+		}
+	}
 
 	app.Status = models.StatusRunning
 	app.Logs = append(app.Logs, "Aplicação reiniciada com sucesso!")
@@ -136,6 +159,20 @@ func RestartApp(id, username string) error {
 	Log(app.ID, username, app.Plan, "🔁 Aplicação reiniciada!")
 	return nil
 }
+
+// 🎯 Persiste o estado desejado antes de agir no container (eventos do runtime chegam ao reconciliador na hora).
+// Ação do usuário também encerra a espera de crash loop.
+func setDesiredStatus(app *models.App, status models.AppStatus) {
+	app.DesiredStatus = status
+	app.CrashLoop = nil
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		a.DesiredStatus = status
+		a.CrashLoop = nil
+	}); err != nil {
+		log.Printf("⚠️ Erro ao gravar estado desejado de %s: %v", app.ID, err)
+	}
+}
+
 func CleanAppID(rawID string) string {
 	parts := strings.Split(rawID, "-")
 	if len(parts) > 1 {
@@ -146,33 +183,35 @@ func CleanAppID(rawID string) string {
 
 // 🔧 Reconstrói a aplicação com base no runtime
 func RebuildApp(id, username string) error {
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		return fmt.Errorf("aplicação não encontrada ou não pertence ao usuário")
 	}
 
+	// 📦 Localiza snapshot (antes de remover qualquer coisa)
+	snapshotPath := filepath.Join("storage", "users", username, app.Plan, "snapshots", app.ID+".zip")
+	if _, err := os.Stat(snapshotPath); os.IsNotExist(err) {
+		return fmt.Errorf("snapshot não encontrado para: %s", app.ID)
+	}
+
 	// 🧹 Remove container antigo
 	if app.ContainerName != "" {
 		Log(app.ID, username, app.Plan, "🧹 Removendo aplicação antiga: "+app.ContainerName)
-		cmd := exec.Command("docker", "rm", "-f", app.ContainerName)
-		if out, err := cmd.CombinedOutput(); err != nil {
-			Log(app.ID, username, app.Plan, fmt.Sprintf("⚠️ Erro ao remover aplicação antiga: %v\nSaída: %s", err, string(out)))
+		ctx, cancel := engine.Timeout()
+		err := engine.Default().Remove(ctx, app.ContainerName, true)
+		cancel()
+		if err != nil {
+			Log(app.ID, username, app.Plan, fmt.Sprintf("⚠️ Erro ao remover aplicação antiga: %v", err))
 		} else {
 			Log(app.ID, username, app.Plan, "✅ Aplicação antiga removida com sucesso")
 		}
 	}
 
-	// 📦 Localiza snapshot
-	snapshotPath := filepath.Join("storage", "users", username, app.Plan, "snapshots", app.ID+".zip")
-	if _, err := os.Stat(snapshotPath); os.IsNotExist(err) {
-		return fmt.Errorf("snapshot não encontrado para: %s", app.ID)
-	}
-
 	// 🧹 Remove pasta antiga (se ainda existir)
 	_ = os.RemoveAll(app.Path)
 
 	// 🔁 Reinstala a aplicação no mesmo ID
-	rebuildApp, err := HandleDeploy(snapshotPath, username, app.Plan, app.ID)
+	rebuildApp, err := redeployFromZip(snapshotPath, app)
 	if err != nil {
 		return fmt.Errorf("erro ao reconstruir aplicação: %v", err)
 	}
@@ -187,7 +226,7 @@ func RebuildApp(id, username string) error {
 
 // 📦 Gera backup da aplicação
 func BackupAppFromContainer(id, username string) error {
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		return fmt.Errorf("aplicação não encontrada ou não pertence ao usuário")
 	}
@@ -200,9 +239,10 @@ func BackupAppFromContainer(id, username string) error {
 	}
 
 	// 🐳 Copia /app do container para pasta temporária
-	copyCmd := exec.Command("docker", "cp", app.ContainerName+":/app", tempDir)
-	if out, err := copyCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("erro ao copiar arquivos da aplicação: %v\nSaída: %s", err, string(out))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := engine.Default().CopyFrom(ctx, app.ContainerName, "/app", tempDir); err != nil {
+		return fmt.Errorf("erro ao copiar arquivos da aplicação: %w", err)
 	}
 
 	// Caminho real dos arquivos copiados
@@ -214,6 +254,13 @@ func BackupAppFromContainer(id, username string) error {
 	// 🧹 Remove node_modules recursivamente
 	_ = os.RemoveAll(filepath.Join(appDir, "node_modules"))
 
+	// 💽 Volumes montados dentro de /app vão para o arquivo próprio, não para o snapshot do código
+	for _, volume := range store.VolumeStore.ListByApp(app.ID) {
+		if rel, ok := strings.CutPrefix(volume.MountPath, "/app/"); ok {
+			_ = os.RemoveAll(filepath.Join(appDir, filepath.FromSlash(rel)))
+		}
+	}
+
 	// 🧹 Remove symlinks (opcional, se ainda quiser)
 	filepath.Walk(appDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -238,6 +285,9 @@ func BackupAppFromContainer(id, username string) error {
 	_ = os.RemoveAll(tempDir)
 
 	app.Logs = append(app.Logs, "📦 Snapshot gerado a partir do container em "+snapshotPath)
+	if err := backupAppVolumes(app); err != nil {
+		return err
+	}
 	store.SaveApp(app)
 	Log(app.ID, username, app.Plan, "📦 Backup gerado com sucesso!")
 	return nil
@@ -245,24 +295,26 @@ func BackupAppFromContainer(id, username string) error {
 
 // 🗑️ Remove aplicação e container
 func DeleteApp(id, username string) error {
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		return fmt.Errorf("aplicação não encontrada ou não pertence ao usuário")
 	}
 
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+
 	// Inspeciona o container antes de removê-lo para obter o ID da imagem
-	imageIDBytes, err := exec.Command("docker", "inspect", "--format={{.Image}}", app.ContainerName).Output()
 	imageID := ""
-	if err == nil {
-		imageID = strings.TrimSpace(string(imageIDBytes))
+	if info, err := rt.Inspect(ctx, app.ContainerName); err == nil {
+		imageID = info.Image
 	} else {
 		log.Println("⚠️ Não foi possível inspecionar a imagem do container:", err)
 	}
 
 	// Remove container
 	log.Println("🧹 Removendo aplicação:", app.ContainerName)
-	err = exec.Command("docker", "rm", "-f", app.ContainerName).Run()
-	if err != nil {
+	if err := rt.Remove(ctx, app.ContainerName, true); err != nil && !engine.IsNotFound(err) {
 		log.Println("⚠️ Erro ao remover container:", err)
 		return fmt.Errorf("erro ao remover container: %w", err)
 	}
@@ -270,7 +322,7 @@ func DeleteApp(id, username string) error {
 	// Remove imagem associada ao container (agora que o container foi removido)
 	if imageID != "" {
 		log.Println("🧹 Removendo imagem associada:", imageID)
-		_ = exec.Command("docker", "rmi", "-f", imageID).Run()
+		_ = rt.ImageRemove(ctx, imageID)
 	}
 
 	// Remove arquivos
@@ -280,7 +332,9 @@ func DeleteApp(id, username string) error {
 	//}
 
 	// Remove do AppStore
-	delete(store.AppStore, id)
+	if err := store.AppStore.Delete(id); err != nil {
+		log.Println("❌ Erro ao remover do AppStore:", err)
+	}
 	Log(app.ID, username, app.Plan, "🗑️ Aplicação removida com sucesso!")
 
 	// Remove pasta de logs da aplicação
@@ -398,20 +452,27 @@ func compressFolder(sourceDir, zipPath string) error {
 	return nil
 }
 
+// 💽 Compacta os volumes ao lado do snapshot (<id>-volumes.zip), preservando os dados que o código não carrega
+func backupAppVolumes(app *models.App) error {
+	volumesPath := filepath.Join("storage", "users", app.Username, string(app.Plan), "snapshots", app.ID+"-volumes.zip")
+	archived, err := volumes.Archive(app.ID, volumesPath)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar backup dos volumes: %w", err)
+	}
+	if archived {
+		app.Logs = append(app.Logs, "💽 Volumes incluídos no backup em "+volumesPath)
+	}
+	return nil
+}
+
 // 📋 Lista todas as aplicações de um usuário
 func ListAppsByUsername(username string) []*models.App {
-	var apps []*models.App
-	for _, app := range store.AppStore {
-		if strings.EqualFold(app.Username, username) {
-			apps = append(apps, app)
-		}
-	}
-	return apps
+	return store.AppStore.ListByUser(username)
 }
 
 // 📦 Gera backup da aplicação
 func BackupApp(id, username string) error {
-	app := store.AppStore[id]
+	app, _ := store.AppStore.Get(id)
 	if app == nil || app.Username != username {
 		return fmt.Errorf("aplicação não encontrada ou não pertence ao usuário")
 	}
@@ -427,6 +488,9 @@ func BackupApp(id, username string) error {
 	}
 
 	app.Logs = append(app.Logs, "Backup gerado em "+backupPath)
+	if err := backupAppVolumes(app); err != nil {
+		return err
+	}
 	store.SaveApp(app) // ✅ persistência
 	return nil
 }
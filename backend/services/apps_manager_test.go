@@ -0,0 +1,161 @@
+//backend/services/apps_manager_test.go
+
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🚀 Deploy de uma aplicação Node mínima para os testes de ciclo de vida
+func deployTestApp(t *testing.T, username string, plan models.PlanType, appID string) (*models.App, string) {
+	t.Helper()
+	newTestUser(t, username, plan)
+	zipPath := writeTestZip(t, map[string]string{"index.js": "console.log('oi')\n"})
+	app, err := HandleDeploy(zipPath, username, string(plan), appID)
+	if err != nil {
+		t.Fatalf("HandleDeploy: %v", err)
+	}
+	return app, zipPath
+}
+
+func TestStopAndStartApp(t *testing.T) {
+	app, _ := deployTestApp(t, "lifecycle", models.PlanPro, "life1")
+
+	if err := StopApp(app.ID, "lifecycle"); err != nil {
+		t.Fatalf("StopApp: %v", err)
+	}
+	info := inspect(t, app.ContainerName)
+	if info.Running {
+		t.Fatalf("container continua rodando após StopApp")
+	}
+	stored, _ := store.AppStore.Get(app.ID)
+	if stored.Status != models.StatusStopped || stored.DesiredStatus != models.StatusStopped {
+		t.Fatalf("status=%s desejado=%s, esperado stopped", stored.Status, stored.DesiredStatus)
+	}
+
+	if err := StartApp(app.ID, "lifecycle"); err != nil {
+		t.Fatalf("StartApp: %v", err)
+	}
+	if !inspect(t, app.ContainerName).Running {
+		t.Fatalf("container parado após StartApp")
+	}
+	stored, _ = store.AppStore.Get(app.ID)
+	if stored.Status != models.StatusRunning || stored.DesiredStatus != models.StatusRunning {
+		t.Fatalf("status=%s desejado=%s, esperado running", stored.Status, stored.DesiredStatus)
+	}
+
+	if err := StartApp(app.ID, "lifecycle"); err == nil {
+		t.Fatalf("StartApp em aplicação rodando deveria falhar")
+	}
+}
+
+func TestRestartApp(t *testing.T) {
+	app, _ := deployTestApp(t, "restarter", models.PlanPro, "rs1")
+	before := inspect(t, app.ContainerName)
+
+	if err := RestartApp(app.ID, "restarter"); err != nil {
+		t.Fatalf("RestartApp: %v", err)
+	}
+	after := inspect(t, app.ContainerName)
+	if !after.Running || after.ID != before.ID {
+		t.Fatalf("restart deveria manter o mesmo container rodando")
+	}
+	if !after.StartedAt.After(before.StartedAt) {
+		t.Fatalf("container não foi reiniciado: %v → %v", before.StartedAt, after.StartedAt)
+	}
+}
+
+func TestStartAppRejectsOtherUser(t *testing.T) {
+	app, _ := deployTestApp(t, "owner", models.PlanPro, "own1")
+	newTestUser(t, "intruder", models.PlanPro)
+
+	if err := StopApp(app.ID, "intruder"); err == nil {
+		t.Fatalf("StopApp de outro usuário deveria falhar")
+	}
+	if err := StartApp(app.ID, "intruder"); err == nil {
+		t.Fatalf("StartApp de outro usuário deveria falhar")
+	}
+	if !inspect(t, app.ContainerName).Running {
+		t.Fatalf("container alterado por outro usuário")
+	}
+}
+
+func TestRebuildAppFromSnapshot(t *testing.T) {
+	// 📐 Plano de teste: uma aplicação ocupa o plano inteiro, a reconstrução não pode contar como deploy novo
+	app, zipPath := deployTestApp(t, "rebuilder", models.PlanTest, "rb1")
+	// ✏️ Nome, fatia redimensionada e probes precisam sobreviver à reconstrução
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		a.Name = "Minha API"
+		a.AllocatedCPUs = 0.3
+		a.HealthCheck = &models.HealthCheck{Readiness: &models.Probe{Type: models.ProbeTCP, Port: 3000}}
+	}); err != nil {
+		t.Fatalf("erro ao atualizar aplicação: %v", err)
+	}
+	before := inspect(t, app.ContainerName)
+	beforeApp, _ := store.AppStore.Get(app.ID)
+
+	snapshotPath := filepath.Join("storage", "users", "rebuilder", "test", "snapshots", app.ID+".zip")
+	if err := os.MkdirAll(filepath.Dir(snapshotPath), os.ModePerm); err != nil {
+		t.Fatalf("erro ao criar pasta de snapshots: %v", err)
+	}
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatalf("erro ao ler zip: %v", err)
+	}
+	if err := os.WriteFile(snapshotPath, data, 0644); err != nil {
+		t.Fatalf("erro ao gravar snapshot: %v", err)
+	}
+
+	if err := RebuildApp(app.ID, "rebuilder"); err != nil {
+		t.Fatalf("RebuildApp: %v", err)
+	}
+
+	after := inspect(t, app.ContainerName)
+	if after.ID == before.ID {
+		t.Fatalf("container não foi recriado")
+	}
+	if !after.Running {
+		t.Fatalf("container reconstruído não está rodando")
+	}
+	rebuilt, ok := store.AppStore.Get(app.ID)
+	if !ok {
+		t.Fatalf("aplicação sumiu do AppStore")
+	}
+	if rebuilt.Name != "Minha API" {
+		t.Fatalf("nome perdido na reconstrução: %q", rebuilt.Name)
+	}
+	if rebuilt.AllocatedRAMMB != beforeApp.AllocatedRAMMB || rebuilt.AllocatedCPUs != beforeApp.AllocatedCPUs {
+		t.Fatalf("alocação mudou: %dMB/%.2f → %dMB/%.2f", beforeApp.AllocatedRAMMB, beforeApp.AllocatedCPUs, rebuilt.AllocatedRAMMB, rebuilt.AllocatedCPUs)
+	}
+	if rebuilt.ImageID != after.Image {
+		t.Fatalf("imagem registrada %q, container em %q", rebuilt.ImageID, after.Image)
+	}
+	if rebuilt.HealthCheck == nil || rebuilt.HealthCheck.Readiness == nil || rebuilt.HealthCheck.Readiness.Port != 3000 {
+		t.Fatalf("probes perdidos na reconstrução: %+v", rebuilt.HealthCheck)
+	}
+	if rebuilt.Status != models.StatusRunning {
+		t.Fatalf("status=%s, esperado running", rebuilt.Status)
+	}
+	if n := len(store.AppStore.ListByUser("rebuilder")); n != 1 {
+		t.Fatalf("%d aplicações após reconstruir, esperado 1", n)
+	}
+	if deployments := store.DeploymentStore.ListByApp(app.ID); len(deployments) != 2 || deployments[0].Kind != "rebuild" {
+		t.Fatalf("reconstrução não registrou um deploy próprio: %d deploys", len(deployments))
+	}
+}
+
+func TestRebuildAppWithoutSnapshotKeepsContainer(t *testing.T) {
+	app, _ := deployTestApp(t, "nosnap", models.PlanPro, "ns1")
+
+	if err := RebuildApp(app.ID, "nosnap"); err == nil {
+		t.Fatalf("RebuildApp sem snapshot deveria falhar")
+	}
+	if !inspect(t, app.ContainerName).Running {
+		t.Fatalf("container removido apesar da falha")
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"strings"
 	"time"
 
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/store"
 )
@@ -34,6 +36,9 @@ type PlanMigrationLog struct {
 	ErrorMsg  string
 }
 
+// 🛡️ Ação de auditoria das migrações de pasta entre planos
+const auditActionPlanMigrate = "user.plan.migrate"
+
 type Session struct {
 	Email     string `json:"email"`
 	Code      string `json:"code"`
@@ -42,9 +47,8 @@ type Session struct {
 
 var (
 	//nextID        = 1
-	tokenMap      = map[string]TokenData{}
-	tokenTTL      = 120 * time.Second
-	migrationLogs []PlanMigrationLog
+	tokenMap = map[string]TokenData{}
+	tokenTTL = 120 * time.Second
 	//tokenTTL      = 5 * time.Minute
 )
 
@@ -73,14 +77,12 @@ func createUserBaseDirs(username string, plan models.PlanType) error {
 
 func CreateOrGetUser(email, username string) (*models.User, error) {
 	// 🔍 Verifica se já existe usuário com mesmo email ou username
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			store.SyncUserToStore(u.Username, u.Email, u.Plan, "") // 🔁 ID removido
-			return u, nil
-		}
-		if u.Username == username {
-			return nil, fmt.Errorf("nome de usuário já está em uso")
-		}
+	if u, ok := store.UserStore.FindByEmail(email); ok {
+		store.SyncUserToStore(u.Username, u.Email, u.Plan, "") // 🔁 ID removido
+		return u, nil
+	}
+	if store.UserStore.Exists(username) {
+		return nil, fmt.Errorf("nome de usuário já está em uso")
 	}
 
 	// 🆕 Cria novo usuário
@@ -91,8 +93,9 @@ func CreateOrGetUser(email, username string) (*models.User, error) {
 		Role:      "user",
 		Plan:      models.PlanNothing,
 	}
-	store.UserStore[user.Username] = user // 🔁 chave por username
-	_ = SaveUsersToFile()
+	if err := store.UserStore.Save(user); err != nil { // 🔁 chave por username
+		return nil, fmt.Errorf("erro ao salvar usuário: %w", err)
+	}
 	store.SyncUserToStore(user.Username, user.Email, user.Plan, "") // 🔁 ID removido
 	_ = createUserBaseDirs(username, user.Plan)
 
@@ -135,39 +138,53 @@ func AuthenticateUserWithToken(email, token, username string) (*models.User, err
 }
 
 func UpdateUserNameByEmail(email, newName string) (*models.User, error) {
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			u.Name = newName
-			return u, nil
-		}
+	u, err := store.UserStore.UpdateByEmail(email, func(u *models.User) {
+		u.Name = newName
+	})
+	if err != nil {
+		return nil, errors.New("usuário não encontrado")
 	}
-	return nil, errors.New("usuário não encontrado")
+	return u, nil
 }
 
 func SetUserAccessAndPlan(email, role string) error {
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			u.Role = role
-			u.Plan = GetDefaultPlanForRole(role)
-			store.SyncUserToStore(u.Username, u.Email, u.Plan, "") // 🔁 ID removido
-			return nil
-		}
+	_, err := store.UserStore.UpdateByEmail(email, func(u *models.User) {
+		u.Role = role
+		u.Plan = GetDefaultPlanForRole(role)
+	})
+	if err != nil {
+		return errors.New("usuário não encontrado")
 	}
-	return errors.New("usuário não encontrado")
+	return nil
 }
 
 func UpgradeUserPlan(email string, newPlan models.PlanType) error {
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			u.Plan = newPlan
-			if err := createUserBaseDirs(u.Username, newPlan); err != nil {
-				return fmt.Errorf("erro ao criar diretórios para o novo plano: %w", err)
-			}
-			store.SyncUserToStore(u.Username, u.Email, newPlan, "") // 🔁 ID removido
-			return nil
-		}
+	event := audit.Event{
+		Actor:   audit.SystemActor,
+		Action:  "user.plan.upgrade",
+		Target:  email,
+		Details: map[string]string{"to": string(newPlan)},
 	}
-	return errors.New("usuário não encontrado")
+
+	u, ok := store.UserStore.FindByEmail(email)
+	if !ok {
+		event.Outcome = audit.OutcomeFailure
+		event.Error = "usuário não encontrado"
+		audit.Record(event)
+		return errors.New("usuário não encontrado")
+	}
+	event.Target = u.Username
+	event.Details["from"] = string(u.Plan)
+
+	if err := createUserBaseDirs(u.Username, newPlan); err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Error = err.Error()
+		audit.Record(event)
+		return fmt.Errorf("erro ao criar diretórios para o novo plano: %w", err)
+	}
+	store.SyncUserToStore(u.Username, u.Email, newPlan, "") // 🔁 ID removido
+	audit.Record(event)
+	return nil
 }
 
 // 📦 Copia conteúdo de uma pasta para outra (recursivamente)
@@ -205,62 +222,81 @@ func copyDir(src string, dst string) error {
 
 // 🔄 Migra dados do plano antigo para o novo, preservando estrutura
 func RenameUserPlanFolder(email string, newPlan models.PlanType) error {
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			oldPlan := u.Plan
-			u.Plan = newPlan
-
-			base := fmt.Sprintf("storage/users/%s", u.Username)
-			oldPath := fmt.Sprintf("%s/%s", base, oldPlan)
-			newPath := fmt.Sprintf("%s/%s", base, newPlan)
-
-			logEntry := PlanMigrationLog{
-				Username:  u.Username,
-				Email:     u.Email,
-				From:      oldPlan,
-				To:        newPlan,
-				Timestamp: time.Now(),
-			}
-
-			if _, err := os.Stat(oldPath); os.IsNotExist(err) {
-				logEntry.Success = false
-				logEntry.ErrorMsg = "Pasta antiga não existe"
-				migrationLogs = append(migrationLogs, logEntry)
-				return fmt.Errorf("pasta antiga '%s' não encontrada", oldPath)
-			}
-
-			if _, err := os.Stat(newPath); err == nil {
-				logEntry.Success = false
-				logEntry.ErrorMsg = "Pasta destino já existe"
-				migrationLogs = append(migrationLogs, logEntry)
-				return fmt.Errorf("pasta destino '%s' já existe", newPath)
-			}
-
-			if err := copyDir(oldPath, newPath); err != nil {
-				logEntry.Success = false
-				logEntry.ErrorMsg = err.Error()
-				migrationLogs = append(migrationLogs, logEntry)
-				return fmt.Errorf("erro ao copiar dados: %w", err)
-			}
-
-			entries, _ := os.ReadDir(oldPath)
-			for _, entry := range entries {
-				_ = os.RemoveAll(filepath.Join(oldPath, entry.Name()))
-			}
-
-			store.SyncUserToStore(u.Username, u.Email, newPlan, "") // 🔁 ID removido
-			_ = NormalizeAppPrefixes(u.Username, newPlan)
-
-			logEntry.Success = true
-			migrationLogs = append(migrationLogs, logEntry)
-			return nil
-		}
+	u, ok := store.UserStore.FindByEmail(email)
+	if !ok {
+		return fmt.Errorf("usuário com email '%s' não encontrado", email)
+	}
+	oldPlan := u.Plan
+
+	base := fmt.Sprintf("storage/users/%s", u.Username)
+	oldPath := fmt.Sprintf("%s/%s", base, oldPlan)
+	newPath := fmt.Sprintf("%s/%s", base, newPlan)
+
+	logEntry := PlanMigrationLog{
+		Username:  u.Username,
+		Email:     u.Email,
+		From:      oldPlan,
+		To:        newPlan,
+		Timestamp: time.Now(),
+	}
+
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		logEntry.Success = false
+		logEntry.ErrorMsg = "Pasta antiga não existe"
+		recordPlanMigration(logEntry)
+		return fmt.Errorf("pasta antiga '%s' não encontrada", oldPath)
+	}
+
+	if _, err := os.Stat(newPath); err == nil {
+		logEntry.Success = false
+		logEntry.ErrorMsg = "Pasta destino já existe"
+		recordPlanMigration(logEntry)
+		return fmt.Errorf("pasta destino '%s' já existe", newPath)
+	}
+
+	if err := copyDir(oldPath, newPath); err != nil {
+		logEntry.Success = false
+		logEntry.ErrorMsg = err.Error()
+		recordPlanMigration(logEntry)
+		return fmt.Errorf("erro ao copiar dados: %w", err)
 	}
-	return fmt.Errorf("usuário com email '%s' não encontrado", email)
+
+	entries, _ := os.ReadDir(oldPath)
+	for _, entry := range entries {
+		_ = os.RemoveAll(filepath.Join(oldPath, entry.Name()))
+	}
+
+	store.SyncUserToStore(u.Username, u.Email, newPlan, "") // 🔁 ID removido
+	_ = NormalizeAppPrefixes(u.Username, newPlan)
+
+	logEntry.Success = true
+	recordPlanMigration(logEntry)
+	return nil
+}
+
+// 🛡️ Grava a migração de plano no log de auditoria
+func recordPlanMigration(entry PlanMigrationLog) {
+	event := audit.Event{
+		Time:    entry.Timestamp.UTC(),
+		Actor:   audit.SystemActor,
+		Action:  auditActionPlanMigrate,
+		Target:  entry.Username,
+		Outcome: audit.OutcomeSuccess,
+		Details: map[string]string{
+			"email": entry.Email,
+			"from":  string(entry.From),
+			"to":    string(entry.To),
+		},
+	}
+	if !entry.Success {
+		event.Outcome = audit.OutcomeFailure
+		event.Error = entry.ErrorMsg
+	}
+	audit.Record(event)
 }
 
 func PrintMigrationLogs() {
-	for _, log := range migrationLogs {
+	for _, log := range GetMigrationLogs() {
 		status := "\033[32m✔\033[0m"
 		if !log.Success {
 			status = "\033[31m✘\033[0m"
@@ -276,8 +312,28 @@ func PrintMigrationLogs() {
 	}
 }
 
+// 📜 Histórico de migrações de plano, lido do log de auditoria (mais antigas primeiro)
 func GetMigrationLogs() []PlanMigrationLog {
-	return migrationLogs
+	events, err := audit.Query(audit.Filter{Action: auditActionPlanMigrate, Limit: audit.MaxLimit})
+	if err != nil {
+		log.Println("⚠️ Erro ao consultar migrações de plano:", err)
+		return nil
+	}
+
+	logs := make([]PlanMigrationLog, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		logs = append(logs, PlanMigrationLog{
+			Username:  e.Target,
+			Email:     e.Details["email"],
+			From:      models.PlanType(e.Details["from"]),
+			To:        models.PlanType(e.Details["to"]),
+			Timestamp: e.Time,
+			Success:   e.Outcome == audit.OutcomeSuccess,
+			ErrorMsg:  e.Error,
+		})
+	}
+	return logs
 }
 
 func GenerateTokenCode() string {
@@ -296,36 +352,12 @@ func GenerateTokenCode() string {
 }
 
 func SaveUsersToFile() error {
-	file, err := os.Create("./database/users.json")
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	data := make(map[string]*models.User)
-	for _, u := range store.UserStore {
-		data[u.Username] = u
-	}
-
-	return json.NewEncoder(file).Encode(data)
+	return store.UserStore.Flush()
 }
 
 func LoadUsersFromFile() {
-	file, err := os.Open("./database/users.json")
-	if err != nil {
-		fmt.Println("⚠️ Nenhum arquivo de usuários encontrado")
-		return
-	}
-	defer file.Close()
-
-	var data map[string]*models.User
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
+	if err := store.LoadUsersFromFile(middleware.ClientsFilePath); err != nil {
 		fmt.Println("⚠️ Erro ao carregar usuários:", err)
-		return
-	}
-
-	for username, u := range data {
-		store.UserStore[username] = u // 🔁 chave por username
 	}
 }
 
@@ -21,15 +21,13 @@ type UserMetrics struct {
 func GetAllUserMetrics() []UserMetrics {
 	var metrics []UserMetrics
 
-	for _, user := range store.UserStore {
+	for _, user := range store.UserStore.List() {
 		var ramTotal float32
 		var containers int
 
-		for _, app := range store.AppStore {
-			if app.Username == user.Username { // ✅ Comparação por username
-				ramTotal += app.RAMUsage
-				containers++
-			}
+		for _, app := range store.AppStore.ListByUser(user.Username) { // ✅ Comparação por username
+			ramTotal += app.RAMUsage
+			containers++
 		}
 
 		status := "✅ Dentro do limite"
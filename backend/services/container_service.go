@@ -9,11 +9,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os/exec"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/store"
 	"virtuscloud/backend/utils"
@@ -26,20 +28,14 @@ type ContainerResult struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
-// 🧠 Helper para executar comandos Docker
-func RunDocker(args ...string) ([]byte, error) {
-	cmd := exec.Command("docker", args...)
-	return cmd.CombinedOutput()
-}
-
 // 🔍 Verifica se o container já existe
 func ContainerExists(ctx context.Context, name string) (bool, error) {
-	out, err := RunDocker("ps", "-a", "--filter", fmt.Sprintf("name=%s", name), "--format", "{{.Names}}")
+	exists, err := engine.Exists(ctx, engine.Default(), name)
 	if err != nil {
-		log.Printf("Erro ao verificar container '%s': %v\n%s", name, err, string(out))
+		log.Printf("Erro ao verificar container '%s': %v", name, err)
 		return false, err
 	}
-	return strings.Contains(string(out), name), nil
+	return exists, nil
 }
 
 // 🧼 Remove o container se já existir
@@ -52,9 +48,10 @@ func EnsureCleanContainer(name string) error {
 
 	// ❌ Remove se existir
 	if exists {
-		out, err := RunDocker("rm", "-f", name)
-		if err != nil {
-			return fmt.Errorf("erro ao remover container existente: %s", string(out))
+		ctx, cancel := engine.Timeout()
+		defer cancel()
+		if err := engine.Default().Remove(ctx, name, true); err != nil && !engine.IsNotFound(err) {
+			return fmt.Errorf("erro ao remover container existente: %w", err)
 		}
 	}
 
@@ -74,36 +71,46 @@ func CreateContainer(appID, image string, volumePath string, workdir string, com
 		return nil, err
 	}
 
-	// 🔍 Recupera plano do usuário
-	user := store.UserStore[username]
-	if user == nil {
-		return nil, fmt.Errorf("usuário não encontrado")
-	}
-	plan := models.Plans[user.Plan]
-
-	args := []string{
-		"run", "-d",
-		"--restart=no", // 🛡️ reinício automático
-		"--name", containerName,
-		"--label", "username=" + username,
-		"--memory", fmt.Sprintf("%dM", plan.MemoryMB),
-		"--memory-swap", fmt.Sprintf("%dM", plan.MemoryMB),
+	// 🔍 Recupera a fatia alocada à aplicação (e os demais limites do plano)
+	resources, err := limits.AppContainerResources(username, appID)
+	if err != nil {
+		return nil, err
 	}
 
-	if volumePath != "" {
-		args = append(args, "-v", fmt.Sprintf("%s:/app", volumePath))
+	// 🕸️ Aplicações do usuário compartilham a rede dele (alcançada pelo ingress)
+	network, err := EnsureUserNetwork(username)
+	if err != nil {
+		return nil, err
 	}
 
-	if workdir != "" {
-		args = append(args, "-w", workdir)
+	spec := engine.Spec{
+		Name:          containerName,
+		Image:         imageName, // usa imagem personalizada
+		Cmd:           command,
+		WorkingDir:    workdir,
+		Labels:        map[string]string{"username": username},
+		Resources:     resources,
+		RestartPolicy: "no", // 🛡️ reinício controlado pelo backend
+		NetworkMode:   network,
+	}
+	if app, ok := store.AppStore.Get(appID); ok && app.Port > 0 {
+		spec.Env = append(spec.Env, fmt.Sprintf("PORT=%d", app.Port))
+	}
+	if err := ApplyAppSpec(&spec, appID); err != nil {
+		return nil, err
+	}
+	if volumePath != "" {
+		spec.Binds = []string{fmt.Sprintf("%s:/app", volumePath)}
 	}
 
-	args = append(args, imageName) // usa imagem personalizada
-	args = append(args, command...)
+	ctx, cancel := engine.Timeout()
+	defer cancel()
 
-	out, err := RunDocker(args...)
-	if err != nil {
-		return nil, fmt.Errorf("falha no docker run: %s", string(out))
+	if _, err := engine.Default().Create(ctx, spec); err != nil {
+		return nil, fmt.Errorf("falha ao criar container: %w", err)
+	}
+	if err := engine.Default().Start(ctx, containerName); err != nil {
+		return nil, fmt.Errorf("falha ao iniciar container: %w", err)
 	}
 
 	return &ContainerResult{
@@ -113,6 +120,9 @@ func CreateContainer(appID, image string, volumePath string, workdir string, com
 	}, nil
 }
 
+// 🌐 Rota interna que cria o container (os testes apontam para um servidor próprio)
+var containerCreateURL = "http://localhost:8080/api/containers/dev-create"
+
 // 📡 Criação de container via API interna (modo seguro)
 func CallContainerCreation(payload map[string]string, token string) error {
 	containerName := utils.GetContainerName(payload["username"], payload["name"])
@@ -125,15 +135,14 @@ func CallContainerCreation(payload map[string]string, token string) error {
 	payload["label_name"] = containerName
 	payload["label_user"] = payload["username"]
 
-	// 🔍 Recupera plano do usuário e adiciona limite de memória
-	user := store.UserStore[payload["username"]]
-	if user != nil {
-		plan := models.Plans[user.Plan]
-		payload["memory"] = fmt.Sprintf("%dM", plan.MemoryMB)
+	// 📐 Limite de memória e CPU da fatia alocada à aplicação (não o total do plano)
+	if resources, err := limits.AppContainerResources(payload["username"], payload["name"]); err == nil {
+		payload["memory"] = fmt.Sprintf("%dM", resources.MemoryMB)
+		payload["cpus"] = strconv.FormatFloat(resources.CPUs, 'f', 2, 64)
 	}
 
 	body, _ := json.Marshal(payload)
-	req, err := http.NewRequest("POST", "http://localhost:8080/api/containers/dev-create", bytes.NewBuffer(body))
+	req, err := http.NewRequest("POST", containerCreateURL, bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição: %w", err)
 	}
@@ -181,9 +190,11 @@ func CreateContainerHybrid(appID, image string, useAPI bool, token string, usern
 // 🧼 Remoção de container via CLI local (manual)
 func DeleteContainer(appID, username string) (*ContainerResult, error) {
 	containerName := utils.GetContainerName(username, appID)
-	out, err := RunDocker("rm", "-f", containerName)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao executar docker rm: %s", string(out))
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	if err := engine.Default().Remove(ctx, containerName, true); err != nil {
+		return nil, fmt.Errorf("erro ao remover container: %w", err)
 	}
 	return &ContainerResult{
 		ID:        containerName,
@@ -194,195 +205,156 @@ func DeleteContainer(appID, username string) (*ContainerResult, error) {
 
 // 📄 Logs do container
 func GetContainerLogs(name string) (string, error) {
-	out, err := RunDocker("logs", name)
-	if err != nil {
-		return "", fmt.Errorf("erro ao obter logs: %s", string(out))
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	var logs bytes.Buffer
+	opts := engine.LogsOptions{Stdout: true, Stderr: true}
+	if err := engine.Default().Logs(ctx, name, opts, &logs, &logs); err != nil {
+		return "", fmt.Errorf("erro ao obter logs: %w", err)
+	}
+	if app, ok := store.AppStore.FindByContainer(name); ok {
+		return MaskSecrets(app.ID, logs.String()), nil
 	}
-	return string(out), nil
+	return logs.String(), nil
 }
 
 // 🧠 Verifica se imagem existe localmente
 func ImageExists(image string) bool {
-	out, err := RunDocker("images", "--format", "{{.Repository}}:{{.Tag}}")
-	if err != nil {
-		return false
-	}
-	return strings.Contains(string(out), image)
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	exists, err := engine.Default().ImageExists(ctx, image)
+	return err == nil && exists
 }
 
 // ▶️ Lista containers em execução
 func ListRunningContainers() ([]string, error) {
-	out, err := RunDocker("ps", "--format", "{{.Names}}")
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	containers, err := engine.Default().List(ctx, engine.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
-	return strings.Split(strings.TrimSpace(string(out)), "\n"), nil
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	return names, nil
 }
 
 // 📋 Lista containers de um usuário via prefixo
 func ListUserContainers(username string) ([]string, error) {
-	pattern := fmt.Sprintf("^%s-", username)
-	cmd := exec.Command("sh", "-c", fmt.Sprintf(`docker ps -a --format "{{.Names}}" | grep "%s"`, pattern))
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("erro ao listar containers: %v", err)
-	}
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	return lines, nil
-}
+	ctx, cancel := engine.Timeout()
+	defer cancel()
 
-// ⚡ Lista todos os containers com status, username, start time e métricas (RAM/CPU)
-func ListAllContainersWithStatusFast() ([]*models.App, error) {
-	// 📋 Lista containers com nome e status
-	psOut, err := RunDocker("ps", "-a", "--filter", "label=username", "--format", "{{.Names}}|{{.Status}}")
+	containers, err := engine.Default().List(ctx, engine.ListOptions{All: true})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("erro ao listar containers: %w", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(psOut)), "\n")
-	if len(lines) == 0 {
-		return []*models.App{}, nil
-	}
-
-	var containerNames []string
-	statusMap := map[string]string{}
-
-	for _, line := range lines {
-		parts := strings.Split(line, "|")
-		if len(parts) != 2 {
-			continue
+	prefix := username + "-"
+	names := []string{}
+	for _, c := range containers {
+		if name := c.Name; strings.HasPrefix(name, prefix) {
+			names = append(names, name)
 		}
-		name := parts[0]
-		status := strings.ToLower(parts[1])
-		containerNames = append(containerNames, name)
-		statusMap[name] = status
 	}
+	return names, nil
+}
 
-	// 🔍 Inspeciona containers em lote para pegar username e start time
-	inspectArgs := append([]string{"inspect", "--format", "{{.Name}}|{{index .Config.Labels \"username\"}}|{{.State.StartedAt}}"}, containerNames...)
-	inspectOut, err := RunDocker(inspectArgs...)
+// ⚡ Lista todos os containers com status, username, start time e métricas (RAM/CPU)
+func ListAllContainersWithStatusFast() ([]*models.App, error) {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+
+	// 📋 Lista containers com label de usuário (nome, estado e labels em uma chamada)
+	containers, err := rt.List(ctx, engine.ListOptions{
+		All:    true,
+		Labels: map[string]string{"username": ""},
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	var apps []*models.App
-	inspectLines := strings.Split(strings.TrimSpace(string(inspectOut)), "\n")
-
-	for _, line := range inspectLines {
-		parts := strings.Split(line, "|")
-		if len(parts) != 3 {
-			continue
-		}
-
-		name := strings.TrimPrefix(parts[0], "/")
-		username := parts[1]
-		startTimeRaw := parts[2]
-
-		if username == "" {
+	for _, c := range containers {
+		name := c.Name
+		if c.Labels["username"] == "" {
 			continue
 		}
 
-		parsedTime, err := time.Parse(time.RFC3339Nano, startTimeRaw)
-		if err != nil {
-			parsedTime = time.Time{}
-		}
-
-		rawStatus := statusMap[name]
 		var appStatus models.AppStatus
-		switch {
-		case strings.Contains(rawStatus, "up"):
+		switch c.State {
+		case "running", "paused":
 			appStatus = models.StatusRunning
-		case strings.Contains(rawStatus, "exited"):
+		case "exited":
 			appStatus = models.StatusStopped
 		default:
 			appStatus = models.StatusBackups
 		}
 
 		// ✅ Busca app real pelo ContainerName
-		var matchedApp *models.App
-		for _, app := range store.AppStore {
-			if app.ContainerName == name {
-				matchedApp = app
-				break
-			}
+		matchedApp, _ := store.AppStore.FindByContainer(name)
+		if matchedApp == nil {
+			log.Println("⚠️ Ignorando container não registrado:", name)
+			continue
 		}
 
-		if matchedApp != nil {
-			matchedApp.Status = appStatus
-			matchedApp.StartTime = parsedTime
-			apps = append(apps, matchedApp)
-		} else {
-			log.Println("⚠️ Ignorando container não registrado:", name)
+		// 🕒 StartedAt só vem no inspect
+		var startedAt time.Time
+		if info, err := rt.Inspect(ctx, c.ID); err == nil {
+			startedAt = info.StartedAt
 		}
+
+		matchedApp.Status = appStatus
+		matchedApp.StartTime = startedAt
+		apps = append(apps, matchedApp)
 	}
 
-	// 📊 Coleta métricas de RAM e CPU em lote via docker stats
-	statsOut, err := RunDocker("stats", "--no-stream", "--format", "{{.Name}}|{{.CPUPerc}}|{{.MemUsage}}")
-	if err == nil {
-		statsLines := strings.Split(strings.TrimSpace(string(statsOut)), "\n")
-		for _, line := range statsLines {
-			parts := strings.Split(line, "|")
-			if len(parts) != 3 {
-				continue
+	// 📊 Coleta métricas de RAM e CPU dos containers em execução, em paralelo
+	var wg sync.WaitGroup
+	for _, app := range apps {
+		if app.Status != models.StatusRunning {
+			continue
+		}
+		wg.Add(1)
+		go func(app *models.App) {
+			defer wg.Done()
+
+			stats, err := rt.Stats(ctx, app.ContainerName)
+			if err != nil {
+				log.Printf("⚠️ Erro ao coletar métricas de %s: %v", app.ContainerName, err)
+				return
 			}
-			name := parts[0]
-			cpuStr := strings.TrimSuffix(parts[1], "%")
-			memStr := parts[2] // exemplo: "85.3MiB / 256MiB"
-
-			// 🔧 Normaliza memória usada e limite para MB
-			memFields := strings.Fields(memStr)
-			if len(memFields) >= 3 {
-				usedStr := memFields[0]  // "85.3MiB"
-				limitStr := memFields[2] // "256MiB"
-
-				usedMB := normalizeMemToMB(usedStr)
-				limitMB := normalizeMemToMB(limitStr)
-
-				cpuVal, _ := strconv.ParseFloat(cpuStr, 32)
-
-				// ✅ Atualiza AppStore com métricas normalizadas
-				for _, app := range store.AppStore {
-					if app.ContainerName == name {
-						// CPU
-						app.CPUUsage = float32(cpuVal)
-
-						// pega o plano do usuário dono da app
-						plan := models.Plans[models.PlanType(app.Plan)]
-						cpuLimit := float32(plan.CPUvCores)
-
-						// 🚀 RAM: usa limite do plano (MemoryMB)
-						ramLimit := float32(plan.MemoryMB)
-
-						// regra: se plano tiver menos que 256 MB, não sobe aplicação
-						if ramLimit < 256 {
-							log.Printf("❌ Plano %s possui apenas %.2f MB. Aplicação %s não pode ser iniciada.", plan.Name, ramLimit, app.ContainerName)
-							continue
-						}
-
-						app.CPULimit = cpuLimit
-						app.RAMLimit = ramLimit
-
-						// cálculo relativo ao plano (CPU)
-						if cpuLimit > 0 {
-							hostCPUs := runtime.NumCPU()
-							usedCores := (app.CPUUsage / 100.0) * float32(hostCPUs)
-							app.CPUPercent = (usedCores / cpuLimit) * 100
-						}
-
-						// cálculo relativo ao plano (RAM)
-						app.RAMUsage = float32(usedMB)
-						if ramLimit > 0 {
-							app.RAMPercent = (app.RAMUsage / ramLimit) * 100
-						}
-
-						// alerta de limite (RAM do Docker vs plano)
-						app.Alert = fmt.Sprintf("Limite Docker: %.2f MB | Plano: %.2f MB", limitMB, ramLimit)
-					}
-				}
+			applyContainerStats(app, stats)
+		}(app)
+	}
+	wg.Wait()
+
+	// 💾 Reflete status e métricas no AppStore (status persistido, métricas só em memória)
+	for _, app := range apps {
+		snapshot := app
+		if current, ok := store.AppStore.Get(app.ID); ok &&
+			(current.Status != snapshot.Status || !current.StartTime.Equal(snapshot.StartTime)) {
+			if _, err := store.AppStore.Update(app.ID, func(stored *models.App) {
+				stored.Status = snapshot.Status
+				stored.StartTime = snapshot.StartTime
+			}); err != nil {
+				log.Println("⚠️ Erro ao atualizar AppStore:", err)
 			}
 		}
-	} else {
-		log.Println("⚠️ Erro ao coletar métricas de CPU/RAM:", err)
+		store.AppStore.UpdateVolatile(app.ID, func(stored *models.App) {
+			stored.CPUUsage = snapshot.CPUUsage
+			stored.CPULimit = snapshot.CPULimit
+			stored.CPUPercent = snapshot.CPUPercent
+			stored.RAMUsage = snapshot.RAMUsage
+			stored.RAMLimit = snapshot.RAMLimit
+			stored.RAMPercent = snapshot.RAMPercent
+			stored.Alert = snapshot.Alert
+		})
 	}
 
 	return apps, nil
@@ -836,44 +808,69 @@ func ListAllContainersWithStatusFast() ([]*models.App, error) {
 //	return apps, nil
 //}
 
-// 🔧 Função auxiliar para normalizar valores de memória para MB
-func normalizeMemToMB(valStr string) float64 {
-	if strings.HasSuffix(valStr, "KiB") {
-		num, _ := strconv.ParseFloat(strings.TrimSuffix(valStr, "KiB"), 64)
-		return num / 1024.0
-	} else if strings.HasSuffix(valStr, "MiB") {
-		num, _ := strconv.ParseFloat(strings.TrimSuffix(valStr, "MiB"), 64)
-		return num
-	} else if strings.HasSuffix(valStr, "GiB") {
-		num, _ := strconv.ParseFloat(strings.TrimSuffix(valStr, "GiB"), 64)
-		return num * 1024.0
+// 📊 Aplica a amostra de métricas do container à aplicação (valores relativos ao plano)
+func applyContainerStats(app *models.App, stats *engine.Stats) {
+	usedMB := stats.MemoryUsageMB
+	limitMB := stats.MemoryLimitMB
+
+	// CPU
+	app.CPUUsage = float32(stats.CPUPercent)
+
+	// pega o plano do usuário dono da app
+	plan := models.Plans[models.PlanType(app.Plan)]
+	cpuLimit := float32(plan.CPUvCores)
+
+	// 🚀 RAM: usa limite do plano (MemoryMB)
+	ramLimit := float32(plan.MemoryMB)
+
+	// regra: se plano tiver menos que 256 MB, não sobe aplicação
+	if ramLimit < 256 {
+		log.Printf("❌ Plano %s possui apenas %.2f MB. Aplicação %s não pode ser iniciada.", plan.Name, ramLimit, app.ContainerName)
+		return
 	}
-	num, _ := strconv.ParseFloat(valStr, 64)
-	return num
+
+	app.CPULimit = cpuLimit
+	app.RAMLimit = ramLimit
+
+	// cálculo relativo ao plano (CPU)
+	if cpuLimit > 0 {
+		hostCPUs := runtime.NumCPU()
+		usedCores := (app.CPUUsage / 100.0) * float32(hostCPUs)
+		app.CPUPercent = (usedCores / cpuLimit) * 100
+	}
+
+	// cálculo relativo ao plano (RAM)
+	app.RAMUsage = float32(usedMB)
+	if ramLimit > 0 {
+		app.RAMPercent = (app.RAMUsage / ramLimit) * 100
+	}
+
+	// alerta de limite (RAM do Docker vs plano)
+	app.Alert = fmt.Sprintf("Limite Docker: %.2f MB | Plano: %.2f MB", limitMB, ramLimit)
 }
 
 // 🧠 Extrai label username do container
 func GetContainerUsername(name string) string {
-	cmd := exec.Command("docker", "inspect", "--format", "{{ index .Config.Labels \"username\" }}", name)
-	out, err := cmd.Output()
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	info, err := engine.Default().Inspect(ctx, name)
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(out))
+	return info.Labels["username"]
 }
 
 // 🕒 Extrai StartTime real do container
 func GetContainerStartTime(name string) time.Time {
-	cmd := exec.Command("docker", "inspect", "--format", "{{ .State.StartedAt }}", name)
-	out, err := cmd.Output()
-	if err != nil {
-		return time.Now()
-	}
-	parsed, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(out)))
-	if err != nil {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	info, err := engine.Default().Inspect(ctx, name)
+	if err != nil || info.StartedAt.IsZero() {
 		return time.Now()
 	}
-	return parsed
+	return info.StartedAt
 }
 
 // 🔄 Sincroniza AppStore com containers Docker
@@ -900,26 +897,23 @@ func SyncAppStoreWithDocker() {
 
 	// 🔁 Atualiza AppStore com base nos containers
 	for _, app := range allContainers {
-		var foundID string
-		for id, existing := range store.AppStore {
-			if existing.ContainerName == app.ContainerName {
-				foundID = id
-				break
-			}
-		}
-
-		if foundID != "" {
-			existing := store.AppStore[foundID]
-			existing.Status = app.Status
-			existing.Logs = app.Logs
-			existing.RAMUsage = app.RAMUsage
-			existing.CPUUsage = app.CPUUsage     // ✅ novo
-			existing.CPULimit = app.CPULimit     // ✅ novo
-			existing.CPUPercent = app.CPUPercent // ✅ novo
-			existing.Port = app.Port
-			existing.Alert = app.Alert
+		if found, ok := store.AppStore.FindByContainer(app.ContainerName); ok {
+			current := app
+			_, err = store.AppStore.Update(found.ID, func(existing *models.App) {
+				existing.Status = current.Status
+				existing.Logs = current.Logs
+				existing.RAMUsage = current.RAMUsage
+				existing.CPUUsage = current.CPUUsage     // ✅ novo
+				existing.CPULimit = current.CPULimit     // ✅ novo
+				existing.CPUPercent = current.CPUPercent // ✅ novo
+				existing.Port = current.Port
+				existing.Alert = current.Alert
+			})
 		} else {
-			store.AppStore[app.ID] = app
+			err = store.AppStore.Save(app)
+		}
+		if err != nil {
+			log.Println("❌ Erro ao atualizar AppStore:", err)
 		}
 	}
 
@@ -932,7 +926,8 @@ func SyncAppStoreWithDocker() {
 
 // 🧹 Remove entradas do AppStore cujos containers não existem mais (com grace period)
 func CleanAppStoreFromMissingContainers() {
-	for id, app := range store.AppStore {
+	for _, app := range store.AppStore.List() {
+		id := app.ID
 		exists, err := ContainerExists(context.Background(), app.ContainerName)
 		if err != nil {
 			log.Printf("⚠️ Erro ao verificar container '%s': %v", app.ContainerName, err)
@@ -940,17 +935,24 @@ func CleanAppStoreFromMissingContainers() {
 		}
 
 		if !exists {
-			app.MissingCount++
-			log.Printf("⚠️ Container '%s' não encontrado (tentativa %d)", app.ContainerName, app.MissingCount)
+			updated, err := store.AppStore.Update(id, func(a *models.App) {
+				a.MissingCount++
+			})
+			if err != nil {
+				continue
+			}
+			log.Printf("⚠️ Container '%s' não encontrado (tentativa %d)", app.ContainerName, updated.MissingCount)
 
 			// só remove se ficar ausente por 3 ciclos consecutivos
-			if app.MissingCount >= 3 {
+			if updated.MissingCount >= 3 {
 				log.Printf("🧹 Removendo app '%s' — ausente por 3 ciclos", id)
-				delete(store.AppStore, id)
+				_ = store.AppStore.Delete(id)
 			}
-		} else {
+		} else if app.MissingCount != 0 {
 			// reset se voltou a aparecer
-			app.MissingCount = 0
+			_, _ = store.AppStore.Update(id, func(a *models.App) {
+				a.MissingCount = 0
+			})
 		}
 	}
 
@@ -961,19 +963,21 @@ func CleanAppStoreFromMissingContainers() {
 
 // 📋 Lista containers com status por prefixo de usuário
 func ListContainersWithStatusByPrefix(username string) ([]string, error) {
-	// 🔍 Executa docker ps -a e filtra containers que começam com o prefixo do usuário
-	out, err := RunDocker("ps", "-a", "--format", "{{.Names}}|{{.Status}}")
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	// 🔍 Lista todos os containers e filtra os que começam com o prefixo do usuário
+	containers, err := engine.Default().List(ctx, engine.ListOptions{All: true})
 	if err != nil {
-		return nil, fmt.Errorf("erro ao listar containers: %v", err)
+		return nil, fmt.Errorf("erro ao listar containers: %w", err)
 	}
 
 	var filtered []string
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 	prefix := fmt.Sprintf("%s-", username)
 
-	for _, line := range lines {
-		if strings.HasPrefix(line, prefix) {
-			filtered = append(filtered, line)
+	for _, c := range containers {
+		if name := c.Name; strings.HasPrefix(name, prefix) {
+			filtered = append(filtered, name+"|"+c.Status)
 		}
 	}
 	return filtered, nil
@@ -1003,19 +1007,19 @@ func CreateContainerFromApp(app *models.App, token string) error {
 	imageName := fmt.Sprintf("%s-%s", app.Username, app.ID) // 📦 imagem personalizada
 	containerName := fmt.Sprintf("%s-%s", app.Username, app.ID)
 
-	// 🔍 Recupera plano do usuário
-	user := store.UserStore[app.Username]
-	if user == nil {
-		return fmt.Errorf("usuário não encontrado")
+	// 📐 Fatia alocada à aplicação
+	resources, err := limits.AppContainerResources(app.Username, app.ID)
+	if err != nil {
+		return err
 	}
-	plan := models.Plans[user.Plan]
 
 	// 📦 Payload para criação
 	payload := map[string]string{
 		"name":     containerName,
 		"image":    imageName,
 		"username": app.Username,
-		"memory":   fmt.Sprintf("%dM", plan.MemoryMB),
+		"memory":   fmt.Sprintf("%dM", resources.MemoryMB),
+		"cpus":     strconv.FormatFloat(resources.CPUs, 'f', 2, 64),
 	}
 
 	// 🚀 Chama função que executa criação real
@@ -0,0 +1,458 @@
+//backend/services/databases.go
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/databases"
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// ⏱️ Prazos do provisionamento: baixar a imagem e esperar o motor aceitar conexões
+const (
+	databasePullTimeout  = 10 * time.Minute
+	databaseReadyTimeout = 3 * time.Minute
+	databaseReadyPoll    = 2 * time.Second
+	databaseStopTimeout  = 30 * time.Second
+)
+
+// 🔤 Prefixo das variáveis injetadas (DATABASE → DATABASE_URL...)
+var envPrefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,31}$`)
+
+// 🔒 Um provisionamento por banco de cada vez
+var (
+	provisionMu  sync.Mutex
+	provisioning = map[string]bool{}
+)
+
+// 🧹 Ligações saem com a aplicação; bancos saem com a conta; a rede sai com o último banco
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		for _, db := range store.DatabaseStore.ListByApp(app.ID) {
+			if _, err := store.DatabaseStore.Update(db.ID, func(stored *models.Database) {
+				stored.Links = withoutLink(stored.Links, app.ID)
+			}); err != nil {
+				log.Printf("⚠️ Erro ao desligar banco %s da aplicação %s: %v", db.ID, app.ID, err)
+			}
+		}
+	})
+	store.UserStore.OnDelete(func(user *models.User) {
+		for _, db := range store.DatabaseStore.ListByUser(user.Username) {
+			if db.Username != user.Username {
+				continue
+			}
+			if _, err := DeleteDatabase(user.Username, db.ID); err != nil {
+				log.Printf("⚠️ Erro ao remover banco %s da conta %s: %v", db.ID, user.Username, err)
+			}
+		}
+	})
+	store.DatabaseStore.OnDelete(func(db *models.Database) {
+		ReleaseUserNetwork(db.Username, false)
+	})
+}
+
+// 🔐 Banco do usuário (de outro usuário = não encontrado)
+func OwnedDatabase(username, id string) (*models.Database, error) {
+	db, ok := store.DatabaseStore.Get(id)
+	if !ok || db.Username != username {
+		return nil, databases.ErrNotFound
+	}
+	return db, nil
+}
+
+// ➕ Registra o banco e sobe o container em segundo plano (status "creating" até aceitar conexões)
+func CreateDatabase(username, name, engineName, version string, sizeGB int) (*models.Database, error) {
+	if err := checkDatabaseAlias(username, name); err != nil {
+		return nil, err
+	}
+	db, err := databases.Create(username, name, engineName, version, sizeGB)
+	if err != nil {
+		return nil, err
+	}
+	go provisionDatabase(db.ID, true)
+	return db, nil
+}
+
+// 💾 Backup manual em segundo plano (o registro aparece como "running" até o dump terminar)
+func BackupDatabase(username, id string) (*models.DatabaseBackup, error) {
+	if _, err := OwnedDatabase(username, id); err != nil {
+		return nil, err
+	}
+	backup, err := databases.BeginBackup(id, models.BackupManual)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_ = databases.RunBackup(id, backup.ID)
+	}()
+	return backup, nil
+}
+
+// ♻️ Restaura um backup num banco novo (a origem continua intacta); a restauração é verificada antes do "running"
+func RestoreDatabase(username, id, backupID, name string, sizeGB int) (*models.Database, error) {
+	source, err := OwnedDatabase(username, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDatabaseAlias(username, name); err != nil {
+		return nil, err
+	}
+	db, err := databases.CreateRestored(source, backupID, name, sizeGB)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("♻️ Banco %s sendo restaurado do backup %s de %s", db.ID, backupID, source.ID)
+	go provisionDatabase(db.ID, true)
+	return db, nil
+}
+
+// 🕸️ O nome vira DNS na rede do usuário: não pode roubar o nome de uma aplicação dele
+func checkDatabaseAlias(username, name string) error {
+	alias := strings.ToLower(strings.TrimSpace(name))
+	for _, app := range store.AppStore.ListByUser(username) {
+		if app.Username == username && AppNetworkAlias(app.Name) == alias {
+			return fmt.Errorf("%w: a aplicação %s já responde pelo nome '%s' na rede", databases.ErrExists, app.ID, alias)
+		}
+	}
+	return nil
+}
+
+// ▶️ Liga o banco (recria o container se ele sumiu)
+func StartDatabase(username, id string) (*models.Database, error) {
+	db, err := OwnedDatabase(username, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case db.Status == models.DatabaseCreating:
+		return nil, fmt.Errorf("%w: %s ainda está sendo criado", databases.ErrBusy, db.Name)
+	case db.Status == models.DatabaseOverQuota && db.UsedBytes > int64(db.SizeGB)*1024*1024*1024:
+		return nil, fmt.Errorf("%w: %s usa %s, acima dos %d GB contratados; aumente o tamanho antes de ligar", databases.ErrNotAllowed, db.Name, databases.FormatBytes(db.UsedBytes), db.SizeGB)
+	}
+	db, err = setDatabaseStatus(id, models.DatabaseCreating, "")
+	if err != nil {
+		return nil, err
+	}
+	go provisionDatabase(id, false)
+	return db, nil
+}
+
+// ⏹️ Para o banco (continua parado depois de reiniciar o Docker)
+func StopDatabase(username, id string) (*models.Database, error) {
+	db, err := OwnedDatabase(username, id)
+	if err != nil {
+		return nil, err
+	}
+	if db.Status == models.DatabaseCreating {
+		return nil, fmt.Errorf("%w: %s ainda está sendo criado", databases.ErrBusy, db.Name)
+	}
+	if err := stopDatabaseContainer(db); err != nil {
+		return nil, err
+	}
+	return setDatabaseStatus(id, models.DatabaseStopped, "")
+}
+
+// 🚫 Chamado pelo monitor quando os dados passam do tamanho contratado
+func SuspendDatabase(id string) {
+	db, ok := store.DatabaseStore.Get(id)
+	if !ok {
+		return
+	}
+	if err := stopDatabaseContainer(db); err != nil {
+		log.Printf("⚠️ Erro ao parar banco %s acima do tamanho: %v", id, err)
+	}
+	reason := fmt.Sprintf("dados (%s) acima dos %d GB contratados: aumente o tamanho para ligar de novo", databases.FormatBytes(db.UsedBytes), db.SizeGB)
+	if _, err := setDatabaseStatus(id, models.DatabaseOverQuota, reason); err != nil {
+		log.Printf("⚠️ Erro ao marcar banco %s acima do tamanho: %v", id, err)
+	}
+}
+
+// 🗑️ Desliga das aplicações, remove o container e apaga os dados; devolve as aplicações que perderam variáveis
+func DeleteDatabase(username, id string) ([]string, error) {
+	db, err := OwnedDatabase(username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var unlinked []string
+	for _, link := range db.Links {
+		app, ok := store.AppStore.Get(link.AppID)
+		if !ok {
+			continue
+		}
+		if _, err := DeleteAppEnv(app, link.Keys); err != nil {
+			return nil, fmt.Errorf("erro ao remover variáveis de %s: %w", app.ID, err)
+		}
+		unlinked = append(unlinked, app.ID)
+	}
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	if err := engine.Default().Remove(ctx, db.ContainerName, true); err != nil && !engine.IsNotFound(err) {
+		return nil, fmt.Errorf("erro ao remover container do banco: %w", err)
+	}
+	if err := databases.Remove(id); err != nil {
+		return nil, err
+	}
+	log.Printf("🗑️ Banco %s (%s) de %s removido", db.Name, db.Engine, db.Username)
+	return unlinked, nil
+}
+
+// 🔗 Injeta a conexão no ambiente da aplicação (<PREFIXO>_URL, _HOST...); vale no próximo start, restart ou rebuild
+func LinkDatabase(username, id string, app *models.App, prefix string) (*models.Database, error) {
+	db, err := OwnedDatabase(username, id)
+	if err != nil {
+		return nil, err
+	}
+	if app.Username != username {
+		return nil, fmt.Errorf("%w: aplicação %s", databases.ErrNotFound, app.ID)
+	}
+
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if prefix == "" {
+		if e, ok := databases.LookupEngine(db.Engine); ok {
+			prefix = e.DefaultPrefix
+		}
+	}
+	if !envPrefixPattern.MatchString(prefix) {
+		return nil, fmt.Errorf("%w: prefixo '%s' (use letras maiúsculas, números e _, começando por letra)", databases.ErrInvalid, prefix)
+	}
+	for _, other := range store.DatabaseStore.ListByApp(app.ID) {
+		if other.ID == db.ID {
+			continue
+		}
+		for _, link := range other.Links {
+			if link.AppID == app.ID && link.Prefix == prefix {
+				return nil, fmt.Errorf("%w: a aplicação já recebe o banco %s com o prefixo %s", databases.ErrExists, other.Name, prefix)
+			}
+		}
+	}
+
+	vars, err := databases.Environment(db, prefix)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]EnvInput, 0, len(vars))
+	keys := make([]string, 0, len(vars))
+	for _, v := range vars {
+		inputs = append(inputs, EnvInput{Key: v.Key, Value: v.Value, Secret: v.Secret})
+		keys = append(keys, v.Key)
+	}
+
+	// ♻️ Religar com outro prefixo tira as variáveis antigas
+	for _, link := range db.Links {
+		if link.AppID == app.ID && link.Prefix != prefix {
+			if _, err := DeleteAppEnv(app, link.Keys); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := SetAppEnv(app, inputs); err != nil {
+		return nil, err
+	}
+
+	updated, err := store.DatabaseStore.Update(db.ID, func(stored *models.Database) {
+		stored.Links = append(withoutLink(stored.Links, app.ID), models.DatabaseLink{
+			AppID:    app.ID,
+			Prefix:   prefix,
+			Keys:     keys,
+			LinkedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	Log(app.ID, app.Username, app.Plan, fmt.Sprintf("🔗 Banco %s ligado com as variáveis %s_*", db.Name, prefix))
+	return updated, nil
+}
+
+// ✂️ Tira as variáveis do banco do ambiente da aplicação
+func UnlinkDatabase(username, id string, app *models.App) (*models.Database, error) {
+	db, err := OwnedDatabase(username, id)
+	if err != nil {
+		return nil, err
+	}
+	var current *models.DatabaseLink
+	for i := range db.Links {
+		if db.Links[i].AppID == app.ID {
+			current = &db.Links[i]
+		}
+	}
+	if current == nil || app.Username != username {
+		return nil, fmt.Errorf("%w: o banco %s não está ligado à aplicação %s", databases.ErrNotFound, db.Name, app.ID)
+	}
+	if _, err := DeleteAppEnv(app, current.Keys); err != nil {
+		return nil, err
+	}
+	updated, err := store.DatabaseStore.Update(db.ID, func(stored *models.Database) {
+		stored.Links = withoutLink(stored.Links, app.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	Log(app.ID, app.Username, app.Plan, fmt.Sprintf("✂️ Banco %s desligado (variáveis %s_* removidas)", db.Name, current.Prefix))
+	return updated, nil
+}
+
+// 🔁 Na subida do backend: termina criações interrompidas e garante os containers dos bancos ligados
+func ResumeDatabases() {
+	for _, db := range store.DatabaseStore.List() {
+		switch db.Status {
+		case models.DatabaseCreating:
+			go provisionDatabase(db.ID, false)
+		case models.DatabaseRunning:
+			ctx, cancel := engine.Timeout()
+			exists, err := engine.Exists(ctx, engine.Default(), db.ContainerName)
+			cancel()
+			if err == nil && !exists {
+				log.Printf("♻️ Container do banco %s sumiu: recriando", db.ID)
+				go provisionDatabase(db.ID, false)
+			}
+		}
+	}
+}
+
+// 🚀 Garante o container do banco e espera ele aceitar conexões (fresh = descarta um container antigo)
+func provisionDatabase(id string, fresh bool) {
+	provisionMu.Lock()
+	if provisioning[id] {
+		provisionMu.Unlock()
+		return
+	}
+	provisioning[id] = true
+	provisionMu.Unlock()
+	defer func() {
+		provisionMu.Lock()
+		delete(provisioning, id)
+		provisionMu.Unlock()
+	}()
+
+	db, ok := store.DatabaseStore.Get(id)
+	if !ok {
+		return
+	}
+	if err := ensureDatabaseContainer(db, fresh); err != nil {
+		log.Printf("❌ Banco %s não subiu: %v", db.ID, err)
+		_, _ = setDatabaseStatus(id, models.DatabaseFailed, err.Error())
+		return
+	}
+	if err := waitDatabaseReady(db); err != nil {
+		log.Printf("❌ Banco %s não respondeu: %v", db.ID, err)
+		_, _ = setDatabaseStatus(id, models.DatabaseFailed, err.Error())
+		return
+	}
+	if databases.PendingRestore(db) {
+		if _, err := databases.Restore(db); err != nil {
+			log.Printf("❌ Restauração do banco %s falhou: %v", db.ID, err)
+			_, _ = setDatabaseStatus(id, models.DatabaseFailed, "restauração: "+err.Error())
+			return
+		}
+	}
+	if _, err := setDatabaseStatus(id, models.DatabaseRunning, ""); err == nil {
+		log.Printf("✅ Banco %s (%s %s) de %s pronto", db.Name, db.Engine, db.Version, db.Username)
+	}
+}
+
+// 🧩 Cria (se preciso) e liga o container na rede do usuário
+func ensureDatabaseContainer(db *models.Database, fresh bool) error {
+	network, err := EnsureUserNetwork(db.Username)
+	if err != nil {
+		return err
+	}
+	rt := engine.Default()
+
+	if !ImageExists(db.Image) {
+		log.Printf("📥 Baixando imagem %s para o banco %s", db.Image, db.ID)
+		ctx, cancel := context.WithTimeout(context.Background(), databasePullTimeout)
+		err := rt.Pull(ctx, db.Image)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("erro ao baixar imagem %s: %w", db.Image, err)
+		}
+	}
+
+	if fresh {
+		if err := EnsureCleanContainer(db.ContainerName); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	info, err := rt.Inspect(ctx, db.ContainerName)
+	if engine.IsNotFound(err) {
+		if err := databases.SeedRestore(db); err != nil {
+			return err
+		}
+		spec, err := databases.Spec(db, network)
+		if err != nil {
+			return err
+		}
+		if _, err := rt.Create(ctx, spec); err != nil {
+			return fmt.Errorf("erro ao criar container do banco: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("erro ao inspecionar container do banco: %w", err)
+	} else if info.Running {
+		return nil
+	}
+	if err := rt.Start(ctx, db.ContainerName); err != nil {
+		return fmt.Errorf("erro ao iniciar container do banco: %w", err)
+	}
+	return nil
+}
+
+// 🩺 Tenta o comando de prontidão até o prazo
+func waitDatabaseReady(db *models.Database) error {
+	deadline := time.Now().Add(databaseReadyTimeout)
+	for {
+		ctx, cancel := engine.Timeout()
+		err := databases.Ready(ctx, db)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("sem resposta em %s: %w", databaseReadyTimeout, err)
+		}
+		time.Sleep(databaseReadyPoll)
+	}
+}
+
+// ⏹️ Para o container (não existir conta como parado)
+func stopDatabaseContainer(db *models.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), databaseStopTimeout+engine.DefaultTimeout)
+	defer cancel()
+	if err := engine.Default().Stop(ctx, db.ContainerName, databaseStopTimeout); err != nil && !engine.IsNotFound(err) {
+		return fmt.Errorf("erro ao parar banco %s: %w", db.Name, err)
+	}
+	return nil
+}
+
+// 🚦 Atualiza o estado (e o último erro)
+func setDatabaseStatus(id, status, lastError string) (*models.Database, error) {
+	return store.DatabaseStore.Update(id, func(db *models.Database) {
+		db.Status = status
+		db.LastError = lastError
+	})
+}
+
+// ✂️ Ligações sem a aplicação
+func withoutLink(links []models.DatabaseLink, appID string) []models.DatabaseLink {
+	var kept []models.DatabaseLink
+	for _, link := range links {
+		if link.AppID != appID {
+			kept = append(kept, link)
+		}
+	}
+	return kept
+}
@@ -21,21 +21,12 @@ func runWithTimeout(cmd *exec.Cmd, timeout time.Duration) error {
 	return cmdWithCtx.Run()
 }
 
-func SyncDependencies(runtime, appPath, username, plan string) {
-	appID := filepath.Base(appPath)
-	//logDir := filepath.Join("storage", "users", username, plan, "apps", appID, "logs")
-	//logDir := filepath.Join("storage", "users", username, plan, "logs")
-	logDir := filepath.Join("storage", "users", username, "logs")
-	_ = os.MkdirAll(logDir, os.ModePerm)
-	logFile := filepath.Join(logDir, fmt.Sprintf("%s.log", appID))
-	var logLines []string
-
-	writeLog := func(lines []string) {
-		if len(lines) == 0 {
-			return
-		}
-		content := strings.Join(lines, "\n") + "\n"
-		_ = os.WriteFile(logFile, []byte(content), 0644)
+func SyncDependencies(runtime, appPath string, dl *DeployLog) {
+	// 📦 Cada instalação vira uma entrada com duração no log do deploy (falha não interrompe o deploy)
+	install := func(cmd *exec.Cmd) {
+		_ = dl.Attempt("install", "📦 "+strings.Join(cmd.Args, " "), func() error {
+			return runWithTimeout(cmd, 30*time.Second)
+		})
 	}
 
 	switch runtime {
@@ -78,10 +69,10 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 			depPath := filepath.Join(jsRoot, "node_modules", name)
 			if _, err := os.Stat(depPath); os.IsNotExist(err) {
 				fmt.Printf("📦 Instalando %s para Node.js\n", name)
-				logLines = append(logLines, fmt.Sprintf("Node.js: %s", name))
+				dl.Debug("dependency", fmt.Sprintf("Node.js: %s", name))
 				cmd := exec.Command("npm", "install", fmt.Sprintf("%s@latest", name))
 				cmd.Dir = jsRoot
-				_ = runWithTimeout(cmd, 30*time.Second)
+				install(cmd)
 			}
 		}
 	case "python", "django":
@@ -111,7 +102,7 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 					line = strings.TrimSpace(line)
 					if line != "" && !strings.HasPrefix(line, "#") {
 						fmt.Printf("📦 Instalando %s para Python\n", line)
-						logLines = append(logLines, fmt.Sprintf("Python: %s", line))
+						dl.Debug("dependency", fmt.Sprintf("Python: %s", line))
 					}
 				}
 
@@ -121,7 +112,7 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 				} else {
 					cmd = exec.Command("pip", "install", "-r", f, "--target", filepath.Join(pyRoot, "site-packages"))
 				}
-				_ = runWithTimeout(cmd, 30*time.Second)
+				install(cmd)
 				break
 			}
 		}
@@ -139,13 +130,13 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 				lines := strings.Split(string(data), "\n")
 				for _, line := range lines {
 					if strings.HasPrefix(line, "require") || strings.Contains(line, "github.com") {
-						logLines = append(logLines, fmt.Sprintf("Go: %s", strings.TrimSpace(line)))
+						dl.Debug("dependency", fmt.Sprintf("Go: %s", strings.TrimSpace(line)))
 						fmt.Printf("📦 Instalando %s para Go\n", strings.TrimSpace(line))
 					}
 				}
 				cmd := exec.Command("go", "mod", "download")
 				cmd.Dir = filepath.Dir(f)
-				_ = runWithTimeout(cmd, 30*time.Second)
+				install(cmd)
 				break
 			}
 		}
@@ -166,11 +157,11 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 					fmt.Println("📦 Verificando dependências...")
 					for name := range pkg.Require {
 						fmt.Printf("📦 Instalando %s para PHP\n", name)
-						logLines = append(logLines, fmt.Sprintf("PHP: %s", name))
+						dl.Debug("dependency", fmt.Sprintf("PHP: %s", name))
 					}
 				}
 				cmd := exec.Command("composer", "install", "--working-dir", filepath.Dir(f))
-				_ = runWithTimeout(cmd, 30*time.Second)
+				install(cmd)
 				break
 			}
 		}
@@ -188,13 +179,13 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 					line = strings.TrimSpace(line)
 					if strings.HasPrefix(line, "serde") || strings.Contains(line, "=") {
 						fmt.Printf("📦 Instalando %s para Rust\n", line)
-						logLines = append(logLines, fmt.Sprintf("Rust: %s", line))
+						dl.Debug("dependency", fmt.Sprintf("Rust: %s", line))
 					}
 				}
 			}
 			cmd := exec.Command("cargo", "fetch")
 			cmd.Dir = appPath
-			_ = runWithTimeout(cmd, 30*time.Second)
+			install(cmd)
 		}
 
 	case "csharp", "dotnet", "dotnetcore":
@@ -212,12 +203,12 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 					line = strings.TrimSpace(line)
 					if strings.Contains(line, "<PackageReference") {
 						fmt.Printf("📦 Instalando %s para C#\n", line)
-						logLines = append(logLines, fmt.Sprintf("CSharp: %s", line))
+						dl.Debug("dependency", fmt.Sprintf("CSharp: %s", line))
 					}
 				}
 				cmd := exec.Command("dotnet", "restore")
 				cmd.Dir = filepath.Dir(f)
-				_ = runWithTimeout(cmd, 30*time.Second)
+				install(cmd)
 				break
 			}
 		}
@@ -233,13 +224,13 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 				for _, line := range lines {
 					if strings.Contains(line, "hex:") || strings.Contains(line, "deps") {
 						fmt.Printf("📦 Instalando %s para Elixir\n", strings.TrimSpace(line))
-						logLines = append(logLines, fmt.Sprintf("Elixir: %s", strings.TrimSpace(line)))
+						dl.Debug("dependency", fmt.Sprintf("Elixir: %s", strings.TrimSpace(line)))
 					}
 				}
 			}
 			cmd := exec.Command("mix", "deps.get")
 			cmd.Dir = appPath
-			_ = runWithTimeout(cmd, 30*time.Second)
+			install(cmd)
 		}
 
 	case "java", "springboot", "springboot-gradle":
@@ -253,13 +244,13 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 			for _, line := range lines {
 				if strings.Contains(line, "<dependency>") || strings.Contains(line, "<groupId>") {
 					fmt.Printf("📦 Instalando %s para Java\n", strings.TrimSpace(line))
-					logLines = append(logLines, fmt.Sprintf("Java: %s", strings.TrimSpace(line)))
+					dl.Debug("dependency", fmt.Sprintf("Java: %s", strings.TrimSpace(line)))
 				}
 			}
 		}
 		cmd := exec.Command("mvn", "install")
 		cmd.Dir = appPath
-		_ = runWithTimeout(cmd, 30*time.Second)
+		install(cmd)
 
 	case "kotlin":
 		kotlinRoot := filepath.Join("storage", "runtimes", "kotlin")
@@ -272,13 +263,13 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 			for _, line := range lines {
 				if strings.Contains(line, "implementation") || strings.Contains(line, "kotlin") {
 					fmt.Printf("📦 Instalando %s para Kotlin\n", strings.TrimSpace(line))
-					logLines = append(logLines, fmt.Sprintf("Kotlin: %s", strings.TrimSpace(line)))
+					dl.Debug("dependency", fmt.Sprintf("Kotlin: %s", strings.TrimSpace(line)))
 				}
 			}
 		}
 		cmd := exec.Command("gradle", "build")
 		cmd.Dir = appPath
-		_ = runWithTimeout(cmd, 30*time.Second)
+		install(cmd)
 
 	case "lua":
 		luaRoot := filepath.Join("storage", "runtimes", "lua")
@@ -291,19 +282,18 @@ func SyncDependencies(runtime, appPath, username, plan string) {
 			for _, line := range lines {
 				if strings.Contains(line, "dependency") || strings.Contains(line, "lua") {
 					fmt.Printf("📦 Instalando %s para Lua\n", strings.TrimSpace(line))
-					logLines = append(logLines, fmt.Sprintf("Lua: %s", strings.TrimSpace(line)))
+					dl.Debug("dependency", fmt.Sprintf("Lua: %s", strings.TrimSpace(line)))
 				}
 			}
 		}
 		cmd := exec.Command("luarocks", "install", "--tree", filepath.Join(appPath, "lua_modules"))
 		cmd.Dir = appPath
-		_ = runWithTimeout(cmd, 30*time.Second)
+		install(cmd)
 
 	default:
 		fmt.Println("⚠️ SyncDependencies: runtime não suportado:", runtime)
+		dl.Warn("install", "⚠️ Runtime sem instalação de dependências: "+runtime, nil)
 	}
-
-	writeLog(logLines)
 }
 
 // services/dependency_sync.go
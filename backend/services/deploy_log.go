@@ -0,0 +1,391 @@
+//backend/services/deploy_log.go
+
+package services
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🏷️ Fases de um deploy, na ordem em que acontecem
+const (
+	PhasePrepare      = "prepare"      // extração, plano, entry point e runtime
+	PhaseDependencies = "dependencies" // instalação das dependências do runtime
+	PhaseConfigure    = "configure"    // config.json, atalhos, Dockerfile e recursos
+	PhaseBuild        = "build"        // imagem do container
+	PhaseContainer    = "container"    // criação e início do container
+)
+
+// 📏 Saída de comandos guardada por passo (build, instalação...)
+const maxDeployOutputLines = 2000
+
+// 📡 Entradas pendentes por assinante; quem não acompanha é desligado e retoma pelo seq
+const deploySubscriberBuffer = 256
+
+// ❗ Deploy inexistente
+var ErrDeploymentNotFound = errors.New("deploy não encontrado")
+
+// 🚀 Deploys em andamento (aceitam assinantes para o log ao vivo)
+var (
+	deploysMu     sync.Mutex
+	activeDeploys = map[string]*DeployLog{}
+)
+
+// 🧹 Deploys (e seus logs) saem junto com a aplicação
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		if err := store.DeploymentStore.DeleteByApp(app.ID); err != nil {
+			log.Printf("⚠️ Erro ao remover deploys da aplicação %s: %v", app.ID, err)
+		}
+		_ = os.RemoveAll(deployLogDir(app.Username, app.ID))
+	})
+}
+
+// 📝 Log estruturado de um deploy: entradas com nível, fase, passo, duração e erro,
+// gravadas em JSON Lines e repassadas ao log textual da aplicação (services.Log)
+type DeployLog struct {
+	mu         sync.Mutex
+	deployment *models.Deployment
+	plan       string
+	file       *os.File
+	phaseStart time.Time
+	subs       map[chan models.DeployLogEntry]DeployLogFilter
+	finished   bool
+}
+
+// 📁 storage/users/<usuário>/deployments/<app>
+func deployLogDir(username, appID string) string {
+	return filepath.Join("storage", "users", username, "deployments", appID)
+}
+
+func deployLogPath(d *models.Deployment) string {
+	return filepath.Join(deployLogDir(d.Username, d.AppID), d.ID+".jsonl")
+}
+
+// 🚀 Abre um deploy novo (kind: origem do código, zip ou folder)
+func StartDeployment(appID, username, plan, kind string) *DeployLog {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	now := time.Now()
+	d := &models.Deployment{
+		ID:        fmt.Sprintf("%s-%s-%s", appID, now.UTC().Format("20060102T150405"), hex.EncodeToString(suffix)),
+		AppID:     appID,
+		Username:  username,
+		Kind:      kind,
+		Status:    models.DeploymentRunning,
+		Phase:     PhasePrepare,
+		StartedAt: now,
+	}
+	dl := &DeployLog{deployment: d, plan: plan, phaseStart: now, subs: map[chan models.DeployLogEntry]DeployLogFilter{}}
+
+	if err := os.MkdirAll(deployLogDir(username, appID), os.ModePerm); err != nil {
+		log.Printf("⚠️ Erro ao criar diretório do log de deploy: %v", err)
+	} else if f, err := os.OpenFile(deployLogPath(d), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err != nil {
+		log.Printf("⚠️ Erro ao abrir log de deploy %s: %v", d.ID, err)
+	} else {
+		dl.file = f
+	}
+	dl.save()
+
+	deploysMu.Lock()
+	activeDeploys[d.ID] = dl
+	deploysMu.Unlock()
+	return dl
+}
+
+// 🆔 ID do deploy
+func (dl *DeployLog) ID() string {
+	return dl.deployment.ID
+}
+
+// 🏷️ Inicia uma fase (fecha a anterior registrando quanto durou)
+func (dl *DeployLog) Phase(phase string) {
+	dl.mu.Lock()
+	previous := dl.deployment.Phase
+	elapsed := time.Since(dl.phaseStart)
+	dl.deployment.Phase = phase
+	dl.phaseStart = time.Now()
+	dl.mu.Unlock()
+
+	dl.add(models.DeployLogEntry{Level: models.DeployInfo, Phase: previous, Message: "fase concluída", DurationMs: elapsed.Milliseconds()}, false)
+	dl.save()
+}
+
+// ℹ️ Entrada informativa no passo da fase atual
+func (dl *DeployLog) Info(step, message string) {
+	dl.add(models.DeployLogEntry{Level: models.DeployInfo, Step: step, Message: message}, true)
+}
+
+// ⚠️ Problema contornado (o deploy continua)
+func (dl *DeployLog) Warn(step, message string, err error) {
+	entry := models.DeployLogEntry{Level: models.DeployWarn, Step: step, Message: message}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	dl.add(entry, true)
+}
+
+// ❌ Falha do passo
+func (dl *DeployLog) Error(step, message string, err error) {
+	entry := models.DeployLogEntry{Level: models.DeployError, Step: step, Message: message}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	dl.add(entry, true)
+}
+
+// 🐞 Detalhe (saída de comandos, dependências); não vai para o log textual
+func (dl *DeployLog) Debug(step, message string) {
+	dl.add(models.DeployLogEntry{Level: models.DeployDebug, Step: step, Message: message}, false)
+}
+
+// 📜 Saída de um comando, uma entrada debug por linha (limitada a maxDeployOutputLines)
+func (dl *DeployLog) Output(step, output string) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > maxDeployOutputLines {
+		omitted := len(lines) - maxDeployOutputLines
+		lines = lines[omitted:]
+		dl.Debug(step, fmt.Sprintf("… %d linhas anteriores omitidas", omitted))
+	}
+	for _, line := range lines {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			dl.Debug(step, line)
+		}
+	}
+}
+
+// ⏱️ Executa o passo registrando duração e erro
+func (dl *DeployLog) Timed(step, message string, fn func() error) error {
+	return dl.timed(step, message, models.DeployError, fn)
+}
+
+// ⏱️ Como Timed, mas a falha é contornável (registrada como warn)
+func (dl *DeployLog) Attempt(step, message string, fn func() error) error {
+	return dl.timed(step, message, models.DeployWarn, fn)
+}
+
+func (dl *DeployLog) timed(step, message string, failLevel models.DeployLevel, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	entry := models.DeployLogEntry{Level: models.DeployInfo, Step: step, Message: message, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		entry.Level, entry.Error = failLevel, err.Error()
+	}
+	dl.add(entry, true)
+	return err
+}
+
+// 🏁 Encerra o deploy (err nil = sucesso) e desliga os assinantes
+func (dl *DeployLog) Finish(err error) {
+	dl.mu.Lock()
+	if dl.finished {
+		dl.mu.Unlock()
+		return
+	}
+	phaseElapsed := time.Since(dl.phaseStart)
+	dl.mu.Unlock()
+
+	dl.add(models.DeployLogEntry{Level: models.DeployInfo, Message: "fase concluída", DurationMs: phaseElapsed.Milliseconds()}, false)
+	if err != nil {
+		dl.add(models.DeployLogEntry{Level: models.DeployError, Message: "❌ Deploy falhou", Error: err.Error()}, true)
+	} else {
+		dl.add(models.DeployLogEntry{Level: models.DeployInfo, Message: "✅ Deploy concluído com sucesso"}, true)
+	}
+
+	dl.mu.Lock()
+	now := time.Now()
+	dl.finished = true
+	dl.deployment.FinishedAt = &now
+	dl.deployment.DurationMs = now.Sub(dl.deployment.StartedAt).Milliseconds()
+	dl.deployment.Status = models.DeploymentSucceeded
+	if err != nil {
+		dl.deployment.Status = models.DeploymentFailed
+		dl.deployment.Error = MaskSecrets(dl.deployment.AppID, err.Error())
+	}
+	for ch := range dl.subs {
+		close(ch)
+		delete(dl.subs, ch)
+	}
+	if dl.file != nil {
+		_ = dl.file.Close()
+	}
+	dl.mu.Unlock()
+	dl.save()
+
+	deploysMu.Lock()
+	delete(activeDeploys, dl.deployment.ID)
+	deploysMu.Unlock()
+}
+
+// ✍️ Grava a entrada, repassa aos assinantes e (mirror) ao log textual da aplicação
+func (dl *DeployLog) add(entry models.DeployLogEntry, mirror bool) {
+	appID := dl.deployment.AppID
+	entry.Message = MaskSecrets(appID, entry.Message)
+	entry.Error = MaskSecrets(appID, entry.Error)
+
+	dl.mu.Lock()
+	if dl.finished {
+		dl.mu.Unlock()
+		return
+	}
+	dl.deployment.Entries++
+	entry.Seq = dl.deployment.Entries
+	entry.Time = time.Now()
+	if entry.Phase == "" {
+		entry.Phase = dl.deployment.Phase
+	}
+	if entry.Level == models.DeployWarn || entry.Level == models.DeployError {
+		dl.deployment.Warnings++
+	}
+	if dl.file != nil {
+		if raw, err := json.Marshal(entry); err == nil {
+			_, _ = dl.file.Write(append(raw, '\n'))
+		}
+	}
+	for ch, filter := range dl.subs {
+		if !filter.match(entry) {
+			continue
+		}
+		select {
+		case ch <- entry:
+		default:
+			close(ch) // assinante lento: desliga; ele retoma do último seq recebido
+			delete(dl.subs, ch)
+		}
+	}
+	username := dl.deployment.Username
+	dl.mu.Unlock()
+
+	if mirror {
+		text := entry.Message
+		if entry.Error != "" {
+			text += ": " + entry.Error
+		}
+		Log(appID, username, dl.plan, text)
+	}
+}
+
+func (dl *DeployLog) save() {
+	dl.mu.Lock()
+	snapshot := dl.deployment.Clone()
+	dl.mu.Unlock()
+
+	removed, err := store.DeploymentStore.Save(snapshot)
+	if err != nil {
+		log.Printf("⚠️ Erro ao salvar deploy %s: %v", snapshot.ID, err)
+	}
+	for _, old := range removed {
+		_ = os.Remove(deployLogPath(old))
+	}
+}
+
+// 🔎 Filtro das entradas
+type DeployLogFilter struct {
+	MinLevel models.DeployLevel // "" = todas
+	Phase    string             // "" = todas
+	AfterSeq int                // só entradas com seq maior
+}
+
+func (f DeployLogFilter) match(e models.DeployLogEntry) bool {
+	if e.Seq <= f.AfterSeq {
+		return false
+	}
+	if f.MinLevel != "" && e.Level.Rank() < f.MinLevel.Rank() {
+		return false
+	}
+	return f.Phase == "" || e.Phase == f.Phase
+}
+
+// 📖 Entradas gravadas do deploy
+func ReadDeployLog(d *models.Deployment, filter DeployLogFilter) ([]models.DeployLogEntry, error) {
+	entries := []models.DeployLogEntry{}
+	f, err := os.Open(deployLogPath(d))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("erro ao abrir log do deploy: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e models.DeployLogEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if filter.match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler log do deploy: %w", err)
+	}
+	return entries, nil
+}
+
+// 📡 Histórico filtrado e, se o deploy ainda roda, um canal com as próximas entradas
+// (fechado quando o deploy termina ou o assinante fica para trás). cancel libera a assinatura.
+func SubscribeDeployLog(d *models.Deployment, filter DeployLogFilter) ([]models.DeployLogEntry, <-chan models.DeployLogEntry, func(), error) {
+	deploysMu.Lock()
+	dl := activeDeploys[d.ID]
+	deploysMu.Unlock()
+
+	if dl == nil {
+		history, err := ReadDeployLog(d, filter)
+		return history, nil, func() {}, err
+	}
+
+	// Sob o lock do deploy nenhuma entrada nova é gravada: histórico + canal não perdem nem repetem nada
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	history, err := ReadDeployLog(dl.deployment, filter)
+	if err != nil || dl.finished {
+		return history, nil, func() {}, err
+	}
+	ch := make(chan models.DeployLogEntry, deploySubscriberBuffer)
+	dl.subs[ch] = filter
+	cancel := func() {
+		dl.mu.Lock()
+		defer dl.mu.Unlock()
+		if _, ok := dl.subs[ch]; ok {
+			delete(dl.subs, ch)
+			close(ch)
+		}
+	}
+	return history, ch, cancel, nil
+}
+
+// 🛑 Deploys que ficaram "running" quando o backend parou não vão terminar: marca como interrompidos
+func MarkInterruptedDeployments() {
+	for _, d := range store.DeploymentStore.List() {
+		if d.Status != models.DeploymentRunning {
+			continue
+		}
+		deploysMu.Lock()
+		_, active := activeDeploys[d.ID]
+		deploysMu.Unlock()
+		if active {
+			continue
+		}
+		d.Status = models.DeploymentInterrupted
+		d.Error = "backend reiniciado durante o deploy"
+		if _, err := store.DeploymentStore.Save(d); err != nil {
+			log.Printf("⚠️ Erro ao marcar deploy %s como interrompido: %v", d.ID, err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+//backend/services/deploy_log_test.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+func storedDeployment(t *testing.T, id string) *models.Deployment {
+	t.Helper()
+	d, ok := store.DeploymentStore.Get(id)
+	if !ok {
+		t.Fatalf("deploy %s não gravado", id)
+	}
+	return d
+}
+
+func messages(entries []models.DeployLogEntry) string {
+	var parts []string
+	for _, e := range entries {
+		parts = append(parts, e.Message)
+	}
+	return strings.Join(parts, "|")
+}
+
+func TestDeployLogRecordsPhasesAndFilters(t *testing.T) {
+	dl := StartDeployment("dlog1", "logger", "pro", "zip")
+	dl.Info("start", "começou")
+	dl.Phase(PhaseBuild)
+	dl.Warn("image", "cache ausente", errors.New("sem cache"))
+	dl.Output("image", "passo 1\r\npasso 2\n\n")
+	dl.Finish(nil)
+
+	d := storedDeployment(t, dl.ID())
+	if d.Status != models.DeploymentSucceeded || d.FinishedAt == nil || d.Error != "" {
+		t.Fatalf("deploy inesperado: %+v", d)
+	}
+	if d.Phase != PhaseBuild || d.Warnings != 1 {
+		t.Fatalf("fase=%s avisos=%d, esperado build/1", d.Phase, d.Warnings)
+	}
+
+	all, err := ReadDeployLog(d, DeployLogFilter{})
+	if err != nil {
+		t.Fatalf("ReadDeployLog: %v", err)
+	}
+	if len(all) != d.Entries {
+		t.Fatalf("%d entradas no arquivo, deploy registra %d", len(all), d.Entries)
+	}
+	for i, e := range all {
+		if e.Seq != i+1 {
+			t.Fatalf("seq fora de ordem na posição %d: %d", i, e.Seq)
+		}
+	}
+	if all[0].Phase != PhasePrepare || all[1].Phase != PhasePrepare || all[1].DurationMs < 0 {
+		t.Fatalf("fase prepare não foi fechada com duração: %+v", all[:2])
+	}
+
+	cases := []struct {
+		name   string
+		filter DeployLogFilter
+		want   string
+	}{
+		{"nível mínimo", DeployLogFilter{MinLevel: models.DeployWarn}, "cache ausente"},
+		{"saída de comando", DeployLogFilter{MinLevel: models.DeployDebug, Phase: PhaseBuild, AfterSeq: 3}, "passo 1|passo 2|fase concluída|✅ Deploy concluído com sucesso"},
+		{"retomada pelo seq", DeployLogFilter{AfterSeq: d.Entries - 1}, "✅ Deploy concluído com sucesso"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := ReadDeployLog(d, tc.filter)
+			if err != nil {
+				t.Fatalf("ReadDeployLog: %v", err)
+			}
+			if got := messages(entries); got != tc.want {
+				t.Fatalf("entradas %q, esperado %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeployLogFailureAndOutputLimit(t *testing.T) {
+	dl := StartDeployment("dlog2", "logger", "pro", "folder")
+	var out strings.Builder
+	for i := 0; i < maxDeployOutputLines+5; i++ {
+		fmt.Fprintf(&out, "linha %d\n", i)
+	}
+	dl.Output("deps", out.String())
+	dl.Finish(errors.New("build quebrou"))
+	dl.Info("late", "depois do fim") // ignorada
+
+	d := storedDeployment(t, dl.ID())
+	if d.Status != models.DeploymentFailed || d.Error != "build quebrou" {
+		t.Fatalf("falha não registrada: %+v", d)
+	}
+	entries, _ := ReadDeployLog(d, DeployLogFilter{MinLevel: models.DeployDebug, Phase: PhasePrepare})
+	if entries[0].Message != "… 5 linhas anteriores omitidas" || entries[1].Message != "linha 5" {
+		t.Fatalf("saída não foi limitada às últimas %d linhas: %q, %q", maxDeployOutputLines, entries[0].Message, entries[1].Message)
+	}
+	last := entries[len(entries)-1]
+	if last.Level != models.DeployError || last.Error != "build quebrou" {
+		t.Fatalf("última entrada deveria registrar a falha: %+v", last)
+	}
+}
+
+func TestSubscribeDeployLogFollowsLiveDeploy(t *testing.T) {
+	dl := StartDeployment("dlog3", "logger", "pro", "zip")
+	dl.Info("start", "um")
+	dl.Debug("flag", "detalhe")
+
+	history, live, cancel, err := SubscribeDeployLog(storedDeployment(t, dl.ID()), DeployLogFilter{MinLevel: models.DeployInfo})
+	if err != nil {
+		t.Fatalf("SubscribeDeployLog: %v", err)
+	}
+	defer cancel()
+	if got := messages(history); got != "um" {
+		t.Fatalf("histórico %q, esperado só as entradas info ou acima", got)
+	}
+	if live == nil {
+		t.Fatalf("deploy em andamento deveria devolver canal ao vivo")
+	}
+
+	dl.Info("entry", "dois")
+	select {
+	case e := <-live:
+		if e.Message != "dois" || e.Seq != 3 {
+			t.Fatalf("entrada ao vivo inesperada: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("entrada ao vivo não chegou")
+	}
+
+	dl.Finish(nil)
+	var rest []models.DeployLogEntry
+	for e := range live { // fechado ao terminar
+		rest = append(rest, e)
+	}
+	if got := messages(rest); got != "fase concluída|✅ Deploy concluído com sucesso" {
+		t.Fatalf("entradas finais %q", got)
+	}
+
+	// 🏁 Deploy encerrado: só histórico, sem canal
+	history, live, _, err = SubscribeDeployLog(storedDeployment(t, dl.ID()), DeployLogFilter{AfterSeq: 3})
+	if err != nil || live != nil {
+		t.Fatalf("deploy encerrado: canal=%v erro=%v", live, err)
+	}
+	if got := messages(history); got != "fase concluída|✅ Deploy concluído com sucesso" {
+		t.Fatalf("histórico após o fim %q", got)
+	}
+}
+
+func TestMarkInterruptedDeployments(t *testing.T) {
+	active := StartDeployment("dlog4", "logger", "pro", "zip")
+	defer active.Finish(nil)
+	orphan := &models.Deployment{ID: "dlog4-orfao", AppID: "dlog4", Username: "logger", Kind: "zip", Status: models.DeploymentRunning, StartedAt: time.Now()}
+	if _, err := store.DeploymentStore.Save(orphan); err != nil {
+		t.Fatalf("erro ao salvar deploy: %v", err)
+	}
+
+	MarkInterruptedDeployments()
+
+	if d := storedDeployment(t, orphan.ID); d.Status != models.DeploymentInterrupted || d.Error == "" {
+		t.Fatalf("deploy sem processo não foi interrompido: %+v", d)
+	}
+	if d := storedDeployment(t, active.ID()); d.Status != models.DeploymentRunning {
+		t.Fatalf("deploy em andamento marcado como %s", d.Status)
+	}
+}
+
+func TestHandleDeployRecordsDeployment(t *testing.T) {
+	app, _ := deployTestApp(t, "recorded", models.PlanPro, "rec1")
+
+	deployments := store.DeploymentStore.ListByApp(app.ID)
+	if len(deployments) != 1 {
+		t.Fatalf("%d deploys registrados, esperado 1", len(deployments))
+	}
+	d := deployments[0]
+	if d.Kind != "zip" || d.Status != models.DeploymentSucceeded || d.Phase != PhaseContainer {
+		t.Fatalf("deploy inesperado: kind=%s status=%s fase=%s", d.Kind, d.Status, d.Phase)
+	}
+	entries, err := ReadDeployLog(d, DeployLogFilter{MinLevel: models.DeployInfo})
+	if err != nil {
+		t.Fatalf("ReadDeployLog: %v", err)
+	}
+	phases := map[string]bool{}
+	for _, e := range entries {
+		phases[e.Phase] = true
+	}
+	for _, phase := range []string{PhasePrepare, PhaseDependencies, PhaseConfigure, PhaseBuild, PhaseContainer} {
+		if !phases[phase] {
+			t.Fatalf("fase %s sem entradas no log do deploy", phase)
+		}
+	}
+}
@@ -24,9 +24,9 @@ func Log(appID, username, plan, message string) {
 	}
 	defer f.Close()
 
-	// Timestamp completo no conteúdo
+	// Timestamp completo no conteúdo (segredos da aplicação nunca chegam ao disco)
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	line := fmt.Sprintf("[%s] %s\n", timestamp, message)
+	line := fmt.Sprintf("[%s] %s\n", timestamp, MaskSecrets(appID, message))
 	_, _ = f.WriteString(line)
 	fmt.Print(line)
 }
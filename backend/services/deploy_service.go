@@ -13,14 +13,13 @@ import (
 	"runtime"
 	"time"
 
+	"virtuscloud/backend/engine"
 	"virtuscloud/backend/limits"
 	"virtuscloud/backend/models"
 	"virtuscloud/backend/store"
 	"virtuscloud/backend/utils"
 )
 
-var AppStore = make(map[string]*models.App)
-
 // 🚀 Deploy a partir de um arquivo ZIP
 func HandleDeploy(zipPath, username, plan, customID string) (*models.App, error) {
 	if !isValidIdentifier(plan) || (customID != "" && !isValidIdentifier(customID)) {
@@ -41,16 +40,34 @@ func HandleDeploy(zipPath, username, plan, customID string) (*models.App, error)
 		return nil, fmt.Errorf("já existe uma aplicação com o ID: %s", appID)
 	}
 
-	Log(appID, username, plan, "🚀 Iniciando deploy da aplicação")
+	dl := StartDeployment(appID, username, plan, "zip")
+	dl.Info("start", "🚀 Iniciando deploy da aplicação")
 
 	extractPath := filepath.Join("storage", "users", username, plan, "apps", appID)
-	if err := utils.ExtractZip(zipPath, extractPath); err != nil {
-		Log(appID, username, plan, "❌ Falha ao extrair ZIP")
+	if err := dl.Timed("extract", "📦 ZIP extraído", func() error {
+		return utils.ExtractZip(zipPath, extractPath)
+	}); err != nil {
+		dl.Finish(err)
+		return nil, err
+	}
+
+	return handleDeployCommon(extractPath, username, plan, appID, nil, dl)
+}
+
+// 🔁 Reinstala uma aplicação existente a partir do ZIP: mesmo ID, nome e fatia do plano já reservada
+func redeployFromZip(zipPath string, previous *models.App) (*models.App, error) {
+	dl := StartDeployment(previous.ID, previous.Username, previous.Plan, "rebuild")
+	dl.Info("start", "🔁 Iniciando reconstrução da aplicação")
+
+	extractPath := filepath.Join("storage", "users", previous.Username, previous.Plan, "apps", previous.ID)
+	if err := dl.Timed("extract", "📦 ZIP extraído", func() error {
+		return utils.ExtractZip(zipPath, extractPath)
+	}); err != nil {
+		dl.Finish(err)
 		return nil, err
 	}
-	Log(appID, username, plan, "📦 ZIP extraído com sucesso")
 
-	return handleDeployCommon(extractPath, username, plan, appID)
+	return handleDeployCommon(extractPath, previous.Username, previous.Plan, previous.ID, previous, dl)
 }
 
 // 🚀 Deploy direto de uma pasta já existente (sem ZIP)
@@ -63,56 +80,67 @@ func HandleDeployFromFolder(folderPath, username, plan, appID string) (*models.A
 		return nil, fmt.Errorf("já existe uma aplicação com o ID: %s", appID)
 	}
 
-	Log(appID, username, plan, "🚀 Iniciando deploy direto da pasta")
+	dl := StartDeployment(appID, username, plan, "folder")
+	dl.Info("start", "🚀 Iniciando deploy direto da pasta")
 
-	return handleDeployCommon(folderPath, username, plan, appID)
+	return handleDeployCommon(folderPath, username, plan, appID, nil, dl)
 }
 
-// 🔁 Lógica compartilhada entre ZIP e pasta // HYBRID
-func handleDeployCommon(path, username, plan, appID string) (*models.App, error) {
+// 🔁 Lógica compartilhada entre ZIP e pasta // HYBRID (previous != nil = reconstrução de aplicação existente)
+func handleDeployCommon(path, username, plan, appID string, previous *models.App, dl *DeployLog) (*models.App, error) {
+	// ❌ Toda saída com erro encerra o deploy com a falha registrada
+	fail := func(err error) (*models.App, error) {
+		dl.Finish(err)
+		return nil, err
+	}
+
 	// ✅ Cria flag de deploy incompleto ANTES da verificação
 	flagPath := filepath.Join(path, "incomplete.flag")
 	_ = os.WriteFile(flagPath, []byte("deploy em andamento"), 0644)
-	Log(appID, username, plan, "🚧 Flag 'incomplete.flag' criado para controle de deploy")
+	dl.Debug("flag", "🚧 Flag 'incomplete.flag' criado para controle de deploy")
 
 	// ✅ Verifica se o usuário pode fazer deploy conforme o plano
-	user := store.UserStore[username]
+	user, _ := store.UserStore.Get(username)
 	if user == nil {
-		Log(appID, username, plan, "❌ Usuário não encontrado para verificação de plano")
-		return nil, fmt.Errorf("usuário não encontrado")
+		dl.Error("plan", "❌ Usuário não encontrado para verificação de plano", nil)
+		return fail(fmt.Errorf("usuário não encontrado"))
 	}
 
-	// ✅ Corrigido: passa username e plano como string
-	if err := limits.IsUserEligibleForDeploy(username, plan); err != nil {
-		Log(appID, username, plan, "❌ Deploy bloqueado por limite de plano: "+err.Error())
-		return nil, fmt.Errorf("deploy bloqueado: %v", err)
+	// ✅ Corrigido: passa username e plano como string (a reconstrução já ocupa a vaga e a fatia dela)
+	if previous != nil {
+		dl.Info("plan", "📐 Reconstrução: mantém a vaga e a fatia já reservadas")
+	} else if err := limits.IsUserEligibleForDeploy(username, plan); err != nil {
+		dl.Error("plan", "❌ Deploy bloqueado por limite de plano", err)
+		return fail(fmt.Errorf("deploy bloqueado: %v", err))
 	}
 
 	entryPoints, err := DetectEntryPoint(path)
 	if err != nil {
-		Log(appID, username, plan, "❌ Erro ao detectar entry point")
-		return nil, err
+		dl.Error("entry", "❌ Erro ao detectar entry point", err)
+		return fail(err)
 	}
 
 	if len(entryPoints) == 0 {
-		Log(appID, username, plan, "❌ Nenhum entry point detectado — verifique se há arquivos como Main.java, index.js, etc.")
-		return nil, fmt.Errorf("nenhum entry point detectado")
+		dl.Error("entry", "❌ Nenhum entry point detectado — verifique se há arquivos como Main.java, index.js, etc.", nil)
+		return fail(fmt.Errorf("nenhum entry point detectado"))
 	}
 	selectedEntry := entryPoints[0]
 
-	Log(appID, username, plan, fmt.Sprintf("📁 Entry point detectado: %s", selectedEntry))
+	dl.Info("entry", fmt.Sprintf("📁 Entry point detectado: %s", selectedEntry))
 
 	runtimeType := DetectRuntime(filepath.Join(path, selectedEntry))
 	visualRuntime := DetectVisualRuntime(filepath.Join(path, selectedEntry)) // para o frontend
 
-	Log(appID, username, plan, fmt.Sprintf("🧠 Runtime detectado: %s", runtimeType))
+	dl.Info("runtime", fmt.Sprintf("🧠 Runtime detectado: %s", runtimeType))
 
-	SyncDependencies(runtimeType, path, username, plan)
+	dl.Phase(PhaseDependencies)
+	SyncDependencies(runtimeType, path, dl)
 
+	dl.Phase(PhaseConfigure)
 	if err := LinkRuntime(runtimeType, path); err != nil {
-		Log(appID, username, plan, fmt.Sprintf("⚠️ Falha ao criar symlink: %v", err))
+		dl.Warn("link", "⚠️ Falha ao criar symlink", err)
 	} else {
-		Log(appID, username, plan, "🔗 Symlink do runtime criado com sucesso")
+		dl.Info("link", "🔗 Symlink do runtime criado com sucesso")
 	}
 
 	config := map[string]string{
@@ -121,15 +149,15 @@ func handleDeployCommon(path, username, plan, appID string) (*models.App, error)
 	}
 	configData, _ := json.MarshalIndent(config, "", "  ")
 	_ = os.WriteFile(filepath.Join(path, "config.json"), configData, 0644)
-	Log(appID, username, plan, "📝 Arquivo config.json gerado")
+	dl.Info("config", "📝 Arquivo config.json gerado")
 
 	// 🔗 Symlink/junction híbrido
 	userSymlink := filepath.Join("storage", "users", username, "current-app")
 	_ = os.MkdirAll(filepath.Dir(userSymlink), os.ModePerm)
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		Log(appID, username, plan, fmt.Sprintf("⚠️ Pasta da aplicação '%s' ainda não existe. Abortando criação do atalho.", path))
-		return nil, fmt.Errorf("pasta da aplicação não encontrada: %s", path)
+		dl.Error("shortcut", fmt.Sprintf("⚠️ Pasta da aplicação '%s' ainda não existe. Abortando criação do atalho.", path), nil)
+		return fail(fmt.Errorf("pasta da aplicação não encontrada: %s", path))
 	}
 
 	_ = os.Remove(userSymlink)
@@ -137,24 +165,24 @@ func handleDeployCommon(path, username, plan, appID string) (*models.App, error)
 	if runtime.GOOS == "windows" {
 		cmd := exec.Command("cmd", "/C", "mklink", "/J", userSymlink, path)
 		if err := cmd.Run(); err != nil {
-			Log(appID, username, plan, fmt.Sprintf("⚠️ Erro ao criar junction no Windows: %v", err))
+			dl.Warn("shortcut", "⚠️ Erro ao criar junction no Windows", err)
 		} else {
-			Log(appID, username, plan, "🔗 Junction 'current-app' criado com sucesso em "+userSymlink)
+			dl.Info("shortcut", "🔗 Junction 'current-app' criado com sucesso em "+userSymlink)
 		}
 	} else {
 		if err := os.Symlink(path, userSymlink); err != nil {
-			Log(appID, username, plan, fmt.Sprintf("⚠️ Erro ao atualizar symlink '%s': %v", userSymlink, err))
+			dl.Warn("shortcut", fmt.Sprintf("⚠️ Erro ao atualizar symlink '%s'", userSymlink), err)
 		} else {
-			Log(appID, username, plan, "🔗 Symlink 'current-app' atualizado em "+userSymlink)
+			dl.Info("shortcut", "🔗 Symlink 'current-app' atualizado em "+userSymlink)
 		}
 	}
 
 	dockerContent, err := LoadDockerTemplate(runtimeType, selectedEntry)
 	if err != nil {
-		Log(appID, username, plan, fmt.Sprintf("⚠️ Template de Dockerfile não encontrado: %v", err))
+		dl.Warn("dockerfile", "⚠️ Template de Dockerfile não encontrado", err)
 	} else {
 		_ = os.WriteFile(filepath.Join(path, "Dockerfile"), []byte(dockerContent), 0644)
-		Log(appID, username, plan, "📄 Dockerfile gerado com sucesso")
+		dl.Info("dockerfile", "📄 Dockerfile gerado com sucesso")
 	}
 
 	app := &models.App{
@@ -165,10 +193,27 @@ func handleDeployCommon(path, username, plan, appID string) (*models.App, error)
 		Entry:         selectedEntry,
 		Plan:          plan,
 		Status:        models.StatusRunning,
+		DesiredStatus: models.StatusRunning,
 		ContainerName: fmt.Sprintf("%s-%s", username, appID), // ✅ Adicionado
 	}
 
-	store.SaveApp(app)
+	// 📐 Reserva a fatia do plano e salva no AppStore (recusa se outro deploy ocupou a sobra)
+	if previous != nil {
+		app.Name = previous.Name
+		app.Port = previous.Port
+		app.Logs = previous.Logs
+		app.HealthCheck = previous.HealthCheck.Clone()
+		app.AllocatedRAMMB = previous.AllocatedRAMMB
+		app.AllocatedCPUs = previous.AllocatedCPUs
+		if err := store.AppStore.Save(app); err != nil {
+			dl.Error("allocate", "❌ Erro ao salvar aplicação", err)
+			return fail(fmt.Errorf("erro ao salvar aplicação: %w", err))
+		}
+	} else if err := limits.AllocateNewApp(app); err != nil {
+		dl.Error("allocate", "❌ Deploy bloqueado por limite de plano", err)
+		return fail(fmt.Errorf("deploy bloqueado: %v", err))
+	}
+	dl.Info("allocate", fmt.Sprintf("📐 Recursos alocados: %dMB | %.2f vCPU", app.AllocatedRAMMB, app.AllocatedCPUs))
 	//app := &models.App{
 	//	ID:       appID,
 	//	Username: username,
@@ -190,60 +235,47 @@ func handleDeployCommon(path, username, plan, appID string) (*models.App, error)
 	//}
 	//AppStore[appID] = app
 
-	Log(appID, username, plan, "✅ Deploy concluído com sucesso")
-
 	// ✅ Remove flag após deploy bem-sucedido
 	_ = os.Remove(flagPath)
-	Log(appID, username, plan, "✅ Flag 'incomplete.flag' removido após deploy")
+	dl.Debug("flag", "✅ Flag 'incomplete.flag' removido após deploy")
 
-	buildAndCreateContainer(app)
+	dl.Finish(buildAndCreateContainer(app, dl))
 
 	return app, nil
 }
 
-// 🛠️ Build da imagem e criação do container
-func buildAndCreateContainer(app *models.App) {
+// 🛠️ Build da imagem e criação do container (o erro devolvido encerra o deploy como falho)
+func buildAndCreateContainer(app *models.App, dl *DeployLog) error {
 	imageName := fmt.Sprintf("%s-%s", app.Username, app.ID) // 📦 imagem personalizada
 	containerName := fmt.Sprintf("%s-%s", app.Username, app.ID)
 
-	buildSucceeded := false // ✅ Flag para controlar se a build foi bem-sucedida
-
-	for {
-		Log(app.ID, app.Username, app.Plan, "🔨 Tentando construir imagem Docker...")
+	dl.Phase(PhaseBuild)
+	for attempt := 1; ; attempt++ {
+		dl.Info("image", fmt.Sprintf("🔨 Construindo imagem Docker (tentativa %d)...", attempt))
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		buildCmd := exec.CommandContext(ctx, "docker", "buildx", "build", "-t", imageName, app.Path)
-		out, err := buildCmd.CombinedOutput()
-		cancel()
+		var out string
+		err := dl.Timed("image", "🔨 Build da imagem", func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), engine.BuildTimeout)
+			defer cancel()
+			var err error
+			out, err = engine.Default().Build(ctx, app.Path, imageName)
+			return err
+		})
+		dl.Output("image", out)
 
 		if err == nil {
-			Log(app.ID, app.Username, app.Plan, "✅ Imagem Docker criada com sucesso")
-			buildSucceeded = true
+			dl.Info("image", "✅ Imagem Docker criada com sucesso")
 			break
 		}
 
-		Log(app.ID, app.Username, app.Plan, fmt.Sprintf("❌ Erro no buildx: %v\nSaída: %s", err, string(out)))
-
-		// 🧪 Fallback para build simples
-		Log(app.ID, app.Username, app.Plan, "🔁 Tentando build simples como fallback...")
-		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
-		buildCmd = exec.CommandContext(ctx, "docker", "build", "-t", imageName, app.Path)
-		out, err = buildCmd.CombinedOutput()
-		cancel()
-
-		if err == nil {
-			Log(app.ID, app.Username, app.Plan, "✅ Imagem Docker criada com build simples")
-			buildSucceeded = true
-			break
-		}
-
-		Log(app.ID, app.Username, app.Plan, fmt.Sprintf("❌ Erro no build simples: %v\nSaída: %s", err, string(out)))
-
 		// ⏳ Espera até Docker estar ativo
 		for {
-			Log(app.ID, app.Username, app.Plan, "⏳ Verificando ativação do Docker...")
-			if exec.Command("docker", "info").Run() == nil {
-				Log(app.ID, app.Username, app.Plan, "✅ Docker está ativo! Retentando build...")
+			dl.Info("engine", "⏳ Verificando ativação do Docker...")
+			ctx, cancel := engine.Timeout()
+			err := engine.Default().Ping(ctx)
+			cancel()
+			if err == nil {
+				dl.Info("engine", "✅ Docker está ativo! Retentando build...")
 				break
 			}
 			time.Sleep(10 * time.Second)
@@ -252,43 +284,58 @@ func buildAndCreateContainer(app *models.App) {
 		time.Sleep(10 * time.Second)
 	}
 
+	dl.Phase(PhaseContainer)
+
 	// 🔐 Recupera token da sessão do usuário via arquivo
 	session, ok := models.GetSessionByTokenFromUsername(app.Username)
 	if !ok || session.Token == "" {
-		Log(app.ID, app.Username, app.Plan, "❌ Token ausente — container não será criado")
+		dl.Error("session", "❌ Token ausente — container não será criado", nil)
 		app.Logs = append(app.Logs, "❌ Token ausente — container não criado")
-		return
+		return fmt.Errorf("token de sessão ausente")
 	}
 	token := session.Token
 
-	// 🐳 Criação do container via função centralizada — somente se a build foi bem-sucedida
-	if buildSucceeded {
-		app.ContainerName = containerName
-		err := CreateContainerFromApp(app, token)
-		if err != nil {
-			Log(app.ID, app.Username, app.Plan, fmt.Sprintf("⚠️ Falha ao criar container: %v", err))
-			app.Logs = append(app.Logs, "⚠️ Falha ao criar container: "+err.Error())
-		} else {
-			Log(app.ID, app.Username, app.Plan, "🐳 Container Docker criado com sucesso")
-			app.Logs = append(app.Logs, "🐳 Container Docker criado com sucesso")
-
-			// 🧹 Remove a pasta da aplicação após deploy
-			err = os.RemoveAll(app.Path)
-			if err != nil {
-				Log(app.ID, app.Username, app.Plan, fmt.Sprintf("⚠️ Erro ao remover pasta da aplicação: %v", err))
-				app.Logs = append(app.Logs, "⚠️ Erro ao remover pasta da aplicação: "+err.Error())
-			} else {
-				Log(app.ID, app.Username, app.Plan, "🧹 Pasta da aplicação removida após deploy")
-				app.Logs = append(app.Logs, "🧹 Pasta da aplicação removida após deploy")
-			}
-		}
+	// 🐳 Criação do container via função centralizada
+	app.ContainerName = containerName
+	if err := dl.Timed("create", "🐳 Criação do container", func() error {
+		return CreateContainerFromApp(app, token)
+	}); err != nil {
+		app.Logs = append(app.Logs, "⚠️ Falha ao criar container: "+err.Error())
+		return fmt.Errorf("falha ao criar container: %w", err)
+	}
+	app.Logs = append(app.Logs, "🐳 Container Docker criado com sucesso")
+	recordImageID(app, imageName)
+
+	// 🧹 Remove a pasta da aplicação após deploy
+	if err := os.RemoveAll(app.Path); err != nil {
+		dl.Warn("cleanup", "⚠️ Erro ao remover pasta da aplicação", err)
+		app.Logs = append(app.Logs, "⚠️ Erro ao remover pasta da aplicação: "+err.Error())
 	} else {
-		Log(app.ID, app.Username, app.Plan, "⚠️ Build falhou — container não criado e pasta preservada")
-		app.Logs = append(app.Logs, "⚠️ Build falhou — container não criado e pasta preservada")
+		dl.Info("cleanup", "🧹 Pasta da aplicação removida após deploy")
+		app.Logs = append(app.Logs, "🧹 Pasta da aplicação removida após deploy")
 	}
+	return nil
 }
 
 // 🔍 Validação de identificadores
+// 🆔 Registra a imagem do deploy como versão desejada (o reconciliador recria containers em outra imagem)
+func recordImageID(app *models.App, imageName string) {
+	ctx, cancel := engine.Timeout()
+	imageID, err := engine.Default().ImageID(ctx, imageName)
+	cancel()
+	if err != nil {
+		Log(app.ID, app.Username, app.Plan, fmt.Sprintf("⚠️ Erro ao identificar imagem %s: %v", imageName, err))
+		return
+	}
+	app.ImageID = imageID
+	if _, err := store.AppStore.Update(app.ID, func(a *models.App) {
+		a.ImageID = imageID
+		a.DesiredStatus = models.StatusRunning
+	}); err != nil {
+		Log(app.ID, app.Username, app.Plan, fmt.Sprintf("⚠️ Erro ao registrar imagem da aplicação: %v", err))
+	}
+}
+
 func isValidIdentifier(id string) bool {
 	valid := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 	return valid.MatchString(id)
@@ -301,8 +348,7 @@ func GenerateID() int64 {
 
 // 🔎 Verifica se o App já existe
 func AppIDExists(appID string) bool {
-	_, exists := AppStore[appID]
-	return exists
+	return store.AppStore.Exists(appID)
 }
 
 // 🛠️ Build da imagem e criação do container
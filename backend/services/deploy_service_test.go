@@ -0,0 +1,271 @@
+//backend/services/deploy_service_test.go
+
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/store"
+)
+
+// 📨 Payloads recebidos pela rota falsa de criação de containers
+var (
+	createdMu       sync.Mutex
+	createdPayloads = map[string]map[string]string{} // container → último payload recebido
+)
+
+// 🧪 Os testes rodam numa pasta temporária (storage/ e database/ relativos), com o runtime em memória
+// e um servidor próprio no lugar da rota interna que cria containers.
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "services-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	// 📄 Templates de Dockerfile do repositório
+	if err := os.Symlink(filepath.Join(packageDir, "..", "templates"), filepath.Join(dir, "templates")); err != nil {
+		fmt.Println("erro ao ligar templates:", err)
+		return 1
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	persistence.SetBackend(persistence.NewJSONBackend())
+	engine.SetDefault(engine.NewFake())
+	loaders := []error{
+		store.UserStore.Load("./database/users.json"),
+		store.LoadAppStoreFromDisk("./database/appstore.json"),
+		store.LoadAppEnvStoreFromDisk(store.DefaultAppEnvStorePath),
+		store.LoadVolumeStoreFromDisk(store.DefaultVolumeStorePath),
+		store.LoadDatabaseStoreFromDisk(store.DefaultDatabaseStorePath),
+		store.LoadDeploymentStoreFromDisk(store.DefaultDeploymentStorePath),
+		models.OpenSessions(),
+	}
+	for _, err := range loaders {
+		if err != nil {
+			fmt.Println("erro ao carregar stores:", err)
+			return 1
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(fakeContainerCreation))
+	defer srv.Close()
+	containerCreateURL = srv.URL
+
+	return m.Run()
+}
+
+// 🐳 Mesmo trabalho da rota /api/containers/dev-create, sem Docker
+func fakeContainerCreation(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	createdMu.Lock()
+	createdPayloads[payload["name"]] = payload
+	createdMu.Unlock()
+	resources, err := limits.AppContainerResources(payload["username"], payload["name"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	network, err := EnsureUserNetwork(payload["username"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	spec := engine.Spec{
+		Name:        payload["name"],
+		Image:       payload["image"],
+		Labels:      map[string]string{"username": payload["username"], "user": payload["username"], "name": payload["name"]},
+		Resources:   resources,
+		NetworkMode: network,
+	}
+	if app := GetAppByContainerName(payload["name"]); app != nil {
+		if err := ApplyAppSpec(&spec, app.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	if _, err := engine.Default().Create(ctx, spec); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := engine.Default().Start(ctx, spec.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// 👤 Usuário com sessão ativa (o deploy busca o token da sessão para criar o container)
+func newTestUser(t *testing.T, username string, plan models.PlanType) {
+	t.Helper()
+	if err := store.UserStore.Save(&models.User{Username: username, Plan: plan}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+	sessions := models.LoadSessions()
+	sessions[username] = models.SessionData{Username: username, Plan: string(plan), LastSeen: models.NowISO(), Token: "token-" + username}
+	if err := models.SaveSessions(sessions); err != nil {
+		t.Fatalf("erro ao salvar sessão: %v", err)
+	}
+}
+
+// 📦 ZIP com os arquivos dados
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("erro ao criar zip: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("erro ao escrever zip: %v", err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("erro ao fechar zip: %v", err)
+	}
+	f.Close()
+	return path
+}
+
+func fakeRuntime(t *testing.T) *engine.FakeRuntime {
+	t.Helper()
+	rt, ok := engine.Default().(*engine.FakeRuntime)
+	if !ok {
+		t.Fatalf("runtime padrão não é o fake")
+	}
+	return rt
+}
+
+func inspect(t *testing.T, name string) *engine.Info {
+	t.Helper()
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	info, err := engine.Default().Inspect(ctx, name)
+	if err != nil {
+		t.Fatalf("erro ao inspecionar %s: %v", name, err)
+	}
+	return info
+}
+
+func TestHandleDeployCreatesRunningContainer(t *testing.T) {
+	newTestUser(t, "deployer", models.PlanPro)
+	zipPath := writeTestZip(t, map[string]string{"index.js": "console.log('oi')\n"})
+
+	app, err := HandleDeploy(zipPath, "deployer", "pro", "web1")
+	if err != nil {
+		t.Fatalf("HandleDeploy: %v", err)
+	}
+	if app.ContainerName != "deployer-web1" || app.Entry != "index.js" {
+		t.Fatalf("aplicação inesperada: container=%q entry=%q", app.ContainerName, app.Entry)
+	}
+	stored, ok := store.AppStore.Get("web1")
+	if !ok {
+		t.Fatalf("aplicação não gravada no AppStore")
+	}
+	ramMB, cpus := models.Plans[models.PlanPro].DefaultAppAllocation()
+	if stored.AllocatedRAMMB != ramMB || stored.AllocatedCPUs != cpus {
+		t.Fatalf("alocação = %dMB/%.2f, esperado %dMB/%.2f", stored.AllocatedRAMMB, stored.AllocatedCPUs, ramMB, cpus)
+	}
+
+	info := inspect(t, "deployer-web1")
+	if !info.Running {
+		t.Fatalf("container não está rodando")
+	}
+	if stored.ImageID == "" || stored.ImageID != info.Image {
+		t.Fatalf("imagem registrada %q, container em %q", stored.ImageID, info.Image)
+	}
+	if info.Resources.MemoryMB != ramMB {
+		t.Fatalf("limite de memória = %dMB, esperado %dMB", info.Resources.MemoryMB, ramMB)
+	}
+	createdMu.Lock()
+	payload := createdPayloads["deployer-web1"]
+	createdMu.Unlock()
+	if want := fmt.Sprintf("%dM", ramMB); payload["memory"] != want {
+		t.Fatalf("memória pedida na criação = %q, esperado a fatia %q", payload["memory"], want)
+	}
+	if info.Labels["user"] != "deployer" {
+		t.Fatalf("labels inesperadas: %v", info.Labels)
+	}
+	if _, ok := info.Networks["vc-net-deployer"]; !ok {
+		t.Fatalf("container fora da rede do usuário: %v", info.Networks)
+	}
+	if _, err := os.Stat(app.Path); !os.IsNotExist(err) {
+		t.Fatalf("pasta da aplicação não foi removida após o deploy")
+	}
+}
+
+func TestHandleDeployRejectsDuplicateID(t *testing.T) {
+	newTestUser(t, "dup", models.PlanPro)
+	zipPath := writeTestZip(t, map[string]string{"main.py": "print('oi')\n"})
+
+	if _, err := HandleDeploy(zipPath, "dup", "pro", "dup1"); err != nil {
+		t.Fatalf("primeiro deploy: %v", err)
+	}
+	if _, err := HandleDeploy(zipPath, "dup", "pro", "dup1"); err == nil {
+		t.Fatalf("deploy com ID existente deveria falhar")
+	}
+}
+
+func TestHandleDeployWithoutEntryPoint(t *testing.T) {
+	newTestUser(t, "noentry", models.PlanPro)
+	zipPath := writeTestZip(t, map[string]string{"README.md": "nada aqui\n"})
+
+	if _, err := HandleDeploy(zipPath, "noentry", "pro", "ne1"); err == nil {
+		t.Fatalf("deploy sem entry point deveria falhar")
+	}
+	if store.AppStore.Exists("ne1") {
+		t.Fatalf("aplicação gravada apesar da falha")
+	}
+}
+
+func TestHandleDeployRespectsPlanLimit(t *testing.T) {
+	newTestUser(t, "tester", models.PlanTest) // plano de teste: 1 aplicação
+	zipPath := writeTestZip(t, map[string]string{"index.js": "console.log('oi')\n"})
+
+	if _, err := HandleDeploy(zipPath, "tester", "test", "t1"); err != nil {
+		t.Fatalf("primeiro deploy: %v", err)
+	}
+	if _, err := HandleDeploy(zipPath, "tester", "test", "t2"); err == nil {
+		t.Fatalf("segundo deploy deveria passar do limite do plano")
+	}
+	if store.AppStore.Exists("t2") {
+		t.Fatalf("aplicação acima do limite gravada")
+	}
+}
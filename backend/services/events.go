@@ -3,10 +3,13 @@
 package services
 
 import (
-	"bufio"
-	"os/exec"
-	"strings"
+	"context"
+	"log"
+	"strconv"
 	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
 )
 
 // Estrutura do evento
@@ -25,30 +28,29 @@ var (
 // Inicia escuta de eventos do Docker
 func StartEventListener() {
 	go func() {
-		cmd := exec.Command("docker", "events", "--format", "{{.Time}}|{{.Action}}|{{.Actor.Attributes.name}}")
-		stdout, _ := cmd.StdoutPipe()
-		cmd.Start()
-
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			parts := strings.Split(scanner.Text(), "|")
-			if len(parts) != 3 {
-				continue
-			}
-
-			e := DockerEvent{
-				Time:      parts[0],
-				Action:    parts[1],
-				App:       parts[2],
-				Container: parts[2],
+		for {
+			events, errs := engine.Default().Events(context.Background())
+			for ev := range events {
+				e := DockerEvent{
+					Time:      strconv.FormatInt(ev.Time.Unix(), 10),
+					Action:    ev.Action,
+					App:       ev.Name,
+					Container: ev.Name,
+				}
+
+				eventLock.Lock()
+				eventLog = append(eventLog, e)
+				if len(eventLog) > 50 {
+					eventLog = eventLog[len(eventLog)-50:] // Mantém os últimos 50
+				}
+				eventLock.Unlock()
 			}
 
-			eventLock.Lock()
-			eventLog = append(eventLog, e)
-			if len(eventLog) > 50 {
-				eventLog = eventLog[len(eventLog)-50:] // Mantém os últimos 50
+			// 🔌 Stream encerrado (daemon reiniciado?): reconecta em seguida
+			if err := <-errs; err != nil {
+				log.Println("⚠️ Stream de eventos Docker interrompido:", err)
 			}
-			eventLock.Unlock()
+			time.Sleep(5 * time.Second)
 		}
 	}()
 }
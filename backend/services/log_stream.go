@@ -0,0 +1,225 @@
+//backend/services/log_stream.go
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 📏 Limites do streaming de logs
+const (
+	DefaultLogTail  = 100
+	MaxLogTail      = 5000
+	maxLogLineBytes = 16 * 1024 // linhas maiores são cortadas (não acumulam memória sem fim)
+)
+
+// 🏷️ Origem da linha; "system" são avisos da própria plataforma (container parado, retomada...)
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+	LogStreamSystem = "system"
+)
+
+// ❗ Aplicação removida durante o streaming
+var ErrLogStreamGone = errors.New("aplicação removida")
+
+// 📄 Linha de log de um container
+type LogLine struct {
+	Stream string
+	Time   time.Time
+	Text   string
+}
+
+// ⚙️ O que transmitir
+type LogStreamOptions struct {
+	Stdout bool
+	Stderr bool
+	Follow bool      // segue o container, inclusive entre reinícios e recriações
+	Tail   int       // linhas iniciais; < 0 = todas
+	Since  time.Time // zero = desde o início
+	After  time.Time // retomada (Last-Event-ID): descarta linhas até este instante, inclusive
+}
+
+// 📡 Envia as linhas da aplicação para emit, na ordem, com os segredos mascarados.
+// emit é chamado de uma goroutine por vez e bloqueia a leitura do runtime enquanto não retorna
+// (cliente lento segura o stream em vez de acumular memória); erro de emit encerra o streaming.
+func StreamAppLogs(ctx context.Context, app *models.App, opts LogStreamOptions, emit func(LogLine) error) error {
+	rt := engine.Default()
+	cursor := opts.After
+	tail := opts.Tail
+	since := opts.Since
+
+	for {
+		var emitErr error
+		send := func(stream string, raw []byte) error {
+			if emitErr != nil {
+				return emitErr
+			}
+			line := parseLogLine(stream, raw)
+			if !cursor.IsZero() && !line.Time.IsZero() && !line.Time.After(cursor) {
+				return nil // já entregue antes da retomada
+			}
+			line.Text = MaskSecrets(app.ID, line.Text)
+			if emitErr = emit(line); emitErr == nil && !line.Time.IsZero() {
+				cursor = line.Time
+			}
+			return emitErr
+		}
+		stdout := &logLineWriter{stream: LogStreamStdout, send: send}
+		stderr := &logLineWriter{stream: LogStreamStderr, send: send}
+
+		engineOpts := engine.LogsOptions{
+			Stdout:     opts.Stdout,
+			Stderr:     opts.Stderr,
+			Follow:     opts.Follow,
+			Timestamps: true, // sempre: o instante da linha é o cursor de retomada
+			Tail:       "all",
+			Since:      since,
+		}
+		if tail >= 0 {
+			engineOpts.Tail = strconv.Itoa(tail)
+		}
+		if !cursor.IsZero() && cursor.After(since) {
+			engineOpts.Since = cursor // o runtime arredonda para segundos; o cursor descarta o excesso
+		}
+
+		started := time.Now()
+		err := rt.Logs(ctx, app.ContainerName, engineOpts, stdout, stderr)
+		stdout.flush()
+		stderr.flush()
+		if emitErr != nil {
+			return emitErr
+		}
+		if ctx.Err() != nil || !opts.Follow {
+			return err
+		}
+		if err != nil && !engine.IsNotFound(err) {
+			return err
+		}
+
+		// 🔁 O stream acabou: container parou, foi recriado ou a conexão com o runtime caiu.
+		// Retoma do cursor, sem tail, assim que houver um container rodando com esse nome.
+		tail, since = -1, time.Time{}
+		if err := waitContainerRunning(ctx, app, started, emit); err != nil {
+			return err
+		}
+	}
+}
+
+// ⏳ Espera o container da aplicação voltar a rodar (ou a aplicação sumir / ctx terminar),
+// avisando o cliente quando precisou esperar
+func waitContainerRunning(ctx context.Context, app *models.App, streamStarted time.Time, emit func(LogLine) error) error {
+	// Stream que acabou na hora com o container rodando: evita laço apertado contra o runtime
+	if time.Since(streamStarted) < time.Second {
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	waited := false
+	for {
+		if _, ok := store.AppStore.Get(app.ID); !ok {
+			return ErrLogStreamGone
+		}
+		inspectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		info, err := engine.Default().Inspect(inspectCtx, app.ContainerName)
+		cancel()
+		if err == nil && info.Running {
+			if waited {
+				return emitSystem(emit, "▶️ Container em execução; retomando logs")
+			}
+			return nil
+		}
+		if !waited {
+			waited = true
+			if err := emitSystem(emit, "⏸️ Container parado; aguardando reinício para continuar"); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func emitSystem(emit func(LogLine) error, text string) error {
+	return emit(LogLine{Stream: LogStreamSystem, Text: text})
+}
+
+// 🕒 Separa o timestamp RFC3339Nano que o runtime põe no início da linha
+func parseLogLine(stream string, raw []byte) LogLine {
+	text := strings.TrimSuffix(string(raw), "\r")
+	line := LogLine{Stream: stream, Text: text}
+	if stamp, rest, ok := strings.Cut(text, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			line.Time, line.Text = t, rest
+		}
+	}
+	return line
+}
+
+// ✂️ Junta os pedaços que o runtime entrega (frames não respeitam quebras de linha) em linhas completas
+type logLineWriter struct {
+	stream    string
+	send      func(stream string, line []byte) error
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.append(p)
+			break
+		}
+		w.append(p[:i])
+		if err := w.emit(); err != nil {
+			return 0, err
+		}
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+func (w *logLineWriter) append(p []byte) {
+	if room := maxLogLineBytes - w.buf.Len(); len(p) > room {
+		p = p[:room]
+		w.truncated = true
+	}
+	w.buf.Write(p)
+}
+
+func (w *logLineWriter) emit() error {
+	line := w.buf.Bytes()
+	if w.truncated {
+		line = append(line, " …(linha cortada)"...)
+	}
+	err := w.send(w.stream, line)
+	w.buf.Reset()
+	w.truncated = false
+	return err
+}
+
+// 🚿 Entrega o resto sem quebra de linha final
+func (w *logLineWriter) flush() {
+	if w.buf.Len() > 0 {
+		_ = w.emit()
+	}
+}
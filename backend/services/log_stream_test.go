@@ -0,0 +1,131 @@
+//backend/services/log_stream_test.go
+
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"virtuscloud/backend/models"
+)
+
+// 📥 logLineWriter que guarda as linhas entregues
+func collectLines(stream string) (*logLineWriter, *[]string) {
+	var lines []string
+	w := &logLineWriter{stream: stream, send: func(s string, line []byte) error {
+		lines = append(lines, s+":"+string(line))
+		return nil
+	}}
+	return w, &lines
+}
+
+func TestLogLineWriterSplitsFrames(t *testing.T) {
+	w, lines := collectLines(LogStreamStdout)
+
+	// Frames do runtime cortam linhas ao meio e juntam várias linhas num só pedaço
+	for _, frame := range []string{"pri", "meira\nsegun", "da\nterceira\nquar", "ta"} {
+		if n, err := w.Write([]byte(frame)); err != nil || n != len(frame) {
+			t.Fatalf("Write(%q) = %d, %v", frame, n, err)
+		}
+	}
+	if got := strings.Join(*lines, "|"); got != "stdout:primeira|stdout:segunda|stdout:terceira" {
+		t.Fatalf("linhas antes do flush: %q", got)
+	}
+
+	w.flush()
+	if got := (*lines)[len(*lines)-1]; got != "stdout:quarta" {
+		t.Fatalf("flush deveria entregar o resto sem quebra de linha, veio %q", got)
+	}
+	w.flush()
+	if len(*lines) != 4 {
+		t.Fatalf("flush com buffer vazio entregou linha extra: %q", *lines)
+	}
+}
+
+func TestLogLineWriterTruncatesLongLines(t *testing.T) {
+	w, lines := collectLines(LogStreamStderr)
+
+	long := strings.Repeat("x", maxLogLineBytes+500)
+	// Em dois pedaços: o corte vale para a linha inteira, não para cada Write
+	_, _ = w.Write([]byte(long[:maxLogLineBytes-10]))
+	_, _ = w.Write([]byte(long[maxLogLineBytes-10:] + "\ncurta\n"))
+
+	if len(*lines) != 2 {
+		t.Fatalf("esperado 2 linhas, veio %d", len(*lines))
+	}
+	want := "stderr:" + strings.Repeat("x", maxLogLineBytes) + " …(linha cortada)"
+	if (*lines)[0] != want {
+		t.Fatalf("linha longa não foi cortada em %d bytes (veio %d bytes)", maxLogLineBytes, len((*lines)[0]))
+	}
+	if (*lines)[1] != "stderr:curta" {
+		t.Fatalf("linha seguinte ao corte herdou a marca: %q", (*lines)[1])
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	stamp := "2024-05-01T10:20:30.123456789Z"
+	line := parseLogLine(LogStreamStdout, []byte(stamp+" servidor no ar\r"))
+	want, _ := time.Parse(time.RFC3339Nano, stamp)
+	if !line.Time.Equal(want) || line.Text != "servidor no ar" || line.Stream != LogStreamStdout {
+		t.Fatalf("linha inesperada: %+v", line)
+	}
+
+	// Sem timestamp a linha passa inteira, sem instante
+	line = parseLogLine(LogStreamStderr, []byte("erro: algo falhou"))
+	if !line.Time.IsZero() || line.Text != "erro: algo falhou" {
+		t.Fatalf("linha sem timestamp alterada: %+v", line)
+	}
+}
+
+func TestStreamAppLogsTailAndResume(t *testing.T) {
+	app, _ := deployTestApp(t, "streamer", models.PlanPro, "logs1")
+	rt := fakeRuntime(t)
+	for _, l := range []struct {
+		stderr bool
+		text   string
+	}{{false, "um"}, {true, "dois"}, {false, "tres"}} {
+		if err := rt.WriteLog(app.ContainerName, l.stderr, l.text); err != nil {
+			t.Fatalf("WriteLog: %v", err)
+		}
+	}
+	stream := func(opts LogStreamOptions) []LogLine {
+		t.Helper()
+		var got []LogLine
+		err := StreamAppLogs(context.Background(), app, opts, func(l LogLine) error {
+			got = append(got, l)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamAppLogs: %v", err)
+		}
+		return got
+	}
+	texts := func(lines []LogLine) string {
+		var parts []string
+		for _, l := range lines {
+			parts = append(parts, l.Stream+":"+l.Text)
+		}
+		return strings.Join(parts, "|")
+	}
+
+	all := stream(LogStreamOptions{Stdout: true, Stderr: true, Tail: -1})
+	if got := texts(all); got != "stdout:um|stderr:dois|stdout:tres" {
+		t.Fatalf("todas as linhas: %q", got)
+	}
+	if got := texts(stream(LogStreamOptions{Stdout: true, Stderr: true, Tail: 2})); got != "stderr:dois|stdout:tres" {
+		t.Fatalf("tail 2: %q", got)
+	}
+	if got := texts(stream(LogStreamOptions{Stdout: true, Tail: -1})); got != "stdout:um|stdout:tres" {
+		t.Fatalf("só stdout: %q", got)
+	}
+
+	// 🔁 Retomada: nada até o instante do último evento entregue, inclusive
+	if all[1].Time.IsZero() {
+		t.Fatalf("linha sem instante: o cursor de retomada depende do timestamp")
+	}
+	if got := texts(stream(LogStreamOptions{Stdout: true, Stderr: true, Tail: -1, After: all[1].Time})); got != "stdout:tres" {
+		t.Fatalf("retomada após %v: %q", all[1].Time, got)
+	}
+}
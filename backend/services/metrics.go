@@ -3,11 +3,10 @@
 package services
 
 import (
-	"os/exec"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"virtuscloud/backend/engine"
 	"virtuscloud/backend/models"
 )
 
@@ -42,26 +41,6 @@ func StartMetricsCache() {
 	}()
 }
 
-// 📐 Converte string como "512MiB", "1.2GiB" para MB
-func parseMemoryToMB(raw string) float32 {
-	raw = strings.TrimSpace(raw)
-	raw = strings.ReplaceAll(raw, " ", "")
-
-	if strings.HasSuffix(raw, "MiB") {
-		val := strings.TrimSuffix(raw, "MiB")
-		num, _ := strconv.ParseFloat(val, 32)
-		return float32(num)
-	}
-
-	if strings.HasSuffix(raw, "GiB") {
-		val := strings.TrimSuffix(raw, "GiB")
-		num, _ := strconv.ParseFloat(val, 32)
-		return float32(num * 1024)
-	}
-
-	return 0
-}
-
 func DetectPlanFromContainer(name string) string {
 	name = strings.ToLower(name)
 	switch {
@@ -81,28 +60,39 @@ func DetectPlanFromContainer(name string) string {
 }
 
 func updateMetricsCache() {
-	cmd := exec.Command("docker", "stats", "--no-stream", "--format", "{{.Name}}:{{.MemUsage}}")
-	out, err := cmd.CombinedOutput()
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+
+	containers, err := rt.List(ctx, engine.ListOptions{})
 	if err != nil {
 		return
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 	ramTotals := map[string]float32{}
 	ramCounts := map[string]int{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 
-	for _, line := range lines {
-		parts := strings.Split(line, ":")
-		if len(parts) != 2 {
-			continue
-		}
-		name := parts[0]
-		mem := parseMemoryToMB(parts[1]) // ✅ Agora em MB
-		plan := DetectPlanFromContainer(name)
+	for _, c := range containers {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			stats, err := rt.Stats(ctx, name)
+			if err != nil {
+				return
+			}
+			mem := float32(stats.MemoryUsageMB) // ✅ Agora em MB
+			plan := DetectPlanFromContainer(name)
 
-		ramTotals[plan] += mem
-		ramCounts[plan]++
+			mu.Lock()
+			ramTotals[plan] += mem
+			ramCounts[plan]++
+			mu.Unlock()
+		}(c.Name)
 	}
+	wg.Wait()
 
 	var result []PlanMetrics
 	for plan, total := range ramTotals {
@@ -0,0 +1,229 @@
+//backend/services/network.go
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/firewall"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 🕸️ Cada usuário tem uma rede bridge própria. O Docker isola redes bridge diferentes entre si, então
+// as aplicações de um usuário se enxergam (pelo nome, via DNS da rede) e as de outros usuários não.
+// A bridge padrão não entra nisso: lá todo container alcança todo container, por isso as aplicações saem dela.
+//
+// 🧱 Modelo de ameaça: o código das aplicações é hostil. O que cada peça garante:
+//   - outro usuário: redes bridge diferentes não trocam pacotes (regras do próprio Docker);
+//   - aplicações do mesmo usuário: se falam livremente (enable_icc fica ligado, é o que o DNS por nome serve);
+//   - o host (IP do gateway da bridge, API do Docker em porta TCP, backend rodando no host): só com
+//     NETWORK_FIREWALL=iptables, que descarta conexões novas vindas das interfaces vcu* (pacote firewall);
+//   - o container do backend (INGRESS_CONTAINER), que o ingress conecta a todas as redes de usuário para
+//     alcançar as aplicações: ele não repassa pacotes entre as redes (sem ip_forward no container), e com o
+//     firewall ligado cada IP dele nessas redes só aceita respostas às conexões do próprio ingress;
+//   - portas publicadas no host valem como acesso pela internet (não publique serviços internos).
+// As interfaces das redes recebem um nome fixo (vcu + hash) para as regras casarem com "vcu+"; redes
+// criadas antes disso mantêm o nome br-<id> até serem recriadas (a rede sai quando fica vazia).
+
+// 🔔 Chamados antes de remover a rede de um usuário (ex.: o ingress desconecta o próprio container)
+type NetworkReleaseFunc func(ctx context.Context, network string)
+
+var (
+	networkHooksMu sync.Mutex
+	networkHooks   []NetworkReleaseFunc
+)
+
+// 🧹 Remover a última aplicação ou a conta remove a rede
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		ReleaseUserNetwork(app.Username, false)
+	})
+	store.UserStore.OnDelete(func(user *models.User) {
+		ReleaseUserNetwork(user.Username, true)
+	})
+}
+
+// 🔔 Registra quem precisa sair da rede antes da remoção
+func OnUserNetworkRelease(fn NetworkReleaseFunc) {
+	networkHooksMu.Lock()
+	defer networkHooksMu.Unlock()
+	networkHooks = append(networkHooks, fn)
+}
+
+// 🕸️ Garante a rede Docker privada do usuário e devolve o nome dela
+func EnsureUserNetwork(username string) (string, error) {
+	network := utils.GetUserNetworkName(username)
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	labels := map[string]string{"virtuscloud.user": username}
+	options := map[string]string{"com.docker.network.bridge.name": firewall.BridgeName(network)}
+	if err := engine.Default().EnsureNetwork(ctx, network, labels, options); err != nil {
+		return "", fmt.Errorf("erro ao criar rede %s: %w", network, err)
+	}
+	return network, nil
+}
+
+// 🔤 Nome da aplicação como rótulo DNS ("Minha API" → "minha-api"); vazio se não sobrar nada
+func AppNetworkAlias(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	alias := strings.TrimRight(b.String(), "-")
+	if len(alias) > 63 {
+		alias = strings.TrimRight(alias[:63], "-")
+	}
+	return alias
+}
+
+// 🔤 Aliases da aplicação na rede do usuário: o nome (se nenhuma outra aplicação ou banco dele já usa) e o ID
+func appNetworkAliases(app *models.App) []string {
+	var aliases []string
+	if alias := AppNetworkAlias(app.Name); alias != "" {
+		taken := false
+		for _, db := range store.DatabaseStore.ListByUser(app.Username) {
+			if db.Username == app.Username && db.Name == alias {
+				taken = true // o banco é dono do nome desde que foi criado
+			}
+		}
+		for _, other := range store.AppStore.ListByUser(app.Username) {
+			if other.ID != app.ID && other.Username == app.Username && AppNetworkAlias(other.Name) == alias && other.ID < app.ID {
+				taken = true // nomes repetidos: fica com a aplicação de menor ID, as outras respondem só pelo ID
+				break
+			}
+		}
+		if !taken {
+			aliases = append(aliases, alias)
+		}
+	}
+	if id := AppNetworkAlias(app.ID); id != "" && (len(aliases) == 0 || aliases[0] != id) {
+		aliases = append(aliases, id)
+	}
+	return aliases
+}
+
+// 🧩 Rede do dono e aliases na especificação de criação
+func ApplyAppNetwork(spec *engine.Spec, app *models.App) error {
+	network, err := EnsureUserNetwork(app.Username)
+	if err != nil {
+		return err
+	}
+	spec.NetworkMode = network
+	spec.NetworkAliases = appNetworkAliases(app)
+	return nil
+}
+
+// 🚧 Deixa o container só na rede do dono: conecta com os aliases e sai da bridge padrão e de qualquer
+// outra rede (containers criados antes das redes por usuário continuam na bridge até passar por aqui)
+func IsolateAppContainer(app *models.App) error {
+	if app.ContainerName == "" {
+		return nil
+	}
+	rt := engine.Default()
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	info, err := rt.Inspect(ctx, app.ContainerName)
+	if engine.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao inspecionar container: %w", err)
+	}
+	network, err := EnsureUserNetwork(app.Username)
+	if err != nil {
+		return err
+	}
+	if _, ok := info.Networks[network]; !ok {
+		if err := rt.Connect(ctx, network, app.ContainerName, appNetworkAliases(app)); err != nil {
+			return fmt.Errorf("erro ao conectar %s à rede %s: %w", app.ContainerName, network, err)
+		}
+		Log(app.ID, app.Username, app.Plan, fmt.Sprintf("🕸️ Aplicação conectada à rede privada %s", network))
+	}
+	for name := range info.Networks {
+		if name == network {
+			continue
+		}
+		if err := rt.Disconnect(ctx, name, app.ContainerName); err != nil && !engine.IsNotFound(err) {
+			return fmt.Errorf("erro ao desconectar %s da rede %s: %w", app.ContainerName, name, err)
+		}
+		log.Printf("🚧 %s desconectado da rede %s (isolamento entre usuários)", app.ContainerName, name)
+	}
+	return nil
+}
+
+// 🚧 Passa todas as aplicações pelo isolamento (na subida do backend)
+func IsolateAppNetworks() {
+	for _, app := range store.AppStore.List() {
+		if err := IsolateAppContainer(app); err != nil {
+			log.Printf("⚠️ Erro ao isolar a rede de %s: %v", app.ID, err)
+		}
+	}
+}
+
+// 🧹 Remove a rede do usuário quando ele não tem mais aplicações nem bancos (force = conta removida: desconecta o que sobrou)
+func ReleaseUserNetwork(username string, force bool) {
+	remaining := 0
+	for _, app := range store.AppStore.ListByUser(username) {
+		if app.Username == username {
+			remaining++
+		}
+	}
+	for _, db := range store.DatabaseStore.ListByUser(username) {
+		if db.Username == username {
+			remaining++
+		}
+	}
+	if remaining > 0 && !force {
+		return
+	}
+
+	network := utils.GetUserNetworkName(username)
+	rt := engine.Default()
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	networkHooksMu.Lock()
+	hooks := append([]NetworkReleaseFunc(nil), networkHooks...)
+	networkHooksMu.Unlock()
+	for _, hook := range hooks {
+		hook(ctx, network)
+	}
+	if force {
+		for _, app := range store.AppStore.ListByUser(username) {
+			if app.Username == username && app.ContainerName != "" {
+				_ = rt.Disconnect(ctx, network, app.ContainerName)
+			}
+		}
+		for _, db := range store.DatabaseStore.ListByUser(username) {
+			if db.Username == username {
+				_ = rt.Disconnect(ctx, network, db.ContainerName)
+			}
+		}
+	}
+
+	if err := rt.RemoveNetwork(ctx, network); err != nil {
+		if !engine.IsNotFound(err) {
+			log.Printf("⚠️ Erro ao remover a rede %s: %v", network, err)
+		}
+		return
+	}
+	log.Printf("🧹 Rede %s do usuário %s removida", network, username)
+}
@@ -0,0 +1,25 @@
+//backend/services/network_test.go
+
+package services
+
+import (
+	"testing"
+
+	"virtuscloud/backend/firewall"
+	"virtuscloud/backend/utils"
+)
+
+func TestEnsureUserNetworkNamesBridgeForFirewall(t *testing.T) {
+	network, err := EnsureUserNetwork("netowner")
+	if err != nil {
+		t.Fatalf("EnsureUserNetwork: %v", err)
+	}
+	if network != utils.GetUserNetworkName("netowner") {
+		t.Fatalf("rede = %q", network)
+	}
+	// 🧱 A interface cai em "vcu+", casada pelas regras do firewall
+	options := fakeRuntime(t).NetworkOptions(network)
+	if got := options["com.docker.network.bridge.name"]; got != firewall.BridgeName(network) {
+		t.Fatalf("bridge da rede = %q, esperado %q", got, firewall.BridgeName(network))
+	}
+}
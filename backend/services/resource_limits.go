@@ -0,0 +1,87 @@
+//backend/services/resource_limits.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 🔔 Troca de plano reaplica os limites nos containers existentes do usuário
+func init() {
+	store.UserStore.OnPlanChange(func(username string, from, to models.PlanType) {
+		go func() {
+			log.Printf("⚙️ Plano de %s alterado (%s → %s): reaplicando limites dos containers", username, from, to)
+			if err := limits.FitAllocationsToPlan(username); err != nil {
+				log.Printf("⚠️ Erro ao ajustar alocações de %s: %v", username, err)
+			}
+			if err := ReapplyUserLimits(username); err != nil {
+				log.Printf("⚠️ Erro ao reaplicar limites de %s: %v", username, err)
+			}
+		}()
+	})
+}
+
+// ⚙️ Atualiza RAM, CPU, PIDs e peso de I/O de todos os containers do usuário conforme o plano atual
+// e a fatia alocada a cada aplicação. O ulimit nofile só muda quando o container é recriado (rebuild).
+// Um container com erro não impede os demais: as falhas voltam juntas no fim.
+func ReapplyUserLimits(username string) error {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+
+	containers, err := rt.List(ctx, engine.ListOptions{
+		All:    true,
+		Labels: map[string]string{"username": username},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao listar containers: %w", err)
+	}
+
+	var errs []error
+	for _, c := range containers {
+		resources, err := limits.AppContainerResources(username, c.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+			continue
+		}
+		if err := rt.Update(ctx, c.Name, engine.Update{Resources: &resources}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+			continue
+		}
+		log.Printf("✅ Limites aplicados em %s: %dMB | %.2f vCPU | %d PIDs", c.Name, resources.MemoryMB, resources.CPUs, resources.PidsLimit)
+	}
+	return errors.Join(errs...)
+}
+
+// 📐 Aplica ao container a fatia alocada à aplicação (após redimensionamento)
+func ApplyAppAllocation(app *models.App) error {
+	resources, err := limits.AppContainerResources(app.Username, app.ID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+
+	containerName := utils.GetContainerName(app.Username, app.ID)
+	if app.ContainerName != "" {
+		containerName = app.ContainerName
+	}
+	err = engine.Default().Update(ctx, containerName, engine.Update{Resources: &resources})
+	if engine.IsNotFound(err) {
+		return nil // sem container: a fatia vale na próxima criação
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao aplicar limites em %s: %w", containerName, err)
+	}
+	log.Printf("📐 Fatia aplicada em %s: %dMB | %.2f vCPU", containerName, resources.MemoryMB, resources.CPUs)
+	return nil
+}
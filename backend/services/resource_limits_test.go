@@ -0,0 +1,52 @@
+//backend/services/resource_limits_test.go
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+func TestReapplyUserLimitsUpdatesEveryContainer(t *testing.T) {
+	newTestUser(t, "limited", models.PlanBasic)
+	rt := fakeRuntime(t)
+	ctx := context.Background()
+	rt.AddImage("limited-img")
+
+	names := []string{"limited-a", "limited-b"}
+	for _, name := range names {
+		resources, err := limits.AppContainerResources("limited", name)
+		if err != nil {
+			t.Fatalf("AppContainerResources: %v", err)
+		}
+		spec := engine.Spec{Name: name, Image: "limited-img", Labels: map[string]string{"username": "limited"}, Resources: resources}
+		if _, err := rt.Create(ctx, spec); err != nil {
+			t.Fatalf("erro ao criar %s: %v", name, err)
+		}
+	}
+	basic := models.Plans[models.PlanBasic]
+
+	if _, err := store.UserStore.Update("limited", func(u *models.User) { u.Plan = models.PlanPremium }); err != nil {
+		t.Fatalf("erro ao trocar plano: %v", err)
+	}
+	if err := ReapplyUserLimits("limited"); err != nil {
+		t.Fatalf("ReapplyUserLimits: %v", err)
+	}
+
+	premium := models.Plans[models.PlanPremium]
+	for _, name := range names {
+		info := inspect(t, name)
+		if info.Resources.PidsLimit != premium.PidsLimit || info.Resources.BlkioWeight != premium.BlkioWeight {
+			t.Fatalf("%s: PIDs %d / I/O %d, esperado %d / %d", name, info.Resources.PidsLimit, info.Resources.BlkioWeight, premium.PidsLimit, premium.BlkioWeight)
+		}
+		// 📂 nofile fica o da criação até o container ser recriado
+		if info.Resources.NoFile != basic.NoFileLimit {
+			t.Fatalf("%s: nofile %d, esperado o da criação (%d)", name, info.Resources.NoFile, basic.NoFileLimit)
+		}
+	}
+}
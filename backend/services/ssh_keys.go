@@ -0,0 +1,150 @@
+//backend/services/ssh_keys.go
+
+package services
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🔢 Chaves SSH por usuário e tamanho mínimo das chaves RSA
+const (
+	maxSSHKeys = 10
+	minRSABits = 2048
+)
+
+// ❗ Erros das chaves SSH (o handler escolhe o status HTTP com errors.Is)
+var (
+	ErrSSHKeyInvalid  = errors.New("chave SSH inválida")
+	ErrSSHKeyExists   = errors.New("chave SSH já registrada")
+	ErrSSHKeyLimit    = errors.New("limite de chaves SSH atingido")
+	ErrSSHKeyNotFound = errors.New("chave SSH não encontrada")
+)
+
+// 📋 Chaves registradas do usuário
+func ListSSHKeys(username string) ([]models.SSHKey, error) {
+	user, ok := store.UserStore.Get(username)
+	if !ok {
+		return nil, fmt.Errorf("usuário %s não encontrado", username)
+	}
+	if user.SSHKeys == nil {
+		return []models.SSHKey{}, nil
+	}
+	return user.SSHKeys, nil
+}
+
+// ➕ Registra uma chave no formato authorized_keys; sem nome, usa o comentário da linha
+func AddSSHKey(username, name, line string) (*models.SSHKey, error) {
+	key, comment, err := parseAuthorizedKey(line)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSHKeyInvalid, err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = comment
+	}
+	if name == "" {
+		name = key.Type()
+	}
+	if len(name) > 100 {
+		return nil, fmt.Errorf("%w: nome com mais de 100 caracteres", ErrSSHKeyInvalid)
+	}
+
+	added := models.SSHKey{Name: name, PublicKey: authorizedKey(key), Fingerprint: ssh.FingerprintSHA256(key), CreatedAt: time.Now().UTC()}
+	var refused error
+	_, err = store.UserStore.Update(username, func(user *models.User) {
+		if len(user.SSHKeys) >= maxSSHKeys {
+			refused = fmt.Errorf("%w: no máximo %d chaves por usuário", ErrSSHKeyLimit, maxSSHKeys)
+			return
+		}
+		for _, existing := range user.SSHKeys {
+			if existing.Fingerprint == added.Fingerprint {
+				refused = fmt.Errorf("%w: %s (%s)", ErrSSHKeyExists, existing.Name, existing.Fingerprint)
+				return
+			}
+		}
+		user.SSHKeys = append(user.SSHKeys, added)
+	})
+	if refused != nil {
+		return nil, refused
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar chave SSH: %w", err)
+	}
+	return &added, nil
+}
+
+// 🗑️ Remove a chave pela impressão digital
+func RemoveSSHKey(username, fingerprint string) (*models.SSHKey, error) {
+	var removed *models.SSHKey
+	_, err := store.UserStore.Update(username, func(user *models.User) {
+		for i, key := range user.SSHKeys {
+			if key.Fingerprint == fingerprint {
+				k := key
+				removed = &k
+				user.SSHKeys = append(user.SSHKeys[:i:i], user.SSHKeys[i+1:]...)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao remover chave SSH: %w", err)
+	}
+	if removed == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSSHKeyNotFound, fingerprint)
+	}
+	return removed, nil
+}
+
+// 🔐 A chave pertence ao usuário? (autenticação do SFTP)
+func HasSSHKey(username string, key ssh.PublicKey) bool {
+	user, ok := store.UserStore.Get(username)
+	if !ok {
+		return false
+	}
+	fingerprint := ssh.FingerprintSHA256(key)
+	for _, k := range user.SSHKeys {
+		if k.Fingerprint == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// 📄 Lê uma linha no formato authorized_keys ("tipo base64 [comentário]"); opções e certificados não são aceitos
+func parseAuthorizedKey(line string) (ssh.PublicKey, string, error) {
+	key, comment, options, rest, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(line)))
+	if err != nil {
+		return nil, "", errors.New("use o formato 'tipo base64 [comentário]'")
+	}
+	if len(options) > 0 {
+		return nil, "", errors.New("opções do authorized_keys não são aceitas")
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, "", errors.New("envie uma chave por vez")
+	}
+	switch key.Type() {
+	case ssh.KeyAlgoED25519, ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521:
+	case ssh.KeyAlgoRSA:
+		if rsaKey, ok := key.(ssh.CryptoPublicKey).CryptoPublicKey().(*rsa.PublicKey); !ok || rsaKey.N.BitLen() < minRSABits {
+			return nil, "", fmt.Errorf("rsa com menos de %d bits", minRSABits)
+		}
+	default:
+		return nil, "", fmt.Errorf("tipo '%s' não suportado (use ed25519, ecdsa ou rsa)", key.Type())
+	}
+	return key, comment, nil
+}
+
+// 🧾 Linha authorized_keys sem comentário
+func authorizedKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
@@ -0,0 +1,72 @@
+//backend/services/ssh_keys_test.go
+
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"virtuscloud/backend/models"
+)
+
+func authorizedLine(t *testing.T, key interface{}) string {
+	t.Helper()
+	pub, err := ssh.NewPublicKey(key)
+	if err != nil {
+		t.Fatalf("erro ao montar chave: %v", err)
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+}
+
+func TestAddSSHKeyStoresFingerprintAndMatches(t *testing.T) {
+	newTestUser(t, "keyowner", models.PlanPro)
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave: %v", err)
+	}
+	line := authorizedLine(t, pub)
+
+	added, err := AddSSHKey("keyowner", "", line+" notebook")
+	if err != nil {
+		t.Fatalf("AddSSHKey: %v", err)
+	}
+	key, _ := ssh.NewPublicKey(pub)
+	if added.Name != "notebook" || added.PublicKey != line || added.Fingerprint != ssh.FingerprintSHA256(key) {
+		t.Fatalf("chave salva inesperada: %+v", added)
+	}
+	if !HasSSHKey("keyowner", key) || HasSSHKey("outro", key) {
+		t.Fatalf("HasSSHKey não reconhece só o dono")
+	}
+	if _, err := AddSSHKey("keyowner", "de novo", line); !errors.Is(err, ErrSSHKeyExists) {
+		t.Fatalf("chave repetida = %v, esperado ErrSSHKeyExists", err)
+	}
+}
+
+func TestAddSSHKeyRejectsOptionsAndWeakKeys(t *testing.T) {
+	newTestUser(t, "keyrules", models.PlanPro)
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave: %v", err)
+	}
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave rsa: %v", err)
+	}
+
+	for name, line := range map[string]string{
+		"opções":      `command="/bin/sh" ` + authorizedLine(t, pub),
+		"rsa fraca":   authorizedLine(t, &weak.PublicKey),
+		"lixo":        "ssh-ed25519 não-é-base64",
+		"duas chaves": authorizedLine(t, pub) + "\n" + authorizedLine(t, pub),
+	} {
+		if _, err := AddSSHKey("keyrules", "", line); !errors.Is(err, ErrSSHKeyInvalid) {
+			t.Fatalf("%s: %v, esperado ErrSSHKeyInvalid", name, err)
+		}
+	}
+}
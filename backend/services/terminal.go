@@ -0,0 +1,193 @@
+//backend/services/terminal.go
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// ⏱️ Terminal sem entrada do usuário por esse tempo é encerrado (TERMINAL_IDLE_MINUTES)
+const defaultTerminalIdle = 15 * time.Minute
+
+// 🐚 bash quando a imagem tiver, senão sh
+var terminalShell = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash -l; else exec sh; fi"}
+
+// ❗ Erros da abertura do terminal (o handler escolhe o status HTTP com errors.Is)
+var (
+	ErrTerminalNotAllowed = errors.New("terminal não permitido pelo plano")
+	ErrTerminalNotRunning = errors.New("aplicação não está em execução")
+)
+
+// 🖥️ Sessão de terminal aberta
+type TerminalSession struct {
+	ID        string    `json:"id"`
+	AppID     string    `json:"appId"`
+	Username  string    `json:"username"`
+	Container string    `json:"container"`
+	RemoteIP  string    `json:"remoteIp,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// 🖥️ Terminal interativo no container de uma aplicação
+type Terminal struct {
+	Session   TerminalSession
+	tty       engine.TTY
+	closeOnce sync.Once
+}
+
+// 📋 Terminais abertos (contam para o limite do plano)
+var (
+	terminalsMu sync.Mutex
+	terminals   = map[string]*Terminal{}
+)
+
+// 🧹 Remover a aplicação derruba os terminais abertos nela
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		terminalsMu.Lock()
+		var open []*Terminal
+		for _, t := range terminals {
+			if t.Session.AppID == app.ID {
+				open = append(open, t)
+			}
+		}
+		terminalsMu.Unlock()
+		for _, t := range open {
+			t.Close()
+		}
+	})
+}
+
+// ⏱️ Inatividade máxima configurada
+func TerminalIdleTimeout() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("TERMINAL_IDLE_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultTerminalIdle
+}
+
+// 🖥️ Abre um shell com TTY no container da aplicação, dentro do limite de sessões simultâneas do plano
+func OpenTerminal(app *models.App, remoteIP string, rows, cols int) (*Terminal, error) {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	t := &Terminal{Session: TerminalSession{
+		ID:        fmt.Sprintf("term-%x-%s", time.Now().UnixNano(), hex.EncodeToString(suffix)),
+		AppID:     app.ID,
+		Username:  app.Username,
+		Container: app.ContainerName,
+		RemoteIP:  remoteIP,
+		StartedAt: time.Now(),
+	}}
+
+	// A vaga é reservada sob o lock: aberturas simultâneas não furam o limite
+	terminalsMu.Lock()
+	active := 0
+	for _, open := range terminals {
+		if open.Session.Username == app.Username {
+			active++
+		}
+	}
+	if err := limits.CanOpenTerminal(app.Username, active); err != nil {
+		terminalsMu.Unlock()
+		return nil, fmt.Errorf("%w: %v", ErrTerminalNotAllowed, err)
+	}
+	terminals[t.Session.ID] = t
+	terminalsMu.Unlock()
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	info, err := engine.Default().Inspect(ctx, app.ContainerName)
+	if err == nil && !info.Running {
+		err = ErrTerminalNotRunning
+	} else if engine.IsNotFound(err) {
+		err = fmt.Errorf("%w: container não encontrado", ErrTerminalNotRunning)
+	}
+	if err == nil {
+		t.tty, err = engine.Default().ExecTTY(ctx, app.ContainerName, engine.TTYOptions{
+			Cmd:  terminalShell,
+			Env:  []string{"TERM=xterm-256color"},
+			Rows: rows,
+			Cols: cols,
+		})
+	}
+	if err != nil {
+		t.release()
+		if errors.Is(err, ErrTerminalNotRunning) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao abrir terminal em %s: %w", app.ContainerName, err)
+	}
+
+	log.Printf("🖥️ Terminal %s aberto por %s em %s", t.Session.ID, app.Username, app.ContainerName)
+	return t, nil
+}
+
+// 📥 Saída do terminal (termina quando o shell sai)
+func (t *Terminal) Read(p []byte) (int, error) {
+	return t.tty.Read(p)
+}
+
+// ⌨️ Entrada do teclado
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.tty.Write(p)
+}
+
+// 📐 Novo tamanho da janela
+func (t *Terminal) Resize(rows, cols int) error {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	return t.tty.Resize(ctx, rows, cols)
+}
+
+// 🏁 Código de saída do shell (depois que a saída terminou)
+func (t *Terminal) ExitCode() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return t.tty.ExitCode(ctx)
+}
+
+// 🚪 Encerra a sessão e libera a vaga do plano
+func (t *Terminal) Close() {
+	t.closeOnce.Do(func() {
+		if t.tty != nil {
+			_ = t.tty.Close()
+		}
+		t.release()
+		log.Printf("🖥️ Terminal %s encerrado (%s)", t.Session.ID, time.Since(t.Session.StartedAt).Round(time.Second))
+	})
+}
+
+func (t *Terminal) release() {
+	terminalsMu.Lock()
+	delete(terminals, t.Session.ID)
+	terminalsMu.Unlock()
+}
+
+// 📋 Terminais abertos do usuário, dos mais antigos aos mais recentes
+func ListTerminals(username string) []TerminalSession {
+	terminalsMu.Lock()
+	defer terminalsMu.Unlock()
+	sessions := []TerminalSession{}
+	for _, t := range terminals {
+		if t.Session.Username == username {
+			sessions = append(sessions, t.Session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartedAt.Before(sessions[j].StartedAt) })
+	return sessions
+}
@@ -0,0 +1,119 @@
+//backend/services/terminal_test.go
+
+package services
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+func TestOpenTerminalRespectsPlanSessionLimit(t *testing.T) {
+	app, _ := deployTestApp(t, "termuser", models.PlanPro, "term1") // plano pro: 2 terminais
+	other, _ := deployTestApp(t, "termother", models.PlanPro, "term2")
+
+	first, err := OpenTerminal(app, "10.0.0.1", 24, 80)
+	if err != nil {
+		t.Fatalf("primeiro terminal: %v", err)
+	}
+	second, err := OpenTerminal(app, "10.0.0.1", 24, 80)
+	if err != nil {
+		t.Fatalf("segundo terminal: %v", err)
+	}
+	defer second.Close()
+	if _, err := OpenTerminal(app, "10.0.0.1", 24, 80); !errors.Is(err, ErrTerminalNotAllowed) {
+		t.Fatalf("terceiro terminal deveria passar do limite do plano, veio %v", err)
+	}
+	if n := len(ListTerminals("termuser")); n != 2 {
+		t.Fatalf("%d terminais listados, esperado 2", n)
+	}
+
+	// 👥 O limite é por usuário: outro usuário abre normalmente
+	third, err := OpenTerminal(other, "10.0.0.2", 24, 80)
+	if err != nil {
+		t.Fatalf("terminal de outro usuário: %v", err)
+	}
+	defer third.Close()
+
+	// 🚪 Fechar libera a vaga
+	first.Close()
+	first.Close() // idempotente
+	again, err := OpenTerminal(app, "10.0.0.1", 24, 80)
+	if err != nil {
+		t.Fatalf("terminal após liberar vaga: %v", err)
+	}
+	again.Close()
+}
+
+func TestOpenTerminalWithoutPlanTerminal(t *testing.T) {
+	if err := store.UserStore.Save(&models.User{Username: "noterm", Plan: models.PlanNothing}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+	app := &models.App{ID: "noterm1", Username: "noterm", ContainerName: "noterm-noterm1"}
+	if _, err := OpenTerminal(app, "", 24, 80); !errors.Is(err, ErrTerminalNotAllowed) {
+		t.Fatalf("plano sem terminal deveria recusar, veio %v", err)
+	}
+}
+
+func TestOpenTerminalOnStoppedAppReleasesSlot(t *testing.T) {
+	app, _ := deployTestApp(t, "termstop", models.PlanBasic, "ts1") // plano basic: 1 terminal
+	if err := StopApp(app.ID, "termstop"); err != nil {
+		t.Fatalf("StopApp: %v", err)
+	}
+	if _, err := OpenTerminal(app, "", 24, 80); !errors.Is(err, ErrTerminalNotRunning) {
+		t.Fatalf("terminal em aplicação parada: %v", err)
+	}
+	if n := len(ListTerminals("termstop")); n != 0 {
+		t.Fatalf("falha ao abrir deixou %d vaga(s) ocupada(s)", n)
+	}
+
+	if err := StartApp(app.ID, "termstop"); err != nil {
+		t.Fatalf("StartApp: %v", err)
+	}
+	term, err := OpenTerminal(app, "", 24, 80)
+	if err != nil {
+		t.Fatalf("terminal após iniciar a aplicação: %v", err)
+	}
+	term.Close()
+}
+
+func TestTerminalRelaysInputAndExitCode(t *testing.T) {
+	app, _ := deployTestApp(t, "termio", models.PlanPro, "tio1")
+	term, err := OpenTerminal(app, "", 24, 80)
+	if err != nil {
+		t.Fatalf("OpenTerminal: %v", err)
+	}
+	defer term.Close()
+
+	// O shell simulado ecoa a entrada
+	go func() {
+		_, _ = term.Write([]byte("ls -la\n"))
+	}()
+	buf := make([]byte, len("ls -la\n"))
+	if _, err := io.ReadFull(term, buf); err != nil || string(buf) != "ls -la\n" {
+		t.Fatalf("eco do terminal = %q, %v", buf, err)
+	}
+	if err := term.Resize(40, 120); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	term.Close()
+	if code, err := term.ExitCode(); err != nil || code != 0 {
+		t.Fatalf("código de saída = %d, %v", code, err)
+	}
+}
+
+func TestDeletingAppClosesTerminals(t *testing.T) {
+	app, _ := deployTestApp(t, "termdel", models.PlanPro, "td1")
+	if _, err := OpenTerminal(app, "", 24, 80); err != nil {
+		t.Fatalf("OpenTerminal: %v", err)
+	}
+	if err := store.AppStore.Delete(app.ID); err != nil {
+		t.Fatalf("erro ao remover aplicação: %v", err)
+	}
+	if n := len(ListTerminals("termdel")); n != 0 {
+		t.Fatalf("%d terminal(is) aberto(s) após remover a aplicação", n)
+	}
+}
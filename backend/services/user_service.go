@@ -3,7 +3,6 @@
 package services
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -21,10 +20,8 @@ import (
 // 👤 Cria novo usuário
 func CreateUser(username, email string, plan models.PlanType) (*models.User, error) {
 	// Verifica se o usuário já existe
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			return nil, errors.New("usuário já existe")
-		}
+	if _, exists := store.UserStore.FindByEmail(email); exists {
+		return nil, errors.New("usuário já existe")
 	}
 
 	// Cria struct do usuário
@@ -36,7 +33,9 @@ func CreateUser(username, email string, plan models.PlanType) (*models.User, err
 	}
 
 	// Armazena no mapa com chave por username
-	store.UserStore[username] = user
+	if err := store.UserStore.Save(user); err != nil {
+		return nil, fmt.Errorf("erro ao salvar usuário: %w", err)
+	}
 
 	// Sincroniza com armazenamento secundário
 	store.SyncUserToStore(user.Username, user.Email, user.Plan, "") // 🔁 ID removido
@@ -62,15 +61,14 @@ func AuthenticateUserByCode(email, code string) (*models.User, error) {
 	//	delete(LastSentMap, email) // ✅ limpa tempo após login
 
 	// Busca usuário pelo email
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			if err := createUserBaseDirs(u.Username, u.Plan); err != nil {
-				log.Printf("❌ Erro ao criar pastas base para %s: %v", u.Username, err)
-			}
-			return u, nil
-		}
+	u, ok := store.UserStore.FindByEmail(email)
+	if !ok {
+		return nil, errors.New("usuário não encontrado")
 	}
-	return nil, errors.New("usuário não encontrado")
+	if err := createUserBaseDirs(u.Username, u.Plan); err != nil {
+		log.Printf("❌ Erro ao criar pastas base para %s: %v", u.Username, err)
+	}
+	return u, nil
 }
 
 //func AuthenticateUserByCode(email, code string) (*models.User, error) {
@@ -99,13 +97,12 @@ func AuthenticateUserByCode(email, code string) (*models.User, error) {
 
 // ✏️ Atualiza nome do usuário
 func UpdateUserName(email, newName string) error {
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			u.Name = newName
-			return nil
-		}
+	if _, err := store.UserStore.UpdateByEmail(email, func(u *models.User) {
+		u.Name = newName
+	}); err != nil {
+		return errors.New("usuário não encontrado")
 	}
-	return errors.New("usuário não encontrado")
+	return nil
 }
 
 // 📦 Migra todas as aplicações de qualquer plano antigo para o diretório do plano atual.
@@ -692,39 +689,35 @@ func RemoveOldPlanDir(username string, oldPlan models.PlanType) error {
 
 // 🔄 Atualiza plano do usuário
 func UpdateUserPlan(email string, newPlan models.PlanType) error {
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			if u.Plan != newPlan {
-				// 📦 Migra todas as aplicações do usuário para o diretório correspondente ao novo plano.
-				// Essa função escaneia todas as pastas de plano existentes e realoca os apps para
-				// storage/users/{username}/{newPlan}/apps/, garantindo consistência com o plano ativo.
-				err := MigrateAllUserAppsToNewPlan(u.Username, newPlan)
-				if err != nil {
-					log.Println("Erro ao migrar aplicações:", err)
-				}
-			}
-
-			u.Plan = newPlan
-
-			// Cria diretórios para o novo plano
-			if err := createUserBaseDirs(u.Username, newPlan); err != nil {
-				return fmt.Errorf("erro ao criar diretórios: %w", err)
-			}
+	u, ok := store.UserStore.FindByEmail(email)
+	if !ok {
+		return errors.New("usuário não encontrado")
+	}
 
-			// Sincroniza com armazenamento secundário
-			store.SyncUserToStore(u.Username, u.Email, newPlan, "") // 🔁 ID removido
-			return nil
+	if u.Plan != newPlan {
+		// 📦 Migra todas as aplicações do usuário para o diretório correspondente ao novo plano.
+		// Essa função escaneia todas as pastas de plano existentes e realoca os apps para
+		// storage/users/{username}/{newPlan}/apps/, garantindo consistência com o plano ativo.
+		err := MigrateAllUserAppsToNewPlan(u.Username, newPlan)
+		if err != nil {
+			log.Println("Erro ao migrar aplicações:", err)
 		}
 	}
-	return errors.New("usuário não encontrado")
+
+	// Cria diretórios para o novo plano
+	if err := createUserBaseDirs(u.Username, newPlan); err != nil {
+		return fmt.Errorf("erro ao criar diretórios: %w", err)
+	}
+
+	// Sincroniza com armazenamento secundário
+	store.SyncUserToStore(u.Username, u.Email, newPlan, "") // 🔁 ID removido
+	return nil
 }
 
 // 🔍 Busca usuário por email
 func GetUserByEmail(email string) (*models.User, error) {
-	for _, u := range store.UserStore {
-		if u.Email == email {
-			return u, nil
-		}
+	if u, ok := store.UserStore.FindByEmail(email); ok {
+		return u, nil
 	}
 	return nil, errors.New("usuário não encontrado")
 }
@@ -739,19 +732,10 @@ func GetUserByEmail(email string) (*models.User, error) {
 //	}
 
 func LoadAllUsers() map[string]models.User {
-	file, err := os.Open("./database/users.json")
-	if err != nil {
-		// Se não conseguir abrir, retorna mapa vazio
-		return map[string]models.User{}
+	users := make(map[string]models.User)
+	for username, u := range store.UserStore.Snapshot() {
+		users[username] = *u
 	}
-	defer file.Close()
-
-	var users map[string]models.User
-	if err := json.NewDecoder(file).Decode(&users); err != nil {
-		// Se falhar ao decodificar, retorna mapa vazio
-		return map[string]models.User{}
-	}
-
 	return users
 }
 
@@ -0,0 +1,182 @@
+//backend/sftp/fs.go
+
+package sftp
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"virtuscloud/backend/files"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+)
+
+// 🗂️ Árvore vista pelo usuário (a raiz "/" é a área dele; nada fora dela é alcançável):
+//
+//	/<app>/source/...          código da aplicação (snapshot .zip; vale no próximo rebuild)
+//	/<app>/volumes/<nome>/...  volume persistente (vale na hora; o container já vê)
+//
+// Cada operação volta ao store: aplicação removida ou transferida some da sessão na hora.
+const (
+	dirSource  = "source"
+	dirVolumes = "volumes"
+)
+
+// 🎯 Caminho resolvido: um diretório virtual (raiz, aplicação, volumes) ou um arquivo dentro de uma raiz do files
+type target struct {
+	path     string // caminho virtual limpo ("/app/source/main.go")
+	app      *models.App
+	rootName string
+	root     files.Root
+	rel      string        // caminho relativo à raiz do files
+	children []files.Entry // diretório virtual: o conteúdo
+	virtual  bool
+}
+
+// 🧭 Caminho absoluto e limpo; ".." para no "/" (a árvore é virtual, não há o que escapar)
+func cleanPath(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return path.Clean(p)
+}
+
+// 🔐 Aplicação do usuário (mesma regra de StartApp e afins: existe e pertence a ele)
+func ownedApp(username, id string) (*models.App, error) {
+	app, _ := store.AppStore.Get(id)
+	if app == nil || app.Username != username {
+		return nil, fmt.Errorf("%w: aplicação %s", files.ErrNotFound, id)
+	}
+	return app, nil
+}
+
+func virtualDir(name string) files.Entry {
+	return files.Entry{Name: name, Path: name, Type: files.TypeDir}
+}
+
+// 🧭 Resolve o caminho virtual do usuário
+func resolve(username, p string) (*target, error) {
+	t := &target{path: cleanPath(p)}
+	parts := strings.Split(strings.TrimPrefix(t.path, "/"), "/")
+	if parts[0] == "" {
+		t.virtual = true
+		for _, app := range store.AppStore.ListByUser(username) {
+			if app.Username == username {
+				t.children = append(t.children, virtualDir(app.ID))
+			}
+		}
+		sort.Slice(t.children, func(i, j int) bool { return t.children[i].Name < t.children[j].Name })
+		return t, nil
+	}
+
+	app, err := ownedApp(username, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	t.app = app
+	if len(parts) == 1 {
+		t.virtual = true
+		for _, root := range files.Roots(app) {
+			if root.Name == files.RootSource && root.Available {
+				t.children = append(t.children, virtualDir(dirSource))
+			}
+		}
+		t.children = append(t.children, virtualDir(dirVolumes))
+		return t, nil
+	}
+
+	rest := parts[2:]
+	switch parts[1] {
+	case dirSource:
+		t.rootName = files.RootSource
+	case dirVolumes:
+		if len(parts) == 2 {
+			t.virtual = true
+			for _, v := range store.VolumeStore.ListByApp(app.ID) {
+				t.children = append(t.children, virtualDir(v.Name))
+			}
+			sort.Slice(t.children, func(i, j int) bool { return t.children[i].Name < t.children[j].Name })
+			return t, nil
+		}
+		t.rootName = "volume:" + parts[2]
+		rest = parts[3:]
+	default:
+		return nil, fmt.Errorf("%w: %s", files.ErrNotFound, t.path)
+	}
+
+	if t.rel, err = files.CleanPath(strings.Join(rest, "/")); err != nil {
+		return nil, err
+	}
+	if t.root, err = files.Open(app, t.rootName); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// 📋 Entrada como o cliente vê (permissões fixas: o dono real dos arquivos é o container).
+// Diretórios virtuais e entradas do .zip sem data aparecem com a data zero do Unix.
+type fileInfo struct {
+	entry files.Entry
+}
+
+func (fi fileInfo) Name() string     { return fi.entry.Name }
+func (fi fileInfo) IsDir() bool      { return fi.entry.Type == files.TypeDir }
+func (fi fileInfo) Sys() interface{} { return nil }
+
+func (fi fileInfo) Size() int64 {
+	if fi.entry.Type != files.TypeFile {
+		return 0
+	}
+	return fi.entry.Size
+}
+
+func (fi fileInfo) Mode() fs.FileMode {
+	switch fi.entry.Type {
+	case files.TypeDir:
+		return fs.ModeDir | 0o755
+	case files.TypeSymlink:
+		return fs.ModeSymlink | 0o777
+	}
+	return 0o644
+}
+
+func (fi fileInfo) ModTime() time.Time {
+	if fi.entry.ModTime != nil && fi.entry.ModTime.Year() > 1980 {
+		return *fi.entry.ModTime
+	}
+	return time.Unix(0, 0)
+}
+
+// 📋 Atributos do próprio alvo
+func (t *target) stat() (*files.Entry, error) {
+	if t.virtual {
+		entry := virtualDir(path.Base(t.path))
+		return &entry, nil
+	}
+	return t.root.Stat(t.rel)
+}
+
+// 📂 Conteúdo do diretório
+func (t *target) list() ([]files.Entry, error) {
+	if t.virtual {
+		return t.children, nil
+	}
+	return t.root.List(t.rel)
+}
+
+// ✏️ Alterações só dentro de uma raiz, nunca na estrutura virtual
+func (t *target) mutable() error {
+	if t.virtual || t.rel == "" {
+		return fmt.Errorf("%w: %s é fixo (altere dentro de source/ ou volumes/<nome>/)", files.ErrNotAllowed, t.path)
+	}
+	return nil
+}
+
+// 🏷️ Detalhes de auditoria
+func (t *target) details() map[string]string {
+	return map[string]string{"root": t.rootName, "path": t.rel}
+}
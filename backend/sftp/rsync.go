@@ -0,0 +1,314 @@
+//backend/sftp/rsync.go
+
+package sftp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/files"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/volumes"
+)
+
+// 🔁 rsync pelo SSH: o cliente roda "rsync --server ..." no servidor e conversa pelo canal.
+// Esse lado do rsync roda num container auxiliar sem rede, com só o volume montado em /volumes/<nome>;
+// o disco do host e as outras aplicações ficam fora do alcance, então as opções do cliente passam como vieram.
+// O código (source/) é um snapshot .zip e fica só no SFTP.
+const (
+	DefaultRsyncImage = "instrumentisto/rsync-ssh"
+	rsyncMountDir     = "/volumes"
+	rsyncRoleLabel    = "virtuscloud.role"
+	rsyncRole         = "rsync"
+)
+
+// 🧾 Pedido de rsync já validado
+type rsyncJob struct {
+	app    *models.App
+	volume *models.Volume
+	cmd    []string // comando dentro do container auxiliar
+	paths  []string // caminhos relativos ao volume (auditoria)
+	sender bool     // o servidor envia: download
+}
+
+// 🔎 O cliente rsync chama sempre "rsync --server"
+func isRsyncCommand(command string) bool {
+	args, err := splitCommand(command)
+	return err == nil && len(args) > 1 && args[0] == "rsync" && args[1] == "--server"
+}
+
+// ✂️ Separa a linha de comando como o shell do servidor faria: espaços, aspas simples e duplas e barra invertida
+// (o rsync 3.2.4+ escapa os caminhos com barra invertida; os antigos mandam como estão)
+func splitCommand(s string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i+1 < len(s) {
+				i++
+				word.WriteByte(s[i])
+			}
+			inWord = true
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("aspas simples sem fechamento")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\\\"$`", s[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, errors.New("aspas duplas sem fechamento")
+			}
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// 🧭 Valida o comando do cliente e traduz os caminhos da árvore virtual para o container auxiliar.
+// Todos os caminhos precisam cair no mesmo volume do usuário; envio para volume acima da cota é recusado.
+func planRsync(username, command string, maxBytes int64) (*rsyncJob, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", files.ErrInvalid, err)
+	}
+	if len(args) < 2 || args[0] != "rsync" || args[1] != "--server" {
+		return nil, fmt.Errorf("%w: comando não é rsync --server", files.ErrInvalid)
+	}
+
+	job := &rsyncJob{}
+	options := []string{"rsync", "--server"}
+	i := 2
+	for ; i < len(args) && args[i] != "."; i++ {
+		opt := args[i]
+		switch {
+		case !strings.HasPrefix(opt, "-"):
+			return nil, fmt.Errorf("%w: argumento inesperado '%s'", files.ErrInvalid, opt)
+		case opt == "--sender":
+			job.sender = true
+		case opt == "--protect-args" || opt == "--secluded-args" || (!strings.HasPrefix(opt, "--") && protectsArgs(opt)):
+			// 🔒 Com -s os caminhos chegam pelo protocolo, depois da validação: não há como mapeá-los
+			return nil, fmt.Errorf("%w: rode o rsync sem -s/--protect-args", files.ErrInvalid)
+		case strings.HasPrefix(opt, "--max-size="):
+			// 📏 O limite do plano vale sempre; um limite menor do cliente é mantido
+			if n, err := strconv.ParseInt(strings.TrimPrefix(opt, "--max-size="), 10, 64); err == nil && n > 0 && n < maxBytes {
+				maxBytes = n
+			}
+			continue
+		}
+		options = append(options, opt)
+	}
+	if i >= len(args)-1 {
+		return nil, fmt.Errorf("%w: nenhum caminho informado (use /<app>/volumes/<nome>/)", files.ErrInvalid)
+	}
+	if !job.sender {
+		options = append(options, "--max-size="+strconv.FormatInt(maxBytes, 10))
+	}
+
+	var mapped []string
+	for _, p := range args[i+1:] {
+		t, err := resolve(username, p)
+		if err != nil {
+			return nil, err
+		}
+		if t.virtual {
+			return nil, fmt.Errorf("%w: aponte o rsync para um volume: /<app>/volumes/<nome>/", files.ErrNotAllowed)
+		}
+		name, ok := strings.CutPrefix(t.rootName, "volume:")
+		if !ok {
+			return nil, fmt.Errorf("%w: o código (source/) não entra no rsync; use SFTP", files.ErrNotAllowed)
+		}
+		if job.app == nil {
+			volume, found := store.VolumeStore.Get(t.app.ID + "/" + name)
+			if !found {
+				return nil, fmt.Errorf("%w: volume %s", files.ErrNotFound, name)
+			}
+			job.app, job.volume = t.app, volume
+		} else if job.app.ID != t.app.ID || job.volume.Name != name {
+			return nil, fmt.Errorf("%w: um rsync por volume", files.ErrNotAllowed)
+		}
+
+		// 📁 A barra final muda o significado no rsync ("pasta/" = o conteúdo) e é preservada
+		dest := rsyncMountDir + "/" + name
+		if t.rel != "" {
+			dest += "/" + t.rel
+		}
+		if strings.HasSuffix(p, "/") || strings.HasSuffix(p, "/.") {
+			dest += "/"
+		}
+		mapped = append(mapped, dest)
+		job.paths = append(job.paths, t.rel)
+	}
+	if !job.sender && job.volume.OverQuota {
+		return nil, fmt.Errorf("%w: volume %s acima da cota (libere espaço antes de gravar)", files.ErrNotAllowed, job.volume.Name)
+	}
+
+	job.cmd = append(append(options, "."), mapped...)
+	return job, nil
+}
+
+// 🔎 Opções curtas do servidor ("-vlogDtprse.iLsfxC"): o que vem depois do "e" são capacidades, não opções
+func protectsArgs(opt string) bool {
+	letters := strings.TrimPrefix(opt, "-")
+	if i := strings.IndexByte(letters, 'e'); i >= 0 {
+		letters = letters[:i]
+	}
+	return strings.Contains(letters, "s")
+}
+
+// 🔁 Roda o rsync pedido pelo exec e devolve o código de saída para o cliente
+func (s *server) runRsync(username string, remote net.Addr, channel ssh.Channel, command string) uint32 {
+	maxBytes, err := files.MaxFileBytes(username)
+	var job *rsyncJob
+	if err == nil {
+		job, err = planRsync(username, command, maxBytes)
+	}
+	if err != nil {
+		recordRsync(username, remote, job, err, 1)
+		_, _ = io.WriteString(channel.Stderr(), "rsync: "+err.Error()+"\n")
+		return 1
+	}
+
+	name, err := startRsyncHelper(s.rsyncImage, username, job)
+	if err != nil {
+		log.Printf("❌ SFTP: rsync de %s não iniciou: %v", username, err)
+		recordRsync(username, remote, job, err, 1)
+		_, _ = io.WriteString(channel.Stderr(), "rsync: indisponível no momento, tente de novo mais tarde ou use SFTP\n")
+		return 1
+	}
+	defer func() {
+		ctx, cancel := engine.Timeout()
+		defer cancel()
+		if err := engine.Default().Remove(ctx, name, true); err != nil {
+			log.Printf("⚠️ SFTP: erro ao remover container do rsync %s: %v", name, err)
+		}
+	}()
+
+	code, err := engine.Default().ExecStream(context.Background(), name, job.cmd, channel, channel, channel.Stderr())
+	if err != nil {
+		log.Printf("❌ SFTP: rsync de %s interrompido: %v", username, err)
+		code = 1
+	} else if code != 0 {
+		err = fmt.Errorf("rsync terminou com código %d", code)
+	}
+	recordRsync(username, remote, job, err, code)
+	return uint32(code)
+}
+
+// 🐳 Container auxiliar parado num "tail" até o exec do rsync; sem rede e só com o volume montado
+// (somente leitura no download e quando o volume passou da cota)
+func startRsyncHelper(image, username string, job *rsyncJob) (string, error) {
+	host, err := filepath.Abs(volumes.HostPath(job.volume))
+	if err != nil {
+		return "", fmt.Errorf("erro ao montar caminho do volume %s: %w", job.volume.ID, err)
+	}
+	bind := host + ":" + rsyncMountDir + "/" + job.volume.Name
+	if job.sender || job.volume.OverQuota {
+		bind += ":ro"
+	}
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("erro ao gerar nome do container: %w", err)
+	}
+	name := "vc-rsync-" + hex.EncodeToString(suffix)
+
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+	_, err = rt.Create(ctx, engine.Spec{
+		Name:        name,
+		Image:       image,
+		Cmd:         []string{"tail", "-f", "/dev/null"},
+		Labels:      map[string]string{rsyncRoleLabel: rsyncRole, "virtuscloud.user": username, "virtuscloud.app": job.app.ID},
+		Binds:       []string{bind},
+		NetworkMode: "none",
+		Resources:   engine.Resources{MemoryMB: 256, CPUs: 1, PidsLimit: 64},
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar container do rsync (imagem %s): %w", image, err)
+	}
+	if err := rt.Start(ctx, name); err != nil {
+		_ = rt.Remove(ctx, name, true)
+		return "", fmt.Errorf("erro ao iniciar container do rsync: %w", err)
+	}
+	return name, nil
+}
+
+// 🧹 Containers do rsync que sobraram de uma parada brusca do backend
+func cleanupRsyncHelpers() {
+	ctx, cancel := engine.Timeout()
+	defer cancel()
+	rt := engine.Default()
+	containers, err := rt.List(ctx, engine.ListOptions{All: true, Labels: map[string]string{rsyncRoleLabel: rsyncRole}})
+	if err != nil {
+		log.Printf("⚠️ SFTP: erro ao listar containers do rsync: %v", err)
+		return
+	}
+	for _, c := range containers {
+		if err := rt.Remove(ctx, c.Name, true); err != nil {
+			log.Printf("⚠️ SFTP: erro ao remover container do rsync %s: %v", c.Name, err)
+		}
+	}
+}
+
+// 📝 Um evento por rsync: direção, volume, caminhos e código de saída
+func recordRsync(username string, remote net.Addr, job *rsyncJob, err error, code int) {
+	event := audit.Event{Actor: username, Action: "sftp.rsync", Target: username, IP: remoteIP(remote), Details: map[string]string{"exitCode": strconv.Itoa(code)}}
+	if job != nil && job.app != nil {
+		event.Target = job.app.ID
+		event.Details["root"] = "volume:" + job.volume.Name
+		event.Details["path"] = strings.Join(job.paths, ", ")
+		event.Details["direction"] = "upload"
+		if job.sender {
+			event.Details["direction"] = "download"
+		}
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		if errors.Is(err, files.ErrNotAllowed) || errors.Is(err, files.ErrNotFound) || errors.Is(err, files.ErrInvalid) {
+			event.Outcome = audit.OutcomeDenied
+		}
+		event.Error = err.Error()
+	} else {
+		log.Printf("🔁 SFTP: rsync de %s em %s/%s concluído (%s)", username, job.app.ID, job.volume.Name, event.Details["direction"])
+	}
+	audit.Record(event)
+}
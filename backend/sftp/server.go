@@ -0,0 +1,261 @@
+//backend/sftp/server.go
+
+package sftp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ⏱️ Limites da conexão SSH
+const (
+	authTimeout        = time.Minute
+	maxAuthTries       = 10
+	maxChannelsPerConn = 8
+)
+
+// ✍️ Algoritmos de assinatura aceitos na autenticação (ssh-rsa com SHA-1 fica de fora)
+var userSigAlgorithms = []string{
+	ssh.KeyAlgoED25519,
+	ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+	ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256,
+}
+
+// 🖥️ Servidor SSH que só autentica por chave pública e só oferece o subsistema sftp e o rsync
+type server struct {
+	hostKey     ssh.Signer
+	idleTimeout time.Duration
+	maxConns    int
+	rsyncImage  string
+
+	connsMu sync.Mutex
+	conns   int
+}
+
+// 📂 Carrega a chave do servidor do arquivo PEM; cria uma nova (0600) quando não existe.
+// Trocar a chave faz os clientes acusarem "host key changed", então ela é gerada uma única vez.
+func loadOrCreateHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao gerar chave do servidor: %w", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao codificar chave do servidor: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, fmt.Errorf("erro ao criar pasta da chave do servidor: %w", err)
+		}
+		data = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return nil, fmt.Errorf("erro ao gravar chave do servidor: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave do servidor: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao interpretar chave do servidor %s: %w", path, err)
+	}
+	if signer.PublicKey().Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("chave do servidor %s não é ed25519", path)
+	}
+	return signer, nil
+}
+
+// 🔌 Aceita conexões até o listener fechar
+func (s *server) serve(l net.Listener) error {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		go s.serveConn(c)
+	}
+}
+
+func (s *server) acquire() bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	if s.conns >= s.maxConns {
+		return false
+	}
+	s.conns++
+	return true
+}
+
+func (s *server) release() {
+	s.connsMu.Lock()
+	s.conns--
+	s.connsMu.Unlock()
+}
+
+// ⏳ Conexão que cai depois de um tempo sem tráfego
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleConn) Read(p []byte) (int, error) {
+	_ = c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Read(p)
+}
+
+func (c *idleConn) Write(p []byte) (int, error) {
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Write(p)
+}
+
+// 🔌 Atende uma conexão do começo ao fim: autenticação, canais e o subsistema sftp
+func (s *server) serveConn(c net.Conn) {
+	defer c.Close()
+	if !s.acquire() {
+		log.Printf("⚠️ SSH: conexão de %s recusada (limite de conexões)", c.RemoteAddr())
+		return
+	}
+	defer s.release()
+
+	// 🔑 A última recusa fica guardada para um único evento de auditoria por conexão
+	var triedUser string
+	var refused error
+	config := &ssh.ServerConfig{
+		MaxAuthTries:            maxAuthTries,
+		PublicKeyAuthAlgorithms: userSigAlgorithms,
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			triedUser = meta.User()
+			if err := authorize(meta.User(), key); err != nil {
+				refused = err
+				return nil, err
+			}
+			return &ssh.Permissions{Extensions: map[string]string{"fingerprint": ssh.FingerprintSHA256(key)}}, nil
+		},
+	}
+	config.AddHostKey(s.hostKey)
+
+	// ⏱️ Quem não autentica a tempo cai; depois disso vale o tempo ocioso
+	timer := time.AfterFunc(authTimeout, func() { c.Close() })
+	conn, channels, requests, err := ssh.NewServerConn(&idleConn{Conn: c, timeout: s.idleTimeout}, config)
+	timer.Stop()
+	if err != nil {
+		if triedUser != "" {
+			if refused == nil {
+				refused = errors.New("nenhuma chave registrada foi aceita")
+			}
+			recordLogin(triedUser, "", c.RemoteAddr(), refused)
+		} else if !errors.Is(err, io.EOF) {
+			log.Printf("⚠️ SSH: conexão de %s encerrada: %v", c.RemoteAddr(), err)
+		}
+		return
+	}
+	defer conn.Close()
+	recordLogin(conn.User(), conn.Permissions.Extensions["fingerprint"], c.RemoteAddr(), nil)
+
+	go ssh.DiscardRequests(requests)
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxChannelsPerConn)
+	for newChannel := range channels {
+		// 🚫 Só sessões (sem encaminhamento de portas nem agente)
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "só sessões sftp")
+			continue
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			_ = newChannel.Reject(ssh.ResourceShortage, "canais demais na conexão")
+			continue
+		}
+		channel, reqs, err := newChannel.Accept()
+		if err != nil {
+			<-slots
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			s.handleSession(conn.User(), c.RemoteAddr(), channel, reqs)
+		}()
+	}
+	wg.Wait()
+}
+
+// 🧩 Sessão: o subsistema sftp e o exec do rsync rodam; shell e outros comandos recebem a explicação no stderr.
+// Pedidos de ambiente, terminal e sinais não têm efeito.
+func (s *server) handleSession(username string, remote net.Addr, channel ssh.Channel, reqs <-chan *ssh.Request) {
+	defer channel.Close()
+	done := make(chan uint32, 1)
+	started := false
+	for {
+		select {
+		case req, ok := <-reqs:
+			if !ok {
+				return
+			}
+			switch {
+			case started:
+				_ = req.Reply(false, nil)
+			case req.Type == "subsystem":
+				var payload struct{ Name string }
+				if ssh.Unmarshal(req.Payload, &payload) != nil || payload.Name != "sftp" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				started = true
+				_ = req.Reply(true, nil)
+				go func() {
+					serveSFTP(username, remote, channel)
+					done <- 0
+				}()
+			case req.Type == "exec" && isRsyncCommand(execCommand(req.Payload)):
+				started = true
+				_ = req.Reply(true, nil)
+				command := execCommand(req.Payload)
+				go func() {
+					done <- s.runRsync(username, remote, channel, command)
+				}()
+			case req.Type == "shell" || req.Type == "exec":
+				started = true
+				_ = req.Reply(true, nil)
+				_, _ = io.WriteString(channel.Stderr(), noShellMessage+"\r\n")
+				done <- 1
+			default:
+				_ = req.Reply(false, nil)
+			}
+		case status := <-done:
+			// 🏁 Fim: exit-status, EOF e CLOSE
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+			_ = channel.CloseWrite()
+			return
+		}
+	}
+}
+
+// 📦 Linha de comando do pedido exec
+func execCommand(payload []byte) string {
+	var req struct{ Command string }
+	if ssh.Unmarshal(payload, &req) != nil {
+		return ""
+	}
+	return req.Command
+}
@@ -0,0 +1,532 @@
+//backend/sftp/session.go
+
+package sftp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+
+	pkgsftp "github.com/pkg/sftp"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/files"
+)
+
+// 🔢 Arquivos abertos por sessão
+const maxHandles = 64
+
+// 📊 Sessão SFTP de um usuário: os handlers do pkg/sftp sobre a árvore virtual de fs.go
+type session struct {
+	username string
+	ip       string
+
+	mu      sync.Mutex
+	handles int
+	uploads map[*upload]struct{} // abertos para escrita (SETSTAT de tamanho trunca o temporário)
+
+	uploaded, downloaded int64
+	changes              int
+}
+
+// 🔌 Atende o subsistema sftp até o cliente fechar o canal
+func serveSFTP(username string, remote net.Addr, ch io.ReadWriteCloser) {
+	s := &session{username: username, ip: remoteIP(remote), uploads: map[*upload]struct{}{}}
+	server := pkgsftp.NewRequestServer(ch, pkgsftp.Handlers{FileGet: s, FilePut: s, FileCmd: s, FileList: s})
+	if err := server.Serve(); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("⚠️ SFTP: sessão de %s encerrada: %v", username, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log.Printf("📂 SFTP: sessão de %s encerrada (%d alterações, %d bytes enviados, %d bytes baixados)", username, s.changes, s.uploaded, s.downloaded)
+}
+
+func remoteIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+var (
+	errUnsupported = errors.New("operação não suportada")
+	errClientError = errors.New("pedido recusado")
+)
+
+// 🚦 Erro com o código SFTP escolhido; o texto vai para o cliente
+type statusError struct {
+	err  error
+	code error
+}
+
+func (e *statusError) Error() string   { return e.err.Error() }
+func (e *statusError) Unwrap() []error { return []error{e.err, e.code} }
+
+// 🚦 O erro do files vira o código mais próximo; erros internos não vazam para o cliente
+func (s *session) status(err error) error {
+	var code error
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, io.EOF):
+		return io.EOF
+	case errors.Is(err, errUnsupported):
+		code = pkgsftp.ErrSSHFxOpUnsupported
+	case errors.Is(err, files.ErrNotFound):
+		code = pkgsftp.ErrSSHFxNoSuchFile
+	case errors.Is(err, files.ErrNotAllowed):
+		code = pkgsftp.ErrSSHFxPermissionDenied
+	case errors.Is(err, files.ErrInvalid), errors.Is(err, files.ErrExists), errors.Is(err, files.ErrTooLarge), errors.Is(err, errClientError):
+		code = pkgsftp.ErrSSHFxFailure
+	default:
+		log.Printf("❌ SFTP: erro para %s: %v", s.username, err)
+		return errors.New("erro interno")
+	}
+	return &statusError{err: err, code: code}
+}
+
+// 📝 Alteração auditada como no gerenciador de arquivos
+func (s *session) record(action string, t *target, err error, extra map[string]string) {
+	event := audit.Event{Actor: s.username, Action: action, Target: t.app.ID, IP: s.ip, Details: t.details()}
+	for k, v := range extra {
+		event.Details[k] = v
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		if errors.Is(err, files.ErrNotAllowed) {
+			event.Outcome = audit.OutcomeDenied
+		}
+		event.Error = err.Error()
+	} else {
+		s.mu.Lock()
+		s.changes++
+		s.mu.Unlock()
+	}
+	audit.Record(event)
+}
+
+func (s *session) acquire() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handles >= maxHandles {
+		return fmt.Errorf("%w: arquivos abertos demais (máximo %d)", errClientError, maxHandles)
+	}
+	s.handles++
+	return nil
+}
+
+func (s *session) release() {
+	s.mu.Lock()
+	s.handles--
+	s.mu.Unlock()
+}
+
+// 📋 LIST, STAT e LSTAT
+func (s *session) Filelist(r *pkgsftp.Request) (pkgsftp.ListerAt, error) {
+	t, err := resolve(s.username, r.Filepath)
+	if err != nil {
+		return nil, s.status(err)
+	}
+	switch r.Method {
+	case "List":
+		entries, err := t.list()
+		if err != nil {
+			return nil, s.status(err)
+		}
+		infos := make(listerAt, 0, len(entries))
+		for _, e := range entries {
+			infos = append(infos, fileInfo{entry: e})
+		}
+		return infos, nil
+	case "Stat", "Lstat":
+		entry, err := t.stat()
+		if err != nil {
+			return nil, s.status(err)
+		}
+		return listerAt{fileInfo{entry: *entry}}, nil
+	}
+	return nil, s.status(fmt.Errorf("%w: %s", errUnsupported, r.Method))
+}
+
+// 🔗 Links simbólicos não são seguidos nem criados pelo SFTP
+func (s *session) Readlink(string) (string, error) {
+	return "", s.status(fmt.Errorf("%w: links simbólicos", errUnsupported))
+}
+
+// 📃 Entradas de um diretório, em páginas
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(ls []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(ls, l[offset:])
+	if n < len(ls) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// 📖 Leitura: o conteúdo vem de um arquivo com ReadAt (volume) ou é copiado para um temporário (snapshot .zip)
+func (s *session) Fileread(r *pkgsftp.Request) (io.ReaderAt, error) {
+	t, err := resolve(s.username, r.Filepath)
+	if err != nil {
+		return nil, s.status(err)
+	}
+	max, err := files.MaxFileBytes(s.username)
+	if err != nil {
+		return nil, s.status(err)
+	}
+	existing, err := t.stat()
+	if err != nil {
+		return nil, s.status(err)
+	}
+	if existing.Type != files.TypeFile {
+		return nil, s.status(fmt.Errorf("%w: %s não é um arquivo", files.ErrInvalid, t.path))
+	}
+	if existing.Size > max {
+		return nil, s.status(fmt.Errorf("%w: %s tem %d bytes (limite %d)", files.ErrTooLarge, t.path, existing.Size, max))
+	}
+	if err := s.acquire(); err != nil {
+		return nil, s.status(err)
+	}
+	file, temp, err := s.contents(t, max)
+	if err != nil {
+		s.release()
+		return nil, s.status(err)
+	}
+	return &download{s: s, file: file, temp: temp}, nil
+}
+
+// 📖 Conteúdo legível com ReadAt
+func (s *session) contents(t *target, max int64) (*os.File, bool, error) {
+	rc, _, err := t.root.Open(t.rel)
+	if err != nil {
+		return nil, false, err
+	}
+	if f, ok := rc.(*os.File); ok {
+		return f, false, nil
+	}
+	defer rc.Close()
+	tmp, err := os.CreateTemp("", "virtus-sftp-*")
+	if err != nil {
+		return nil, false, fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	if _, err := io.Copy(tmp, io.LimitReader(rc, max)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, false, fmt.Errorf("erro ao ler %s: %w", t.path, err)
+	}
+	return tmp, true, nil
+}
+
+// 📥 Arquivo aberto para leitura
+type download struct {
+	s    *session
+	file *os.File
+	temp bool
+}
+
+func (d *download) ReadAt(p []byte, off int64) (int, error) {
+	n, err := d.file.ReadAt(p, off)
+	d.s.mu.Lock()
+	d.s.downloaded += int64(n)
+	d.s.mu.Unlock()
+	return n, err
+}
+
+func (d *download) Close() error {
+	defer d.s.release()
+	d.file.Close()
+	if d.temp {
+		os.Remove(d.file.Name())
+	}
+	return nil
+}
+
+// ✏️ Escrita: tudo vai para um temporário que substitui o arquivo no CLOSE, pelo Write do files (cota e limite do plano)
+func (s *session) Filewrite(r *pkgsftp.Request) (io.WriterAt, error) {
+	return s.openUpload(r)
+}
+
+// ✏️ Leitura e escrita no mesmo handle (sshfs): lê do temporário que está sendo escrito
+func (s *session) OpenFile(r *pkgsftp.Request) (pkgsftp.WriterAtReaderAt, error) {
+	return s.openUpload(r)
+}
+
+func (s *session) openUpload(r *pkgsftp.Request) (*upload, error) {
+	flags := r.Pflags()
+	t, err := resolve(s.username, r.Filepath)
+	if err == nil {
+		err = t.mutable()
+	}
+	if err != nil {
+		return nil, s.status(err)
+	}
+	max, err := files.MaxFileBytes(s.username)
+	if err != nil {
+		return nil, s.status(err)
+	}
+
+	existing, statErr := t.stat()
+	exists := statErr == nil
+	if statErr != nil && !errors.Is(statErr, files.ErrNotFound) {
+		return nil, s.status(statErr)
+	}
+	if exists && existing.Type != files.TypeFile {
+		return nil, s.status(fmt.Errorf("%w: %s não é um arquivo", files.ErrInvalid, t.path))
+	}
+	if exists && existing.Size > max && !flags.Trunc {
+		return nil, s.status(fmt.Errorf("%w: %s tem %d bytes (limite %d)", files.ErrTooLarge, t.path, existing.Size, max))
+	}
+	if exists && flags.Excl {
+		return nil, s.status(fmt.Errorf("%w: %s", files.ErrExists, t.path))
+	}
+	if !exists && !flags.Creat {
+		return nil, s.status(fmt.Errorf("%w: %s", files.ErrNotFound, t.path))
+	}
+	if err := s.acquire(); err != nil {
+		return nil, s.status(err)
+	}
+
+	u := &upload{s: s, target: t, max: max, dirty: !exists || flags.Trunc} // criar ou truncar já é alteração, mesmo sem WRITE
+	if u.file, err = os.CreateTemp("", "virtus-sftp-*"); err != nil {
+		s.release()
+		return nil, s.status(fmt.Errorf("erro ao criar arquivo temporário: %w", err))
+	}
+	if exists && !flags.Trunc {
+		// Sem TRUNC o cliente altera parte do arquivo: começa do conteúdo atual
+		rc, _, err := t.root.Open(t.rel)
+		if err == nil {
+			_, err = io.Copy(u.file, io.LimitReader(rc, max))
+			rc.Close()
+		}
+		if err != nil {
+			u.discard()
+			return nil, s.status(err)
+		}
+	}
+	s.mu.Lock()
+	s.uploads[u] = struct{}{}
+	s.mu.Unlock()
+	return u, nil
+}
+
+// 📤 Arquivo aberto para escrita
+type upload struct {
+	s      *session
+	target *target
+	file   *os.File
+	max    int64
+
+	mu      sync.Mutex
+	dirty   bool
+	failed  error // WRITE recusado: o arquivo incompleto não é gravado no CLOSE
+	aborted bool  // conexão caiu com o handle aberto
+}
+
+func (u *upload) ReadAt(p []byte, off int64) (int, error) {
+	return u.file.ReadAt(p, off)
+}
+
+func (u *upload) WriteAt(p []byte, off int64) (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if off+int64(len(p)) > u.max {
+		if u.failed == nil {
+			u.failed = fmt.Errorf("%w (%d bytes)", files.ErrTooLarge, u.max)
+		}
+		return 0, u.s.status(u.failed)
+	}
+	n, err := u.file.WriteAt(p, off)
+	if err != nil {
+		if u.failed == nil {
+			u.failed = err
+		}
+		return n, u.s.status(err)
+	}
+	u.dirty = true
+	u.s.mu.Lock()
+	u.s.uploaded += int64(n)
+	u.s.mu.Unlock()
+	return n, nil
+}
+
+// ✂️ SETSTAT com tamanho num arquivo aberto para escrita
+func (u *upload) truncate(size int64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if size > u.max {
+		return fmt.Errorf("%w (%d bytes)", files.ErrTooLarge, u.max)
+	}
+	if err := u.file.Truncate(size); err != nil {
+		return err
+	}
+	u.dirty = true
+	return nil
+}
+
+// 🔌 Chamado pelo pkg/sftp quando a conexão cai com o handle aberto: nada é gravado
+func (u *upload) TransferError(error) {
+	u.mu.Lock()
+	u.aborted = true
+	u.mu.Unlock()
+}
+
+// 🏁 CLOSE: grava o arquivo escrito e libera o handle
+func (u *upload) Close() error {
+	defer u.discard()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.aborted {
+		if u.dirty {
+			log.Printf("⚠️ SFTP: escrita de %s em %s interrompida e descartada", u.s.username, u.target.path)
+		}
+		return nil
+	}
+	if !u.dirty {
+		return nil
+	}
+	if u.failed != nil {
+		u.s.record("sftp.write", u.target, u.failed, nil)
+		return u.s.status(fmt.Errorf("%w: escrita incompleta descartada", u.failed))
+	}
+	if _, err := u.file.Seek(0, io.SeekStart); err != nil {
+		return u.s.status(err)
+	}
+	entry, err := u.target.root.Write(u.target.rel, u.file, u.max)
+	extra := map[string]string{}
+	if entry != nil {
+		extra["size"] = strconv.FormatInt(entry.Size, 10)
+	}
+	u.s.record("sftp.write", u.target, err, extra)
+	return u.s.status(err)
+}
+
+func (u *upload) discard() {
+	u.s.mu.Lock()
+	delete(u.s.uploads, u)
+	u.s.mu.Unlock()
+	u.s.release()
+	u.file.Close()
+	os.Remove(u.file.Name())
+}
+
+// 🛠️ MKDIR, RMDIR, REMOVE, RENAME e SETSTAT
+func (s *session) Filecmd(r *pkgsftp.Request) error {
+	switch r.Method {
+	case "Setstat":
+		return s.status(s.setstat(r))
+	case "Mkdir":
+		return s.status(s.mkdir(r.Filepath))
+	case "Remove", "Rmdir":
+		return s.status(s.remove(r.Filepath, r.Method == "Rmdir"))
+	case "Rename":
+		return s.status(s.rename(r.Filepath, r.Target))
+	case "Link", "Symlink":
+		return s.status(fmt.Errorf("%w: links", errUnsupported))
+	}
+	return s.status(fmt.Errorf("%w: %s", errUnsupported, r.Method))
+}
+
+// 📐 Só o tamanho de um arquivo aberto para escrita vale; permissões, dono e datas ficam com o container
+func (s *session) setstat(r *pkgsftp.Request) error {
+	if !r.AttrFlags().Size {
+		return nil
+	}
+	p := cleanPath(r.Filepath)
+	s.mu.Lock()
+	var open *upload
+	for u := range s.uploads {
+		if u.target.path == p {
+			open = u
+			break
+		}
+	}
+	s.mu.Unlock()
+	if open == nil {
+		return fmt.Errorf("%w: truncar só em arquivo aberto para escrita", errUnsupported)
+	}
+	return open.truncate(int64(r.Attributes().Size))
+}
+
+func (s *session) mkdir(p string) error {
+	t, err := resolve(s.username, p)
+	if err == nil {
+		err = t.mutable()
+	}
+	if err != nil {
+		return err
+	}
+	err = t.root.Mkdir(t.rel)
+	s.record("sftp.mkdir", t, err, nil)
+	return err
+}
+
+// 🗑️ REMOVE só arquivos; RMDIR só diretórios vazios
+func (s *session) remove(p string, dir bool) error {
+	t, err := resolve(s.username, p)
+	if err == nil {
+		err = t.mutable()
+	}
+	if err != nil {
+		return err
+	}
+	entry, err := t.stat()
+	if err != nil {
+		return err
+	}
+	action := "sftp.remove"
+	if dir {
+		action = "sftp.rmdir"
+		if entry.Type != files.TypeDir {
+			return fmt.Errorf("%w: %s não é um diretório", files.ErrInvalid, t.path)
+		}
+		children, err := t.list()
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			return fmt.Errorf("%w: %s não está vazio", files.ErrInvalid, t.path)
+		}
+	} else if entry.Type == files.TypeDir {
+		return fmt.Errorf("%w: %s é um diretório (use rmdir)", files.ErrInvalid, t.path)
+	}
+	err = t.root.Delete(t.rel)
+	s.record(action, t, err, nil)
+	return err
+}
+
+// ✏️ Renomear só dentro da mesma raiz
+func (s *session) rename(from, to string) error {
+	src, err := resolve(s.username, from)
+	if err == nil {
+		err = src.mutable()
+	}
+	if err != nil {
+		return err
+	}
+	dst, err := resolve(s.username, to)
+	if err == nil {
+		err = dst.mutable()
+	}
+	if err != nil {
+		return err
+	}
+	if src.app.ID != dst.app.ID || src.rootName != dst.rootName {
+		return fmt.Errorf("%w: mover entre %s e %s (copie e apague)", errUnsupported, path.Dir(src.path), path.Dir(dst.path))
+	}
+	err = src.root.Rename(src.rel, dst.rel)
+	s.record("sftp.rename", src, err, map[string]string{"to": dst.rel})
+	return err
+}
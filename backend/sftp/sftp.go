@@ -0,0 +1,181 @@
+//backend/sftp/sftp.go
+
+// 📂 Acesso SFTP aos arquivos das aplicações (sftp, sshfs, rclone, lftp, FileZilla), autenticado pelas
+// chaves SSH do perfil. O transporte é o golang.org/x/crypto/ssh e o protocolo é o github.com/pkg/sftp;
+// o que o cliente enxerga é a árvore virtual de fs.go, nunca o disco do host.
+//
+// Escopo: o subsistema sftp e o rsync nos volumes (rsync.go: o lado servidor roda num container auxiliar
+// sem rede, com só o volume montado). Shell e outros comandos são recusados; o scp atual usa SFTP e funciona.
+package sftp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"virtuscloud/backend/audit"
+	"virtuscloud/backend/files"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+)
+
+// 💬 Resposta a shell e exec que não é rsync, enviada no stderr
+const noShellMessage = "Este servidor só oferece SFTP (sftp, scp, sshfs, rclone, FileZilla) e rsync para os volumes (/<app>/volumes/<nome>/). Shell e outros comandos não estão disponíveis."
+
+// ⚙️ Configuração do servidor SFTP
+type Config struct {
+	Addr        string        // endereço de escuta (SFTP_ADDR, ex.: :2222; vazio = SFTP desligado)
+	HostKeyPath string        // chave ed25519 do servidor, criada na primeira vez (SFTP_HOST_KEY, padrão ./database/sftp_host_ed25519_key)
+	PublicHost  string        // host mostrado ao usuário nas instruções de conexão (SFTP_PUBLIC_HOST)
+	IdleTimeout time.Duration // conexão sem tráfego é encerrada (SFTP_IDLE_MINUTES, padrão 30)
+	MaxConns    int           // conexões simultâneas no total (SFTP_MAX_CONNECTIONS, padrão 64)
+	RsyncImage  string        // imagem com rsync dos containers auxiliares (RSYNC_IMAGE, padrão DefaultRsyncImage)
+}
+
+// ⚙️ Configuração lida das variáveis SFTP_*
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Addr:        os.Getenv("SFTP_ADDR"),
+		HostKeyPath: os.Getenv("SFTP_HOST_KEY"),
+		PublicHost:  os.Getenv("SFTP_PUBLIC_HOST"),
+		IdleTimeout: 30 * time.Minute,
+		MaxConns:    64,
+		RsyncImage:  os.Getenv("RSYNC_IMAGE"),
+	}
+	if cfg.HostKeyPath == "" {
+		cfg.HostKeyPath = "./database/sftp_host_ed25519_key"
+	}
+	if cfg.RsyncImage == "" {
+		cfg.RsyncImage = DefaultRsyncImage
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("SFTP_IDLE_MINUTES")); err == nil && minutes > 0 {
+		cfg.IdleTimeout = time.Duration(minutes) * time.Minute
+	}
+	if n, err := strconv.Atoi(os.Getenv("SFTP_MAX_CONNECTIONS")); err == nil && n > 0 {
+		cfg.MaxConns = n
+	}
+	return cfg
+}
+
+// 📡 Servidor em execução (para as instruções no perfil)
+var (
+	runningMu   sync.RWMutex
+	running     *Config
+	fingerprint string
+)
+
+// 🚀 Sobe o servidor SFTP em segundo plano
+func Start(cfg Config) error {
+	if cfg.Addr == "" {
+		log.Println("📂 SFTP desligado (SFTP_ADDR vazio)")
+		return nil
+	}
+	hostKey, err := loadOrCreateHostKey(cfg.HostKeyPath)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("erro ao escutar em %s: %w", cfg.Addr, err)
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Minute
+	}
+	if cfg.MaxConns <= 0 {
+		cfg.MaxConns = 64
+	}
+	if cfg.RsyncImage == "" {
+		cfg.RsyncImage = DefaultRsyncImage
+	}
+	srv := &server{hostKey: hostKey, idleTimeout: cfg.IdleTimeout, maxConns: cfg.MaxConns, rsyncImage: cfg.RsyncImage}
+	go cleanupRsyncHelpers()
+	hostFingerprint := ssh.FingerprintSHA256(hostKey.PublicKey())
+
+	runningMu.Lock()
+	running, fingerprint = &cfg, hostFingerprint
+	runningMu.Unlock()
+
+	go func() {
+		if err := srv.serve(listener); err != nil {
+			log.Println("❌ SFTP encerrado:", err)
+		}
+	}()
+	log.Printf("📂 SFTP escutando em %s (chave do servidor %s)", cfg.Addr, hostFingerprint)
+	return nil
+}
+
+// 🔑 Entra quem tem a chave registrada no perfil e um plano com gerenciador de arquivos
+func authorize(username string, key ssh.PublicKey) error {
+	if !services.HasSSHKey(username, key) {
+		return errors.New("chave não registrada para o usuário")
+	}
+	user, ok := store.UserStore.Get(username)
+	if !ok || user.Status == "disabled" {
+		return errors.New("conta desativada")
+	}
+	if _, err := files.MaxFileBytes(username); err != nil {
+		return err
+	}
+	return nil
+}
+
+// 📝 Um evento de auditoria por conexão: entrou (com a chave usada) ou foi recusado
+func recordLogin(username, keyFingerprint string, remote net.Addr, err error) {
+	event := audit.Event{Actor: username, Action: "sftp.login", Target: username, IP: remoteIP(remote)}
+	if err != nil {
+		event.Outcome = audit.OutcomeDenied
+		event.Error = err.Error()
+		log.Printf("🚫 SFTP: acesso negado para %s de %s: %v", username, remote, err)
+	} else {
+		event.Details = map[string]string{"fingerprint": keyFingerprint}
+		log.Printf("📂 SFTP: %s conectado de %s", username, remote)
+	}
+	audit.Record(event)
+}
+
+// 📋 Como se conectar (vazio quando o SFTP está desligado)
+func Info(username string) map[string]interface{} {
+	runningMu.RLock()
+	cfg, hostFingerprint := running, fingerprint
+	runningMu.RUnlock()
+	if cfg == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		host, port = "", "22"
+	}
+	if cfg.PublicHost != "" {
+		host = cfg.PublicHost
+	} else if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	command := "sftp " + username + "@" + host
+	rsync := "rsync -av ./pasta/ " + username + "@" + host + ":/<app>/volumes/<nome>/"
+	if port != "22" {
+		command = "sftp -P " + port + " " + username + "@" + host
+		rsync = "rsync -av -e 'ssh -p " + port + "' ./pasta/ " + username + "@" + host + ":/<app>/volumes/<nome>/"
+	}
+	return map[string]interface{}{
+		"enabled":            true,
+		"host":               host,
+		"port":               port,
+		"username":           username,
+		"hostKeyFingerprint": hostFingerprint,
+		"command":            command,
+		"rsyncCommand":       rsync,
+		"layout": strings.Join([]string{
+			"/<app>/source — código (vale no próximo rebuild)",
+			"/<app>/volumes/<nome> — volume persistente (vale na hora)",
+		}, "; "),
+	}
+}
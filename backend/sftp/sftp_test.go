@@ -0,0 +1,543 @@
+//backend/sftp/sftp_test.go
+
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pkgsftp "github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"virtuscloud/backend/engine"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+	"virtuscloud/backend/services"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/volumes"
+)
+
+// 🧪 Stores JSON e volumes numa pasta temporária
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	packageDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println("erro ao ler diretório:", err)
+		return 1
+	}
+	dir, err := os.MkdirTemp("", "sftp-test")
+	if err != nil {
+		fmt.Println("erro ao criar pasta temporária:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chdir(dir); err != nil {
+		fmt.Println("erro ao entrar na pasta temporária:", err)
+		return 1
+	}
+	defer os.Chdir(packageDir)
+	_ = os.MkdirAll("database", os.ModePerm)
+
+	persistence.SetBackend(persistence.NewJSONBackend())
+	fake := engine.NewFake()
+	fake.AddImage(testRsyncImage)
+	engine.SetDefault(fake)
+	for _, err := range []error{
+		store.UserStore.Load("./database/users.json"),
+		store.LoadAppStoreFromDisk(store.DefaultAppStorePath),
+		store.LoadVolumeStoreFromDisk(store.DefaultVolumeStorePath),
+	} {
+		if err != nil {
+			fmt.Println("erro ao carregar stores:", err)
+			return 1
+		}
+	}
+	return m.Run()
+}
+
+// 🐳 Imagem do rsync registrada no runtime fake
+const testRsyncImage = "rsync-test"
+
+// 🖥️ Servidor com chave em memória numa porta livre do loopback
+func startServer(t *testing.T) (string, ssh.PublicKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave do servidor: %v", err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("erro ao montar chave do servidor: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao escutar: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	srv := &server{hostKey: hostKey, idleTimeout: time.Minute, maxConns: 8, rsyncImage: testRsyncImage}
+	go srv.serve(l)
+	return l.Addr().String(), hostKey.PublicKey()
+}
+
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("erro ao montar chave: %v", err)
+	}
+	return signer
+}
+
+// 👤 Usuário com uma chave registrada no perfil
+func newTestUser(t *testing.T, username string, plan models.PlanType) ssh.Signer {
+	t.Helper()
+	if err := store.UserStore.Save(&models.User{Username: username, Plan: plan, Status: "active"}); err != nil {
+		t.Fatalf("erro ao salvar usuário: %v", err)
+	}
+	signer := newSigner(t)
+	if _, err := services.AddSSHKey(username, "teste", string(ssh.MarshalAuthorizedKey(signer.PublicKey()))); err != nil {
+		t.Fatalf("AddSSHKey: %v", err)
+	}
+	return signer
+}
+
+// 📦 Aplicação com um volume vazio
+func newTestApp(t *testing.T, username, appID, volume string) string {
+	t.Helper()
+	if err := store.AppStore.Save(&models.App{ID: appID, Username: username, ContainerName: username + "-" + appID}); err != nil {
+		t.Fatalf("erro ao salvar aplicação: %v", err)
+	}
+	v := &models.Volume{ID: appID + "/" + volume, Username: username, AppID: appID, Name: volume, SizeGB: 1}
+	if _, exists := store.VolumeStore.Get(v.ID); exists {
+		_ = os.RemoveAll(volumes.HostPath(v))
+	} else if err := store.VolumeStore.Create(v); err != nil {
+		t.Fatalf("erro ao salvar volume: %v", err)
+	}
+	host := volumes.HostPath(v)
+	if err := os.MkdirAll(host, 0o777); err != nil {
+		t.Fatalf("erro ao criar volume: %v", err)
+	}
+	return host
+}
+
+func dial(addr string, hostKey ssh.PublicKey, username string, signer ssh.Signer) (*ssh.Client, error) {
+	return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         5 * time.Second,
+	})
+}
+
+func connect(t *testing.T, addr string, hostKey ssh.PublicKey, username string, signer ssh.Signer) *pkgsftp.Client {
+	t.Helper()
+	conn, err := dial(addr, hostKey, username, signer)
+	if err != nil {
+		t.Fatalf("erro ao conectar como %s: %v", username, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client, err := pkgsftp.NewClient(conn)
+	if err != nil {
+		t.Fatalf("erro ao abrir sftp: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestAuthAcceptsOnlyRegisteredKeys(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "authalice", models.PlanPro)
+	bob := newTestUser(t, "authbob", models.PlanPro)
+
+	conn, err := dial(addr, hostKey, "authalice", alice)
+	if err != nil {
+		t.Fatalf("chave registrada recusada: %v", err)
+	}
+	conn.Close()
+
+	cases := []struct {
+		name, user string
+		signer     ssh.Signer
+	}{
+		{"chave desconhecida", "authalice", newSigner(t)},
+		{"chave de outro usuário", "authalice", bob},
+		{"usuário inexistente", "ninguem", alice},
+	}
+	for _, c := range cases {
+		if conn, err := dial(addr, hostKey, c.user, c.signer); err == nil {
+			conn.Close()
+			t.Fatalf("%s: conexão aceita", c.name)
+		}
+	}
+}
+
+func TestAuthRefusesDisabledAccountAndPlanWithoutFiles(t *testing.T) {
+	addr, hostKey := startServer(t)
+	disabled := newTestUser(t, "authdisabled", models.PlanPro)
+	if _, err := store.UserStore.Update("authdisabled", func(u *models.User) { u.Status = "disabled" }); err != nil {
+		t.Fatalf("erro ao desativar usuário: %v", err)
+	}
+	noFiles := newTestUser(t, "authnoplan", models.PlanNothing)
+
+	if conn, err := dial(addr, hostKey, "authdisabled", disabled); err == nil {
+		conn.Close()
+		t.Fatalf("conta desativada entrou")
+	}
+	if conn, err := dial(addr, hostKey, "authnoplan", noFiles); err == nil {
+		conn.Close()
+		t.Fatalf("plano sem gerenciador de arquivos entrou")
+	}
+}
+
+func TestChrootShowsOnlyOwnApps(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "treealice", models.PlanPro)
+	newTestUser(t, "treebob", models.PlanPro)
+	aliceHost := newTestApp(t, "treealice", "aliceweb", "data")
+	bobHost := newTestApp(t, "treebob", "bobweb", "data")
+	if err := os.WriteFile(filepath.Join(bobHost, "secret.txt"), []byte("do bob"), 0o600); err != nil {
+		t.Fatalf("erro ao gravar arquivo do bob: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(aliceHost, "mine.txt"), []byte("da alice"), 0o600); err != nil {
+		t.Fatalf("erro ao gravar arquivo da alice: %v", err)
+	}
+	client := connect(t, addr, hostKey, "treealice", alice)
+
+	entries, err := client.ReadDir("/")
+	if err != nil {
+		t.Fatalf("ReadDir(/): %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "aliceweb" || !entries[0].IsDir() {
+		t.Fatalf("raiz = %v, esperado só aliceweb", entries)
+	}
+	if got, err := client.RealPath("../../.."); err != nil || got != "/" {
+		t.Fatalf("RealPath(../../..) = %q, %v, esperado /", got, err)
+	}
+
+	// 🔐 Aplicação de outro usuário e o disco do host não existem na árvore
+	for _, p := range []string{"/bobweb/volumes/data/secret.txt", "/../bobweb/volumes/data/secret.txt", "/../../etc/passwd", "/aliceweb/volumes/data/../../../bobweb/volumes/data/secret.txt"} {
+		if _, err := client.Open(p); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Open(%s) = %v, esperado inexistente", p, err)
+		}
+	}
+	if _, err := client.Stat("/bobweb"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Stat(/bobweb) = %v, esperado inexistente", err)
+	}
+	if err := client.Rename("/aliceweb/volumes/data/mine.txt", "/bobweb/volumes/data/mine.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Rename para a aplicação de outro usuário = %v, esperado inexistente", err)
+	}
+	if _, err := os.Stat(filepath.Join(bobHost, "mine.txt")); !os.IsNotExist(err) {
+		t.Fatalf("arquivo movido para o volume do bob: %v", err)
+	}
+}
+
+func TestChrootReadsAndWritesInsideVolume(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "volalice", models.PlanPro)
+	host := newTestApp(t, "volalice", "volweb", "data")
+	client := connect(t, addr, hostKey, "volalice", alice)
+
+	if err := client.Mkdir("/volweb/volumes/data/conf"); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	f, err := client.Create("/volweb/volumes/data/conf/app.yml")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := f.Write([]byte("porta: 8080\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(host, "conf", "app.yml")); err != nil || string(data) != "porta: 8080\n" {
+		t.Fatalf("arquivo no volume = %q, %v", data, err)
+	}
+
+	r, err := client.Open("/volweb/volumes/data/conf/app.yml")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "porta: 8080\n" {
+		t.Fatalf("leitura = %q", data)
+	}
+
+	if err := client.Rename("/volweb/volumes/data/conf/app.yml", "/volweb/volumes/data/app.yml"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if err := client.Remove("/volweb/volumes/data/app.yml"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := client.RemoveDirectory("/volweb/volumes/data/conf"); err != nil {
+		t.Fatalf("RemoveDirectory: %v", err)
+	}
+
+	// 🧱 A estrutura virtual é fixa
+	for _, p := range []string{"/volweb", "/volweb/volumes", "/volweb/volumes/data"} {
+		if err := client.Mkdir(p); !errors.Is(err, os.ErrPermission) {
+			t.Fatalf("Mkdir(%s) = %v, esperado permissão negada", p, err)
+		}
+	}
+	if err := client.Remove("/volweb"); err == nil {
+		t.Fatalf("Remove(/volweb) aceito")
+	}
+}
+
+func TestChrootRefusesLinksOutOfVolume(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "linkalice", models.PlanPro)
+	host := newTestApp(t, "linkalice", "linkweb", "data")
+	outside, err := filepath.Abs("outside-linkweb")
+	if err != nil {
+		t.Fatalf("erro ao montar caminho: %v", err)
+	}
+	if err := os.MkdirAll(outside, 0o700); err != nil {
+		t.Fatalf("erro ao criar pasta externa: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("segredo"), 0o600); err != nil {
+		t.Fatalf("erro ao gravar segredo: %v", err)
+	}
+	// 🔗 Link criado pelo container apontando para o host
+	if err := os.Symlink(outside, filepath.Join(host, "escape")); err != nil {
+		t.Fatalf("erro ao criar link: %v", err)
+	}
+	client := connect(t, addr, hostKey, "linkalice", alice)
+
+	if _, err := client.Open("/linkweb/volumes/data/escape/secret.txt"); err == nil {
+		t.Fatalf("leitura pelo link saiu do volume")
+	}
+	if f, err := client.Create("/linkweb/volumes/data/escape/novo.txt"); err == nil {
+		_, _ = f.Write([]byte("invadido"))
+		if err := f.Close(); err == nil {
+			t.Fatalf("escrita pelo link saiu do volume")
+		}
+	}
+	if items, _ := os.ReadDir(outside); len(items) != 1 {
+		t.Fatalf("arquivos criados fora do volume: %v", items)
+	}
+}
+
+func TestWriteAboveLimitIsDiscarded(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "bigalice", models.PlanTest)
+	host := newTestApp(t, "bigalice", "bigweb", "data")
+	client := connect(t, addr, hostKey, "bigalice", alice)
+
+	max := int64(models.Plans[models.PlanTest].FileManagerMaxMB) * 1024 * 1024
+	f, err := client.Create("/bigweb/volumes/data/big.bin")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, writeErr := f.Write(bytes.Repeat([]byte("x"), int(max)+1))
+	closeErr := f.Close()
+	if writeErr == nil && closeErr == nil {
+		t.Fatalf("arquivo acima do limite do plano aceito")
+	}
+	if _, err := os.Stat(filepath.Join(host, "big.bin")); !os.IsNotExist(err) {
+		t.Fatalf("escrita acima do limite gravada no volume: %v", err)
+	}
+}
+
+// 🔌 Roda um comando pelo exec do SSH e devolve o código de saída, a saída e o stderr
+func runExec(t *testing.T, addr string, hostKey ssh.PublicKey, username string, signer ssh.Signer, command, input string) (int, string, string) {
+	t.Helper()
+	conn, err := dial(addr, hostKey, username, signer)
+	if err != nil {
+		t.Fatalf("erro ao conectar: %v", err)
+	}
+	defer conn.Close()
+	session, err := conn.NewSession()
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdin = strings.NewReader(input)
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	err = session.Run(command)
+	var exit *ssh.ExitError
+	switch {
+	case err == nil:
+		return 0, stdout.String(), stderr.String()
+	case errors.As(err, &exit):
+		return exit.ExitStatus(), stdout.String(), stderr.String()
+	}
+	t.Fatalf("exec %q: %v", command, err)
+	return 0, "", ""
+}
+
+func TestExecIsRefusedWithExplanation(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "execalice", models.PlanPro)
+
+	for _, command := range []string{"ls -la", "rsync --daemon", "scp -t /app"} {
+		code, _, stderr := runExec(t, addr, hostKey, "execalice", alice, command, "")
+		if code != 1 {
+			t.Fatalf("exec %q = %d, esperado saída 1", command, code)
+		}
+		if !strings.Contains(stderr, "Shell e outros comandos") {
+			t.Fatalf("stderr sem a explicação: %q", stderr)
+		}
+	}
+}
+
+func TestRsyncRunsInHelperContainer(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "rsyncalice", models.PlanPro)
+	host := newTestApp(t, "rsyncalice", "rsyncweb", "data")
+	fake := engine.Default().(*engine.FakeRuntime)
+
+	// 🐳 O rsync simulado confere o container auxiliar e ecoa a entrada
+	var gotCmd []string
+	var gotSpec engine.Spec
+	var helper string
+	fake.SetStream(testRsyncImage, func(cmd []string, stdin io.Reader, stdout, stderr io.Writer) int {
+		gotCmd = cmd
+		containers, _ := fake.List(context.Background(), engine.ListOptions{Labels: map[string]string{rsyncRoleLabel: rsyncRole}})
+		if len(containers) == 1 {
+			helper = containers[0].Name
+			if info, err := fake.Inspect(context.Background(), helper); err == nil {
+				gotSpec = info.Spec
+			}
+		}
+		data, _ := io.ReadAll(stdin)
+		_, _ = stdout.Write(data)
+		return 0
+	})
+	t.Cleanup(func() { fake.SetStream(testRsyncImage, nil) })
+
+	code, stdout, stderr := runExec(t, addr, hostKey, "rsyncalice", alice, `rsync --server -vlogDtpre.iLsfxC . /rsyncweb/volumes/data/meus\ arquivos/`, "protocolo")
+	if code != 0 || stdout != "protocolo" {
+		t.Fatalf("rsync = %d, saída %q, stderr %q", code, stdout, stderr)
+	}
+	max := int64(models.Plans[models.PlanPro].FileManagerMaxMB) * 1024 * 1024
+	want := []string{"rsync", "--server", "-vlogDtpre.iLsfxC", fmt.Sprintf("--max-size=%d", max), ".", "/volumes/data/meus arquivos/"}
+	if strings.Join(gotCmd, "|") != strings.Join(want, "|") {
+		t.Fatalf("comando no container = %q, esperado %q", gotCmd, want)
+	}
+	abs, _ := filepath.Abs(host)
+	if len(gotSpec.Binds) != 1 || gotSpec.Binds[0] != abs+":/volumes/data" || gotSpec.NetworkMode != "none" {
+		t.Fatalf("container auxiliar = binds %v, rede %q", gotSpec.Binds, gotSpec.NetworkMode)
+	}
+	if _, err := fake.Inspect(context.Background(), helper); !errors.Is(err, engine.ErrNotFound) {
+		t.Fatalf("container auxiliar %q não foi removido: %v", helper, err)
+	}
+
+	// ⬇️ Download monta o volume somente leitura e o código de saída chega ao cliente
+	fake.SetStream(testRsyncImage, func(cmd []string, stdin io.Reader, stdout, stderr io.Writer) int {
+		gotCmd = cmd
+		containers, _ := fake.List(context.Background(), engine.ListOptions{Labels: map[string]string{rsyncRoleLabel: rsyncRole}})
+		if len(containers) == 1 {
+			if info, err := fake.Inspect(context.Background(), containers[0].Name); err == nil {
+				gotSpec = info.Spec
+			}
+		}
+		return 23
+	})
+	code, _, _ = runExec(t, addr, hostKey, "rsyncalice", alice, "rsync --server --sender -vlogDtpre.iLsfxC . /rsyncweb/volumes/data", "")
+	if code != 23 {
+		t.Fatalf("código de saída = %d, esperado 23", code)
+	}
+	if gotCmd[len(gotCmd)-1] != "/volumes/data" || len(gotSpec.Binds) != 1 || !strings.HasSuffix(gotSpec.Binds[0], ":ro") {
+		t.Fatalf("download = comando %q, binds %v", gotCmd, gotSpec.Binds)
+	}
+}
+
+func TestRsyncRefusesPathsOutsideVolumes(t *testing.T) {
+	addr, hostKey := startServer(t)
+	alice := newTestUser(t, "rsyncrefalice", models.PlanPro)
+	newTestUser(t, "rsyncrefbob", models.PlanPro)
+	newTestApp(t, "rsyncrefalice", "refweb", "data")
+	newTestApp(t, "rsyncrefalice", "refapi", "cache")
+	newTestApp(t, "rsyncrefbob", "refbob", "data")
+	fake := engine.Default().(*engine.FakeRuntime)
+	ran := false
+	fake.SetStream(testRsyncImage, func(cmd []string, stdin io.Reader, stdout, stderr io.Writer) int {
+		ran = true
+		return 0
+	})
+	t.Cleanup(func() { fake.SetStream(testRsyncImage, nil) })
+
+	cases := map[string]string{
+		"aplicação de outro usuário": "rsync --server -vlogDtpre.iLsfxC . /refbob/volumes/data/",
+		"código":                     "rsync --server -vlogDtpre.iLsfxC . /refweb/source/",
+		"raiz virtual":               "rsync --server --sender -vlogDtpre.iLsfxC . /",
+		"dois volumes":               "rsync --server --sender -vlogDtpre.iLsfxC . /refweb/volumes/data/ /refapi/volumes/cache/",
+		"escapando com ..":           "rsync --server -vlogDtpre.iLsfxC . /refweb/volumes/data/../../../refbob/volumes/data/",
+		"protect-args":               "rsync --server -vlogDtprse.iLsfxC . ",
+		"sem caminho":                "rsync --server -vlogDtpre.iLsfxC .",
+	}
+	for name, command := range cases {
+		code, _, stderr := runExec(t, addr, hostKey, "rsyncrefalice", alice, command, "")
+		if code != 1 || !strings.HasPrefix(stderr, "rsync: ") {
+			t.Fatalf("%s: rsync = %d, stderr %q, esperado recusa", name, code, stderr)
+		}
+	}
+	if ran {
+		t.Fatalf("rsync rodou num pedido recusado")
+	}
+	if containers, _ := fake.List(context.Background(), engine.ListOptions{All: true, Labels: map[string]string{rsyncRoleLabel: rsyncRole}}); len(containers) != 0 {
+		t.Fatalf("containers auxiliares sobraram: %v", containers)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	cases := map[string][]string{
+		`rsync --server . a\ b/`:        {"rsync", "--server", ".", "a b/"},
+		`rsync --server . 'a b' "c\"d"`: {"rsync", "--server", ".", "a b", `c"d`},
+		"rsync  --server\t.":            {"rsync", "--server", "."},
+		`rsync --server . x''y`:         {"rsync", "--server", ".", "xy"},
+	}
+	for in, want := range cases {
+		got, err := splitCommand(in)
+		if err != nil || strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Fatalf("splitCommand(%q) = %q, %v, esperado %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{`rsync 'aberto`, `rsync "aberto`} {
+		if _, err := splitCommand(in); err == nil {
+			t.Fatalf("splitCommand(%q) aceito", in)
+		}
+	}
+}
+
+func TestHostKeyCreatedOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "host_ed25519")
+	first, err := loadOrCreateHostKey(path)
+	if err != nil {
+		t.Fatalf("loadOrCreateHostKey: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("chave do servidor sem 0600: %v, %v", info, err)
+	}
+	again, err := loadOrCreateHostKey(path)
+	if err != nil {
+		t.Fatalf("recarregar chave: %v", err)
+	}
+	if ssh.FingerprintSHA256(first.PublicKey()) != ssh.FingerprintSHA256(again.PublicKey()) {
+		t.Fatalf("chave do servidor trocou entre subidas")
+	}
+}
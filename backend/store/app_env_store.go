@@ -0,0 +1,153 @@
+// store/app_env_store.go
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho padrão das variáveis de ambiente em disco
+const DefaultAppEnvStorePath = "./database/app_env.json"
+
+// 🔒 Repositório das variáveis e segredos das aplicações, indexado pelo ID da aplicação
+type AppEnvRepository struct {
+	mu         sync.RWMutex
+	envs       map[string]*models.AppEnv
+	path       string
+	collection persistence.Collection
+}
+
+// 🔑 Ambientes em memória (segredos continuam selados)
+var AppEnvStore = &AppEnvRepository{
+	envs: map[string]*models.AppEnv{},
+	path: DefaultAppEnvStorePath,
+}
+
+// 🔍 Busca o ambiente da aplicação (retorna cópia)
+func (r *AppEnvRepository) Get(appID string) (*models.AppEnv, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	env, ok := r.envs[appID]
+	if !ok {
+		return nil, false
+	}
+	return env.Clone(), true
+}
+
+// ✏️ Altera o ambiente sob lock, criando-o se ainda não existir (leitura-modificação-escrita atômica).
+// Um erro devolvido por fn cancela a alteração.
+func (r *AppEnvRepository) Update(appID, username string, fn func(env *models.AppEnv) error) (*models.AppEnv, error) {
+	if appID == "" {
+		return nil, errors.New("aplicação inválida")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated := &models.AppEnv{AppID: appID, Username: username}
+	if current, ok := r.envs[appID]; ok {
+		updated = current.Clone()
+	}
+	if err := fn(updated); err != nil {
+		return nil, err
+	}
+	updated.AppID = appID
+	updated.UpdatedAt = time.Now()
+	r.envs[appID] = updated
+
+	if err := r.openLocked(); err != nil {
+		return nil, err
+	}
+	return updated.Clone(), r.collection.Put(appID, updated)
+}
+
+// 🗑️ Remove o ambiente da aplicação e persiste
+func (r *AppEnvRepository) Delete(appID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.envs[appID]; !ok {
+		return nil
+	}
+	delete(r.envs, appID)
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Delete(appID)
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *AppEnvRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionAppEnv, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+// 🔄 Carrega os ambientes a partir do caminho informado
+func (r *AppEnvRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler variáveis de ambiente: %w", err)
+	}
+
+	envs := map[string]*models.AppEnv{}
+	for appID, raw := range records {
+		var env models.AppEnv
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return fmt.Errorf("ambiente '%s' inválido em %s: %w", appID, path, err)
+		}
+		envs[appID] = &env
+	}
+	r.envs = envs
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *AppEnvRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔄 Carrega os ambientes do disco
+func LoadAppEnvStoreFromDisk(filePath string) error {
+	return AppEnvStore.Load(filePath)
+}
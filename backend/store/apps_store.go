@@ -5,58 +5,283 @@ package store
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
-	"os"
+	"strings"
+	"sync"
 	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
 )
 
+// 📁 Caminho padrão do AppStore em disco
+const DefaultAppStorePath = "./database/appstore.json"
+
+// 🔒 Repositório de aplicações com lock interno e backend de persistência plugável
+type AppRepository struct {
+	mu         sync.RWMutex
+	apps       map[string]*models.App
+	path       string
+	collection persistence.Collection
+	delHooks   []AppDeleteFunc
+}
+
+// 🔔 Chamada após a remoção de uma aplicação já persistida (fora do lock)
+type AppDeleteFunc func(app *models.App)
+
 // 🔒 Armazena todas as aplicações em memória
-var AppStore = map[string]*models.App{}
+var AppStore = &AppRepository{
+	apps: map[string]*models.App{},
+	path: DefaultAppStorePath,
+}
 
-// 🔒 Armazena todos os clientes/usuários em memória
-// Busca aplicação pelo ID
-func GetAppByID(appID string) (*models.App, error) {
-	app, ok := AppStore[appID]
+// 🔍 Busca aplicação pelo ID (retorna cópia)
+func (r *AppRepository) Get(id string) (*models.App, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	app, ok := r.apps[id]
+	if !ok {
+		return nil, false
+	}
+	return app.Clone(), true
+}
+
+// ✅ Indica se existe aplicação com o ID informado
+func (r *AppRepository) Exists(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.apps[id]
+	return ok
+}
+
+// 📋 Lista cópias de todas as aplicações
+func (r *AppRepository) List() []*models.App {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*models.App, 0, len(r.apps))
+	for _, app := range r.apps {
+		out = append(out, app.Clone())
+	}
+	return out
+}
+
+// 👤 Lista cópias das aplicações de um usuário
+func (r *AppRepository) ListByUser(username string) []*models.App {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*models.App
+	for _, app := range r.apps {
+		if strings.EqualFold(app.Username, username) {
+			out = append(out, app.Clone())
+		}
+	}
+	return out
+}
+
+// 🐳 Busca aplicação pelo nome do container (ou pelo próprio ID)
+func (r *AppRepository) FindByContainer(name string) (*models.App, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, app := range r.apps {
+		if app.ContainerName == name || app.ID == name {
+			return app.Clone(), true
+		}
+	}
+	return nil, false
+}
+
+// 🔔 Registra um observador de remoção de aplicação (ex.: limpeza de domínios)
+func (r *AppRepository) OnDelete(fn AppDeleteFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delHooks = append(r.delHooks, fn)
+}
+
+// 💾 Adiciona ou substitui uma aplicação e persiste
+func (r *AppRepository) Save(app *models.App) error {
+	if app == nil || app.ID == "" {
+		return errors.New("aplicação inválida")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := app.Clone()
+	r.apps[stored.ID] = stored
+	return r.persistLocked(stored.ID, stored)
+}
+
+// ✏️ Altera uma aplicação sob lock (leitura-modificação-escrita atômica)
+func (r *AppRepository) Update(id string, fn func(app *models.App)) (*models.App, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.apps[id]
 	if !ok {
 		return nil, errors.New("aplicação não encontrada")
 	}
-	return app, nil
+
+	updated := current.Clone()
+	fn(updated)
+	updated.ID = id
+	r.apps[id] = updated
+
+	return updated.Clone(), r.persistLocked(id, updated)
 }
 
-// 💾 Adiciona ou atualiza uma aplicação e salva em disco
-func SaveApp(app *models.App) {
-	AppStore[app.ID] = app
-	err := SaveAppStoreToDisk("./database/appstore.json")
-	if err != nil {
-		log.Println("❌ Erro ao salvar AppStore:", err)
+// 📊 Atualiza apenas em memória (métricas voláteis coletadas do Docker)
+// As alterações seguem para o disco junto com a próxima gravação da aplicação.
+func (r *AppRepository) UpdateVolatile(id string, fn func(app *models.App)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.apps[id]
+	if !ok {
+		return false
 	}
+
+	updated := current.Clone()
+	fn(updated)
+	updated.ID = id
+	r.apps[id] = updated
+	return true
 }
 
-// 💾 Salva o AppStore em disco
-func SaveAppStoreToDisk(filePath string) error {
-	os.MkdirAll("./database", os.ModePerm)
-	data, err := json.MarshalIndent(AppStore, "", "  ")
+// 🗑️ Remove uma aplicação e persiste
+func (r *AppRepository) Delete(id string) error {
+	r.mu.Lock()
+	removed, ok := r.apps[id]
+	if !ok {
+		r.mu.Unlock()
+		return nil
+	}
+	delete(r.apps, id)
+
+	err := r.openLocked()
+	if err == nil {
+		err = r.collection.Delete(id)
+	}
+	hooks := r.delHooks
+	r.mu.Unlock()
+
+	if err == nil {
+		for _, hook := range hooks {
+			hook(removed.Clone())
+		}
+	}
+	return err
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *AppRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionApps, r.path)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0644)
+	r.collection = collection
+	return nil
 }
 
-func LoadAppStoreFromDisk(filePath string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
+func (r *AppRepository) persistLocked(id string, app *models.App) error {
+	if err := r.openLocked(); err != nil {
 		return err
 	}
+	return r.collection.Put(id, app)
+}
 
-	var temp map[string]*models.App
-	if err := json.Unmarshal(data, &temp); err != nil {
+// 📸 Consolida as alterações pendentes no backend
+func (r *AppRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.openLocked(); err != nil {
 		return err
 	}
+	return r.collection.Flush()
+}
+
+// 🔄 Carrega (e recupera) o AppStore a partir do caminho informado
+func (r *AppRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
 
-	AppStore = temp
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler aplicações: %w", err)
+	}
+
+	apps := map[string]*models.App{}
+	for id, raw := range records {
+		var app models.App
+		if err := json.Unmarshal(raw, &app); err != nil {
+			return fmt.Errorf("aplicação '%s' inválida em %s: %w", id, path, err)
+		}
+		apps[id] = &app
+	}
+	r.apps = apps
 	return nil
 }
 
+// 🔒 Faz o flush final e fecha a coleção
+func (r *AppRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔒 Armazena todos os clientes/usuários em memória
+// Busca aplicação pelo ID
+func GetAppByID(appID string) (*models.App, error) {
+	app, ok := AppStore.Get(appID)
+	if !ok {
+		return nil, errors.New("aplicação não encontrada")
+	}
+	return app, nil
+}
+
+// 💾 Adiciona ou atualiza uma aplicação e salva em disco
+func SaveApp(app *models.App) {
+	if err := AppStore.Save(app); err != nil {
+		log.Println("❌ Erro ao salvar AppStore:", err)
+	}
+}
+
+// 💾 Salva o AppStore em disco (caminho definido em LoadAppStoreFromDisk)
+func SaveAppStoreToDisk(_ string) error {
+	return AppStore.Flush()
+}
+
+func LoadAppStoreFromDisk(filePath string) error {
+	return AppStore.Load(filePath)
+}
+
 //// Adiciona ou atualiza uma aplicação
 //func SaveApp(app *models.App) {
 //	AppStore[app.ID] = app
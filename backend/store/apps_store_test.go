@@ -0,0 +1,74 @@
+//backend/store/apps_store_test.go
+
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"virtuscloud/backend/models"
+)
+
+// 🏁 Rode com -race: Save, Update e leituras ao mesmo tempo no mesmo repositório
+func TestAppRepositoryConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appstore.json")
+	repo := &AppRepository{apps: map[string]*models.App{}}
+	if err := repo.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := repo.Save(&models.App{ID: "contador", Username: "todos"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	const workers, apps = 8, 20
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < apps; i++ {
+				id := fmt.Sprintf("app-%d-%d", w, i)
+				if err := repo.Save(&models.App{ID: id, Username: fmt.Sprintf("user%d", w)}); err != nil {
+					t.Errorf("Save: %v", err)
+					return
+				}
+				// ✏️ Todos os workers disputam o mesmo contador
+				if _, err := repo.Update("contador", func(app *models.App) { app.Port++ }); err != nil {
+					t.Errorf("Update: %v", err)
+					return
+				}
+				_ = repo.ListByUser(fmt.Sprintf("user%d", (w+1)%workers))
+			}
+		}(w)
+	}
+	wg.Wait()
+	if got := repo.mustGet(t, "contador").Port; got != workers*apps {
+		t.Fatalf("contador = %d, esperado %d (atualização perdida)", got, workers*apps)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reloaded := &AppRepository{apps: map[string]*models.App{}}
+	if err := reloaded.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer reloaded.Close()
+	if n := len(reloaded.List()); n != workers*apps+1 {
+		t.Fatalf("%d aplicações no disco, esperado %d", n, workers*apps+1)
+	}
+	if got := reloaded.mustGet(t, "contador").Port; got != workers*apps {
+		t.Fatalf("contador no disco = %d, esperado %d", got, workers*apps)
+	}
+}
+
+func (r *AppRepository) mustGet(t *testing.T, id string) *models.App {
+	t.Helper()
+	app, ok := r.Get(id)
+	if !ok {
+		t.Fatalf("aplicação %s não encontrada", id)
+	}
+	return app
+}
@@ -0,0 +1,158 @@
+// store/certificates_store.go
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho padrão dos certificados em disco
+const DefaultCertificateStorePath = "./database/certificates.json"
+
+// 🔒 Repositório de certificados TLS e da conta ACME, indexados por nome
+type CertificateRepository struct {
+	mu         sync.RWMutex
+	certs      map[string]*models.Certificate
+	path       string
+	collection persistence.Collection
+}
+
+// 🔒 Certificados em memória (o material privado continua selado)
+var CertificateStore = &CertificateRepository{
+	certs: map[string]*models.Certificate{},
+	path:  DefaultCertificateStorePath,
+}
+
+// 🔍 Busca pelo nome (retorna cópia)
+func (r *CertificateRepository) Get(name string) (*models.Certificate, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cert, ok := r.certs[name]
+	if !ok {
+		return nil, false
+	}
+	return cert.Clone(), true
+}
+
+// 📋 Lista cópias dos registros do tipo informado (vazio = todos), ordenadas por nome
+func (r *CertificateRepository) List(kind string) []*models.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*models.Certificate
+	for _, cert := range r.certs {
+		if kind == "" || cert.Kind == kind {
+			out = append(out, cert.Clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// 💾 Adiciona ou substitui e persiste
+func (r *CertificateRepository) Save(cert *models.Certificate) error {
+	if cert == nil || cert.Name == "" {
+		return errors.New("certificado inválido")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := cert.Clone()
+	r.certs[stored.Name] = stored
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Put(stored.Name, stored)
+}
+
+// 🗑️ Remove e persiste
+func (r *CertificateRepository) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.certs[name]; !ok {
+		return nil
+	}
+	delete(r.certs, name)
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Delete(name)
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *CertificateRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionCertificates, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+// 🔄 Carrega os certificados a partir do caminho informado
+func (r *CertificateRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler certificados: %w", err)
+	}
+
+	certs := map[string]*models.Certificate{}
+	for name, raw := range records {
+		var cert models.Certificate
+		if err := json.Unmarshal(raw, &cert); err != nil {
+			return fmt.Errorf("certificado '%s' inválido em %s: %w", name, path, err)
+		}
+		certs[name] = &cert
+	}
+	r.certs = certs
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *CertificateRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔄 Carrega os certificados do disco
+func LoadCertificateStoreFromDisk(filePath string) error {
+	return CertificateStore.Load(filePath)
+}
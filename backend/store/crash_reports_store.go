@@ -0,0 +1,183 @@
+// store/crash_reports_store.go
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho padrão dos relatórios de crash em disco
+const DefaultCrashReportStorePath = "./database/crash_reports.json"
+
+// 🗂️ Relatórios mantidos por aplicação (os mais antigos saem primeiro)
+const MaxCrashReportsPerApp = 20
+
+// 🔒 Repositório de relatórios de crash, indexados por "<appId>/<unix nanos>"
+type CrashReportRepository struct {
+	mu         sync.RWMutex
+	reports    map[string]*models.CrashReport
+	path       string
+	collection persistence.Collection
+}
+
+// 💥 Relatórios de crash em memória
+var CrashReportStore = &CrashReportRepository{
+	reports: map[string]*models.CrashReport{},
+	path:    DefaultCrashReportStorePath,
+}
+
+// 📱 Relatórios da aplicação, do mais recente para o mais antigo (retorna cópias)
+func (r *CrashReportRepository) ListByApp(appID string) []*models.CrashReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*models.CrashReport
+	for _, report := range r.reports {
+		if report.AppID == appID {
+			out = append(out, report.Clone())
+		}
+	}
+	sortNewestFirst(out)
+	return out
+}
+
+// 🆕 Registra o relatório e descarta os excedentes da aplicação
+func (r *CrashReportRepository) Add(report *models.CrashReport) error {
+	if report == nil || report.ID == "" || report.AppID == "" {
+		return errors.New("relatório de crash inválido")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := report.Clone()
+	r.reports[stored.ID] = stored
+	if err := r.persistLocked(stored.ID, stored); err != nil {
+		return err
+	}
+
+	var same []*models.CrashReport
+	for _, existing := range r.reports {
+		if existing.AppID == stored.AppID {
+			same = append(same, existing)
+		}
+	}
+	sortNewestFirst(same)
+	for _, old := range same[min(len(same), MaxCrashReportsPerApp):] {
+		delete(r.reports, old.ID)
+		if err := r.collection.Delete(old.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 🗑️ Remove todos os relatórios da aplicação
+func (r *CrashReportRepository) DeleteByApp(appID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	for id, report := range r.reports {
+		if report.AppID != appID {
+			continue
+		}
+		delete(r.reports, id)
+		if err := r.collection.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortNewestFirst(reports []*models.CrashReport) {
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].CrashedAt.Equal(reports[j].CrashedAt) {
+			return reports[i].CrashedAt.After(reports[j].CrashedAt)
+		}
+		return reports[i].ID > reports[j].ID
+	})
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *CrashReportRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionCrashReports, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+func (r *CrashReportRepository) persistLocked(id string, report *models.CrashReport) error {
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Put(id, report)
+}
+
+// 🔄 Carrega os relatórios a partir do caminho informado
+func (r *CrashReportRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler relatórios de crash: %w", err)
+	}
+
+	reports := map[string]*models.CrashReport{}
+	for id, raw := range records {
+		var report models.CrashReport
+		if err := json.Unmarshal(raw, &report); err != nil {
+			return fmt.Errorf("relatório de crash '%s' inválido em %s: %w", id, path, err)
+		}
+		reports[id] = &report
+	}
+	r.reports = reports
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *CrashReportRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔄 Carrega os relatórios de crash do disco
+func LoadCrashReportStoreFromDisk(filePath string) error {
+	return CrashReportStore.Load(filePath)
+}
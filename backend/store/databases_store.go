@@ -0,0 +1,238 @@
+// store/databases_store.go
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho padrão dos bancos gerenciados em disco
+const DefaultDatabaseStorePath = "./database/databases.json"
+
+// 🔒 Repositório de bancos gerenciados, indexados pelo ID
+type DatabaseRepository struct {
+	mu         sync.RWMutex
+	databases  map[string]*models.Database
+	path       string
+	collection persistence.Collection
+	delHooks   []DatabaseDeleteFunc
+}
+
+// 🔔 Chamada após a remoção de um banco já persistida (fora do lock)
+type DatabaseDeleteFunc func(database *models.Database)
+
+// 🗄️ Bancos gerenciados em memória
+var DatabaseStore = &DatabaseRepository{
+	databases: map[string]*models.Database{},
+	path:      DefaultDatabaseStorePath,
+}
+
+// 🔍 Busca banco pelo ID (retorna cópia)
+func (r *DatabaseRepository) Get(id string) (*models.Database, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	database, ok := r.databases[id]
+	if !ok {
+		return nil, false
+	}
+	return database.Clone(), true
+}
+
+// 📋 Lista cópias de todos os bancos (ordenados por ID)
+func (r *DatabaseRepository) List() []*models.Database {
+	return r.filter(func(*models.Database) bool { return true })
+}
+
+// 👤 Lista cópias dos bancos de um usuário
+func (r *DatabaseRepository) ListByUser(username string) []*models.Database {
+	return r.filter(func(d *models.Database) bool { return strings.EqualFold(d.Username, username) })
+}
+
+// 🔗 Lista cópias dos bancos ligados a uma aplicação
+func (r *DatabaseRepository) ListByApp(appID string) []*models.Database {
+	return r.filter(func(d *models.Database) bool {
+		for _, link := range d.Links {
+			if link.AppID == appID {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// 🐳 Busca o banco pelo nome do container
+func (r *DatabaseRepository) FindByContainer(name string) (*models.Database, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, database := range r.databases {
+		if database.ContainerName == name {
+			return database.Clone(), true
+		}
+	}
+	return nil, false
+}
+
+// 🔔 Registra um observador de remoção de banco (ex.: limpeza das variáveis das aplicações)
+func (r *DatabaseRepository) OnDelete(fn DatabaseDeleteFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delHooks = append(r.delHooks, fn)
+}
+
+func (r *DatabaseRepository) filter(keep func(*models.Database) bool) []*models.Database {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*models.Database
+	for _, database := range r.databases {
+		if keep(database) {
+			out = append(out, database.Clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// 🆕 Registra um banco novo; falha se o ID já existir
+func (r *DatabaseRepository) Create(database *models.Database) error {
+	if database == nil || database.ID == "" {
+		return errors.New("banco inválido")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, taken := r.databases[database.ID]; taken {
+		return fmt.Errorf("banco %s já existe", database.ID)
+	}
+	stored := database.Clone()
+	r.databases[stored.ID] = stored
+	return r.persistLocked(stored.ID, stored)
+}
+
+// ✏️ Altera um banco sob lock (leitura-modificação-escrita atômica)
+func (r *DatabaseRepository) Update(id string, fn func(database *models.Database)) (*models.Database, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.databases[id]
+	if !ok {
+		return nil, errors.New("banco não encontrado")
+	}
+
+	updated := current.Clone()
+	fn(updated)
+	updated.ID = id
+	r.databases[id] = updated
+
+	return updated.Clone(), r.persistLocked(id, updated)
+}
+
+// 🗑️ Remove um banco e persiste
+func (r *DatabaseRepository) Delete(id string) error {
+	r.mu.Lock()
+	removed, ok := r.databases[id]
+	if !ok {
+		r.mu.Unlock()
+		return nil
+	}
+	delete(r.databases, id)
+
+	err := r.openLocked()
+	if err == nil {
+		err = r.collection.Delete(id)
+	}
+	hooks := r.delHooks
+	r.mu.Unlock()
+
+	if err == nil {
+		for _, hook := range hooks {
+			hook(removed.Clone())
+		}
+	}
+	return err
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *DatabaseRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionDatabases, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+func (r *DatabaseRepository) persistLocked(id string, database *models.Database) error {
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Put(id, database)
+}
+
+// 🔄 Carrega os bancos a partir do caminho informado
+func (r *DatabaseRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler bancos gerenciados: %w", err)
+	}
+
+	databases := map[string]*models.Database{}
+	for id, raw := range records {
+		var database models.Database
+		if err := json.Unmarshal(raw, &database); err != nil {
+			return fmt.Errorf("banco '%s' inválido em %s: %w", id, path, err)
+		}
+		databases[id] = &database
+	}
+	r.databases = databases
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *DatabaseRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔄 Carrega os bancos gerenciados do disco
+func LoadDatabaseStoreFromDisk(filePath string) error {
+	return DatabaseStore.Load(filePath)
+}
@@ -0,0 +1,210 @@
+// store/deployments_store.go
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho padrão dos deploys em disco
+const DefaultDeploymentStorePath = "./database/deployments.json"
+
+// 🗂️ Deploys mantidos por aplicação (os mais antigos saem primeiro)
+const MaxDeploymentsPerApp = 30
+
+// 🔒 Repositório de deploys, indexados pelo ID do deploy
+type DeploymentRepository struct {
+	mu          sync.RWMutex
+	deployments map[string]*models.Deployment
+	path        string
+	collection  persistence.Collection
+}
+
+// 🚀 Deploys em memória
+var DeploymentStore = &DeploymentRepository{
+	deployments: map[string]*models.Deployment{},
+	path:        DefaultDeploymentStorePath,
+}
+
+// 🔍 Deploy pelo ID (retorna cópia)
+func (r *DeploymentRepository) Get(id string) (*models.Deployment, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.deployments[id]
+	return d.Clone(), ok
+}
+
+// 📱 Deploys da aplicação, do mais recente para o mais antigo (retorna cópias)
+func (r *DeploymentRepository) ListByApp(appID string) []*models.Deployment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*models.Deployment
+	for _, d := range r.deployments {
+		if d.AppID == appID {
+			out = append(out, d.Clone())
+		}
+	}
+	sortDeploymentsNewestFirst(out)
+	return out
+}
+
+// 📋 Todos os deploys (retorna cópias)
+func (r *DeploymentRepository) List() []*models.Deployment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*models.Deployment, 0, len(r.deployments))
+	for _, d := range r.deployments {
+		out = append(out, d.Clone())
+	}
+	return out
+}
+
+// 💾 Cria ou atualiza o deploy; devolve os deploys antigos descartados da aplicação
+func (r *DeploymentRepository) Save(d *models.Deployment) ([]*models.Deployment, error) {
+	if d == nil || d.ID == "" || d.AppID == "" {
+		return nil, errors.New("deploy inválido")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := d.Clone()
+	_, existed := r.deployments[stored.ID]
+	r.deployments[stored.ID] = stored
+	if err := r.persistLocked(stored.ID, stored); err != nil {
+		return nil, err
+	}
+	if existed {
+		return nil, nil
+	}
+
+	var same []*models.Deployment
+	for _, existing := range r.deployments {
+		if existing.AppID == stored.AppID {
+			same = append(same, existing)
+		}
+	}
+	sortDeploymentsNewestFirst(same)
+	var removed []*models.Deployment
+	for _, old := range same[min(len(same), MaxDeploymentsPerApp):] {
+		delete(r.deployments, old.ID)
+		if err := r.collection.Delete(old.ID); err != nil {
+			return removed, err
+		}
+		removed = append(removed, old)
+	}
+	return removed, nil
+}
+
+// 🗑️ Remove todos os deploys da aplicação
+func (r *DeploymentRepository) DeleteByApp(appID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	for id, d := range r.deployments {
+		if d.AppID != appID {
+			continue
+		}
+		delete(r.deployments, id)
+		if err := r.collection.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortDeploymentsNewestFirst(list []*models.Deployment) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].StartedAt.Equal(list[j].StartedAt) {
+			return list[i].StartedAt.After(list[j].StartedAt)
+		}
+		return list[i].ID > list[j].ID
+	})
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *DeploymentRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionDeployments, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+func (r *DeploymentRepository) persistLocked(id string, d *models.Deployment) error {
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Put(id, d)
+}
+
+// 🔄 Carrega os deploys a partir do caminho informado
+func (r *DeploymentRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler deploys: %w", err)
+	}
+
+	deployments := map[string]*models.Deployment{}
+	for id, raw := range records {
+		var d models.Deployment
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return fmt.Errorf("deploy '%s' inválido em %s: %w", id, path, err)
+		}
+		deployments[id] = &d
+	}
+	r.deployments = deployments
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *DeploymentRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔄 Carrega os deploys do disco
+func LoadDeploymentStoreFromDisk(filePath string) error {
+	return DeploymentStore.Load(filePath)
+}
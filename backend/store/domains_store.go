@@ -0,0 +1,214 @@
+// store/domains_store.go
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho padrão dos domínios personalizados em disco
+const DefaultDomainStorePath = "./database/domains.json"
+
+// 🔒 Repositório de domínios personalizados, indexados por hostname
+type DomainRepository struct {
+	mu         sync.RWMutex
+	domains    map[string]*models.Domain
+	path       string
+	collection persistence.Collection
+}
+
+// 🌍 Domínios personalizados em memória
+var DomainStore = &DomainRepository{
+	domains: map[string]*models.Domain{},
+	path:    DefaultDomainStorePath,
+}
+
+// 🔍 Busca domínio pelo hostname (retorna cópia)
+func (r *DomainRepository) Get(hostname string) (*models.Domain, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	domain, ok := r.domains[strings.ToLower(hostname)]
+	if !ok {
+		return nil, false
+	}
+	return domain.Clone(), true
+}
+
+// 📋 Lista cópias de todos os domínios (ordenados por hostname)
+func (r *DomainRepository) List() []*models.Domain {
+	return r.filter(func(*models.Domain) bool { return true })
+}
+
+// 👤 Lista cópias dos domínios de um usuário
+func (r *DomainRepository) ListByUser(username string) []*models.Domain {
+	return r.filter(func(d *models.Domain) bool { return strings.EqualFold(d.Username, username) })
+}
+
+// 📱 Lista cópias dos domínios de uma aplicação
+func (r *DomainRepository) ListByApp(appID string) []*models.Domain {
+	return r.filter(func(d *models.Domain) bool { return d.AppID == appID })
+}
+
+func (r *DomainRepository) filter(keep func(*models.Domain) bool) []*models.Domain {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*models.Domain
+	for _, domain := range r.domains {
+		if keep(domain) {
+			out = append(out, domain.Clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hostname < out[j].Hostname })
+	return out
+}
+
+// 🆕 Registra um domínio novo; falha se o hostname já estiver em uso
+func (r *DomainRepository) Create(domain *models.Domain) error {
+	if domain == nil || domain.Hostname == "" {
+		return errors.New("domínio inválido")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := domain.Clone()
+	stored.Hostname = strings.ToLower(stored.Hostname)
+	if _, taken := r.domains[stored.Hostname]; taken {
+		return fmt.Errorf("domínio %s já cadastrado", stored.Hostname)
+	}
+	r.domains[stored.Hostname] = stored
+	return r.persistLocked(stored.Hostname, stored)
+}
+
+// ✏️ Altera um domínio sob lock (leitura-modificação-escrita atômica)
+func (r *DomainRepository) Update(hostname string, fn func(domain *models.Domain)) (*models.Domain, error) {
+	hostname = strings.ToLower(hostname)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.domains[hostname]
+	if !ok {
+		return nil, errors.New("domínio não encontrado")
+	}
+
+	updated := current.Clone()
+	fn(updated)
+	updated.Hostname = hostname
+	r.domains[hostname] = updated
+
+	return updated.Clone(), r.persistLocked(hostname, updated)
+}
+
+// 🗑️ Remove um domínio e persiste
+func (r *DomainRepository) Delete(hostname string) error {
+	hostname = strings.ToLower(hostname)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.domains[hostname]; !ok {
+		return nil
+	}
+	delete(r.domains, hostname)
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Delete(hostname)
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *DomainRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionDomains, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+func (r *DomainRepository) persistLocked(hostname string, domain *models.Domain) error {
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Put(hostname, domain)
+}
+
+// 📸 Consolida as alterações pendentes no backend
+func (r *DomainRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Flush()
+}
+
+// 🔄 Carrega os domínios a partir do caminho informado
+func (r *DomainRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler domínios: %w", err)
+	}
+
+	domains := map[string]*models.Domain{}
+	for hostname, raw := range records {
+		var domain models.Domain
+		if err := json.Unmarshal(raw, &domain); err != nil {
+			return fmt.Errorf("domínio '%s' inválido em %s: %w", hostname, path, err)
+		}
+		domains[hostname] = &domain
+	}
+	r.domains = domains
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *DomainRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔄 Carrega os domínios personalizados do disco
+func LoadDomainStoreFromDisk(filePath string) error {
+	return DomainStore.Load(filePath)
+}
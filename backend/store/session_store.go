@@ -5,20 +5,59 @@ package store
 import (
 	"encoding/json"
 	"os"
+	"sync"
 	"virtuscloud/backend/models"
 )
 
+// 🔒 Repositório das sessões ativas em memória, indexadas por username
+type SessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*models.SessionData
+}
+
 // 🗃️ Sessões ativas em memória, indexadas por username
-var SessionStore = make(map[string]*models.SessionData)
+var SessionStore = &SessionRepository{sessions: map[string]*models.SessionData{}}
+
+// 💾 Registra ou substitui a sessão do usuário
+func (r *SessionRepository) Save(session *models.SessionData) {
+	if session == nil {
+		return
+	}
+	copy := *session
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[copy.Username] = &copy
+}
+
+// 🔍 Busca a sessão do usuário (retorna cópia)
+func (r *SessionRepository) Get(username string) (*models.SessionData, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[username]
+	if !ok {
+		return nil, false
+	}
+	copy := *session
+	return &copy, true
+}
+
+// 🗑️ Remove a sessão do usuário
+func (r *SessionRepository) Delete(username string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, username)
+}
 
 // 💾 Salva sessão em memória para uso interno (ex: deploy, autenticação)
 func SaveSession(session *models.SessionData) {
-	SessionStore[session.Username] = session
+	SessionStore.Save(session)
 }
 
 // 🔐 Recupera o token JWT da sessão do usuário
 func GetSessionToken(username string) string {
-	if session, ok := SessionStore[username]; ok {
+	if session, ok := SessionStore.Get(username); ok {
 		return session.Token
 	}
 	return ""
@@ -27,7 +66,7 @@ func GetSessionToken(username string) string {
 // 🔐 Recupera o usuário logado a partir do arquivo de sessão
 func GetLoggedUser() *models.User {
 	// 📂 Lê o conteúdo do arquivo de sessão
-	data, err := os.ReadFile("./database/sessions.json")
+	data, err := os.ReadFile(models.SessionsFilePath)
 	if err != nil {
 		return nil
 	}
@@ -43,10 +82,8 @@ func GetLoggedUser() *models.User {
 	}
 
 	// 🔎 Busca o usuário na memória usando o e-mail da sessão
-	for _, user := range UserStore {
-		if user.Email == session.Email {
-			return user
-		}
+	if user, ok := UserStore.FindByEmail(session.Email); ok {
+		return user
 	}
 
 	return nil
@@ -72,10 +109,8 @@ func GetLoggedUserByToken(token string) *models.User {
 	if !ok {
 		return nil
 	}
-	for _, user := range UserStore {
-		if user.Email == session.Email {
-			return user
-		}
+	if user, ok := UserStore.FindByEmail(session.Email); ok {
+		return user
 	}
 	return nil
 }
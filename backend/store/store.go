@@ -9,11 +9,18 @@ import (
 // 🔄 Sincroniza dados do usuário no armazenamento em memória
 func SyncUserToStore(username, email string, plan models.PlanType, _ string) {
 	// ✅ Agora indexado diretamente por username
-	UserStore[username] = &models.User{
+	if UserStore.Exists(username) {
+		_, _ = UserStore.Update(username, func(user *models.User) {
+			user.Email = email
+			user.Plan = plan
+		})
+		return
+	}
+	_ = UserStore.Save(&models.User{
 		Username: username,
 		Email:    email,
 		Plan:     plan,
-	}
+	})
 }
 
 //package store
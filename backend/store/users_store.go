@@ -4,37 +4,315 @@ package store
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 	"virtuscloud/backend/middleware"
 	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
 )
 
+// 🔒 Repositório de usuários com lock interno e backend de persistência plugável
+type UserRepository struct {
+	mu         sync.RWMutex
+	users      map[string]*models.User
+	path       string
+	collection persistence.Collection
+	planHooks  []PlanChangeFunc
+	delHooks   []UserDeleteFunc
+}
+
+// 🔔 Chamada após a troca de plano de um usuário já persistida (fora do lock)
+type PlanChangeFunc func(username string, from, to models.PlanType)
+
+// 🔔 Chamada após a remoção de um usuário já persistida (fora do lock)
+type UserDeleteFunc func(user *models.User)
+
 // Armazena os usuários indexados por username (imutável e único)
-var UserStore = map[string]*models.User{} // username como chave
+var UserStore = &UserRepository{
+	users: map[string]*models.User{},
+	path:  middleware.ClientsFilePath,
+} // username como chave
+
+// 🔍 Busca usuário pelo username (retorna cópia)
+func (r *UserRepository) Get(username string) (*models.User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return nil, false
+	}
+	return user.Clone(), true
+}
+
+// ✅ Indica se o username já está cadastrado
+func (r *UserRepository) Exists(username string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.users[username]
+	return ok
+}
+
+// 📧 Busca usuário pelo e-mail (retorna cópia)
+func (r *UserRepository) FindByEmail(email string) (*models.User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return user.Clone(), true
+		}
+	}
+	return nil, false
+}
+
+// 📋 Lista cópias de todos os usuários
+func (r *UserRepository) List() []*models.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		out = append(out, user.Clone())
+	}
+	return out
+}
+
+// 🗂️ Cópia de todos os usuários indexados por username
+func (r *UserRepository) Snapshot() map[string]*models.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make(map[string]*models.User, len(r.users))
+	for username, user := range r.users {
+		out[username] = user.Clone()
+	}
+	return out
+}
+
+// 🔔 Registra um observador de troca de plano (vale para Save, Update e UpdateByEmail)
+func (r *UserRepository) OnPlanChange(fn PlanChangeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.planHooks = append(r.planHooks, fn)
+}
+
+// 🔔 Registra um observador de remoção de conta (ex.: remoção da rede do usuário)
+func (r *UserRepository) OnDelete(fn UserDeleteFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delHooks = append(r.delHooks, fn)
+}
+
+// 💾 Adiciona ou substitui um usuário e persiste
+func (r *UserRepository) Save(user *models.User) error {
+	if user == nil || user.Username == "" {
+		return errors.New("usuário inválido")
+	}
+
+	r.mu.Lock()
+	stored := user.Clone()
+	previous, existed := r.users[stored.Username]
+	r.users[stored.Username] = stored
+	err := r.persistLocked(stored.Username, stored)
+	hooks := r.planHooks
+	r.mu.Unlock()
+
+	if err == nil && existed && previous.Plan != stored.Plan {
+		notifyPlanChange(hooks, stored.Username, previous.Plan, stored.Plan)
+	}
+	return err
+}
+
+// ✏️ Altera um usuário sob lock (leitura-modificação-escrita atômica)
+func (r *UserRepository) Update(username string, fn func(user *models.User)) (*models.User, error) {
+	r.mu.Lock()
+	current, ok := r.users[username]
+	if !ok {
+		r.mu.Unlock()
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	updated := current.Clone()
+	fn(updated)
+	updated.Username = username
+	r.users[username] = updated
+	err := r.persistLocked(username, updated)
+	result := updated.Clone()
+	hooks := r.planHooks
+	r.mu.Unlock()
+
+	if err == nil && current.Plan != updated.Plan {
+		notifyPlanChange(hooks, username, current.Plan, updated.Plan)
+	}
+	return result, err
+}
+
+// 🔔 Dispara os observadores fora do lock (eles podem consultar o próprio repositório)
+func notifyPlanChange(hooks []PlanChangeFunc, username string, from, to models.PlanType) {
+	for _, hook := range hooks {
+		hook(username, from, to)
+	}
+}
+
+// ✏️ Altera o usuário dono do e-mail informado
+func (r *UserRepository) UpdateByEmail(email string, fn func(user *models.User)) (*models.User, error) {
+	r.mu.RLock()
+	username := ""
+	for _, user := range r.users {
+		if user.Email == email {
+			username = user.Username
+			break
+		}
+	}
+	r.mu.RUnlock()
+
+	if username == "" {
+		return nil, errors.New("usuário não encontrado")
+	}
+	return r.Update(username, fn)
+}
+
+// 🗑️ Remove um usuário e persiste
+func (r *UserRepository) Delete(username string) error {
+	r.mu.Lock()
+	removed, ok := r.users[username]
+	if !ok {
+		r.mu.Unlock()
+		return nil
+	}
+	delete(r.users, username)
+
+	err := r.openLocked()
+	if err == nil {
+		err = r.collection.Delete(username)
+	}
+	hooks := r.delHooks
+	r.mu.Unlock()
+
+	if err == nil {
+		for _, hook := range hooks {
+			hook(removed.Clone())
+		}
+	}
+	return err
+}
+
+func (r *UserRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionUsers, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+func (r *UserRepository) persistLocked(username string, user *models.User) error {
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Put(username, user)
+}
+
+// 📸 Consolida as alterações pendentes no backend
+func (r *UserRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Flush()
+}
+
+// 🔄 Carrega (e recupera) os usuários a partir do caminho informado.
+// Edições externas no arquivo são mescladas sobre o estado em memória.
+func (r *UserRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		changed, err := r.collection.Reload()
+		if err != nil || !changed {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler usuários: %w", err)
+	}
+
+	for username, raw := range records {
+		var user models.User
+		if err := json.Unmarshal(raw, &user); err != nil {
+			return fmt.Errorf("usuário '%s' inválido em %s: %w", username, path, err)
+		}
+		r.users[username] = &user
+	}
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *UserRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
 
 // Inicializa o usuário admin no sistema
 func InitAdminUser() {
-	UserStore["admin"] = &models.User{
+	admin := &models.User{
 		Name:     "Admin",
 		Username: "admin",
 		Email:    "admin@virtuscloud.com",
 		Plan:     models.PlanPremium,
 		Role:     "admin",
 	}
+	if existing, ok := UserStore.Get("admin"); ok {
+		admin.CreatedAt = existing.CreatedAt
+		admin.LastActivity = existing.LastActivity
+	}
+	if err := UserStore.Save(admin); err != nil {
+		fmt.Println("⚠️ Erro ao salvar usuário admin:", err)
+	}
 }
 
 // Busca usuário pelo username
 func GetUserByUsername(username string) (*models.User, error) {
-	user, ok := UserStore[username]
+	user, ok := UserStore.Get(username)
 	if !ok {
 		return nil, nil
 	}
 	return user, nil
 }
 
+// 📧 Busca usuário pelo e-mail
+func GetUserByEmail(email string) (*models.User, bool) {
+	return UserStore.FindByEmail(strings.TrimSpace(email))
+}
+
 func SyncSessionsWithUserStore() error {
 	sessions := models.LoadSessions()
 	valid := map[string]models.SessionData{}
@@ -72,64 +350,40 @@ func SyncUser(username, name, email string, plan models.PlanType, _ int, role st
 		return
 	}
 
-	if user, ok := UserStore[username]; ok {
-		user.Name = name
-		user.Email = email
-		user.Plan = plan
-		if role != "" {
-			user.Role = role // atualiza cargo se informado
-		}
+	var err error
+	if UserStore.Exists(username) {
+		_, err = UserStore.Update(username, func(user *models.User) {
+			user.Name = name
+			user.Email = email
+			user.Plan = plan
+			if role != "" {
+				user.Role = role // atualiza cargo se informado
+			}
+		})
 	} else {
-		UserStore[username] = &models.User{
+		err = UserStore.Save(&models.User{
 			Name:     name,
 			Username: username,
 			Email:    email,
 			Plan:     plan,
 			Role:     "user", // padrão para novos cadastros
-		}
+		})
 	}
 
 	fmt.Printf("🔄 SyncUser: %s (%s) → plano %s\n", username, email, plan)
-	_ = SaveUsersToFile(middleware.ClientsFilePath)
+	if err != nil {
+		fmt.Println("⚠️ Erro ao persistir usuário:", err)
+	}
 }
 
 // Salva todos os usuários em arquivo JSON
-func SaveUsersToFile(filename string) error {
-	data, err := json.MarshalIndent(UserStore, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(filename, data, 0644)
+func SaveUsersToFile(_ string) error {
+	return UserStore.Flush()
 }
 
 // Carrega usuários do arquivo JSON
 func LoadUsersFromFile(filename string) error {
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
-			return fmt.Errorf("erro ao criar diretório: %w", err)
-		}
-		empty := map[string]*models.User{}
-		data, _ := json.MarshalIndent(empty, "", "  ")
-		if err := os.WriteFile(filename, data, 0644); err != nil {
-			return fmt.Errorf("erro ao criar arquivo: %w", err)
-		}
-		return nil
-	}
-
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return err
-	}
-
-	var loaded map[string]*models.User
-	if err := json.Unmarshal(data, &loaded); err != nil {
-		return err
-	}
-
-	for k, v := range loaded {
-		UserStore[k] = v
-	}
-	return nil
+	return UserStore.Load(filename)
 }
 
 //func RemoveInactiveSessions() error {
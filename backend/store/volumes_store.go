@@ -0,0 +1,198 @@
+// store/volumes_store.go
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/persistence"
+)
+
+// 📁 Caminho padrão dos volumes em disco
+const DefaultVolumeStorePath = "./database/volumes.json"
+
+// 🔒 Repositório de volumes persistentes, indexados por "<appId>/<nome>"
+type VolumeRepository struct {
+	mu         sync.RWMutex
+	volumes    map[string]*models.Volume
+	path       string
+	collection persistence.Collection
+}
+
+// 💽 Volumes em memória
+var VolumeStore = &VolumeRepository{
+	volumes: map[string]*models.Volume{},
+	path:    DefaultVolumeStorePath,
+}
+
+// 🔍 Busca volume pelo ID (retorna cópia)
+func (r *VolumeRepository) Get(id string) (*models.Volume, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	volume, ok := r.volumes[id]
+	if !ok {
+		return nil, false
+	}
+	return volume.Clone(), true
+}
+
+// 📋 Lista cópias de todos os volumes (ordenados por ID)
+func (r *VolumeRepository) List() []*models.Volume {
+	return r.filter(func(*models.Volume) bool { return true })
+}
+
+// 👤 Lista cópias dos volumes de um usuário
+func (r *VolumeRepository) ListByUser(username string) []*models.Volume {
+	return r.filter(func(v *models.Volume) bool { return strings.EqualFold(v.Username, username) })
+}
+
+// 📱 Lista cópias dos volumes de uma aplicação
+func (r *VolumeRepository) ListByApp(appID string) []*models.Volume {
+	return r.filter(func(v *models.Volume) bool { return v.AppID == appID })
+}
+
+func (r *VolumeRepository) filter(keep func(*models.Volume) bool) []*models.Volume {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []*models.Volume
+	for _, volume := range r.volumes {
+		if keep(volume) {
+			out = append(out, volume.Clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// 🆕 Registra um volume novo; falha se o ID já existir
+func (r *VolumeRepository) Create(volume *models.Volume) error {
+	if volume == nil || volume.ID == "" {
+		return errors.New("volume inválido")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, taken := r.volumes[volume.ID]; taken {
+		return fmt.Errorf("volume %s já existe", volume.ID)
+	}
+	stored := volume.Clone()
+	r.volumes[stored.ID] = stored
+	return r.persistLocked(stored.ID, stored)
+}
+
+// ✏️ Altera um volume sob lock (leitura-modificação-escrita atômica)
+func (r *VolumeRepository) Update(id string, fn func(volume *models.Volume)) (*models.Volume, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.volumes[id]
+	if !ok {
+		return nil, errors.New("volume não encontrado")
+	}
+
+	updated := current.Clone()
+	fn(updated)
+	updated.ID = id
+	r.volumes[id] = updated
+
+	return updated.Clone(), r.persistLocked(id, updated)
+}
+
+// 🗑️ Remove um volume e persiste
+func (r *VolumeRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.volumes[id]; !ok {
+		return nil
+	}
+	delete(r.volumes, id)
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Delete(id)
+}
+
+// 📂 Abre a coleção no backend ativo (lazy)
+func (r *VolumeRepository) openLocked() error {
+	if r.collection != nil {
+		return nil
+	}
+	collection, err := persistence.Open(persistence.CollectionVolumes, r.path)
+	if err != nil {
+		return err
+	}
+	r.collection = collection
+	return nil
+}
+
+func (r *VolumeRepository) persistLocked(id string, volume *models.Volume) error {
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+	return r.collection.Put(id, volume)
+}
+
+// 🔄 Carrega os volumes a partir do caminho informado
+func (r *VolumeRepository) Load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection != nil && r.path == path {
+		if _, err := r.collection.Reload(); err != nil {
+			return err
+		}
+	} else {
+		if r.collection != nil {
+			_ = r.collection.Close()
+			r.collection = nil
+		}
+		r.path = path
+		if err := r.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	records, err := r.collection.Records()
+	if err != nil {
+		return fmt.Errorf("erro ao ler volumes: %w", err)
+	}
+
+	volumes := map[string]*models.Volume{}
+	for id, raw := range records {
+		var volume models.Volume
+		if err := json.Unmarshal(raw, &volume); err != nil {
+			return fmt.Errorf("volume '%s' inválido em %s: %w", id, path, err)
+		}
+		volumes[id] = &volume
+	}
+	r.volumes = volumes
+	return nil
+}
+
+// 🔒 Faz o flush final e fecha a coleção
+func (r *VolumeRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.collection == nil {
+		return nil
+	}
+	err := r.collection.Close()
+	r.collection = nil
+	return err
+}
+
+// 🔄 Carrega os volumes do disco
+func LoadVolumeStoreFromDisk(filePath string) error {
+	return VolumeStore.Load(filePath)
+}
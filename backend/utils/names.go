@@ -30,6 +30,11 @@ func GetContainerName(username, appID string) string {
 	return fmt.Sprintf("%s-%s", username, appID)
 }
 
+// 🕸️ Nome da rede Docker privada do usuário (compartilhada pelas aplicações dele)
+func GetUserNetworkName(username string) string {
+	return fmt.Sprintf("vc-net-%s", username)
+}
+
 //func GetContainerName(app *models.App) string {
 //	return fmt.Sprintf("%s-%s", app.Username, app.ID)
 //}
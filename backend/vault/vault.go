@@ -0,0 +1,181 @@
+//backend/vault/vault.go
+
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 📁 Pastas com os dados cifrados: a chave mestra não pode ficar dentro delas
+// (um backup ou vazamento dos dados levaria a chave junto)
+var dataDirs = []string{"database", "storage"}
+
+// 📁 Local onde versões anteriores geravam a chave, hoje recusado
+const legacyKeyPath = "./database/master.key"
+
+// 🏷️ Prefixo dos valores selados (versão do formato)
+const sealedPrefix = "v1:"
+
+var (
+	keyMu sync.Mutex
+	key   []byte
+)
+
+// 🔐 Cifra o valor com AES-256-GCM usando a chave mestra ("v1:" + base64(nonce‖cifrado))
+func Seal(plaintext []byte) (string, error) {
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("erro ao gerar nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// 🔓 Decifra um valor produzido por Seal
+func Open(sealed string) ([]byte, error) {
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return nil, errors.New("valor selado em formato desconhecido")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("valor selado inválido: %w", err)
+	}
+	aead, err := newAEAD()
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < aead.NonceSize() {
+		return nil, errors.New("valor selado truncado")
+	}
+	plaintext, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("falha ao decifrar (chave mestra diferente?)")
+	}
+	return plaintext, nil
+}
+
+func newAEAD() (cipher.AEAD, error) {
+	k, err := masterKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ✅ Carrega a chave mestra já na subida, para a configuração errada aparecer antes do primeiro segredo
+func Check() error {
+	_, err := masterKey()
+	return err
+}
+
+// 🗝️ Chave mestra: VAULT_MASTER_KEY (32 bytes em hex ou base64) ou o arquivo VAULT_KEY_FILE,
+// fora das pastas de dados e criado no primeiro uso
+func masterKey() ([]byte, error) {
+	keyMu.Lock()
+	defer keyMu.Unlock()
+	if key != nil {
+		return key, nil
+	}
+
+	if env := strings.TrimSpace(os.Getenv("VAULT_MASTER_KEY")); env != "" {
+		k, err := decodeKey(env)
+		if err != nil {
+			return nil, fmt.Errorf("VAULT_MASTER_KEY inválida: %w", err)
+		}
+		key = k
+		return key, nil
+	}
+
+	path := strings.TrimSpace(os.Getenv("VAULT_KEY_FILE"))
+	if path == "" {
+		if _, err := os.Stat(legacyKeyPath); err == nil {
+			return nil, fmt.Errorf("a chave mestra em %s fica junto dos dados que protege: mova o arquivo para fora de %s e aponte VAULT_KEY_FILE para ele", legacyKeyPath, strings.Join(dataDirs, "/ e "))
+		}
+		return nil, errors.New("chave mestra não configurada: defina VAULT_MASTER_KEY (32 bytes em hex ou base64) ou VAULT_KEY_FILE com um caminho fora das pastas de dados")
+	}
+	if dir, inside := insideDataDir(path); inside {
+		return nil, fmt.Errorf("VAULT_KEY_FILE (%s) está dentro de %s, junto dos dados que protege", path, dir)
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		k, err := decodeKey(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("chave mestra inválida em %s: %w", path, err)
+		}
+		key = k
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("erro ao ler chave mestra: %w", err)
+	}
+
+	// 🆕 Primeira execução: gera a chave no caminho configurado e grava só para o dono
+	k := make([]byte, 32)
+	if _, err := rand.Read(k); err != nil {
+		return nil, fmt.Errorf("erro ao gerar chave mestra: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório da chave mestra: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(k)+"\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("erro ao gravar chave mestra: %w", err)
+	}
+	key = k
+	return key, nil
+}
+
+// 📂 Pasta de dados que contém path (links simbólicos resolvidos até onde o caminho já existe)
+func insideDataDir(path string) (string, bool) {
+	target := resolvePath(path)
+	for _, dir := range dataDirs {
+		rel, err := filepath.Rel(resolvePath(dir), target)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	// 🔗 Sobe até o primeiro ancestral existente e resolve os links a partir dele
+	rest := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(real, rest)
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return abs
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
+
+func decodeKey(s string) ([]byte, error) {
+	if k, err := hex.DecodeString(s); err == nil && len(k) == 32 {
+		return k, nil
+	}
+	if k, err := base64.StdEncoding.DecodeString(s); err == nil && len(k) == 32 {
+		return k, nil
+	}
+	return nil, errors.New("esperados 32 bytes em hex ou base64")
+}
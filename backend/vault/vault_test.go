@@ -0,0 +1,123 @@
+//backend/vault/vault_test.go
+
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 🔁 Esquece a chave em cache (como numa nova subida)
+func resetKey() {
+	keyMu.Lock()
+	key = nil
+	keyMu.Unlock()
+}
+
+// 🧪 Cada teste roda numa pasta própria, com a chave em cache zerada
+func setup(t *testing.T) string {
+	t.Helper()
+	packageDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("erro ao ler diretório: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("erro ao entrar na pasta temporária: %v", err)
+	}
+	t.Setenv("VAULT_MASTER_KEY", "")
+	t.Setenv("VAULT_KEY_FILE", "")
+	resetKey()
+	t.Cleanup(func() {
+		resetKey()
+		_ = os.Chdir(packageDir)
+	})
+	return dir
+}
+
+func TestSealOpenWithEnvKey(t *testing.T) {
+	setup(t)
+	t.Setenv("VAULT_MASTER_KEY", strings.Repeat("ab", 32))
+
+	sealed, err := Seal([]byte("senha"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	plain, err := Open(sealed)
+	if err != nil || string(plain) != "senha" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if _, err := os.Stat(legacyKeyPath); !os.IsNotExist(err) {
+		t.Fatalf("chave gravada junto dos dados: %v", err)
+	}
+}
+
+func TestCheckRequiresConfiguredKey(t *testing.T) {
+	setup(t)
+	err := Check()
+	if err == nil || !strings.Contains(err.Error(), "não configurada") {
+		t.Fatalf("Check sem chave = %v, esperado erro de configuração", err)
+	}
+	if _, statErr := os.Stat(legacyKeyPath); !os.IsNotExist(statErr) {
+		t.Fatalf("chave criada automaticamente em %s", legacyKeyPath)
+	}
+}
+
+func TestCheckRefusesLegacyKeyNextToData(t *testing.T) {
+	setup(t)
+	if err := os.MkdirAll("database", 0o700); err != nil {
+		t.Fatalf("erro ao criar database: %v", err)
+	}
+	if err := os.WriteFile(legacyKeyPath, []byte(strings.Repeat("cd", 32)+"\n"), 0o600); err != nil {
+		t.Fatalf("erro ao gravar chave antiga: %v", err)
+	}
+	if err := Check(); err == nil || !strings.Contains(err.Error(), "VAULT_KEY_FILE") {
+		t.Fatalf("Check com a chave antiga = %v, esperado pedido para mover o arquivo", err)
+	}
+}
+
+func TestCheckRefusesKeyFileInsideDataDirs(t *testing.T) {
+	dir := setup(t)
+	if err := os.MkdirAll(filepath.Join("storage", "users"), 0o700); err != nil {
+		t.Fatalf("erro ao criar storage: %v", err)
+	}
+	// 🔗 Link fora da pasta de dados apontando para dentro dela também é recusado
+	if err := os.Symlink(filepath.Join(dir, "storage", "users"), filepath.Join(dir, "atalho")); err != nil {
+		t.Fatalf("erro ao criar link: %v", err)
+	}
+
+	for _, path := range []string{"database/master.key", "./storage/users/../master.key", filepath.Join(dir, "atalho", "master.key")} {
+		resetKey()
+		t.Setenv("VAULT_KEY_FILE", path)
+		if err := Check(); err == nil || !strings.Contains(err.Error(), "junto dos dados") {
+			t.Fatalf("VAULT_KEY_FILE=%s: %v, esperado recusa", path, err)
+		}
+	}
+}
+
+func TestKeyFileCreatedAtExternalPath(t *testing.T) {
+	dir := setup(t)
+	path := filepath.Join(dir, "secrets", "master.key")
+	t.Setenv("VAULT_KEY_FILE", path)
+
+	sealed, err := Seal([]byte("token"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("chave não criada em VAULT_KEY_FILE: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("permissão da chave = %v, esperado 0600", info.Mode().Perm())
+	}
+
+	// 🔁 Nova subida lê a mesma chave do arquivo
+	resetKey()
+	plain, err := Open(sealed)
+	if err != nil || string(plain) != "token" {
+		t.Fatalf("Open após recarregar = %q, %v", plain, err)
+	}
+}
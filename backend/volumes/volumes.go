@@ -0,0 +1,376 @@
+//backend/volumes/volumes.go
+
+package volumes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"virtuscloud/backend/limits"
+	"virtuscloud/backend/models"
+	"virtuscloud/backend/store"
+	"virtuscloud/backend/utils"
+)
+
+// 📏 Limites de cada volume
+const (
+	MaxVolumesPerApp = 10
+	gb               = int64(1024 * 1024 * 1024)
+)
+
+// ❗ Erros de validação (o handler escolhe o status HTTP com errors.Is)
+var (
+	ErrInvalid    = errors.New("volume inválido")
+	ErrExists     = errors.New("volume já existe")
+	ErrNotAllowed = errors.New("armazenamento não permitido pelo plano")
+	ErrNotFound   = errors.New("volume não encontrado")
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// 🚫 Diretórios do sistema que um volume não pode cobrir (nem seus subdiretórios)
+var systemMounts = []string{"/bin", "/boot", "/dev", "/etc", "/lib", "/lib64", "/proc", "/run", "/sbin", "/sys", "/usr", "/var/run"}
+
+// 🔒 Serializa reservas para a soma das cotas respeitar o plano
+var reserveMu sync.Mutex
+
+// 🧹 Volumes (e os dados) saem junto com a aplicação
+func init() {
+	store.AppStore.OnDelete(func(app *models.App) {
+		for _, volume := range store.VolumeStore.ListByApp(app.ID) {
+			if err := Remove(volume.ID); err != nil {
+				log.Printf("⚠️ Erro ao remover volume %s da aplicação %s: %v", volume.ID, app.ID, err)
+				continue
+			}
+			log.Printf("💽 Volume %s removido junto com a aplicação %s", volume.ID, app.ID)
+		}
+		_ = os.Remove(appDir(app.Username, app.ID))
+	})
+}
+
+// 📁 storage/users/<usuário>/volumes/<app>
+func appDir(username, appID string) string {
+	return filepath.Join("storage", "users", username, "volumes", appID)
+}
+
+// 📁 Diretório do volume no host
+func HostPath(volume *models.Volume) string {
+	return filepath.Join(appDir(volume.Username, volume.AppID), volume.Name)
+}
+
+// ➕ Cria o volume, reservando sizeGB do armazenamento do plano
+func Create(app *models.App, name, mountPath string, sizeGB int) (*models.Volume, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: nome '%s' (use até 32 letras minúsculas, números, - e _)", ErrInvalid, name)
+	}
+	mountPath, err := normalizeMountPath(mountPath)
+	if err != nil {
+		return nil, err
+	}
+	if sizeGB <= 0 {
+		return nil, fmt.Errorf("%w: tamanho deve ser de pelo menos 1 GB", ErrInvalid)
+	}
+
+	reserveMu.Lock()
+	defer reserveMu.Unlock()
+
+	existing := store.VolumeStore.ListByApp(app.ID)
+	if len(existing) >= MaxVolumesPerApp {
+		return nil, fmt.Errorf("%w: limite de %d volumes por aplicação", ErrInvalid, MaxVolumesPerApp)
+	}
+	for _, v := range existing {
+		if v.Name == name {
+			return nil, fmt.Errorf("%w: %s", ErrExists, name)
+		}
+		if v.MountPath == mountPath {
+			return nil, fmt.Errorf("%w: %s já é usado pelo volume %s", ErrInvalid, mountPath, v.Name)
+		}
+	}
+	if err := limits.CanReserveVolume(app.Username, ReservedGB(app.Username), sizeGB); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAllowed, err)
+	}
+
+	volume := &models.Volume{
+		ID:        app.ID + "/" + name,
+		AppID:     app.ID,
+		Username:  app.Username,
+		Name:      name,
+		MountPath: mountPath,
+		SizeGB:    sizeGB,
+		CreatedAt: time.Now(),
+	}
+	dir := HostPath(volume)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do volume: %w", err)
+	}
+	// 🔓 O processo do container pode rodar com qualquer UID
+	if err := os.Chmod(dir, 0o777); err != nil {
+		return nil, fmt.Errorf("erro ao ajustar permissões do volume: %w", err)
+	}
+	if err := store.VolumeStore.Create(volume); err != nil {
+		return nil, err
+	}
+	log.Printf("💽 Volume %s criado (%d GB em %s)", volume.ID, sizeGB, mountPath)
+	return volume, nil
+}
+
+// ✏️ Altera cota e/ou ponto de montagem (sizeGB 0 e mountPath vazio mantêm o atual)
+func Update(id string, sizeGB int, mountPath string) (*models.Volume, error) {
+	reserveMu.Lock()
+	defer reserveMu.Unlock()
+
+	current, ok := store.VolumeStore.Get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if mountPath != "" {
+		normalized, err := normalizeMountPath(mountPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range store.VolumeStore.ListByApp(current.AppID) {
+			if v.ID != id && v.MountPath == normalized {
+				return nil, fmt.Errorf("%w: %s já é usado pelo volume %s", ErrInvalid, normalized, v.Name)
+			}
+		}
+		mountPath = normalized
+	}
+
+	if sizeGB < 0 {
+		return nil, fmt.Errorf("%w: tamanho negativo", ErrInvalid)
+	}
+	if sizeGB > 0 && sizeGB != current.SizeGB {
+		if int64(sizeGB)*gb < current.UsedBytes {
+			return nil, fmt.Errorf("%w: o volume já usa %s, mais que %d GB", ErrInvalid, FormatBytes(current.UsedBytes), sizeGB)
+		}
+		if sizeGB > current.SizeGB {
+			if err := limits.CanReserveVolume(current.Username, ReservedGB(current.Username), sizeGB-current.SizeGB); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrNotAllowed, err)
+			}
+		}
+	}
+
+	return store.VolumeStore.Update(id, func(v *models.Volume) {
+		if mountPath != "" {
+			v.MountPath = mountPath
+		}
+		if sizeGB > 0 {
+			v.SizeGB = sizeGB
+			v.OverQuota = v.UsedBytes > int64(sizeGB)*gb
+		}
+	})
+}
+
+// 🗑️ Remove o volume e apaga os dados
+func Remove(id string) error {
+	volume, ok := store.VolumeStore.Get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	if err := os.RemoveAll(HostPath(volume)); err != nil {
+		return fmt.Errorf("erro ao apagar dados do volume: %w", err)
+	}
+	return store.VolumeStore.Delete(id)
+}
+
+// 🧮 Soma das cotas reservadas pelo usuário
+func ReservedGB(username string) int {
+	total := 0
+	for _, v := range store.VolumeStore.ListByUser(username) {
+		total += v.SizeGB
+	}
+	return total
+}
+
+// 🔗 Binds "host:container[:ro]" dos volumes da aplicação (volume acima da cota monta somente leitura)
+func Binds(appID string) []string {
+	var binds []string
+	for _, v := range store.VolumeStore.ListByApp(appID) {
+		host, err := filepath.Abs(HostPath(v))
+		if err != nil {
+			log.Printf("⚠️ Caminho inválido para o volume %s: %v", v.ID, err)
+			continue
+		}
+		bind := host + ":" + v.MountPath
+		if v.OverQuota {
+			bind += ":ro"
+		}
+		binds = append(binds, bind)
+	}
+	return binds
+}
+
+// 📐 Caminho absoluto e limpo dentro do container, fora de diretórios do sistema e sem cobrir /app
+func normalizeMountPath(mountPath string) (string, error) {
+	mountPath = strings.TrimSpace(mountPath)
+	if !strings.HasPrefix(mountPath, "/") || strings.ContainsAny(mountPath, ":,\x00") {
+		return "", fmt.Errorf("%w: ponto de montagem '%s' deve ser um caminho absoluto", ErrInvalid, mountPath)
+	}
+	mountPath = path.Clean(mountPath)
+	if mountPath == "/" || mountPath == "/app" {
+		return "", fmt.Errorf("%w: %s não pode ser coberto por um volume (use um subdiretório, ex.: /app/data)", ErrInvalid, mountPath)
+	}
+	for _, sys := range systemMounts {
+		if mountPath == sys || strings.HasPrefix(mountPath, sys+"/") {
+			return "", fmt.Errorf("%w: %s é um diretório do sistema", ErrInvalid, mountPath)
+		}
+	}
+	return mountPath, nil
+}
+
+// 📏 Bytes ocupados pelo diretório do volume (links simbólicos não são seguidos)
+func Measure(volume *models.Volume) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(HostPath(volume), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// 🔄 Mede todos os volumes e atualiza o uso; devolve as aplicações cujo estado de cota mudou
+func Refresh() []string {
+	changed := map[string]bool{}
+	for _, v := range store.VolumeStore.List() {
+		used, err := Measure(v)
+		if err != nil {
+			log.Printf("⚠️ Erro ao medir volume %s: %v", v.ID, err)
+			continue
+		}
+		now := time.Now()
+		wasOver := false
+		updated, err := store.VolumeStore.Update(v.ID, func(stored *models.Volume) {
+			wasOver = stored.OverQuota
+			stored.UsedBytes = used
+			stored.MeasuredAt = &now
+			stored.OverQuota = used > int64(stored.SizeGB)*gb
+		})
+		if err != nil {
+			log.Printf("⚠️ Erro ao salvar uso do volume %s: %v", v.ID, err)
+			continue
+		}
+		if updated.OverQuota != wasOver {
+			if updated.OverQuota {
+				log.Printf("🚫 Volume %s acima da cota (%s de %d GB): passa a montar somente leitura", v.ID, FormatBytes(used), updated.SizeGB)
+			} else {
+				log.Printf("✅ Volume %s voltou à cota: leitura e escrita liberadas", v.ID)
+			}
+			changed[v.AppID] = true
+		}
+	}
+
+	apps := make([]string, 0, len(changed))
+	for appID := range changed {
+		apps = append(apps, appID)
+	}
+	return apps
+}
+
+// ⏱️ Mede os volumes periodicamente; onQuotaChange recebe as aplicações que precisam remontar volumes
+func StartMonitor(interval time.Duration, onQuotaChange func(appID string)) {
+	go func() {
+		for {
+			for _, appID := range Refresh() {
+				onQuotaChange(appID)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// 📦 Compacta os volumes da aplicação em zipPath (false = aplicação sem volumes)
+func Archive(appID, zipPath string) (bool, error) {
+	list := store.VolumeStore.ListByApp(appID)
+	if len(list) == 0 {
+		return false, nil
+	}
+	dir := appDir(list[0].Username, appID)
+	if err := os.MkdirAll(filepath.Dir(zipPath), os.ModePerm); err != nil {
+		return false, fmt.Errorf("erro ao criar diretório do backup de volumes: %w", err)
+	}
+	if err := utils.ZipFolder(dir, zipPath); err != nil {
+		return false, fmt.Errorf("erro ao compactar volumes: %w", err)
+	}
+	return true, nil
+}
+
+// 🧾 Armazenamento do usuário frente ao plano
+type Summary struct {
+	FreeGB     int   `json:"freeGB"`     // incluído no plano (BlobFreeGBMax)
+	LimitGB    int   `json:"limitGB"`    // teto do plano (BlobLimitGB)
+	ReservedGB int   `json:"reservedGB"` // soma das cotas dos volumes
+	ExtraGB    int   `json:"extraGB"`    // reservado além do incluído
+	UsedBytes  int64 `json:"usedBytes"`  // última medição de todos os volumes
+}
+
+// 🧾 Resumo do armazenamento em volumes do usuário
+func Usage(username string) (Summary, error) {
+	freeGB, limitGB, err := limits.VolumeQuota(username)
+	if err != nil {
+		return Summary{}, err
+	}
+	summary := Summary{FreeGB: freeGB, LimitGB: limitGB}
+	for _, v := range store.VolumeStore.ListByUser(username) {
+		summary.ReservedGB += v.SizeGB
+		summary.UsedBytes += v.UsedBytes
+	}
+	if summary.ReservedGB > freeGB {
+		summary.ExtraGB = summary.ReservedGB - freeGB
+	}
+	return summary, nil
+}
+
+// 📱 Uso medido e cota reservada dos volumes de uma aplicação
+func AppUsage(appID string) (usedBytes int64, reservedGB int) {
+	for _, v := range store.VolumeStore.ListByApp(appID) {
+		usedBytes += v.UsedBytes
+		reservedGB += v.SizeGB
+	}
+	return usedBytes, reservedGB
+}
+
+// 🔤 Bytes em unidade legível (ex.: 1.5 GB)
+func FormatBytes(n int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(n)
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// ⏱️ Intervalo de medição (VOLUMES_SCAN_SECONDS, padrão 5 minutos)
+func MonitorIntervalFromEnv() time.Duration {
+	if seconds, err := strconv.Atoi(os.Getenv("VOLUMES_SCAN_SECONDS")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 5 * time.Minute
+}
@@ -0,0 +1,400 @@
+//backend/websocket/websocket.go
+
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 🔌 Servidor WebSocket mínimo (RFC 6455) sobre o net/http: handshake, mensagens
+// fragmentadas, ping/pong e fechamento. Sem extensões (compressão) nem subprotocolos.
+
+// 🏷️ Tipos de mensagem
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+// 🚪 Códigos de fechamento
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+	CloseInternalError   = 1011
+)
+
+const (
+	acceptGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxControlPayload   = 125
+	defaultMaxMessage   = 1 << 20
+	defaultWriteTimeout = 10 * time.Second
+	closeWriteTimeout   = time.Second
+)
+
+// ❗ Erros do protocolo
+var (
+	ErrBadHandshake = errors.New("handshake WebSocket inválido")
+	ErrOrigin       = errors.New("origem não permitida")
+	ErrClosed       = errors.New("conexão WebSocket fechada")
+)
+
+// 🚪 Fechamento recebido do cliente (ou imposto por violação do protocolo)
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket fechado (%d)", e.Code)
+	}
+	return fmt.Sprintf("websocket fechado (%d): %s", e.Code, e.Reason)
+}
+
+// ⚙️ Opções do upgrade
+type Options struct {
+	MaxMessageBytes int64         // maior mensagem aceita (0 = 1 MiB)
+	ReadTimeout     time.Duration // prazo entre frames recebidos, inclusive pong (0 = sem prazo)
+	WriteTimeout    time.Duration // prazo de cada escrita (0 = 10s)
+	// Origens aceitas além da do próprio host (ex.: https://painel.exemplo.com).
+	// Sem cabeçalho Origin (clientes fora do navegador) a conexão é aceita.
+	AllowedOrigins []string
+}
+
+// 🔗 Conexão WebSocket do lado do servidor.
+// Leituras devem vir de uma única goroutine; escritas podem ser concorrentes.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	opts Options
+
+	writeMu    sync.Mutex
+	closeSent  bool
+	closeOnce  sync.Once
+	readClosed bool
+}
+
+// 🔍 Indica se a requisição pede upgrade para WebSocket
+func IsUpgrade(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") && headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+// 🔍 Valida o handshake sem responder: status HTTP e mensagem do erro (0 = válido).
+// Permite recusar antes de preparar recursos caros para a sessão.
+func Validate(r *http.Request, opts Options) (int, string) {
+	switch {
+	case r.Method != http.MethodGet:
+		return http.StatusMethodNotAllowed, "Método não permitido"
+	case !IsUpgrade(r):
+		return http.StatusBadRequest, "Requisição não é um upgrade para WebSocket"
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		return http.StatusUpgradeRequired, "Versão de WebSocket não suportada"
+	}
+	if raw, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key")); err != nil || len(raw) != 16 {
+		return http.StatusBadRequest, "Sec-WebSocket-Key inválido"
+	}
+	if !originAllowed(r, opts.AllowedOrigins) {
+		return http.StatusForbidden, "Origem não permitida"
+	}
+	return 0, ""
+}
+
+// 🤝 Valida o handshake, assume a conexão (Hijack) e responde 101.
+// Em caso de erro a resposta HTTP já foi enviada.
+func Upgrade(w http.ResponseWriter, r *http.Request, opts Options) (*Conn, error) {
+	if status, message := Validate(r, opts); status != 0 {
+		if status == http.StatusUpgradeRequired {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+		}
+		http.Error(w, message, status)
+		if status == http.StatusForbidden {
+			return nil, ErrOrigin
+		}
+		return nil, ErrBadHandshake
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket não suportado", http.StatusInternalServerError)
+		return nil, fmt.Errorf("erro ao assumir a conexão: %w", err)
+	}
+	// O servidor HTTP pode ter deixado prazos na conexão: a partir daqui quem controla é o Conn
+	_ = conn.SetDeadline(time.Time{})
+
+	if opts.MaxMessageBytes <= 0 {
+		opts.MaxMessageBytes = defaultMaxMessage
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = defaultWriteTimeout
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	_ = conn.SetWriteDeadline(time.Now().Add(opts.WriteTimeout))
+	if _, err := io.WriteString(conn, response); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro ao concluir handshake: %w", err)
+	}
+	return &Conn{conn: conn, br: brw.Reader, opts: opts}, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 🛡️ Navegadores mandam Origin: só o próprio host ou as origens liberadas abrem a conexão
+// (cookies de sessão seguem em WebSockets de qualquer site)
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimRight(strings.TrimSpace(a), "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// 🌐 Endereço remoto da conexão
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// 📥 Próxima mensagem de dados (texto ou binária), já remontada a partir dos fragmentos.
+// Pings são respondidos aqui; um fechamento do cliente volta como *CloseError.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	if c.readClosed {
+		return 0, nil, ErrClosed
+	}
+	var (
+		messageType int
+		message     []byte
+	)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, c.failRead(err)
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, payload); err != nil {
+				return 0, nil, c.failRead(err)
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := parseClose(payload)
+			c.readClosed = true
+			code := closeErr.Code
+			if code == CloseNoStatus {
+				code = CloseNormal
+			}
+			_ = c.Close(code, "")
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.failRead(&CloseError{Code: CloseProtocolError, Reason: "nova mensagem antes do fim da anterior"})
+			}
+			messageType = opcode
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.failRead(&CloseError{Code: CloseProtocolError, Reason: "continuação sem mensagem"})
+			}
+		default:
+			return 0, nil, c.failRead(&CloseError{Code: CloseProtocolError, Reason: "opcode desconhecido"})
+		}
+
+		if int64(len(message)+len(payload)) > c.opts.MaxMessageBytes {
+			return 0, nil, c.failRead(&CloseError{Code: CloseTooBig, Reason: "mensagem grande demais"})
+		}
+		message = append(message, payload...)
+		if !fin {
+			continue
+		}
+		if messageType == TextMessage && !utf8.Valid(message) {
+			return 0, nil, c.failRead(&CloseError{Code: CloseInvalidPayload, Reason: "texto não é UTF-8"})
+		}
+		return messageType, message, nil
+	}
+}
+
+// ❌ Violação do protocolo: avisa o cliente com o código adequado e fecha
+func (c *Conn) failRead(err error) error {
+	c.readClosed = true
+	var closeErr *CloseError
+	if errors.As(err, &closeErr) {
+		_ = c.Close(closeErr.Code, closeErr.Reason)
+	} else {
+		c.conn.Close()
+	}
+	return err
+}
+
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	if c.opts.ReadTimeout > 0 {
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.opts.ReadTimeout))
+	}
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "bits RSV sem extensão negociada"}
+	}
+	opcode := int(header[0] & 0x0f)
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "frame do cliente sem máscara"}
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if opcode >= CloseMessage && (length > maxControlPayload || !fin) {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "frame de controle inválido"}
+	}
+	if length < 0 || length > c.opts.MaxMessageBytes {
+		return false, 0, nil, &CloseError{Code: CloseTooBig, Reason: "mensagem grande demais"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+func parseClose(payload []byte) *CloseError {
+	if len(payload) < 2 {
+		return &CloseError{Code: CloseNoStatus}
+	}
+	return &CloseError{Code: int(binary.BigEndian.Uint16(payload)), Reason: string(payload[2:])}
+}
+
+// 📤 Envia uma mensagem de texto ou binária (um único frame)
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("tipo de mensagem inválido: %d", messageType)
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// 🏓 Envia um ping (o cliente responde com pong, que renova o ReadTimeout)
+func (c *Conn) Ping() error {
+	return c.writeFrame(PingMessage, nil)
+}
+
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+	return c.writeFrameLocked(opcode, payload, c.opts.WriteTimeout)
+}
+
+func (c *Conn) writeFrameLocked(opcode int, payload []byte, timeout time.Duration) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | byte(opcode)
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// 🚪 Envia o frame de fechamento (uma vez) e encerra a conexão
+func (c *Conn) Close(code int, reason string) error {
+	c.closeOnce.Do(func() {
+		c.writeMu.Lock()
+		if !c.closeSent {
+			c.closeSent = true
+			if len(reason) > maxControlPayload-2 {
+				reason = strings.ToValidUTF8(reason[:maxControlPayload-2], "")
+			}
+			payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+			_ = c.writeFrameLocked(CloseMessage, append(payload, reason...), closeWriteTimeout)
+		}
+		c.writeMu.Unlock()
+		c.conn.Close()
+	})
+	return nil
+}